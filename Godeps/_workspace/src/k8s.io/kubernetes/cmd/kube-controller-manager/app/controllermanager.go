@@ -54,6 +54,7 @@ import (
 	"k8s.io/kubernetes/pkg/healthz"
 	"k8s.io/kubernetes/pkg/master/ports"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/volume"
 
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
@@ -76,6 +77,7 @@ type CMServer struct {
 	NamespaceSyncPeriod               time.Duration
 	PVClaimBinderSyncPeriod           time.Duration
 	VolumeConfigFlags                 VolumeConfigFlags
+	VolumeAuditSink                   string
 	TerminatedPodGCThreshold          int
 	HorizontalPodAutoscalerSyncPeriod time.Duration
 	DeploymentControllerSyncPeriod    time.Duration
@@ -149,6 +151,13 @@ type VolumeConfigFlags struct {
 	PersistentVolumeRecyclerMinimumTimeoutHostPath      int
 	PersistentVolumeRecyclerIncrementTimeoutHostPath    int
 	EnableHostPathProvisioning                          bool
+	RBDProvisionerMonitors                              string
+	RBDProvisionerPool                                  string
+	RBDProvisionerUser                                  string
+	RBDProvisionerKeyring                               string
+	CSIProvisionerDriver                                string
+	CSISocketDir                                        string
+	ProvisionerClassesFilePath                          string
 }
 
 // AddFlags adds flags for a specific CMServer to the specified FlagSet
@@ -174,6 +183,14 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&s.VolumeConfigFlags.PersistentVolumeRecyclerMinimumTimeoutHostPath, "pv-recycler-minimum-timeout-hostpath", s.VolumeConfigFlags.PersistentVolumeRecyclerMinimumTimeoutHostPath, "The minimum ActiveDeadlineSeconds to use for a HostPath Recycler pod.  This is for development and testing only and will not work in a multi-node cluster.")
 	fs.IntVar(&s.VolumeConfigFlags.PersistentVolumeRecyclerIncrementTimeoutHostPath, "pv-recycler-timeout-increment-hostpath", s.VolumeConfigFlags.PersistentVolumeRecyclerIncrementTimeoutHostPath, "the increment of time added per Gi to ActiveDeadlineSeconds for a HostPath scrubber pod.  This is for development and testing only and will not work in a multi-node cluster.")
 	fs.BoolVar(&s.VolumeConfigFlags.EnableHostPathProvisioning, "enable-hostpath-provisioner", s.VolumeConfigFlags.EnableHostPathProvisioning, "Enable HostPath PV provisioning when running without a cloud provider. This allows testing and development of provisioning features.  HostPath provisioning is not supported in any way, won't work in a multi-node cluster, and should not be used for anything other than testing or development.")
+	fs.StringVar(&s.VolumeConfigFlags.RBDProvisionerMonitors, "rbd-provisioner-monitors", s.VolumeConfigFlags.RBDProvisionerMonitors, "Comma separated list of Ceph monitors. If set when running without a cloud provider, PVs are provisioned as RBD images.")
+	fs.StringVar(&s.VolumeConfigFlags.RBDProvisionerPool, "rbd-provisioner-pool", s.VolumeConfigFlags.RBDProvisionerPool, "The Ceph pool RBD images are provisioned in. Defaults to rbd.")
+	fs.StringVar(&s.VolumeConfigFlags.RBDProvisionerUser, "rbd-provisioner-user", s.VolumeConfigFlags.RBDProvisionerUser, "The rados user that provisions and deletes RBD images, and that provisioned PVs are mounted as. Defaults to admin.")
+	fs.StringVar(&s.VolumeConfigFlags.RBDProvisionerKeyring, "rbd-provisioner-keyring", s.VolumeConfigFlags.RBDProvisionerKeyring, "The path to the key ring of the rbd provisioner user. Defaults to /etc/ceph/keyring.")
+	fs.StringVar(&s.VolumeConfigFlags.CSIProvisionerDriver, "csi-provisioner-driver", s.VolumeConfigFlags.CSIProvisionerDriver, "The CSI driver, in the form vendor/driver, that provisions and deletes PVs. If set when running without a cloud provider, PVs are provisioned by this driver.")
+	fs.StringVar(&s.VolumeConfigFlags.CSISocketDir, "csi-socket-dir", s.VolumeConfigFlags.CSISocketDir, "The directory the sockets of CSI drivers are in, the socket of the driver vendor/driver being vendor~driver/csi.sock.")
+	fs.StringVar(&s.VolumeConfigFlags.ProvisionerClassesFilePath, "pv-provisioner-classes-filepath", s.VolumeConfigFlags.ProvisionerClassesFilePath, "The file path to a JSON object mapping the storage classes claims can request to the parameters of the provisioner, e.g. {\"fast\": {\"type\": \"pd-ssd\"}}. If empty, any class is provisioned with the defaults of the provisioner.")
+	fs.StringVar(&s.VolumeAuditSink, "volume-audit-sink", s.VolumeAuditSink, "Where to record every recycle, delete and provision of a persistent volume: the http:// or https:// URL of a webhook, 'syslog', 'syslog:<tag>' or the path of a file. Empty for no audit.")
 	fs.IntVar(&s.TerminatedPodGCThreshold, "terminated-pod-gc-threshold", s.TerminatedPodGCThreshold, "Number of terminated pods that can exist before the terminated pod garbage collector starts deleting terminated pods. If <= 0, the terminated pod garbage collector is disabled.")
 	fs.DurationVar(&s.HorizontalPodAutoscalerSyncPeriod, "horizontal-pod-autoscaler-sync-period", s.HorizontalPodAutoscalerSyncPeriod, "The period for syncing the number of pods in horizontal pod autoscaler.")
 	fs.DurationVar(&s.DeploymentControllerSyncPeriod, "deployment-controller-sync-period", s.DeploymentControllerSyncPeriod, "Period for syncing the deployments.")
@@ -312,6 +329,12 @@ func (s *CMServer) Run(_ []string) error {
 		glog.Fatal("A Provisioner could not be created, but one was expected. Provisioning will not work. This functionality is considered an early Alpha version.")
 	}
 
+	volume.RegisterOperationMetrics()
+	auditSink, err := volume.NewAuditSink(s.VolumeAuditSink)
+	if err != nil {
+		glog.Fatalf("Failed to open the volume audit sink %q: %v", s.VolumeAuditSink, err)
+	}
+	volume.SetOperationAuditSink(auditSink)
 	pvclaimBinder := persistentvolumecontroller.NewPersistentVolumeClaimBinder(kubeClient, s.PVClaimBinderSyncPeriod)
 	pvclaimBinder.Run()
 
@@ -322,7 +345,11 @@ func (s *CMServer) Run(_ []string) error {
 	pvRecycler.Run()
 
 	if provisioner != nil {
-		pvController, err := persistentvolumecontroller.NewPersistentVolumeProvisionerController(persistentvolumecontroller.NewControllerClient(kubeClient), s.PVClaimBinderSyncPeriod, volumePlugins, provisioner, cloud)
+		classes, err := LoadProvisionerClasses(s.VolumeConfigFlags.ProvisionerClassesFilePath)
+		if err != nil {
+			glog.Fatalf("Failed to load the storage classes of the provisioner: %+v", err)
+		}
+		pvController, err := persistentvolumecontroller.NewPersistentVolumeProvisionerController(persistentvolumecontroller.NewControllerClient(kubeClient), s.PVClaimBinderSyncPeriod, volumePlugins, provisioner, cloud, classes)
 		if err != nil {
 			glog.Fatalf("Failed to start persistent volume provisioner controller: %+v", err)
 		}
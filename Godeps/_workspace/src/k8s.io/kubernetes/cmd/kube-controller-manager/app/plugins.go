@@ -21,7 +21,9 @@ import (
 	// This should probably be part of some configuration fed into the build for a
 	// given binary target.
 
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 
 	//Cloud providers
 	_ "k8s.io/kubernetes/pkg/cloudprovider/providers"
@@ -35,9 +37,12 @@ import (
 	"k8s.io/kubernetes/pkg/volume"
 	"k8s.io/kubernetes/pkg/volume/aws_ebs"
 	"k8s.io/kubernetes/pkg/volume/cinder"
+	"k8s.io/kubernetes/pkg/volume/csi"
 	"k8s.io/kubernetes/pkg/volume/gce_pd"
 	"k8s.io/kubernetes/pkg/volume/host_path"
+	"k8s.io/kubernetes/pkg/volume/local"
 	"k8s.io/kubernetes/pkg/volume/nfs"
+	"k8s.io/kubernetes/pkg/volume/rbd"
 
 	"github.com/golang/glog"
 )
@@ -78,10 +83,36 @@ func ProbeRecyclableVolumePlugins(flags VolumeConfigFlags) []volume.VolumePlugin
 	allPlugins = append(allPlugins, aws_ebs.ProbeVolumePlugins()...)
 	allPlugins = append(allPlugins, gce_pd.ProbeVolumePlugins()...)
 	allPlugins = append(allPlugins, cinder.ProbeVolumePlugins()...)
+	allPlugins = append(allPlugins, rbd.ProbeVolumePlugins(newRBDVolumeConfig(flags))...)
+	allPlugins = append(allPlugins, csi.ProbeVolumePlugins(newCSIVolumeConfig(flags))...)
+	// Local volumes can't be deleted, their deleters report it.
+	allPlugins = append(allPlugins, local.ProbeVolumePlugins()...)
 
 	return allPlugins
 }
 
+// newRBDVolumeConfig passes the rbd provisioner flags to the rbd plugin.
+func newRBDVolumeConfig(flags VolumeConfigFlags) volume.VolumeConfig {
+	return volume.VolumeConfig{
+		OtherAttributes: map[string]string{
+			rbd.RBDProvisionerMonitors: flags.RBDProvisionerMonitors,
+			rbd.RBDProvisionerPool:     flags.RBDProvisionerPool,
+			rbd.RBDProvisionerUser:     flags.RBDProvisionerUser,
+			rbd.RBDProvisionerKeyring:  flags.RBDProvisionerKeyring,
+		},
+	}
+}
+
+// newCSIVolumeConfig passes the csi provisioner flags to the csi plugin.
+func newCSIVolumeConfig(flags VolumeConfigFlags) volume.VolumeConfig {
+	return volume.VolumeConfig{
+		OtherAttributes: map[string]string{
+			csi.CSISocketDir:         flags.CSISocketDir,
+			csi.CSIProvisionerDriver: flags.CSIProvisionerDriver,
+		},
+	}
+}
+
 // NewVolumeProvisioner returns a volume provisioner to use when running in a cloud or development environment.
 // The beta implementation of provisioning allows 1 implied provisioner per cloud, until we allow configuration of many.
 // We explicitly map clouds to volume plugins here which allows us to configure many later without backwards compatibility issues.
@@ -90,6 +121,10 @@ func NewVolumeProvisioner(cloud cloudprovider.Interface, flags VolumeConfigFlags
 	switch {
 	case cloud == nil && flags.EnableHostPathProvisioning:
 		return getProvisionablePluginFromVolumePlugins(host_path.ProbeVolumePlugins(volume.VolumeConfig{}))
+	case cloud == nil && flags.RBDProvisionerMonitors != "":
+		return getProvisionablePluginFromVolumePlugins(rbd.ProbeVolumePlugins(newRBDVolumeConfig(flags)))
+	case cloud == nil && flags.CSIProvisionerDriver != "":
+		return getProvisionablePluginFromVolumePlugins(csi.ProbeVolumePlugins(newCSIVolumeConfig(flags)))
 	case cloud != nil && aws_cloud.ProviderName == cloud.ProviderName():
 		return getProvisionablePluginFromVolumePlugins(aws_ebs.ProbeVolumePlugins())
 	case cloud != nil && gce_cloud.ProviderName == cloud.ProviderName():
@@ -122,3 +157,21 @@ func AttemptToLoadRecycler(path string, config *volume.VolumeConfig) error {
 	}
 	return nil
 }
+
+// LoadProvisionerClasses reads the storage classes of the provisioner from the JSON object in the file at path,
+// which maps class names to the parameters of the provisioner, e.g. {"fast": {"type": "pd-ssd"}}.  An empty path
+// configures no classes.
+func LoadProvisionerClasses(path string) (map[string]map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	classes := map[string]map[string]string{}
+	if err := json.Unmarshal(data, &classes); err != nil {
+		return nil, fmt.Errorf("failed to decode provisioner classes from %s: %v", path, err)
+	}
+	return classes, nil
+}
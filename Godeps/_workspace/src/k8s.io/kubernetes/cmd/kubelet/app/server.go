@@ -27,9 +27,12 @@ import (
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"k8s.io/kubernetes/pkg/api"
@@ -62,6 +65,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
+	"golang.org/x/net/context"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 )
 
@@ -137,6 +141,11 @@ type KubeletServer struct {
 	TLSCertFile                    string
 	TLSPrivateKeyFile              string
 	ReconcileCIDR                  bool
+	VolumeAuditSink                string
+	VolumePluginDir                string
+	VolumeShutdownGracePeriod      time.Duration
+	VolumeStagingCleanupPolicy     string
+	VolumeUnmountGracePeriod       time.Duration
 
 	// Flags intended for testing
 	// Is the kubelet containerized?
@@ -219,6 +228,10 @@ func NewKubeletServer() *KubeletServer {
 		StreamingConnectionIdleTimeout: 5 * time.Minute,
 		SyncFrequency:                  10 * time.Second,
 		SystemContainer:                "",
+		VolumePluginDir:                "/usr/libexec/kubernetes/kubelet-plugins/volume/exec/",
+		VolumeShutdownGracePeriod:      30 * time.Second,
+		VolumeStagingCleanupPolicy:     string(volume.StagingCleanupAlways),
+		VolumeUnmountGracePeriod:       time.Minute,
 	}
 }
 
@@ -277,6 +290,11 @@ func (s *KubeletServer) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&s.LowDiskSpaceThresholdMB, "low-diskspace-threshold-mb", s.LowDiskSpaceThresholdMB, "The absolute free disk space, in MB, to maintain. When disk space falls below this threshold, new pods would be rejected. Default: 256")
 	fs.StringVar(&s.NetworkPluginName, "network-plugin", s.NetworkPluginName, "<Warning: Alpha feature> The name of the network plugin to be invoked for various events in kubelet/pod lifecycle")
 	fs.StringVar(&s.NetworkPluginDir, "network-plugin-dir", s.NetworkPluginDir, "<Warning: Alpha feature> The full path of the directory in which to search for network plugins")
+	fs.StringVar(&s.VolumePluginDir, "volume-plugin-dir", s.VolumePluginDir, "<Warning: Alpha feature> The full path of the directory in which to search for additional third party volume plugins")
+	fs.StringVar(&s.VolumeAuditSink, "volume-audit-sink", s.VolumeAuditSink, "Where to record every volume set up, tear down, attach and detach: the http:// or https:// URL of a webhook, 'syslog', 'syslog:<tag>' or the path of a file. Empty for no audit.")
+	fs.DurationVar(&s.VolumeShutdownGracePeriod, "volume-shutdown-grace-period", s.VolumeShutdownGracePeriod, "How long to wait on shutdown for the volume mounts and unmounts in progress to finish before aborting them. Default: 30s")
+	fs.StringVar(&s.VolumeStagingCleanupPolicy, "volume-staging-cleanup-policy", s.VolumeStagingCleanupPolicy, "What to do with the directories created while setting up a volume once the step using them has finished: 'AlwaysClean' removes them, 'KeepOnError' keeps those of a failed step for inspection, 'Never' keeps them all. Default: AlwaysClean")
+	fs.DurationVar(&s.VolumeUnmountGracePeriod, "volume-unmount-grace-period", s.VolumeUnmountGracePeriod, "How long to wait for an unmount of an NFS volume before forcing it, and then for the forced one before unmounting lazily, so that a mount whose server is gone does not hang its teardown. 0 never escalates. Default: 1m")
 	fs.StringVar(&s.CloudProvider, "cloud-provider", s.CloudProvider, "The provider for cloud services.  Empty string for no provider.")
 	fs.StringVar(&s.CloudConfigFile, "cloud-config", s.CloudConfigFile, "The path to the cloud provider configuration file.  Empty string for no configuration file.")
 	fs.StringVar(&s.ResourceContainer, "resource-container", s.ResourceContainer, "Absolute name of the resource-only container to create and run the Kubelet in (Default: /kubelet).")
@@ -435,7 +453,8 @@ func (s *KubeletServer) UnsecuredKubeletConfig() (*KubeletConfig, error) {
 		SystemContainer:                s.SystemContainer,
 		TLSOptions:                     tlsOptions,
 		Writer:                         writer,
-		VolumePlugins:                  ProbeVolumePlugins(),
+		VolumePlugins:                  ProbeVolumePlugins(volume.VolumeConfig{UnmountGracePeriod: s.VolumeUnmountGracePeriod}),
+		DynamicPluginProber:            ProbeDynamicPlugins(s.VolumePluginDir),
 	}, nil
 }
 
@@ -480,6 +499,15 @@ func (s *KubeletServer) Run(kcfg *KubeletConfig) error {
 
 	credentialprovider.SetPreferredDockercfgPath(s.RootDirectory)
 
+	auditSink, err := volume.NewAuditSink(s.VolumeAuditSink)
+	if err != nil {
+		return fmt.Errorf("failed to open the volume audit sink %q: %v", s.VolumeAuditSink, err)
+	}
+	volume.SetOperationAuditSink(auditSink)
+	if err := volume.SetStagingCleanupPolicy(volume.StagingCleanupPolicy(s.VolumeStagingCleanupPolicy)); err != nil {
+		return err
+	}
+
 	glog.V(2).Infof("Using root directory: %v", s.RootDirectory)
 
 	// TODO(vmarmol): Do this through container config.
@@ -506,8 +534,24 @@ func (s *KubeletServer) Run(kcfg *KubeletConfig) error {
 		return nil
 	}
 
-	// run forever
-	select {}
+	// run until asked to stop
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	glog.Infof("Received %v, shutting down", <-signals)
+	return s.shutdownVolumeOperations()
+}
+
+// shutdownVolumeOperations stops new volume operations and waits up to
+// VolumeShutdownGracePeriod for the ones in progress, so that the kubelet
+// doesn't exit with a volume half mounted or unmounted.
+func (s *KubeletServer) shutdownVolumeOperations() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.VolumeShutdownGracePeriod)
+	defer cancel()
+	if err := volume.ShutdownOperations(ctx); err != nil {
+		glog.Errorf("Shutting down volume operations failed: %v", err)
+		return err
+	}
+	return nil
 }
 
 // InitializeTLS checks for a configured TLSCertFile and TLSPrivateKeyFile: if unspecified a new self-signed
@@ -895,6 +939,7 @@ type KubeletConfig struct {
 	TLSOptions                     *kubelet.TLSOptions
 	Writer                         io.Writer
 	VolumePlugins                  []volume.VolumePlugin
+	DynamicPluginProber            volume.DynamicPluginProber
 }
 
 func CreateAndInitKubelet(kc *KubeletConfig) (k KubeletBootstrap, pc *config.PodConfig, err error) {
@@ -943,6 +988,7 @@ func CreateAndInitKubelet(kc *KubeletConfig) (k KubeletBootstrap, pc *config.Pod
 		kc.ClusterDNS,
 		kc.MasterServiceNamespace,
 		kc.VolumePlugins,
+		kc.DynamicPluginProber,
 		kc.NetworkPlugins,
 		kc.NetworkPluginName,
 		kc.StreamingConnectionIdleTimeout,
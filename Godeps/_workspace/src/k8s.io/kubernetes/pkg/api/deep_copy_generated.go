@@ -52,6 +52,22 @@ func deepCopy_api_Binding(in Binding, out *Binding, c *conversion.Cloner) error
 	return nil
 }
 
+func deepCopy_api_CSIPersistentVolumeSource(in CSIPersistentVolumeSource, out *CSIPersistentVolumeSource, c *conversion.Cloner) error {
+	out.Driver = in.Driver
+	out.VolumeHandle = in.VolumeHandle
+	out.ReadOnly = in.ReadOnly
+	out.FSType = in.FSType
+	if in.VolumeAttributes != nil {
+		out.VolumeAttributes = make(map[string]string)
+		for key, val := range in.VolumeAttributes {
+			out.VolumeAttributes[key] = val
+		}
+	} else {
+		out.VolumeAttributes = nil
+	}
+	return nil
+}
+
 func deepCopy_api_Capabilities(in Capabilities, out *Capabilities, c *conversion.Cloner) error {
 	if in.Add != nil {
 		out.Add = make([]Capability, len(in.Add))
@@ -81,6 +97,7 @@ func deepCopy_api_CephFSVolumeSource(in CephFSVolumeSource, out *CephFSVolumeSou
 	} else {
 		out.Monitors = nil
 	}
+	out.Path = in.Path
 	out.User = in.User
 	out.SecretFile = in.SecretFile
 	if in.SecretRef != nil {
@@ -150,6 +167,73 @@ func deepCopy_api_ComponentStatusList(in ComponentStatusList, out *ComponentStat
 	return nil
 }
 
+func deepCopy_api_ConfigMap(in ConfigMap, out *ConfigMap, c *conversion.Cloner) error {
+	if err := deepCopy_unversioned_TypeMeta(in.TypeMeta, &out.TypeMeta, c); err != nil {
+		return err
+	}
+	if err := deepCopy_api_ObjectMeta(in.ObjectMeta, &out.ObjectMeta, c); err != nil {
+		return err
+	}
+	if in.Data != nil {
+		out.Data = make(map[string]string)
+		for key, val := range in.Data {
+			out.Data[key] = val
+		}
+	} else {
+		out.Data = nil
+	}
+	return nil
+}
+
+func deepCopy_api_ConfigMapList(in ConfigMapList, out *ConfigMapList, c *conversion.Cloner) error {
+	if err := deepCopy_unversioned_TypeMeta(in.TypeMeta, &out.TypeMeta, c); err != nil {
+		return err
+	}
+	if err := deepCopy_unversioned_ListMeta(in.ListMeta, &out.ListMeta, c); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]ConfigMap, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_api_ConfigMap(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+func deepCopy_api_ConfigMapVolumeSource(in ConfigMapVolumeSource, out *ConfigMapVolumeSource, c *conversion.Cloner) error {
+	if err := deepCopy_api_LocalObjectReference(in.LocalObjectReference, &out.LocalObjectReference, c); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_api_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	if in.Optional != nil {
+		out.Optional = new(bool)
+		*out.Optional = *in.Optional
+	} else {
+		out.Optional = nil
+	}
+	return nil
+}
+
 func deepCopy_api_Container(in Container, out *Container, c *conversion.Cloner) error {
 	out.Name = in.Name
 	out.Image = in.Image
@@ -572,6 +656,29 @@ func deepCopy_api_FCVolumeSource(in FCVolumeSource, out *FCVolumeSource, c *conv
 	return nil
 }
 
+func deepCopy_api_FlexVolumeSource(in FlexVolumeSource, out *FlexVolumeSource, c *conversion.Cloner) error {
+	out.Driver = in.Driver
+	out.FSType = in.FSType
+	if in.SecretRef != nil {
+		out.SecretRef = new(LocalObjectReference)
+		if err := deepCopy_api_LocalObjectReference(*in.SecretRef, out.SecretRef, c); err != nil {
+			return err
+		}
+	} else {
+		out.SecretRef = nil
+	}
+	out.ReadOnly = in.ReadOnly
+	if in.Options != nil {
+		out.Options = make(map[string]string)
+		for key, val := range in.Options {
+			out.Options[key] = val
+		}
+	} else {
+		out.Options = nil
+	}
+	return nil
+}
+
 func deepCopy_api_FlockerVolumeSource(in FlockerVolumeSource, out *FlockerVolumeSource, c *conversion.Cloner) error {
 	out.DatasetName = in.DatasetName
 	return nil
@@ -638,6 +745,7 @@ func deepCopy_api_Handler(in Handler, out *Handler, c *conversion.Cloner) error
 
 func deepCopy_api_HostPathVolumeSource(in HostPathVolumeSource, out *HostPathVolumeSource, c *conversion.Cloner) error {
 	out.Path = in.Path
+	out.Type = in.Type
 	return nil
 }
 
@@ -647,6 +755,26 @@ func deepCopy_api_ISCSIVolumeSource(in ISCSIVolumeSource, out *ISCSIVolumeSource
 	out.Lun = in.Lun
 	out.FSType = in.FSType
 	out.ReadOnly = in.ReadOnly
+	if in.Portals != nil {
+		out.Portals = make([]string, len(in.Portals))
+		for i := range in.Portals {
+			out.Portals[i] = in.Portals[i]
+		}
+	} else {
+		out.Portals = nil
+	}
+	return nil
+}
+
+func deepCopy_api_KeyToPath(in KeyToPath, out *KeyToPath, c *conversion.Cloner) error {
+	out.Key = in.Key
+	out.Path = in.Path
+	if in.Mode != nil {
+		out.Mode = new(int32)
+		*out.Mode = *in.Mode
+	} else {
+		out.Mode = nil
+	}
 	return nil
 }
 
@@ -854,6 +982,11 @@ func deepCopy_api_LocalObjectReference(in LocalObjectReference, out *LocalObject
 	return nil
 }
 
+func deepCopy_api_LocalVolumeSource(in LocalVolumeSource, out *LocalVolumeSource, c *conversion.Cloner) error {
+	out.Path = in.Path
+	return nil
+}
+
 func deepCopy_api_NFSVolumeSource(in NFSVolumeSource, out *NFSVolumeSource, c *conversion.Cloner) error {
 	out.Server = in.Server
 	out.Path = in.Path
@@ -1309,6 +1442,30 @@ func deepCopy_api_PersistentVolumeSource(in PersistentVolumeSource, out *Persist
 	} else {
 		out.Flocker = nil
 	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(FlexVolumeSource)
+		if err := deepCopy_api_FlexVolumeSource(*in.FlexVolume, out.FlexVolume, c); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
+	if in.CSI != nil {
+		out.CSI = new(CSIPersistentVolumeSource)
+		if err := deepCopy_api_CSIPersistentVolumeSource(*in.CSI, out.CSI, c); err != nil {
+			return err
+		}
+	} else {
+		out.CSI = nil
+	}
+	if in.Local != nil {
+		out.Local = new(LocalVolumeSource)
+		if err := deepCopy_api_LocalVolumeSource(*in.Local, out.Local, c); err != nil {
+			return err
+		}
+	} else {
+		out.Local = nil
+	}
 	return nil
 }
 
@@ -1691,6 +1848,26 @@ func deepCopy_api_Probe(in Probe, out *Probe, c *conversion.Cloner) error {
 	return nil
 }
 
+func deepCopy_api_ProjectedVolumeSource(in ProjectedVolumeSource, out *ProjectedVolumeSource, c *conversion.Cloner) error {
+	if in.Sources != nil {
+		out.Sources = make([]VolumeProjection, len(in.Sources))
+		for i := range in.Sources {
+			if err := deepCopy_api_VolumeProjection(in.Sources[i], &out.Sources[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Sources = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	return nil
+}
+
 func deepCopy_api_RBDVolumeSource(in RBDVolumeSource, out *RBDVolumeSource, c *conversion.Cloner) error {
 	if in.CephMonitors != nil {
 		out.CephMonitors = make([]string, len(in.CephMonitors))
@@ -2032,6 +2209,16 @@ func deepCopy_api_SecretList(in SecretList, out *SecretList, c *conversion.Clone
 
 func deepCopy_api_SecretVolumeSource(in SecretVolumeSource, out *SecretVolumeSource, c *conversion.Cloner) error {
 	out.SecretName = in.SecretName
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_api_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -2322,6 +2509,35 @@ func deepCopy_api_VolumeMount(in VolumeMount, out *VolumeMount, c *conversion.Cl
 	return nil
 }
 
+func deepCopy_api_VolumeProjection(in VolumeProjection, out *VolumeProjection, c *conversion.Cloner) error {
+	out.Path = in.Path
+	if in.Secret != nil {
+		out.Secret = new(SecretVolumeSource)
+		if err := deepCopy_api_SecretVolumeSource(*in.Secret, out.Secret, c); err != nil {
+			return err
+		}
+	} else {
+		out.Secret = nil
+	}
+	if in.DownwardAPI != nil {
+		out.DownwardAPI = new(DownwardAPIVolumeSource)
+		if err := deepCopy_api_DownwardAPIVolumeSource(*in.DownwardAPI, out.DownwardAPI, c); err != nil {
+			return err
+		}
+	} else {
+		out.DownwardAPI = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(ConfigMapVolumeSource)
+		if err := deepCopy_api_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	return nil
+}
+
 func deepCopy_api_VolumeSource(in VolumeSource, out *VolumeSource, c *conversion.Cloner) error {
 	if in.HostPath != nil {
 		out.HostPath = new(HostPathVolumeSource)
@@ -2451,6 +2667,30 @@ func deepCopy_api_VolumeSource(in VolumeSource, out *VolumeSource, c *conversion
 	} else {
 		out.FC = nil
 	}
+	if in.Projected != nil {
+		out.Projected = new(ProjectedVolumeSource)
+		if err := deepCopy_api_ProjectedVolumeSource(*in.Projected, out.Projected, c); err != nil {
+			return err
+		}
+	} else {
+		out.Projected = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(ConfigMapVolumeSource)
+		if err := deepCopy_api_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(FlexVolumeSource)
+		if err := deepCopy_api_FlexVolumeSource(*in.FlexVolume, out.FlexVolume, c); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
 	return nil
 }
 
@@ -2502,12 +2742,16 @@ func init() {
 	err := Scheme.AddGeneratedDeepCopyFuncs(
 		deepCopy_api_AWSElasticBlockStoreVolumeSource,
 		deepCopy_api_Binding,
+		deepCopy_api_CSIPersistentVolumeSource,
 		deepCopy_api_Capabilities,
 		deepCopy_api_CephFSVolumeSource,
 		deepCopy_api_CinderVolumeSource,
 		deepCopy_api_ComponentCondition,
 		deepCopy_api_ComponentStatus,
 		deepCopy_api_ComponentStatusList,
+		deepCopy_api_ConfigMap,
+		deepCopy_api_ConfigMapList,
+		deepCopy_api_ConfigMapVolumeSource,
 		deepCopy_api_Container,
 		deepCopy_api_ContainerPort,
 		deepCopy_api_ContainerState,
@@ -2532,6 +2776,7 @@ func init() {
 		deepCopy_api_EventSource,
 		deepCopy_api_ExecAction,
 		deepCopy_api_FCVolumeSource,
+		deepCopy_api_FlexVolumeSource,
 		deepCopy_api_FlockerVolumeSource,
 		deepCopy_api_FSGroupStrategyOptions,
 		deepCopy_api_GCEPersistentDiskVolumeSource,
@@ -2542,6 +2787,7 @@ func init() {
 		deepCopy_api_HostPathVolumeSource,
 		deepCopy_api_IDRange,
 		deepCopy_api_ISCSIVolumeSource,
+		deepCopy_api_KeyToPath,
 		deepCopy_api_Lifecycle,
 		deepCopy_api_LimitRange,
 		deepCopy_api_LimitRangeItem,
@@ -2552,6 +2798,7 @@ func init() {
 		deepCopy_api_LoadBalancerIngress,
 		deepCopy_api_LoadBalancerStatus,
 		deepCopy_api_LocalObjectReference,
+		deepCopy_api_LocalVolumeSource,
 		deepCopy_api_NFSVolumeSource,
 		deepCopy_api_Namespace,
 		deepCopy_api_NamespaceList,
@@ -2593,6 +2840,7 @@ func init() {
 		deepCopy_api_PodTemplateList,
 		deepCopy_api_PodTemplateSpec,
 		deepCopy_api_Probe,
+		deepCopy_api_ProjectedVolumeSource,
 		deepCopy_api_RBDVolumeSource,
 		deepCopy_api_RangeAllocation,
 		deepCopy_api_ReplicationController,
@@ -2625,6 +2873,7 @@ func init() {
 		deepCopy_api_TCPSocketAction,
 		deepCopy_api_Volume,
 		deepCopy_api_VolumeMount,
+		deepCopy_api_VolumeProjection,
 		deepCopy_api_VolumeSource,
 		deepCopy_resource_Quantity,
 		deepCopy_unversioned_ListMeta,
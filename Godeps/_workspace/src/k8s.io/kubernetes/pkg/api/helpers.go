@@ -199,7 +199,7 @@ func LoadBalancerStatusDeepCopy(lb *LoadBalancerStatus) *LoadBalancerStatus {
 }
 
 // GetAccessModesAsString returns a string representation of an array of access modes.
-// modes, when present, are always in the same order: RWO,ROX,RWX.
+// modes, when present, are always in the same order: RWO,ROX,RWX,RWOP.
 func GetAccessModesAsString(modes []PersistentVolumeAccessMode) string {
 	modes = removeDuplicateAccessModes(modes)
 	modesStr := []string{}
@@ -212,6 +212,9 @@ func GetAccessModesAsString(modes []PersistentVolumeAccessMode) string {
 	if containsAccessMode(modes, ReadWriteMany) {
 		modesStr = append(modesStr, "RWX")
 	}
+	if containsAccessMode(modes, ReadWriteOncePod) {
+		modesStr = append(modesStr, "RWOP")
+	}
 	return strings.Join(modesStr, ",")
 }
 
@@ -228,6 +231,8 @@ func GetAccessModesFromString(modes string) []PersistentVolumeAccessMode {
 			accessModes = append(accessModes, ReadOnlyMany)
 		case s == "RWX":
 			accessModes = append(accessModes, ReadWriteMany)
+		case s == "RWOP":
+			accessModes = append(accessModes, ReadWriteOncePod)
 		}
 	}
 	return accessModes
@@ -164,6 +164,14 @@ func TestGetAccessModesFromString(t *testing.T) {
 	if !containsAccessMode(modes, ReadWriteMany) {
 		t.Errorf("Expected mode %s, but got %+v", ReadWriteMany, modes)
 	}
+
+	modes = GetAccessModesFromString("RWO,RWOP")
+	if !containsAccessMode(modes, ReadWriteOncePod) {
+		t.Errorf("Expected mode %s, but got %+v", ReadWriteOncePod, modes)
+	}
+	if s := GetAccessModesAsString(modes); s != "RWO,RWOP" {
+		t.Errorf("Expected RWO,RWOP, got %s", s)
+	}
 }
 
 func TestRemoveDuplicateAccessModes(t *testing.T) {
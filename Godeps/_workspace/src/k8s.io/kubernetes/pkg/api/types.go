@@ -211,6 +211,13 @@ type VolumeSource struct {
 	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
 	// FC represents a Fibre Channel resource that is attached to a kubelet's host machine and then exposed to the pod.
 	FC *FCVolumeSource `json:"fc,omitempty"`
+	// Projected represents several sources projected into the same directory
+	Projected *ProjectedVolumeSource `json:"projected,omitempty"`
+	// ConfigMap represents a configMap that should populate this volume
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// FlexVolume represents a volume that is set up by a vendor-provided
+	// driver binary on the host.
+	FlexVolume *FlexVolumeSource `json:"flexVolume,omitempty"`
 }
 
 // Similar to VolumeSource but meant for the administrator who creates PVs.
@@ -244,6 +251,15 @@ type PersistentVolumeSource struct {
 	FC *FCVolumeSource `json:"fc,omitempty"`
 	// Flocker represents a Flocker volume attached to a kubelet's host machine. This depends on the Flocker control service being running
 	Flocker *FlockerVolumeSource `json:"flocker,omitempty"`
+	// FlexVolume represents a volume that is set up by a vendor-provided
+	// driver binary on the host.
+	FlexVolume *FlexVolumeSource `json:"flexVolume,omitempty"`
+	// CSI represents a volume that is set up by an external driver speaking
+	// the CSI gRPC protocol.
+	CSI *CSIPersistentVolumeSource `json:"csi,omitempty"`
+	// Local represents a pre-provisioned disk or directory on one node,
+	// which pods using it are scheduled to.
+	Local *LocalVolumeSource `json:"local,omitempty"`
 }
 
 type PersistentVolumeClaimVolumeSource struct {
@@ -357,6 +373,8 @@ const (
 	ReadOnlyMany PersistentVolumeAccessMode = "ReadOnlyMany"
 	// can be mounted in read/write mode to many hosts
 	ReadWriteMany PersistentVolumeAccessMode = "ReadWriteMany"
+	// can be mounted in read/write mode for exactly 1 pod
+	ReadWriteOncePod PersistentVolumeAccessMode = "ReadWriteOncePod"
 )
 
 type PersistentVolumePhase string
@@ -389,8 +407,35 @@ const (
 // HostPathVolumeSource represents a host directory mapped into a pod.
 type HostPathVolumeSource struct {
 	Path string `json:"path"`
+	// Type of the path, checked before the volume is set up.
+	Type HostPathType `json:"type,omitempty"`
 }
 
+// HostPathType is the kind of file a hostPath volume expects at its path.
+type HostPathType string
+
+const (
+	// HostPathUnset skips all checks, for compatibility with volumes
+	// created before the type existed.
+	HostPathUnset HostPathType = ""
+	// HostPathDirectoryOrCreate makes an empty directory with mode 0755
+	// if nothing exists at the path.
+	HostPathDirectoryOrCreate HostPathType = "DirectoryOrCreate"
+	// HostPathDirectory requires a directory at the path.
+	HostPathDirectory HostPathType = "Directory"
+	// HostPathFileOrCreate makes an empty file with mode 0644 if nothing
+	// exists at the path.
+	HostPathFileOrCreate HostPathType = "FileOrCreate"
+	// HostPathFile requires a file at the path.
+	HostPathFile HostPathType = "File"
+	// HostPathSocket requires a UNIX socket at the path.
+	HostPathSocket HostPathType = "Socket"
+	// HostPathCharDev requires a character device at the path.
+	HostPathCharDev HostPathType = "CharDevice"
+	// HostPathBlockDev requires a block device at the path.
+	HostPathBlockDev HostPathType = "BlockDevice"
+)
+
 // EmptyDirVolumeSource represents an empty directory for a pod.
 type EmptyDirVolumeSource struct {
 	// TODO: Longer term we want to represent the selection of underlying
@@ -406,8 +451,9 @@ type EmptyDirVolumeSource struct {
 type StorageMedium string
 
 const (
-	StorageMediumDefault StorageMedium = ""       // use whatever the default is for the node
-	StorageMediumMemory  StorageMedium = "Memory" // use memory (tmpfs)
+	StorageMediumDefault   StorageMedium = ""          // use whatever the default is for the node
+	StorageMediumMemory    StorageMedium = "Memory"    // use memory (tmpfs)
+	StorageMediumHugePages StorageMedium = "HugePages" // use hugepages (hugetlbfs)
 )
 
 // Protocol defines network protocols supported for things like conatiner ports.
@@ -459,6 +505,9 @@ type ISCSIVolumeSource struct {
 	// Optional: Defaults to false (read/write). ReadOnly here will force
 	// the ReadOnly setting in VolumeMounts.
 	ReadOnly bool `json:"readOnly,omitempty"`
+	// Optional: additional iSCSI target portals for multipath access to the
+	// same target.  Each is either an IP or ip_addr:port like TargetPortal.
+	Portals []string `json:"portals,omitempty"`
 }
 
 // A Fibre Channel Disk can only be mounted as read/write once.
@@ -511,10 +560,40 @@ type GitRepoVolumeSource struct {
 // SecretVolumeSource adapts a Secret into a VolumeSource.
 //
 // The contents of the target Secret's Data field will be presented in a volume
-// as files using the keys in the Data field as the file names.
+// as files using the keys in the Data field as the file names, unless Items
+// selects the keys to present.
 type SecretVolumeSource struct {
 	// Name of the secret in the pod's namespace to use
 	SecretName string `json:"secretName"`
+	// Items, if set, are the keys of the secret to project into the volume and
+	// the paths to project them to.  Keys not listed are not projected.
+	Items []KeyToPath `json:"items,omitempty"`
+}
+
+// KeyToPath maps a key of a secret or configMap to a file in a volume.
+type KeyToPath struct {
+	// The key to project
+	Key string `json:"key"`
+	// The relative path of the file to project the key to, which must not
+	// contain '..' or start with '..'
+	Path string `json:"path"`
+	// Mode bits of the file, the default mode of the volume if unset
+	Mode *int32 `json:"mode,omitempty"`
+}
+
+// ConfigMapVolumeSource adapts a ConfigMap into a volume.
+type ConfigMapVolumeSource struct {
+	// Name of the configMap in the pod's namespace to use
+	LocalObjectReference `json:",inline"`
+	// Items, if set, are the keys of the configMap to project into the volume
+	// and the paths to project them to.  Keys not listed are not projected.
+	Items []KeyToPath `json:"items,omitempty"`
+	// Optional: Mode bits of the files whose mode isn't set by their item,
+	// 0644 if unset
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+	// Optional: Whether the volume is set up empty if the configMap or one of
+	// the keys selected by items doesn't exist, instead of failing
+	Optional *bool `json:"optional,omitempty"`
 }
 
 // NFSVolumeSource represents an NFS Mount that lasts the lifetime of a pod
@@ -586,6 +665,9 @@ type CinderVolumeSource struct {
 type CephFSVolumeSource struct {
 	// Required: Monitors is a collection of Ceph monitors
 	Monitors []string `json:"monitors"`
+	// Optional: Path is the directory of the filesystem to mount as the
+	// root of the volume, default is /
+	Path string `json:"path,omitempty"`
 	// Optional: User is the rados user name, default is admin
 	User string `json:"user,omitempty"`
 	// Optional: SecretFile is the path to key ring for User, default is /etc/ceph/user.secret
@@ -597,6 +679,55 @@ type CephFSVolumeSource struct {
 	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
+// FlexVolumeSource represents a volume that is set up and torn down by a
+// vendor-provided driver binary installed on the host.
+type FlexVolumeSource struct {
+	// Driver is the name of the driver to use for this volume, in the form
+	// "vendor/driver".
+	Driver string `json:"driver"`
+	// Optional: FSType is the filesystem type to mount, which the driver
+	// is told about.  Its default depends on the driver.
+	FSType string `json:"fsType,omitempty"`
+	// Optional: SecretRef is reference to the secret whose data is passed
+	// to the driver, which may use it to authenticate.
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+	// Optional: Defaults to false (read/write). ReadOnly here will force
+	// the ReadOnly setting in VolumeMounts.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Optional: Options are driver specific options passed to the driver.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// CSIPersistentVolumeSource represents a volume that is set up and torn
+// down by an external driver speaking the CSI gRPC protocol over a Unix
+// socket on the host.
+type CSIPersistentVolumeSource struct {
+	// Driver is the name of the driver to use for this volume, in the form
+	// "vendor/driver".
+	Driver string `json:"driver"`
+	// VolumeHandle is the identifier the driver gave the volume when it
+	// created it.
+	VolumeHandle string `json:"volumeHandle"`
+	// Optional: Defaults to false (read/write). ReadOnly here will force
+	// the ReadOnly setting in VolumeMounts.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Optional: FSType is the filesystem type to mount, which the driver
+	// is told about.  Its default depends on the driver.
+	FSType string `json:"fsType,omitempty"`
+	// Optional: VolumeAttributes are driver specific attributes of the
+	// volume, passed to the driver when the volume is published.
+	VolumeAttributes map[string]string `json:"volumeAttributes,omitempty"`
+}
+
+// LocalVolumeSource represents a disk, partition or directory that is
+// attached to a single node.  Its PersistentVolume must have the node
+// affinity of the node, so that pods using it are scheduled there.
+type LocalVolumeSource struct {
+	// Path is the full path to the volume on the node.  It is either a
+	// directory, typically a mounted disk, or a raw block device.
+	Path string `json:"path"`
+}
+
 // FlockerVolumeSource represents a Flocker volume mounted by the Flocker agent.
 type FlockerVolumeSource struct {
 	// Required: the volume name. This is going to be store on metadata -> name on the payload for Flocker
@@ -617,6 +748,32 @@ type DownwardAPIVolumeFile struct {
 	FieldRef ObjectFieldSelector `json:"fieldRef"`
 }
 
+// ProjectedVolumeSource represents a volume whose files are projected from
+// several sources into one directory.
+type ProjectedVolumeSource struct {
+	// Sources are the sources to project
+	Sources []VolumeProjection `json:"sources"`
+	// Optional: Mode bits of the files whose mode isn't set by their source.
+	// Defaults to the default of each source, 0444 for secrets and 0644 for
+	// the downward API.
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+}
+
+// VolumeProjection is a source projected into a projected volume.  Exactly
+// one of its sources must be set.
+type VolumeProjection struct {
+	// Optional: The relative path of the directory the files of the source are
+	// projected into, the root of the volume if empty.  Must not contain '..'
+	// or start with '..'
+	Path string `json:"path,omitempty"`
+	// Secret projects the keys of a secret
+	Secret *SecretVolumeSource `json:"secret,omitempty"`
+	// DownwardAPI projects information about the pod
+	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
+	// ConfigMap projects the keys of a configMap
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+}
+
 // ContainerPort represents a network port in a single container
 type ContainerPort struct {
 	// Optional: If specified, this must be an IANA_SVC_NAME  Each named port
@@ -1987,6 +2144,27 @@ type SecretList struct {
 	Items []Secret `json:"items"`
 }
 
+// ConfigMap holds configuration data for pods to consume.  The total bytes of
+// the values in the Data field must be less than MaxConfigMapSize bytes.
+type ConfigMap struct {
+	unversioned.TypeMeta `json:",inline"`
+	ObjectMeta           `json:"metadata,omitempty"`
+
+	// Data contains the configuration data.  Each key must be a valid
+	// DNS_SUBDOMAIN or leading dot followed by valid DNS_SUBDOMAIN.
+	Data map[string]string `json:"data,omitempty"`
+}
+
+const MaxConfigMapSize = 1 * 1024 * 1024
+
+// ConfigMapList is a list of ConfigMap.
+type ConfigMapList struct {
+	unversioned.TypeMeta `json:",inline"`
+	unversioned.ListMeta `json:"metadata,omitempty"`
+
+	Items []ConfigMap `json:"items"`
+}
+
 // These constants are for remote command execution and port forwarding and are
 // used by both the client side and server side components.
 //
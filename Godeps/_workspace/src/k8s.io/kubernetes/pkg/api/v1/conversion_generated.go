@@ -61,6 +61,29 @@ func convert_api_Binding_To_v1_Binding(in *api.Binding, out *Binding, s conversi
 	return autoconvert_api_Binding_To_v1_Binding(in, out, s)
 }
 
+func autoconvert_api_CSIPersistentVolumeSource_To_v1_CSIPersistentVolumeSource(in *api.CSIPersistentVolumeSource, out *CSIPersistentVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.CSIPersistentVolumeSource))(in)
+	}
+	out.Driver = in.Driver
+	out.VolumeHandle = in.VolumeHandle
+	out.ReadOnly = in.ReadOnly
+	out.FSType = in.FSType
+	if in.VolumeAttributes != nil {
+		out.VolumeAttributes = make(map[string]string)
+		for key, val := range in.VolumeAttributes {
+			out.VolumeAttributes[key] = val
+		}
+	} else {
+		out.VolumeAttributes = nil
+	}
+	return nil
+}
+
+func convert_api_CSIPersistentVolumeSource_To_v1_CSIPersistentVolumeSource(in *api.CSIPersistentVolumeSource, out *CSIPersistentVolumeSource, s conversion.Scope) error {
+	return autoconvert_api_CSIPersistentVolumeSource_To_v1_CSIPersistentVolumeSource(in, out, s)
+}
+
 func autoconvert_api_Capabilities_To_v1_Capabilities(in *api.Capabilities, out *Capabilities, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.Capabilities))(in)
@@ -100,6 +123,7 @@ func autoconvert_api_CephFSVolumeSource_To_v1_CephFSVolumeSource(in *api.CephFSV
 	} else {
 		out.Monitors = nil
 	}
+	out.Path = in.Path
 	out.User = in.User
 	out.SecretFile = in.SecretFile
 	if in.SecretRef != nil {
@@ -201,6 +225,94 @@ func convert_api_ComponentStatusList_To_v1_ComponentStatusList(in *api.Component
 	return autoconvert_api_ComponentStatusList_To_v1_ComponentStatusList(in, out, s)
 }
 
+func autoconvert_api_ConfigMap_To_v1_ConfigMap(in *api.ConfigMap, out *ConfigMap, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.ConfigMap))(in)
+	}
+	if err := s.Convert(&in.TypeMeta, &out.TypeMeta, 0); err != nil {
+		return err
+	}
+	if err := convert_api_ObjectMeta_To_v1_ObjectMeta(&in.ObjectMeta, &out.ObjectMeta, s); err != nil {
+		return err
+	}
+	if in.Data != nil {
+		out.Data = make(map[string]string)
+		for key, val := range in.Data {
+			out.Data[key] = val
+		}
+	} else {
+		out.Data = nil
+	}
+	return nil
+}
+
+func convert_api_ConfigMap_To_v1_ConfigMap(in *api.ConfigMap, out *ConfigMap, s conversion.Scope) error {
+	return autoconvert_api_ConfigMap_To_v1_ConfigMap(in, out, s)
+}
+
+func autoconvert_api_ConfigMapList_To_v1_ConfigMapList(in *api.ConfigMapList, out *ConfigMapList, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.ConfigMapList))(in)
+	}
+	if err := s.Convert(&in.TypeMeta, &out.TypeMeta, 0); err != nil {
+		return err
+	}
+	if err := s.Convert(&in.ListMeta, &out.ListMeta, 0); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]ConfigMap, len(in.Items))
+		for i := range in.Items {
+			if err := convert_api_ConfigMap_To_v1_ConfigMap(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+func convert_api_ConfigMapList_To_v1_ConfigMapList(in *api.ConfigMapList, out *ConfigMapList, s conversion.Scope) error {
+	return autoconvert_api_ConfigMapList_To_v1_ConfigMapList(in, out, s)
+}
+
+func autoconvert_api_ConfigMapVolumeSource_To_v1_ConfigMapVolumeSource(in *api.ConfigMapVolumeSource, out *ConfigMapVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.ConfigMapVolumeSource))(in)
+	}
+	if err := convert_api_LocalObjectReference_To_v1_LocalObjectReference(&in.LocalObjectReference, &out.LocalObjectReference, s); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := convert_api_KeyToPath_To_v1_KeyToPath(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	if in.Optional != nil {
+		out.Optional = new(bool)
+		*out.Optional = *in.Optional
+	} else {
+		out.Optional = nil
+	}
+	return nil
+}
+
+func convert_api_ConfigMapVolumeSource_To_v1_ConfigMapVolumeSource(in *api.ConfigMapVolumeSource, out *ConfigMapVolumeSource, s conversion.Scope) error {
+	return autoconvert_api_ConfigMapVolumeSource_To_v1_ConfigMapVolumeSource(in, out, s)
+}
+
 func autoconvert_api_Container_To_v1_Container(in *api.Container, out *Container, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.Container))(in)
@@ -791,6 +903,36 @@ func convert_api_FCVolumeSource_To_v1_FCVolumeSource(in *api.FCVolumeSource, out
 	return autoconvert_api_FCVolumeSource_To_v1_FCVolumeSource(in, out, s)
 }
 
+func autoconvert_api_FlexVolumeSource_To_v1_FlexVolumeSource(in *api.FlexVolumeSource, out *FlexVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.FlexVolumeSource))(in)
+	}
+	out.Driver = in.Driver
+	out.FSType = in.FSType
+	if in.SecretRef != nil {
+		out.SecretRef = new(LocalObjectReference)
+		if err := convert_api_LocalObjectReference_To_v1_LocalObjectReference(in.SecretRef, out.SecretRef, s); err != nil {
+			return err
+		}
+	} else {
+		out.SecretRef = nil
+	}
+	out.ReadOnly = in.ReadOnly
+	if in.Options != nil {
+		out.Options = make(map[string]string)
+		for key, val := range in.Options {
+			out.Options[key] = val
+		}
+	} else {
+		out.Options = nil
+	}
+	return nil
+}
+
+func convert_api_FlexVolumeSource_To_v1_FlexVolumeSource(in *api.FlexVolumeSource, out *FlexVolumeSource, s conversion.Scope) error {
+	return autoconvert_api_FlexVolumeSource_To_v1_FlexVolumeSource(in, out, s)
+}
+
 func autoconvert_api_FlockerVolumeSource_To_v1_FlockerVolumeSource(in *api.FlockerVolumeSource, out *FlockerVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.FlockerVolumeSource))(in)
@@ -902,6 +1044,7 @@ func autoconvert_api_HostPathVolumeSource_To_v1_HostPathVolumeSource(in *api.Hos
 		defaulting.(func(*api.HostPathVolumeSource))(in)
 	}
 	out.Path = in.Path
+	out.Type = HostPathType(in.Type)
 	return nil
 }
 
@@ -918,6 +1061,14 @@ func autoconvert_api_ISCSIVolumeSource_To_v1_ISCSIVolumeSource(in *api.ISCSIVolu
 	out.Lun = in.Lun
 	out.FSType = in.FSType
 	out.ReadOnly = in.ReadOnly
+	if in.Portals != nil {
+		out.Portals = make([]string, len(in.Portals))
+		for i := range in.Portals {
+			out.Portals[i] = in.Portals[i]
+		}
+	} else {
+		out.Portals = nil
+	}
 	return nil
 }
 
@@ -925,6 +1076,25 @@ func convert_api_ISCSIVolumeSource_To_v1_ISCSIVolumeSource(in *api.ISCSIVolumeSo
 	return autoconvert_api_ISCSIVolumeSource_To_v1_ISCSIVolumeSource(in, out, s)
 }
 
+func autoconvert_api_KeyToPath_To_v1_KeyToPath(in *api.KeyToPath, out *KeyToPath, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.KeyToPath))(in)
+	}
+	out.Key = in.Key
+	out.Path = in.Path
+	if in.Mode != nil {
+		out.Mode = new(int32)
+		*out.Mode = *in.Mode
+	} else {
+		out.Mode = nil
+	}
+	return nil
+}
+
+func convert_api_KeyToPath_To_v1_KeyToPath(in *api.KeyToPath, out *KeyToPath, s conversion.Scope) error {
+	return autoconvert_api_KeyToPath_To_v1_KeyToPath(in, out, s)
+}
+
 func autoconvert_api_Lifecycle_To_v1_Lifecycle(in *api.Lifecycle, out *Lifecycle, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.Lifecycle))(in)
@@ -1180,6 +1350,18 @@ func convert_api_LocalObjectReference_To_v1_LocalObjectReference(in *api.LocalOb
 	return autoconvert_api_LocalObjectReference_To_v1_LocalObjectReference(in, out, s)
 }
 
+func autoconvert_api_LocalVolumeSource_To_v1_LocalVolumeSource(in *api.LocalVolumeSource, out *LocalVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.LocalVolumeSource))(in)
+	}
+	out.Path = in.Path
+	return nil
+}
+
+func convert_api_LocalVolumeSource_To_v1_LocalVolumeSource(in *api.LocalVolumeSource, out *LocalVolumeSource, s conversion.Scope) error {
+	return autoconvert_api_LocalVolumeSource_To_v1_LocalVolumeSource(in, out, s)
+}
+
 func autoconvert_api_NFSVolumeSource_To_v1_NFSVolumeSource(in *api.NFSVolumeSource, out *NFSVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.NFSVolumeSource))(in)
@@ -1798,6 +1980,30 @@ func autoconvert_api_PersistentVolumeSource_To_v1_PersistentVolumeSource(in *api
 	} else {
 		out.Flocker = nil
 	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(FlexVolumeSource)
+		if err := convert_api_FlexVolumeSource_To_v1_FlexVolumeSource(in.FlexVolume, out.FlexVolume, s); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
+	if in.CSI != nil {
+		out.CSI = new(CSIPersistentVolumeSource)
+		if err := convert_api_CSIPersistentVolumeSource_To_v1_CSIPersistentVolumeSource(in.CSI, out.CSI, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSI = nil
+	}
+	if in.Local != nil {
+		out.Local = new(LocalVolumeSource)
+		if err := convert_api_LocalVolumeSource_To_v1_LocalVolumeSource(in.Local, out.Local, s); err != nil {
+			return err
+		}
+	} else {
+		out.Local = nil
+	}
 	return nil
 }
 
@@ -2244,6 +2450,33 @@ func convert_api_Probe_To_v1_Probe(in *api.Probe, out *Probe, s conversion.Scope
 	return autoconvert_api_Probe_To_v1_Probe(in, out, s)
 }
 
+func autoconvert_api_ProjectedVolumeSource_To_v1_ProjectedVolumeSource(in *api.ProjectedVolumeSource, out *ProjectedVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.ProjectedVolumeSource))(in)
+	}
+	if in.Sources != nil {
+		out.Sources = make([]VolumeProjection, len(in.Sources))
+		for i := range in.Sources {
+			if err := convert_api_VolumeProjection_To_v1_VolumeProjection(&in.Sources[i], &out.Sources[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Sources = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	return nil
+}
+
+func convert_api_ProjectedVolumeSource_To_v1_ProjectedVolumeSource(in *api.ProjectedVolumeSource, out *ProjectedVolumeSource, s conversion.Scope) error {
+	return autoconvert_api_ProjectedVolumeSource_To_v1_ProjectedVolumeSource(in, out, s)
+}
+
 func autoconvert_api_RBDVolumeSource_To_v1_RBDVolumeSource(in *api.RBDVolumeSource, out *RBDVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.RBDVolumeSource))(in)
@@ -2649,6 +2882,16 @@ func autoconvert_api_SecretVolumeSource_To_v1_SecretVolumeSource(in *api.SecretV
 		defaulting.(func(*api.SecretVolumeSource))(in)
 	}
 	out.SecretName = in.SecretName
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := convert_api_KeyToPath_To_v1_KeyToPath(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -3032,6 +3275,42 @@ func convert_api_VolumeMount_To_v1_VolumeMount(in *api.VolumeMount, out *VolumeM
 	return autoconvert_api_VolumeMount_To_v1_VolumeMount(in, out, s)
 }
 
+func autoconvert_api_VolumeProjection_To_v1_VolumeProjection(in *api.VolumeProjection, out *VolumeProjection, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*api.VolumeProjection))(in)
+	}
+	out.Path = in.Path
+	if in.Secret != nil {
+		out.Secret = new(SecretVolumeSource)
+		if err := convert_api_SecretVolumeSource_To_v1_SecretVolumeSource(in.Secret, out.Secret, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secret = nil
+	}
+	if in.DownwardAPI != nil {
+		out.DownwardAPI = new(DownwardAPIVolumeSource)
+		if err := convert_api_DownwardAPIVolumeSource_To_v1_DownwardAPIVolumeSource(in.DownwardAPI, out.DownwardAPI, s); err != nil {
+			return err
+		}
+	} else {
+		out.DownwardAPI = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(ConfigMapVolumeSource)
+		if err := convert_api_ConfigMapVolumeSource_To_v1_ConfigMapVolumeSource(in.ConfigMap, out.ConfigMap, s); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	return nil
+}
+
+func convert_api_VolumeProjection_To_v1_VolumeProjection(in *api.VolumeProjection, out *VolumeProjection, s conversion.Scope) error {
+	return autoconvert_api_VolumeProjection_To_v1_VolumeProjection(in, out, s)
+}
+
 func autoconvert_v1_AWSElasticBlockStoreVolumeSource_To_api_AWSElasticBlockStoreVolumeSource(in *AWSElasticBlockStoreVolumeSource, out *api.AWSElasticBlockStoreVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*AWSElasticBlockStoreVolumeSource))(in)
@@ -3067,6 +3346,29 @@ func convert_v1_Binding_To_api_Binding(in *Binding, out *api.Binding, s conversi
 	return autoconvert_v1_Binding_To_api_Binding(in, out, s)
 }
 
+func autoconvert_v1_CSIPersistentVolumeSource_To_api_CSIPersistentVolumeSource(in *CSIPersistentVolumeSource, out *api.CSIPersistentVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*CSIPersistentVolumeSource))(in)
+	}
+	out.Driver = in.Driver
+	out.VolumeHandle = in.VolumeHandle
+	out.ReadOnly = in.ReadOnly
+	out.FSType = in.FSType
+	if in.VolumeAttributes != nil {
+		out.VolumeAttributes = make(map[string]string)
+		for key, val := range in.VolumeAttributes {
+			out.VolumeAttributes[key] = val
+		}
+	} else {
+		out.VolumeAttributes = nil
+	}
+	return nil
+}
+
+func convert_v1_CSIPersistentVolumeSource_To_api_CSIPersistentVolumeSource(in *CSIPersistentVolumeSource, out *api.CSIPersistentVolumeSource, s conversion.Scope) error {
+	return autoconvert_v1_CSIPersistentVolumeSource_To_api_CSIPersistentVolumeSource(in, out, s)
+}
+
 func autoconvert_v1_Capabilities_To_api_Capabilities(in *Capabilities, out *api.Capabilities, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*Capabilities))(in)
@@ -3106,6 +3408,7 @@ func autoconvert_v1_CephFSVolumeSource_To_api_CephFSVolumeSource(in *CephFSVolum
 	} else {
 		out.Monitors = nil
 	}
+	out.Path = in.Path
 	out.User = in.User
 	out.SecretFile = in.SecretFile
 	if in.SecretRef != nil {
@@ -3207,6 +3510,94 @@ func convert_v1_ComponentStatusList_To_api_ComponentStatusList(in *ComponentStat
 	return autoconvert_v1_ComponentStatusList_To_api_ComponentStatusList(in, out, s)
 }
 
+func autoconvert_v1_ConfigMap_To_api_ConfigMap(in *ConfigMap, out *api.ConfigMap, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*ConfigMap))(in)
+	}
+	if err := s.Convert(&in.TypeMeta, &out.TypeMeta, 0); err != nil {
+		return err
+	}
+	if err := convert_v1_ObjectMeta_To_api_ObjectMeta(&in.ObjectMeta, &out.ObjectMeta, s); err != nil {
+		return err
+	}
+	if in.Data != nil {
+		out.Data = make(map[string]string)
+		for key, val := range in.Data {
+			out.Data[key] = val
+		}
+	} else {
+		out.Data = nil
+	}
+	return nil
+}
+
+func convert_v1_ConfigMap_To_api_ConfigMap(in *ConfigMap, out *api.ConfigMap, s conversion.Scope) error {
+	return autoconvert_v1_ConfigMap_To_api_ConfigMap(in, out, s)
+}
+
+func autoconvert_v1_ConfigMapList_To_api_ConfigMapList(in *ConfigMapList, out *api.ConfigMapList, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*ConfigMapList))(in)
+	}
+	if err := s.Convert(&in.TypeMeta, &out.TypeMeta, 0); err != nil {
+		return err
+	}
+	if err := s.Convert(&in.ListMeta, &out.ListMeta, 0); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]api.ConfigMap, len(in.Items))
+		for i := range in.Items {
+			if err := convert_v1_ConfigMap_To_api_ConfigMap(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+func convert_v1_ConfigMapList_To_api_ConfigMapList(in *ConfigMapList, out *api.ConfigMapList, s conversion.Scope) error {
+	return autoconvert_v1_ConfigMapList_To_api_ConfigMapList(in, out, s)
+}
+
+func autoconvert_v1_ConfigMapVolumeSource_To_api_ConfigMapVolumeSource(in *ConfigMapVolumeSource, out *api.ConfigMapVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*ConfigMapVolumeSource))(in)
+	}
+	if err := convert_v1_LocalObjectReference_To_api_LocalObjectReference(&in.LocalObjectReference, &out.LocalObjectReference, s); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]api.KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := convert_v1_KeyToPath_To_api_KeyToPath(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	if in.Optional != nil {
+		out.Optional = new(bool)
+		*out.Optional = *in.Optional
+	} else {
+		out.Optional = nil
+	}
+	return nil
+}
+
+func convert_v1_ConfigMapVolumeSource_To_api_ConfigMapVolumeSource(in *ConfigMapVolumeSource, out *api.ConfigMapVolumeSource, s conversion.Scope) error {
+	return autoconvert_v1_ConfigMapVolumeSource_To_api_ConfigMapVolumeSource(in, out, s)
+}
+
 func autoconvert_v1_Container_To_api_Container(in *Container, out *api.Container, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*Container))(in)
@@ -3797,6 +4188,36 @@ func convert_v1_FCVolumeSource_To_api_FCVolumeSource(in *FCVolumeSource, out *ap
 	return autoconvert_v1_FCVolumeSource_To_api_FCVolumeSource(in, out, s)
 }
 
+func autoconvert_v1_FlexVolumeSource_To_api_FlexVolumeSource(in *FlexVolumeSource, out *api.FlexVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*FlexVolumeSource))(in)
+	}
+	out.Driver = in.Driver
+	out.FSType = in.FSType
+	if in.SecretRef != nil {
+		out.SecretRef = new(api.LocalObjectReference)
+		if err := convert_v1_LocalObjectReference_To_api_LocalObjectReference(in.SecretRef, out.SecretRef, s); err != nil {
+			return err
+		}
+	} else {
+		out.SecretRef = nil
+	}
+	out.ReadOnly = in.ReadOnly
+	if in.Options != nil {
+		out.Options = make(map[string]string)
+		for key, val := range in.Options {
+			out.Options[key] = val
+		}
+	} else {
+		out.Options = nil
+	}
+	return nil
+}
+
+func convert_v1_FlexVolumeSource_To_api_FlexVolumeSource(in *FlexVolumeSource, out *api.FlexVolumeSource, s conversion.Scope) error {
+	return autoconvert_v1_FlexVolumeSource_To_api_FlexVolumeSource(in, out, s)
+}
+
 func autoconvert_v1_FlockerVolumeSource_To_api_FlockerVolumeSource(in *FlockerVolumeSource, out *api.FlockerVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*FlockerVolumeSource))(in)
@@ -3908,6 +4329,7 @@ func autoconvert_v1_HostPathVolumeSource_To_api_HostPathVolumeSource(in *HostPat
 		defaulting.(func(*HostPathVolumeSource))(in)
 	}
 	out.Path = in.Path
+	out.Type = api.HostPathType(in.Type)
 	return nil
 }
 
@@ -3924,6 +4346,14 @@ func autoconvert_v1_ISCSIVolumeSource_To_api_ISCSIVolumeSource(in *ISCSIVolumeSo
 	out.Lun = in.Lun
 	out.FSType = in.FSType
 	out.ReadOnly = in.ReadOnly
+	if in.Portals != nil {
+		out.Portals = make([]string, len(in.Portals))
+		for i := range in.Portals {
+			out.Portals[i] = in.Portals[i]
+		}
+	} else {
+		out.Portals = nil
+	}
 	return nil
 }
 
@@ -3931,6 +4361,25 @@ func convert_v1_ISCSIVolumeSource_To_api_ISCSIVolumeSource(in *ISCSIVolumeSource
 	return autoconvert_v1_ISCSIVolumeSource_To_api_ISCSIVolumeSource(in, out, s)
 }
 
+func autoconvert_v1_KeyToPath_To_api_KeyToPath(in *KeyToPath, out *api.KeyToPath, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*KeyToPath))(in)
+	}
+	out.Key = in.Key
+	out.Path = in.Path
+	if in.Mode != nil {
+		out.Mode = new(int32)
+		*out.Mode = *in.Mode
+	} else {
+		out.Mode = nil
+	}
+	return nil
+}
+
+func convert_v1_KeyToPath_To_api_KeyToPath(in *KeyToPath, out *api.KeyToPath, s conversion.Scope) error {
+	return autoconvert_v1_KeyToPath_To_api_KeyToPath(in, out, s)
+}
+
 func autoconvert_v1_Lifecycle_To_api_Lifecycle(in *Lifecycle, out *api.Lifecycle, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*Lifecycle))(in)
@@ -4186,6 +4635,18 @@ func convert_v1_LocalObjectReference_To_api_LocalObjectReference(in *LocalObject
 	return autoconvert_v1_LocalObjectReference_To_api_LocalObjectReference(in, out, s)
 }
 
+func autoconvert_v1_LocalVolumeSource_To_api_LocalVolumeSource(in *LocalVolumeSource, out *api.LocalVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*LocalVolumeSource))(in)
+	}
+	out.Path = in.Path
+	return nil
+}
+
+func convert_v1_LocalVolumeSource_To_api_LocalVolumeSource(in *LocalVolumeSource, out *api.LocalVolumeSource, s conversion.Scope) error {
+	return autoconvert_v1_LocalVolumeSource_To_api_LocalVolumeSource(in, out, s)
+}
+
 func autoconvert_v1_NFSVolumeSource_To_api_NFSVolumeSource(in *NFSVolumeSource, out *api.NFSVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*NFSVolumeSource))(in)
@@ -4804,6 +5265,30 @@ func autoconvert_v1_PersistentVolumeSource_To_api_PersistentVolumeSource(in *Per
 	} else {
 		out.Flocker = nil
 	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(api.FlexVolumeSource)
+		if err := convert_v1_FlexVolumeSource_To_api_FlexVolumeSource(in.FlexVolume, out.FlexVolume, s); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
+	if in.CSI != nil {
+		out.CSI = new(api.CSIPersistentVolumeSource)
+		if err := convert_v1_CSIPersistentVolumeSource_To_api_CSIPersistentVolumeSource(in.CSI, out.CSI, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSI = nil
+	}
+	if in.Local != nil {
+		out.Local = new(api.LocalVolumeSource)
+		if err := convert_v1_LocalVolumeSource_To_api_LocalVolumeSource(in.Local, out.Local, s); err != nil {
+			return err
+		}
+	} else {
+		out.Local = nil
+	}
 	return nil
 }
 
@@ -5254,6 +5739,33 @@ func convert_v1_Probe_To_api_Probe(in *Probe, out *api.Probe, s conversion.Scope
 	return autoconvert_v1_Probe_To_api_Probe(in, out, s)
 }
 
+func autoconvert_v1_ProjectedVolumeSource_To_api_ProjectedVolumeSource(in *ProjectedVolumeSource, out *api.ProjectedVolumeSource, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*ProjectedVolumeSource))(in)
+	}
+	if in.Sources != nil {
+		out.Sources = make([]api.VolumeProjection, len(in.Sources))
+		for i := range in.Sources {
+			if err := convert_v1_VolumeProjection_To_api_VolumeProjection(&in.Sources[i], &out.Sources[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Sources = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	return nil
+}
+
+func convert_v1_ProjectedVolumeSource_To_api_ProjectedVolumeSource(in *ProjectedVolumeSource, out *api.ProjectedVolumeSource, s conversion.Scope) error {
+	return autoconvert_v1_ProjectedVolumeSource_To_api_ProjectedVolumeSource(in, out, s)
+}
+
 func autoconvert_v1_RBDVolumeSource_To_api_RBDVolumeSource(in *RBDVolumeSource, out *api.RBDVolumeSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*RBDVolumeSource))(in)
@@ -5657,6 +6169,16 @@ func autoconvert_v1_SecretVolumeSource_To_api_SecretVolumeSource(in *SecretVolum
 		defaulting.(func(*SecretVolumeSource))(in)
 	}
 	out.SecretName = in.SecretName
+	if in.Items != nil {
+		out.Items = make([]api.KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := convert_v1_KeyToPath_To_api_KeyToPath(&in.Items[i], &out.Items[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -6037,6 +6559,42 @@ func convert_v1_VolumeMount_To_api_VolumeMount(in *VolumeMount, out *api.VolumeM
 	return autoconvert_v1_VolumeMount_To_api_VolumeMount(in, out, s)
 }
 
+func autoconvert_v1_VolumeProjection_To_api_VolumeProjection(in *VolumeProjection, out *api.VolumeProjection, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*VolumeProjection))(in)
+	}
+	out.Path = in.Path
+	if in.Secret != nil {
+		out.Secret = new(api.SecretVolumeSource)
+		if err := convert_v1_SecretVolumeSource_To_api_SecretVolumeSource(in.Secret, out.Secret, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secret = nil
+	}
+	if in.DownwardAPI != nil {
+		out.DownwardAPI = new(api.DownwardAPIVolumeSource)
+		if err := convert_v1_DownwardAPIVolumeSource_To_api_DownwardAPIVolumeSource(in.DownwardAPI, out.DownwardAPI, s); err != nil {
+			return err
+		}
+	} else {
+		out.DownwardAPI = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(api.ConfigMapVolumeSource)
+		if err := convert_v1_ConfigMapVolumeSource_To_api_ConfigMapVolumeSource(in.ConfigMap, out.ConfigMap, s); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	return nil
+}
+
+func convert_v1_VolumeProjection_To_api_VolumeProjection(in *VolumeProjection, out *api.VolumeProjection, s conversion.Scope) error {
+	return autoconvert_v1_VolumeProjection_To_api_VolumeProjection(in, out, s)
+}
+
 func convert_api_FSGroupStrategyOptions_To_v1_FSGroupStrategyOptions(in *api.FSGroupStrategyOptions, out *FSGroupStrategyOptions, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*api.FSGroupStrategyOptions))(in)
@@ -6133,11 +6691,15 @@ func init() {
 
 		autoconvert_api_AWSElasticBlockStoreVolumeSource_To_v1_AWSElasticBlockStoreVolumeSource,
 		autoconvert_api_Binding_To_v1_Binding,
+		autoconvert_api_CSIPersistentVolumeSource_To_v1_CSIPersistentVolumeSource,
 		autoconvert_api_Capabilities_To_v1_Capabilities,
 		autoconvert_api_CephFSVolumeSource_To_v1_CephFSVolumeSource,
 		autoconvert_api_CinderVolumeSource_To_v1_CinderVolumeSource,
 		autoconvert_api_ComponentCondition_To_v1_ComponentCondition,
 		autoconvert_api_ComponentStatusList_To_v1_ComponentStatusList,
+		autoconvert_api_ConfigMap_To_v1_ConfigMap,
+		autoconvert_api_ConfigMapList_To_v1_ConfigMapList,
+		autoconvert_api_ConfigMapVolumeSource_To_v1_ConfigMapVolumeSource,
 		autoconvert_api_ComponentStatus_To_v1_ComponentStatus,
 		autoconvert_api_ContainerPort_To_v1_ContainerPort,
 		autoconvert_api_ContainerStateRunning_To_v1_ContainerStateRunning,
@@ -6163,6 +6725,7 @@ func init() {
 		autoconvert_api_Event_To_v1_Event,
 		autoconvert_api_ExecAction_To_v1_ExecAction,
 		autoconvert_api_FCVolumeSource_To_v1_FCVolumeSource,
+		autoconvert_api_FlexVolumeSource_To_v1_FlexVolumeSource,
 		autoconvert_api_FlockerVolumeSource_To_v1_FlockerVolumeSource,
 		autoconvert_api_GCEPersistentDiskVolumeSource_To_v1_GCEPersistentDiskVolumeSource,
 		autoconvert_api_GitRepoVolumeSource_To_v1_GitRepoVolumeSource,
@@ -6171,6 +6734,7 @@ func init() {
 		autoconvert_api_Handler_To_v1_Handler,
 		autoconvert_api_HostPathVolumeSource_To_v1_HostPathVolumeSource,
 		autoconvert_api_ISCSIVolumeSource_To_v1_ISCSIVolumeSource,
+		autoconvert_api_KeyToPath_To_v1_KeyToPath,
 		autoconvert_api_Lifecycle_To_v1_Lifecycle,
 		autoconvert_api_LimitRangeItem_To_v1_LimitRangeItem,
 		autoconvert_api_LimitRangeList_To_v1_LimitRangeList,
@@ -6181,6 +6745,7 @@ func init() {
 		autoconvert_api_LoadBalancerIngress_To_v1_LoadBalancerIngress,
 		autoconvert_api_LoadBalancerStatus_To_v1_LoadBalancerStatus,
 		autoconvert_api_LocalObjectReference_To_v1_LocalObjectReference,
+		autoconvert_api_LocalVolumeSource_To_v1_LocalVolumeSource,
 		autoconvert_api_NFSVolumeSource_To_v1_NFSVolumeSource,
 		autoconvert_api_NamespaceList_To_v1_NamespaceList,
 		autoconvert_api_NamespaceSpec_To_v1_NamespaceSpec,
@@ -6221,6 +6786,7 @@ func init() {
 		autoconvert_api_PodTemplate_To_v1_PodTemplate,
 		autoconvert_api_Pod_To_v1_Pod,
 		autoconvert_api_Probe_To_v1_Probe,
+		autoconvert_api_ProjectedVolumeSource_To_v1_ProjectedVolumeSource,
 		autoconvert_api_RBDVolumeSource_To_v1_RBDVolumeSource,
 		autoconvert_api_RangeAllocation_To_v1_RangeAllocation,
 		autoconvert_api_ReplicationControllerList_To_v1_ReplicationControllerList,
@@ -6247,14 +6813,19 @@ func init() {
 		autoconvert_api_Service_To_v1_Service,
 		autoconvert_api_TCPSocketAction_To_v1_TCPSocketAction,
 		autoconvert_api_VolumeMount_To_v1_VolumeMount,
+		autoconvert_api_VolumeProjection_To_v1_VolumeProjection,
 		autoconvert_api_Volume_To_v1_Volume,
 		autoconvert_v1_AWSElasticBlockStoreVolumeSource_To_api_AWSElasticBlockStoreVolumeSource,
 		autoconvert_v1_Binding_To_api_Binding,
+		autoconvert_v1_CSIPersistentVolumeSource_To_api_CSIPersistentVolumeSource,
 		autoconvert_v1_Capabilities_To_api_Capabilities,
 		autoconvert_v1_CephFSVolumeSource_To_api_CephFSVolumeSource,
 		autoconvert_v1_CinderVolumeSource_To_api_CinderVolumeSource,
 		autoconvert_v1_ComponentCondition_To_api_ComponentCondition,
 		autoconvert_v1_ComponentStatusList_To_api_ComponentStatusList,
+		autoconvert_v1_ConfigMap_To_api_ConfigMap,
+		autoconvert_v1_ConfigMapList_To_api_ConfigMapList,
+		autoconvert_v1_ConfigMapVolumeSource_To_api_ConfigMapVolumeSource,
 		autoconvert_v1_ComponentStatus_To_api_ComponentStatus,
 		autoconvert_v1_ContainerPort_To_api_ContainerPort,
 		autoconvert_v1_ContainerStateRunning_To_api_ContainerStateRunning,
@@ -6280,6 +6851,7 @@ func init() {
 		autoconvert_v1_Event_To_api_Event,
 		autoconvert_v1_ExecAction_To_api_ExecAction,
 		autoconvert_v1_FCVolumeSource_To_api_FCVolumeSource,
+		autoconvert_v1_FlexVolumeSource_To_api_FlexVolumeSource,
 		autoconvert_v1_FlockerVolumeSource_To_api_FlockerVolumeSource,
 		autoconvert_v1_GCEPersistentDiskVolumeSource_To_api_GCEPersistentDiskVolumeSource,
 		autoconvert_v1_GitRepoVolumeSource_To_api_GitRepoVolumeSource,
@@ -6288,6 +6860,7 @@ func init() {
 		autoconvert_v1_Handler_To_api_Handler,
 		autoconvert_v1_HostPathVolumeSource_To_api_HostPathVolumeSource,
 		autoconvert_v1_ISCSIVolumeSource_To_api_ISCSIVolumeSource,
+		autoconvert_v1_KeyToPath_To_api_KeyToPath,
 		autoconvert_v1_Lifecycle_To_api_Lifecycle,
 		autoconvert_v1_LimitRangeItem_To_api_LimitRangeItem,
 		autoconvert_v1_LimitRangeList_To_api_LimitRangeList,
@@ -6298,6 +6871,7 @@ func init() {
 		autoconvert_v1_LoadBalancerIngress_To_api_LoadBalancerIngress,
 		autoconvert_v1_LoadBalancerStatus_To_api_LoadBalancerStatus,
 		autoconvert_v1_LocalObjectReference_To_api_LocalObjectReference,
+		autoconvert_v1_LocalVolumeSource_To_api_LocalVolumeSource,
 		autoconvert_v1_NFSVolumeSource_To_api_NFSVolumeSource,
 		autoconvert_v1_NamespaceList_To_api_NamespaceList,
 		autoconvert_v1_NamespaceSpec_To_api_NamespaceSpec,
@@ -6338,6 +6912,7 @@ func init() {
 		autoconvert_v1_PodTemplate_To_api_PodTemplate,
 		autoconvert_v1_Pod_To_api_Pod,
 		autoconvert_v1_Probe_To_api_Probe,
+		autoconvert_v1_ProjectedVolumeSource_To_api_ProjectedVolumeSource,
 		autoconvert_v1_RBDVolumeSource_To_api_RBDVolumeSource,
 		autoconvert_v1_RangeAllocation_To_api_RangeAllocation,
 		autoconvert_v1_ReplicationControllerList_To_api_ReplicationControllerList,
@@ -6364,6 +6939,7 @@ func init() {
 		autoconvert_v1_Service_To_api_Service,
 		autoconvert_v1_TCPSocketAction_To_api_TCPSocketAction,
 		autoconvert_v1_VolumeMount_To_api_VolumeMount,
+		autoconvert_v1_VolumeProjection_To_api_VolumeProjection,
 		autoconvert_v1_Volume_To_api_Volume,
 
 		convert_api_RunAsUserStrategyOptions_To_v1_RunAsUserStrategyOptions,
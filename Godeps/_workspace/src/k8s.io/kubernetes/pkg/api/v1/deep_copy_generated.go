@@ -88,6 +88,22 @@ func deepCopy_v1_Binding(in Binding, out *Binding, c *conversion.Cloner) error {
 	return nil
 }
 
+func deepCopy_v1_CSIPersistentVolumeSource(in CSIPersistentVolumeSource, out *CSIPersistentVolumeSource, c *conversion.Cloner) error {
+	out.Driver = in.Driver
+	out.VolumeHandle = in.VolumeHandle
+	out.ReadOnly = in.ReadOnly
+	out.FSType = in.FSType
+	if in.VolumeAttributes != nil {
+		out.VolumeAttributes = make(map[string]string)
+		for key, val := range in.VolumeAttributes {
+			out.VolumeAttributes[key] = val
+		}
+	} else {
+		out.VolumeAttributes = nil
+	}
+	return nil
+}
+
 func deepCopy_v1_Capabilities(in Capabilities, out *Capabilities, c *conversion.Cloner) error {
 	if in.Add != nil {
 		out.Add = make([]Capability, len(in.Add))
@@ -117,6 +133,7 @@ func deepCopy_v1_CephFSVolumeSource(in CephFSVolumeSource, out *CephFSVolumeSour
 	} else {
 		out.Monitors = nil
 	}
+	out.Path = in.Path
 	out.User = in.User
 	out.SecretFile = in.SecretFile
 	if in.SecretRef != nil {
@@ -186,6 +203,73 @@ func deepCopy_v1_ComponentStatusList(in ComponentStatusList, out *ComponentStatu
 	return nil
 }
 
+func deepCopy_v1_ConfigMap(in ConfigMap, out *ConfigMap, c *conversion.Cloner) error {
+	if err := deepCopy_unversioned_TypeMeta(in.TypeMeta, &out.TypeMeta, c); err != nil {
+		return err
+	}
+	if err := deepCopy_v1_ObjectMeta(in.ObjectMeta, &out.ObjectMeta, c); err != nil {
+		return err
+	}
+	if in.Data != nil {
+		out.Data = make(map[string]string)
+		for key, val := range in.Data {
+			out.Data[key] = val
+		}
+	} else {
+		out.Data = nil
+	}
+	return nil
+}
+
+func deepCopy_v1_ConfigMapList(in ConfigMapList, out *ConfigMapList, c *conversion.Cloner) error {
+	if err := deepCopy_unversioned_TypeMeta(in.TypeMeta, &out.TypeMeta, c); err != nil {
+		return err
+	}
+	if err := deepCopy_unversioned_ListMeta(in.ListMeta, &out.ListMeta, c); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]ConfigMap, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_v1_ConfigMap(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+func deepCopy_v1_ConfigMapVolumeSource(in ConfigMapVolumeSource, out *ConfigMapVolumeSource, c *conversion.Cloner) error {
+	if err := deepCopy_v1_LocalObjectReference(in.LocalObjectReference, &out.LocalObjectReference, c); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_v1_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	if in.Optional != nil {
+		out.Optional = new(bool)
+		*out.Optional = *in.Optional
+	} else {
+		out.Optional = nil
+	}
+	return nil
+}
+
 func deepCopy_v1_Container(in Container, out *Container, c *conversion.Cloner) error {
 	out.Name = in.Name
 	out.Image = in.Image
@@ -608,6 +692,29 @@ func deepCopy_v1_FCVolumeSource(in FCVolumeSource, out *FCVolumeSource, c *conve
 	return nil
 }
 
+func deepCopy_v1_FlexVolumeSource(in FlexVolumeSource, out *FlexVolumeSource, c *conversion.Cloner) error {
+	out.Driver = in.Driver
+	out.FSType = in.FSType
+	if in.SecretRef != nil {
+		out.SecretRef = new(LocalObjectReference)
+		if err := deepCopy_v1_LocalObjectReference(*in.SecretRef, out.SecretRef, c); err != nil {
+			return err
+		}
+	} else {
+		out.SecretRef = nil
+	}
+	out.ReadOnly = in.ReadOnly
+	if in.Options != nil {
+		out.Options = make(map[string]string)
+		for key, val := range in.Options {
+			out.Options[key] = val
+		}
+	} else {
+		out.Options = nil
+	}
+	return nil
+}
+
 func deepCopy_v1_FlockerVolumeSource(in FlockerVolumeSource, out *FlockerVolumeSource, c *conversion.Cloner) error {
 	out.DatasetName = in.DatasetName
 	return nil
@@ -674,6 +781,7 @@ func deepCopy_v1_Handler(in Handler, out *Handler, c *conversion.Cloner) error {
 
 func deepCopy_v1_HostPathVolumeSource(in HostPathVolumeSource, out *HostPathVolumeSource, c *conversion.Cloner) error {
 	out.Path = in.Path
+	out.Type = in.Type
 	return nil
 }
 
@@ -683,6 +791,26 @@ func deepCopy_v1_ISCSIVolumeSource(in ISCSIVolumeSource, out *ISCSIVolumeSource,
 	out.Lun = in.Lun
 	out.FSType = in.FSType
 	out.ReadOnly = in.ReadOnly
+	if in.Portals != nil {
+		out.Portals = make([]string, len(in.Portals))
+		for i := range in.Portals {
+			out.Portals[i] = in.Portals[i]
+		}
+	} else {
+		out.Portals = nil
+	}
+	return nil
+}
+
+func deepCopy_v1_KeyToPath(in KeyToPath, out *KeyToPath, c *conversion.Cloner) error {
+	out.Key = in.Key
+	out.Path = in.Path
+	if in.Mode != nil {
+		out.Mode = new(int32)
+		*out.Mode = *in.Mode
+	} else {
+		out.Mode = nil
+	}
 	return nil
 }
 
@@ -874,6 +1002,11 @@ func deepCopy_v1_LocalObjectReference(in LocalObjectReference, out *LocalObjectR
 	return nil
 }
 
+func deepCopy_v1_LocalVolumeSource(in LocalVolumeSource, out *LocalVolumeSource, c *conversion.Cloner) error {
+	out.Path = in.Path
+	return nil
+}
+
 func deepCopy_v1_MetadataFile(in MetadataFile, out *MetadataFile, c *conversion.Cloner) error {
 	out.Name = in.Name
 	if err := deepCopy_v1_ObjectFieldSelector(in.FieldRef, &out.FieldRef, c); err != nil {
@@ -1351,6 +1484,30 @@ func deepCopy_v1_PersistentVolumeSource(in PersistentVolumeSource, out *Persiste
 	} else {
 		out.Flocker = nil
 	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(FlexVolumeSource)
+		if err := deepCopy_v1_FlexVolumeSource(*in.FlexVolume, out.FlexVolume, c); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
+	if in.CSI != nil {
+		out.CSI = new(CSIPersistentVolumeSource)
+		if err := deepCopy_v1_CSIPersistentVolumeSource(*in.CSI, out.CSI, c); err != nil {
+			return err
+		}
+	} else {
+		out.CSI = nil
+	}
+	if in.Local != nil {
+		out.Local = new(LocalVolumeSource)
+		if err := deepCopy_v1_LocalVolumeSource(*in.Local, out.Local, c); err != nil {
+			return err
+		}
+	} else {
+		out.Local = nil
+	}
 	return nil
 }
 
@@ -1736,6 +1893,26 @@ func deepCopy_v1_Probe(in Probe, out *Probe, c *conversion.Cloner) error {
 	return nil
 }
 
+func deepCopy_v1_ProjectedVolumeSource(in ProjectedVolumeSource, out *ProjectedVolumeSource, c *conversion.Cloner) error {
+	if in.Sources != nil {
+		out.Sources = make([]VolumeProjection, len(in.Sources))
+		for i := range in.Sources {
+			if err := deepCopy_v1_VolumeProjection(in.Sources[i], &out.Sources[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Sources = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	return nil
+}
+
 func deepCopy_v1_RBDVolumeSource(in RBDVolumeSource, out *RBDVolumeSource, c *conversion.Cloner) error {
 	if in.CephMonitors != nil {
 		out.CephMonitors = make([]string, len(in.CephMonitors))
@@ -2082,6 +2259,16 @@ func deepCopy_v1_SecretList(in SecretList, out *SecretList, c *conversion.Cloner
 
 func deepCopy_v1_SecretVolumeSource(in SecretVolumeSource, out *SecretVolumeSource, c *conversion.Cloner) error {
 	out.SecretName = in.SecretName
+	if in.Items != nil {
+		out.Items = make([]KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_v1_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -2382,6 +2569,35 @@ func deepCopy_v1_VolumeMount(in VolumeMount, out *VolumeMount, c *conversion.Clo
 	return nil
 }
 
+func deepCopy_v1_VolumeProjection(in VolumeProjection, out *VolumeProjection, c *conversion.Cloner) error {
+	out.Path = in.Path
+	if in.Secret != nil {
+		out.Secret = new(SecretVolumeSource)
+		if err := deepCopy_v1_SecretVolumeSource(*in.Secret, out.Secret, c); err != nil {
+			return err
+		}
+	} else {
+		out.Secret = nil
+	}
+	if in.DownwardAPI != nil {
+		out.DownwardAPI = new(DownwardAPIVolumeSource)
+		if err := deepCopy_v1_DownwardAPIVolumeSource(*in.DownwardAPI, out.DownwardAPI, c); err != nil {
+			return err
+		}
+	} else {
+		out.DownwardAPI = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(ConfigMapVolumeSource)
+		if err := deepCopy_v1_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	return nil
+}
+
 func deepCopy_v1_VolumeSource(in VolumeSource, out *VolumeSource, c *conversion.Cloner) error {
 	if in.HostPath != nil {
 		out.HostPath = new(HostPathVolumeSource)
@@ -2511,6 +2727,22 @@ func deepCopy_v1_VolumeSource(in VolumeSource, out *VolumeSource, c *conversion.
 	} else {
 		out.FC = nil
 	}
+	if in.Projected != nil {
+		out.Projected = new(ProjectedVolumeSource)
+		if err := deepCopy_v1_ProjectedVolumeSource(*in.Projected, out.Projected, c); err != nil {
+			return err
+		}
+	} else {
+		out.Projected = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(ConfigMapVolumeSource)
+		if err := deepCopy_v1_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
 
 	if in.Metadata != nil {
 		out.Metadata = new(MetadataVolumeSource)
@@ -2520,6 +2752,14 @@ func deepCopy_v1_VolumeSource(in VolumeSource, out *VolumeSource, c *conversion.
 	} else {
 		out.Metadata = nil
 	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(FlexVolumeSource)
+		if err := deepCopy_v1_FlexVolumeSource(*in.FlexVolume, out.FlexVolume, c); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
 	return nil
 }
 
@@ -2550,12 +2790,16 @@ func init() {
 		deepCopy_unversioned_TypeMeta,
 		deepCopy_v1_AWSElasticBlockStoreVolumeSource,
 		deepCopy_v1_Binding,
+		deepCopy_v1_CSIPersistentVolumeSource,
 		deepCopy_v1_Capabilities,
 		deepCopy_v1_CephFSVolumeSource,
 		deepCopy_v1_CinderVolumeSource,
 		deepCopy_v1_ComponentCondition,
 		deepCopy_v1_ComponentStatus,
 		deepCopy_v1_ComponentStatusList,
+		deepCopy_v1_ConfigMap,
+		deepCopy_v1_ConfigMapList,
+		deepCopy_v1_ConfigMapVolumeSource,
 		deepCopy_v1_Container,
 		deepCopy_v1_ContainerPort,
 		deepCopy_v1_ContainerState,
@@ -2580,6 +2824,7 @@ func init() {
 		deepCopy_v1_EventSource,
 		deepCopy_v1_ExecAction,
 		deepCopy_v1_FCVolumeSource,
+		deepCopy_v1_FlexVolumeSource,
 		deepCopy_v1_FlockerVolumeSource,
 		deepCopy_v1_FSGroupStrategyOptions,
 		deepCopy_v1_GCEPersistentDiskVolumeSource,
@@ -2590,6 +2835,7 @@ func init() {
 		deepCopy_v1_HostPathVolumeSource,
 		deepCopy_v1_IDRange,
 		deepCopy_v1_ISCSIVolumeSource,
+		deepCopy_v1_KeyToPath,
 		deepCopy_v1_Lifecycle,
 		deepCopy_v1_LimitRange,
 		deepCopy_v1_LimitRangeItem,
@@ -2600,6 +2846,7 @@ func init() {
 		deepCopy_v1_LoadBalancerIngress,
 		deepCopy_v1_LoadBalancerStatus,
 		deepCopy_v1_LocalObjectReference,
+		deepCopy_v1_LocalVolumeSource,
 		deepCopy_v1_MetadataFile,
 		deepCopy_v1_MetadataVolumeSource,
 		deepCopy_v1_NFSVolumeSource,
@@ -2643,6 +2890,7 @@ func init() {
 		deepCopy_v1_PodTemplateList,
 		deepCopy_v1_PodTemplateSpec,
 		deepCopy_v1_Probe,
+		deepCopy_v1_ProjectedVolumeSource,
 		deepCopy_v1_RBDVolumeSource,
 		deepCopy_v1_RangeAllocation,
 		deepCopy_v1_ReplicationController,
@@ -2675,6 +2923,7 @@ func init() {
 		deepCopy_v1_TCPSocketAction,
 		deepCopy_v1_Volume,
 		deepCopy_v1_VolumeMount,
+		deepCopy_v1_VolumeProjection,
 		deepCopy_v1_VolumeSource,
 		deepCopy_runtime_RawExtension,
 		deepCopy_util_IntOrString,
@@ -256,6 +256,14 @@ type VolumeSource struct {
 	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
 	// FC represents a Fibre Channel resource that is attached to a kubelet's host machine and then exposed to the pod.
 	FC *FCVolumeSource `json:"fc,omitempty"`
+	// Projected represents several sources, like secrets and downward API information,
+	// projected into one directory.
+	Projected *ProjectedVolumeSource `json:"projected,omitempty"`
+	// ConfigMap represents a configMap that should populate this volume.
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// FlexVolume represents a volume that is set up by a vendor-provided
+	// driver binary on the host.
+	FlexVolume *FlexVolumeSource `json:"flexVolume,omitempty"`
 
 	// Metadata represents metadata about the pod that should populate this volume
 	// NOTE: Deprecated in favor of DownwardAPI
@@ -314,6 +322,15 @@ type PersistentVolumeSource struct {
 	FC *FCVolumeSource `json:"fc,omitempty"`
 	// Flocker represents a Flocker volume attached to a kubelet's host machine and exposed to the pod for its usage. This depends on the Flocker control service being running
 	Flocker *FlockerVolumeSource `json:"flocker,omitempty"`
+	// FlexVolume represents a volume that is set up by a vendor-provided
+	// driver binary on the host.
+	FlexVolume *FlexVolumeSource `json:"flexVolume,omitempty"`
+	// CSI represents a volume that is set up by an external driver speaking
+	// the CSI gRPC protocol.
+	CSI *CSIPersistentVolumeSource `json:"csi,omitempty"`
+	// Local represents a pre-provisioned disk or directory on one node,
+	// which pods using it are scheduled to.
+	Local *LocalVolumeSource `json:"local,omitempty"`
 }
 
 // PersistentVolume (PV) is a storage resource provisioned by an administrator.
@@ -458,6 +475,8 @@ const (
 	ReadOnlyMany PersistentVolumeAccessMode = "ReadOnlyMany"
 	// can be mounted in read/write mode to many hosts
 	ReadWriteMany PersistentVolumeAccessMode = "ReadWriteMany"
+	// can be mounted in read/write mode for exactly 1 pod
+	ReadWriteOncePod PersistentVolumeAccessMode = "ReadWriteOncePod"
 )
 
 type PersistentVolumePhase string
@@ -492,13 +511,40 @@ type HostPathVolumeSource struct {
 	// Path of the directory on the host.
 	// More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#hostpath
 	Path string `json:"path"`
+	// Type of the path, checked before the volume is set up: one of
+	// DirectoryOrCreate, Directory, FileOrCreate, File, Socket, CharDevice
+	// or BlockDevice.  Defaults to "", which skips the checks.
+	// More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#hostpath
+	Type HostPathType `json:"type,omitempty"`
 }
 
+// HostPathType is the kind of file a hostPath volume expects at its path.
+type HostPathType string
+
+const (
+	// HostPathUnset skips all checks.
+	HostPathUnset HostPathType = ""
+	// HostPathDirectoryOrCreate makes an empty directory if nothing exists at the path.
+	HostPathDirectoryOrCreate HostPathType = "DirectoryOrCreate"
+	// HostPathDirectory requires a directory at the path.
+	HostPathDirectory HostPathType = "Directory"
+	// HostPathFileOrCreate makes an empty file if nothing exists at the path.
+	HostPathFileOrCreate HostPathType = "FileOrCreate"
+	// HostPathFile requires a file at the path.
+	HostPathFile HostPathType = "File"
+	// HostPathSocket requires a UNIX socket at the path.
+	HostPathSocket HostPathType = "Socket"
+	// HostPathCharDev requires a character device at the path.
+	HostPathCharDev HostPathType = "CharDevice"
+	// HostPathBlockDev requires a block device at the path.
+	HostPathBlockDev HostPathType = "BlockDevice"
+)
+
 // EmptyDirVolumeSource is temporary directory that shares a pod's lifetime.
 type EmptyDirVolumeSource struct {
 	// What type of storage medium should back this directory.
 	// The default is "" which means to use the node's default medium.
-	// Must be an empty string (default) or Memory.
+	// Must be an empty string (default), Memory or HugePages.
 	// More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#emptydir
 	Medium StorageMedium `json:"medium,omitempty"`
 }
@@ -582,6 +628,9 @@ type CephFSVolumeSource struct {
 	// Required: Monitors is a collection of Ceph monitors
 	// More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it
 	Monitors []string `json:"monitors"`
+	// Optional: Path is the directory of the filesystem to mount as the
+	// root of the volume, default is /
+	Path string `json:"path,omitempty"`
 	// Optional: User is the rados user name, default is admin
 	// More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it
 	User string `json:"user,omitempty"`
@@ -597,6 +646,55 @@ type CephFSVolumeSource struct {
 	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
+// FlexVolumeSource represents a volume that is set up and torn down by a
+// vendor-provided driver binary installed on the host.
+type FlexVolumeSource struct {
+	// Driver is the name of the driver to use for this volume, in the form
+	// "vendor/driver".
+	Driver string `json:"driver"`
+	// Optional: FSType is the filesystem type to mount, which the driver
+	// is told about.  Its default depends on the driver.
+	FSType string `json:"fsType,omitempty"`
+	// Optional: SecretRef is reference to the secret whose data is passed
+	// to the driver, which may use it to authenticate.
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+	// Optional: Defaults to false (read/write). ReadOnly here will force
+	// the ReadOnly setting in VolumeMounts.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Optional: Options are driver specific options passed to the driver.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// CSIPersistentVolumeSource represents a volume that is set up and torn
+// down by an external driver speaking the CSI gRPC protocol over a Unix
+// socket on the host.
+type CSIPersistentVolumeSource struct {
+	// Driver is the name of the driver to use for this volume, in the form
+	// "vendor/driver".
+	Driver string `json:"driver"`
+	// VolumeHandle is the identifier the driver gave the volume when it
+	// created it.
+	VolumeHandle string `json:"volumeHandle"`
+	// Optional: Defaults to false (read/write). ReadOnly here will force
+	// the ReadOnly setting in VolumeMounts.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Optional: FSType is the filesystem type to mount, which the driver
+	// is told about.  Its default depends on the driver.
+	FSType string `json:"fsType,omitempty"`
+	// Optional: VolumeAttributes are driver specific attributes of the
+	// volume, passed to the driver when the volume is published.
+	VolumeAttributes map[string]string `json:"volumeAttributes,omitempty"`
+}
+
+// LocalVolumeSource represents a disk, partition or directory that is
+// attached to a single node.  Its PersistentVolume must have the node
+// affinity of the node, so that pods using it are scheduled there.
+type LocalVolumeSource struct {
+	// Path is the full path to the volume on the node.  It is either a
+	// directory, typically a mounted disk, or a raw block device.
+	Path string `json:"path"`
+}
+
 // FlockerVolumeSource represents a Flocker volume mounted by the Flocker agent.
 type FlockerVolumeSource struct {
 	// Required: the volume name. This is going to be store on metadata -> name on the payload for Flocker
@@ -604,8 +702,9 @@ type FlockerVolumeSource struct {
 }
 
 const (
-	StorageMediumDefault StorageMedium = ""       // use whatever the default is for the node
-	StorageMediumMemory  StorageMedium = "Memory" // use memory (tmpfs)
+	StorageMediumDefault   StorageMedium = ""          // use whatever the default is for the node
+	StorageMediumMemory    StorageMedium = "Memory"    // use memory (tmpfs)
+	StorageMediumHugePages StorageMedium = "HugePages" // use hugepages (hugetlbfs)
 )
 
 // Protocol defines network protocols supported for things like conatiner ports.
@@ -685,6 +784,36 @@ type SecretVolumeSource struct {
 	// SecretName is the name of a secret in the pod's namespace.
 	// More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#secrets
 	SecretName string `json:"secretName"`
+	// Items, if specified, are the keys of the secret to project into the volume
+	// and the paths to project them to. Keys that are not listed are not projected.
+	// If unspecified, every key is projected into a file named after the key.
+	Items []KeyToPath `json:"items,omitempty"`
+}
+
+// KeyToPath maps a key of a secret or configMap to a file in a volume.
+type KeyToPath struct {
+	// Key is the key to project.
+	Key string `json:"key"`
+	// Path is the relative path of the file to project the key to.
+	// It may not be an absolute path, contain '..' or start with '..'.
+	Path string `json:"path"`
+	// Mode is the mode bits of the file. Defaults to the default mode of the volume.
+	Mode *int32 `json:"mode,omitempty"`
+}
+
+// ConfigMapVolumeSource adapts a ConfigMap into a volume.
+type ConfigMapVolumeSource struct {
+	LocalObjectReference `json:",inline"`
+	// Items, if specified, are the keys of the configMap to project into the volume
+	// and the paths to project them to. Keys that are not listed are not projected.
+	// If unspecified, every key is projected into a file named after the key.
+	Items []KeyToPath `json:"items,omitempty"`
+	// DefaultMode is the mode bits of the files whose mode is not set by their item.
+	// Defaults to 0644.
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+	// Optional specifies whether the volume is set up empty when the configMap or one
+	// of the keys selected by items does not exist, instead of failing.
+	Optional *bool `json:"optional,omitempty"`
 }
 
 // NFSVolumeSource represents an NFS mount that lasts the lifetime of a pod
@@ -722,6 +851,10 @@ type ISCSIVolumeSource struct {
 	// ReadOnly here will force the ReadOnly setting in VolumeMounts.
 	// Defaults to false.
 	ReadOnly bool `json:"readOnly,omitempty"`
+	// iSCSI target portals in addition to TargetPortal, used to reach the
+	// same target over multiple paths. Each portal is either an IP or
+	// ip_addr:port if the port is other than default (typically TCP ports 860 and 3260).
+	Portals []string `json:"portals,omitempty"`
 }
 
 // A Fibre Channel Disk can only be mounted as read/write once.
@@ -2453,6 +2586,29 @@ type SecretList struct {
 	Items []Secret `json:"items"`
 }
 
+// ConfigMap holds configuration data for pods to consume.
+type ConfigMap struct {
+	unversioned.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	ObjectMeta `json:"metadata,omitempty"`
+
+	// Data contains the configuration data.
+	// Each key must be a valid DNS_SUBDOMAIN or leading dot followed by valid DNS_SUBDOMAIN.
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// ConfigMapList is a resource containing a list of ConfigMap objects.
+type ConfigMapList struct {
+	unversioned.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#types-kinds
+	unversioned.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of ConfigMaps.
+	Items []ConfigMap `json:"items"`
+}
+
 // Type and constants for component health validation.
 type ComponentConditionType string
 
@@ -2529,6 +2685,30 @@ type DownwardAPIVolumeFile struct {
 	FieldRef ObjectFieldSelector `json:"fieldRef"`
 }
 
+// ProjectedVolumeSource represents a volume whose files are projected from several
+// sources into one directory.
+type ProjectedVolumeSource struct {
+	// Sources are the sources to project.
+	Sources []VolumeProjection `json:"sources"`
+	// DefaultMode is the mode bits of the files whose mode is not set by their source.
+	// Defaults to the default of each source: 0444 for secrets and 0644 for the downward API.
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+}
+
+// VolumeProjection is a source projected into a projected volume.
+// Exactly one of its sources must be set.
+type VolumeProjection struct {
+	// Path is the relative path of the directory the files of the source are projected into.
+	// It may not contain '..' or start with '..'. Defaults to the root of the volume.
+	Path string `json:"path,omitempty"`
+	// Secret projects the keys of a secret in the pod's namespace.
+	Secret *SecretVolumeSource `json:"secret,omitempty"`
+	// DownwardAPI projects information about the pod.
+	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
+	// ConfigMap projects the keys of a configMap in the pod's namespace.
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+}
+
 // SecurityContext holds security configuration that will be applied to a container.
 // Some fields are present in both SecurityContext and PodSecurityContext.  When both
 // are set, the values in SecurityContext take precedence.
@@ -49,6 +49,19 @@ func (Binding) SwaggerDoc() map[string]string {
 	return map_Binding
 }
 
+var map_CSIPersistentVolumeSource = map[string]string{
+	"":                 "CSIPersistentVolumeSource represents a volume that is set up and torn down by an external driver speaking the CSI gRPC protocol over a Unix socket on the host.",
+	"driver":           "Driver is the name of the driver to use for this volume, in the form \"vendor/driver\".",
+	"volumeHandle":     "VolumeHandle is the identifier the driver gave the volume when it created it.",
+	"readOnly":         "Optional: Defaults to false (read/write). ReadOnly here will force the ReadOnly setting in VolumeMounts.",
+	"fsType":           "Optional: FSType is the filesystem type to mount, which the driver is told about.  Its default depends on the driver.",
+	"volumeAttributes": "Optional: VolumeAttributes are driver specific attributes of the volume, passed to the driver when the volume is published.",
+}
+
+func (CSIPersistentVolumeSource) SwaggerDoc() map[string]string {
+	return map_CSIPersistentVolumeSource
+}
+
 var map_Capabilities = map[string]string{
 	"":     "Adds and removes POSIX capabilities from running containers.",
 	"add":  "Added capabilities",
@@ -62,6 +75,7 @@ func (Capabilities) SwaggerDoc() map[string]string {
 var map_CephFSVolumeSource = map[string]string{
 	"":           "CephFSVolumeSource represents a Ceph Filesystem Mount that lasts the lifetime of a pod",
 	"monitors":   "Required: Monitors is a collection of Ceph monitors More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it",
+	"path":       "Optional: Path is the directory of the filesystem to mount as the root of the volume, default is /",
 	"user":       "Optional: User is the rados user name, default is admin More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it",
 	"secretFile": "Optional: SecretFile is the path to key ring for User, default is /etc/ceph/user.secret More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it",
 	"secretRef":  "Optional: SecretRef is reference to the authentication secret for User, default is empty. More info: http://releases.k8s.io/HEAD/examples/cephfs/README.md#how-to-use-it",
@@ -115,6 +129,37 @@ func (ComponentStatusList) SwaggerDoc() map[string]string {
 	return map_ComponentStatusList
 }
 
+var map_ConfigMap = map[string]string{
+	"":         "ConfigMap holds configuration data for pods to consume.",
+	"metadata": "Standard object's metadata. More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata",
+	"data":     "Data contains the configuration data. Each key must be a valid DNS_SUBDOMAIN or leading dot followed by valid DNS_SUBDOMAIN.",
+}
+
+func (ConfigMap) SwaggerDoc() map[string]string {
+	return map_ConfigMap
+}
+
+var map_ConfigMapList = map[string]string{
+	"":         "ConfigMapList is a resource containing a list of ConfigMap objects.",
+	"metadata": "Standard list metadata. More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#types-kinds",
+	"items":    "Items is the list of ConfigMaps.",
+}
+
+func (ConfigMapList) SwaggerDoc() map[string]string {
+	return map_ConfigMapList
+}
+
+var map_ConfigMapVolumeSource = map[string]string{
+	"":            "ConfigMapVolumeSource adapts a ConfigMap into a volume.",
+	"items":       "Items, if specified, are the keys of the configMap to project into the volume and the paths to project them to. Keys that are not listed are not projected. If unspecified, every key is projected into a file named after the key.",
+	"defaultMode": "DefaultMode is the mode bits of the files whose mode is not set by their item. Defaults to 0644.",
+	"optional":    "Optional specifies whether the volume is set up empty when the configMap or one of the keys selected by items does not exist, instead of failing.",
+}
+
+func (ConfigMapVolumeSource) SwaggerDoc() map[string]string {
+	return map_ConfigMapVolumeSource
+}
+
 var map_Container = map[string]string{
 	"":                       "A single application container that you want to run within a pod.",
 	"name":                   "Name of the container specified as a DNS_LABEL. Each container in a pod must have a unique name (DNS_LABEL). Cannot be updated.",
@@ -254,7 +299,7 @@ func (DownwardAPIVolumeSource) SwaggerDoc() map[string]string {
 
 var map_EmptyDirVolumeSource = map[string]string{
 	"":       "EmptyDirVolumeSource is temporary directory that shares a pod's lifetime.",
-	"medium": "What type of storage medium should back this directory. The default is \"\" which means to use the node's default medium. Must be an empty string (default) or Memory. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#emptydir",
+	"medium": "What type of storage medium should back this directory. The default is \"\" which means to use the node's default medium. Must be an empty string (default), Memory or HugePages. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#emptydir",
 }
 
 func (EmptyDirVolumeSource) SwaggerDoc() map[string]string {
@@ -390,6 +435,19 @@ func (FCVolumeSource) SwaggerDoc() map[string]string {
 	return map_FCVolumeSource
 }
 
+var map_FlexVolumeSource = map[string]string{
+	"":          "FlexVolumeSource represents a volume that is set up and torn down by a vendor-provided driver binary installed on the host.",
+	"driver":    "Driver is the name of the driver to use for this volume, in the form \"vendor/driver\".",
+	"fsType":    "Optional: FSType is the filesystem type to mount, which the driver is told about.  Its default depends on the driver.",
+	"secretRef": "Optional: SecretRef is reference to the secret whose data is passed to the driver, which may use it to authenticate.",
+	"readOnly":  "Optional: Defaults to false (read/write). ReadOnly here will force the ReadOnly setting in VolumeMounts.",
+	"options":   "Optional: Options are driver specific options passed to the driver.",
+}
+
+func (FlexVolumeSource) SwaggerDoc() map[string]string {
+	return map_FlexVolumeSource
+}
+
 var map_FlockerVolumeSource = map[string]string{
 	"":            "FlockerVolumeSource represents a Flocker volume mounted by the Flocker agent.",
 	"datasetName": "Required: the volume name. This is going to be store on metadata -> name on the payload for Flocker",
@@ -458,6 +516,7 @@ func (Handler) SwaggerDoc() map[string]string {
 var map_HostPathVolumeSource = map[string]string{
 	"":     "HostPathVolumeSource represents bare host directory volume.",
 	"path": "Path of the directory on the host. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#hostpath",
+	"type": "Type of the path, checked before the volume is set up: one of DirectoryOrCreate, Directory, FileOrCreate, File, Socket, CharDevice or BlockDevice.  Defaults to \"\", which skips the checks. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#hostpath",
 }
 
 func (HostPathVolumeSource) SwaggerDoc() map[string]string {
@@ -471,12 +530,24 @@ var map_ISCSIVolumeSource = map[string]string{
 	"lun":          "iSCSI target lun number.",
 	"fsType":       "Filesystem type of the volume that you want to mount. Tip: Ensure that the filesystem type is supported by the host operating system. Examples: \"ext4\", \"xfs\", \"ntfs\". More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#iscsi",
 	"readOnly":     "ReadOnly here will force the ReadOnly setting in VolumeMounts. Defaults to false.",
+	"portals":      "iSCSI target portals in addition to TargetPortal, used to reach the same target over multiple paths. Each portal is either an IP or ip_addr:port if the port is other than default (typically TCP ports 860 and 3260).",
 }
 
 func (ISCSIVolumeSource) SwaggerDoc() map[string]string {
 	return map_ISCSIVolumeSource
 }
 
+var map_KeyToPath = map[string]string{
+	"":     "KeyToPath maps a key of a secret or configMap to a file in a volume.",
+	"key":  "Key is the key to project.",
+	"path": "Path is the relative path of the file to project the key to. It may not be an absolute path, contain '..' or start with '..'.",
+	"mode": "Mode is the mode bits of the file. Defaults to the default mode of the volume.",
+}
+
+func (KeyToPath) SwaggerDoc() map[string]string {
+	return map_KeyToPath
+}
+
 var map_Lifecycle = map[string]string{
 	"":          "Lifecycle describes actions that the management system should take in response to container lifecycle events. For the PostStart and PreStop lifecycle handlers, management of the container blocks until the action is complete, unless the container process fails, in which case the handler is aborted.",
 	"postStart": "PostStart is called immediately after a container is created. If the handler fails, the container is terminated and restarted according to its restart policy. Other management of the container blocks until the hook completes. More info: http://releases.k8s.io/HEAD/docs/user-guide/container-environment.md#hook-details",
@@ -580,6 +651,15 @@ func (LocalObjectReference) SwaggerDoc() map[string]string {
 	return map_LocalObjectReference
 }
 
+var map_LocalVolumeSource = map[string]string{
+	"":     "LocalVolumeSource represents a disk, partition or directory that is attached to a single node.  Its PersistentVolume must have the node affinity of the node, so that pods using it are scheduled there.",
+	"path": "Path is the full path to the volume on the node.  It is either a directory, typically a mounted disk, or a raw block device.",
+}
+
+func (LocalVolumeSource) SwaggerDoc() map[string]string {
+	return map_LocalVolumeSource
+}
+
 var map_NFSVolumeSource = map[string]string{
 	"":         "NFSVolumeSource represents an NFS mount that lasts the lifetime of a pod",
 	"server":   "Server is the hostname or IP address of the NFS server. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#nfs",
@@ -858,6 +938,9 @@ var map_PersistentVolumeSource = map[string]string{
 	"cephfs":               "CephFS represents a Ceph FS mount on the host that shares a pod's lifetime",
 	"fc":                   "FC represents a Fibre Channel resource that is attached to a kubelet's host machine and then exposed to the pod.",
 	"flocker":              "Flocker represents a Flocker volume attached to a kubelet's host machine and exposed to the pod for its usage. This depends on the Flocker control service being running",
+	"flexVolume":           "FlexVolume represents a volume that is set up by a vendor-provided driver binary on the host.",
+	"csi":                  "CSI represents a volume that is set up by an external driver speaking the CSI gRPC protocol.",
+	"local":                "Local represents a pre-provisioned disk or directory on one node, which pods using it are scheduled to.",
 }
 
 func (PersistentVolumeSource) SwaggerDoc() map[string]string {
@@ -1076,6 +1159,16 @@ func (Probe) SwaggerDoc() map[string]string {
 	return map_Probe
 }
 
+var map_ProjectedVolumeSource = map[string]string{
+	"":            "ProjectedVolumeSource represents a volume whose files are projected from several sources into one directory.",
+	"sources":     "Sources are the sources to project.",
+	"defaultMode": "DefaultMode is the mode bits of the files whose mode is not set by their source. Defaults to the default of each source: 0444 for secrets and 0644 for the downward API.",
+}
+
+func (ProjectedVolumeSource) SwaggerDoc() map[string]string {
+	return map_ProjectedVolumeSource
+}
+
 var map_RBDVolumeSource = map[string]string{
 	"":          "RBDVolumeSource represents a Rados Block Device Mount that lasts the lifetime of a pod",
 	"monitors":  "A collection of Ceph monitors. More info: http://releases.k8s.io/HEAD/examples/rbd/README.md#how-to-use-it",
@@ -1231,6 +1324,7 @@ func (SecretList) SwaggerDoc() map[string]string {
 var map_SecretVolumeSource = map[string]string{
 	"":           "SecretVolumeSource adapts a Secret into a VolumeSource. More info: http://releases.k8s.io/HEAD/docs/design/secrets.md",
 	"secretName": "SecretName is the name of a secret in the pod's namespace. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#secrets",
+	"items":      "Items, if specified, are the keys of the secret to project into the volume and the paths to project them to. Keys that are not listed are not projected. If unspecified, every key is projected into a file named after the key.",
 }
 
 func (SecretVolumeSource) SwaggerDoc() map[string]string {
@@ -1368,6 +1462,18 @@ func (VolumeMount) SwaggerDoc() map[string]string {
 	return map_VolumeMount
 }
 
+var map_VolumeProjection = map[string]string{
+	"":            "VolumeProjection is a source projected into a projected volume. Exactly one of its sources must be set.",
+	"path":        "Path is the relative path of the directory the files of the source are projected into. It may not contain '..' or start with '..'. Defaults to the root of the volume.",
+	"secret":      "Secret projects the keys of a secret in the pod's namespace.",
+	"downwardAPI": "DownwardAPI projects information about the pod.",
+	"configMap":   "ConfigMap projects the keys of a configMap in the pod's namespace.",
+}
+
+func (VolumeProjection) SwaggerDoc() map[string]string {
+	return map_VolumeProjection
+}
+
 var map_VolumeSource = map[string]string{
 	"":                      "VolumeSource represents the source location of a volume to mount. Only one of its members may be specified.",
 	"hostPath":              "HostPath represents a pre-existing file or directory on the host machine that is directly exposed to the container. This is generally used for system agents or other privileged things that are allowed to see the host machine. Most containers will NOT need this. More info: http://releases.k8s.io/HEAD/docs/user-guide/volumes.md#hostpath",
@@ -1386,6 +1492,9 @@ var map_VolumeSource = map[string]string{
 	"flocker":     "Flocker represents a Flocker volume attached to a kubelet's host machine. This depends on the Flocker control service being running",
 	"downwardAPI": "DownwardAPI represents downward API about the pod that should populate this volume",
 	"fc":          "FC represents a Fibre Channel resource that is attached to a kubelet's host machine and then exposed to the pod.",
+	"projected":   "Projected represents several sources, like secrets and downward API information, projected into one directory.",
+	"configMap":   "ConfigMap represents a configMap that should populate this volume.",
+	"flexVolume":  "FlexVolume represents a volume that is set up by a vendor-provided driver binary on the host.",
 }
 
 func (VolumeSource) SwaggerDoc() map[string]string {
@@ -169,6 +169,13 @@ func ValidateSecretName(name string, prefix bool) (bool, string) {
 	return NameIsDNSSubdomain(name, prefix)
 }
 
+// ValidateConfigMapName can be used to check whether the given configMap name is valid.
+// Prefix indicates this name will be used as part of generation, in which case
+// trailing dashes are allowed.
+func ValidateConfigMapName(name string, prefix bool) (bool, string) {
+	return NameIsDNSSubdomain(name, prefix)
+}
+
 // ValidateServiceAccountName can be used to check whether the given service account name is valid.
 // Prefix indicates this name will be used as part of generation, in which case
 // trailing dashes are allowed.
@@ -430,6 +437,18 @@ func validateSource(source *api.VolumeSource) errs.ValidationErrorList {
 		numVolumes++
 		allErrs = append(allErrs, validateFCVolumeSource(source.FC).Prefix("fc")...)
 	}
+	if source.Projected != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateProjectedVolumeSource(source.Projected).Prefix("projected")...)
+	}
+	if source.ConfigMap != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateConfigMapVolumeSource(source.ConfigMap).Prefix("configMap")...)
+	}
+	if source.FlexVolume != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateFlexVolumeSource(source.FlexVolume).Prefix("flexVolume")...)
+	}
 	if numVolumes != 1 {
 		allErrs = append(allErrs, errs.NewFieldInvalid("", source, "exactly 1 volume type is required"))
 	}
@@ -437,11 +456,16 @@ func validateSource(source *api.VolumeSource) errs.ValidationErrorList {
 	return allErrs
 }
 
+var supportedHostPathTypes = sets.NewString(string(api.HostPathDirectoryOrCreate), string(api.HostPathDirectory), string(api.HostPathFileOrCreate), string(api.HostPathFile), string(api.HostPathSocket), string(api.HostPathCharDev), string(api.HostPathBlockDev))
+
 func validateHostPathVolumeSource(hostPath *api.HostPathVolumeSource) errs.ValidationErrorList {
 	allErrs := errs.ValidationErrorList{}
 	if hostPath.Path == "" {
 		allErrs = append(allErrs, errs.NewFieldRequired("path"))
 	}
+	if hostPath.Type != api.HostPathUnset && !supportedHostPathTypes.Has(string(hostPath.Type)) {
+		allErrs = append(allErrs, errs.NewFieldValueNotSupported("type", hostPath.Type, supportedHostPathTypes.List()))
+	}
 	return allErrs
 }
 
@@ -467,6 +491,11 @@ func validateISCSIVolumeSource(iscsi *api.ISCSIVolumeSource) errs.ValidationErro
 	if iscsi.Lun < 0 || iscsi.Lun > 255 {
 		allErrs = append(allErrs, errs.NewFieldInvalid("lun", iscsi.Lun, ""))
 	}
+	for i, portal := range iscsi.Portals {
+		if portal == "" {
+			allErrs = append(allErrs, errs.NewFieldRequired(fmt.Sprintf("portals[%d]", i)))
+		}
+	}
 	return allErrs
 }
 
@@ -522,6 +551,37 @@ func validateSecretVolumeSource(secretSource *api.SecretVolumeSource) errs.Valid
 	if secretSource.SecretName == "" {
 		allErrs = append(allErrs, errs.NewFieldRequired("secretName"))
 	}
+	allErrs = append(allErrs, validateKeyToPaths(secretSource.Items).Prefix("items")...)
+	return allErrs
+}
+
+func validateConfigMapVolumeSource(configMapSource *api.ConfigMapVolumeSource) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if configMapSource.Name == "" {
+		allErrs = append(allErrs, errs.NewFieldRequired("name"))
+	}
+	allErrs = append(allErrs, validateKeyToPaths(configMapSource.Items).Prefix("items")...)
+	if configMapSource.DefaultMode != nil && (*configMapSource.DefaultMode < 0 || *configMapSource.DefaultMode > 0777) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("defaultMode", *configMapSource.DefaultMode, "must be between 0 and 0777"))
+	}
+	return allErrs
+}
+
+// validateKeyToPaths validates the keys of a secret or configMap projected
+// into a volume.
+func validateKeyToPaths(items []api.KeyToPath) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	for i, item := range items {
+		itemErrs := errs.ValidationErrorList{}
+		if item.Key == "" {
+			itemErrs = append(itemErrs, errs.NewFieldRequired("key"))
+		}
+		itemErrs = append(itemErrs, validateVolumeFilePath(item.Path)...)
+		if item.Mode != nil && (*item.Mode < 0 || *item.Mode > 0777) {
+			itemErrs = append(itemErrs, errs.NewFieldInvalid("mode", *item.Mode, "must be between 0 and 0777"))
+		}
+		allErrs = append(allErrs, itemErrs.PrefixIndex(i)...)
+	}
 	return allErrs
 }
 
@@ -574,22 +634,64 @@ var validDownwardAPIFieldPathExpressions = sets.NewString("metadata.name", "meta
 func validateDownwardAPIVolumeSource(downwardAPIVolume *api.DownwardAPIVolumeSource) errs.ValidationErrorList {
 	allErrs := errs.ValidationErrorList{}
 	for _, downwardAPIVolumeFile := range downwardAPIVolume.Items {
-		if len(downwardAPIVolumeFile.Path) == 0 {
-			allErrs = append(allErrs, errs.NewFieldRequired("path"))
+		allErrs = append(allErrs, validateVolumeFilePath(downwardAPIVolumeFile.Path)...)
+		allErrs = append(allErrs, validateObjectFieldSelector(&downwardAPIVolumeFile.FieldRef, &validDownwardAPIFieldPathExpressions).Prefix("FieldRef")...)
+	}
+	return allErrs
+}
+
+func validateProjectedVolumeSource(projected *api.ProjectedVolumeSource) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if len(projected.Sources) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("sources"))
+	}
+	for i, source := range projected.Sources {
+		sourceErrs := errs.ValidationErrorList{}
+		if len(source.Path) != 0 {
+			sourceErrs = append(sourceErrs, validateVolumeFilePath(source.Path)...)
 		}
-		if path.IsAbs(downwardAPIVolumeFile.Path) {
-			allErrs = append(allErrs, errs.NewFieldForbidden("path", "must not be an absolute path"))
+		numSources := 0
+		if source.Secret != nil {
+			numSources++
+			sourceErrs = append(sourceErrs, validateSecretVolumeSource(source.Secret).Prefix("secret")...)
 		}
-		items := strings.Split(downwardAPIVolumeFile.Path, string(os.PathSeparator))
-		for _, item := range items {
-			if item == ".." {
-				allErrs = append(allErrs, errs.NewFieldInvalid("path", downwardAPIVolumeFile.Path, "must not contain \"..\"."))
-			}
+		if source.DownwardAPI != nil {
+			numSources++
+			sourceErrs = append(sourceErrs, validateDownwardAPIVolumeSource(source.DownwardAPI).Prefix("downwardAPI")...)
 		}
-		if strings.HasPrefix(items[0], "..") && len(items[0]) > 2 {
-			allErrs = append(allErrs, errs.NewFieldInvalid("path", downwardAPIVolumeFile.Path, "must not start with \"..\"."))
+		if source.ConfigMap != nil {
+			numSources++
+			sourceErrs = append(sourceErrs, validateConfigMapVolumeSource(source.ConfigMap).Prefix("configMap")...)
 		}
-		allErrs = append(allErrs, validateObjectFieldSelector(&downwardAPIVolumeFile.FieldRef, &validDownwardAPIFieldPathExpressions).Prefix("FieldRef")...)
+		if numSources != 1 {
+			sourceErrs = append(sourceErrs, errs.NewFieldInvalid("", source, "exactly 1 source is required"))
+		}
+		allErrs = append(allErrs, sourceErrs.PrefixIndex(i).Prefix("sources")...)
+	}
+	if projected.DefaultMode != nil && (*projected.DefaultMode < 0 || *projected.DefaultMode > 0777) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("defaultMode", *projected.DefaultMode, "must be between 0 and 0777"))
+	}
+	return allErrs
+}
+
+// validateVolumeFilePath validates the path of a file projected into a volume,
+// which must be relative and stay within the volume.
+func validateVolumeFilePath(filePath string) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if len(filePath) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("path"))
+	}
+	if path.IsAbs(filePath) {
+		allErrs = append(allErrs, errs.NewFieldForbidden("path", "must not be an absolute path"))
+	}
+	items := strings.Split(filePath, string(os.PathSeparator))
+	for _, item := range items {
+		if item == ".." {
+			allErrs = append(allErrs, errs.NewFieldInvalid("path", filePath, "must not contain \"..\"."))
+		}
+	}
+	if strings.HasPrefix(items[0], "..") && len(items[0]) > 2 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("path", filePath, "must not start with \"..\"."))
 	}
 	return allErrs
 }
@@ -619,11 +721,47 @@ func validateCinderVolumeSource(cd *api.CinderVolumeSource) errs.ValidationError
 	return allErrs
 }
 
+func validateFlexVolumeSource(fv *api.FlexVolumeSource) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if fv.Driver == "" {
+		allErrs = append(allErrs, errs.NewFieldRequired("driver"))
+	} else if !validation.IsQualifiedName(fv.Driver) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("driver", fv.Driver, qualifiedNameErrorMsg))
+	}
+	return allErrs
+}
+
+func validateCSIPersistentVolumeSource(csi *api.CSIPersistentVolumeSource) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if csi.Driver == "" {
+		allErrs = append(allErrs, errs.NewFieldRequired("driver"))
+	} else if !validation.IsQualifiedName(csi.Driver) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("driver", csi.Driver, qualifiedNameErrorMsg))
+	}
+	if csi.VolumeHandle == "" {
+		allErrs = append(allErrs, errs.NewFieldRequired("volumeHandle"))
+	}
+	return allErrs
+}
+
+func validateLocalVolumeSource(local *api.LocalVolumeSource) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	if local.Path == "" {
+		allErrs = append(allErrs, errs.NewFieldRequired("path"))
+	} else if !path.IsAbs(local.Path) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("path", local.Path, "must be an absolute path"))
+	}
+	return allErrs
+}
+
 func validateCephFS(cephfs *api.CephFSVolumeSource) errs.ValidationErrorList {
 	allErrs := errs.ValidationErrorList{}
 	if len(cephfs.Monitors) == 0 {
 		allErrs = append(allErrs, errs.NewFieldRequired("monitors"))
 	}
+	if cephfs.Path != "" && !path.IsAbs(cephfs.Path) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("path", cephfs.Path, "must be an absolute path"))
+	}
 	return allErrs
 }
 
@@ -640,8 +778,8 @@ func ValidatePersistentVolume(pv *api.PersistentVolume) errs.ValidationErrorList
 	}
 
 	for _, mode := range pv.Spec.AccessModes {
-		if mode != api.ReadWriteOnce && mode != api.ReadOnlyMany && mode != api.ReadWriteMany {
-			allErrs = append(allErrs, errs.NewFieldInvalid("persistentVolume.Spec.AccessModes", mode, fmt.Sprintf("only %s, %s, and %s are valid", api.ReadWriteOnce, api.ReadOnlyMany, api.ReadWriteMany)))
+		if mode != api.ReadWriteOnce && mode != api.ReadOnlyMany && mode != api.ReadWriteMany && mode != api.ReadWriteOncePod {
+			allErrs = append(allErrs, errs.NewFieldInvalid("persistentVolume.Spec.AccessModes", mode, fmt.Sprintf("only %s, %s, %s, and %s are valid", api.ReadWriteOnce, api.ReadOnlyMany, api.ReadWriteMany, api.ReadWriteOncePod)))
 		}
 	}
 
@@ -702,6 +840,18 @@ func ValidatePersistentVolume(pv *api.PersistentVolume) errs.ValidationErrorList
 		numVolumes++
 		allErrs = append(allErrs, validateFCVolumeSource(pv.Spec.FC).Prefix("fc")...)
 	}
+	if pv.Spec.FlexVolume != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateFlexVolumeSource(pv.Spec.FlexVolume).Prefix("flexVolume")...)
+	}
+	if pv.Spec.CSI != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateCSIPersistentVolumeSource(pv.Spec.CSI).Prefix("csi")...)
+	}
+	if pv.Spec.Local != nil {
+		numVolumes++
+		allErrs = append(allErrs, validateLocalVolumeSource(pv.Spec.Local).Prefix("local")...)
+	}
 	if numVolumes != 1 {
 		allErrs = append(allErrs, errs.NewFieldInvalid("", pv.Spec.PersistentVolumeSource, "exactly 1 volume type is required"))
 	}
@@ -735,8 +885,8 @@ func ValidatePersistentVolumeClaim(pvc *api.PersistentVolumeClaim) errs.Validati
 		allErrs = append(allErrs, errs.NewFieldInvalid("persistentVolumeClaim.Spec.AccessModes", pvc.Spec.AccessModes, "at least 1 PersistentVolumeAccessMode is required"))
 	}
 	for _, mode := range pvc.Spec.AccessModes {
-		if mode != api.ReadWriteOnce && mode != api.ReadOnlyMany && mode != api.ReadWriteMany {
-			allErrs = append(allErrs, errs.NewFieldInvalid("persistentVolumeClaim.Spec.AccessModes", mode, fmt.Sprintf("only %s, %s, and %s are valid", api.ReadWriteOnce, api.ReadOnlyMany, api.ReadWriteMany)))
+		if mode != api.ReadWriteOnce && mode != api.ReadOnlyMany && mode != api.ReadWriteMany && mode != api.ReadWriteOncePod {
+			allErrs = append(allErrs, errs.NewFieldInvalid("persistentVolumeClaim.Spec.AccessModes", mode, fmt.Sprintf("only %s, %s, %s, and %s are valid", api.ReadWriteOnce, api.ReadOnlyMany, api.ReadWriteMany, api.ReadWriteOncePod)))
 		}
 	}
 	if _, ok := pvc.Spec.Resources.Requests[api.ResourceStorage]; !ok {
@@ -1748,6 +1898,34 @@ func ValidateSecretUpdate(oldSecret, newSecret *api.Secret) errs.ValidationError
 	return allErrs
 }
 
+// ValidateConfigMap tests if required fields in the ConfigMap are set.
+func ValidateConfigMap(configMap *api.ConfigMap) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	allErrs = append(allErrs, ValidateObjectMeta(&configMap.ObjectMeta, true, ValidateConfigMapName).Prefix("metadata")...)
+
+	totalSize := 0
+	for key, value := range configMap.Data {
+		if !IsSecretKey(key) {
+			allErrs = append(allErrs, errs.NewFieldInvalid(fmt.Sprintf("data[%s]", key), key, fmt.Sprintf("must have at most %d characters and match regex %s", validation.DNS1123SubdomainMaxLength, SecretKeyFmt)))
+		}
+
+		totalSize += len(value)
+	}
+
+	if totalSize > api.MaxConfigMapSize {
+		allErrs = append(allErrs, errs.NewFieldForbidden("data", "Maximum configMap size exceeded"))
+	}
+	return allErrs
+}
+
+// ValidateConfigMapUpdate tests if required fields in the ConfigMap are set.
+func ValidateConfigMapUpdate(oldConfigMap, newConfigMap *api.ConfigMap) errs.ValidationErrorList {
+	allErrs := errs.ValidationErrorList{}
+	allErrs = append(allErrs, ValidateObjectMetaUpdate(&newConfigMap.ObjectMeta, &oldConfigMap.ObjectMeta).Prefix("metadata")...)
+	allErrs = append(allErrs, ValidateConfigMap(newConfigMap)...)
+	return allErrs
+}
+
 func validateBasicResource(quantity resource.Quantity) errs.ValidationErrorList {
 	if quantity.Value() < 0 {
 		return errs.ValidationErrorList{errs.NewFieldInvalid("", quantity.Value(), "must be a valid resource quantity")}
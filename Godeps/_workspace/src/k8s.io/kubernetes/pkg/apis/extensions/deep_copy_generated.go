@@ -66,6 +66,7 @@ func deepCopy_api_CephFSVolumeSource(in api.CephFSVolumeSource, out *api.CephFSV
 	} else {
 		out.Monitors = nil
 	}
+	out.Path = in.Path
 	out.User = in.User
 	out.SecretFile = in.SecretFile
 	if in.SecretRef != nil {
@@ -87,6 +88,35 @@ func deepCopy_api_CinderVolumeSource(in api.CinderVolumeSource, out *api.CinderV
 	return nil
 }
 
+func deepCopy_api_ConfigMapVolumeSource(in api.ConfigMapVolumeSource, out *api.ConfigMapVolumeSource, c *conversion.Cloner) error {
+	if err := deepCopy_api_LocalObjectReference(in.LocalObjectReference, &out.LocalObjectReference, c); err != nil {
+		return err
+	}
+	if in.Items != nil {
+		out.Items = make([]api.KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_api_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	if in.Optional != nil {
+		out.Optional = new(bool)
+		*out.Optional = *in.Optional
+	} else {
+		out.Optional = nil
+	}
+	return nil
+}
+
 func deepCopy_api_Container(in api.Container, out *api.Container, c *conversion.Cloner) error {
 	out.Name = in.Name
 	out.Image = in.Image
@@ -274,6 +304,29 @@ func deepCopy_api_FCVolumeSource(in api.FCVolumeSource, out *api.FCVolumeSource,
 	return nil
 }
 
+func deepCopy_api_FlexVolumeSource(in api.FlexVolumeSource, out *api.FlexVolumeSource, c *conversion.Cloner) error {
+	out.Driver = in.Driver
+	out.FSType = in.FSType
+	if in.SecretRef != nil {
+		out.SecretRef = new(api.LocalObjectReference)
+		if err := deepCopy_api_LocalObjectReference(*in.SecretRef, out.SecretRef, c); err != nil {
+			return err
+		}
+	} else {
+		out.SecretRef = nil
+	}
+	out.ReadOnly = in.ReadOnly
+	if in.Options != nil {
+		out.Options = make(map[string]string)
+		for key, val := range in.Options {
+			out.Options[key] = val
+		}
+	} else {
+		out.Options = nil
+	}
+	return nil
+}
+
 func deepCopy_api_FlockerVolumeSource(in api.FlockerVolumeSource, out *api.FlockerVolumeSource, c *conversion.Cloner) error {
 	out.DatasetName = in.DatasetName
 	return nil
@@ -340,6 +393,7 @@ func deepCopy_api_Handler(in api.Handler, out *api.Handler, c *conversion.Cloner
 
 func deepCopy_api_HostPathVolumeSource(in api.HostPathVolumeSource, out *api.HostPathVolumeSource, c *conversion.Cloner) error {
 	out.Path = in.Path
+	out.Type = in.Type
 	return nil
 }
 
@@ -349,6 +403,26 @@ func deepCopy_api_ISCSIVolumeSource(in api.ISCSIVolumeSource, out *api.ISCSIVolu
 	out.Lun = in.Lun
 	out.FSType = in.FSType
 	out.ReadOnly = in.ReadOnly
+	if in.Portals != nil {
+		out.Portals = make([]string, len(in.Portals))
+		for i := range in.Portals {
+			out.Portals[i] = in.Portals[i]
+		}
+	} else {
+		out.Portals = nil
+	}
+	return nil
+}
+
+func deepCopy_api_KeyToPath(in api.KeyToPath, out *api.KeyToPath, c *conversion.Cloner) error {
+	out.Key = in.Key
+	out.Path = in.Path
+	if in.Mode != nil {
+		out.Mode = new(int32)
+		*out.Mode = *in.Mode
+	} else {
+		out.Mode = nil
+	}
 	return nil
 }
 
@@ -577,6 +651,26 @@ func deepCopy_api_PodTemplateSpec(in api.PodTemplateSpec, out *api.PodTemplateSp
 	return nil
 }
 
+func deepCopy_api_ProjectedVolumeSource(in api.ProjectedVolumeSource, out *api.ProjectedVolumeSource, c *conversion.Cloner) error {
+	if in.Sources != nil {
+		out.Sources = make([]api.VolumeProjection, len(in.Sources))
+		for i := range in.Sources {
+			if err := deepCopy_api_VolumeProjection(in.Sources[i], &out.Sources[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Sources = nil
+	}
+	if in.DefaultMode != nil {
+		out.DefaultMode = new(int32)
+		*out.DefaultMode = *in.DefaultMode
+	} else {
+		out.DefaultMode = nil
+	}
+	return nil
+}
+
 func deepCopy_api_Probe(in api.Probe, out *api.Probe, c *conversion.Cloner) error {
 	if err := deepCopy_api_Handler(in.Handler, &out.Handler, c); err != nil {
 		return err
@@ -650,6 +744,16 @@ func deepCopy_api_SELinuxOptions(in api.SELinuxOptions, out *api.SELinuxOptions,
 
 func deepCopy_api_SecretVolumeSource(in api.SecretVolumeSource, out *api.SecretVolumeSource, c *conversion.Cloner) error {
 	out.SecretName = in.SecretName
+	if in.Items != nil {
+		out.Items = make([]api.KeyToPath, len(in.Items))
+		for i := range in.Items {
+			if err := deepCopy_api_KeyToPath(in.Items[i], &out.Items[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -713,6 +817,35 @@ func deepCopy_api_VolumeMount(in api.VolumeMount, out *api.VolumeMount, c *conve
 	return nil
 }
 
+func deepCopy_api_VolumeProjection(in api.VolumeProjection, out *api.VolumeProjection, c *conversion.Cloner) error {
+	out.Path = in.Path
+	if in.Secret != nil {
+		out.Secret = new(api.SecretVolumeSource)
+		if err := deepCopy_api_SecretVolumeSource(*in.Secret, out.Secret, c); err != nil {
+			return err
+		}
+	} else {
+		out.Secret = nil
+	}
+	if in.DownwardAPI != nil {
+		out.DownwardAPI = new(api.DownwardAPIVolumeSource)
+		if err := deepCopy_api_DownwardAPIVolumeSource(*in.DownwardAPI, out.DownwardAPI, c); err != nil {
+			return err
+		}
+	} else {
+		out.DownwardAPI = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(api.ConfigMapVolumeSource)
+		if err := deepCopy_api_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	return nil
+}
+
 func deepCopy_api_VolumeSource(in api.VolumeSource, out *api.VolumeSource, c *conversion.Cloner) error {
 	if in.HostPath != nil {
 		out.HostPath = new(api.HostPathVolumeSource)
@@ -842,6 +975,30 @@ func deepCopy_api_VolumeSource(in api.VolumeSource, out *api.VolumeSource, c *co
 	} else {
 		out.FC = nil
 	}
+	if in.Projected != nil {
+		out.Projected = new(api.ProjectedVolumeSource)
+		if err := deepCopy_api_ProjectedVolumeSource(*in.Projected, out.Projected, c); err != nil {
+			return err
+		}
+	} else {
+		out.Projected = nil
+	}
+	if in.ConfigMap != nil {
+		out.ConfigMap = new(api.ConfigMapVolumeSource)
+		if err := deepCopy_api_ConfigMapVolumeSource(*in.ConfigMap, out.ConfigMap, c); err != nil {
+			return err
+		}
+	} else {
+		out.ConfigMap = nil
+	}
+	if in.FlexVolume != nil {
+		out.FlexVolume = new(api.FlexVolumeSource)
+		if err := deepCopy_api_FlexVolumeSource(*in.FlexVolume, out.FlexVolume, c); err != nil {
+			return err
+		}
+	} else {
+		out.FlexVolume = nil
+	}
 	return nil
 }
 
@@ -1588,6 +1745,7 @@ func init() {
 		deepCopy_api_Capabilities,
 		deepCopy_api_CephFSVolumeSource,
 		deepCopy_api_CinderVolumeSource,
+		deepCopy_api_ConfigMapVolumeSource,
 		deepCopy_api_Container,
 		deepCopy_api_ContainerPort,
 		deepCopy_api_DownwardAPIVolumeFile,
@@ -1597,6 +1755,7 @@ func init() {
 		deepCopy_api_EnvVarSource,
 		deepCopy_api_ExecAction,
 		deepCopy_api_FCVolumeSource,
+		deepCopy_api_FlexVolumeSource,
 		deepCopy_api_FlockerVolumeSource,
 		deepCopy_api_GCEPersistentDiskVolumeSource,
 		deepCopy_api_GitRepoVolumeSource,
@@ -1605,6 +1764,7 @@ func init() {
 		deepCopy_api_Handler,
 		deepCopy_api_HostPathVolumeSource,
 		deepCopy_api_ISCSIVolumeSource,
+		deepCopy_api_KeyToPath,
 		deepCopy_api_Lifecycle,
 		deepCopy_api_LoadBalancerIngress,
 		deepCopy_api_LoadBalancerStatus,
@@ -1617,6 +1777,7 @@ func init() {
 		deepCopy_api_PodSpec,
 		deepCopy_api_PodTemplateSpec,
 		deepCopy_api_Probe,
+		deepCopy_api_ProjectedVolumeSource,
 		deepCopy_api_RBDVolumeSource,
 		deepCopy_api_ResourceRequirements,
 		deepCopy_api_SELinuxOptions,
@@ -1625,6 +1786,7 @@ func init() {
 		deepCopy_api_TCPSocketAction,
 		deepCopy_api_Volume,
 		deepCopy_api_VolumeMount,
+		deepCopy_api_VolumeProjection,
 		deepCopy_api_VolumeSource,
 		deepCopy_resource_Quantity,
 		deepCopy_unversioned_ListMeta,
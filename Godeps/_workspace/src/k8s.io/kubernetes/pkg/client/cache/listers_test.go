@@ -354,3 +354,25 @@ func TestStoreToServiceLister(t *testing.T) {
 		t.Errorf("Expected service %q, got %q", e, a)
 	}
 }
+
+func TestStoreToPVAndPVCFetchers(t *testing.T) {
+	pvStore := NewStore(MetaNamespaceKeyFunc)
+	pvStore.Add(&api.PersistentVolume{ObjectMeta: api.ObjectMeta{Name: "pv1"}})
+	pvcStore := NewStore(MetaNamespaceKeyFunc)
+	pvcStore.Add(&api.PersistentVolumeClaim{ObjectMeta: api.ObjectMeta{Namespace: "ns1", Name: "claim1"}})
+	pvs := StoreToPVFetcher{pvStore}
+	pvcs := StoreToPVCFetcher{pvcStore}
+
+	if pv, err := pvs.GetPersistentVolumeInfo("pv1"); err != nil || pv.Name != "pv1" {
+		t.Errorf("Expected pv1, got %v, %v", pv, err)
+	}
+	if _, err := pvs.GetPersistentVolumeInfo("pv2"); err == nil {
+		t.Errorf("Expected an error for a PersistentVolume not in the store")
+	}
+	if claim, err := pvcs.GetPersistentVolumeClaimInfo("ns1", "claim1"); err != nil || claim.Name != "claim1" {
+		t.Errorf("Expected claim1, got %v, %v", claim, err)
+	}
+	if _, err := pvcs.GetPersistentVolumeClaimInfo("ns2", "claim1"); err == nil {
+		t.Errorf("Expected an error for a claim in another namespace")
+	}
+}
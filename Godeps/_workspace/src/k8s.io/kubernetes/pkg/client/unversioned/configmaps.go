@@ -0,0 +1,125 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unversioned
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+type ConfigMapsNamespacer interface {
+	ConfigMaps(namespace string) ConfigMapsInterface
+}
+
+type ConfigMapsInterface interface {
+	Create(configMap *api.ConfigMap) (*api.ConfigMap, error)
+	Update(configMap *api.ConfigMap) (*api.ConfigMap, error)
+	Delete(name string) error
+	List(label labels.Selector, field fields.Selector) (*api.ConfigMapList, error)
+	Get(name string) (*api.ConfigMap, error)
+	Watch(label labels.Selector, field fields.Selector, resourceVersion string) (watch.Interface, error)
+}
+
+// configMaps implements ConfigMaps interface
+type configMaps struct {
+	client    *Client
+	namespace string
+}
+
+// newConfigMaps returns a new configMaps object.
+func newConfigMaps(c *Client, ns string) *configMaps {
+	return &configMaps{
+		client:    c,
+		namespace: ns,
+	}
+}
+
+func (s *configMaps) Create(configMap *api.ConfigMap) (*api.ConfigMap, error) {
+	result := &api.ConfigMap{}
+	err := s.client.Post().
+		Namespace(s.namespace).
+		Resource("configMaps").
+		Body(configMap).
+		Do().
+		Into(result)
+
+	return result, err
+}
+
+// List returns a list of configMaps matching the selectors.
+func (s *configMaps) List(label labels.Selector, field fields.Selector) (*api.ConfigMapList, error) {
+	result := &api.ConfigMapList{}
+
+	err := s.client.Get().
+		Namespace(s.namespace).
+		Resource("configMaps").
+		LabelsSelectorParam(label).
+		FieldsSelectorParam(field).
+		Do().
+		Into(result)
+
+	return result, err
+}
+
+// Get returns the given configMap, or an error.
+func (s *configMaps) Get(name string) (*api.ConfigMap, error) {
+	result := &api.ConfigMap{}
+	err := s.client.Get().
+		Namespace(s.namespace).
+		Resource("configMaps").
+		Name(name).
+		Do().
+		Into(result)
+
+	return result, err
+}
+
+// Watch starts watching for configMaps matching the given selectors.
+func (s *configMaps) Watch(label labels.Selector, field fields.Selector, resourceVersion string) (watch.Interface, error) {
+	return s.client.Get().
+		Prefix("watch").
+		Namespace(s.namespace).
+		Resource("configMaps").
+		Param("resourceVersion", resourceVersion).
+		LabelsSelectorParam(label).
+		FieldsSelectorParam(field).
+		Watch()
+}
+
+func (s *configMaps) Delete(name string) error {
+	return s.client.Delete().
+		Namespace(s.namespace).
+		Resource("configMaps").
+		Name(name).
+		Do().
+		Error()
+}
+
+func (s *configMaps) Update(configMap *api.ConfigMap) (result *api.ConfigMap, err error) {
+	result = &api.ConfigMap{}
+	err = s.client.Put().
+		Namespace(s.namespace).
+		Resource("configMaps").
+		Name(configMap.Name).
+		Body(configMap).
+		Do().
+		Into(result)
+
+	return
+}
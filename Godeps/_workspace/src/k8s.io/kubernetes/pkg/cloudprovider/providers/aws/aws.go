@@ -42,6 +42,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/volume"
 
 	"github.com/golang/glog"
 )
@@ -141,6 +142,16 @@ type EC2Metadata interface {
 type VolumeOptions struct {
 	CapacityGB int
 	Tags       *map[string]string
+	// AvailabilityZone to create the volume in, the zone of this instance if empty.
+	AvailabilityZone string
+	// VolumeType is the EBS volume type, DefaultVolumeType if empty.
+	VolumeType string
+	// IOPSPerGB is the number of IOPS to provision per GB of an io1 volume.
+	IOPSPerGB int
+	// Encrypted is whether the volume is encrypted, with the KMS key KMSKeyID or the default EBS key if
+	// KMSKeyID is empty.
+	Encrypted bool
+	KMSKeyID  string
 }
 
 // Volumes is an interface for managing cloud-provisioned volumes
@@ -1018,6 +1029,9 @@ func (self *awsDisk) waitForAttachmentStatus(status string) error {
 func (self *awsDisk) delete() error {
 	_, err := self.ec2.DeleteVolume(self.awsID)
 	if err != nil {
+		if awsError, ok := err.(awserr.Error); ok && awsError.Code() == "VolumeInUse" {
+			return volume.NewDeletedVolumeInUseError(fmt.Sprintf("EBS volume %s is in use: %v", self.awsID, err))
+		}
 		return fmt.Errorf("error delete EBS volumes: %v", err)
 	}
 	return nil
@@ -1167,9 +1181,24 @@ func (s *AWSCloud) CreateVolume(volumeOptions *VolumeOptions) (string, error) {
 
 	request := &ec2.CreateVolumeInput{}
 	request.AvailabilityZone = &s.availabilityZone
+	if volumeOptions.AvailabilityZone != "" {
+		request.AvailabilityZone = aws.String(volumeOptions.AvailabilityZone)
+	}
 	volSize := int64(volumeOptions.CapacityGB)
 	request.Size = &volSize
 	request.VolumeType = aws.String(DefaultVolumeType)
+	if volumeOptions.VolumeType != "" {
+		request.VolumeType = aws.String(volumeOptions.VolumeType)
+	}
+	if volumeOptions.IOPSPerGB > 0 {
+		request.Iops = aws.Int64(int64(volumeOptions.CapacityGB * volumeOptions.IOPSPerGB))
+	}
+	if volumeOptions.Encrypted {
+		request.Encrypted = aws.Bool(true)
+		if volumeOptions.KMSKeyID != "" {
+			request.KmsKeyId = aws.String(volumeOptions.KMSKeyID)
+		}
+	}
 	response, err := s.ec2.CreateVolume(request)
 	if err != nil {
 		return "", err
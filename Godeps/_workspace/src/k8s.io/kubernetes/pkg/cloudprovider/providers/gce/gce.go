@@ -32,6 +32,7 @@ import (
 	"k8s.io/kubernetes/pkg/util/errors"
 	"k8s.io/kubernetes/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/volume"
 
 	"github.com/golang/glog"
 	"github.com/scalingdata/gcfg"
@@ -284,8 +285,13 @@ func (gce *GCECloud) waitForRegionOp(op *compute.Operation, region string) error
 }
 
 func (gce *GCECloud) waitForZoneOp(op *compute.Operation) error {
+	// Disk operations may run in a zone other than the one of this instance.
+	zone := gce.zone
+	if op.Zone != "" {
+		zone = path.Base(op.Zone)
+	}
 	return waitForOp(op, func() (*compute.Operation, error) {
-		return gce.service.ZoneOperations.Get(gce.projectID, gce.zone, op.Name).Do()
+		return gce.service.ZoneOperations.Get(gce.projectID, zone, op.Name).Do()
 	})
 }
 
@@ -1429,12 +1435,17 @@ func (gce *GCECloud) GetZone() (cloudprovider.Zone, error) {
 	}, nil
 }
 
-func (gce *GCECloud) CreateDisk(name string, sizeGb int64) error {
+// CreateDisk creates a disk of sizeGb and of diskType, e.g. "pd-ssd", in zone.  An empty diskType or zone
+// stands for the default disk type or the zone of this instance.
+func (gce *GCECloud) CreateDisk(name string, diskType string, zone string, sizeGb int64) error {
 	diskToCreate := &compute.Disk{
 		Name:   name,
 		SizeGb: sizeGb,
 	}
-	createOp, err := gce.service.Disks.Insert(gce.projectID, gce.zone, diskToCreate).Do()
+	if diskType != "" {
+		diskToCreate.Type = fmt.Sprintf("projects/%s/zones/%s/diskTypes/%s", gce.projectID, gce.diskZone(zone), diskType)
+	}
+	createOp, err := gce.service.Disks.Insert(gce.projectID, gce.diskZone(zone), diskToCreate).Do()
 	if err != nil {
 		return err
 	}
@@ -1442,13 +1453,85 @@ func (gce *GCECloud) CreateDisk(name string, sizeGb int64) error {
 	return gce.waitForZoneOp(createOp)
 }
 
-func (gce *GCECloud) DeleteDisk(diskToDelete string) error {
-	deleteOp, err := gce.service.Disks.Delete(gce.projectID, gce.zone, diskToDelete).Do()
+// DeleteDisk deletes a disk in zone, or in the zone of this instance if zone is empty.
+func (gce *GCECloud) DeleteDisk(diskToDelete string, zone string) error {
+	deleteOp, err := gce.service.Disks.Delete(gce.projectID, gce.diskZone(zone), diskToDelete).Do()
+	if err == nil {
+		err = gce.waitForZoneOp(deleteOp)
+	}
+	if isGCEError(err, "resourceInUseByAnotherResource") {
+		return volume.NewDeletedVolumeInUseError(err.Error())
+	}
+	return err
+}
+
+// ValidateDisk checks, without creating anything, that a disk of sizeGb and
+// of diskType could be created in zone: the disk type has to exist there and
+// the disk has to fit in the remaining disk quota of the zone's region.
+func (gce *GCECloud) ValidateDisk(diskType string, zone string, sizeGb int64) error {
+	zone = gce.diskZone(zone)
+	if diskType == "" {
+		diskType = "pd-standard"
+	}
+	if _, err := gce.service.DiskTypes.Get(gce.projectID, zone, diskType).Do(); err != nil {
+		if isHTTPErrorCode(err, http.StatusNotFound) {
+			return fmt.Errorf("disk type %q is not available in zone %s", diskType, zone)
+		}
+		return err
+	}
+	region, err := getGceRegion(zone)
 	if err != nil {
 		return err
 	}
+	r, err := gce.service.Regions.Get(gce.projectID, region).Do()
+	if err != nil {
+		return err
+	}
+	metric := "DISKS_TOTAL_GB"
+	if diskType == "pd-ssd" {
+		metric = "SSD_TOTAL_GB"
+	}
+	for _, quota := range r.Quotas {
+		if quota.Metric == metric && quota.Usage+float64(sizeGb) > quota.Limit {
+			return fmt.Errorf("a %dGB disk would exceed the %s quota of region %s (%.0f of %.0f used)", sizeGb, metric, region, quota.Usage, quota.Limit)
+		}
+	}
+	return nil
+}
+
+// ValidateDiskDeletion checks, without deleting anything, that the disk
+// named diskName exists in zone and is not attached to any instance.
+func (gce *GCECloud) ValidateDiskDeletion(diskName string, zone string) error {
+	disk, err := gce.service.Disks.Get(gce.projectID, gce.diskZone(zone), diskName).Do()
+	if err != nil {
+		return err
+	}
+	if len(disk.Users) > 0 {
+		return volume.NewDeletedVolumeInUseError(fmt.Sprintf("disk %s is in use by %s", diskName, strings.Join(disk.Users, ", ")))
+	}
+	return nil
+}
 
-	return gce.waitForZoneOp(deleteOp)
+// isGCEError returns true if err is a googleapi.Error with the given reason,
+// e.g. "resourceInUseByAnotherResource".
+func isGCEError(err error, reason string) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func (gce *GCECloud) diskZone(zone string) string {
+	if zone == "" {
+		return gce.zone
+	}
+	return zone
 }
 
 func (gce *GCECloud) AttachDisk(diskName string, readOnly bool) error {
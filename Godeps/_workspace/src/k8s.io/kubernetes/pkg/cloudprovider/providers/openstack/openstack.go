@@ -959,7 +959,10 @@ func (os *OpenStack) getVolume(diskName string) (volumes.Volume, error) {
 }
 
 // Create a volume of given size (in GiB)
-func (os *OpenStack) CreateVolume(size int) (volumeName string, err error) {
+// CreateVolume creates a volume of size GB in the availability zone, or in the default zone of the cloud if
+// availability is empty.  An empty volumeType stands for the default volume type of the cloud.  If
+// sourceVolumeID is not empty, the volume is created as a copy of that volume.
+func (os *OpenStack) CreateVolume(size int, availability string, volumeType string, sourceVolumeID string) (volumeName string, err error) {
 
 	sClient, err := openstack.NewBlockStorageV1(os.provider, gophercloud.EndpointOpts{
 		Region: os.region,
@@ -970,7 +973,7 @@ func (os *OpenStack) CreateVolume(size int) (volumeName string, err error) {
 		return "", err
 	}
 
-	opts := volumes.CreateOpts{Size: size}
+	opts := volumes.CreateOpts{Size: size, Availability: availability, VolumeType: volumeType, SourceVolID: sourceVolumeID}
 	vol, err := volumes.Create(sClient, opts).Extract()
 	if err != nil {
 		glog.Errorf("Failed to create a %d GB volume: %v", size, err)
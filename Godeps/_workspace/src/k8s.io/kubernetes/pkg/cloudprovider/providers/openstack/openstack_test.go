@@ -210,7 +210,7 @@ func TestVolumes(t *testing.T) {
 		t.Fatalf("Failed to construct/authenticate OpenStack: %s", err)
 	}
 
-	vol, err := os.CreateVolume(1)
+	vol, err := os.CreateVolume(1, "", "", "")
 	if err != nil {
 		t.Fatalf("Cannot create a new Cinder volume: %v", err)
 	}
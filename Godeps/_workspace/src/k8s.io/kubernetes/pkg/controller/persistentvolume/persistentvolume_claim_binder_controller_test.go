@@ -298,14 +298,12 @@ func TestBindingWithExamples(t *testing.T) {
 		claim:  claim,
 	}
 
-	plugMgr := volume.VolumePluginMgr{}
-	plugMgr.InitPlugins(host_path.ProbeRecyclableVolumePlugins(newMockRecycler, volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
-
 	recycler := &PersistentVolumeRecycler{
-		kubeClient: client,
-		client:     mockClient,
-		pluginMgr:  plugMgr,
+		kubeClient:   client,
+		client:       mockClient,
+		runOperation: volume.RunOperation,
 	}
+	recycler.pluginMgr.InitPlugins(host_path.ProbeRecyclableVolumePlugins(newMockRecycler, volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
 
 	// adds the volume to the index, making the volume available
 	syncVolume(volumeIndex, mockClient, pv)
@@ -395,6 +393,7 @@ func TestCasting(t *testing.T) {
 type mockBinderClient struct {
 	volume *api.PersistentVolume
 	claim  *api.PersistentVolumeClaim
+	pods   []api.Pod
 }
 
 func (c *mockBinderClient) GetPersistentVolume(name string) (*api.PersistentVolume, error) {
@@ -416,6 +415,16 @@ func (c *mockBinderClient) UpdatePersistentVolumeStatus(volume *api.PersistentVo
 	return c.volume, nil
 }
 
+func (c *mockBinderClient) ListPods(namespace string) (*api.PodList, error) {
+	list := &api.PodList{}
+	for _, pod := range c.pods {
+		if pod.Namespace == namespace {
+			list.Items = append(list.Items, pod)
+		}
+	}
+	return list, nil
+}
+
 func (c *mockBinderClient) GetPersistentVolumeClaim(namespace, name string) (*api.PersistentVolumeClaim, error) {
 	if c.claim != nil {
 		return c.claim, nil
@@ -22,6 +22,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/testapi"
+	"k8s.io/kubernetes/pkg/volume"
 )
 
 func TestMatchVolume(t *testing.T) {
@@ -229,7 +230,7 @@ func TestAllPossibleAccessModes(t *testing.T) {
 		t.Errorf("Expected 2 arrays of modes that match RWO, but got %v", len(possibleModes))
 	}
 	for _, m := range possibleModes {
-		if !contains(m, api.ReadWriteOnce) {
+		if !volume.AccessModesContains(m, api.ReadWriteOnce) {
 			t.Errorf("AccessModes does not contain %s", api.ReadWriteOnce)
 		}
 	}
@@ -238,7 +239,7 @@ func TestAllPossibleAccessModes(t *testing.T) {
 	if len(possibleModes) != 1 {
 		t.Errorf("Expected 1 array of modes that match RWX, but got %v", len(possibleModes))
 	}
-	if !contains(possibleModes[0], api.ReadWriteMany) {
+	if !volume.AccessModesContains(possibleModes[0], api.ReadWriteMany) {
 		t.Errorf("AccessModes does not contain %s", api.ReadWriteOnce)
 	}
 
@@ -24,6 +24,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	"k8s.io/kubernetes/pkg/controller/framework"
 	"k8s.io/kubernetes/pkg/conversion"
@@ -48,7 +49,10 @@ type PersistentVolumeProvisionerController struct {
 	client           controllerClient
 	cloud            cloudprovider.Interface
 	provisioner      volume.ProvisionableVolumePlugin
+	classes          map[string]map[string]string
 	pluginMgr        volume.VolumePluginMgr
+	provisions       *volume.ProvisionController
+	recorder         record.EventRecorder
 	stopChannels     map[string]chan struct{}
 	mutex            sync.RWMutex
 }
@@ -58,12 +62,23 @@ type PersistentVolumeProvisionerController struct {
 const volumesStopChannel = "volumes"
 const claimsStopChannel = "claims"
 
-// NewPersistentVolumeProvisionerController creates a new PersistentVolumeProvisionerController
-func NewPersistentVolumeProvisionerController(client controllerClient, syncPeriod time.Duration, plugins []volume.VolumePlugin, provisioner volume.ProvisionableVolumePlugin, cloud cloudprovider.Interface) (*PersistentVolumeProvisionerController, error) {
+// provisionLimits bounds the provisions of the controller, so that a burst of
+// claims does not trip the throttling of the storage backend.
+var provisionLimits = volume.ProvisionControllerConfig{
+	Workers:      4,
+	DefaultLimit: volume.ProvisionRateLimit{QPS: 5, Burst: 10},
+}
+
+// NewPersistentVolumeProvisionerController creates a new PersistentVolumeProvisionerController.  classes maps the
+// storage classes claims can ask for to the parameters volumes of the class are provisioned with.
+func NewPersistentVolumeProvisionerController(client controllerClient, syncPeriod time.Duration, plugins []volume.VolumePlugin, provisioner volume.ProvisionableVolumePlugin, cloud cloudprovider.Interface, classes map[string]map[string]string) (*PersistentVolumeProvisionerController, error) {
 	controller := &PersistentVolumeProvisionerController{
 		client:      client,
 		cloud:       cloud,
 		provisioner: provisioner,
+		classes:     classes,
+		provisions:  volume.NewProvisionController(provisionLimits),
+		recorder:    newEventRecorder(client.GetKubeClient(), "persistentvolume-provisioner"),
 	}
 
 	if err := controller.pluginMgr.InitPlugins(plugins, controller); err != nil {
@@ -174,8 +189,9 @@ func (controller *PersistentVolumeProvisionerController) reconcileClaim(claim *a
 	}
 
 	glog.V(5).Infof("PersistentVolumeClaim[%s] provisioning", claim.Name)
-	provisioner, err := newProvisioner(controller.provisioner, claim)
+	provisioner, err := controller.newProvisioner(claim)
 	if err != nil {
+		volume.RecordOperationFailure(controller, claim, volume.ProvisioningFailed, err)
 		return fmt.Errorf("Unexpected error getting new provisioner for claim %s: %v\n", claim.Name, err)
 	}
 	newVolume, err := provisioner.NewPersistentVolumeTemplate()
@@ -276,10 +292,18 @@ func provisionVolume(pv *api.PersistentVolume, controller *PersistentVolumeProvi
 	}
 	claim := obj.(*api.PersistentVolumeClaim)
 
-	provisioner, _ := newProvisioner(controller.provisioner, claim)
-	err := provisioner.Provision(pv)
+	provisioner, err := controller.newProvisioner(claim)
+	if err == nil {
+		err = controller.provisions.Provision(controller.provisioner.Name(), claimToClaimKey(claim), func() error {
+			return volume.MeasureOperation(controller.provisioner.Name(), volume.OperationProvision, func() error {
+				return volume.ProvisionVolume(provisioner, pv)
+			})
+		})
+		err = volume.NewError(volume.OperationProvision, controller.provisioner.Name(), pv.Name, "", err)
+	}
 	if err != nil {
 		glog.Errorf("Could not provision %s", pv.Name)
+		volume.RecordOperationFailure(controller, claim, volume.ProvisioningFailed, err)
 		pv.Status.Phase = api.VolumeFailed
 		pv.Status.Message = err.Error()
 		if pv, apiErr := controller.client.UpdatePersistentVolumeStatus(pv); apiErr != nil {
@@ -332,7 +356,47 @@ func (controller *PersistentVolumeProvisionerController) Stop() {
 	}
 }
 
-func newProvisioner(plugin volume.ProvisionableVolumePlugin, claim *api.PersistentVolumeClaim) (volume.Provisioner, error) {
+// newProvisioner returns a provisioner for the volume of claim, created where the node in the
+// volume.SelectedNodeAnnotation of claim, if any, can use it, as a clone of the volume of the claim in
+// the volume.CloneSourceAnnotation of claim, if any.
+func (controller *PersistentVolumeProvisionerController) newProvisioner(claim *api.PersistentVolumeClaim) (volume.Provisioner, error) {
+	var node *api.Node
+	if name := claim.Annotations[volume.SelectedNodeAnnotation]; name != "" {
+		var err error
+		node, err = controller.client.GetKubeClient().Nodes().Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the selected node %s: %v", name, err)
+		}
+	}
+	var cloneSource *api.PersistentVolume
+	if name := claim.Annotations[volume.CloneSourceAnnotation]; name != "" {
+		source, err := controller.client.GetPersistentVolumeClaim(claim.Namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the claim %s to clone: %v", name, err)
+		}
+		if source.Spec.VolumeName == "" {
+			return nil, fmt.Errorf("claim %s to clone is not bound to a volume", name)
+		}
+		cloneSource, err = controller.client.GetPersistentVolume(source.Spec.VolumeName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the volume %s to clone: %v", source.Spec.VolumeName, err)
+		}
+	}
+	return newProvisioner(controller.provisioner, claim, controller.classes, node, cloneSource)
+}
+
+func newProvisioner(plugin volume.ProvisionableVolumePlugin, claim *api.PersistentVolumeClaim, classes map[string]map[string]string, selectedNode *api.Node, cloneSource *api.PersistentVolume) (volume.Provisioner, error) {
+	class := claim.Annotations[qosProvisioningKey]
+	parameters, found := classes[class]
+	if !found && len(classes) > 0 {
+		return nil, fmt.Errorf("unknown storage class %q", class)
+	}
+	if cloneSource != nil {
+		if !plugin.SupportsCloning() || !plugin.CanSupport(volume.NewSpecFromPersistentVolume(cloneSource, false)) {
+			return nil, &volume.ErrCloneSourceNotSupported{Plugin: plugin.Name(), Source: cloneSource.Name}
+		}
+	}
+
 	volumeOptions := volume.VolumeOptions{
 		Capacity:                      claim.Spec.Resources.Requests[api.ResourceName(api.ResourceStorage)],
 		AccessModes:                   claim.Spec.AccessModes,
@@ -341,6 +405,11 @@ func newProvisioner(plugin volume.ProvisionableVolumePlugin, claim *api.Persiste
 			cloudVolumeCreatedForNamespaceTag: claim.Namespace,
 			cloudVolumeCreatedForNameTag:      claim.Name,
 		},
+		Zone:         claim.Annotations[volume.ZoneAnnotation],
+		Parameters:   parameters,
+		PVC:          claim,
+		SelectedNode: selectedNode,
+		CloneSource:  cloneSource,
 	}
 
 	provisioner, err := plugin.NewProvisioner(volumeOptions)
@@ -493,6 +562,10 @@ func (c *PersistentVolumeProvisionerController) GetHostName() string {
 	return ""
 }
 
+func (c *PersistentVolumeProvisionerController) GetEventRecorder() record.EventRecorder {
+	return c.recorder
+}
+
 const (
 	// these pair of constants are used by the provisioner.
 	// The key is a kube namespaced key that denotes a volume requires provisioning.
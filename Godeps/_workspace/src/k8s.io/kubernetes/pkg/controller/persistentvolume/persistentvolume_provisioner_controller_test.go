@@ -18,6 +18,7 @@ package persistentvolume
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,7 +26,9 @@ import (
 	"k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/testapi"
+	"k8s.io/kubernetes/pkg/client/record"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/fields"
 	fake_cloud "k8s.io/kubernetes/pkg/cloudprovider/providers/fake"
@@ -97,7 +100,7 @@ func makeTestClaim() *api.PersistentVolumeClaim {
 func makeTestController() (*PersistentVolumeProvisionerController, *mockControllerClient) {
 	mockClient := &mockControllerClient{}
 	mockVolumePlugin := &volume.FakeVolumePlugin{}
-	controller, _ := NewPersistentVolumeProvisionerController(mockClient, 1*time.Second, nil, mockVolumePlugin, &fake_cloud.FakeCloud{})
+	controller, _ := NewPersistentVolumeProvisionerController(mockClient, 1*time.Second, nil, mockVolumePlugin, &fake_cloud.FakeCloud{}, nil)
 	return controller, mockClient
 }
 
@@ -134,6 +137,114 @@ func TestReconcileClaim(t *testing.T) {
 	}
 }
 
+func TestReconcileClaimRecordsFailure(t *testing.T) {
+	controller, mockClient := makeTestController()
+	recorder := &record.FakeRecorder{}
+	controller.recorder = recorder
+	controller.classes = map[string]map[string]string{"gold": {}}
+	pvc := makeTestClaim()
+	pvc.Annotations[qosProvisioningKey] = "tin"
+
+	controller.claimStore.Add(pvc)
+	if err := controller.reconcileClaim(pvc); err == nil {
+		t.Errorf("Expected an error for an unknown class")
+	}
+	if mockClient.volume != nil {
+		t.Errorf("Unexpected volume created for an unknown class")
+	}
+	if len(recorder.Events) != 1 || !strings.HasPrefix(recorder.Events[0], volume.ProvisioningFailed+" ") {
+		t.Errorf("Expected a %s event, got %v", volume.ProvisioningFailed, recorder.Events)
+	}
+}
+
+func TestNewProvisionerZone(t *testing.T) {
+	plugin := &volume.FakeVolumePlugin{}
+	pvc := makeTestClaim()
+	pvc.Annotations[volume.ZoneAnnotation] = "us-east-1a"
+
+	provisioner, err := newProvisioner(plugin, pvc, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if zone := provisioner.(*volume.FakeProvisioner).Options.Zone; zone != "us-east-1a" {
+		t.Errorf("Expected the provisioner to be asked for zone us-east-1a, got %q", zone)
+	}
+}
+
+func TestNewProvisionerParameters(t *testing.T) {
+	plugin := &volume.FakeVolumePlugin{}
+	pvc := makeTestClaim()
+	classes := map[string]map[string]string{"fast": {"type": "pd-ssd"}}
+
+	pvc.Annotations[qosProvisioningKey] = "fast"
+	provisioner, err := newProvisioner(plugin, pvc, classes, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	options := provisioner.(*volume.FakeProvisioner).Options
+	if options.Parameters["type"] != "pd-ssd" {
+		t.Errorf("Expected the parameters of class fast, got %v", options.Parameters)
+	}
+	if options.PVC != pvc {
+		t.Errorf("Expected the provisioner to be given the claim")
+	}
+
+	pvc.Annotations[qosProvisioningKey] = "slow"
+	if _, err := newProvisioner(plugin, pvc, classes, nil, nil); err == nil {
+		t.Errorf("Expected an error for an unknown storage class")
+	}
+	// Without configured classes any class is provisioned without parameters.
+	if _, err := newProvisioner(plugin, pvc, nil, nil, nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNewProvisionerSelectedNode(t *testing.T) {
+	controller, mockClient := makeTestController()
+	node := &api.Node{ObjectMeta: api.ObjectMeta{Name: "node1", Labels: map[string]string{volume.ZoneLabel: "us-east-1b"}}}
+	mockClient.kubeClient = testclient.NewSimpleFake(node)
+	pvc := makeTestClaim()
+	pvc.Annotations[volume.SelectedNodeAnnotation] = "node1"
+
+	provisioner, err := controller.newProvisioner(pvc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	selected := provisioner.(*volume.FakeProvisioner).Options.SelectedNode
+	if selected == nil || selected.Labels[volume.ZoneLabel] != "us-east-1b" {
+		t.Errorf("Expected the provisioner to be given node1, got %+v", selected)
+	}
+}
+
+func TestNewProvisionerCloneSource(t *testing.T) {
+	controller, mockClient := makeTestController()
+	source := makeTestClaim()
+	source.Name = "source"
+	source.Spec.VolumeName = "pv01"
+	mockClient.claim = source
+	mockClient.volume = makeTestVolume()
+	pvc := makeTestClaim()
+	pvc.Annotations[volume.CloneSourceAnnotation] = "source"
+
+	if _, err := controller.newProvisioner(pvc); err == nil {
+		t.Errorf("Expected an error for a plugin which can't clone")
+	}
+
+	controller.provisioner.(*volume.FakeVolumePlugin).Cloning = true
+	provisioner, err := controller.newProvisioner(pvc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clone := provisioner.(*volume.FakeProvisioner).Options.CloneSource; clone == nil || clone.Name != "pv01" {
+		t.Errorf("Expected the provisioner to be given pv01 to clone, got %+v", clone)
+	}
+
+	source.Spec.VolumeName = ""
+	if _, err := controller.newProvisioner(pvc); err == nil {
+		t.Errorf("Expected an error for an unbound claim to clone")
+	}
+}
+
 func TestReconcileVolume(t *testing.T) {
 
 	controller, mockClient := makeTestController()
@@ -170,8 +281,9 @@ func TestReconcileVolume(t *testing.T) {
 var _ controllerClient = &mockControllerClient{}
 
 type mockControllerClient struct {
-	volume *api.PersistentVolume
-	claim  *api.PersistentVolumeClaim
+	volume     *api.PersistentVolume
+	claim      *api.PersistentVolumeClaim
+	kubeClient client.Interface
 }
 
 func (c *mockControllerClient) GetPersistentVolume(name string) (*api.PersistentVolume, error) {
@@ -237,5 +349,5 @@ func (c *mockControllerClient) UpdatePersistentVolumeClaimStatus(claim *api.Pers
 }
 
 func (c *mockControllerClient) GetKubeClient() client.Interface {
-	return nil
+	return c.kubeClient
 }
@@ -22,7 +22,9 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/record"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	"k8s.io/kubernetes/pkg/controller/framework"
@@ -48,15 +50,21 @@ type PersistentVolumeRecycler struct {
 	kubeClient       client.Interface
 	pluginMgr        volume.VolumePluginMgr
 	cloud            cloudprovider.Interface
+	recorder         record.EventRecorder
+	// runOperation runs the recycle and delete operations, and is replaced
+	// in tests.
+	runOperation func(op volume.Operation) error
 }
 
 // PersistentVolumeRecycler creates a new PersistentVolumeRecycler
 func NewPersistentVolumeRecycler(kubeClient client.Interface, syncPeriod time.Duration, plugins []volume.VolumePlugin, cloud cloudprovider.Interface) (*PersistentVolumeRecycler, error) {
 	recyclerClient := NewRecyclerClient(kubeClient)
 	recycler := &PersistentVolumeRecycler{
-		client:     recyclerClient,
-		kubeClient: kubeClient,
-		cloud:      cloud,
+		client:       recyclerClient,
+		kubeClient:   kubeClient,
+		cloud:        cloud,
+		recorder:     newEventRecorder(kubeClient, "persistentvolume-recycler"),
+		runOperation: volume.RunOperation,
 	}
 
 	if err := recycler.pluginMgr.InitPlugins(plugins, recycler); err != nil {
@@ -150,11 +158,28 @@ func (recycler *PersistentVolumeRecycler) handleRecycle(pv *api.PersistentVolume
 		if err != nil {
 			return fmt.Errorf("Could not obtain Recycler for spec: %#v  error: %v", spec, err)
 		}
+		progress := func(message string) {
+			glog.V(4).Infof("PersistentVolume[%s] recycling: %s", pv.Name, message)
+		}
 		// blocks until completion
-		if err := volRecycler.Recycle(); err != nil {
+		if err := volume.MeasureOperation(plugin.Name(), volume.OperationRecycle, func() error {
+			return recycler.runOperation(volume.Operation{
+				Type:       volume.OperationRecycle,
+				VolumePath: volRecycler.GetPath(),
+				VolumeName: pv.Name,
+				Priority:   volume.PriorityBackground,
+				Func: func() error {
+					return volume.RecycleWithTimeout(volRecycler, 0, progress)
+				},
+			})
+		}); err != nil {
+			err = volume.NewError(volume.OperationRecycle, plugin.Name(), pv.Name, "", err)
 			glog.Errorf("PersistentVolume[%s] failed recycling: %+v", pv.Name, err)
+			volume.RecordOperationFailure(recycler, pv, volume.RecyclerPodError, err)
 			pv.Status.Message = fmt.Sprintf("Recycling error: %s", err)
-			nextPhase = api.VolumeFailed
+			if !volume.IsTransient(err) {
+				nextPhase = api.VolumeFailed
+			}
 		} else {
 			glog.V(5).Infof("PersistentVolume[%s] successfully recycled\n", pv.Name)
 			nextPhase = api.VolumePending
@@ -195,12 +220,33 @@ func (recycler *PersistentVolumeRecycler) handleDelete(pv *api.PersistentVolume)
 		if err != nil {
 			return fmt.Errorf("Could not obtain Deleter for spec: %#v  error: %v", spec, err)
 		}
+		uniqueName, err := volume.GetUniqueVolumeNameFromSpec(plugin, spec)
+		if err != nil {
+			return err
+		}
+		deleter = volume.NewDeleterWithProtection(deleter, uniqueName, func() map[string]volume.ActualStateOfWorld {
+			return recycler.nodeVolumeStates(pv, uniqueName, spec)
+		})
 		// blocks until completion
-		err = deleter.Delete()
+		err = volume.MeasureOperation(plugin.Name(), volume.OperationDelete, func() error {
+			return recycler.runOperation(volume.Operation{
+				Type:       volume.OperationDelete,
+				VolumePath: deleter.GetPath(),
+				VolumeName: pv.Name,
+				Priority:   volume.PriorityBackground,
+				Func:       deleter.Delete,
+			})
+		})
 		if err != nil {
+			err = volume.NewError(volume.OperationDelete, plugin.Name(), pv.Name, "", err)
 			glog.Errorf("PersistentVolume[%s] failed deletion: %+v", pv.Name, err)
+			volume.RecordOperationFailure(recycler, pv, volume.VolumeDeleteFailed, err)
 			pv.Status.Message = fmt.Sprintf("Deletion error: %s", err)
-			nextPhase = api.VolumeFailed
+			// A volume that is still in use or a hiccup of the cloud
+			// provider stays Released and is deleted on a later sync.
+			if !volume.IsTransient(err) {
+				nextPhase = api.VolumeFailed
+			}
 		} else {
 			glog.V(5).Infof("PersistentVolume[%s] successfully deleted through plugin\n", pv.Name)
 			// after successful deletion through the plugin, we can also remove the PV from the cluster
@@ -241,12 +287,70 @@ func (recycler *PersistentVolumeRecycler) Stop() {
 	}
 }
 
+// nodeVolumeStates returns the state of the volume on every node, keyed by
+// node name, as seen from the pods scheduled there which use the claim the
+// volume was bound to.  Pods which use a newer claim of the same name don't
+// use the volume.  If the claim or the pods cannot be read the volume is
+// reported as in use, so that its deletion is retried on a later sync.
+func (recycler *PersistentVolumeRecycler) nodeVolumeStates(pv *api.PersistentVolume, uniqueName string, spec *volume.Spec) map[string]volume.ActualStateOfWorld {
+	states := map[string]volume.ActualStateOfWorld{}
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil {
+		return states
+	}
+	inUse := func(err error) map[string]volume.ActualStateOfWorld {
+		glog.Errorf("PersistentVolume[%s] cannot find the pods using it: %v", pv.Name, err)
+		state := volume.NewActualStateOfWorld()
+		state.MarkVolumeAsMounted("", uniqueName, spec)
+		states["<unknown>"] = state
+		return states
+	}
+
+	claim, err := recycler.client.GetPersistentVolumeClaim(claimRef.Namespace, claimRef.Name)
+	switch {
+	case errors.IsNotFound(err):
+		// Pods of the deleted claim may still run.
+	case err != nil:
+		return inUse(err)
+	case claimRef.UID != "" && claim.UID != claimRef.UID, claimRef.UID == "" && claim.Spec.VolumeName != pv.Name:
+		glog.V(5).Infof("PersistentVolume[%s] was bound to an earlier claim %s/%s", pv.Name, claimRef.Namespace, claimRef.Name)
+		return states
+	}
+
+	pods, err := recycler.client.ListPods(claimRef.Namespace)
+	if err != nil {
+		return inUse(err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == api.PodSucceeded || pod.Status.Phase == api.PodFailed {
+			continue
+		}
+		for _, podVolume := range pod.Spec.Volumes {
+			source := podVolume.PersistentVolumeClaim
+			if source == nil || source.ClaimName != claimRef.Name {
+				continue
+			}
+			state, ok := states[pod.Spec.NodeName]
+			if !ok {
+				state = volume.NewActualStateOfWorld()
+				states[pod.Spec.NodeName] = state
+			}
+			if err := state.MarkVolumeAsMounted(pod.UID, uniqueName, spec); err != nil {
+				glog.Errorf("PersistentVolume[%s] cannot record its use by pod %s: %v", pv.Name, pod.Name, err)
+			}
+		}
+	}
+	return states
+}
+
 // recyclerClient abstracts access to PVs
 type recyclerClient interface {
 	GetPersistentVolume(name string) (*api.PersistentVolume, error)
 	UpdatePersistentVolume(volume *api.PersistentVolume) (*api.PersistentVolume, error)
 	DeletePersistentVolume(volume *api.PersistentVolume) error
 	UpdatePersistentVolumeStatus(volume *api.PersistentVolume) (*api.PersistentVolume, error)
+	GetPersistentVolumeClaim(namespace, name string) (*api.PersistentVolumeClaim, error)
+	ListPods(namespace string) (*api.PodList, error)
 }
 
 func NewRecyclerClient(c client.Interface) recyclerClient {
@@ -273,6 +377,14 @@ func (c *realRecyclerClient) UpdatePersistentVolumeStatus(volume *api.Persistent
 	return c.client.PersistentVolumes().UpdateStatus(volume)
 }
 
+func (c *realRecyclerClient) GetPersistentVolumeClaim(namespace, name string) (*api.PersistentVolumeClaim, error) {
+	return c.client.PersistentVolumeClaims(namespace).Get(name)
+}
+
+func (c *realRecyclerClient) ListPods(namespace string) (*api.PodList, error) {
+	return c.client.Pods(namespace).List(labels.Everything(), fields.Everything())
+}
+
 // PersistentVolumeRecycler is host to the volume plugins, but does not actually mount any volumes.
 // Because no mounting is performed, most of the VolumeHost methods are not implemented.
 func (f *PersistentVolumeRecycler) GetPluginDir(podUID string) string {
@@ -310,3 +422,11 @@ func (f *PersistentVolumeRecycler) GetMounter() mount.Interface {
 func (f *PersistentVolumeRecycler) GetWriter() ioutil.Writer {
 	return nil
 }
+
+func (f *PersistentVolumeRecycler) GetHostName() string {
+	return ""
+}
+
+func (f *PersistentVolumeRecycler) GetEventRecorder() record.EventRecorder {
+	return f.recorder
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolume
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/host_path"
+)
+
+func TestDeleteVolumeInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "pv-delete")
+	if err != nil {
+		t.Fatalf("Unexpected error creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv-delete"},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				HostPath: &api.HostPathVolumeSource{Path: dir},
+			},
+			ClaimRef:                      &api.ObjectReference{Namespace: "ns", Name: "claim", UID: "claim-uid"},
+			PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+		},
+		Status: api.PersistentVolumeStatus{Phase: api.VolumeReleased},
+	}
+	mockClient := &mockBinderClient{
+		volume: pv,
+		pods: []api.Pod{
+			{
+				ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "writer", UID: "writer"},
+				Spec: api.PodSpec{
+					NodeName: "node",
+					Volumes: []api.Volume{
+						{
+							Name: "data",
+							VolumeSource: api.VolumeSource{
+								PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: "claim"},
+							},
+						},
+					},
+				},
+				Status: api.PodStatus{Phase: api.PodRunning},
+			},
+		},
+	}
+
+	clock := &util.FakeClock{Time: time.Now()}
+	recycler := &PersistentVolumeRecycler{
+		client:       mockClient,
+		runOperation: volume.NewOperationRegistry(clock).RunOperation,
+	}
+	recycler.pluginMgr.InitPlugins(host_path.ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+
+	if err := recycler.handleDelete(pv); err != nil {
+		t.Fatalf("Unexpected error deleting volume: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected the volume a pod still uses to be kept, got %v", err)
+	}
+	if mockClient.volume == nil || mockClient.volume.Status.Phase != api.VolumeReleased {
+		t.Errorf("Expected the volume to stay %s, got %+v", api.VolumeReleased, mockClient.volume)
+	}
+
+	// the claim was recreated, so the pod uses a new volume and this one is
+	// deleted on the next sync once the backoff of the failed deletion expired
+	mockClient.claim = &api.PersistentVolumeClaim{
+		ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "claim", UID: "new-claim-uid"},
+		Spec:       api.PersistentVolumeClaimSpec{VolumeName: "pv-new"},
+	}
+	clock.Step(time.Minute)
+	if err := recycler.handleDelete(pv); err != nil {
+		t.Fatalf("Unexpected error deleting volume: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the volume to be deleted, got %v", err)
+	}
+	if mockClient.volume != nil {
+		t.Errorf("Expected the PersistentVolume to be deleted, got %+v", mockClient.volume)
+	}
+}
@@ -20,15 +20,20 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/record"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/volume"
 )
 
 const (
 // A PVClaim can request a quality of service tier by adding this annotation.  The value of the annotation
 // is arbitrary.  The values are pre-defined by a cluster admin and known to users when requesting a QoS.
 // For example tiers might be gold, silver, and tin and the admin configures what that means for each volume plugin that can provision a volume.
-// Values in the alpha version of this feature are not meaningful, but will be in the full version of this feature.
+// The admin configures the parameters of each value with NewPersistentVolumeProvisionerController; unless any are
+// configured, values only request provisioning.
 	qosProvisioningKey = "volume.alpha.kubernetes.io/storage-class"
 	// Name of a tag attached to a real volume in cloud (e.g. AWS EBS or GCE PD)
 	// with namespace of a persistent volume claim used to create this volume.
@@ -38,6 +43,18 @@ const (
 	cloudVolumeCreatedForNameTag = "kubernetes.io/created-for/pvc/name"
 )
 
+// newEventRecorder returns a recorder which sends the events of component to the API server through
+// kubeClient, or nil if there is no kubeClient.
+func newEventRecorder(kubeClient client.Interface, component string) record.EventRecorder {
+	if kubeClient == nil {
+		return nil
+	}
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(glog.Infof)
+	eventBroadcaster.StartRecordingToSink(kubeClient.Events(""))
+	return eventBroadcaster.NewRecorder(api.EventSource{Component: component})
+}
+
 // persistentVolumeOrderedIndex is a cache.Store that keeps persistent volumes indexed by AccessModes and ordered by storage capacity.
 type persistentVolumeOrderedIndex struct {
 	cache.Indexer
@@ -213,7 +230,7 @@ func (pvIndex *persistentVolumeOrderedIndex) allPossibleMatchingAccessModes(requ
 	keys := pvIndex.Indexer.ListIndexFuncValues("accessmodes")
 	for _, key := range keys {
 		indexedModes := api.GetAccessModesFromString(key)
-		if containedInAll(indexedModes, requestedModes) {
+		if volume.AccessModesContainedInAll(indexedModes, requestedModes) {
 			matchedModes = append(matchedModes, indexedModes)
 		}
 	}
@@ -224,24 +241,6 @@ func (pvIndex *persistentVolumeOrderedIndex) allPossibleMatchingAccessModes(requ
 	return matchedModes
 }
 
-func contains(modes []api.PersistentVolumeAccessMode, mode api.PersistentVolumeAccessMode) bool {
-	for _, m := range modes {
-		if m == mode {
-			return true
-		}
-	}
-	return false
-}
-
-func containedInAll(indexedModes []api.PersistentVolumeAccessMode, requestedModes []api.PersistentVolumeAccessMode) bool {
-	for _, mode := range requestedModes {
-		if !contains(indexedModes, mode) {
-			return false
-		}
-	}
-	return true
-}
-
 // byAccessModes is used to order access modes by size, with the fewest modes first
 type byAccessModes struct {
 	modes [][]api.PersistentVolumeAccessMode
@@ -29,7 +29,6 @@ import (
 	"net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -69,13 +68,11 @@ import (
 	"k8s.io/kubernetes/pkg/util/bandwidth"
 	"k8s.io/kubernetes/pkg/util/chmod"
 	"k8s.io/kubernetes/pkg/util/chown"
-	utilErrors "k8s.io/kubernetes/pkg/util/errors"
 	kubeio "k8s.io/kubernetes/pkg/util/io"
 	"k8s.io/kubernetes/pkg/util/mount"
 	nodeutil "k8s.io/kubernetes/pkg/util/node"
 	"k8s.io/kubernetes/pkg/util/oom"
 	"k8s.io/kubernetes/pkg/util/procfs"
-	"k8s.io/kubernetes/pkg/util/selinux"
 	"k8s.io/kubernetes/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/version"
 	"k8s.io/kubernetes/pkg/volume"
@@ -110,6 +107,10 @@ const (
 	housekeepingMinimumPeriod = time.Second * 2
 
 	etcHostsPath = "/etc/hosts"
+
+	// Number of volume set ups and tear downs run at the same time.  Set ups
+	// are run first, so that pods don't wait on the cleanup of others.
+	volumeOperationWorkers = 10
 )
 
 var (
@@ -165,6 +166,7 @@ func NewMainKubelet(
 	clusterDNS net.IP,
 	masterServiceNamespace string,
 	volumePlugins []volume.VolumePlugin,
+	dynamicPluginProber volume.DynamicPluginProber,
 	networkPlugins []network.NetworkPlugin,
 	networkPluginName string,
 	streamingConnectionIdleTimeout time.Duration,
@@ -289,6 +291,7 @@ func NewMainKubelet(
 		diskSpaceManager:               diskSpaceManager,
 		statusManager:                  statusManager,
 		volumeManager:                  volumeManager,
+		volumeOperations:               volume.NewSharedOperationQueue(volumeOperationWorkers),
 		cloud:                          cloud,
 		nodeRef:                        nodeRef,
 		nodeStatusUpdateFrequency:      nodeStatusUpdateFrequency,
@@ -435,6 +438,7 @@ func NewMainKubelet(
 	klet.podWorkers = newPodWorkers(runtimeCache, klet.syncPod, recorder)
 
 	metrics.Register(runtimeCache)
+	volume.RegisterOperationMetrics()
 
 	if err = klet.setupDataDirs(); err != nil {
 		return nil, err
@@ -442,6 +446,11 @@ func NewMainKubelet(
 	if err = klet.volumePluginMgr.InitPlugins(volumePlugins, &volumeHost{klet}); err != nil {
 		return nil, err
 	}
+	if dynamicPluginProber != nil {
+		if err = klet.volumePluginMgr.InitDynamicPlugins(dynamicPluginProber, &volumeHost{klet}); err != nil {
+			return nil, err
+		}
+	}
 
 	// If the container logs directory does not exist, create it.
 	if _, err := os.Stat(containerLogsDir); err != nil {
@@ -564,6 +573,9 @@ type Kubelet struct {
 	// Manager for the volume maps for the pods.
 	volumeManager *volumeManager
 
+	// Runs the set ups and tear downs of volumes.
+	volumeOperations *volume.OperationQueue
+
 	//Cloud provider interface
 	cloud cloudprovider.Interface
 
@@ -991,7 +1003,7 @@ func (kl *Kubelet) relabelVolumes(pod *api.Pod, volumes kubecontainer.VolumeMap)
 		return err
 	}
 
-	chconRunner := selinux.NewChconRunner()
+	labeler := volume.NewSELinuxLabeler()
 	// Apply the pod's Level to the rootDirContext
 	rootDirSELinuxOptions, err := securitycontext.ParseSELinuxOptions(rootDirContext)
 	if err != nil {
@@ -1001,19 +1013,13 @@ func (kl *Kubelet) relabelVolumes(pod *api.Pod, volumes kubecontainer.VolumeMap)
 	rootDirSELinuxOptions.Level = pod.Spec.SecurityContext.SELinuxOptions.Level
 	volumeContext := fmt.Sprintf("%s:%s:%s:%s", rootDirSELinuxOptions.User, rootDirSELinuxOptions.Role, rootDirSELinuxOptions.Type, rootDirSELinuxOptions.Level)
 
-	for _, volume := range volumes {
-		if volume.Builder.SupportsSELinux() && !volume.Builder.IsReadOnly() {
+	for _, vol := range volumes {
+		if attrs := vol.Builder.GetAttributes(); attrs.SupportsSELinux && !attrs.ReadOnly {
 			// Relabel the volume and its content to match the 'Level' of the pod
-			err := filepath.Walk(volume.Builder.GetPath(), func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-				return chconRunner.SetContext(path, volumeContext)
-			})
-			if err != nil {
+			if err := labeler.Relabel(vol.Builder, volumeContext, true); err != nil {
 				return err
 			}
-			volume.SELinuxLabeled = true
+			vol.SELinuxLabeled = true
 		}
 	}
 	return nil
@@ -1040,7 +1046,8 @@ func makeMounts(pod *api.Pod, podDir string, container *api.Container, podVolume
 		// If the volume supports SELinux and it has not been
 		// relabeled already and it is not a read-only volume,
 		// relabel it and mark it as labeled
-		if vol.Builder.SupportsSELinux() && !vol.SELinuxLabeled && !vol.Builder.IsReadOnly() {
+		attrs := vol.Builder.GetAttributes()
+		if attrs.SupportsSELinux && !vol.SELinuxLabeled && !attrs.ReadOnly {
 			vol.SELinuxLabeled = true
 			relabelVolume = true
 		}
@@ -1592,37 +1599,18 @@ func getDesiredVolumes(pods []*api.Pod) map[string]api.Volume {
 	return desiredVolumes
 }
 
-// cleanupOrphanedPodDirs removes a pod directory if the pod is not in the
-// desired set of pods and there is no running containers in the pod.
+// cleanupOrphanedPodDirs tears down the volumes left in the directory of a pod
+// which is not in the desired set of pods and has no running containers, and
+// then removes the directory, see volume.CleanupOrphanedPodDirs.
 func (kl *Kubelet) cleanupOrphanedPodDirs(pods []*api.Pod, runningPods []*kubecontainer.Pod) error {
-	active := sets.NewString()
+	active := []types.UID{}
 	for _, pod := range pods {
-		active.Insert(string(pod.UID))
+		active = append(active, pod.UID)
 	}
 	for _, pod := range runningPods {
-		active.Insert(string(pod.ID))
-	}
-
-	found, err := kl.listPodsFromDisk()
-	if err != nil {
-		return err
+		active = append(active, pod.ID)
 	}
-	errlist := []error{}
-	for _, uid := range found {
-		if active.Has(string(uid)) {
-			continue
-		}
-		if volumes, err := kl.getPodVolumes(uid); err != nil || len(volumes) != 0 {
-			glog.V(3).Infof("Orphaned pod %q found, but volumes are not cleaned up; err: %v, volumes: %v ", uid, err, volumes)
-			continue
-		}
-
-		glog.V(3).Infof("Orphaned pod %q found, removing", uid)
-		if err := os.RemoveAll(kl.getPodDir(uid)); err != nil {
-			errlist = append(errlist, err)
-		}
-	}
-	return utilErrors.NewAggregate(errlist)
+	return volume.CleanupOrphanedPodDirs(active, kl.getPodsDir(), &kl.volumePluginMgr)
 }
 
 func (kl *Kubelet) cleanupBandwidthLimits(allPods []*api.Pod) error {
@@ -1691,8 +1679,16 @@ func (kl *Kubelet) cleanupOrphanedVolumes(pods []*api.Pod, runningPods []*kubeco
 			// TODO(yifan): Refactor this hacky string manipulation.
 			kl.volumeManager.DeleteVolumes(types.UID(parts[0]))
 			//TODO (jonesdl) This should not block other kubelet synchronization procedures
-			err := vol.TearDown()
+			err := kl.runVolumeOperation(volume.Operation{
+				Type:       volume.OperationTearDown,
+				VolumePath: vol.GetPath(),
+				PodUID:     types.UID(parts[0]),
+				VolumeName: vol.GetPath(),
+				Priority:   volume.DefaultPriority(volume.OperationTearDown),
+				Func:       vol.TearDown,
+			})
 			if err != nil {
+				err = volume.NewError(volume.OperationTearDown, "", parts[1], types.UID(parts[0]), err)
 				glog.Errorf("Could not tear down volume %q: %v", name, err)
 			}
 		}
@@ -1879,11 +1875,10 @@ func (kl *Kubelet) HandlePodCleanups() error {
 	// Remove any orphaned pod directories.
 	// Note that we pass all pods (including terminated pods) to the function,
 	// so that we don't remove directories associated with terminated but not yet
-	// deleted pods.
-	err = kl.cleanupOrphanedPodDirs(allPods, runningPods)
-	if err != nil {
+	// deleted pods. Directories which can't be cleaned up yet are retried by
+	// the next cleanup, so they must not hold up the cleanups below.
+	if err := kl.cleanupOrphanedPodDirs(allPods, runningPods); err != nil {
 		glog.Errorf("Failed cleaning up orphaned pod directories: %v", err)
-		return err
 	}
 
 	// Remove any orphaned mirror pods.
@@ -139,6 +139,7 @@ func newTestKubelet(t *testing.T) *TestKubelet {
 	kubelet.livenessManager = proberesults.NewManager()
 
 	kubelet.volumeManager = newVolumeManager()
+	kubelet.volumeOperations = volume.NewSharedOperationQueue(volumeOperationWorkers)
 	kubelet.containerManager, _ = newContainerManager(fakeContainerMgrMountInt(), mockCadvisor, "", "", "")
 	kubelet.networkConfigured = true
 	fakeClock := &util.FakeClock{Time: time.Now()}
@@ -511,10 +512,6 @@ func (f *stubVolume) GetPath() string {
 	return f.path
 }
 
-func (f *stubVolume) IsReadOnly() bool {
-	return false
-}
-
 func (f *stubVolume) SetUp() error {
 	return nil
 }
@@ -523,12 +520,8 @@ func (f *stubVolume) SetUpAt(dir string) error {
 	return nil
 }
 
-func (f *stubVolume) SupportsSELinux() bool {
-	return false
-}
-
-func (f *stubVolume) SupportsOwnershipManagement() bool {
-	return false
+func (f *stubVolume) GetAttributes() volume.Attributes {
+	return volume.Attributes{}
 }
 
 func TestMakeVolumeMounts(t *testing.T) {
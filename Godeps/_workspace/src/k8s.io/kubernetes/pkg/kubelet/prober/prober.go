@@ -18,6 +18,7 @@ package prober
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"strconv"
@@ -254,3 +255,7 @@ func (eic execInContainer) CombinedOutput() ([]byte, error) {
 func (eic execInContainer) SetDir(dir string) {
 	//unimplemented
 }
+
+func (eic execInContainer) SetStdin(in io.Reader) {
+	//unimplemented
+}
@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/record"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
@@ -57,6 +59,10 @@ func (vh *volumeHost) GetKubeClient() client.Interface {
 	return vh.kubelet.kubeClient
 }
 
+func (vh *volumeHost) GetEventRecorder() record.EventRecorder {
+	return vh.kubelet.recorder
+}
+
 func (vh *volumeHost) NewWrapperBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	b, err := vh.kubelet.newVolumeBuilderFromPlugins(spec, pod, opts)
 	if err == nil && b == nil {
@@ -93,6 +99,10 @@ func (vh *volumeHost) GetWriter() io.Writer {
 	return vh.kubelet.writer
 }
 
+func (vh *volumeHost) GetHostName() string {
+	return vh.kubelet.hostname
+}
+
 func (kl *Kubelet) newVolumeBuilderFromPlugins(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	plugin, err := kl.volumePluginMgr.FindPluginBySpec(spec)
 	if err != nil {
@@ -110,6 +120,15 @@ func (kl *Kubelet) newVolumeBuilderFromPlugins(spec *volume.Spec, pod *api.Pod,
 	return builder, nil
 }
 
+// volumePluginName returns the name of the plugin of spec for metrics.
+func (kl *Kubelet) volumePluginName(spec *volume.Spec) string {
+	plugin, err := kl.volumePluginMgr.FindPluginBySpec(spec)
+	if err != nil || plugin == nil {
+		return ""
+	}
+	return plugin.Name()
+}
+
 func (kl *Kubelet) mountExternalVolumes(pod *api.Pod) (kubecontainer.VolumeMap, error) {
 	podVolumes := make(kubecontainer.VolumeMap)
 	for i := range pod.Spec.Volumes {
@@ -136,12 +155,35 @@ func (kl *Kubelet) mountExternalVolumes(pod *api.Pod) (kubecontainer.VolumeMap,
 		if builder == nil {
 			return nil, errUnsupportedVolumeType
 		}
-		err = builder.SetUp()
+		pluginName := kl.volumePluginName(internal)
+		err = volume.MeasureOperation(pluginName, volume.OperationSetUp, func() error {
+			return kl.runVolumeOperation(volume.Operation{
+				Type:       volume.OperationSetUp,
+				VolumePath: builder.GetPath(),
+				PodUID:     pod.UID,
+				VolumeName: builder.GetPath(),
+				Priority:   volume.PriorityCritical,
+				Func:       builder.SetUp,
+			})
+		})
 		if err != nil {
+			return nil, volume.NewError(volume.OperationSetUp, pluginName, volSpec.Name, pod.UID, err)
+		}
+		if err := volume.VerifyWithTimeout(builder, volume.DefaultVerifyTimeout); err != nil {
+			glog.Errorf("Verification of volume %s for pod %s failed: %v", volSpec.Name, pod.UID, err)
 			return nil, err
 		}
-		if hasFSGroup && builder.SupportsOwnershipManagement() && !builder.IsReadOnly() {
-			err := kl.manageVolumeOwnership(pod, internal, builder, fsGroup)
+		// Host paths are not the kubelet's to remount.
+		if strings.HasPrefix(builder.GetPath(), kl.getPodsDir()) {
+			if err := volume.EnforceReadOnly(builder); err != nil {
+				return nil, err
+			}
+			if err := volume.SetUpMountPropagation(builder); err != nil {
+				return nil, err
+			}
+		}
+		if attrs := builder.GetAttributes(); hasFSGroup && attrs.Managed && !attrs.ReadOnly {
+			err := volume.SetVolumeOwnership(builder, &fsGroup)
 			if err != nil {
 				return nil, err
 			}
@@ -151,6 +193,12 @@ func (kl *Kubelet) mountExternalVolumes(pod *api.Pod) (kubecontainer.VolumeMap,
 	return podVolumes, nil
 }
 
+// runVolumeOperation runs op on the volume operation queue and waits for its
+// result.
+func (kl *Kubelet) runVolumeOperation(op volume.Operation) error {
+	return <-kl.volumeOperations.Enqueue(op)
+}
+
 type volumeTuple struct {
 	Kind string
 	Name string
@@ -239,5 +287,5 @@ func (kl *Kubelet) newVolumeCleanerFromPlugins(kind string, name string, podUID
 		return nil, fmt.Errorf("failed to instantiate volume plugin for %s/%s: %v", podUID, kind, err)
 	}
 	glog.V(3).Infof("Used volume plugin %q for %s/%s", plugin.Name(), podUID, kind)
-	return cleaner, nil
+	return volume.InstrumentCleaner(plugin.Name(), cleaner), nil
 }
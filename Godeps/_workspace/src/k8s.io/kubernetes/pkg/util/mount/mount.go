@@ -19,6 +19,7 @@ limitations under the License.
 package mount
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/golang/glog"
@@ -121,32 +122,45 @@ func New() Interface {
 // GetMountRefs finds all other references to the device referenced
 // by mountPath; returns a list of paths.
 func GetMountRefs(mounter Interface, mountPath string) ([]string, error) {
+	exists, refs, err := PathExistsWithRefs(mounter, mountPath)
+	if err == nil && !exists {
+		glog.Warningf("could not determine device for path: %q", mountPath)
+	}
+	return refs, err
+}
+
+// PathExistsWithRefs returns whether mountPath is in the mount table, and
+// the other paths the device mounted at mountPath is mounted at, such as
+// the global mount of a disk and the bind mounts of other pods.  Unlike
+// GetMountRefs it tells a path which is not mounted from a device with no
+// other mounts, and only the latter may be detached.
+func PathExistsWithRefs(mounter Interface, mountPath string) (bool, []string, error) {
 	mps, err := mounter.List()
 	if err != nil {
-		return nil, err
+		return false, nil, err
 	}
 
 	// Find the device name.
+	mountPath = filepath.Clean(mountPath)
 	deviceName := ""
 	for i := range mps {
-		if mps[i].Path == mountPath {
+		if filepath.Clean(mps[i].Path) == mountPath {
 			deviceName = mps[i].Device
 			break
 		}
 	}
+	if deviceName == "" {
+		return false, nil, nil
+	}
 
 	// Find all references to the device.
 	var refs []string
-	if deviceName == "" {
-		glog.Warningf("could not determine device for path: %q", mountPath)
-	} else {
-		for i := range mps {
-			if mps[i].Device == deviceName && mps[i].Path != mountPath {
-				refs = append(refs, mps[i].Path)
-			}
+	for i := range mps {
+		if mps[i].Device == deviceName && filepath.Clean(mps[i].Path) != mountPath {
+			refs = append(refs, mps[i].Path)
 		}
 	}
-	return refs, nil
+	return true, refs, nil
 }
 
 // GetDeviceNameFromMount: given a mnt point, find the device from /proc/mounts
@@ -129,6 +129,29 @@ func TestGetMountRefs(t *testing.T) {
 	}
 }
 
+func TestPathExistsWithRefs(t *testing.T) {
+	fm := &FakeMounter{
+		MountPoints: []MountPoint{
+			{Device: "/dev/sdb", Path: "/var/lib/kubelet/plugins/kubernetes.io/gce-pd/mounts/gce-pd"},
+			{Device: "/dev/sdb", Path: "/var/lib/kubelet/pods/some-pod/volumes/kubernetes.io~gce-pd/gce-pd-in-pod"},
+			{Device: "/dev/sdc", Path: "/var/lib/kubelet/pods/some-pod/volumes/kubernetes.io~gce-pd/gce-pd2-in-pod"},
+		},
+	}
+
+	exists, refs, err := PathExistsWithRefs(fm, "/var/lib/kubelet/pods/some-pod/volumes/kubernetes.io~gce-pd/gce-pd-in-pod/")
+	if err != nil || !exists || !setEquivalent(refs, []string{"/var/lib/kubelet/plugins/kubernetes.io/gce-pd/mounts/gce-pd"}) {
+		t.Errorf("Unexpected result for a mount with a global mount: %v, %v, %v", exists, refs, err)
+	}
+	exists, refs, err = PathExistsWithRefs(fm, "/var/lib/kubelet/pods/some-pod/volumes/kubernetes.io~gce-pd/gce-pd2-in-pod")
+	if err != nil || !exists || len(refs) != 0 {
+		t.Errorf("Unexpected result for a mount without other references: %v, %v, %v", exists, refs, err)
+	}
+	exists, refs, err = PathExistsWithRefs(fm, "/var/lib/kubelet/pods/other-pod/volumes/kubernetes.io~gce-pd/gce-pd")
+	if err != nil || exists || len(refs) != 0 {
+		t.Errorf("Unexpected result for a path which is not mounted: %v, %v, %v", exists, refs, err)
+	}
+}
+
 func setEquivalent(set1, set2 []string) bool {
 	map1 := make(map[string]bool)
 	map2 := make(map[string]bool)
@@ -0,0 +1,121 @@
+// +build windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mount
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// Mounter mounts volumes on Windows, which has no bind mounts: the target
+// is made a directory symlink to the source instead, after mapping SMB
+// shares to the node.
+type Mounter struct{}
+
+// Mount links target to source.  Sources of the "smb" and "cifs" types are
+// shares given as //server/share, which are first mapped to the node with
+// the credentials in the username= and password= options.
+func (mounter *Mounter) Mount(source string, target string, fstype string, options []string) error {
+	glog.V(5).Infof("Mounting %s %s %s", source, target, fstype)
+	if fstype == "smb" || fstype == "cifs" {
+		source = strings.Replace(source, "/", "\\", -1)
+		if err := newSMBGlobalMapping(source, options); err != nil {
+			return err
+		}
+	}
+	// mklink refuses to replace the directory the caller made for the mount.
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Mount failed: can't replace %s: %v", target, err)
+	}
+	output, err := exec.Command("cmd", "/c", "mklink", "/D", target, source).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Mount failed: %v\nMounting arguments: %s %s %s\nOutput: %s\n", err, source, target, fstype, string(output))
+	}
+	return nil
+}
+
+// newSMBGlobalMapping maps the share to the node for all users.  The
+// credentials are passed in the environment so that they don't show up in
+// the command line of the process.
+func newSMBGlobalMapping(share string, options []string) error {
+	env := []string{"smbremotepath=" + share}
+	for _, option := range options {
+		if strings.HasPrefix(option, "username=") {
+			env = append(env, "smbuser="+strings.TrimPrefix(option, "username="))
+		} else if strings.HasPrefix(option, "password=") {
+			env = append(env, "smbpassword="+strings.TrimPrefix(option, "password="))
+		}
+	}
+	cmd := exec.Command("powershell", "/c",
+		`if (Get-SmbGlobalMapping -RemotePath $Env:smbremotepath -ErrorAction SilentlyContinue) { exit 0 }; `+
+			`$password = ConvertTo-SecureString -String $Env:smbpassword -AsPlainText -Force; `+
+			`$credential = New-Object System.Management.Automation.PSCredential -ArgumentList $Env:smbuser, $password; `+
+			`New-SmbGlobalMapping -RemotePath $Env:smbremotepath -Credential $credential`)
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to map SMB share %s: %v\nOutput: %s\n", share, err, string(output))
+	}
+	return nil
+}
+
+// Unmount removes the link at target.  The SMB mapping of a share is left
+// in place, as other volumes of the node may use it.
+func (mounter *Mounter) Unmount(target string) error {
+	glog.V(5).Infof("Unmounting %s", target)
+	if err := os.Remove(target); err != nil {
+		return fmt.Errorf("Unmount failed: %v\nUnmounting arguments: %s\n", err, target)
+	}
+	return nil
+}
+
+// List returns no mount points, as Windows has no mount table to list the
+// links made by Mount from.
+func (mounter *Mounter) List() ([]MountPoint, error) {
+	return []MountPoint{}, nil
+}
+
+// IsLikelyNotMountPoint determines whether file is a symlink or junction
+// whose target exists, which is how Mount sets up volumes.  Dangling links
+// are not mount points.
+func (mounter *Mounter) IsLikelyNotMountPoint(file string) (bool, error) {
+	stat, err := os.Lstat(file)
+	if err != nil {
+		return true, err
+	}
+	// Junctions are reported as irregular files rather than symlinks.
+	if stat.Mode()&(os.ModeSymlink|os.ModeIrregular) == 0 {
+		return true, nil
+	}
+	target, err := os.Readlink(file)
+	if err != nil {
+		return true, fmt.Errorf("readlink error: %v", err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return true, err
+	}
+	return false, nil
+}
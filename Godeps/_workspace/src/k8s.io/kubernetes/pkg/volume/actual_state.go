@@ -0,0 +1,343 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// ActualStateOfWorld caches which volumes are attached to the node and
+// mounted for which of its pods, as recorded by the operations that
+// attached and mounted them.  Volumes are keyed by their unique names, see
+// GetUniqueVolumeName.  It is safe for concurrent use.
+type ActualStateOfWorld interface {
+	// MarkVolumeAsAttached records that the volume of spec is attached to
+	// the node as devicePath.
+	MarkVolumeAsAttached(volumeName string, spec *Spec, devicePath string) error
+	// MarkVolumeAsDetached records that the volume is no longer attached.
+	// Volumes still mounted for a pod can't be detached.
+	MarkVolumeAsDetached(volumeName string) error
+	// MarkVolumeAsMounted records that the volume of spec is mounted for
+	// the pod.  Volumes don't need to be attached first, as only the
+	// volumes of attachable plugins are.
+	MarkVolumeAsMounted(podUID types.UID, volumeName string, spec *Spec) error
+	// MarkVolumeAsMountedExclusively records that the volume of spec is
+	// mounted for the pod, like MarkVolumeAsMounted, unless it is mounted
+	// for another pod, which fails with an *ErrVolumeConflict.  Other pods
+	// that inUse reports false for are forgotten first, so that pods torn
+	// down without updating the cache don't hold on to the volume.
+	MarkVolumeAsMountedExclusively(podUID types.UID, volumeName string, spec *Spec, inUse func(podUID types.UID) bool) error
+	// MarkVolumeAsUnmounted records that the volume is no longer mounted
+	// for the pod.
+	MarkVolumeAsUnmounted(podUID types.UID, volumeName string)
+	// VolumeExists returns whether the volume is attached or mounted.
+	VolumeExists(volumeName string) bool
+	// IsVolumeAttached returns whether the volume is attached.
+	IsVolumeAttached(volumeName string) bool
+	// PodExistsInVolume returns whether the volume is mounted for the pod.
+	PodExistsInVolume(podUID types.UID, volumeName string) bool
+	// GetAttachedVolumes returns the attached volumes ordered by name.
+	GetAttachedVolumes() []AttachedVolume
+	// GetMountedVolumes returns an entry for every pod and volume mounted
+	// for it, ordered by volume name and pod UID.
+	GetMountedVolumes() []MountedPodVolume
+}
+
+// AttachedVolume is a volume attached to the node.
+type AttachedVolume struct {
+	// VolumeName is the unique name of the volume.
+	VolumeName string
+	// PluginName is the name of the plugin of the volume.
+	PluginName string
+	// DevicePath is the device the volume is attached as.
+	DevicePath string
+	Spec       *Spec
+}
+
+// MountedPodVolume is a volume mounted for a pod.
+type MountedPodVolume struct {
+	// VolumeName is the unique name of the volume.
+	VolumeName string
+	// PluginName is the name of the plugin of the volume.
+	PluginName string
+	PodUID     types.UID
+	Spec       *Spec
+}
+
+// NewActualStateOfWorld returns an empty ActualStateOfWorld.
+func NewActualStateOfWorld() ActualStateOfWorld {
+	return &actualStateOfWorld{volumes: map[string]*actualVolume{}}
+}
+
+type actualStateOfWorld struct {
+	lock    sync.RWMutex
+	volumes map[string]*actualVolume
+}
+
+type actualVolume struct {
+	pluginName string
+	attached   bool
+	devicePath string
+	spec       *Spec
+	// pods maps the UIDs of the pods the volume is mounted for to the
+	// spec it was mounted with.
+	pods map[types.UID]*Spec
+}
+
+var _ ActualStateOfWorld = &actualStateOfWorld{}
+
+// getOrAddVolume must be called with asw.lock held.
+func (asw *actualStateOfWorld) getOrAddVolume(volumeName string, spec *Spec) (*actualVolume, error) {
+	if volume, found := asw.volumes[volumeName]; found {
+		return volume, nil
+	}
+	pluginName, _, err := SplitUniqueName(volumeName)
+	if err != nil {
+		return nil, err
+	}
+	volume := &actualVolume{
+		pluginName: pluginName,
+		spec:       spec,
+		pods:       map[types.UID]*Spec{},
+	}
+	asw.volumes[volumeName] = volume
+	return volume, nil
+}
+
+// forgetIfUnused must be called with asw.lock held.
+func (asw *actualStateOfWorld) forgetIfUnused(volumeName string) {
+	if volume, found := asw.volumes[volumeName]; found && !volume.attached && len(volume.pods) == 0 {
+		delete(asw.volumes, volumeName)
+	}
+}
+
+func (asw *actualStateOfWorld) MarkVolumeAsAttached(volumeName string, spec *Spec, devicePath string) error {
+	asw.lock.Lock()
+	defer asw.lock.Unlock()
+	volume, err := asw.getOrAddVolume(volumeName, spec)
+	if err != nil {
+		return err
+	}
+	volume.attached = true
+	volume.devicePath = devicePath
+	volume.spec = spec
+	return nil
+}
+
+func (asw *actualStateOfWorld) MarkVolumeAsDetached(volumeName string) error {
+	asw.lock.Lock()
+	defer asw.lock.Unlock()
+	volume, found := asw.volumes[volumeName]
+	if !found {
+		return nil
+	}
+	if len(volume.pods) != 0 {
+		return fmt.Errorf("volume %s is still mounted for %d pods", volumeName, len(volume.pods))
+	}
+	volume.attached = false
+	volume.devicePath = ""
+	asw.forgetIfUnused(volumeName)
+	return nil
+}
+
+func (asw *actualStateOfWorld) MarkVolumeAsMounted(podUID types.UID, volumeName string, spec *Spec) error {
+	asw.lock.Lock()
+	defer asw.lock.Unlock()
+	volume, err := asw.getOrAddVolume(volumeName, spec)
+	if err != nil {
+		return err
+	}
+	volume.pods[podUID] = spec
+	return nil
+}
+
+func (asw *actualStateOfWorld) MarkVolumeAsMountedExclusively(podUID types.UID, volumeName string, spec *Spec, inUse func(podUID types.UID) bool) error {
+	asw.lock.Lock()
+	defer asw.lock.Unlock()
+	volume, err := asw.getOrAddVolume(volumeName, spec)
+	if err != nil {
+		return err
+	}
+	others := []types.UID{}
+	for uid := range volume.pods {
+		if uid == podUID {
+			continue
+		}
+		if !inUse(uid) {
+			delete(volume.pods, uid)
+			continue
+		}
+		others = append(others, uid)
+	}
+	if len(others) != 0 {
+		sort.Sort(uidSlice(others))
+		return &ErrVolumeConflict{VolumeName: volumeName, PodUID: podUID, Pods: others}
+	}
+	volume.pods[podUID] = spec
+	return nil
+}
+
+func (asw *actualStateOfWorld) MarkVolumeAsUnmounted(podUID types.UID, volumeName string) {
+	asw.lock.Lock()
+	defer asw.lock.Unlock()
+	volume, found := asw.volumes[volumeName]
+	if !found {
+		return
+	}
+	delete(volume.pods, podUID)
+	asw.forgetIfUnused(volumeName)
+}
+
+func (asw *actualStateOfWorld) VolumeExists(volumeName string) bool {
+	asw.lock.RLock()
+	defer asw.lock.RUnlock()
+	_, found := asw.volumes[volumeName]
+	return found
+}
+
+func (asw *actualStateOfWorld) IsVolumeAttached(volumeName string) bool {
+	asw.lock.RLock()
+	defer asw.lock.RUnlock()
+	volume, found := asw.volumes[volumeName]
+	return found && volume.attached
+}
+
+func (asw *actualStateOfWorld) PodExistsInVolume(podUID types.UID, volumeName string) bool {
+	asw.lock.RLock()
+	defer asw.lock.RUnlock()
+	volume, found := asw.volumes[volumeName]
+	if !found {
+		return false
+	}
+	_, found = volume.pods[podUID]
+	return found
+}
+
+func (asw *actualStateOfWorld) GetAttachedVolumes() []AttachedVolume {
+	asw.lock.RLock()
+	defer asw.lock.RUnlock()
+	attached := []AttachedVolume{}
+	for volumeName, volume := range asw.volumes {
+		if volume.attached {
+			attached = append(attached, AttachedVolume{
+				VolumeName: volumeName,
+				PluginName: volume.pluginName,
+				DevicePath: volume.devicePath,
+				Spec:       volume.spec,
+			})
+		}
+	}
+	sort.Sort(attachedByName(attached))
+	return attached
+}
+
+func (asw *actualStateOfWorld) GetMountedVolumes() []MountedPodVolume {
+	asw.lock.RLock()
+	defer asw.lock.RUnlock()
+	mounted := []MountedPodVolume{}
+	for volumeName, volume := range asw.volumes {
+		for podUID, spec := range volume.pods {
+			mounted = append(mounted, MountedPodVolume{
+				VolumeName: volumeName,
+				PluginName: volume.pluginName,
+				PodUID:     podUID,
+				Spec:       spec,
+			})
+		}
+	}
+	sort.Sort(mountedByVolumeAndPod(mounted))
+	return mounted
+}
+
+type uidSlice []types.UID
+
+func (s uidSlice) Len() int           { return len(s) }
+func (s uidSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s uidSlice) Less(i, j int) bool { return s[i] < s[j] }
+
+type attachedByName []AttachedVolume
+
+func (v attachedByName) Len() int           { return len(v) }
+func (v attachedByName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v attachedByName) Less(i, j int) bool { return v[i].VolumeName < v[j].VolumeName }
+
+type mountedByVolumeAndPod []MountedPodVolume
+
+func (v mountedByVolumeAndPod) Len() int      { return len(v) }
+func (v mountedByVolumeAndPod) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v mountedByVolumeAndPod) Less(i, j int) bool {
+	if v[i].VolumeName != v[j].VolumeName {
+		return v[i].VolumeName < v[j].VolumeName
+	}
+	return v[i].PodUID < v[j].PodUID
+}
+
+// StateDiff is what a reconciler has to do for the actual state of the
+// node to become the desired one.
+type StateDiff struct {
+	// ToAttach are the volumes that have to be attached before they are
+	// mounted for the pods in the desired state, one entry per volume.
+	ToAttach []VolumeToMount
+	// ToMount are the volumes to mount for pods whose volume is attached
+	// or doesn't need to be.
+	ToMount []VolumeToMount
+	// ToUnmount are the volumes mounted for pods that no longer want them.
+	ToUnmount []MountedPodVolume
+	// ToDetach are the attached volumes that no pod wants and that are no
+	// longer mounted for any pod.
+	ToDetach []AttachedVolume
+}
+
+// DiffStates compares the desired with the actual state.  The states are
+// read one after the other, so reconcilers that act on the diff while
+// pods come and go must compute it again after each round of operations.
+// Volumes are only detached once unmounted for all pods, so a volume may
+// be in ToUnmount in one diff and in ToDetach in a later one.
+func DiffStates(desired DesiredStateOfWorld, actual ActualStateOfWorld) StateDiff {
+	diff := StateDiff{}
+	toAttach := map[string]bool{}
+	for _, volume := range desired.GetVolumesToMount() {
+		if volume.Attachable && !actual.IsVolumeAttached(volume.VolumeName) {
+			if !toAttach[volume.VolumeName] {
+				toAttach[volume.VolumeName] = true
+				diff.ToAttach = append(diff.ToAttach, volume)
+			}
+			continue
+		}
+		if !actual.PodExistsInVolume(volume.Pod.UID, volume.VolumeName) {
+			diff.ToMount = append(diff.ToMount, volume)
+		}
+	}
+
+	mounted := map[string]bool{}
+	for _, volume := range actual.GetMountedVolumes() {
+		mounted[volume.VolumeName] = true
+		if !desired.PodExistsInVolume(volume.PodUID, volume.VolumeName) {
+			diff.ToUnmount = append(diff.ToUnmount, volume)
+		}
+	}
+	for _, volume := range actual.GetAttachedVolumes() {
+		if !mounted[volume.VolumeName] && !desired.VolumeExists(volume.VolumeName) {
+			diff.ToDetach = append(diff.ToDetach, volume)
+		}
+	}
+	return diff
+}
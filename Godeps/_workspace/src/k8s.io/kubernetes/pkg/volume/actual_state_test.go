@@ -0,0 +1,109 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+)
+
+func TestActualStateOfWorld(t *testing.T) {
+	asw := NewActualStateOfWorld()
+	const volumeName = "kubernetes.io/fake/vol1"
+
+	if err := asw.MarkVolumeAsAttached("invalid", testVolumeSpec("vol1"), "/dev/sdb"); err == nil {
+		t.Errorf("Expected an error for an invalid volume name")
+	}
+	if err := asw.MarkVolumeAsAttached(volumeName, testVolumeSpec("vol1"), "/dev/sdb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := asw.MarkVolumeAsMounted("pod1", volumeName, testVolumeSpec("vol1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !asw.IsVolumeAttached(volumeName) || !asw.PodExistsInVolume("pod1", volumeName) {
+		t.Errorf("Expected the volume to be attached and mounted for pod1")
+	}
+	attached := asw.GetAttachedVolumes()
+	if len(attached) != 1 || attached[0].PluginName != "kubernetes.io/fake" || attached[0].DevicePath != "/dev/sdb" {
+		t.Errorf("Unexpected attached volumes %+v", attached)
+	}
+	if err := asw.MarkVolumeAsDetached(volumeName); err == nil {
+		t.Errorf("Expected an error detaching a mounted volume")
+	}
+
+	asw.MarkVolumeAsUnmounted("pod1", volumeName)
+	if asw.PodExistsInVolume("pod1", volumeName) || !asw.VolumeExists(volumeName) {
+		t.Errorf("Expected the volume to stay attached after unmounting")
+	}
+	if err := asw.MarkVolumeAsDetached(volumeName); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if asw.VolumeExists(volumeName) {
+		t.Errorf("Expected the detached volume to be forgotten")
+	}
+
+	// Volumes that aren't attachable are only mounted.
+	if err := asw.MarkVolumeAsMounted("pod1", "kubernetes.io/local/vol2", testVolumeSpec("vol2")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if asw.IsVolumeAttached("kubernetes.io/local/vol2") || len(asw.GetMountedVolumes()) != 1 {
+		t.Errorf("Unexpected state: %+v", asw.GetMountedVolumes())
+	}
+	asw.MarkVolumeAsUnmounted("pod1", "kubernetes.io/local/vol2")
+	if asw.VolumeExists("kubernetes.io/local/vol2") {
+		t.Errorf("Expected the unmounted volume to be forgotten")
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	dsw := NewDesiredStateOfWorld(newTestPluginMgr(t, &FakeVolumePlugin{PluginName: "kubernetes.io/fake"}))
+	asw := NewActualStateOfWorld()
+
+	vol1, _ := dsw.AddPodToVolume(testPod("pod1"), testVolumeSpec("vol1"))
+	dsw.AddPodToVolume(testPod("pod2"), testVolumeSpec("vol1"))
+	diff := DiffStates(dsw, asw)
+	if len(diff.ToAttach) != 1 || diff.ToAttach[0].VolumeName != vol1 || len(diff.ToMount) != 0 {
+		t.Fatalf("Expected vol1 to be attached once before it is mounted, got %+v", diff)
+	}
+
+	asw.MarkVolumeAsAttached(vol1, testVolumeSpec("vol1"), "/dev/sdb")
+	asw.MarkVolumeAsMounted("pod1", vol1, testVolumeSpec("vol1"))
+	diff = DiffStates(dsw, asw)
+	if len(diff.ToAttach) != 0 || len(diff.ToMount) != 1 || diff.ToMount[0].Pod.UID != "pod2" {
+		t.Fatalf("Expected vol1 to be mounted for pod2, got %+v", diff)
+	}
+
+	asw.MarkVolumeAsMounted("pod2", vol1, testVolumeSpec("vol1"))
+	dsw.DeletePod("pod1")
+	dsw.DeletePod("pod2")
+	diff = DiffStates(dsw, asw)
+	if len(diff.ToUnmount) != 2 || len(diff.ToDetach) != 0 {
+		t.Fatalf("Expected vol1 to be unmounted for both pods before it is detached, got %+v", diff)
+	}
+
+	asw.MarkVolumeAsUnmounted("pod1", vol1)
+	asw.MarkVolumeAsUnmounted("pod2", vol1)
+	diff = DiffStates(dsw, asw)
+	if len(diff.ToUnmount) != 0 || len(diff.ToDetach) != 1 || diff.ToDetach[0].DevicePath != "/dev/sdb" {
+		t.Fatalf("Expected vol1 to be detached, got %+v", diff)
+	}
+
+	asw.MarkVolumeAsDetached(vol1)
+	diff = DiffStates(dsw, asw)
+	if len(diff.ToAttach)+len(diff.ToMount)+len(diff.ToUnmount)+len(diff.ToDetach) != 0 {
+		t.Errorf("Expected no differences, got %+v", diff)
+	}
+}
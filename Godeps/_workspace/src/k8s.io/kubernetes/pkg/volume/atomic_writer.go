@@ -0,0 +1,268 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// atomicWriterDataDir is the symlink to the timestamped directory that
+	// holds the current payload.
+	atomicWriterDataDir = "..data"
+	// atomicWriterNewDataDir is the temporary symlink renamed over
+	// atomicWriterDataDir to switch to a new payload.
+	atomicWriterNewDataDir = "..data_tmp"
+	// maxPayloadPathLength is the longest relative path AtomicWriter accepts.
+	maxPayloadPathLength = 4096
+	// maxPayloadPathSegmentLength is the longest file name AtomicWriter accepts.
+	maxPayloadPathSegmentLength = 255
+)
+
+// AtomicWriter writes a set of files into a directory so that a reader of
+// the directory sees either all of the previous files or all of the new ones,
+// never a mix, which is what volumes projecting API data like secrets or the
+// downward API into a pod need.
+//
+// The files visible in the target directory are symlinks into '..data',
+// which is itself a symlink to a hidden timestamped directory holding the
+// real files:
+//
+//   <target>/podName      -> ..data/podName
+//   <target>/user_space   -> ..data/user_space
+//   <target>/..data       -> ..2015_11_05_10_17_32.123456789
+//
+// To write a new payload, a new timestamped directory is filled and a
+// symlink to it is renamed over '..data', which the kernel does atomically.
+// Names beginning with '..' are reserved for the implementation.
+type AtomicWriter struct {
+	targetDir  string
+	logContext string
+}
+
+// FileProjection is the contents and mode of a file in an AtomicWriter payload.
+type FileProjection struct {
+	Data []byte
+	Mode int32
+}
+
+// NewAtomicWriter returns an AtomicWriter for targetDir, which must exist.
+// logContext is prefixed to log messages, e.g. the volume and pod names.
+func NewAtomicWriter(targetDir, logContext string) (*AtomicWriter, error) {
+	info, err := os.Stat(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", targetDir)
+	}
+	return &AtomicWriter{targetDir: targetDir, logContext: logContext}, nil
+}
+
+// Write makes the target directory hold exactly the files in payload, which
+// maps paths relative to the target directory to file contents and modes.  Files of
+// the previous payload that are not in payload are removed.  Nothing is
+// written if the payload is unchanged.
+func (w *AtomicWriter) Write(payload map[string]FileProjection) error {
+	cleanPayload, err := validatePayload(payload)
+	if err != nil {
+		return err
+	}
+
+	dataDirPath := path.Join(w.targetDir, atomicWriterDataDir)
+	oldTsDir, err := os.Readlink(dataDirPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s: can't read %s: %v", w.logContext, dataDirPath, err)
+	}
+	if oldTsDir != "" && !w.payloadChanged(cleanPayload, path.Join(w.targetDir, oldTsDir)) {
+		glog.V(4).Infof("%s: no update required for %s", w.logContext, w.targetDir)
+		return nil
+	}
+
+	tsDir, err := ioutil.TempDir(w.targetDir, time.Now().Format("..2006_01_02_15_04_05."))
+	if err != nil {
+		return fmt.Errorf("%s: can't make a timestamped directory in %s: %v", w.logContext, w.targetDir, err)
+	}
+	if err := os.Chmod(tsDir, 0755); err != nil {
+		CleanupStagingTree(tsDir, err)
+		return err
+	}
+	if err := writePayloadToDir(cleanPayload, tsDir); err != nil {
+		CleanupStagingTree(tsDir, err)
+		return fmt.Errorf("%s: can't write payload to %s: %v", w.logContext, tsDir, err)
+	}
+
+	newDataDirPath := path.Join(w.targetDir, atomicWriterNewDataDir)
+	os.Remove(newDataDirPath)
+	if err := os.Symlink(path.Base(tsDir), newDataDirPath); err != nil {
+		CleanupStagingTree(tsDir, err)
+		return fmt.Errorf("%s: can't make symlink %s: %v", w.logContext, newDataDirPath, err)
+	}
+	if err := os.Rename(newDataDirPath, dataDirPath); err != nil {
+		os.Remove(newDataDirPath)
+		CleanupStagingTree(tsDir, err)
+		return fmt.Errorf("%s: can't rename %s to %s: %v", w.logContext, newDataDirPath, dataDirPath, err)
+	}
+
+	// From here on the new payload is in place; errors only leave garbage
+	// behind, which the next Write removes.
+	if err := w.updateUserVisibleLinks(cleanPayload); err != nil {
+		return err
+	}
+	return w.removeStaleTimestampDirs(path.Base(tsDir))
+}
+
+// validatePayload returns payload with cleaned paths, or an error if a path
+// is absolute, escapes the target directory, or uses a reserved name.
+func validatePayload(payload map[string]FileProjection) (map[string]FileProjection, error) {
+	cleanPayload := make(map[string]FileProjection, len(payload))
+	for p, content := range payload {
+		if err := validatePayloadPath(p); err != nil {
+			return nil, err
+		}
+		cleanPayload[path.Clean(p)] = content
+	}
+	return cleanPayload, nil
+}
+
+func validatePayloadPath(p string) error {
+	switch {
+	case p == "":
+		return fmt.Errorf("payload path must not be empty")
+	case len(p) > maxPayloadPathLength:
+		return fmt.Errorf("payload path %q is longer than %d bytes", p, maxPayloadPathLength)
+	case path.IsAbs(p):
+		return fmt.Errorf("payload path %q must be relative", p)
+	case strings.HasPrefix(p, ".."):
+		return fmt.Errorf("payload path %q must not start with '..'", p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("payload path %q must not contain '..'", p)
+		}
+		if len(segment) > maxPayloadPathSegmentLength {
+			return fmt.Errorf("payload path %q has a name longer than %d bytes", p, maxPayloadPathSegmentLength)
+		}
+	}
+	return nil
+}
+
+// payloadChanged reports whether the files in tsDir differ from payload.
+// Modes are not compared, the Kubelet adds group bits to files it manages
+// the ownership of.
+func (w *AtomicWriter) payloadChanged(payload map[string]FileProjection, tsDir string) bool {
+	existing := 0
+	err := filepath.Walk(tsDir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			existing++
+		}
+		return err
+	})
+	if err != nil || existing != len(payload) {
+		return true
+	}
+	for p, content := range payload {
+		data, err := ioutil.ReadFile(path.Join(tsDir, p))
+		if err != nil || !bytes.Equal(data, content.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+func writePayloadToDir(payload map[string]FileProjection, dir string) error {
+	for p, content := range payload {
+		file := path.Join(dir, p)
+		mode := os.FileMode(content.Mode).Perm()
+		if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(file, content.Data, mode); err != nil {
+			return err
+		}
+		// WriteFile applies the umask.
+		if err := os.Chmod(file, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateUserVisibleLinks links the top level entry of every payload path
+// into '..data' and removes the links of entries no longer in the payload.
+func (w *AtomicWriter) updateUserVisibleLinks(payload map[string]FileProjection) error {
+	topLevel := make(map[string]bool)
+	for p := range payload {
+		topLevel[strings.SplitN(p, "/", 2)[0]] = true
+	}
+	for name := range topLevel {
+		link := path.Join(w.targetDir, name)
+		if _, err := os.Readlink(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(path.Join(atomicWriterDataDir, name), link); err != nil {
+			return fmt.Errorf("%s: can't make symlink %s: %v", w.logContext, link, err)
+		}
+	}
+
+	entries, err := ioutil.ReadDir(w.targetDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") || topLevel[name] || entry.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := os.Readlink(path.Join(w.targetDir, name))
+		if err != nil || !strings.HasPrefix(target, atomicWriterDataDir+"/") {
+			continue
+		}
+		if err := os.Remove(path.Join(w.targetDir, name)); err != nil {
+			return fmt.Errorf("%s: can't remove stale symlink %s: %v", w.logContext, name, err)
+		}
+	}
+	return nil
+}
+
+// removeStaleTimestampDirs removes the timestamped directories other than
+// current, including those left behind by a Write that didn't finish.
+func (w *AtomicWriter) removeStaleTimestampDirs(current string) error {
+	entries, err := ioutil.ReadDir(w.targetDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, "..") || name == current {
+			continue
+		}
+		if err := os.RemoveAll(path.Join(w.targetDir, name)); err != nil {
+			return fmt.Errorf("%s: can't remove stale directory %s: %v", w.logContext, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func readTargetFiles(t *testing.T, dir string, files ...string) map[string]string {
+	contents := map[string]string{}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(path.Join(dir, f))
+		if err != nil {
+			t.Errorf("Can't read %s: %v", f, err)
+			continue
+		}
+		contents[f] = string(data)
+	}
+	return contents
+}
+
+func timestampDirs(t *testing.T, dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Can't read %s: %v", dir, err)
+	}
+	dirs := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "..") {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs
+}
+
+func TestAtomicWriterWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic_writer_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writer, err := NewAtomicWriter(dir, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := writer.Write(map[string]FileProjection{"podName": {Data: []byte("foo"), Mode: 0644}, "user_space/labels": {Data: []byte("a=b"), Mode: 0644}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	contents := readTargetFiles(t, dir, "podName", "user_space/labels")
+	if contents["podName"] != "foo" || contents["user_space/labels"] != "a=b" {
+		t.Errorf("Unexpected contents: %v", contents)
+	}
+	oldTsDir, err := os.Readlink(path.Join(dir, atomicWriterDataDir))
+	if err != nil {
+		t.Fatalf("Can't read the data symlink: %v", err)
+	}
+
+	// Writing the same payload again changes nothing.
+	if err := writer.Write(map[string]FileProjection{"podName": {Data: []byte("foo"), Mode: 0644}, "user_space/labels": {Data: []byte("a=b"), Mode: 0644}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tsDir, _ := os.Readlink(path.Join(dir, atomicWriterDataDir)); tsDir != oldTsDir {
+		t.Errorf("Expected an unchanged payload not to be written again")
+	}
+
+	// Leave garbage behind as an interrupted Write would.
+	if err := os.Mkdir(path.Join(dir, "..2000_01_01_00_00_00.1"), 0755); err != nil {
+		t.Fatalf("Can't make a stale directory: %v", err)
+	}
+	if err := writer.Write(map[string]FileProjection{"podName": {Data: []byte("bar"), Mode: 0400}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if contents := readTargetFiles(t, dir, "podName"); contents["podName"] != "bar" {
+		t.Errorf("Unexpected contents: %v", contents)
+	}
+	if info, err := os.Stat(path.Join(dir, "podName")); err != nil || info.Mode().Perm() != 0400 {
+		t.Errorf("Expected podName to have mode 0400, got %v, %v", info, err)
+	}
+	if _, err := os.Lstat(path.Join(dir, "user_space")); !os.IsNotExist(err) {
+		t.Errorf("Expected user_space to be removed, got %v", err)
+	}
+	tsDir, _ := os.Readlink(path.Join(dir, atomicWriterDataDir))
+	if dirs := timestampDirs(t, dir); len(dirs) != 1 || dirs[0] != tsDir {
+		t.Errorf("Expected only the current timestamped directory %s, got %v", tsDir, dirs)
+	}
+}
+
+func TestAtomicWriterInvalidPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic_writer_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writer, err := NewAtomicWriter(dir, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, p := range []string{"", "/etc/passwd", "../escape", "foo/../../escape", "..data", "..data_tmp", strings.Repeat("a", 256)} {
+		if err := writer.Write(map[string]FileProjection{p: {Data: []byte("data"), Mode: 0644}}); err == nil {
+			t.Errorf("Expected an error for payload path %q", p)
+		}
+	}
+	if dirs := timestampDirs(t, dir); len(dirs) != 0 {
+		t.Errorf("Expected nothing to be written, got %v", dirs)
+	}
+
+	if _, err := NewAtomicWriter(path.Join(dir, "missing"), "test"); err == nil {
+		t.Errorf("Expected an error for a missing target directory")
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// AuditOutcome is whether an audited operation succeeded.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditRecord describes a volume operation that ran through an
+// OperationRegistry with an AuditSink, for environments which must trace
+// every access to data.
+type AuditRecord struct {
+	// Time is when the operation started.
+	Time time.Time     `json:"time"`
+	Type OperationType `json:"type"`
+	// PodUID is the pod the volume was set up or torn down for, if any.
+	PodUID types.UID `json:"podUID,omitempty"`
+	// VolumeID identifies the volume, see OperationInfo.VolumePath.
+	VolumeID string        `json:"volumeID"`
+	Duration time.Duration `json:"duration"`
+	Outcome  AuditOutcome  `json:"outcome"`
+	// Error is the error the operation failed with.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink stores AuditRecords.  Records are passed to it synchronously
+// once an operation returned, so sinks must be safe for concurrent use and
+// should not block for long.  An error of a sink is logged, but does not
+// fail the operation.
+type AuditSink interface {
+	Record(record AuditRecord) error
+}
+
+// SetOperationAuditSink makes the registry used by the shared lifecycle
+// helpers, e.g. SetUpVolume, record their operations to sink.  A nil sink
+// turns auditing off.
+func SetOperationAuditSink(sink AuditSink) {
+	defaultOperationRegistry.SetAuditSink(sink)
+}
+
+// NewAuditSink returns the AuditSink described by spec, which is the http:// or
+// https:// URL of a webhook, "syslog" or "syslog:<tag>" for the local syslog
+// daemon, or the path of a file.  An empty spec returns a nil sink, which
+// turns auditing off.
+func NewAuditSink(spec string) (AuditSink, error) {
+	switch {
+	case spec == "":
+		return nil, nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewWebhookAuditSink(spec, nil), nil
+	case spec == "syslog":
+		return NewSyslogAuditSink(defaultAuditSyslogTag)
+	case strings.HasPrefix(spec, "syslog:"):
+		return NewSyslogAuditSink(strings.TrimPrefix(spec, "syslog:"))
+	}
+	sink, err := NewFileAuditSink(spec)
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// defaultAuditSyslogTag tags the records of a syslog sink without a tag of
+// its own.
+const defaultAuditSyslogTag = "kubernetes-volume-audit"
+
+// newAuditRecord returns the record of op, which started at start, ran for
+// duration and returned err.
+func newAuditRecord(op Operation, start time.Time, duration time.Duration, err error) AuditRecord {
+	record := AuditRecord{
+		Time:     start,
+		Type:     op.Type,
+		PodUID:   op.PodUID,
+		VolumeID: op.VolumePath,
+		Duration: duration,
+		Outcome:  AuditOutcomeSuccess,
+	}
+	if record.PodUID == "" {
+		record.PodUID = podUIDFromVolumePath(op.VolumePath)
+	}
+	if err != nil {
+		record.Outcome = AuditOutcomeFailure
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// FileAuditSink appends records to a file, one JSON object per line.
+type FileAuditSink struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+var _ AuditSink = &FileAuditSink{}
+
+// NewFileAuditSink opens the file at path for appending records to it,
+// creating it readable by its owner only if it is missing.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+func (s *FileAuditSink) Record(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileAuditSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+// defaultAuditWebhookTimeout bounds the requests of webhook sinks without a
+// client of their own.
+const defaultAuditWebhookTimeout = 10 * time.Second
+
+// NewWebhookAuditSink returns an AuditSink which POSTs every record as JSON
+// to url with client, or with a client giving up after
+// defaultAuditWebhookTimeout if client is nil.  Responses other than 2xx
+// are errors.
+func NewWebhookAuditSink(url string, client *http.Client) AuditSink {
+	if client == nil {
+		client = &http.Client{Timeout: defaultAuditWebhookTimeout}
+	}
+	return &webhookAuditSink{url: url, client: client}
+}
+
+type webhookAuditSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookAuditSink) Record(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// NewSyslogAuditSink returns an AuditSink which writes every record as JSON
+// to the local syslog daemon with tag, at the info priority of the auth
+// facility, or the warning priority for failed operations.
+func NewSyslogAuditSink(tag string) (AuditSink, error) {
+	writer, err := syslog.New(syslog.LOG_AUTH|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogAuditSink{writer: writer}, nil
+}
+
+type syslogAuditSink struct {
+	writer *syslog.Writer
+}
+
+func (s *syslogAuditSink) Record(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if record.Outcome == AuditOutcomeFailure {
+		return s.writer.Warning(string(data))
+	}
+	return s.writer.Info(string(data))
+}
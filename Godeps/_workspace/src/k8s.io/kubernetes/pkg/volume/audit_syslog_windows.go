@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import "fmt"
+
+// NewSyslogAuditSink fails, as there is no syslog on Windows.
+func NewSyslogAuditSink(tag string) (AuditSink, error) {
+	return nil, fmt.Errorf("syslog is not supported on Windows")
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+type fakeAuditSink struct {
+	lock    sync.Mutex
+	records []AuditRecord
+	err     error
+}
+
+func (s *fakeAuditSink) Record(record AuditRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, record)
+	return s.err
+}
+
+func TestOperationRegistryAudit(t *testing.T) {
+	start := time.Date(2015, 11, 2, 10, 0, 0, 0, time.UTC)
+	clock := &util.FakeClock{Time: start}
+	registry := NewOperationRegistry(clock)
+	sink := &fakeAuditSink{err: fmt.Errorf("disk full")}
+	registry.SetAuditSink(sink)
+
+	podVolume := "/var/lib/kubelet/pods/poduid/volumes/kubernetes.io~nfs/vol1"
+	if err := registry.Run(OperationSetUp, podVolume, func() error {
+		clock.Step(time.Second)
+		return nil
+	}); err != nil {
+		t.Errorf("Expected a failing sink not to fail the operation, got %v", err)
+	}
+	if err := registry.Run(OperationDelete, "pv1", func() error {
+		return fmt.Errorf("volume is attached")
+	}); err == nil || err.Error() != "volume is attached" {
+		t.Errorf("Expected the error of the operation, got %v", err)
+	}
+	if err := registry.RunOperation(Operation{Type: OperationTearDown, VolumePath: "/mnt/vol", PodUID: "otheruid", Func: func() error { return nil }}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := []AuditRecord{
+		{Time: start, Type: OperationSetUp, PodUID: "poduid", VolumeID: podVolume, Duration: time.Second, Outcome: AuditOutcomeSuccess},
+		{Time: start.Add(time.Second), Type: OperationDelete, VolumeID: "pv1", Outcome: AuditOutcomeFailure, Error: "volume is attached"},
+		{Time: start.Add(time.Second), Type: OperationTearDown, PodUID: "otheruid", VolumeID: "/mnt/vol", Outcome: AuditOutcomeSuccess},
+	}
+	if !reflect.DeepEqual(sink.records, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, sink.records)
+	}
+
+	// Without a sink nothing is recorded.
+	registry.SetAuditSink(nil)
+	registry.Run(OperationSetUp, podVolume, func() error { return nil })
+	if len(sink.records) != len(expected) {
+		t.Errorf("Expected no more records, got %+v", sink.records)
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "auditTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	file := path.Join(tmpDir, "audit.log")
+
+	sink, err := NewFileAuditSink(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	records := []AuditRecord{
+		{Type: OperationSetUp, PodUID: "poduid", VolumeID: "/vol1", Outcome: AuditOutcomeSuccess},
+		{Type: OperationProvision, VolumeID: "pv1", Outcome: AuditOutcomeFailure, Error: "quota exceeded"},
+	}
+	for _, record := range records {
+		if err := sink.Record(record); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("Expected %d lines, got %q", len(records), string(data))
+	}
+	for i, line := range lines {
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Errorf("Can't decode %q: %v", line, err)
+			continue
+		}
+		record.Time = records[i].Time
+		if !reflect.DeepEqual(record, records[i]) {
+			t.Errorf("Expected %+v, got %+v", records[i], record)
+		}
+	}
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the log to be readable by its owner only, got %v: %v", info.Mode(), err)
+	}
+}
+
+func TestWebhookAuditSink(t *testing.T) {
+	var received []AuditRecord
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record AuditRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			t.Errorf("Can't decode the record: %v", err)
+		}
+		received = append(received, record)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookAuditSink(server.URL, nil)
+	record := AuditRecord{Type: OperationTearDown, PodUID: "poduid", VolumeID: "/vol1", Outcome: AuditOutcomeSuccess}
+	if err := sink.Record(record); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(received) != 1 || received[0].VolumeID != "/vol1" || received[0].PodUID != "poduid" {
+		t.Errorf("Expected the record to be posted, got %+v", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Record(record); err == nil {
+		t.Errorf("Expected an error for a failing webhook")
+	}
+}
+
+func TestNewAuditSink(t *testing.T) {
+	if sink, err := NewAuditSink(""); sink != nil || err != nil {
+		t.Errorf("Expected no sink for an empty spec, got %v, %v", sink, err)
+	}
+	if sink, err := NewAuditSink("https://audit.example.com/volumes"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if webhook, ok := sink.(*webhookAuditSink); !ok || webhook.url != "https://audit.example.com/volumes" {
+		t.Errorf("Expected a webhook sink, got %#v", sink)
+	}
+
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "auditTest")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	sink, err := NewAuditSink(path.Join(tmpDir, "audit.log"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fileSink, ok := sink.(*FileAuditSink); !ok {
+		t.Errorf("Expected a file sink, got %#v", sink)
+	} else {
+		fileSink.Close()
+	}
+	if _, err := NewAuditSink(path.Join(tmpDir, "missing", "audit.log")); err == nil {
+		t.Errorf("Expected an error for a file in a missing directory")
+	}
+}
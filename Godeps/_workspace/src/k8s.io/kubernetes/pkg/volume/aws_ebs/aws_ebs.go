@@ -29,7 +29,6 @@ import (
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/cloudprovider/providers/aws"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -48,9 +47,17 @@ var _ volume.VolumePlugin = &awsElasticBlockStorePlugin{}
 var _ volume.PersistentVolumePlugin = &awsElasticBlockStorePlugin{}
 var _ volume.DeletableVolumePlugin = &awsElasticBlockStorePlugin{}
 var _ volume.ProvisionableVolumePlugin = &awsElasticBlockStorePlugin{}
+var _ volume.EncryptableVolumePlugin = &awsElasticBlockStorePlugin{}
+var _ volume.DeviceMountablePlugin = &awsElasticBlockStorePlugin{}
+var _ volume.ReconstructableVolumePlugin = &awsElasticBlockStorePlugin{}
+var _ volume.VolumePluginWithAttachLimits = &awsElasticBlockStorePlugin{}
 
 const (
-	awsElasticBlockStorePluginName = "kubernetes.io/aws-ebs"
+	awsElasticBlockStorePluginName     = "kubernetes.io/aws-ebs"
+	awsElasticBlockStoreVolumeLimitKey = volume.VolumeLimitKeyPrefix + "aws-ebs"
+
+	// Linux EC2 instances support 39 EBS volumes, more risk boot problems.
+	maxAWSElasticBlockStores = 39
 )
 
 func (plugin *awsElasticBlockStorePlugin) Init(host volume.VolumeHost) {
@@ -66,6 +73,14 @@ func (plugin *awsElasticBlockStorePlugin) CanSupport(spec *volume.Spec) bool {
 		(spec.Volume != nil && spec.Volume.AWSElasticBlockStore != nil)
 }
 
+func (plugin *awsElasticBlockStorePlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.AWSElasticBlockStore == nil {
+		return "", fmt.Errorf("spec does not reference a AWS EBS volume")
+	}
+	return source.AWSElasticBlockStore.VolumeID, nil
+}
+
 func (plugin *awsElasticBlockStorePlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -97,7 +112,7 @@ func (plugin *awsElasticBlockStorePlugin) newBuilderInternal(spec *volume.Spec,
 		partition = strconv.Itoa(ebs.Partition)
 	}
 
-	return &awsElasticBlockStoreBuilder{
+	builder := &awsElasticBlockStoreBuilder{
 		awsElasticBlockStore: &awsElasticBlockStore{
 			podUID:   podUID,
 			volName:  spec.Name(),
@@ -109,7 +124,43 @@ func (plugin *awsElasticBlockStorePlugin) newBuilderInternal(spec *volume.Spec,
 		fsType:      fsType,
 		partition:   partition,
 		readOnly:    readOnly,
-		diskMounter: &mount.SafeFormatAndMount{plugin.host.GetMounter(), exec.New()}}, nil
+		diskMounter: &volume.SafeFormatAndMount{Interface: plugin.host.GetMounter(), Runner: exec.New()},
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
+}
+
+func (plugin *awsElasticBlockStorePlugin) GetDeviceMountPath(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.AWSElasticBlockStore == nil {
+		return "", fmt.Errorf("spec does not reference an AWS EBS volume")
+	}
+	return makeGlobalPDPath(plugin.host, source.AWSElasticBlockStore.VolumeID), nil
+}
+
+func (plugin *awsElasticBlockStorePlugin) ConstructVolumeSpec(volumeName, mountPath string) (*volume.Spec, error) {
+	globalPath, err := volume.GetGlobalMountRef(plugin.host, awsElasticBlockStorePluginName, mountPath)
+	if err != nil {
+		return nil, err
+	}
+	volumeID, err := getVolumeIDFromGlobalMount(plugin.host, globalPath)
+	if err != nil {
+		return nil, err
+	}
+	return volume.NewSpecFromVolume(&api.Volume{
+		Name: volumeName,
+		VolumeSource: api.VolumeSource{
+			AWSElasticBlockStore: &api.AWSElasticBlockStoreVolumeSource{VolumeID: volumeID},
+		},
+	}), nil
+}
+
+func (plugin *awsElasticBlockStorePlugin) GetVolumeLimits() (map[string]int64, error) {
+	return map[string]int64{awsElasticBlockStoreVolumeLimitKey: maxAWSElasticBlockStores}, nil
+}
+
+func (plugin *awsElasticBlockStorePlugin) VolumeLimitKey(spec *volume.Spec) string {
+	return awsElasticBlockStoreVolumeLimitKey
 }
 
 func (plugin *awsElasticBlockStorePlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -144,6 +195,14 @@ func (plugin *awsElasticBlockStorePlugin) newDeleterInternal(spec *volume.Spec,
 		}}, nil
 }
 
+func (plugin *awsElasticBlockStorePlugin) SupportsCloning() bool {
+	return false
+}
+
+func (plugin *awsElasticBlockStorePlugin) SupportsEncryption() bool {
+	return true
+}
+
 func (plugin *awsElasticBlockStorePlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
 	if len(options.AccessModes) == 0 {
 		options.AccessModes = plugin.GetAccessModes()
@@ -152,13 +211,47 @@ func (plugin *awsElasticBlockStorePlugin) NewProvisioner(options volume.VolumeOp
 }
 
 func (plugin *awsElasticBlockStorePlugin) newProvisionerInternal(options volume.VolumeOptions, manager ebsManager) (volume.Provisioner, error) {
-	return &awsElasticBlockStoreProvisioner{
+	provisioner := &awsElasticBlockStoreProvisioner{
 		awsElasticBlockStore: &awsElasticBlockStore{
 			manager: manager,
 			plugin:  plugin,
 		},
 		options: options,
-	}, nil
+	}
+	for k, v := range options.Parameters {
+		var err error
+		switch k {
+		case "type":
+			provisioner.volumeType = v
+		case "zone":
+			// The zone requested by the claim wins.
+			if provisioner.options.Zone == "" {
+				provisioner.options.Zone = v
+			}
+		case "iopsPerGB":
+			provisioner.iopsPerGB, err = strconv.Atoi(v)
+		case volume.EncryptionSecretParameter:
+			// EBS volumes are encrypted by EBS, with KMS keys.
+			err = fmt.Errorf("unknown option")
+		default:
+			var found bool
+			if found, err = provisioner.encryption.Parse(k, v); !found {
+				err = fmt.Errorf("unknown option")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid option %s=%q for volume plugin %s: %v", k, v, awsElasticBlockStorePluginName, err)
+		}
+	}
+	if err := provisioner.encryption.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options for volume plugin %s: %v", awsElasticBlockStorePluginName, err)
+	}
+	zone, err := volume.ChooseZone(provisioner.options)
+	if err != nil {
+		return nil, err
+	}
+	provisioner.options.Zone = zone
+	return provisioner, nil
 }
 
 // Abstract interface to PD operations.
@@ -206,6 +299,7 @@ func (ebs *awsElasticBlockStore) getVolumeProvider() (aws_cloud.Volumes, error)
 
 type awsElasticBlockStoreBuilder struct {
 	*awsElasticBlockStore
+	volume.MetricsProvider
 	// Filesystem type, optional.
 	fsType string
 	// Specifies the partition to mount
@@ -217,10 +311,7 @@ type awsElasticBlockStoreBuilder struct {
 }
 
 var _ volume.Builder = &awsElasticBlockStoreBuilder{}
-
-func (_ *awsElasticBlockStoreBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
+var _ volume.DiskBuilder = &awsElasticBlockStoreBuilder{}
 
 // SetUp attaches the disk and bind mounts to the volume path.
 func (b *awsElasticBlockStoreBuilder) SetUp() error {
@@ -278,7 +369,7 @@ func (b *awsElasticBlockStoreBuilder) SetUpAt(dir string) error {
 				return err
 			}
 		}
-		os.Remove(dir)
+		volume.CleanupStagingDir(dir, err)
 		// TODO: we should really eject the attach/detach out into its own control loop.
 		detachDiskLogError(b.awsElasticBlockStore)
 		return err
@@ -287,12 +378,17 @@ func (b *awsElasticBlockStoreBuilder) SetUpAt(dir string) error {
 	return nil
 }
 
-func (b *awsElasticBlockStoreBuilder) IsReadOnly() bool {
-	return b.readOnly
+func (b *awsElasticBlockStoreBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *awsElasticBlockStoreBuilder) SupportsSELinux() bool {
-	return true
+func (b *awsElasticBlockStoreBuilder) FSType() string {
+	return b.fsType
 }
 
 func makeGlobalPDPath(host volume.VolumeHost, volumeID string) string {
@@ -322,7 +418,7 @@ func getVolumeIDFromGlobalMount(host volume.VolumeHost, globalPath string) (stri
 
 func (ebs *awsElasticBlockStore) GetPath() string {
 	name := awsElasticBlockStorePluginName
-	return ebs.plugin.host.GetPodVolumeDir(ebs.podUID, util.EscapeQualifiedNameForDisk(name), ebs.volName)
+	return volume.NewDirectoryLayout(ebs.plugin.host).PodVolumeDir(ebs.podUID, name, ebs.volName)
 }
 
 type awsElasticBlockStoreCleaner struct {
@@ -350,7 +446,7 @@ func (c *awsElasticBlockStoreCleaner) TearDownAt(dir string) error {
 		return os.Remove(dir)
 	}
 
-	refs, err := mount.GetMountRefs(c.mounter, dir)
+	_, refs, err := mount.PathExistsWithRefs(c.mounter, dir)
 	if err != nil {
 		glog.V(2).Info("Error getting mountrefs for ", dir, ": ", err)
 		return err
@@ -363,13 +459,13 @@ func (c *awsElasticBlockStoreCleaner) TearDownAt(dir string) error {
 		glog.V(2).Info("Error unmounting dir ", dir, ": ", err)
 		return err
 	}
-	// If len(refs) is 1, then all bind mounts have been removed, and the
-	// remaining reference is the global mount. It is safe to detach.
-	if len(refs) == 1 {
+	// If the global mount is the last reference, then no other pod uses
+	// the disk anymore. It is safe to detach.
+	if globalPath, ok := volume.LastGlobalMountRef(c.plugin.host, refs); ok {
 		// c.volumeID is not initially set for volume-cleaners, so set it here.
-		c.volumeID, err = getVolumeIDFromGlobalMount(c.plugin.host, refs[0])
+		c.volumeID, err = getVolumeIDFromGlobalMount(c.plugin.host, globalPath)
 		if err != nil {
-			glog.V(2).Info("Could not determine volumeID from mountpoint ", refs[0], ": ", err)
+			glog.V(2).Info("Could not determine volumeID from mountpoint ", globalPath, ": ", err)
 			return err
 		}
 		if err := c.manager.DetachDisk(&awsElasticBlockStoreCleaner{c.awsElasticBlockStore}); err != nil {
@@ -401,7 +497,7 @@ var _ volume.Deleter = &awsElasticBlockStoreDeleter{}
 
 func (d *awsElasticBlockStoreDeleter) GetPath() string {
 	name := awsElasticBlockStorePluginName
-	return d.plugin.host.GetPodVolumeDir(d.podUID, util.EscapeQualifiedNameForDisk(name), d.volName)
+	return volume.NewDirectoryLayout(d.plugin.host).PodVolumeDir(d.podUID, name, d.volName)
 }
 
 func (d *awsElasticBlockStoreDeleter) Delete() error {
@@ -412,11 +508,23 @@ type awsElasticBlockStoreProvisioner struct {
 	*awsElasticBlockStore
 	options   volume.VolumeOptions
 	namespace string
+	// volumeType is the EBS volume type, e.g. "io1", or empty for the default.
+	volumeType string
+	// iopsPerGB is multiplied with the size of the volume to get the provisioned IOPS of an io1 volume.
+	iopsPerGB int
+	// encryption is how the volume is encrypted by EBS.
+	encryption volume.EncryptionParameters
 }
 
 var _ volume.Provisioner = &awsElasticBlockStoreProvisioner{}
 
 func (c *awsElasticBlockStoreProvisioner) Provision(pv *api.PersistentVolume) error {
+	if c.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: awsElasticBlockStorePluginName, SnapshotID: c.options.SnapshotSource}
+	}
+	if c.options.CloneSource != nil {
+		return &volume.ErrCloneSourceNotSupported{Plugin: awsElasticBlockStorePluginName, Source: c.options.CloneSource.Name}
+	}
 	volumeID, sizeGB, err := c.manager.CreateVolume(c)
 	if err != nil {
 		return err
@@ -429,12 +537,16 @@ func (c *awsElasticBlockStoreProvisioner) Provision(pv *api.PersistentVolume) er
 }
 
 func (c *awsElasticBlockStoreProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
+	labels := map[string]string{}
+	if c.options.Zone != "" {
+		labels[volume.ZoneAnnotation] = c.options.Zone
+	}
 	// Provide dummy api.PersistentVolume.Spec, it will be filled in
 	// awsElasticBlockStoreProvisioner.Provision()
-	return &api.PersistentVolume{
+	pv := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
 			GenerateName: "pv-aws-",
-			Labels:       map[string]string{},
+			Labels:       labels,
 			Annotations: map[string]string{
 				"kubernetes.io/createdby": "aws-ebs-dynamic-provisioner",
 			},
@@ -454,5 +566,11 @@ func (c *awsElasticBlockStoreProvisioner) NewPersistentVolumeTemplate() (*api.Pe
 				},
 			},
 		},
-	}, nil
+	}
+	if c.options.Zone != "" {
+		if err := volume.SetNodeAffinity(pv, volume.ZoneNodeAffinity(c.options.Zone)); err != nil {
+			return nil, err
+		}
+	}
+	return pv, nil
 }
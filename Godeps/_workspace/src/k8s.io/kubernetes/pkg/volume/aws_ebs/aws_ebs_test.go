@@ -50,6 +50,27 @@ func TestCanSupport(t *testing.T) {
 	}
 }
 
+func TestGetVolumeName(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/aws-ebs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	source := &api.AWSElasticBlockStoreVolumeSource{VolumeID: "vol-1234"}
+	inline := &volume.Spec{Volume: &api.Volume{Name: "vol1", VolumeSource: api.VolumeSource{AWSElasticBlockStore: source}}}
+	persistent := &volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{AWSElasticBlockStore: source}}}}
+	for _, spec := range []*volume.Spec{inline, persistent} {
+		if name, err := plug.GetVolumeName(spec); err != nil || name != "vol-1234" {
+			t.Errorf("Expected the volume ID, got %q, %v", name, err)
+		}
+	}
+	if _, err := plug.GetVolumeName(&volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{}}}); err == nil {
+		t.Errorf("Expected an error for a spec without an EBS volume")
+	}
+}
+
 func TestGetAccessModes(t *testing.T) {
 	plugMgr := volume.VolumePluginMgr{}
 	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
@@ -215,6 +236,35 @@ func TestPlugin(t *testing.T) {
 	}
 }
 
+func TestProvisionParameters(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/aws-ebs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	options := volume.VolumeOptions{
+		Capacity:                      resource.MustParse("100Gi"),
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+		Parameters:                    map[string]string{"type": "io1", "iopsPerGB": "10", "encrypted": "true", "kmsKeyId": "arn:aws:kms:us-east-1:123456789012:key/abcd"},
+	}
+	provisioner, err := plug.(*awsElasticBlockStorePlugin).newProvisionerInternal(options, &fakePDManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	if p := provisioner.(*awsElasticBlockStoreProvisioner); p.volumeType != "io1" || p.iopsPerGB != 10 || !p.encryption.Encrypted || p.encryption.KMSKeyID != "arn:aws:kms:us-east-1:123456789012:key/abcd" {
+		t.Errorf("Unexpected provisioner %+v", p)
+	}
+
+	for _, parameters := range []map[string]string{{"iopsPerGB": "many"}, {"encrypted": "maybe"}, {"replicas": "3"}, {"kmsKeyId": "key"}, {"encrypted": "true", "encryptionSecret": "ns/key"}} {
+		options.Parameters = parameters
+		if _, err := plug.(*awsElasticBlockStorePlugin).newProvisionerInternal(options, &fakePDManager{}); err == nil {
+			t.Errorf("Expected an error for parameters %v", parameters)
+		}
+	}
+}
+
 func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pv := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
@@ -258,8 +308,42 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestGetAttributes(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName(awsElasticBlockStorePluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	for _, readOnly := range []bool{false, true} {
+		spec := &api.Volume{
+			Name: "vol1",
+			VolumeSource: api.VolumeSource{
+				AWSElasticBlockStore: &api.AWSElasticBlockStoreVolumeSource{
+					VolumeID: "pd",
+					ReadOnly: readOnly,
+				},
+			},
+		}
+		builder, err := plug.(*awsElasticBlockStorePlugin).newBuilderInternal(volume.NewSpecFromVolume(spec), types.UID("poduid"), &fakePDManager{}, &mount.FakeMounter{})
+		if err != nil {
+			t.Fatalf("Failed to make a new Builder: %v", err)
+		}
+		expected := volume.Attributes{
+			ReadOnly:            readOnly,
+			Managed:             true,
+			FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+			SupportsSELinux:     true,
+		}
+		if attrs := builder.GetAttributes(); attrs != expected {
+			t.Errorf("Expected attributes %+v for readOnly %t, got %+v", expected, readOnly, attrs)
+		}
 	}
 }
 
@@ -78,7 +78,7 @@ func (util *AWSDiskUtil) AttachAndMountDisk(b *awsElasticBlockStoreBuilder, glob
 	if notMnt {
 		err = b.diskMounter.Mount(devicePath, globalPDPath, b.fsType, options)
 		if err != nil {
-			os.Remove(globalPDPath)
+			volume.CleanupStagingDir(globalPDPath, err)
 			return err
 		}
 	}
@@ -132,12 +132,16 @@ func (util *AWSDiskUtil) CreateVolume(c *awsElasticBlockStoreProvisioner) (volum
 		return "", 0, err
 	}
 
-	requestBytes := c.options.Capacity.Value()
 	// AWS works with gigabytes, convert to GiB with rounding up
-	requestGB := int(volume.RoundUpSize(requestBytes, 1024*1024*1024))
+	requestGB := int(volume.RoundUpToGiB(c.options.Capacity))
 	volSpec := &aws_cloud.VolumeOptions{
-		CapacityGB: requestGB,
-		Tags:       c.options.CloudTags,
+		CapacityGB:       requestGB,
+		Tags:             c.options.CloudTags,
+		AvailabilityZone: c.options.Zone,
+		VolumeType:       c.volumeType,
+		IOPSPerGB:        c.iopsPerGB,
+		Encrypted:        c.encryption.Encrypted,
+		KMSKeyID:         c.encryption.KMSKeyID,
 	}
 
 	name, err := volumes.CreateVolume(volSpec)
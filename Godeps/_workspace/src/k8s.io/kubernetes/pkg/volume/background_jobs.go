@@ -0,0 +1,110 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"sync"
+)
+
+// backgroundJobs tracks the background work the package runs against each
+// volume (trimming, metrics collection, ...) so that it can be suspended
+// around operations which must not race with it, such as snapshots.
+type backgroundJobs struct {
+	lock sync.Mutex
+	cond *sync.Cond
+	// suspended counts outstanding suspensions per volume key.
+	suspended map[string]int
+	// active counts jobs currently issuing I/O per volume key.
+	active map[string]int
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	j := &backgroundJobs{
+		suspended: map[string]int{},
+		active:    map[string]int{},
+	}
+	j.cond = sync.NewCond(&j.lock)
+	return j
+}
+
+var defaultBackgroundJobs = newBackgroundJobs()
+
+// SuspendBackgroundJobs pauses all package-managed background work for the
+// volume identified by volumeKey and waits for any job already running
+// against it to finish.  Suspensions nest: the jobs resume only once
+// ResumeBackgroundJobs has been called once for every suspend.
+func SuspendBackgroundJobs(volumeKey string) {
+	defaultBackgroundJobs.suspend(volumeKey)
+}
+
+// ResumeBackgroundJobs undoes one call to SuspendBackgroundJobs.
+func ResumeBackgroundJobs(volumeKey string) {
+	defaultBackgroundJobs.resume(volumeKey)
+}
+
+func (j *backgroundJobs) suspend(key string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.suspended[key]++
+	for j.active[key] > 0 {
+		j.cond.Wait()
+	}
+}
+
+func (j *backgroundJobs) resume(key string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.suspended[key] == 0 {
+		return
+	}
+	j.suspended[key]--
+	if j.suspended[key] == 0 {
+		delete(j.suspended, key)
+	}
+}
+
+// isSuspended reports whether the volume's jobs are suspended.  Jobs which
+// have to wait before they can start, e.g. for a rate limiter, check it
+// first so that they don't wait for nothing; they must still call start.
+func (j *backgroundJobs) isSuspended(key string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.suspended[key] > 0
+}
+
+// start is called by a job before it issues I/O against the volume.  It
+// returns false if the volume's jobs are suspended, in which case the job
+// must skip its work.  Otherwise the job must call finish when done.
+func (j *backgroundJobs) start(key string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.suspended[key] > 0 {
+		return false
+	}
+	j.active[key]++
+	return true
+}
+
+func (j *backgroundJobs) finish(key string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.active[key]--
+	if j.active[key] <= 0 {
+		delete(j.active, key)
+		j.cond.Broadcast()
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+func TestSuspendBackgroundJobs(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(2, "", nil, &trimmed)
+	clock := &util.FakeClock{Time: time.Now()}
+	s := newTrimScheduler(&Trimmer{Runner: runner}, []string{"/a", "/b"}, time.Hour, clock, util.NewFakeRateLimiter())
+	s.jobs = newBackgroundJobs()
+
+	// Nested suspends need a matching number of resumes.
+	s.jobs.suspend("/a")
+	s.jobs.suspend("/a")
+	s.trimDue()
+	if len(trimmed) != 1 || trimmed[0] != "/b" {
+		t.Fatalf("Expected only /b to be trimmed while /a is suspended, got %v", trimmed)
+	}
+
+	s.jobs.resume("/a")
+	s.trimDue()
+	if len(trimmed) != 1 {
+		t.Fatalf("Expected /a to stay suspended after one resume, got %v", trimmed)
+	}
+
+	s.jobs.resume("/a")
+	s.trimDue()
+	if len(trimmed) != 2 || trimmed[1] != "/a" {
+		t.Fatalf("Expected /a to be trimmed after resuming, got %v", trimmed)
+	}
+}
+
+func TestSuspendBackgroundJobsWaitsForRunningJob(t *testing.T) {
+	jobs := newBackgroundJobs()
+	if !jobs.start("/a") {
+		t.Fatalf("Expected job to start")
+	}
+
+	suspended := make(chan struct{})
+	go func() {
+		jobs.suspend("/a")
+		close(suspended)
+	}()
+	select {
+	case <-suspended:
+		t.Fatalf("Suspend returned while a job was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	jobs.finish("/a")
+	select {
+	case <-suspended:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Suspend did not return after the job finished")
+	}
+	if jobs.start("/a") {
+		t.Errorf("Expected job not to start while suspended")
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupManifestName is the name of the tar entry holding the manifest.  It
+// is always the last entry of a backup stream.
+const backupManifestName = ".kubernetes-backup-manifest.json"
+
+// BackupEntry records the state of a single file, directory or symlink at
+// backup time.
+type BackupEntry struct {
+	Mode    os.FileMode `json:"mode"`
+	ModTime time.Time   `json:"modTime"`
+	Size    int64       `json:"size,omitempty"`
+	// SHA256 is the hash of a regular file's contents.
+	SHA256 string `json:"sha256,omitempty"`
+	// Target is the target of a symlink.
+	Target string `json:"target,omitempty"`
+}
+
+// BackupManifest describes the complete tree a backup stream brings a volume
+// to, relative to the previous backup in the chain.
+type BackupManifest struct {
+	// Entries holds every path in the tree at backup time, keyed by slash
+	// separated path relative to the volume root.
+	Entries map[string]BackupEntry `json:"entries"`
+	// Deleted lists the paths of the base manifest which no longer exist.
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// IncrementalBackup writes a tar stream to w containing every entry under
+// path that is new or changed since baseManifest, followed by a manifest of
+// the complete tree.  A nil baseManifest produces a full backup.  Files whose
+// size, mode and modification time match the base are assumed unchanged;
+// others are rehashed so that a touched but unmodified file is not included.
+// The returned manifest is the base for the next increment.
+func IncrementalBackup(path string, baseManifest *BackupManifest, w io.Writer) (*BackupManifest, error) {
+	if baseManifest == nil {
+		baseManifest = &BackupManifest{}
+	}
+	manifest := &BackupManifest{Entries: map[string]BackupEntry{}}
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		entry := BackupEntry{Mode: info.Mode(), ModTime: info.ModTime()}
+		base, inBase := baseManifest.Entries[rel]
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if entry.Target, err = os.Readlink(file); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			entry.Size = info.Size()
+			if inBase && base.Mode == entry.Mode && base.Size == entry.Size && base.ModTime.Equal(entry.ModTime) {
+				entry.SHA256 = base.SHA256
+			} else if entry.SHA256, err = hashFile(file); err != nil {
+				return err
+			}
+		case !info.IsDir():
+			// Devices, sockets and pipes can't be restored meaningfully.
+			return nil
+		}
+		manifest.Entries[rel] = entry
+
+		if inBase && base.Mode == entry.Mode && base.SHA256 == entry.SHA256 && base.Target == entry.Target {
+			return nil
+		}
+		return writeBackupEntry(tw, file, rel, info, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for rel := range baseManifest.Entries {
+		if _, found := manifest.Entries[rel]; !found {
+			manifest.Deleted = append(manifest.Deleted, rel)
+		}
+	}
+	sort.Strings(manifest.Deleted)
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0600, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeBackupEntry(tw *tar.Writer, file, rel string, info os.FileInfo, entry BackupEntry) error {
+	hdr, err := tar.FileInfoHeader(info, entry.Target)
+	if err != nil {
+		return err
+	}
+	hdr.Name = rel
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func hashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RestoreBackup applies backup streams produced by IncrementalBackup to path,
+// in order: first a full backup, then each increment taken on top of it.
+// It returns the manifest of the last stream.
+func RestoreBackup(path string, backups ...io.Reader) (*BackupManifest, error) {
+	var manifest *BackupManifest
+	for i, r := range backups {
+		m, err := restoreBackupStream(path, r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore backup %d: %v", i, err)
+		}
+		manifest = m
+	}
+	return manifest, nil
+}
+
+func restoreBackupStream(root string, r io.Reader) (*BackupManifest, error) {
+	var manifest *BackupManifest
+	// Directory modes are applied last, so that restoring a read-only
+	// directory does not prevent restoring its contents.
+	dirModes := map[string]os.FileMode{}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == backupManifestName {
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			manifest = &BackupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("corrupt backup manifest: %v", err)
+			}
+			continue
+		}
+
+		target, err := backupTargetPath(root, hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := clearBackupTarget(target, true); err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return nil, err
+			}
+			dirModes[target] = mode.Perm()
+		case tar.TypeSymlink:
+			if err := clearBackupTarget(target, false); err != nil {
+				return nil, err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			if err := clearBackupTarget(target, false); err != nil {
+				return nil, err
+			}
+			if err := restoreBackupFile(target, tr, mode.Perm()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("backup stream has no manifest")
+	}
+
+	for _, rel := range manifest.Deleted {
+		target, err := backupTargetPath(root, rel)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return nil, err
+		}
+	}
+	for dir, mode := range dirModes {
+		if err := os.Chmod(dir, mode); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// clearBackupTarget removes whatever is at target so that an entry can be
+// restored there, even if its type changed since the previous backup.  Only
+// a directory restored over a directory is kept, along with its contents.
+func clearBackupTarget(target string, isDir bool) error {
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isDir && info.IsDir() {
+		return nil
+	}
+	return os.RemoveAll(target)
+}
+
+func restoreBackupFile(target string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(target, mode)
+}
+
+// backupTargetPath resolves a path from a backup stream below root, refusing
+// paths which would escape it, either lexically or through a symlink the
+// stream restored earlier, e.g. "a" -> "/etc" followed by "a/passwd".
+func backupTargetPath(root, rel string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("backup entry %q escapes the volume", rel)
+	}
+	parts := strings.Split(cleaned, string(filepath.Separator))
+	parent := root
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("backup entry %q is beneath the symlink %s", rel, parent)
+		}
+	}
+	return filepath.Join(root, cleaned), nil
+}
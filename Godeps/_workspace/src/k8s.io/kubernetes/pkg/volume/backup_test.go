@@ -0,0 +1,198 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// backupEntryNames lists the tar entries of a backup stream, without the
+// manifest.
+func backupEntryNames(t *testing.T, data []byte) []string {
+	names := []string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error reading backup: %v", err)
+		}
+		if hdr.Name != backupManifestName {
+			names = append(names, hdr.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestIncrementalBackup(t *testing.T) {
+	src := makeTree(t, []string{"a.txt", "sub/b.txt", "sub/c/d.txt"})
+	defer os.RemoveAll(src)
+
+	full := &bytes.Buffer{}
+	base, err := IncrementalBackup(src, nil, full)
+	if err != nil {
+		t.Fatalf("Unexpected error taking full backup: %v", err)
+	}
+	expected := []string{"a.txt", "link", "sub", "sub/b.txt", "sub/c", "sub/c/d.txt"}
+	if names := backupEntryNames(t, full.Bytes()); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected full backup to contain %v, got %v", expected, names)
+	}
+
+	// Nothing changed, so the increment carries only the manifest.
+	unchanged := &bytes.Buffer{}
+	m, err := IncrementalBackup(src, base, unchanged)
+	if err != nil {
+		t.Fatalf("Unexpected error taking increment: %v", err)
+	}
+	if names := backupEntryNames(t, unchanged.Bytes()); len(names) != 0 {
+		t.Errorf("Expected empty increment for an unchanged tree, got %v", names)
+	}
+	if len(m.Deleted) != 0 || !reflect.DeepEqual(m.Entries, base.Entries) {
+		t.Errorf("Expected manifest of an unchanged tree to match its base, got %+v", m)
+	}
+
+	// Change one file, add one and delete another.
+	if err := ioutil.WriteFile(path.Join(src, "sub/b.txt"), []byte("bravo!"), 0640); err != nil {
+		t.Fatalf("can't write file: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(src, "new.txt"), []byte("new"), 0600); err != nil {
+		t.Fatalf("can't write file: %v", err)
+	}
+	if err := os.Remove(path.Join(src, "sub/c/d.txt")); err != nil {
+		t.Fatalf("can't remove file: %v", err)
+	}
+	incr := &bytes.Buffer{}
+	m, err = IncrementalBackup(src, base, incr)
+	if err != nil {
+		t.Fatalf("Unexpected error taking increment: %v", err)
+	}
+	expected = []string{"new.txt", "sub/b.txt"}
+	if names := backupEntryNames(t, incr.Bytes()); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected increment to contain %v, got %v", expected, names)
+	}
+	if !reflect.DeepEqual(m.Deleted, []string{"sub/c/d.txt"}) {
+		t.Errorf("Expected sub/c/d.txt to be recorded as deleted, got %v", m.Deleted)
+	}
+
+	// Restoring the full backup plus the increment reproduces the tree.
+	dst, err := ioutil.TempDir("", "backup_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dst)
+	if _, err := RestoreBackup(dst, bytes.NewReader(full.Bytes()), bytes.NewReader(incr.Bytes())); err != nil {
+		t.Fatalf("Unexpected error restoring: %v", err)
+	}
+	srcHash, _ := ContentHash(context.Background(), src)
+	dstHash, _ := ContentHash(context.Background(), dst)
+	if srcHash != dstHash {
+		t.Errorf("Expected restored tree to match the source")
+	}
+}
+
+func TestRestoreBackupRejectsEscapes(t *testing.T) {
+	data := &bytes.Buffer{}
+	tw := tar.NewWriter(data)
+	tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0600, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+
+	dst, err := ioutil.TempDir("", "backup_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dst)
+	if _, err := RestoreBackup(dst, data); err == nil {
+		t.Errorf("Expected error restoring an entry outside the volume")
+	}
+}
+
+// writeBackupStream writes a backup stream of the given entries, in order,
+// followed by an empty manifest.
+func writeBackupStream(t *testing.T, headers []*tar.Header) *bytes.Buffer {
+	data := &bytes.Buffer{}
+	tw := tar.NewWriter(data)
+	for _, hdr := range headers {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if hdr.Size > 0 {
+			tw.Write(bytes.Repeat([]byte("x"), int(hdr.Size)))
+		}
+	}
+	tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0600, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("{}"))
+	tw.Close()
+	return data
+}
+
+func TestRestoreBackupRejectsSymlinkEscapes(t *testing.T) {
+	outside, err := ioutil.TempDir("", "backup_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(outside)
+	dst, err := ioutil.TempDir("", "backup_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dst)
+
+	data := writeBackupStream(t, []*tar.Header{
+		{Name: "a", Linkname: outside, Mode: 0777, Typeflag: tar.TypeSymlink},
+		{Name: "a/x", Mode: 0600, Size: 1, Typeflag: tar.TypeReg},
+	})
+	if _, err := RestoreBackup(dst, data); err == nil {
+		t.Errorf("Expected error restoring an entry beneath a symlink")
+	}
+	if _, err := os.Lstat(path.Join(outside, "x")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written outside the volume, got %v", err)
+	}
+}
+
+func TestRestoreBackupReplacesChangedTypes(t *testing.T) {
+	dst := makeTree(t, []string{"file-then-dir", "dir-then-file/inner.txt"})
+	defer os.RemoveAll(dst)
+
+	data := writeBackupStream(t, []*tar.Header{
+		{Name: "file-then-dir", Mode: 0755, Typeflag: tar.TypeDir},
+		{Name: "file-then-dir/new.txt", Mode: 0600, Size: 1, Typeflag: tar.TypeReg},
+		{Name: "dir-then-file", Mode: 0600, Size: 1, Typeflag: tar.TypeReg},
+	})
+	if _, err := RestoreBackup(dst, data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info, err := os.Lstat(path.Join(dst, "file-then-dir")); err != nil || !info.IsDir() {
+		t.Errorf("Expected the file to be replaced by a directory, got %v, %v", info, err)
+	}
+	if info, err := os.Lstat(path.Join(dst, "dir-then-file")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("Expected the directory to be replaced by a file, got %v, %v", info, err)
+	}
+}
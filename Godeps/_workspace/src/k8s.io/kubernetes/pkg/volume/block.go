@@ -0,0 +1,94 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path"
+)
+
+// MapBlockVolume links the device at devicePath as linkName in both the
+// node wide globalMapPath and the pod's podDeviceMapPath, creating the
+// directories as needed.  Existing links are replaced, so it is safe to call
+// again after a device moved.
+func MapBlockVolume(devicePath, globalMapPath, podDeviceMapPath, linkName string) error {
+	for _, dir := range []string{globalMapPath, podDeviceMapPath} {
+		if err := makeDeviceLink(devicePath, dir, linkName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmapBlockVolume removes the pod's link to the device made by
+// MapBlockVolume, and the node wide link too if no other pod links to the
+// same device.  It returns whether the global link was removed, in which
+// case the device can be torn down.
+func UnmapBlockVolume(globalMapPath, podDeviceMapPath, linkName string, otherPodDeviceMapPaths []string) (bool, error) {
+	if err := removeDeviceLink(path.Join(podDeviceMapPath, linkName)); err != nil {
+		return false, err
+	}
+	globalLink := path.Join(globalMapPath, linkName)
+	device, err := os.Readlink(globalLink)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, dir := range otherPodDeviceMapPaths {
+		if target, err := os.Readlink(path.Join(dir, linkName)); err == nil && target == device {
+			return false, nil
+		}
+	}
+	if err := removeDeviceLink(globalLink); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func makeDeviceLink(devicePath, dir, linkName string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	link := path.Join(dir, linkName)
+	if target, err := os.Readlink(link); err == nil && target == devicePath {
+		return nil
+	}
+	if err := removeDeviceLink(link); err != nil {
+		return err
+	}
+	if err := os.Symlink(devicePath, link); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %v", link, devicePath, err)
+	}
+	return nil
+}
+
+// removeDeviceLink removes the symlink at link, refusing to remove anything
+// that is not a symlink.
+func removeDeviceLink(link string) error {
+	info, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a symlink to a device", link)
+	}
+	return os.Remove(link)
+}
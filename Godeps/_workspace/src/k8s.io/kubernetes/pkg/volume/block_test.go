@@ -0,0 +1,94 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func expectLink(t *testing.T, link, target string) {
+	got, err := os.Readlink(link)
+	if target == "" {
+		if !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to exist, got %q, %v", link, got, err)
+		}
+		return
+	}
+	if err != nil || got != target {
+		t.Errorf("Expected %s to link to %s, got %q, %v", link, target, got, err)
+	}
+}
+
+func TestMapBlockVolume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	global := path.Join(dir, "plugins/fake/volumeDevices/vol1")
+	pod1 := path.Join(dir, "pods/uid1/volumeDevices/fake")
+	pod2 := path.Join(dir, "pods/uid2/volumeDevices/fake")
+
+	for _, podDir := range []string{pod1, pod2} {
+		if err := MapBlockVolume("/dev/sdb", global, podDir, "vol1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expectLink(t, path.Join(podDir, "vol1"), "/dev/sdb")
+	}
+	expectLink(t, path.Join(global, "vol1"), "/dev/sdb")
+
+	// Mapping again after the device moved replaces the links.
+	if err := MapBlockVolume("/dev/sdc", global, pod1, "vol1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectLink(t, path.Join(pod1, "vol1"), "/dev/sdc")
+	expectLink(t, path.Join(global, "vol1"), "/dev/sdc")
+	if err := MapBlockVolume("/dev/sdc", global, pod2, "vol1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The global link stays while another pod uses the device.
+	removed, err := UnmapBlockVolume(global, pod1, "vol1", []string{pod2})
+	if err != nil || removed {
+		t.Fatalf("Expected the global link to be kept, got %v, %v", removed, err)
+	}
+	expectLink(t, path.Join(pod1, "vol1"), "")
+	expectLink(t, path.Join(global, "vol1"), "/dev/sdc")
+
+	removed, err = UnmapBlockVolume(global, pod2, "vol1", []string{pod1})
+	if err != nil || !removed {
+		t.Fatalf("Expected the global link to be removed, got %v, %v", removed, err)
+	}
+	expectLink(t, path.Join(global, "vol1"), "")
+}
+
+func TestMapBlockVolumeRefusesFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(path.Join(dir, "vol1"), []byte("data"), 0600); err != nil {
+		t.Fatalf("Can't write file: %v", err)
+	}
+	if err := MapBlockVolume("/dev/sdb", path.Join(dir, "global"), dir, "vol1"); err == nil {
+		t.Errorf("Expected a regular file not to be replaced by a link")
+	}
+}
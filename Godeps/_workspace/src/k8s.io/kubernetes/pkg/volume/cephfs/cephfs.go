@@ -18,12 +18,15 @@ package cephfs
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
@@ -55,6 +58,18 @@ func (plugin *cephfsPlugin) CanSupport(spec *volume.Spec) bool {
 	return (spec.Volume != nil && spec.Volume.CephFS != nil) || (spec.PersistentVolume != nil && spec.PersistentVolume.Spec.CephFS != nil)
 }
 
+func (plugin *cephfsPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.CephFS == nil {
+		return "", fmt.Errorf("spec does not reference a cephfs volume")
+	}
+	name := strings.Join(source.CephFS.Monitors, ",")
+	if source.CephFS.Path != "" {
+		name += ":" + source.CephFS.Path
+	}
+	return name, nil
+}
+
 func (plugin *cephfsPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -82,10 +97,10 @@ func (plugin *cephfsPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume
 			glog.V(1).Infof("found ceph secret info: %s", name)
 		}
 	}
-	return plugin.newBuilderInternal(spec, pod.UID, plugin.host.GetMounter(), secret)
+	return plugin.newBuilderInternal(spec, pod.UID, plugin.host.GetMounter(), exec.New(), secret)
 }
 
-func (plugin *cephfsPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, mounter mount.Interface, secret string) (volume.Builder, error) {
+func (plugin *cephfsPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, mounter mount.Interface, exe exec.Interface, secret string) (volume.Builder, error) {
 	cephvs := plugin.getVolumeSource(spec)
 	id := cephvs.User
 	if id == "" {
@@ -95,18 +110,25 @@ func (plugin *cephfsPlugin) newBuilderInternal(spec *volume.Spec, podUID types.U
 	if secret_file == "" {
 		secret_file = "/etc/ceph/" + id + ".secret"
 	}
+	root := cephvs.Path
+	if root == "" {
+		root = "/"
+	}
 
 	return &cephfsBuilder{
 		cephfs: &cephfs{
-			podUID:      podUID,
-			volName:     spec.Name(),
-			mon:         cephvs.Monitors,
-			secret:      secret,
-			id:          id,
-			secret_file: secret_file,
-			readonly:    cephvs.ReadOnly,
-			mounter:     mounter,
-			plugin:      plugin},
+			podUID:       podUID,
+			volName:      spec.Name(),
+			mon:          cephvs.Monitors,
+			path:         root,
+			secret:       secret,
+			id:           id,
+			secret_file:  secret_file,
+			readonly:     cephvs.ReadOnly,
+			mountOptions: spec.MountOptions(),
+			mounter:      mounter,
+			plugin:       plugin},
+		exe: exe,
 	}, nil
 }
 
@@ -125,36 +147,31 @@ func (plugin *cephfsPlugin) newCleanerInternal(volName string, podUID types.UID,
 }
 
 func (plugin *cephfsPlugin) getVolumeSource(spec *volume.Spec) *api.CephFSVolumeSource {
-	if spec.Volume != nil && spec.Volume.CephFS != nil {
-		return spec.Volume.CephFS
-	} else {
-		return spec.PersistentVolume.Spec.CephFS
-	}
+	return spec.PersistentVolumeSource().CephFS
 }
 
 // CephFS volumes represent a bare host file or directory mount of an CephFS export.
 type cephfs struct {
-	volName     string
-	podUID      types.UID
-	mon         []string
-	id          string
-	secret      string
-	secret_file string
-	readonly    bool
-	mounter     mount.Interface
-	plugin      *cephfsPlugin
+	volName      string
+	podUID       types.UID
+	mon          []string
+	path         string
+	id           string
+	secret       string
+	secret_file  string
+	readonly     bool
+	mountOptions []string
+	mounter      mount.Interface
+	plugin       *cephfsPlugin
 }
 
 type cephfsBuilder struct {
 	*cephfs
+	exe exec.Interface
 }
 
 var _ volume.Builder = &cephfsBuilder{}
 
-func (_ *cephfsBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
-
 // SetUp attaches the disk and bind mounts to the volume path.
 func (cephfsVolume *cephfsBuilder) SetUp() error {
 	return cephfsVolume.SetUpAt(cephfsVolume.GetPath())
@@ -178,17 +195,24 @@ func (cephfsVolume *cephfsBuilder) SetUpAt(dir string) error {
 	}
 
 	// cleanup upon failure
-	cephfsVolume.cleanup(dir)
+	if mntErr := volume.UnmountMountPoint(dir, cephfsVolume.mounter); mntErr != nil {
+		glog.Errorf("CephFS: failed to clean up %s after a failed mount: %v", dir, mntErr)
+	} else {
+		volume.CleanupStagingDir(dir, err)
+	}
+	if rmErr := os.RemoveAll(path.Dir(cephfsVolume.keyfilePath())); rmErr != nil {
+		glog.Errorf("CephFS: failed to remove the keyfile after a failed mount: %v", rmErr)
+	}
 	// return error
 	return err
 }
 
-func (cephfsVolume *cephfsBuilder) IsReadOnly() bool {
-	return cephfsVolume.readonly
-}
-
-func (cephfsVolume *cephfsBuilder) SupportsSELinux() bool {
-	return false
+func (cephfsVolume *cephfsBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        cephfsVolume.readonly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
 }
 
 type cephfsCleaner struct {
@@ -210,35 +234,42 @@ func (cephfsVolume *cephfsCleaner) TearDownAt(dir string) error {
 // GatePath creates global mount path
 func (cephfsVolume *cephfs) GetPath() string {
 	name := cephfsPluginName
-	return cephfsVolume.plugin.host.GetPodVolumeDir(cephfsVolume.podUID, util.EscapeQualifiedNameForDisk(name), cephfsVolume.volName)
+	return volume.NewDirectoryLayout(cephfsVolume.plugin.host).PodVolumeDir(cephfsVolume.podUID, name, cephfsVolume.volName)
+}
+
+// GetMetrics reports the usage of the mounted share.  Walking the share over
+// the network would be too slow, so its whole filesystem is reported.
+func (cephfsVolume *cephfs) GetMetrics() (*volume.Metrics, error) {
+	return volume.NewMetricsStatFS(cephfsVolume.GetPath()).GetMetrics()
+}
+
+// keyfilePath returns the file the key of the user is written to for
+// ceph-fuse, which takes no secret on its command line.
+func (cephfsVolume *cephfs) keyfilePath() string {
+	return path.Join(volume.NewDirectoryLayout(cephfsVolume.plugin.host).PodPluginDir(cephfsVolume.podUID, cephfsPluginName), cephfsVolume.volName, "keyfile")
 }
 
 func (cephfsVolume *cephfs) cleanup(dir string) error {
-	noMnt, err := cephfsVolume.mounter.IsLikelyNotMountPoint(dir)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("CephFS: Error checking IsLikelyNotMountPoint: %v", err)
+	if err := volume.UnmountPath(dir, cephfsVolume.mounter); err != nil {
+		return fmt.Errorf("CephFS: %v", err)
 	}
-	if noMnt {
-		return os.RemoveAll(dir)
+	if err := os.RemoveAll(path.Dir(cephfsVolume.keyfilePath())); err != nil {
+		return fmt.Errorf("CephFS: failed to remove the keyfile: %v", err)
 	}
+	return nil
+}
 
-	if err := cephfsVolume.mounter.Unmount(dir); err != nil {
-		return fmt.Errorf("CephFS: Unmounting failed: %v", err)
-	}
-	noMnt, mntErr := cephfsVolume.mounter.IsLikelyNotMountPoint(dir)
-	if mntErr != nil {
-		return fmt.Errorf("CephFS: IsMountpoint check failed: %v", mntErr)
-	}
-	if noMnt {
-		if err := os.RemoveAll(dir); err != nil {
-			return fmt.Errorf("CephFS: removeAll %s/%v", dir, err)
-		}
+// execMount mounts the filesystem with the kernel client, or with ceph-fuse
+// on nodes whose kernel has no ceph module.
+func (cephfsVolume *cephfsBuilder) execMount(mountpoint string) error {
+	if output, err := cephfsVolume.exe.Command("modprobe", "ceph").CombinedOutput(); err != nil {
+		glog.V(4).Infof("CephFS: kernel client unavailable, falling back to ceph-fuse: %v, output: %q", err, string(output))
+		return cephfsVolume.execFuseMount(mountpoint)
 	}
-
-	return nil
+	return cephfsVolume.execKernelMount(mountpoint)
 }
 
-func (cephfsVolume *cephfs) execMount(mountpoint string) error {
+func (cephfsVolume *cephfsBuilder) execKernelMount(mountpoint string) error {
 	// cephfs mount option
 	ceph_opt := ""
 	// override secretfile if secret is provided
@@ -253,17 +284,11 @@ func (cephfsVolume *cephfs) execMount(mountpoint string) error {
 		opt = append(opt, "ro")
 	}
 	opt = append(opt, ceph_opt)
+	opt = volume.JoinMountOptions(cephfsVolume.mountOptions, opt)
 
-	// build src like mon1:6789,mon2:6789,mon3:6789:/
-	hosts := cephfsVolume.mon
-	l := len(hosts)
-	// pass all monitors and let ceph randomize and fail over
-	i := 0
-	src := ""
-	for i = 0; i < l-1; i++ {
-		src += hosts[i] + ","
-	}
-	src += hosts[i] + ":/"
+	// build src like mon1:6789,mon2:6789,mon3:6789:/path, passing all
+	// monitors to let ceph randomize and fail over
+	src := strings.Join(cephfsVolume.mon, ",") + ":" + cephfsVolume.path
 
 	if err := cephfsVolume.mounter.Mount(src, mountpoint, "ceph", opt); err != nil {
 		return fmt.Errorf("CephFS: mount failed: %v", err)
@@ -271,3 +296,32 @@ func (cephfsVolume *cephfs) execMount(mountpoint string) error {
 
 	return nil
 }
+
+// execFuseMount mounts the filesystem with ceph-fuse.  The mount is not made
+// read-only: containers get read-only volumes through their bind mounts.
+func (cephfsVolume *cephfsBuilder) execFuseMount(mountpoint string) error {
+	if _, err := cephfsVolume.exe.LookPath("ceph-fuse"); err != nil {
+		return fmt.Errorf("CephFS: neither the ceph kernel module nor ceph-fuse is available: %v", err)
+	}
+	keyfile := cephfsVolume.secret_file
+	if cephfsVolume.secret != "" {
+		keyfile = cephfsVolume.keyfilePath()
+		if err := os.MkdirAll(path.Dir(keyfile), 0750); err != nil {
+			return fmt.Errorf("CephFS: failed to create the keyfile directory: %v", err)
+		}
+		if err := ioutil.WriteFile(keyfile, []byte(cephfsVolume.secret), 0600); err != nil {
+			return fmt.Errorf("CephFS: failed to write the keyfile: %v", err)
+		}
+	}
+	args := []string{
+		mountpoint,
+		"-m", strings.Join(cephfsVolume.mon, ","),
+		"-r", cephfsVolume.path,
+		"--id", cephfsVolume.id,
+		"--keyfile", keyfile,
+	}
+	if output, err := cephfsVolume.exe.Command("ceph-fuse", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("CephFS: ceph-fuse mount failed: %v, output: %q", err, string(output))
+	}
+	return nil
+}
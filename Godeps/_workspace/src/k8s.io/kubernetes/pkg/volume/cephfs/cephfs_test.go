@@ -17,11 +17,16 @@ limitations under the License.
 package cephfs
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"reflect"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
@@ -63,7 +68,8 @@ func TestPlugin(t *testing.T) {
 		},
 	}
 
-	builder, err := plug.(*cephfsPlugin).newBuilderInternal(volume.NewSpecFromVolume(spec), types.UID("poduid"), &mount.FakeMounter{}, "secrets")
+	fakeMounter := &mount.FakeMounter{}
+	builder, err := plug.(*cephfsPlugin).newBuilderInternal(volume.NewSpecFromVolume(spec), types.UID("poduid"), fakeMounter, cephExec(nil, nil, nil), "secrets")
 	volumePath := builder.GetPath()
 	if err != nil {
 		t.Errorf("Failed to make a new Builder: %v", err)
@@ -78,6 +84,9 @@ func TestPlugin(t *testing.T) {
 	if err := builder.SetUp(); err != nil {
 		t.Errorf("Expected success, got: %v", err)
 	}
+	if len(fakeMounter.Log) != 1 || fakeMounter.Log[0].Source != "a,b:/" || fakeMounter.Log[0].FSType != "ceph" {
+		t.Errorf("Expected a kernel mount of a,b:/, got %#v", fakeMounter.Log)
+	}
 	if _, err := os.Stat(volumePath); err != nil {
 		if os.IsNotExist(err) {
 			t.Errorf("SetUp() failed, volume path not created: %s", volumePath)
@@ -101,3 +110,150 @@ func TestPlugin(t *testing.T) {
 		t.Errorf("SetUp() failed: %v", err)
 	}
 }
+
+// cephExec returns a FakeExec whose modprobe fails with modprobeErr and
+// which finds ceph-fuse unless lookPathErr is set.  The argv of the ceph-fuse
+// commands is recorded in fuseArgvs.
+func cephExec(modprobeErr, lookPathErr error, fuseArgvs *[][]string) *exec.FakeExec {
+	fake := &exec.FakeExec{
+		LookPathFunc: func(file string) (string, error) {
+			if lookPathErr != nil {
+				return "", lookPathErr
+			}
+			return "/usr/bin/" + file, nil
+		},
+	}
+	fake.CommandScript = []exec.FakeCommandAction{
+		func(cmd string, args ...string) exec.Cmd {
+			return &exec.FakeCmd{CombinedOutputScript: []exec.FakeCombinedOutputAction{
+				func() ([]byte, error) { return nil, modprobeErr },
+			}}
+		},
+		func(cmd string, args ...string) exec.Cmd {
+			*fuseArgvs = append(*fuseArgvs, append([]string{cmd}, args...))
+			return &exec.FakeCmd{CombinedOutputScript: []exec.FakeCombinedOutputAction{
+				func() ([]byte, error) { return nil, nil },
+			}}
+		},
+	}
+	return fake
+}
+
+func newTestPlugin(t *testing.T) (*cephfsPlugin, string) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "cephfs_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost(tmpDir, nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/cephfs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	return plug.(*cephfsPlugin), tmpDir
+}
+
+func TestGetVolumeName(t *testing.T) {
+	plug, tmpDir := newTestPlugin(t)
+	defer os.RemoveAll(tmpDir)
+	tests := map[string]*api.CephFSVolumeSource{
+		"a,b":              {Monitors: []string{"a", "b"}},
+		"a,b:/exports/vol": {Monitors: []string{"a", "b"}, Path: "/exports/vol"},
+	}
+	for expected, source := range tests {
+		spec := volume.NewSpecFromVolume(&api.Volume{Name: "vol1", VolumeSource: api.VolumeSource{CephFS: source}})
+		if name, err := plug.GetVolumeName(spec); err != nil || name != expected {
+			t.Errorf("Expected %s, got %s: %v", expected, name, err)
+		}
+	}
+}
+
+func TestSubdirMount(t *testing.T) {
+	plug, tmpDir := newTestPlugin(t)
+	defer os.RemoveAll(tmpDir)
+	spec := volume.NewSpecFromPersistentVolume(&api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv1"},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				CephFS: &api.CephFSVolumeSource{Monitors: []string{"a:6789", "b:6789"}, Path: "/exports/pv1", ReadOnly: true},
+			},
+		},
+	}, false)
+
+	fakeMounter := &mount.FakeMounter{}
+	builder, err := plug.newBuilderInternal(spec, types.UID("poduid"), fakeMounter, cephExec(nil, nil, nil), "")
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	expected := []mount.FakeAction{{Action: mount.FakeActionMount, Target: builder.GetPath(), Source: "a:6789,b:6789:/exports/pv1", FSType: "ceph"}}
+	if !reflect.DeepEqual(fakeMounter.Log, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, fakeMounter.Log)
+	}
+}
+
+func TestFuseFallback(t *testing.T) {
+	plug, tmpDir := newTestPlugin(t)
+	defer os.RemoveAll(tmpDir)
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name: "vol1",
+		VolumeSource: api.VolumeSource{
+			CephFS: &api.CephFSVolumeSource{Monitors: []string{"a", "b"}, Path: "/exports/vol1", User: "user"},
+		},
+	})
+
+	fakeMounter := &mount.FakeMounter{}
+	argvs := [][]string{}
+	fakeExec := cephExec(fmt.Errorf("module ceph not found"), nil, &argvs)
+	builder, err := plug.newBuilderInternal(spec, types.UID("poduid"), fakeMounter, fakeExec, "key")
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	if len(fakeMounter.Log) != 0 {
+		t.Errorf("Expected no kernel mount, got %#v", fakeMounter.Log)
+	}
+	keyfile := path.Join(tmpDir, "pods/poduid/plugins/kubernetes.io~cephfs/vol1/keyfile")
+	expected := [][]string{{"ceph-fuse", builder.GetPath(), "-m", "a,b", "-r", "/exports/vol1", "--id", "user", "--keyfile", keyfile}}
+	if !reflect.DeepEqual(argvs, expected) {
+		t.Errorf("Expected %v, got %v", expected, argvs)
+	}
+	if data, err := ioutil.ReadFile(keyfile); err != nil || string(data) != "key" {
+		t.Errorf("Expected the secret in %s, got %q: %v", keyfile, string(data), err)
+	}
+
+	cleaner, err := plug.newCleanerInternal("vol1", types.UID("poduid"), fakeMounter)
+	if err != nil {
+		t.Fatalf("Failed to make a new Cleaner: %v", err)
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if _, err := os.Stat(keyfile); !os.IsNotExist(err) {
+		t.Errorf("Expected the keyfile to be removed, got: %v", err)
+	}
+}
+
+func TestNoClientAvailable(t *testing.T) {
+	plug, tmpDir := newTestPlugin(t)
+	defer os.RemoveAll(tmpDir)
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{CephFS: &api.CephFSVolumeSource{Monitors: []string{"a"}}},
+	})
+	fakeExec := cephExec(fmt.Errorf("module ceph not found"), fmt.Errorf("not found"), nil)
+	builder, err := plug.newBuilderInternal(spec, types.UID("poduid"), &mount.FakeMounter{}, fakeExec, "")
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error")
+	}
+	if _, err := os.Stat(builder.GetPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the volume dir to be cleaned up, got: %v", err)
+	}
+}
@@ -27,7 +27,6 @@ import (
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/cloudprovider/providers/openstack"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -46,9 +45,14 @@ var _ volume.VolumePlugin = &cinderPlugin{}
 var _ volume.PersistentVolumePlugin = &cinderPlugin{}
 var _ volume.DeletableVolumePlugin = &cinderPlugin{}
 var _ volume.ProvisionableVolumePlugin = &cinderPlugin{}
+var _ volume.VolumePluginWithAttachLimits = &cinderPlugin{}
 
 const (
-	cinderVolumePluginName = "kubernetes.io/cinder"
+	cinderVolumePluginName     = "kubernetes.io/cinder"
+	cinderVolumeVolumeLimitKey = volume.VolumeLimitKeyPrefix + "cinder"
+
+	// Nova attaches Cinder volumes as virtio disks, of which a guest has at most 256.
+	maxCinderVolumes = 256
 )
 
 func (plugin *cinderPlugin) Init(host volume.VolumeHost) {
@@ -63,12 +67,28 @@ func (plugin *cinderPlugin) CanSupport(spec *volume.Spec) bool {
 	return (spec.Volume != nil && spec.Volume.Cinder != nil) || (spec.PersistentVolume != nil && spec.PersistentVolume.Spec.Cinder != nil)
 }
 
+func (plugin *cinderPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.Cinder == nil {
+		return "", fmt.Errorf("spec does not reference a cinder volume")
+	}
+	return source.Cinder.VolumeID, nil
+}
+
 func (plugin *cinderPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
 	}
 }
 
+func (plugin *cinderPlugin) GetVolumeLimits() (map[string]int64, error) {
+	return map[string]int64{cinderVolumeVolumeLimitKey: maxCinderVolumes}, nil
+}
+
+func (plugin *cinderPlugin) VolumeLimitKey(spec *volume.Spec) string {
+	return cinderVolumeVolumeLimitKey
+}
+
 func (plugin *cinderPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
 	return plugin.newBuilderInternal(spec, pod.UID, &CinderDiskUtil{}, plugin.host.GetMounter())
 }
@@ -85,7 +105,7 @@ func (plugin *cinderPlugin) newBuilderInternal(spec *volume.Spec, podUID types.U
 	fsType := cinder.FSType
 	readOnly := cinder.ReadOnly
 
-	return &cinderVolumeBuilder{
+	builder := &cinderVolumeBuilder{
 		cinderVolume: &cinderVolume{
 			podUID:  podUID,
 			volName: spec.Name(),
@@ -96,7 +116,10 @@ func (plugin *cinderPlugin) newBuilderInternal(spec *volume.Spec, podUID types.U
 		},
 		fsType:             fsType,
 		readOnly:           readOnly,
-		blockDeviceMounter: &cinderSafeFormatAndMount{mounter, exec.New()}}, nil
+		blockDeviceMounter: &volume.SafeFormatAndMount{Interface: mounter, Runner: exec.New()},
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
 }
 
 func (plugin *cinderPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -131,6 +154,10 @@ func (plugin *cinderPlugin) newDeleterInternal(spec *volume.Spec, manager cdMana
 		}}, nil
 }
 
+func (plugin *cinderPlugin) SupportsCloning() bool {
+	return true
+}
+
 func (plugin *cinderPlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
 	if len(options.AccessModes) == 0 {
 		options.AccessModes = plugin.GetAccessModes()
@@ -139,13 +166,32 @@ func (plugin *cinderPlugin) NewProvisioner(options volume.VolumeOptions) (volume
 }
 
 func (plugin *cinderPlugin) newProvisionerInternal(options volume.VolumeOptions, manager cdManager) (volume.Provisioner, error) {
-	return &cinderVolumeProvisioner{
+	provisioner := &cinderVolumeProvisioner{
 		cinderVolume: &cinderVolume{
 			manager: manager,
 			plugin:  plugin,
 		},
 		options: options,
-	}, nil
+	}
+	for k, v := range options.Parameters {
+		switch k {
+		case "type":
+			provisioner.volumeType = v
+		case "availability":
+			// The zone requested by the claim wins.
+			if provisioner.options.Zone == "" {
+				provisioner.options.Zone = v
+			}
+		default:
+			return nil, fmt.Errorf("invalid option %q for volume plugin %s", k, cinderVolumePluginName)
+		}
+	}
+	zone, err := volume.ChooseZone(provisioner.options)
+	if err != nil {
+		return nil, err
+	}
+	provisioner.options.Zone = zone
+	return provisioner, nil
 }
 
 func (plugin *cinderPlugin) getCloudProvider() (*openstack.OpenStack, error) {
@@ -175,9 +221,11 @@ type cdManager interface {
 }
 
 var _ volume.Builder = &cinderVolumeBuilder{}
+var _ volume.DiskBuilder = &cinderVolumeBuilder{}
 
 type cinderVolumeBuilder struct {
 	*cinderVolume
+	volume.MetricsProvider
 	fsType             string
 	readOnly           bool
 	blockDeviceMounter mount.Interface
@@ -212,10 +260,6 @@ func detachDiskLogError(cd *cinderVolume) {
 	}
 }
 
-func (_ *cinderVolumeBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
-
 func (b *cinderVolumeBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
 }
@@ -271,7 +315,7 @@ func (b *cinderVolumeBuilder) SetUpAt(dir string) error {
 				return err
 			}
 		}
-		os.Remove(dir)
+		volume.CleanupStagingDir(dir, err)
 		// TODO: we should really eject the attach/detach out into its own control loop.
 		detachDiskLogError(b.cinderVolume)
 		return err
@@ -280,12 +324,17 @@ func (b *cinderVolumeBuilder) SetUpAt(dir string) error {
 	return nil
 }
 
-func (b *cinderVolumeBuilder) IsReadOnly() bool {
-	return b.readOnly
+func (b *cinderVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *cinderVolumeBuilder) SupportsSELinux() bool {
-	return true
+func (b *cinderVolumeBuilder) FSType() string {
+	return b.fsType
 }
 
 func makeGlobalPDName(host volume.VolumeHost, devName string) string {
@@ -294,7 +343,7 @@ func makeGlobalPDName(host volume.VolumeHost, devName string) string {
 
 func (cd *cinderVolume) GetPath() string {
 	name := cinderVolumePluginName
-	return cd.plugin.host.GetPodVolumeDir(cd.podUID, util.EscapeQualifiedNameForDisk(name), cd.volName)
+	return volume.NewDirectoryLayout(cd.plugin.host).PodVolumeDir(cd.podUID, name, cd.volName)
 }
 
 type cinderVolumeCleaner struct {
@@ -317,7 +366,7 @@ func (c *cinderVolumeCleaner) TearDownAt(dir string) error {
 	if notmnt {
 		return os.Remove(dir)
 	}
-	refs, err := mount.GetMountRefs(c.mounter, dir)
+	_, refs, err := mount.PathExistsWithRefs(c.mounter, dir)
 	if err != nil {
 		return err
 	}
@@ -326,10 +375,10 @@ func (c *cinderVolumeCleaner) TearDownAt(dir string) error {
 	}
 	glog.Infof("successfully unmounted: %s\n", dir)
 
-	// If refCount is 1, then all bind mounts have been removed, and the
-	// remaining reference is the global mount. It is safe to detach.
-	if len(refs) == 1 {
-		c.pdName = path.Base(refs[0])
+	// If the global mount is the last reference, then no other pod uses
+	// the disk anymore. It is safe to detach.
+	if globalPath, ok := volume.LastGlobalMountRef(c.plugin.host, refs); ok {
+		c.pdName = path.Base(globalPath)
 		if err := c.manager.DetachDisk(c); err != nil {
 			return err
 		}
@@ -355,7 +404,7 @@ var _ volume.Deleter = &cinderVolumeDeleter{}
 
 func (r *cinderVolumeDeleter) GetPath() string {
 	name := cinderVolumePluginName
-	return r.plugin.host.GetPodVolumeDir(r.podUID, util.EscapeQualifiedNameForDisk(name), r.volName)
+	return volume.NewDirectoryLayout(r.plugin.host).PodVolumeDir(r.podUID, name, r.volName)
 }
 
 func (r *cinderVolumeDeleter) Delete() error {
@@ -365,11 +414,19 @@ func (r *cinderVolumeDeleter) Delete() error {
 type cinderVolumeProvisioner struct {
 	*cinderVolume
 	options volume.VolumeOptions
+	// volumeType is the Cinder volume type, or empty for the default of the cloud.
+	volumeType string
 }
 
 var _ volume.Provisioner = &cinderVolumeProvisioner{}
 
 func (c *cinderVolumeProvisioner) Provision(pv *api.PersistentVolume) error {
+	if c.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: cinderVolumePluginName, SnapshotID: c.options.SnapshotSource}
+	}
+	if source := c.options.CloneSource; source != nil && source.Spec.Cinder == nil {
+		return fmt.Errorf("can't clone volume %s, it is not a cinder volume", source.Name)
+	}
 	volumeID, sizeGB, err := c.manager.CreateVolume(c)
 	if err != nil {
 		return err
@@ -382,12 +439,16 @@ func (c *cinderVolumeProvisioner) Provision(pv *api.PersistentVolume) error {
 }
 
 func (c *cinderVolumeProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
+	labels := map[string]string{}
+	if c.options.Zone != "" {
+		labels[volume.ZoneAnnotation] = c.options.Zone
+	}
 	// Provide dummy api.PersistentVolume.Spec, it will be filled in
 	// cinderVolumeProvisioner.Provision()
-	return &api.PersistentVolume{
+	pv := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
 			GenerateName: "pv-cinder-",
-			Labels:       map[string]string{},
+			Labels:       labels,
 			Annotations: map[string]string{
 				"kubernetes.io/createdby": "cinder-dynamic-provisioner",
 			},
@@ -406,6 +467,12 @@ func (c *cinderVolumeProvisioner) NewPersistentVolumeTemplate() (*api.Persistent
 				},
 			},
 		},
-	}, nil
+	}
+	if c.options.Zone != "" {
+		if err := volume.SetNodeAffinity(pv, volume.ZoneNodeAffinity(c.options.Zone)); err != nil {
+			return nil, err
+		}
+	}
+	return pv, nil
 
 }
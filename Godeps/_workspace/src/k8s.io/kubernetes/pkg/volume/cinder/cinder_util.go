@@ -18,7 +18,6 @@ package cinder
 
 import (
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -27,7 +26,6 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/util/exec"
-	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
 
@@ -84,7 +82,7 @@ func (util *CinderDiskUtil) AttachDisk(b *cinderVolumeBuilder, globalPDPath stri
 	if notmnt {
 		err = b.blockDeviceMounter.Mount(devicePath, globalPDPath, b.fsType, options)
 		if err != nil {
-			os.Remove(globalPDPath)
+			volume.CleanupStagingDir(globalPDPath, err)
 			return err
 		}
 		glog.V(2).Infof("Safe mount successful: %q\n", devicePath)
@@ -150,10 +148,13 @@ func (util *CinderDiskUtil) CreateVolume(c *cinderVolumeProvisioner) (volumeID s
 		return "", 0, err
 	}
 
-	volSizeBytes := c.options.Capacity.Value()
 	// Cinder works with gigabytes, convert to GiB with rounding up
-	volSizeGB := int(volume.RoundUpSize(volSizeBytes, 1024*1024*1024))
-	name, err := cloud.CreateVolume(volSizeGB)
+	volSizeGB := int(volume.RoundUpToGiB(c.options.Capacity))
+	var sourceVolumeID string
+	if c.options.CloneSource != nil {
+		sourceVolumeID = c.options.CloneSource.Spec.Cinder.VolumeID
+	}
+	name, err := cloud.CreateVolume(volSizeGB, c.options.Zone, c.volumeType, sourceVolumeID)
 	if err != nil {
 		glog.V(2).Infof("Error creating cinder volume: %v", err)
 		return "", 0, err
@@ -162,72 +163,6 @@ func (util *CinderDiskUtil) CreateVolume(c *cinderVolumeProvisioner) (volumeID s
 	return name, volSizeGB, nil
 }
 
-type cinderSafeFormatAndMount struct {
-	mount.Interface
-	runner exec.Interface
-}
-
-/*
-The functions below depend on the following executables; This will have to be ported to more generic implementations
-/bin/lsblk
-/sbin/mkfs.ext3 or /sbin/mkfs.ext4
-/usr/bin/udevadm
-*/
-func (diskmounter *cinderSafeFormatAndMount) Mount(device string, target string, fstype string, options []string) error {
-	fmtRequired, err := isFormatRequired(device, fstype, diskmounter)
-	if err != nil {
-		glog.Warningf("Failed to determine if formating is required: %v\n", err)
-		//return err
-	}
-	if fmtRequired {
-		glog.V(2).Infof("Formatting of the vol required")
-		if _, err := formatVolume(device, fstype, diskmounter); err != nil {
-			glog.Warningf("Failed to format volume: %v\n", err)
-			return err
-		}
-	}
-	return diskmounter.Interface.Mount(device, target, fstype, options)
-}
-
-func isFormatRequired(devicePath string, fstype string, exec *cinderSafeFormatAndMount) (bool, error) {
-	args := []string{"-f", devicePath}
-	glog.V(4).Infof("exec-ing: /bin/lsblk %v\n", args)
-	cmd := exec.runner.Command("/bin/lsblk", args...)
-	dataOut, err := cmd.CombinedOutput()
-	if err != nil {
-		glog.Warningf("error running /bin/lsblk\n%s", string(dataOut))
-		return false, err
-	}
-	if len(string(dataOut)) > 0 {
-		if strings.Contains(string(dataOut), fstype) {
-			return false, nil
-		} else {
-			return true, nil
-		}
-	} else {
-		glog.Warningf("Failed to get any response from /bin/lsblk")
-		return false, errors.New("Failed to get reponse from /bin/lsblk")
-	}
-	glog.Warningf("Unknown error occured executing /bin/lsblk")
-	return false, errors.New("Unknown error occured executing /bin/lsblk")
-}
-
-func formatVolume(devicePath string, fstype string, exec *cinderSafeFormatAndMount) (bool, error) {
-	if "ext4" != fstype && "ext3" != fstype {
-		glog.Warningf("Unsupported format type: %q\n", fstype)
-		return false, errors.New(fmt.Sprint("Unsupported format type: %q\n", fstype))
-	}
-	args := []string{devicePath}
-	cmd := exec.runner.Command(fmt.Sprintf("/sbin/mkfs.%s", fstype), args...)
-	dataOut, err := cmd.CombinedOutput()
-	if err != nil {
-		glog.Warningf("error running /sbin/mkfs for fstype: %q \n%s", fstype, string(dataOut))
-		return false, err
-	}
-	glog.V(2).Infof("Successfully formated device: %q with fstype %q; output:\n %q\n,", devicePath, fstype, string(dataOut))
-	return true, err
-}
-
 func probeAttachedVolume() error {
 	executor := exec.New()
 	args := []string{"trigger"}
@@ -0,0 +1,259 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// ProbeVolumePlugins is the entry point for plugin detection in a package.
+func ProbeVolumePlugins() []volume.VolumePlugin {
+	return []volume.VolumePlugin{&configMapPlugin{}}
+}
+
+const (
+	configMapPluginName = "kubernetes.io/configmap"
+	// defaultFileMode is the mode of configMap files whose mode isn't set.
+	defaultFileMode = 0644
+)
+
+// configMapPlugin implements the VolumePlugin interface.
+type configMapPlugin struct {
+	host volume.VolumeHost
+}
+
+var _ volume.VolumePlugin = &configMapPlugin{}
+
+func (plugin *configMapPlugin) Init(host volume.VolumeHost) {
+	plugin.host = host
+}
+
+func (plugin *configMapPlugin) Name() string {
+	return configMapPluginName
+}
+
+func (plugin *configMapPlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.Volume != nil && spec.Volume.ConfigMap != nil
+}
+
+func (plugin *configMapPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.ConfigMap == nil {
+		return "", fmt.Errorf("spec does not reference a configMap volume")
+	}
+	return spec.Name(), nil
+}
+
+func (plugin *configMapPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
+	return &configMapVolumeBuilder{
+		configMapVolume: &configMapVolume{spec.Name(), pod.UID, plugin, plugin.host.GetMounter()},
+		source:          *spec.Volume.ConfigMap,
+		pod:             *pod,
+		opts:            &opts}, nil
+}
+
+func (plugin *configMapPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
+	return &configMapVolumeCleaner{&configMapVolume{volName, podUID, plugin, plugin.host.GetMounter()}}, nil
+}
+
+type configMapVolume struct {
+	volName string
+	podUID  types.UID
+	plugin  *configMapPlugin
+	mounter mount.Interface
+}
+
+var _ volume.Volume = &configMapVolume{}
+
+func (cv *configMapVolume) GetPath() string {
+	return volume.NewDirectoryLayout(cv.plugin.host).PodVolumeDir(cv.podUID, configMapPluginName, cv.volName)
+}
+
+// configMapVolumeBuilder handles retrieving configMaps from the API server
+// and placing them into the volume on the host.
+type configMapVolumeBuilder struct {
+	*configMapVolume
+
+	source api.ConfigMapVolumeSource
+	pod    api.Pod
+	opts   *volume.VolumeOptions
+}
+
+var _ volume.Builder = &configMapVolumeBuilder{}
+var _ volume.ContentRefresher = &configMapVolumeBuilder{}
+
+func (b *configMapVolumeBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// This is the spec for the volume that this plugin wraps.
+var wrappedVolumeSpec = &volume.Spec{
+	Volume: &api.Volume{VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{Medium: api.StorageMediumMemory}}},
+}
+
+func (b *configMapVolumeBuilder) getMetaDir() string {
+	return path.Join(volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.podUID, configMapPluginName), b.volName)
+}
+
+func (b *configMapVolumeBuilder) SetUpAt(dir string) error {
+	notMnt, err := b.mounter.IsLikelyNotMountPoint(dir)
+	// Getting an os.IsNotExist err from is a contingency; the directory
+	// may not exist yet, in which case, setup should run.
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// If the plugin readiness file is present for this volume and
+	// the setup dir is a mountpoint, the tmpfs is already set up and
+	// only the configMap data is refreshed.
+	ready := volumeutil.IsReady(b.getMetaDir()) && !notMnt
+	if !ready {
+		glog.V(3).Infof("Setting up volume %v for pod %v at %v", b.volName, b.pod.UID, dir)
+
+		// Wrap EmptyDir, let it do the setup.
+		wrapped, err := b.plugin.host.NewWrapperBuilder(wrappedVolumeSpec, &b.pod, *b.opts)
+		if err != nil {
+			return err
+		}
+		if err := wrapped.SetUpAt(dir); err != nil {
+			return err
+		}
+	}
+
+	kubeClient := b.plugin.host.GetKubeClient()
+	if kubeClient == nil {
+		return fmt.Errorf("Cannot setup configMap volume %v because kube client is not configured", b.volName)
+	}
+
+	payload, err := CollectData(kubeClient, b.pod.Namespace, &b.source)
+	if err != nil {
+		if ready {
+			// Keep serving the data already in the volume.
+			return nil
+		}
+		return err
+	}
+	writer, err := volume.NewAtomicWriter(dir, fmt.Sprintf("configMap volume %v for pod %v", b.volName, b.pod.UID))
+	if err != nil {
+		return err
+	}
+	if err := writer.Write(payload); err != nil {
+		glog.Errorf("Error writing configMap data %v/%v to %v: %v", b.pod.Namespace, b.source.Name, dir, err)
+		return err
+	}
+
+	volumeutil.SetReady(b.getMetaDir())
+
+	return nil
+}
+
+func (b *configMapVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            false,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeAlways,
+		SupportsSELinux:     true,
+	}
+}
+
+// RefreshesContent tells volume.EnforceReadOnly that SetUp rewrites the
+// files of the volume, which must stay writable therefore.
+func (cv *configMapVolume) RefreshesContent() bool {
+	return true
+}
+
+// CollectData returns the files to project the configMap of source in
+// namespace into.  An optional source that doesn't exist projects no files.
+func CollectData(kubeClient client.Interface, namespace string, source *api.ConfigMapVolumeSource) (map[string]volume.FileProjection, error) {
+	optional := source.Optional != nil && *source.Optional
+	configMap, err := kubeClient.ConfigMaps(namespace).Get(source.Name)
+	if err != nil {
+		if optional && apierrors.IsNotFound(err) {
+			glog.V(3).Infof("Optional configMap %v/%v doesn't exist, projecting no files", namespace, source.Name)
+			return map[string]volume.FileProjection{}, nil
+		}
+		glog.Errorf("Couldn't get configMap %v/%v: %v", namespace, source.Name, err)
+		return nil, err
+	}
+	glog.V(3).Infof("Received configMap %v/%v containing (%v) pieces of data", namespace, source.Name, len(configMap.Data))
+	return MakePayload(source.Items, configMap, source.DefaultMode, optional)
+}
+
+// MakePayload returns the files to project configMap into: the keys selected
+// by items at their paths, or every key under its own name if items is empty.
+// Files whose mode isn't set by their item get defaultMode, 0644 if nil.  A
+// key selected by items that configMap lacks is an error unless optional.
+func MakePayload(items []api.KeyToPath, configMap *api.ConfigMap, defaultMode *int32, optional bool) (map[string]volume.FileProjection, error) {
+	fileMode := int32(defaultFileMode)
+	if defaultMode != nil {
+		fileMode = *defaultMode
+	}
+	payload := make(map[string]volume.FileProjection, len(configMap.Data))
+	if len(items) == 0 {
+		for name, data := range configMap.Data {
+			payload[name] = volume.FileProjection{Data: []byte(data), Mode: fileMode}
+		}
+		return payload, nil
+	}
+	for _, item := range items {
+		data, ok := configMap.Data[item.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("configMap %v/%v has no key %q", configMap.Namespace, configMap.Name, item.Key)
+		}
+		mode := fileMode
+		if item.Mode != nil {
+			mode = *item.Mode
+		}
+		payload[item.Path] = volume.FileProjection{Data: []byte(data), Mode: mode}
+	}
+	return payload, nil
+}
+
+// configMapVolumeCleaner handles cleaning up configMap volumes.
+type configMapVolumeCleaner struct {
+	*configMapVolume
+}
+
+var _ volume.Cleaner = &configMapVolumeCleaner{}
+
+func (c *configMapVolumeCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+func (c *configMapVolumeCleaner) TearDownAt(dir string) error {
+	glog.V(3).Infof("Tearing down volume %v for pod %v at %v", c.volName, c.podUID, dir)
+
+	// Wrap EmptyDir, let it do the teardown.
+	wrapped, err := c.plugin.host.NewWrapperCleaner(wrappedVolumeSpec, c.podUID)
+	if err != nil {
+		return err
+	}
+	return wrapped.TearDownAt(dir)
+}
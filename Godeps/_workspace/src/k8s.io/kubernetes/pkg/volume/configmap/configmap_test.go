@@ -0,0 +1,292 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/empty_dir"
+)
+
+func newTestHost(t *testing.T, client client.Interface) (string, volume.VolumeHost) {
+	tempDir, err := ioutil.TempDir("/tmp", "configmap_volume_test.")
+	if err != nil {
+		t.Fatalf("can't make a temp rootdir: %v", err)
+	}
+
+	return tempDir, volume.NewFakeVolumeHost(tempDir, client, empty_dir.ProbeVolumePlugins())
+}
+
+func TestCanSupport(t *testing.T) {
+	pluginMgr := volume.VolumePluginMgr{}
+	_, host := newTestHost(t, nil)
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+
+	plugin, err := pluginMgr.FindPluginByName(configMapPluginName)
+	if err != nil {
+		t.Errorf("Can't find the plugin by name")
+	}
+	if plugin.Name() != configMapPluginName {
+		t.Errorf("Wrong name: %s", plugin.Name())
+	}
+	if !plugin.CanSupport(&volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{ConfigMap: &api.ConfigMapVolumeSource{}}}}) {
+		t.Errorf("Expected true")
+	}
+	if plugin.CanSupport(&volume.Spec{}) {
+		t.Errorf("Expected false")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_configmap_namespace"
+		testName       = "test_configmap_name"
+
+		configMap = testConfigMap(testNamespace, testName)
+		client    = testclient.NewSimpleFake(&configMap)
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+	)
+	volumeSpec := volumeSpec(testVolumeName, testName)
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(configMapPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+
+	volumePath := builder.GetPath()
+	if !strings.HasSuffix(volumePath, fmt.Sprintf("pods/test_pod_uid/volumes/kubernetes.io~configmap/test_volume_name")) {
+		t.Errorf("Got unexpected path: %s", volumePath)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	doTestFilesInVolume(volumePath, map[string]expectedFile{
+		"key-1": {"value-1", 0644},
+		"key-2": {"value-2", 0644},
+		"key-3": {"value-3", 0644},
+	}, t)
+	doTestCleanAndTeardown(plugin, testPodUID, testVolumeName, volumePath, t)
+}
+
+func TestPluginItemsAndModes(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid2")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_configmap_namespace"
+		testName       = "test_configmap_name"
+
+		configMap   = testConfigMap(testNamespace, testName)
+		client      = testclient.NewSimpleFake(&configMap)
+		pluginMgr   = volume.VolumePluginMgr{}
+		_, host     = newTestHost(t, client)
+		defaultMode = int32(0440)
+		mode        = int32(0400)
+	)
+	volumeSpec := volumeSpec(testVolumeName, testName)
+	volumeSpec.ConfigMap.DefaultMode = &defaultMode
+	volumeSpec.ConfigMap.Items = []api.KeyToPath{
+		{Key: "key-1", Path: "one"},
+		{Key: "key-2", Path: "dir/two", Mode: &mode},
+	}
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(configMapPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	doTestFilesInVolume(builder.GetPath(), map[string]expectedFile{
+		"one":     {"value-1", 0440},
+		"dir/two": {"value-2", 0400},
+		"key-3":   {},
+	}, t)
+}
+
+func TestPluginMissing(t *testing.T) {
+	optional := true
+	tests := []struct {
+		name      string
+		optional  *bool
+		items     []api.KeyToPath
+		configMap bool
+		expectErr bool
+	}{
+		{name: "missing configMap", expectErr: true},
+		{name: "optional missing configMap", optional: &optional},
+		{name: "missing key", items: []api.KeyToPath{{Key: "key-4", Path: "four"}}, configMap: true, expectErr: true},
+		{name: "optional missing key", optional: &optional, items: []api.KeyToPath{{Key: "key-4", Path: "four"}}, configMap: true},
+	}
+	for i, test := range tests {
+		var (
+			testPodUID     = types.UID(fmt.Sprintf("test_pod_uid_missing%d", i))
+			testVolumeName = "test_volume_name"
+			testNamespace  = "test_configmap_namespace"
+			testName       = "test_configmap_name"
+
+			client    = testclient.NewSimpleFake()
+			pluginMgr = volume.VolumePluginMgr{}
+		)
+		if test.configMap {
+			configMap := testConfigMap(testNamespace, testName)
+			client = testclient.NewSimpleFake(&configMap)
+		}
+		_, host := newTestHost(t, client)
+		volumeSpec := volumeSpec(testVolumeName, testName)
+		volumeSpec.ConfigMap.Optional = test.optional
+		volumeSpec.ConfigMap.Items = test.items
+
+		pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+		plugin, err := pluginMgr.FindPluginByName(configMapPluginName)
+		if err != nil {
+			t.Fatalf("Can't find the plugin by name")
+		}
+		pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Namespace: testNamespace}}
+		builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+		if err != nil {
+			t.Fatalf("%s: Failed to make a new Builder: %v", test.name, err)
+		}
+		err = builder.SetUp()
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Failed to setup volume: %v", test.name, err)
+			continue
+		}
+		files, err := ioutil.ReadDir(builder.GetPath())
+		if err != nil {
+			t.Errorf("%s: Couldn't read volume: %v", test.name, err)
+			continue
+		}
+		for _, file := range files {
+			// The atomic writer keeps its data in hidden entries.
+			if !strings.HasPrefix(file.Name(), ".") {
+				t.Errorf("%s: expected an empty volume, found %v", test.name, file.Name())
+			}
+		}
+	}
+}
+
+func volumeSpec(volumeName, configMapName string) *api.Volume {
+	return &api.Volume{
+		Name: volumeName,
+		VolumeSource: api.VolumeSource{
+			ConfigMap: &api.ConfigMapVolumeSource{
+				LocalObjectReference: api.LocalObjectReference{Name: configMapName},
+			},
+		},
+	}
+}
+
+func testConfigMap(namespace, name string) api.ConfigMap {
+	return api.ConfigMap{
+		ObjectMeta: api.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: map[string]string{
+			"key-1": "value-1",
+			"key-2": "value-2",
+			"key-3": "value-3",
+		},
+	}
+}
+
+// expectedFile is the content and mode of a file expected in a volume; the
+// zero value expects the file not to exist.
+type expectedFile struct {
+	value string
+	mode  os.FileMode
+}
+
+func doTestFilesInVolume(volumePath string, files map[string]expectedFile, t *testing.T) {
+	for file, expected := range files {
+		filePath := path.Join(volumePath, file)
+		if expected.mode == 0 {
+			if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+				t.Errorf("Expected %v not to exist, got %v", filePath, err)
+			}
+			continue
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			t.Errorf("SetUp() failed, couldn't find %v: %v", filePath, err)
+			continue
+		}
+		if info.Mode().Perm() != expected.mode {
+			t.Errorf("Expected mode %v for %v, got %v", expected.mode, filePath, info.Mode().Perm())
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Errorf("Couldn't read %v: %v", filePath, err)
+			continue
+		}
+		if string(data) != expected.value {
+			t.Errorf("Unexpected value of %v; expected %q, got %q", filePath, expected.value, string(data))
+		}
+	}
+}
+
+func doTestCleanAndTeardown(plugin volume.VolumePlugin, podUID types.UID, testVolumeName, volumePath string, t *testing.T) {
+	cleaner, err := plugin.NewCleaner(testVolumeName, podUID)
+	if err != nil {
+		t.Errorf("Failed to make a new Cleaner: %v", err)
+	}
+	if cleaner == nil {
+		t.Errorf("Got a nil Cleaner")
+	}
+
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if _, err := os.Stat(volumePath); err == nil {
+		t.Errorf("TearDown() failed, volume path still exists: %s", volumePath)
+	} else if !os.IsNotExist(err) {
+		t.Errorf("SetUp() failed: %v", err)
+	}
+}
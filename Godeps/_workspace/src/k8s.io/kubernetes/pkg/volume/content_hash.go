@@ -0,0 +1,120 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// ContentHash computes a hash of the tree rooted at path (typically a
+// volume's GetPath()) covering the relative path, permission bits and
+// contents of every entry.  Entries are visited in lexical order so identical
+// trees always hash identically.  Symlinks contribute their target rather
+// than the content they point to.  The walk stops with ctx.Err() as soon as
+// ctx is cancelled.
+func ContentHash(ctx context.Context, path string) (string, error) {
+	h := sha256.New()
+	if err := hashTree(ctx, h, path, ""); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTree hashes the entry root/rel and, if it is a directory, its children.
+func hashTree(ctx context.Context, h hash.Hash, root, rel string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	full := filepath.Join(root, rel)
+	info, err := os.Lstat(full)
+	if err != nil {
+		return err
+	}
+	// Each field is length-prefixed so that different trees can not produce
+	// the same byte stream.
+	writeHashField(h, []byte(filepath.ToSlash(rel)))
+	writeHashField(h, []byte(fmt.Sprintf("%o", uint32(info.Mode()))))
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(full)
+		if err != nil {
+			return err
+		}
+		writeHashField(h, []byte(target))
+	case info.IsDir():
+		f, err := os.Open(full)
+		if err != nil {
+			return err
+		}
+		names, err := f.Readdirnames(-1)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := hashTree(ctx, h, root, filepath.Join(rel, name)); err != nil {
+				return err
+			}
+		}
+	case info.Mode().IsRegular():
+		f, err := os.Open(full)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%d:", info.Size())
+		if _, err := io.Copy(h, &contextReader{ctx: ctx, r: f}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHashField(h hash.Hash, data []byte) {
+	fmt.Fprintf(h, "%d:", len(data))
+	h.Write(data)
+}
+
+// contextReader fails reads once its context is done, so that hashing a
+// large file can be cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	select {
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	default:
+	}
+	return c.r.Read(p)
+}
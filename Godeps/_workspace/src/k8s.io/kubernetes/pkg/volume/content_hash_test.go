@@ -0,0 +1,132 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// makeTree creates the same small tree under a new temp dir each time it is
+// called, creating files in the given order.
+func makeTree(t *testing.T, order []string) string {
+	root, err := ioutil.TempDir("", "content_hash_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	files := map[string]string{
+		"a.txt":       "alpha",
+		"sub/b.txt":   "bravo",
+		"sub/c/d.txt": "delta",
+	}
+	for _, name := range order {
+		p := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(p), 0750); err != nil {
+			t.Fatalf("can't mkdir: %v", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(files[name]), 0640); err != nil {
+			t.Fatalf("can't write file: %v", err)
+		}
+	}
+	if err := os.Symlink("sub/b.txt", path.Join(root, "link")); err != nil {
+		t.Fatalf("can't symlink: %v", err)
+	}
+	return root
+}
+
+func mustHash(t *testing.T, root string) string {
+	h, err := ContentHash(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Unexpected error hashing %s: %v", root, err)
+	}
+	return h
+}
+
+func TestContentHashStable(t *testing.T) {
+	root1 := makeTree(t, []string{"a.txt", "sub/b.txt", "sub/c/d.txt"})
+	defer os.RemoveAll(root1)
+	root2 := makeTree(t, []string{"sub/c/d.txt", "sub/b.txt", "a.txt"})
+	defer os.RemoveAll(root2)
+
+	h1 := mustHash(t, root1)
+	if h2 := mustHash(t, root2); h1 != h2 {
+		t.Errorf("Expected identical trees to hash identically, got %s and %s", h1, h2)
+	}
+	if again := mustHash(t, root1); again != h1 {
+		t.Errorf("Expected repeated hashing to be stable, got %s and %s", h1, again)
+	}
+}
+
+func TestContentHashChanges(t *testing.T) {
+	root := makeTree(t, []string{"a.txt", "sub/b.txt", "sub/c/d.txt"})
+	defer os.RemoveAll(root)
+	orig := mustHash(t, root)
+
+	// A single changed byte.
+	if err := ioutil.WriteFile(path.Join(root, "sub/c/d.txt"), []byte("deltb"), 0640); err != nil {
+		t.Fatalf("can't write file: %v", err)
+	}
+	changed := mustHash(t, root)
+	if changed == orig {
+		t.Errorf("Expected hash to change after modifying a byte")
+	}
+
+	// A mode change.
+	if err := os.Chmod(path.Join(root, "a.txt"), 0600); err != nil {
+		t.Fatalf("can't chmod: %v", err)
+	}
+	if h := mustHash(t, root); h == changed {
+		t.Errorf("Expected hash to change after changing a mode")
+	}
+}
+
+func TestContentHashSymlinkTarget(t *testing.T) {
+	root := makeTree(t, []string{"a.txt", "sub/b.txt", "sub/c/d.txt"})
+	defer os.RemoveAll(root)
+	orig := mustHash(t, root)
+
+	// The link is hashed by its target, so retargeting it changes the hash.
+	os.Remove(path.Join(root, "link"))
+	if err := os.Symlink("a.txt", path.Join(root, "link")); err != nil {
+		t.Fatalf("can't symlink: %v", err)
+	}
+	if h := mustHash(t, root); h == orig {
+		t.Errorf("Expected hash to change after retargeting a symlink")
+	}
+
+	// A dangling symlink is hashed by its target too.
+	os.Remove(path.Join(root, "link"))
+	if err := os.Symlink("does-not-exist", path.Join(root, "link")); err != nil {
+		t.Fatalf("can't symlink: %v", err)
+	}
+	mustHash(t, root)
+}
+
+func TestContentHashCancelled(t *testing.T) {
+	root := makeTree(t, []string{"a.txt", "sub/b.txt", "sub/c/d.txt"})
+	defer os.RemoveAll(root)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ContentHash(ctx, root); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
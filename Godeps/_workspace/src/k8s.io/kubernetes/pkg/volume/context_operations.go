@@ -0,0 +1,85 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"golang.org/x/net/context"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// ContextProvisioner is implemented by Provisioners which can abort a
+// provision when ctx is done, e.g. by cancelling the cloud provider request.
+type ContextProvisioner interface {
+	ProvisionWithContext(ctx context.Context, pv *api.PersistentVolume) error
+}
+
+// ContextDeleter is implemented by Deleters which can abort a delete when
+// ctx is done.
+type ContextDeleter interface {
+	DeleteWithContext(ctx context.Context) error
+}
+
+// ContextRecycler is implemented by Recyclers which can abort a recycle when
+// ctx is done.
+type ContextRecycler interface {
+	RecycleWithContext(ctx context.Context) error
+}
+
+// ProvisionWithContext provisions pv, returning ctx.Err() as soon as ctx is
+// done.  Provisioners implementing ContextProvisioner are asked to abort,
+// while for others the call to Provision is left to finish in the
+// background and its result is discarded.
+func ProvisionWithContext(ctx context.Context, provisioner Provisioner, pv *api.PersistentVolume) error {
+	if p, ok := provisioner.(ContextProvisioner); ok {
+		return p.ProvisionWithContext(ctx, pv)
+	}
+	return runWithContext(ctx, func() error { return provisioner.Provision(pv) })
+}
+
+// DeleteWithContext is like ProvisionWithContext, for Deleters.
+func DeleteWithContext(ctx context.Context, deleter Deleter) error {
+	if d, ok := deleter.(ContextDeleter); ok {
+		return d.DeleteWithContext(ctx)
+	}
+	return runWithContext(ctx, deleter.Delete)
+}
+
+// RecycleWithContext is like ProvisionWithContext, for Recyclers.
+func RecycleWithContext(ctx context.Context, recycler Recycler) error {
+	if r, ok := recycler.(ContextRecycler); ok {
+		return r.RecycleWithContext(ctx)
+	}
+	return runWithContext(ctx, recycler.Recycle)
+}
+
+// runWithContext runs fn and returns its error, or ctx.Err() if ctx is done
+// first.
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// blockingProvisioner is a Provisioner without context support whose
+// Provision blocks until release is closed.
+type blockingProvisioner struct {
+	FakeProvisioner
+	release chan struct{}
+}
+
+func (p *blockingProvisioner) Provision(pv *api.PersistentVolume) error {
+	<-p.release
+	return nil
+}
+
+// cancellableDeleter is a Deleter which aborts when its context is done.
+type cancellableDeleter struct {
+	FakeDeleter
+	aborted chan struct{}
+}
+
+func (d *cancellableDeleter) DeleteWithContext(ctx context.Context) error {
+	<-ctx.Done()
+	close(d.aborted)
+	return fmt.Errorf("delete aborted: %v", ctx.Err())
+}
+
+func TestProvisionWithContextTimeout(t *testing.T) {
+	provisioner := &blockingProvisioner{release: make(chan struct{})}
+	defer close(provisioner.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := ProvisionWithContext(ctx, provisioner, &api.PersistentVolume{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected ProvisionWithContext to return at the deadline, took %s", elapsed)
+	}
+}
+
+func TestProvisionWithContextCompletes(t *testing.T) {
+	provisioner := &blockingProvisioner{release: make(chan struct{})}
+	close(provisioner.release)
+	if err := ProvisionWithContext(context.Background(), provisioner, &api.PersistentVolume{}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := RecycleWithContext(ctx, &fakeRecycler{}); err != context.Canceled {
+		t.Errorf("Expected an already cancelled context to stop the recycle, got %v", err)
+	}
+}
+
+func TestDeleteWithContextUsesContextDeleter(t *testing.T) {
+	deleter := &cancellableDeleter{aborted: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancel()
+	if err := DeleteWithContext(ctx, deleter); err == nil {
+		t.Errorf("Expected the aborted delete's error")
+	}
+	select {
+	case <-deleter.aborted:
+	default:
+		t.Errorf("Expected the deleter to be asked to abort")
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/volume/csi/csipb"
+)
+
+const (
+	// socketName is the name of the socket in the directory of a driver.
+	socketName = "csi.sock"
+	// dialTimeout is how long dialing a driver may take.
+	dialTimeout = 10 * time.Second
+	// callTimeout is how long a call to a driver may take.
+	callTimeout = 2 * time.Minute
+)
+
+// socketPath returns the path of the socket of driver in socketDir.
+func socketPath(socketDir, driver string) string {
+	return path.Join(socketDir, util.EscapeQualifiedNameForDisk(driver), socketName)
+}
+
+// connections keeps a connection to every driver that was called.
+type connections struct {
+	socketDir string
+
+	lock  sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func newConnections(socketDir string) *connections {
+	return &connections{
+		socketDir: socketDir,
+		conns:     map[string]*grpc.ClientConn{},
+	}
+}
+
+// call calls fn with a connection to driver.  Calls to drivers are
+// idempotent, so a call which failed because the connection was lost, e.g.
+// because the driver restarted, is made again on a new connection.  Every
+// attempt gets callTimeout.
+func (c *connections) call(driver string, fn func(ctx context.Context, conn *grpc.ClientConn) error) error {
+	conn, err := c.get(driver)
+	if err != nil {
+		return err
+	}
+	err = callWithTimeout(conn, fn)
+	if err == nil || conn.State() == grpc.Ready {
+		return err
+	}
+
+	glog.V(2).Infof("Lost connection to csi driver %s, reconnecting: %v", driver, err)
+	c.drop(driver, conn)
+	if conn, err = c.get(driver); err != nil {
+		return err
+	}
+	return callWithTimeout(conn, fn)
+}
+
+// callWithTimeout calls fn with conn and a context which expires after
+// callTimeout.
+func callWithTimeout(conn *grpc.ClientConn, fn func(ctx context.Context, conn *grpc.ClientConn) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	return fn(ctx, conn)
+}
+
+// get returns the connection to driver, which is dialed if there is none
+// or the one there is failed.  Dialing a driver can take up to dialTimeout,
+// so it is done without holding the lock, not to hold up the calls to other
+// drivers.
+func (c *connections) get(driver string) (*grpc.ClientConn, error) {
+	if conn, ok := c.cached(driver); ok {
+		return conn, nil
+	}
+
+	conn, err := dial(c.socketDir, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if existing, ok := c.conns[driver]; ok && usable(existing) {
+		// Another call dialed the driver meanwhile.
+		conn.Close()
+		return existing, nil
+	}
+	c.conns[driver] = conn
+	return conn, nil
+}
+
+// cached returns the connection to driver, if there is one which did not
+// fail.  A failed connection is closed and forgotten.
+func (c *connections) cached(driver string) (*grpc.ClientConn, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	conn, ok := c.conns[driver]
+	if !ok {
+		return nil, false
+	}
+	if !usable(conn) {
+		// The driver went away.  Dial it again rather than waiting
+		// for grpc to back off, it may be listening on a new socket.
+		glog.V(4).Infof("Connection to csi driver %s failed, dialing it again", driver)
+		conn.Close()
+		delete(c.conns, driver)
+		return nil, false
+	}
+	return conn, true
+}
+
+// usable returns whether calls can be made on conn.
+func usable(conn *grpc.ClientConn) bool {
+	switch conn.State() {
+	case grpc.Shutdown, grpc.TransientFailure:
+		return false
+	}
+	return true
+}
+
+// drop closes conn, the failed connection to driver, unless it has been
+// replaced already.
+func (c *connections) drop(driver string, conn *grpc.ClientConn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.conns[driver] == conn {
+		delete(c.conns, driver)
+	}
+	conn.Close()
+}
+
+// dial connects to the socket of driver in socketDir and checks that it is
+// served by driver.
+func dial(socketDir, driver string) (*grpc.ClientConn, error) {
+	socket := socketPath(socketDir, driver)
+	if _, err := os.Stat(socket); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("csi driver %s is not installed, there is no socket at %s", driver, socket)
+		}
+		return nil, err
+	}
+
+	glog.V(4).Infof("Dialing csi driver %s at %s", driver, socket)
+	conn, err := grpc.Dial(socket,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial csi driver %s at %s: %v", driver, socket, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	info, err := csipb.NewIdentityClient(conn).GetPluginInfo(ctx, &csipb.GetPluginInfoRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to get plugin info of csi driver %s: %v", driver, err)
+	}
+	if info.Name != driver {
+		conn.Close()
+		return nil, fmt.Errorf("socket %s is served by csi driver %q, not %q", socket, info.Name, driver)
+	}
+	return conn, nil
+}
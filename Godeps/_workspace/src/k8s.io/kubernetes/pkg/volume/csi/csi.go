@@ -0,0 +1,416 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/golang/glog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/csi/csipb"
+)
+
+// ProbeVolumePlugins is the entry point for plugin detection in a package.
+// The volumeConfig arg configures where the sockets of drivers are and the
+// driver new volumes are provisioned by, see the CSI* keys of its
+// OtherAttributes.
+func ProbeVolumePlugins(volumeConfig volume.VolumeConfig) []volume.VolumePlugin {
+	return []volume.VolumePlugin{&csiPlugin{config: volumeConfig}}
+}
+
+const (
+	csiPluginName = "kubernetes.io/csi"
+	// volDataFile is the file the driver and handle of a published volume
+	// are saved to.
+	volDataFile = "vol_data.json"
+)
+
+// Keys of VolumeConfig.OtherAttributes that configure the plugin.
+const (
+	// CSISocketDir is the directory the sockets of drivers are in, default
+	// is the plugin directory of the host.
+	CSISocketDir = "csi.socketDir"
+	// CSIProvisionerDriver is the driver new volumes are created by.  No
+	// volumes are provisioned if it is not set.
+	CSIProvisionerDriver = "csi.provisionerDriver"
+)
+
+type csiPlugin struct {
+	host   volume.VolumeHost
+	config volume.VolumeConfig
+	conns  *connections
+}
+
+var _ volume.VolumePlugin = &csiPlugin{}
+var _ volume.PersistentVolumePlugin = &csiPlugin{}
+var _ volume.DeletableVolumePlugin = &csiPlugin{}
+var _ volume.ProvisionableVolumePlugin = &csiPlugin{}
+
+func (plugin *csiPlugin) Init(host volume.VolumeHost) {
+	plugin.host = host
+	socketDir := plugin.config.OtherAttributes[CSISocketDir]
+	if socketDir == "" {
+		socketDir = volume.NewDirectoryLayout(host).PluginDataDir(csiPluginName)
+	}
+	plugin.conns = newConnections(socketDir)
+}
+
+func (plugin *csiPlugin) Name() string {
+	return csiPluginName
+}
+
+func (plugin *csiPlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.PersistentVolume != nil && spec.PersistentVolume.Spec.CSI != nil
+}
+
+func (plugin *csiPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.PersistentVolume == nil || spec.PersistentVolume.Spec.CSI == nil {
+		return "", fmt.Errorf("spec does not reference a csi volume")
+	}
+	// Handles are only unique among the volumes of a driver.
+	source := spec.PersistentVolume.Spec.CSI
+	return fmt.Sprintf("%s^%s", source.Driver, source.VolumeHandle), nil
+}
+
+func (plugin *csiPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
+	return []api.PersistentVolumeAccessMode{
+		api.ReadWriteOnce,
+		api.ReadOnlyMany,
+	}
+}
+
+func (plugin *csiPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
+	return plugin.newBuilderInternal(spec, pod.UID, plugin.host.GetMounter())
+}
+
+func (plugin *csiPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, mounter mount.Interface) (volume.Builder, error) {
+	if !plugin.CanSupport(spec) {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.CSI is nil")
+	}
+	source := spec.PersistentVolume.Spec.CSI
+	return &csiBuilder{
+		csiVolume: &csiVolume{
+			volName: spec.Name(),
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+		source:   source,
+		readOnly: spec.IsReadOnly() || source.ReadOnly,
+	}, nil
+}
+
+func (plugin *csiPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
+	return plugin.newCleanerInternal(volName, podUID, plugin.host.GetMounter())
+}
+
+func (plugin *csiPlugin) newCleanerInternal(volName string, podUID types.UID, mounter mount.Interface) (volume.Cleaner, error) {
+	return &csiCleaner{
+		csiVolume: &csiVolume{
+			volName: volName,
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+	}, nil
+}
+
+func (plugin *csiPlugin) NewDeleter(spec *volume.Spec) (volume.Deleter, error) {
+	if !plugin.CanSupport(spec) {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.CSI is nil")
+	}
+	return &csiDeleter{
+		csiVolume: &csiVolume{
+			volName: spec.Name(),
+			plugin:  plugin,
+		},
+		source: spec.PersistentVolume.Spec.CSI,
+	}, nil
+}
+
+func (plugin *csiPlugin) SupportsCloning() bool {
+	return false
+}
+
+func (plugin *csiPlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
+	driver := plugin.config.OtherAttributes[CSIProvisionerDriver]
+	if driver == "" {
+		return nil, fmt.Errorf("csi: %s must be configured to provision volumes", CSIProvisionerDriver)
+	}
+	if len(options.AccessModes) == 0 {
+		options.AccessModes = plugin.GetAccessModes()
+	}
+	return &csiProvisioner{
+		csiVolume: &csiVolume{plugin: plugin},
+		driver:    driver,
+		options:   options,
+	}, nil
+}
+
+// csiVolume is a volume published by a driver.
+type csiVolume struct {
+	volName string
+	podUID  types.UID
+	mounter mount.Interface
+	plugin  *csiPlugin
+}
+
+var _ volume.Volume = &csiVolume{}
+
+func (v *csiVolume) GetPath() string {
+	return volume.NewDirectoryLayout(v.plugin.host).PodVolumeDir(v.podUID, csiPluginName, v.volName)
+}
+
+// volData is what is saved about a published volume, so that it can be
+// unpublished.
+type volData struct {
+	Driver       string `json:"driver"`
+	VolumeHandle string `json:"volumeHandle"`
+}
+
+func (v *csiVolume) getVolDataPath() string {
+	return path.Join(volume.NewDirectoryLayout(v.plugin.host).PodPluginDir(v.podUID, csiPluginName), v.volName, volDataFile)
+}
+
+func (v *csiVolume) saveVolData(data *volData) error {
+	file := v.getVolDataPath()
+	if err := os.MkdirAll(path.Dir(file), 0750); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, bytes, 0640)
+}
+
+func (v *csiVolume) loadVolData() (*volData, error) {
+	bytes, err := ioutil.ReadFile(v.getVolDataPath())
+	if err != nil {
+		return nil, err
+	}
+	data := &volData{}
+	if err := json.Unmarshal(bytes, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", v.getVolDataPath(), err)
+	}
+	return data, nil
+}
+
+type csiBuilder struct {
+	*csiVolume
+	source   *api.CSIPersistentVolumeSource
+	readOnly bool
+}
+
+var _ volume.Builder = &csiBuilder{}
+
+// SetUp publishes the volume at the volume path.
+func (b *csiBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// SetUpAt publishes the volume at dir.
+func (b *csiBuilder) SetUpAt(dir string) error {
+	notMnt, err := b.mounter.IsLikelyNotMountPoint(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !notMnt {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	// Save the volume before publishing it, so that a volume which the
+	// driver published partially is unpublished by TearDown.
+	if err := b.saveVolData(&volData{Driver: b.source.Driver, VolumeHandle: b.source.VolumeHandle}); err != nil {
+		volume.CleanupStagingDir(dir, err)
+		return err
+	}
+	glog.V(4).Infof("Publishing csi volume %s of driver %s at %s", b.source.VolumeHandle, b.source.Driver, dir)
+	err = b.plugin.conns.call(b.source.Driver, func(ctx context.Context, conn *grpc.ClientConn) error {
+		_, err := csipb.NewNodeClient(conn).NodePublishVolume(ctx, &csipb.NodePublishVolumeRequest{
+			VolumeId:         b.source.VolumeHandle,
+			TargetPath:       dir,
+			Readonly:         b.readOnly,
+			FsType:           b.source.FSType,
+			VolumeAttributes: b.source.VolumeAttributes,
+		})
+		return err
+	})
+	if err != nil {
+		// A volume the driver published partially is left to TearDown, which
+		// unpublishes it; only a dir the driver didn't mount is cleaned up.
+		if notMnt, mntErr := b.mounter.IsLikelyNotMountPoint(dir); mntErr == nil && notMnt {
+			volume.CleanupStagingDir(dir, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (b *csiBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
+}
+
+type csiCleaner struct {
+	*csiVolume
+}
+
+var _ volume.Cleaner = &csiCleaner{}
+
+// TearDown unpublishes the volume from the volume path.
+func (c *csiCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+// TearDownAt unpublishes the volume from dir.
+func (c *csiCleaner) TearDownAt(dir string) error {
+	data, err := c.loadVolData()
+	if os.IsNotExist(err) {
+		// The volume was never published.
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	glog.V(4).Infof("Unpublishing csi volume %s of driver %s from %s", data.VolumeHandle, data.Driver, dir)
+	err = c.plugin.conns.call(data.Driver, func(ctx context.Context, conn *grpc.ClientConn) error {
+		_, err := csipb.NewNodeClient(conn).NodeUnpublishVolume(ctx, &csipb.NodeUnpublishVolumeRequest{
+			VolumeId:   data.VolumeHandle,
+			TargetPath: dir,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(path.Dir(c.getVolDataPath()))
+}
+
+type csiDeleter struct {
+	*csiVolume
+	source *api.CSIPersistentVolumeSource
+}
+
+var _ volume.Deleter = &csiDeleter{}
+
+func (d *csiDeleter) Delete() error {
+	return d.plugin.conns.call(d.source.Driver, func(ctx context.Context, conn *grpc.ClientConn) error {
+		_, err := csipb.NewControllerClient(conn).DeleteVolume(ctx, &csipb.DeleteVolumeRequest{
+			VolumeId: d.source.VolumeHandle,
+		})
+		return err
+	})
+}
+
+type csiProvisioner struct {
+	*csiVolume
+	driver  string
+	options volume.VolumeOptions
+}
+
+var _ volume.Provisioner = &csiProvisioner{}
+
+func (p *csiProvisioner) Provision(pv *api.PersistentVolume) error {
+	if p.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: csiPluginName, SnapshotID: p.options.SnapshotSource}
+	}
+	if p.options.CloneSource != nil {
+		return &volume.ErrCloneSourceNotSupported{Plugin: csiPluginName, Source: p.options.CloneSource.Name}
+	}
+	var created *csipb.Volume
+	// The name makes CreateVolume idempotent, retrying it doesn't create a
+	// second volume.
+	name := "kubernetes-dynamic-pv-" + string(util.NewUUID())
+	err := p.plugin.conns.call(p.driver, func(ctx context.Context, conn *grpc.ClientConn) error {
+		resp, err := csipb.NewControllerClient(conn).CreateVolume(ctx, &csipb.CreateVolumeRequest{
+			Name:          name,
+			CapacityBytes: p.options.Capacity.Value(),
+			Parameters:    p.options.Parameters,
+		})
+		if err != nil {
+			return err
+		}
+		created = resp.GetVolume()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if created == nil || created.Id == "" {
+		return fmt.Errorf("csi driver %s created volume %s without an id", p.driver, name)
+	}
+	pv.Spec.PersistentVolumeSource.CSI.VolumeHandle = created.Id
+	pv.Spec.PersistentVolumeSource.CSI.VolumeAttributes = created.Attributes
+	if created.CapacityBytes > 0 {
+		pv.Spec.Capacity = api.ResourceList{
+			api.ResourceName(api.ResourceStorage): *resource.NewQuantity(created.CapacityBytes, resource.BinarySI),
+		}
+	}
+	return nil
+}
+
+func (p *csiProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
+	// Provide dummy api.PersistentVolume.Spec, the volume handle will be
+	// filled in csiProvisioner.Provision()
+	return &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			GenerateName: "pv-csi-",
+			Labels:       map[string]string{},
+			Annotations: map[string]string{
+				"kubernetes.io/createdby": "csi-dynamic-provisioner",
+			},
+		},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: p.options.PersistentVolumeReclaimPolicy,
+			AccessModes:                   p.options.AccessModes,
+			Capacity: api.ResourceList{
+				api.ResourceName(api.ResourceStorage): p.options.Capacity,
+			},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				CSI: &api.CSIPersistentVolumeSource{
+					Driver:       p.driver,
+					VolumeHandle: "dummy",
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/csi/csipb"
+)
+
+const testDriverName = "example.com/csi"
+
+// fakeDriver serves the csi protocol on a socket and logs the calls made
+// to it.
+type fakeDriver struct {
+	name   string
+	server *grpc.Server
+
+	lock      sync.Mutex
+	published map[string]*csipb.NodePublishVolumeRequest
+	created   []*csipb.CreateVolumeRequest
+	deleted   []string
+}
+
+// startFakeDriver serves a driver called name on the socket of driver in
+// socketDir.
+func startFakeDriver(t *testing.T, socketDir, driver, name string) *fakeDriver {
+	socket := socketPath(socketDir, driver)
+	if err := os.MkdirAll(path.Dir(socket), 0750); err != nil {
+		t.Fatalf("can't make the socket dir: %v", err)
+	}
+	os.Remove(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("can't listen on %s: %v", socket, err)
+	}
+	d := &fakeDriver{
+		name:      name,
+		server:    grpc.NewServer(),
+		published: map[string]*csipb.NodePublishVolumeRequest{},
+	}
+	csipb.RegisterIdentityServer(d.server, d)
+	csipb.RegisterNodeServer(d.server, d)
+	csipb.RegisterControllerServer(d.server, d)
+	go d.server.Serve(listener)
+	return d
+}
+
+func (d *fakeDriver) GetPluginInfo(ctx context.Context, in *csipb.GetPluginInfoRequest) (*csipb.GetPluginInfoResponse, error) {
+	return &csipb.GetPluginInfoResponse{Name: d.name, VendorVersion: "0.1"}, nil
+}
+
+func (d *fakeDriver) NodePublishVolume(ctx context.Context, in *csipb.NodePublishVolumeRequest) (*csipb.NodePublishVolumeResponse, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.published[in.TargetPath] = in
+	return &csipb.NodePublishVolumeResponse{}, nil
+}
+
+func (d *fakeDriver) NodeUnpublishVolume(ctx context.Context, in *csipb.NodeUnpublishVolumeRequest) (*csipb.NodeUnpublishVolumeResponse, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.published, in.TargetPath)
+	return &csipb.NodeUnpublishVolumeResponse{}, nil
+}
+
+func (d *fakeDriver) CreateVolume(ctx context.Context, in *csipb.CreateVolumeRequest) (*csipb.CreateVolumeResponse, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.created = append(d.created, in)
+	return &csipb.CreateVolumeResponse{
+		Volume: &csipb.Volume{
+			Id:            "vol-" + in.Name,
+			CapacityBytes: 2 * in.CapacityBytes,
+			Attributes:    map[string]string{"pool": in.Parameters["pool"]},
+		},
+	}, nil
+}
+
+func (d *fakeDriver) DeleteVolume(ctx context.Context, in *csipb.DeleteVolumeRequest) (*csipb.DeleteVolumeResponse, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.deleted = append(d.deleted, in.VolumeId)
+	return &csipb.DeleteVolumeResponse{}, nil
+}
+
+func (d *fakeDriver) getPublished(targetPath string) *csipb.NodePublishVolumeRequest {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.published[targetPath]
+}
+
+// newTestPlugin returns the plugin with the sockets of drivers in
+// socketDir, and the temp dir its host is rooted at.
+func newTestPlugin(t *testing.T, attrs map[string]string) (string, *csiPlugin) {
+	tempDir, err := ioutil.TempDir("/tmp", "csi_test.")
+	if err != nil {
+		t.Fatalf("can't make a temp rootdir: %v", err)
+	}
+	config := volume.VolumeConfig{OtherAttributes: map[string]string{CSISocketDir: path.Join(tempDir, "sockets")}}
+	for key, value := range attrs {
+		config.OtherAttributes[key] = value
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(config), volume.NewFakeVolumeHost(tempDir, nil, nil))
+	plug, err := plugMgr.FindPluginByName(csiPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	return tempDir, plug.(*csiPlugin)
+}
+
+func newTestSpec(readOnly bool) *volume.Spec {
+	return volume.NewSpecFromPersistentVolume(&api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv1"},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				CSI: &api.CSIPersistentVolumeSource{
+					Driver:           testDriverName,
+					VolumeHandle:     "vol1",
+					FSType:           "ext4",
+					VolumeAttributes: map[string]string{"zone": "a"},
+				},
+			},
+		},
+	}, readOnly)
+}
+
+func TestCanSupport(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+
+	if plug.Name() != csiPluginName {
+		t.Errorf("Wrong name: %s", plug.Name())
+	}
+	if !plug.CanSupport(newTestSpec(false)) {
+		t.Errorf("Expected true for a csi persistent volume")
+	}
+	if plug.CanSupport(&volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{}}}) {
+		t.Errorf("Expected false for a volume")
+	}
+	if plug.CanSupport(&volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{}}}}) {
+		t.Errorf("Expected false for a persistent volume without csi source")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+	driver := startFakeDriver(t, path.Join(tmpDir, "sockets"), testDriverName, testDriverName)
+	defer driver.server.Stop()
+
+	builder, err := plug.newBuilderInternal(newTestSpec(true), types.UID("poduid"), &mount.FakeMounter{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	volPath := path.Join(tmpDir, "pods/poduid/volumes/kubernetes.io~csi/pv1")
+	if path := builder.GetPath(); path != volPath {
+		t.Errorf("Got unexpected path: %s", path)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	published := driver.getPublished(volPath)
+	if published == nil {
+		t.Fatalf("Expected the volume to be published at %s", volPath)
+	}
+	if published.VolumeId != "vol1" || !published.Readonly || published.FsType != "ext4" || published.VolumeAttributes["zone"] != "a" {
+		t.Errorf("Unexpected publish request: %v", published)
+	}
+	if _, err := os.Stat(volPath); err != nil {
+		t.Errorf("SetUp() failed to make the volume path: %v", err)
+	}
+
+	cleaner, err := plug.newCleanerInternal("pv1", types.UID("poduid"), &mount.FakeMounter{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Cleaner: %v", err)
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	if driver.getPublished(volPath) != nil {
+		t.Errorf("Expected the volume to be unpublished")
+	}
+	if _, err := os.Stat(volPath); !os.IsNotExist(err) {
+		t.Errorf("TearDown() failed to remove the volume path: %v", err)
+	}
+	if _, err := os.Stat(path.Join(tmpDir, "pods/poduid/plugins/kubernetes.io~csi/pv1")); !os.IsNotExist(err) {
+		t.Errorf("TearDown() failed to remove the volume data: %v", err)
+	}
+}
+
+func TestDriverNotInstalled(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+
+	builder, err := plug.newBuilderInternal(newTestSpec(false), types.UID("poduid"), &mount.FakeMounter{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected SetUp() to fail without a driver socket")
+	}
+}
+
+func TestDriverNameMismatch(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+	driver := startFakeDriver(t, path.Join(tmpDir, "sockets"), testDriverName, "example.com/other")
+	defer driver.server.Stop()
+
+	builder, err := plug.newBuilderInternal(newTestSpec(false), types.UID("poduid"), &mount.FakeMounter{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected SetUp() to fail for a socket served by another driver")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+	socketDir := path.Join(tmpDir, "sockets")
+	driver := startFakeDriver(t, socketDir, testDriverName, testDriverName)
+
+	builder, err := plug.newBuilderInternal(newTestSpec(false), types.UID("poduid"), &mount.FakeMounter{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+
+	// Restart the driver, which loses what it published.
+	driver.server.Stop()
+	driver = startFakeDriver(t, socketDir, testDriverName, testDriverName)
+	defer driver.server.Stop()
+
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success after the driver restarted, got: %v", err)
+	}
+	if driver.getPublished(builder.GetPath()) == nil {
+		t.Errorf("Expected the volume to be published by the restarted driver")
+	}
+}
+
+func TestProvisionAndDelete(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, map[string]string{CSIProvisionerDriver: testDriverName})
+	defer os.RemoveAll(tmpDir)
+	driver := startFakeDriver(t, path.Join(tmpDir, "sockets"), testDriverName, testDriverName)
+	defer driver.server.Stop()
+
+	provisioner, err := plug.NewProvisioner(volume.VolumeOptions{
+		Capacity:                      resource.MustParse("1Gi"),
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+		Parameters:                    map[string]string{"pool": "fast"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("Failed to make a new PV template: %v", err)
+	}
+	if err := provisioner.Provision(pv); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+
+	if len(driver.created) != 1 {
+		t.Fatalf("Expected 1 volume to be created, got %d", len(driver.created))
+	}
+	created := driver.created[0]
+	if created.CapacityBytes != 1<<30 || created.Parameters["pool"] != "fast" {
+		t.Errorf("Unexpected create request: %v", created)
+	}
+	source := pv.Spec.CSI
+	if source.Driver != testDriverName || source.VolumeHandle != "vol-"+created.Name || source.VolumeAttributes["pool"] != "fast" {
+		t.Errorf("Unexpected csi source: %+v", source)
+	}
+	capacity := pv.Spec.Capacity[api.ResourceStorage]
+	if capacity.Value() != 2<<30 {
+		t.Errorf("Expected the capacity of the created volume, got %v", capacity.String())
+	}
+	if len(pv.Spec.AccessModes) == 0 {
+		t.Errorf("Expected default access modes")
+	}
+
+	deleter, err := plug.NewDeleter(volume.NewSpecFromPersistentVolume(pv, false))
+	if err != nil {
+		t.Fatalf("Failed to make a new Deleter: %v", err)
+	}
+	if err := deleter.Delete(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	if len(driver.deleted) != 1 || driver.deleted[0] != source.VolumeHandle {
+		t.Errorf("Expected volume %s to be deleted, got %v", source.VolumeHandle, driver.deleted)
+	}
+}
+
+func TestProvisionerNotConfigured(t *testing.T) {
+	tmpDir, plug := newTestPlugin(t, nil)
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := plug.NewProvisioner(volume.VolumeOptions{}); err == nil {
+		t.Errorf("Expected an error without a provisioner driver")
+	}
+}
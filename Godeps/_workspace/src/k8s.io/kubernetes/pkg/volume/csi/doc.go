@@ -0,0 +1,34 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package csi contains the internal representation of persistent volumes
+// set up by external drivers, which run as daemons on the host and serve the
+// gRPC protocol of package csipb on a Unix socket.
+//
+// The socket of the driver "vendor/driver" is "vendor~driver/csi.sock" in
+// the socket directory, the plugin directory of the kubelet unless
+// configured otherwise.  A driver is dialed when the first of its volumes is
+// used, and must report its own name from GetPluginInfo.  Connections are
+// kept and dialed again once the driver went away, so that drivers can be
+// restarted and upgraded without restarting the kubelet.
+//
+// Builders call NodePublishVolume to make the volume available at the
+// volume path of the pod and cleaners call NodeUnpublishVolume.  The
+// driver and volume handle a volume was published with are saved next to
+// the volume, since cleaners are only given its name.  Provisioners call
+// CreateVolume on the driver configured to provision volumes and deleters
+// call DeleteVolume.
+package csi
@@ -0,0 +1,107 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// initialDeleteBackoff is how long DeleterWithProtection waits before
+	// retrying a deletion that failed transiently.
+	initialDeleteBackoff = time.Second
+	// maxDeleteBackoff caps the backoff, which doubles on every retry.
+	maxDeleteBackoff = 16 * time.Second
+	// deleteAttempts is how many times a deletion is tried before its error
+	// is returned.
+	deleteAttempts = 5
+)
+
+// DeleterWithProtection is a Deleter which refuses to delete a volume while
+// a node still has it attached or mounted, so that e.g. a disk a pod still
+// writes to is not deleted because its claim went away first.  Once no node
+// references the volume, a deletion that fails transiently, e.g. because the
+// cloud provider still reports the disk as attached for a while after the
+// detach, is retried with exponential backoff.
+type DeleterWithProtection struct {
+	deleter    Deleter
+	volumeName string
+	nodeStates func() map[string]ActualStateOfWorld
+
+	// Replaced in tests.
+	sleep func(time.Duration)
+}
+
+var _ Deleter = &DeleterWithProtection{}
+
+// NewDeleterWithProtection wraps deleter, the Deleter of the volume with the
+// unique name volumeName, see GetUniqueVolumeName.  nodeStates returns the
+// state cache of every node, keyed by node name, which is consulted before
+// every attempt to delete the volume.
+func NewDeleterWithProtection(deleter Deleter, volumeName string, nodeStates func() map[string]ActualStateOfWorld) *DeleterWithProtection {
+	return &DeleterWithProtection{
+		deleter:    deleter,
+		volumeName: volumeName,
+		nodeStates: nodeStates,
+		sleep:      time.Sleep,
+	}
+}
+
+func (d *DeleterWithProtection) GetPath() string {
+	return d.deleter.GetPath()
+}
+
+// Delete deletes the volume, or returns an error for which
+// IsDeletedVolumeInUse is true while a node references it.  Transient
+// errors of the deletion are retried up to deleteAttempts times, checking
+// the references again before every attempt.
+func (d *DeleterWithProtection) Delete() error {
+	backoff := initialDeleteBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if nodes := d.nodesUsingVolume(); len(nodes) > 0 {
+			return NewDeletedVolumeInUseError(fmt.Sprintf("volume %s is still attached or mounted on nodes %s", d.volumeName, strings.Join(nodes, ", ")))
+		}
+		if err = d.deleter.Delete(); err == nil || !IsTransient(err) || attempt == deleteAttempts {
+			return err
+		}
+		glog.V(4).Infof("Deleting volume %s failed, retrying in %v: %v", d.volumeName, backoff, err)
+		d.sleep(backoff)
+		backoff *= 2
+		if backoff > maxDeleteBackoff {
+			backoff = maxDeleteBackoff
+		}
+	}
+}
+
+// nodesUsingVolume returns the sorted names of the nodes the volume is
+// attached to or mounted on.
+func (d *DeleterWithProtection) nodesUsingVolume() []string {
+	nodes := []string{}
+	for node, state := range d.nodeStates() {
+		if state.VolumeExists(d.volumeName) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
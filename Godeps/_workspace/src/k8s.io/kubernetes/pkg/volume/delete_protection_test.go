@@ -0,0 +1,156 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// scriptedDeleter fails its deletions with errs, one per call, and succeeds
+// once they ran out.
+type scriptedDeleter struct {
+	errs  []error
+	calls int
+}
+
+func (d *scriptedDeleter) GetPath() string {
+	return "/vol"
+}
+
+func (d *scriptedDeleter) Delete() error {
+	d.calls++
+	if len(d.errs) == 0 {
+		return nil
+	}
+	err := d.errs[0]
+	d.errs = d.errs[1:]
+	return err
+}
+
+func newProtectedDeleter(deleter Deleter, states map[string]ActualStateOfWorld) (*DeleterWithProtection, *[]time.Duration) {
+	d := NewDeleterWithProtection(deleter, "kubernetes.io/fake/vol", func() map[string]ActualStateOfWorld { return states })
+	sleeps := &[]time.Duration{}
+	d.sleep = func(backoff time.Duration) { *sleeps = append(*sleeps, backoff) }
+	return d, sleeps
+}
+
+func TestDeleterWithProtectionRefusesVolumesInUse(t *testing.T) {
+	spec := &Spec{Volume: &api.Volume{Name: "vol"}}
+	attached := NewActualStateOfWorld()
+	if err := attached.MarkVolumeAsAttached("kubernetes.io/fake/vol", spec, "/dev/sdb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mounted := NewActualStateOfWorld()
+	if err := mounted.MarkVolumeAsMounted("pod1", "kubernetes.io/fake/vol", spec); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	states := map[string]ActualStateOfWorld{"node1": attached, "node2": mounted, "node3": NewActualStateOfWorld()}
+
+	deleter := &scriptedDeleter{}
+	d, _ := newProtectedDeleter(deleter, states)
+	err := d.Delete()
+	if !IsDeletedVolumeInUse(err) {
+		t.Fatalf("Expected an in use error, got %v", err)
+	}
+	if err.Error() != "volume kubernetes.io/fake/vol is still attached or mounted on nodes node1, node2" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if deleter.calls != 0 {
+		t.Errorf("Expected no deletion, got %d", deleter.calls)
+	}
+
+	// The volume is deleted once the nodes released it.
+	mounted.MarkVolumeAsUnmounted("pod1", "kubernetes.io/fake/vol")
+	if err := attached.MarkVolumeAsDetached("kubernetes.io/fake/vol"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := d.Delete(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if deleter.calls != 1 {
+		t.Errorf("Expected one deletion, got %d", deleter.calls)
+	}
+}
+
+func TestDeleterWithProtectionRetries(t *testing.T) {
+	inUse := NewDeletedVolumeInUseError("disk is still attached")
+	tests := []struct {
+		name           string
+		errs           []error
+		expectErr      bool
+		expectedCalls  int
+		expectedSleeps []time.Duration
+	}{
+		{
+			name:           "transient errors",
+			errs:           []error{inUse, NewTransientError("throttled")},
+			expectedCalls:  3,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:          "permanent error",
+			errs:          []error{fmt.Errorf("permission denied")},
+			expectErr:     true,
+			expectedCalls: 1,
+		},
+		{
+			name:           "attempts exhausted",
+			errs:           []error{inUse, inUse, inUse, inUse, inUse, inUse},
+			expectErr:      true,
+			expectedCalls:  deleteAttempts,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+	}
+	for _, test := range tests {
+		deleter := &scriptedDeleter{errs: test.errs}
+		d, sleeps := newProtectedDeleter(deleter, map[string]ActualStateOfWorld{"node1": NewActualStateOfWorld()})
+		err := d.Delete()
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if deleter.calls != test.expectedCalls {
+			t.Errorf("%s: expected %d deletions, got %d", test.name, test.expectedCalls, deleter.calls)
+		}
+		if len(*sleeps) != 0 || len(test.expectedSleeps) != 0 {
+			if !reflect.DeepEqual(*sleeps, test.expectedSleeps) {
+				t.Errorf("%s: expected backoffs %v, got %v", test.name, test.expectedSleeps, *sleeps)
+			}
+		}
+	}
+}
+
+func TestDeleterWithProtectionRechecksReferences(t *testing.T) {
+	state := NewActualStateOfWorld()
+	deleter := &scriptedDeleter{errs: []error{NewTransientError("throttled")}}
+	d, _ := newProtectedDeleter(deleter, map[string]ActualStateOfWorld{"node1": state})
+	// The volume is attached again while the deletion backs off.
+	d.sleep = func(time.Duration) {
+		state.MarkVolumeAsAttached("kubernetes.io/fake/vol", &Spec{Volume: &api.Volume{Name: "vol"}}, "/dev/sdb")
+	}
+
+	if err := d.Delete(); !IsDeletedVolumeInUse(err) {
+		t.Errorf("Expected an in use error, got %v", err)
+	}
+	if deleter.calls != 1 {
+		t.Errorf("Expected one deletion, got %d", deleter.calls)
+	}
+}
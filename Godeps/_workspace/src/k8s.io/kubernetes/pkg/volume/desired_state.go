@@ -0,0 +1,193 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// DesiredStateOfWorld caches which volumes should be attached to the node
+// and mounted for which of its pods.  Volumes are keyed by their unique
+// names, see GetUniqueVolumeName, so that a volume used by several pods is
+// tracked once.  It is safe for concurrent use.
+type DesiredStateOfWorld interface {
+	// AddPodToVolume records that the volume of spec should be mounted for
+	// pod and returns the unique name of the volume.  Adding a pod to a
+	// volume again replaces its pod and spec.
+	AddPodToVolume(pod *api.Pod, spec *Spec) (string, error)
+	// DeletePodFromVolume records that the volume should no longer be
+	// mounted for the pod.  The volume is forgotten once no pod wants it.
+	DeletePodFromVolume(podUID types.UID, volumeName string)
+	// DeletePod deletes the pod from all of its volumes.
+	DeletePod(podUID types.UID)
+	// VolumeExists returns whether any pod wants the volume.
+	VolumeExists(volumeName string) bool
+	// PodExistsInVolume returns whether the volume should be mounted for
+	// the pod.
+	PodExistsInVolume(podUID types.UID, volumeName string) bool
+	// GetVolumesToMount returns an entry for every pod and volume that
+	// should be mounted for it, ordered by volume name and pod UID.
+	GetVolumesToMount() []VolumeToMount
+}
+
+// VolumeToMount is a volume that should be mounted for a pod.
+type VolumeToMount struct {
+	// VolumeName is the unique name of the volume.
+	VolumeName string
+	// PluginName is the name of the plugin of the volume.
+	PluginName string
+	// Attachable is whether the volume needs to be attached to the node
+	// before it is mounted.
+	Attachable bool
+	Pod        *api.Pod
+	Spec       *Spec
+}
+
+// NewDesiredStateOfWorld returns an empty DesiredStateOfWorld which looks
+// up the plugins of volumes in pluginMgr.
+func NewDesiredStateOfWorld(pluginMgr *VolumePluginMgr) DesiredStateOfWorld {
+	return &desiredStateOfWorld{
+		pluginMgr: pluginMgr,
+		volumes:   map[string]*desiredVolume{},
+	}
+}
+
+type desiredStateOfWorld struct {
+	pluginMgr *VolumePluginMgr
+
+	lock    sync.RWMutex
+	volumes map[string]*desiredVolume
+}
+
+type desiredVolume struct {
+	pluginName string
+	attachable bool
+	pods       map[types.UID]desiredPod
+}
+
+// desiredPod keeps the spec of each pod, as pods may name the same volume
+// differently.
+type desiredPod struct {
+	pod  *api.Pod
+	spec *Spec
+}
+
+var _ DesiredStateOfWorld = &desiredStateOfWorld{}
+
+func (dsw *desiredStateOfWorld) AddPodToVolume(pod *api.Pod, spec *Spec) (string, error) {
+	plugin, err := dsw.pluginMgr.FindPluginBySpec(spec)
+	if err != nil {
+		return "", err
+	}
+	volumeName, err := GetUniqueVolumeNameFromSpec(plugin, spec)
+	if err != nil {
+		return "", err
+	}
+	_, attachable := plugin.(AttachableVolumePlugin)
+
+	dsw.lock.Lock()
+	defer dsw.lock.Unlock()
+	volume, found := dsw.volumes[volumeName]
+	if !found {
+		volume = &desiredVolume{
+			pluginName: plugin.Name(),
+			attachable: attachable,
+			pods:       map[types.UID]desiredPod{},
+		}
+		dsw.volumes[volumeName] = volume
+	}
+	volume.pods[pod.UID] = desiredPod{pod: pod, spec: spec}
+	return volumeName, nil
+}
+
+func (dsw *desiredStateOfWorld) DeletePodFromVolume(podUID types.UID, volumeName string) {
+	dsw.lock.Lock()
+	defer dsw.lock.Unlock()
+	dsw.deletePodFromVolume(podUID, volumeName)
+}
+
+func (dsw *desiredStateOfWorld) DeletePod(podUID types.UID) {
+	dsw.lock.Lock()
+	defer dsw.lock.Unlock()
+	for volumeName := range dsw.volumes {
+		dsw.deletePodFromVolume(podUID, volumeName)
+	}
+}
+
+// deletePodFromVolume must be called with dsw.lock held.
+func (dsw *desiredStateOfWorld) deletePodFromVolume(podUID types.UID, volumeName string) {
+	volume, found := dsw.volumes[volumeName]
+	if !found {
+		return
+	}
+	delete(volume.pods, podUID)
+	if len(volume.pods) == 0 {
+		delete(dsw.volumes, volumeName)
+	}
+}
+
+func (dsw *desiredStateOfWorld) VolumeExists(volumeName string) bool {
+	dsw.lock.RLock()
+	defer dsw.lock.RUnlock()
+	_, found := dsw.volumes[volumeName]
+	return found
+}
+
+func (dsw *desiredStateOfWorld) PodExistsInVolume(podUID types.UID, volumeName string) bool {
+	dsw.lock.RLock()
+	defer dsw.lock.RUnlock()
+	volume, found := dsw.volumes[volumeName]
+	if !found {
+		return false
+	}
+	_, found = volume.pods[podUID]
+	return found
+}
+
+func (dsw *desiredStateOfWorld) GetVolumesToMount() []VolumeToMount {
+	dsw.lock.RLock()
+	defer dsw.lock.RUnlock()
+	volumesToMount := []VolumeToMount{}
+	for volumeName, volume := range dsw.volumes {
+		for _, pod := range volume.pods {
+			volumesToMount = append(volumesToMount, VolumeToMount{
+				VolumeName: volumeName,
+				PluginName: volume.pluginName,
+				Attachable: volume.attachable,
+				Pod:        pod.pod,
+				Spec:       pod.spec,
+			})
+		}
+	}
+	sort.Sort(byVolumeAndPod(volumesToMount))
+	return volumesToMount
+}
+
+type byVolumeAndPod []VolumeToMount
+
+func (v byVolumeAndPod) Len() int      { return len(v) }
+func (v byVolumeAndPod) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v byVolumeAndPod) Less(i, j int) bool {
+	if v[i].VolumeName != v[j].VolumeName {
+		return v[i].VolumeName < v[j].VolumeName
+	}
+	return v[i].Pod.UID < v[j].Pod.UID
+}
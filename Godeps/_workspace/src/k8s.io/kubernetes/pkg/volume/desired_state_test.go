@@ -0,0 +1,104 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// localPlugin is a FakeVolumePlugin whose volumes are not attachable.
+type localPlugin struct {
+	VolumePlugin
+}
+
+func newTestPluginMgr(t *testing.T, plugin VolumePlugin) *VolumePluginMgr {
+	plugMgr := &VolumePluginMgr{}
+	if err := plugMgr.InitPlugins([]VolumePlugin{plugin}, NewFakeVolumeHost("/tmp/fake", nil, nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return plugMgr
+}
+
+func testPod(uid string) *api.Pod {
+	return &api.Pod{ObjectMeta: api.ObjectMeta{Name: "pod-" + uid, Namespace: "ns", UID: types.UID(uid)}}
+}
+
+func testVolumeSpec(name string) *Spec {
+	return NewSpecFromVolume(&api.Volume{Name: name})
+}
+
+func TestDesiredStateOfWorld(t *testing.T) {
+	dsw := NewDesiredStateOfWorld(newTestPluginMgr(t, &FakeVolumePlugin{PluginName: "kubernetes.io/fake"}))
+
+	volumeName, err := dsw.AddPodToVolume(testPod("pod1"), testVolumeSpec("vol1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if volumeName != "kubernetes.io/fake/vol1" {
+		t.Errorf("Unexpected volume name %q", volumeName)
+	}
+	if _, err := dsw.AddPodToVolume(testPod("pod2"), testVolumeSpec("vol1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := dsw.AddPodToVolume(testPod("pod2"), testVolumeSpec("vol2")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !dsw.VolumeExists(volumeName) || !dsw.PodExistsInVolume("pod1", volumeName) || dsw.PodExistsInVolume("pod3", volumeName) {
+		t.Errorf("Unexpected state: %+v", dsw.GetVolumesToMount())
+	}
+
+	volumes := dsw.GetVolumesToMount()
+	expected := []struct {
+		volumeName string
+		podUID     types.UID
+	}{
+		{"kubernetes.io/fake/vol1", "pod1"},
+		{"kubernetes.io/fake/vol1", "pod2"},
+		{"kubernetes.io/fake/vol2", "pod2"},
+	}
+	if len(volumes) != len(expected) {
+		t.Fatalf("Expected %d volumes to mount, got %+v", len(expected), volumes)
+	}
+	for i, volume := range volumes {
+		if volume.VolumeName != expected[i].volumeName || volume.Pod.UID != expected[i].podUID {
+			t.Errorf("Expected %+v, got %+v", expected[i], volume)
+		}
+		if volume.PluginName != "kubernetes.io/fake" || !volume.Attachable {
+			t.Errorf("Expected an attachable volume of kubernetes.io/fake, got %+v", volume)
+		}
+	}
+
+	dsw.DeletePodFromVolume("pod1", volumeName)
+	if dsw.PodExistsInVolume("pod1", volumeName) || !dsw.VolumeExists(volumeName) {
+		t.Errorf("Expected only pod1 to be deleted from %s", volumeName)
+	}
+	dsw.DeletePod("pod2")
+	if volumes := dsw.GetVolumesToMount(); len(volumes) != 0 || dsw.VolumeExists(volumeName) {
+		t.Errorf("Expected unused volumes to be forgotten, got %+v", volumes)
+	}
+
+	local := NewDesiredStateOfWorld(newTestPluginMgr(t, localPlugin{&FakeVolumePlugin{PluginName: "kubernetes.io/local"}}))
+	if _, err := local.AddPodToVolume(testPod("pod1"), testVolumeSpec("vol1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if volumes := local.GetVolumesToMount(); len(volumes) != 1 || volumes[0].Attachable {
+		t.Errorf("Expected a volume that isn't attachable, got %+v", volumes)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+// blkidNotFoundExitCode is returned by blkid when the device has no
+// recognizable filesystem or partition table.
+const blkidNotFoundExitCode = 2
+
+// ErrDeviceUnformatted is returned when a filesystem was expected on a device
+// that has none.
+type ErrDeviceUnformatted struct {
+	Device   string
+	Expected string
+}
+
+func (e *ErrDeviceUnformatted) Error() string {
+	return fmt.Sprintf("device %s is not formatted, expected %s", e.Device, e.Expected)
+}
+
+// ErrFSTypeMismatch is returned when a device holds a different filesystem
+// than expected.
+type ErrFSTypeMismatch struct {
+	Device   string
+	Expected string
+	Actual   string
+}
+
+func (e *ErrFSTypeMismatch) Error() string {
+	return fmt.Sprintf("device %s is formatted as %s, expected %s", e.Device, e.Actual, e.Expected)
+}
+
+// DeviceProber inspects block devices using blkid.
+type DeviceProber struct {
+	Runner exec.Interface
+}
+
+// GetDiskFormat returns the filesystem type on the device at devicePath, or
+// "" if it has none.  A device with a partition table but no filesystem is
+// reported as an error, since it must not be formatted over.
+func (p *DeviceProber) GetDiskFormat(devicePath string) (string, error) {
+	args := []string{"-p", "-s", "TYPE", "-s", "PTTYPE", "-o", "export", devicePath}
+	output, err := p.Runner.Command("blkid", args...).CombinedOutput()
+	if err != nil {
+		if exitErr, ok := err.(exec.ExitError); ok && exitErr.ExitStatus() == blkidNotFoundExitCode {
+			return "", nil
+		}
+		return "", fmt.Errorf("blkid failed for %s: %v, output: %q", devicePath, err, string(output))
+	}
+
+	var fsType, ptType string
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "TYPE":
+			fsType = parts[1]
+		case "PTTYPE":
+			ptType = parts[1]
+		}
+	}
+	if fsType == "" && ptType != "" {
+		return "", fmt.Errorf("device %s has a %s partition table but no filesystem", devicePath, ptType)
+	}
+	return fsType, nil
+}
+
+// VerifyExpectedFSType checks that the device at devicePath holds a
+// filesystem of type expectedFSType, so a builder can refuse to mount the
+// wrong thing.  It returns ErrDeviceUnformatted if the device has no
+// filesystem and ErrFSTypeMismatch if it has a different one.
+func (p *DeviceProber) VerifyExpectedFSType(devicePath, expectedFSType string) error {
+	actual, err := p.GetDiskFormat(devicePath)
+	if err != nil {
+		return err
+	}
+	if actual == "" {
+		return &ErrDeviceUnformatted{Device: devicePath, Expected: expectedFSType}
+	}
+	if actual != expectedFSType {
+		return &ErrFSTypeMismatch{Device: devicePath, Expected: expectedFSType, Actual: actual}
+	}
+	return nil
+}
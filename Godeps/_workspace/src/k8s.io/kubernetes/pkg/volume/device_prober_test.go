@@ -0,0 +1,101 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+// newFakeBlkid returns a FakeExec which answers a single blkid call.
+func newFakeBlkid(output string, err error, argv *[]string) *exec.FakeExec {
+	return &exec.FakeExec{
+		CommandScript: []exec.FakeCommandAction{
+			func(cmd string, args ...string) exec.Cmd {
+				*argv = append([]string{cmd}, args...)
+				fcmd := &exec.FakeCmd{
+					CombinedOutputScript: []exec.FakeCombinedOutputAction{
+						func() ([]byte, error) { return []byte(output), err },
+					},
+				}
+				return exec.InitFakeCmd(fcmd, cmd, args...)
+			},
+		},
+	}
+}
+
+func TestVerifyExpectedFSType(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		check  func(error) bool
+	}{
+		{
+			name:   "matching",
+			output: "DEVNAME=/dev/sdb\nTYPE=ext4\n",
+			check:  func(err error) bool { return err == nil },
+		},
+		{
+			name:   "mismatched",
+			output: "DEVNAME=/dev/sdb\nTYPE=xfs\n",
+			check: func(err error) bool {
+				mismatch, ok := err.(*ErrFSTypeMismatch)
+				return ok && mismatch.Actual == "xfs" && mismatch.Expected == "ext4"
+			},
+		},
+		{
+			name: "unformatted",
+			err:  &exec.FakeExitError{Status: blkidNotFoundExitCode},
+			check: func(err error) bool {
+				_, ok := err.(*ErrDeviceUnformatted)
+				return ok
+			},
+		},
+		{
+			name:   "partitioned",
+			output: "DEVNAME=/dev/sdb\nPTTYPE=gpt\n",
+			check: func(err error) bool {
+				_, mismatch := err.(*ErrFSTypeMismatch)
+				_, unformatted := err.(*ErrDeviceUnformatted)
+				return err != nil && !mismatch && !unformatted
+			},
+		},
+		{
+			name: "blkid failure",
+			err:  &exec.FakeExitError{Status: 4},
+			check: func(err error) bool {
+				_, unformatted := err.(*ErrDeviceUnformatted)
+				return err != nil && !unformatted
+			},
+		},
+	}
+	for _, test := range tests {
+		var argv []string
+		prober := &DeviceProber{Runner: newFakeBlkid(test.output, test.err, &argv)}
+		err := prober.VerifyExpectedFSType("/dev/sdb", "ext4")
+		if !test.check(err) {
+			t.Errorf("%s: unexpected result %v", test.name, err)
+		}
+		expected := []string{"blkid", "-p", "-s", "TYPE", "-s", "PTTYPE", "-o", "export", "/dev/sdb"}
+		if !reflect.DeepEqual(argv, expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, expected, argv)
+		}
+	}
+}
@@ -17,18 +17,14 @@ limitations under the License.
 package downwardapi
 
 import (
-	"io/ioutil"
-	"os"
+	"fmt"
 	"path"
-	"path/filepath"
 	"sort"
 	"strings"
-	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/fieldpath"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	utilErrors "k8s.io/kubernetes/pkg/util/errors"
 	"k8s.io/kubernetes/pkg/volume"
 
@@ -42,6 +38,8 @@ func ProbeVolumePlugins() []volume.VolumePlugin {
 
 const (
 	downwardAPIPluginName = "kubernetes.io/downward-api"
+	// defaultFileMode is the mode of the files of the volume.
+	defaultFileMode = 0644
 )
 
 // downwardAPIPlugin implements the VolumePlugin interface.
@@ -63,6 +61,13 @@ func (plugin *downwardAPIPlugin) CanSupport(spec *volume.Spec) bool {
 	return spec.Volume != nil && spec.Volume.DownwardAPI != nil
 }
 
+func (plugin *downwardAPIPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.DownwardAPI == nil {
+		return "", fmt.Errorf("spec does not reference a downwardAPI volume")
+	}
+	return spec.Name(), nil
+}
+
 func (plugin *downwardAPIPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	v := &downwardAPIVolume{
 		volName: spec.Name(),
@@ -106,10 +111,7 @@ type downwardAPIVolumeBuilder struct {
 
 // downwardAPIVolumeBuilder implements volume.Builder interface
 var _ volume.Builder = &downwardAPIVolumeBuilder{}
-
-func (_ *downwardAPIVolumeBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
+var _ volume.ContentRefresher = &downwardAPIVolumeBuilder{}
 
 // SetUp puts in place the volume plugin.
 // This function is not idempotent by design. We want the data to be refreshed periodically.
@@ -138,12 +140,13 @@ func (b *downwardAPIVolumeBuilder) SetUpAt(dir string) error {
 		return err
 	}
 
-	if !b.isDataChanged(data) {
-		// No data changed: nothing to write
-		return nil
+	writer, err := volume.NewAtomicWriter(dir, fmt.Sprintf("downwardAPI volume %v for pod %v/%v", b.volName, b.pod.Namespace, b.pod.Name))
+	if err != nil {
+		glog.Errorf("Unable to dump files for downwardAPI volume %v for pod %v/%v: %s", b.volName, b.pod.Namespace, b.pod.Name, err.Error())
+		return err
 	}
-
-	if err := b.writeData(data); err != nil {
+	// The writer leaves the volume alone if no data changed.
+	if err := writer.Write(data); err != nil {
 		glog.Errorf("Unable to dump files for downwardAPI volume %v for pod %v/%v: %s", b.volName, b.pod.Namespace, b.pod.Name, err.Error())
 		return err
 	}
@@ -153,188 +156,53 @@ func (b *downwardAPIVolumeBuilder) SetUpAt(dir string) error {
 }
 
 // IsReadOnly func to fulfill volume.Builder interface
-func (d *downwardAPIVolume) IsReadOnly() bool {
-	return true
+func (b *downwardAPIVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        true,
+		Managed:         false,
+		SupportsSELinux: true,
+	}
 }
 
-func (d *downwardAPIVolume) SupportsSELinux() bool {
+// RefreshesContent tells volume.EnforceReadOnly that SetUp rewrites the
+// files of the volume, which must stay writable therefore.
+func (d *downwardAPIVolume) RefreshesContent() bool {
 	return true
 }
 
 // collectData collects requested downwardAPI in data map.
 // Map's key is the requested name of file to dump
 // Map's value is the (sorted) content of the field to be dumped in the file.
-func (d *downwardAPIVolume) collectData() (map[string]string, error) {
-	errlist := []error{}
-	data := make(map[string]string)
-	for fieldReference, fileName := range d.fieldReferenceFileNames {
-		if values, err := fieldpath.ExtractFieldPathAsString(d.pod, fieldReference); err != nil {
-			glog.Errorf("Unable to extract field %s: %s", fieldReference, err.Error())
-			errlist = append(errlist, err)
-		} else {
-			data[fileName] = sortLines(values)
-		}
-	}
-	return data, utilErrors.NewAggregate(errlist)
+func (d *downwardAPIVolume) collectData() (map[string]volume.FileProjection, error) {
+	return collectFieldData(d.pod, d.fieldReferenceFileNames, defaultFileMode)
 }
 
-// isDataChanged iterate over all the entries to check whether at least one
-// file needs to be updated.
-func (d *downwardAPIVolume) isDataChanged(data map[string]string) bool {
-	for fileName, values := range data {
-		if isFileToGenerate(path.Join(d.GetPath(), fileName), values) {
-			return true
-		}
-	}
-	return false
-}
-
-// isFileToGenerate compares actual file with the new values. If
-// different (or the file does not exist) return true
-func isFileToGenerate(fileName, values string) bool {
-	if _, err := os.Lstat(fileName); os.IsNotExist(err) {
-		return true
-	}
-	return readFile(fileName) != values
-}
-
-const (
-	downwardAPIDir    = "..downwardapi"
-	downwardAPITmpDir = "..downwardapi_tmp"
-	// It seems reasonable to allow dot-files in the config, so we reserved double-dot-files for the implementation".
-)
-
-// writeData writes requested downwardAPI in specified files.
-//
-// The file visible in this volume are symlinks to files in the '..downwardapi'
-// directory. Actual files are stored in an hidden timestamped directory which is
-// symlinked to by '..downwardapi'. The timestamped directory and '..downwardapi' symlink
-// are created in the plugin root dir.  This scheme allows the files to be
-// atomically updated by changing the target of the '..downwardapi' symlink.  When new
-// data is available:
-//
-// 1.  A new timestamped dir is created by writeDataInTimestampDir and requested data
-//     is written inside new timestamped directory
-// 2.  Symlinks and directory for new files are created (if needed).
-//     For example for files:
-//       <volume-dir>/user_space/labels
-//       <volume-dir>/k8s_space/annotations
-//       <volume-dir>/podName
-//     This structure is created:
-//       <volume-dir>/podName               -> ..downwardapi/podName
-//       <volume-dir>/user_space/labels     -> ../..downwardapi/user_space/labels
-//       <volume-dir>/k8s_space/annotations -> ../..downwardapi/k8s_space/annotations
-//       <volume-dir>/..downwardapi         -> ..downwardapi.12345678
-//     where ..downwardapi.12345678 is a randomly generated directory which contains
-//     the real data. If a file has to be dumped in subdirectory (for example <volume-dir>/user_space/labels)
-//     plugin builds a relative symlink (<volume-dir>/user_space/labels -> ../..downwardapi/user_space/labels)
-// 3.  The previous timestamped directory is detected reading the '..downwardapi' symlink
-// 4.  In case no symlink exists then it's created
-// 5.  In case symlink exists a new temporary symlink is created ..downwardapi_tmp
-// 6.  ..downwardapi_tmp is renamed to ..downwardapi
-// 7.  The previous timestamped directory is removed
-
-func (d *downwardAPIVolume) writeData(data map[string]string) error {
-	timestampDir, err := d.writeDataInTimestampDir(data)
-	if err != nil {
-		glog.Errorf("Unable to write data in temporary directory: %s", err.Error())
-		return err
-	}
-	// update symbolic links for relative paths
-	if err = d.updateSymlinksToCurrentDir(); err != nil {
-		os.RemoveAll(timestampDir)
-		glog.Errorf("Unable to create symlinks and/or directory: %s", err.Error())
-		return err
-	}
-
-	_, timestampDirBaseName := filepath.Split(timestampDir)
-	var oldTimestampDirectory string
-	oldTimestampDirectory, err = os.Readlink(path.Join(d.GetPath(), downwardAPIDir))
-
-	if err = os.Symlink(timestampDirBaseName, path.Join(d.GetPath(), downwardAPITmpDir)); err != nil {
-		os.RemoveAll(timestampDir)
-		glog.Errorf("Unable to create symolic link: %s", err.Error())
-		return err
+// CollectData returns the files to project the fields of pod selected by
+// items into, with mode defaultMode, 0644 if nil.
+func CollectData(pod *api.Pod, items []api.DownwardAPIVolumeFile, defaultMode *int32) (map[string]volume.FileProjection, error) {
+	fileMode := int32(defaultFileMode)
+	if defaultMode != nil {
+		fileMode = *defaultMode
 	}
-
-	// Rename the symbolic link downwardAPITmpDir to downwardAPIDir
-	if err = os.Rename(path.Join(d.GetPath(), downwardAPITmpDir), path.Join(d.GetPath(), downwardAPIDir)); err != nil {
-		// in case of error remove latest data and downwardAPITmpDir
-		os.Remove(path.Join(d.GetPath(), downwardAPITmpDir))
-		os.RemoveAll(timestampDir)
-		glog.Errorf("Unable to rename symbolic link: %s", err.Error())
-		return err
+	fieldReferenceFileNames := make(map[string]string, len(items))
+	for _, item := range items {
+		fieldReferenceFileNames[item.FieldRef.FieldPath] = path.Clean(item.Path)
 	}
-	// Remove oldTimestampDirectory
-	if len(oldTimestampDirectory) > 0 {
-		if err := os.RemoveAll(path.Join(d.GetPath(), oldTimestampDirectory)); err != nil {
-			glog.Errorf("Unable to remove directory: %s", err.Error())
-			return err
-		}
-	}
-	return nil
+	return collectFieldData(pod, fieldReferenceFileNames, fileMode)
 }
 
-// writeDataInTimestampDir writes the latest data into a new temporary directory with a timestamp.
-func (d *downwardAPIVolume) writeDataInTimestampDir(data map[string]string) (string, error) {
+func collectFieldData(pod *api.Pod, fieldReferenceFileNames map[string]string, mode int32) (map[string]volume.FileProjection, error) {
 	errlist := []error{}
-	timestampDir, err := ioutil.TempDir(d.GetPath(), ".."+time.Now().Format("2006_01_02_15_04_05"))
-	for fileName, values := range data {
-		fullPathFile := path.Join(timestampDir, fileName)
-		dir, _ := filepath.Split(fullPathFile)
-		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
-			glog.Errorf("Unable to create directory `%s`: %s", dir, err.Error())
-			return "", err
-		}
-		if err := ioutil.WriteFile(fullPathFile, []byte(values), 0644); err != nil {
-			glog.Errorf("Unable to write file `%s`: %s", fullPathFile, err.Error())
+	data := make(map[string]volume.FileProjection)
+	for fieldReference, fileName := range fieldReferenceFileNames {
+		if values, err := fieldpath.ExtractFieldPathAsString(pod, fieldReference); err != nil {
+			glog.Errorf("Unable to extract field %s: %s", fieldReference, err.Error())
 			errlist = append(errlist, err)
+		} else {
+			data[fileName] = volume.FileProjection{Data: []byte(sortLines(values)), Mode: mode}
 		}
 	}
-	return timestampDir, utilErrors.NewAggregate(errlist)
-}
-
-// updateSymlinksToCurrentDir creates the relative symlinks for all the files configured in this volume.
-// If the directory in a file path does not exist, it is created.
-//
-// For example for files: "bar", "foo/bar", "baz/bar", "foo/baz/blah"
-// the following symlinks and subdirectory are created:
-// bar          -> ..downwardapi/bar
-// baz/bar      -> ../..downwardapi/baz/bar
-// foo/bar      -> ../..downwardapi/foo/bar
-// foo/baz/blah -> ../../..downwardapi/foo/baz/blah
-func (d *downwardAPIVolume) updateSymlinksToCurrentDir() error {
-	for _, f := range d.fieldReferenceFileNames {
-		dir, _ := filepath.Split(f)
-		nbOfSubdir := 0
-		if len(dir) > 0 {
-			// if dir is not empty f contains at least a subdirectory (for example: f="foo/bar")
-			// since filepath.Split leaves a trailing '/'  we have dir="foo/"
-			// and since len(strings.Split"foo/")=2 to count the number
-			// of sub directory you need to remove 1
-			nbOfSubdir = len(strings.Split(dir, "/")) - 1
-			if err := os.MkdirAll(path.Join(d.GetPath(), dir), os.ModePerm); err != nil {
-				return err
-			}
-		}
-		if _, err := os.Readlink(path.Join(d.GetPath(), f)); err != nil {
-			// link does not exist create it
-			presentedFile := path.Join(strings.Repeat("../", nbOfSubdir), downwardAPIDir, f)
-			actualFile := path.Join(d.GetPath(), f)
-			if err := os.Symlink(presentedFile, actualFile); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
-}
-
-// readFile reads the file at the given path and returns the content as a string.
-func readFile(path string) string {
-	if data, err := ioutil.ReadFile(path); err == nil {
-		return string(data)
-	}
-	return ""
+	return data, utilErrors.NewAggregate(errlist)
 }
 
 // sortLines sorts the strings generated from map based data
@@ -346,7 +214,7 @@ func sortLines(values string) string {
 }
 
 func (d *downwardAPIVolume) GetPath() string {
-	return d.plugin.host.GetPodVolumeDir(d.podUID, util.EscapeQualifiedNameForDisk(downwardAPIPluginName), d.volName)
+	return volume.NewDirectoryLayout(d.plugin.host).PodVolumeDir(d.podUID, downwardAPIPluginName, d.volName)
 }
 
 // downwardAPIVolumeCleander handles cleaning up downwardAPI volumes
@@ -373,5 +241,5 @@ func (c *downwardAPIVolumeCleaner) TearDownAt(dir string) error {
 }
 
 func (b *downwardAPIVolumeBuilder) getMetaDir() string {
-	return path.Join(b.plugin.host.GetPodPluginDir(b.podUID, util.EscapeQualifiedNameForDisk(downwardAPIPluginName)), b.volName)
+	return path.Join(volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.podUID, downwardAPIPluginName), b.volName)
 }
@@ -33,6 +33,9 @@ import (
 
 const basePath = "/tmp/fake"
 
+// dataDirName is the symlink the atomic writer switches to new data.
+const dataDirName = "..data"
+
 func formatMap(m map[string]string) (fmtstr string) {
 	for key, value := range m {
 		fmtstr += fmt.Sprintf("%v=%q\n", key, value)
@@ -382,7 +385,7 @@ func TestWriteTwiceNoUpdate(t *testing.T) {
 
 	// get the link of the link
 	var currentTarget string
-	if currentTarget, err = os.Readlink(path.Join(volumePath, downwardAPIDir)); err != nil {
+	if currentTarget, err = os.Readlink(path.Join(volumePath, dataDirName)); err != nil {
 		t.Errorf(".current should be a link... %s\n", err.Error())
 	}
 
@@ -393,7 +396,7 @@ func TestWriteTwiceNoUpdate(t *testing.T) {
 
 	// get the link of the link
 	var currentTarget2 string
-	if currentTarget2, err = os.Readlink(path.Join(volumePath, downwardAPIDir)); err != nil {
+	if currentTarget2, err = os.Readlink(path.Join(volumePath, dataDirName)); err != nil {
 		t.Errorf(".current should be a link... %s\n", err.Error())
 	}
 
@@ -465,7 +468,7 @@ func TestWriteTwiceWithUpdate(t *testing.T) {
 	}
 
 	var currentTarget string
-	if currentTarget, err = os.Readlink(path.Join(volumePath, downwardAPIDir)); err != nil {
+	if currentTarget, err = os.Readlink(path.Join(volumePath, dataDirName)); err != nil {
 		t.Errorf("labels file should be a link... %s\n", err.Error())
 	}
 
@@ -493,7 +496,7 @@ func TestWriteTwiceWithUpdate(t *testing.T) {
 
 	// get the link of the link
 	var currentTarget2 string
-	if currentTarget2, err = os.Readlink(path.Join(volumePath, downwardAPIDir)); err != nil {
+	if currentTarget2, err = os.Readlink(path.Join(volumePath, dataDirName)); err != nil {
 		t.Errorf(".current should be a link... %s\n", err.Error())
 	}
 
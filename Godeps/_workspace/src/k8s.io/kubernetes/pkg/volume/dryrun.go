@@ -0,0 +1,51 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// ErrDryRunNotSupported is returned by DryRunProvision and DryRunDelete for
+// volumes which cannot be validated without performing the operation.
+type ErrDryRunNotSupported struct {
+	Operation OperationType
+}
+
+func (e *ErrDryRunNotSupported) Error() string {
+	return fmt.Sprintf("dry run of %s is not supported by this volume plugin", e.Operation)
+}
+
+// DryRunProvision checks whether provisioner would be able to provision pv,
+// without creating anything.  It returns ErrDryRunNotSupported if
+// provisioner does not implement DryRunProvisioner.
+func DryRunProvision(provisioner Provisioner, pv *api.PersistentVolume) error {
+	if p, ok := provisioner.(DryRunProvisioner); ok {
+		return p.ValidateProvision(pv)
+	}
+	return &ErrDryRunNotSupported{Operation: OperationProvision}
+}
+
+// DryRunDelete is like DryRunProvision, for Deleters.
+func DryRunDelete(deleter Deleter) error {
+	if d, ok := deleter.(DryRunDeleter); ok {
+		return d.ValidateDelete()
+	}
+	return &ErrDryRunNotSupported{Operation: OperationDelete}
+}
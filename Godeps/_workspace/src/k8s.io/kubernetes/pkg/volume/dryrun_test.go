@@ -0,0 +1,32 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestDryRunNotSupported(t *testing.T) {
+	if err, ok := DryRunProvision(&FakeProvisioner{}, &api.PersistentVolume{}).(*ErrDryRunNotSupported); !ok || err.Operation != OperationProvision {
+		t.Errorf("Expected ErrDryRunNotSupported for provision, got %v", err)
+	}
+	if err, ok := DryRunDelete(&FakeDeleter{}).(*ErrDryRunNotSupported); !ok || err.Operation != OperationDelete {
+		t.Errorf("Expected ErrDryRunNotSupported for delete, got %v", err)
+	}
+}
@@ -24,7 +24,6 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 	volumeutil "k8s.io/kubernetes/pkg/volume/util"
@@ -40,12 +39,14 @@ const perm os.FileMode = 0777
 // This is the primary entrypoint for volume plugins.
 func ProbeVolumePlugins() []volume.VolumePlugin {
 	return []volume.VolumePlugin{
-		&emptyDirPlugin{nil},
+		&emptyDirPlugin{nil, volume.NewProjectQuota()},
 	}
 }
 
 type emptyDirPlugin struct {
 	host volume.VolumeHost
+	// quota limits the size of volumes on disk.
+	quota volume.QuotaApplier
 }
 
 var _ volume.VolumePlugin = &emptyDirPlugin{}
@@ -69,6 +70,13 @@ func (plugin *emptyDirPlugin) CanSupport(spec *volume.Spec) bool {
 	return false
 }
 
+func (plugin *emptyDirPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.EmptyDir == nil {
+		return "", fmt.Errorf("spec does not reference a emptyDir volume")
+	}
+	return spec.Name(), nil
+}
+
 func (plugin *emptyDirPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	return plugin.newBuilderInternal(spec, pod, plugin.host.GetMounter(), &realMountDetector{plugin.host.GetMounter()}, opts)
 }
@@ -119,8 +127,9 @@ type mountDetector interface {
 type storageMedium int
 
 const (
-	mediumUnknown storageMedium = 0 // assume anything we don't explicitly handle is this
-	mediumMemory  storageMedium = 1 // memory (e.g. tmpfs on linux)
+	mediumUnknown   storageMedium = 0 // assume anything we don't explicitly handle is this
+	mediumMemory    storageMedium = 1 // memory (e.g. tmpfs on linux)
+	mediumHugepages storageMedium = 2 // hugepages (e.g. hugetlbfs on linux)
 )
 
 // EmptyDir volumes are temporary directories exposed to the pod.
@@ -135,8 +144,18 @@ type emptyDir struct {
 	rootContext   string
 }
 
-func (_ *emptyDir) SupportsOwnershipManagement() bool {
-	return true
+var _ volume.MetricsProvider = &emptyDir{}
+
+// GetMetrics measures the space and inodes used by the volume directory,
+// reading them from its quota if it has one.
+func (ed *emptyDir) GetMetrics() (*volume.Metrics, error) {
+	dir := ed.GetPath()
+	if limit := diskSizeLimit(ed.pod); limit > 0 && ed.medium == api.StorageMediumDefault {
+		if supported, err := ed.plugin.quota.SupportsQuotas(dir); err == nil && supported {
+			return (&volume.MetricsQuota{Path: dir, Quota: ed.plugin.quota, Limit: limit}).GetMetrics()
+		}
+	}
+	return volume.NewMetricsDu(dir).GetMetrics()
 }
 
 // SetUp creates new directory.
@@ -155,10 +174,10 @@ func (ed *emptyDir) SetUpAt(dir string) error {
 
 	// If the plugin readiness file is present for this volume, and the
 	// storage medium is the default, then the volume is ready.  If the
-	// medium is memory, and a mountpoint is present, then the volume is
-	// ready.
+	// medium is memory or hugepages, and a mountpoint is present, then the
+	// volume is ready.
 	if volumeutil.IsReady(ed.getMetaDir()) {
-		if ed.medium == api.StorageMediumMemory && !notMnt {
+		if (ed.medium == api.StorageMediumMemory || ed.medium == api.StorageMediumHugePages) && !notMnt {
 			return nil
 		} else if ed.medium == api.StorageMediumDefault {
 			return nil
@@ -173,9 +192,11 @@ func (ed *emptyDir) SetUpAt(dir string) error {
 
 	switch ed.medium {
 	case api.StorageMediumDefault:
-		err = ed.setupDir(dir)
+		err = ed.setupDisk(dir)
 	case api.StorageMediumMemory:
 		err = ed.setupTmpfs(dir, securityContext)
+	case api.StorageMediumHugePages:
+		err = ed.setupHugepages(dir)
 	default:
 		err = fmt.Errorf("unknown storage medium %q", ed.medium)
 	}
@@ -187,12 +208,50 @@ func (ed *emptyDir) SetUpAt(dir string) error {
 	return err
 }
 
-func (ed *emptyDir) IsReadOnly() bool {
-	return false
+func (ed *emptyDir) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            false,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeAlways,
+		SupportsSELinux:     true,
+	}
 }
 
-func (ed *emptyDir) SupportsSELinux() bool {
-	return true
+// setupDisk creates the directory on the node's disk, limiting its size
+// with a quota where the filesystem supports it.
+func (ed *emptyDir) setupDisk(dir string) error {
+	if err := ed.setupDir(dir); err != nil {
+		return err
+	}
+	limit := diskSizeLimit(ed.pod)
+	if limit <= 0 {
+		return nil
+	}
+	supported, err := ed.plugin.quota.SupportsQuotas(dir)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		glog.V(4).Infof("pod %v: quotas are not supported for volume %v, its size is not limited", ed.pod.UID, ed.volName)
+		return nil
+	}
+	glog.V(3).Infof("pod %v: limiting volume %v to %d bytes", ed.pod.UID, ed.volName, limit)
+	return ed.plugin.quota.AssignQuota(dir, limit)
+}
+
+// diskSizeLimit returns the size in bytes a disk-backed volume of pod may
+// grow to: the sum of its containers' storage limits.  Zero means unlimited,
+// which is the case when any container has no storage limit.
+func diskSizeLimit(pod *api.Pod) int64 {
+	var size int64
+	for _, container := range pod.Spec.Containers {
+		limit, found := container.Resources.Limits[api.ResourceStorage]
+		if !found || limit.Value() <= 0 {
+			return 0
+		}
+		size += limit.Value()
+	}
+	return size
 }
 
 // setupTmpfs creates a tmpfs mount at the specified directory with the
@@ -217,17 +276,54 @@ func (ed *emptyDir) setupTmpfs(dir string, selinuxContext string) error {
 
 	// By default a tmpfs mount will receive a different SELinux context
 	// which is not readable from the SELinux context of a docker container.
-	var opts []string
+	opts := []string{}
 	if selinuxContext != "" {
-		opts = []string{fmt.Sprintf("rootcontext=\"%v\"", selinuxContext)}
-	} else {
-		opts = []string{}
+		opts = append(opts, fmt.Sprintf("rootcontext=\"%v\"", selinuxContext))
+	}
+	if size := tmpfsSizeLimit(ed.pod); size > 0 {
+		opts = append(opts, fmt.Sprintf("size=%d", size))
 	}
 
 	glog.V(3).Infof("pod %v: mounting tmpfs for volume %v with opts %v", ed.pod.UID, ed.volName, opts)
 	return ed.mounter.Mount("tmpfs", dir, "tmpfs", opts)
 }
 
+// tmpfsSizeLimit returns the size in bytes a memory-backed volume of pod may
+// grow to: the sum of its containers' memory limits.  Zero means unlimited,
+// which is the case when any container has no memory limit.
+func tmpfsSizeLimit(pod *api.Pod) int64 {
+	var size int64
+	for _, container := range pod.Spec.Containers {
+		limit, found := container.Resources.Limits[api.ResourceMemory]
+		if !found || limit.Value() <= 0 {
+			return 0
+		}
+		size += limit.Value()
+	}
+	return size
+}
+
+// setupHugepages creates a hugetlbfs mount at the specified directory.
+func (ed *emptyDir) setupHugepages(dir string) error {
+	if ed.mounter == nil {
+		return fmt.Errorf("hugepages storage requested, but mounter is nil")
+	}
+	if err := ed.setupDir(dir); err != nil {
+		return err
+	}
+	// Make SetUp idempotent.
+	medium, isMnt, err := ed.mountDetector.GetMountMedium(dir)
+	if err != nil {
+		return err
+	}
+	if isMnt && medium == mediumHugepages {
+		return nil
+	}
+
+	glog.V(3).Infof("pod %v: mounting hugepages for volume %v", ed.pod.UID, ed.volName)
+	return ed.mounter.Mount("nodev", dir, "hugetlbfs", []string{})
+}
+
 // setupDir creates the directory with the specified SELinux context and
 // the default permissions specified by the perm constant.
 func (ed *emptyDir) setupDir(dir string) error {
@@ -269,7 +365,7 @@ func (ed *emptyDir) setupDir(dir string) error {
 
 func (ed *emptyDir) GetPath() string {
 	name := emptyDirPluginName
-	return ed.plugin.host.GetPodVolumeDir(ed.pod.UID, util.EscapeQualifiedNameForDisk(name), ed.volName)
+	return volume.NewDirectoryLayout(ed.plugin.host).PodVolumeDir(ed.pod.UID, name, ed.volName)
 }
 
 // TearDown simply discards everything in the directory.
@@ -284,29 +380,38 @@ func (ed *emptyDir) TearDownAt(dir string) error {
 	if err != nil {
 		return err
 	}
-	if isMnt && medium == mediumMemory {
-		ed.medium = api.StorageMediumMemory
-		return ed.teardownTmpfs(dir)
+	if isMnt {
+		switch medium {
+		case mediumMemory:
+			ed.medium = api.StorageMediumMemory
+			return ed.teardownMount(dir)
+		case mediumHugepages:
+			ed.medium = api.StorageMediumHugePages
+			return ed.teardownMount(dir)
+		}
 	}
 	// assume StorageMediumDefault
 	return ed.teardownDefault(dir)
 }
 
 func (ed *emptyDir) teardownDefault(dir string) error {
-	tmpDir, err := volume.RenameDirectory(dir, ed.volName+".deleting~")
-	if err != nil {
-		return err
+	// The quota is not needed to delete the directory, so failing to clear
+	// it must not leave the volume behind.
+	if err := ed.plugin.quota.ClearQuota(dir); err != nil {
+		glog.Warningf("pod %v: failed to clear the quota of volume %v: %v", ed.pod.UID, ed.volName, err)
 	}
-	err = os.RemoveAll(tmpDir)
-	if err != nil {
+	// The medium is detected from the filesystem, which misses mounts it
+	// can't see, e.g. those of wrapped volumes created with another mounter.
+	if err := volume.UnmountBeneath(ed.mounter, dir); err != nil {
 		return err
 	}
-	return nil
+	return volume.RenameAndDelete(ed.mounter, dir, nil)
 }
 
-func (ed *emptyDir) teardownTmpfs(dir string) error {
+// teardownMount unmounts the tmpfs or hugetlbfs backing dir and removes it.
+func (ed *emptyDir) teardownMount(dir string) error {
 	if ed.mounter == nil {
-		return fmt.Errorf("memory storage requested, but mounter is nil")
+		return fmt.Errorf("%s storage requested, but mounter is nil", ed.medium)
 	}
 	if err := ed.mounter.Unmount(dir); err != nil {
 		return err
@@ -318,5 +423,5 @@ func (ed *emptyDir) teardownTmpfs(dir string) error {
 }
 
 func (ed *emptyDir) getMetaDir() string {
-	return path.Join(ed.plugin.host.GetPodPluginDir(ed.pod.UID, util.EscapeQualifiedNameForDisk(emptyDirPluginName)), ed.volName)
+	return path.Join(volume.NewDirectoryLayout(ed.plugin.host).PodPluginDir(ed.pod.UID, emptyDirPluginName), ed.volName)
 }
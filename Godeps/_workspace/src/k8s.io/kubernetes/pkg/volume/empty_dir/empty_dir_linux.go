@@ -27,8 +27,11 @@ import (
 	"k8s.io/kubernetes/pkg/util/mount"
 )
 
-// Defined by Linux - the type number for tmpfs mounts.
-const linuxTmpfsMagic = 0x01021994
+// Defined by Linux - the type numbers for tmpfs and hugetlbfs mounts.
+const (
+	linuxTmpfsMagic     = 0x01021994
+	linuxHugetlbfsMagic = 0x958458f6
+)
 
 // realMountDetector implements mountDetector in terms of syscalls.
 type realMountDetector struct {
@@ -47,8 +50,12 @@ func (m *realMountDetector) GetMountMedium(path string) (storageMedium, bool, er
 	}
 
 	glog.V(5).Info("Statfs_t of %v: %+v", path, buf)
-	if buf.Type == linuxTmpfsMagic {
+	// Compare as uint32 since the hugetlbfs magic overflows a 32-bit f_type.
+	switch uint32(buf.Type) {
+	case linuxTmpfsMagic:
 		return mediumMemory, !notMnt, nil
+	case linuxHugetlbfsMagic:
+		return mediumHugepages, !notMnt, nil
 	}
 	return mediumUnknown, !notMnt, nil
 }
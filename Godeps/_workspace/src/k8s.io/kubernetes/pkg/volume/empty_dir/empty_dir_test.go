@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -99,6 +100,14 @@ func TestPluginTmpfs(t *testing.T) {
 		expectedTeardownMounts:        1})
 }
 
+func TestPluginHugepages(t *testing.T) {
+	doTestPlugin(t, pluginTestConfig{
+		medium:                        api.StorageMediumHugePages,
+		expectedSetupMounts:           1,
+		shouldBeMountedBeforeTeardown: true,
+		expectedTeardownMounts:        1})
+}
+
 type pluginTestConfig struct {
 	medium                        api.StorageMedium
 	rootContext                   string
@@ -201,18 +210,25 @@ func doTestPlugin(t *testing.T, config pluginTestConfig) {
 	}
 
 	// Check the number of mounts performed during setup
+	expectedFSType := "tmpfs"
+	if config.medium == api.StorageMediumHugePages {
+		expectedFSType = "hugetlbfs"
+	}
 	if e, a := config.expectedSetupMounts, len(mounter.Log); e != a {
 		t.Errorf("Expected %v mounter calls during setup, got %v", e, a)
 	} else if config.expectedSetupMounts == 1 &&
-		(mounter.Log[0].Action != mount.FakeActionMount || mounter.Log[0].FSType != "tmpfs") {
+		(mounter.Log[0].Action != mount.FakeActionMount || mounter.Log[0].FSType != expectedFSType) {
 		t.Errorf("Unexpected mounter action during setup: %#v", mounter.Log[0])
 	}
 	mounter.ResetLog()
 
 	// Make a cleaner for the volume
 	teardownMedium := mediumUnknown
-	if config.medium == api.StorageMediumMemory {
+	switch config.medium {
+	case api.StorageMediumMemory:
 		teardownMedium = mediumMemory
+	case api.StorageMediumHugePages:
+		teardownMedium = mediumHugepages
 	}
 	cleanerMountDetector := &fakeMountDetector{medium: teardownMedium, isMount: config.shouldBeMountedBeforeTeardown}
 	cleaner, err := plug.(*emptyDirPlugin).newCleanerInternal(volumeName, types.UID("poduid"), &mounter, cleanerMountDetector)
@@ -263,3 +279,102 @@ func TestPluginBackCompat(t *testing.T) {
 		t.Errorf("Got unexpected path: %s", volPath)
 	}
 }
+
+func TestTmpfsSizeLimit(t *testing.T) {
+	limited := func(quantity string) api.Container {
+		return api.Container{Resources: api.ResourceRequirements{
+			Limits: api.ResourceList{api.ResourceMemory: resource.MustParse(quantity)},
+		}}
+	}
+	testCases := []struct {
+		containers []api.Container
+		expected   int64
+	}{
+		{[]api.Container{}, 0},
+		{[]api.Container{{}}, 0},
+		{[]api.Container{limited("64Mi")}, 64 * 1024 * 1024},
+		{[]api.Container{limited("64Mi"), limited("1Gi")}, 64*1024*1024 + 1024*1024*1024},
+		{[]api.Container{limited("64Mi"), {}}, 0},
+	}
+	for i, tc := range testCases {
+		pod := &api.Pod{Spec: api.PodSpec{Containers: tc.containers}}
+		if size := tmpfsSizeLimit(pod); size != tc.expected {
+			t.Errorf("case %d: expected size %d, got %d", i, tc.expected, size)
+		}
+	}
+}
+
+// fakeQuota is a QuotaApplier recording the quotas assigned.
+type fakeQuota struct {
+	quotas map[string]int64
+}
+
+func (q *fakeQuota) SupportsQuotas(path string) (bool, error) {
+	return true, nil
+}
+
+func (q *fakeQuota) AssignQuota(path string, bytes int64) error {
+	q.quotas[path] = bytes
+	return nil
+}
+
+func (q *fakeQuota) GetQuotaUsage(path string) (int64, int64, error) {
+	return 4096, 3, nil
+}
+
+func (q *fakeQuota) ClearQuota(path string) error {
+	delete(q.quotas, path)
+	return nil
+}
+
+func TestDiskQuota(t *testing.T) {
+	basePath, err := ioutil.TempDir("/tmp", "emptydir_quota_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(basePath)
+	plug := makePluginUnderTest(t, "kubernetes.io/empty-dir", basePath)
+	quota := &fakeQuota{quotas: map[string]int64{}}
+	plug.(*emptyDirPlugin).quota = quota
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")},
+		Spec: api.PodSpec{Containers: []api.Container{{Resources: api.ResourceRequirements{
+			Limits: api.ResourceList{api.ResourceStorage: resource.MustParse("1Gi")},
+		}}}},
+	}
+	spec := &api.Volume{Name: "vol1", VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{}}}
+	mounter := mount.FakeMounter{}
+	builder, err := plug.(*emptyDirPlugin).newBuilderInternal(volume.NewSpecFromVolume(spec), pod, &mounter, &fakeMountDetector{}, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("SetUp() failed: %v", err)
+	}
+	if limit := quota.quotas[builder.GetPath()]; limit != 1024*1024*1024 {
+		t.Errorf("Expected a quota of 1Gi, got %d", limit)
+	}
+
+	metrics, err := builder.(volume.MetricsProvider).GetMetrics()
+	if err != nil {
+		t.Fatalf("GetMetrics() failed: %v", err)
+	}
+	if metrics.Used != 4096 || metrics.InodesUsed != 3 {
+		t.Errorf("Expected the usage of the quota, got %+v", metrics)
+	}
+	if metrics.Capacity > 1024*1024*1024 {
+		t.Errorf("Expected the capacity to be limited by the quota, got %d", metrics.Capacity)
+	}
+
+	cleaner, err := plug.(*emptyDirPlugin).newCleanerInternal("vol1", types.UID("poduid"), &mounter, &fakeMountDetector{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Cleaner: %v", err)
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Fatalf("TearDown() failed: %v", err)
+	}
+	if len(quota.quotas) != 0 {
+		t.Errorf("Expected the quota to be cleared, got %v", quota.quotas)
+	}
+}
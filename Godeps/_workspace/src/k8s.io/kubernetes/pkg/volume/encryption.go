@@ -0,0 +1,194 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+const (
+	// EncryptedParameter is the storage class parameter asking for volumes encrypted at rest, "true" or
+	// "false".
+	EncryptedParameter = "encrypted"
+	// KMSKeyIDParameter is the storage class parameter with the ID of the key management service key the
+	// storage provider encrypts volumes with.  Its default key is used if empty.
+	KMSKeyIDParameter = "kmsKeyId"
+	// EncryptionSecretParameter is the storage class parameter naming, as namespace/name, the secret with
+	// the key of volumes the node encrypts itself with dm-crypt.
+	EncryptionSecretParameter = "encryptionSecret"
+
+	// EncryptionSecretAnnotation is the annotation on a PersistentVolume with the EncryptionSecretParameter
+	// it was provisioned with, so that nodes setting up the volume know where to find its key.
+	EncryptionSecretAnnotation = "volume.alpha.kubernetes.io/encryption-secret"
+	// EncryptionKeySecretKey is the key of the encryption key in the data of an encryption secret.
+	EncryptionKeySecretKey = "key"
+
+	// luksFormat is the format blkid reports for LUKS devices.
+	luksFormat = "crypto_LUKS"
+)
+
+// deviceMapperDir is where dm-crypt mappings show up, a variable so that tests can swap it.
+var deviceMapperDir = "/dev/mapper"
+
+// EncryptableVolumePlugin is implemented by ProvisionableVolumePlugins which can provision encrypted
+// volumes, i.e. whose provisioners understand EncryptionParameters.
+type EncryptableVolumePlugin interface {
+	SupportsEncryption() bool
+}
+
+// SupportsEncryption returns whether plugin can provision encrypted volumes.
+func SupportsEncryption(plugin VolumePlugin) bool {
+	p, ok := plugin.(EncryptableVolumePlugin)
+	return ok && p.SupportsEncryption()
+}
+
+// EncryptionParameters are the storage class parameters about encrypting the volumes provisioned for it.
+type EncryptionParameters struct {
+	// Encrypted is whether the volume is encrypted at rest.
+	Encrypted bool
+	// KMSKeyID is the key the storage provider encrypts the volume with, or empty for its default key.
+	KMSKeyID string
+	// Secret is the namespace/name of the secret with the key of a volume encrypted by the node, or empty
+	// if the volume is encrypted by its storage provider.
+	Secret string
+}
+
+// Parse sets the field of e for the storage class parameter key.  It returns false if key is not an
+// encryption parameter, so that provisioners can go on with their own parameters.
+func (e *EncryptionParameters) Parse(key, value string) (bool, error) {
+	switch key {
+	case EncryptedParameter:
+		encrypted, err := strconv.ParseBool(value)
+		if err != nil {
+			return true, err
+		}
+		e.Encrypted = encrypted
+	case KMSKeyIDParameter:
+		e.KMSKeyID = value
+	case EncryptionSecretParameter:
+		if parts := strings.Split(value, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return true, fmt.Errorf("expected namespace/name")
+		}
+		e.Secret = value
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Validate checks that the parameters are consistent once all of them were parsed.
+func (e *EncryptionParameters) Validate() error {
+	if !e.Encrypted && (e.KMSKeyID != "" || e.Secret != "") {
+		return fmt.Errorf("%s and %s require %s=true", KMSKeyIDParameter, EncryptionSecretParameter, EncryptedParameter)
+	}
+	if e.KMSKeyID != "" && e.Secret != "" {
+		return fmt.Errorf("%s and %s are mutually exclusive", KMSKeyIDParameter, EncryptionSecretParameter)
+	}
+	return nil
+}
+
+// SetEncryptionSecret records on pv the secret of the key the node encrypts the volume with, if any.
+func SetEncryptionSecret(pv *api.PersistentVolume, e EncryptionParameters) {
+	if e.Secret == "" {
+		return
+	}
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[EncryptionSecretAnnotation] = e.Secret
+}
+
+// GetEncryptionKey returns the key the node encrypts pv with, read from the secret in its
+// EncryptionSecretAnnotation, or nil if the node does not encrypt pv.
+func GetEncryptionKey(host VolumeHost, pv *api.PersistentVolume) ([]byte, error) {
+	if pv == nil || pv.Annotations[EncryptionSecretAnnotation] == "" {
+		return nil, nil
+	}
+	ref := pv.Annotations[EncryptionSecretAnnotation]
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid encryption secret %q of volume %s, expected namespace/name", ref, pv.Name)
+	}
+	kubeClient := host.GetKubeClient()
+	if kubeClient == nil {
+		return nil, fmt.Errorf("cannot get kube client to read the encryption secret of volume %s", pv.Name)
+	}
+	secret, err := kubeClient.Secrets(parts[0]).Get(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("error getting the encryption secret %s of volume %s: %v", ref, pv.Name, err)
+	}
+	key, found := secret.Data[EncryptionKeySecretKey]
+	if !found || len(key) == 0 {
+		return nil, fmt.Errorf("encryption secret %s of volume %s has no %q", ref, pv.Name, EncryptionKeySecretKey)
+	}
+	return key, nil
+}
+
+// OpenEncryptedDevice sets up a dm-crypt mapping called name of the LUKS device at devicePath, unlocked
+// with key, and returns the path of the decrypted device, to be used in place of devicePath.  Blank
+// devices are formatted with LUKS first; devices holding anything else are refused, so that data is never
+// formatted over.  It is meant to be called by SetUpDevice, which is the only one to mount the device.
+func OpenEncryptedDevice(runner exec.Interface, devicePath, name string, key []byte) (string, error) {
+	mapperPath := path.Join(deviceMapperDir, name)
+	if _, err := os.Stat(mapperPath); err == nil {
+		return mapperPath, nil
+	}
+	format, err := (&DeviceProber{Runner: runner}).GetDiskFormat(devicePath)
+	if err != nil {
+		return "", err
+	}
+	if format != "" && format != luksFormat {
+		return "", &ErrFSTypeMismatch{Device: devicePath, Expected: luksFormat, Actual: format}
+	}
+
+	// The key is passed on stdin, so that it is never written to disk.
+	if format == "" {
+		glog.Infof("Formatting blank device %s with LUKS", devicePath)
+		cmd := runner.Command("cryptsetup", "luksFormat", "--batch-mode", "--key-file=-", devicePath)
+		cmd.SetStdin(bytes.NewReader(key))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to format %s with LUKS: %v, output: %q", devicePath, err, string(output))
+		}
+	}
+	cmd := runner.Command("cryptsetup", "luksOpen", "--key-file=-", devicePath, name)
+	cmd.SetStdin(bytes.NewReader(key))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to open LUKS device %s: %v, output: %q", devicePath, err, string(output))
+	}
+	return mapperPath, nil
+}
+
+// CloseEncryptedDevice removes the dm-crypt mapping called name set up by OpenEncryptedDevice.  It is
+// meant to be called by TearDownDevice, before the underlying device is detached.
+func CloseEncryptedDevice(runner exec.Interface, name string) error {
+	if _, err := os.Stat(path.Join(deviceMapperDir, name)); os.IsNotExist(err) {
+		return nil
+	}
+	if output, err := runner.Command("cryptsetup", "luksClose", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to close LUKS device %s: %v, output: %q", name, err, string(output))
+	}
+	return nil
+}
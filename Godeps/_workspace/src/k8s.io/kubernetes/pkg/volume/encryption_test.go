@@ -0,0 +1,122 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+func TestEncryptionParameters(t *testing.T) {
+	var e EncryptionParameters
+	for k, v := range map[string]string{"encrypted": "true", "kmsKeyId": "key-1", "type": "gp2"} {
+		found, err := e.Parse(k, v)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %v", k, err)
+		}
+		if found != (k != "type") {
+			t.Errorf("Unexpected found=%v for %s", found, k)
+		}
+	}
+	if !e.Encrypted || e.KMSKeyID != "key-1" || e.Validate() != nil {
+		t.Errorf("Unexpected parameters %+v", e)
+	}
+	if _, err := e.Parse(EncryptionSecretParameter, "no-namespace"); err == nil {
+		t.Errorf("Expected an error for a secret without namespace")
+	}
+	if _, err := e.Parse(EncryptionSecretParameter, "ns/luks"); err != nil || e.Validate() == nil {
+		t.Errorf("Expected a KMS key and a secret to be mutually exclusive, got %v", err)
+	}
+	if err := (&EncryptionParameters{KMSKeyID: "key-1"}).Validate(); err == nil {
+		t.Errorf("Expected a KMS key to require encryption")
+	}
+}
+
+func TestGetEncryptionKey(t *testing.T) {
+	secret := &api.Secret{
+		ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "luks"},
+		Data:       map[string][]byte{EncryptionKeySecretKey: []byte("passphrase")},
+	}
+	host := NewFakeVolumeHost("/tmp/fake", testclient.NewSimpleFake(secret), nil)
+	pv := &api.PersistentVolume{}
+	if key, err := GetEncryptionKey(host, pv); key != nil || err != nil {
+		t.Errorf("Expected no key for a volume not encrypted by the node, got %q, %v", key, err)
+	}
+	SetEncryptionSecret(pv, EncryptionParameters{Encrypted: true, Secret: "ns/luks"})
+	key, err := GetEncryptionKey(host, pv)
+	if err != nil || string(key) != "passphrase" {
+		t.Errorf("Expected the key of the secret, got %q, %v", key, err)
+	}
+}
+
+func TestOpenEncryptedDevice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dm-crypt")
+	if err != nil {
+		t.Fatalf("Can't make a tmp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	saved := deviceMapperDir
+	defer func() { deviceMapperDir = saved }()
+	deviceMapperDir = dir
+
+	blank := fakeCommand{err: &exec.FakeExitError{Status: blkidNotFoundExitCode}}
+	var argv [][]string
+	var formatKey, openKey string
+	runner := newFakeRunner([]fakeCommand{blank, {stdin: &formatKey}, {stdin: &openKey}}, &argv)
+	mapperPath, err := OpenEncryptedDevice(runner, "/dev/foo", "pv-1", []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("OpenEncryptedDevice() failed: %v", err)
+	}
+	if mapperPath != path.Join(dir, "pv-1") {
+		t.Errorf("Unexpected decrypted device %s", mapperPath)
+	}
+	if len(argv) != 3 || argv[1][1] != "luksFormat" || argv[2][1] != "luksOpen" || argv[2][len(argv[2])-1] != "pv-1" {
+		t.Fatalf("Expected a blank device to be formatted and opened, got %v", argv)
+	}
+	if argv[1][3] != "--key-file=-" || argv[2][2] != "--key-file=-" || formatKey != "passphrase" || openKey != "passphrase" {
+		t.Errorf("Expected the key to be passed on stdin, got %v with %q and %q", argv, formatKey, openKey)
+	}
+
+	// A device with a filesystem is never formatted over.
+	argv = nil
+	runner = newFakeRunner([]fakeCommand{{output: "TYPE=ext4\n"}}, &argv)
+	if _, err := OpenEncryptedDevice(runner, "/dev/foo", "pv-2", []byte("passphrase")); err == nil {
+		t.Errorf("Expected an error for a device with a filesystem")
+	}
+	if len(argv) != 1 {
+		t.Errorf("Unexpected commands %v", argv)
+	}
+
+	// Existing mappings are reused, and closed on teardown.
+	if err := ioutil.WriteFile(path.Join(dir, "pv-1"), nil, 0600); err != nil {
+		t.Fatalf("Can't fake the mapping: %v", err)
+	}
+	argv = nil
+	runner = newFakeRunner([]fakeCommand{{}}, &argv)
+	if _, err := OpenEncryptedDevice(runner, "/dev/foo", "pv-1", []byte("passphrase")); err != nil || len(argv) != 0 {
+		t.Errorf("Expected the existing mapping to be reused, got %v, %v", argv, err)
+	}
+	if err := CloseEncryptedDevice(runner, "pv-1"); err != nil || len(argv) != 1 || argv[0][1] != "luksClose" {
+		t.Errorf("Expected the mapping to be closed, got %v, %v", argv, err)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// BackendResourceChecker is implemented by Cleaners of ephemeral volumes whose
+// TearDown also deletes the resource backing the volume (e.g. a cloud disk
+// created for the lifetime of a pod).
+type BackendResourceChecker interface {
+	// BackendID returns the identifier of the backing resource.
+	BackendID() string
+	// BackendResourceExists looks up the backing resource by ID and
+	// reports whether it still exists.
+	BackendResourceExists(id string) (bool, error)
+}
+
+// ErrResourceLeaked is returned when the backing resource of an ephemeral
+// volume still exists after TearDown reported success.  Callers may retry the
+// teardown or alert on it.
+type ErrResourceLeaked struct {
+	// Path is the path of the volume that was torn down.
+	Path string
+	// BackendID is the identifier of the resource that was leaked.
+	BackendID string
+}
+
+func (e *ErrResourceLeaked) Error() string {
+	return fmt.Sprintf("backend resource %q of volume %s still exists after teardown", e.BackendID, e.Path)
+}
+
+// VerifyTearDown confirms that the backing resource of an ephemeral volume is
+// gone after cleaner.TearDown() returned.  PersistentVolumes outlive the pod
+// and are not checked, nor are Cleaners which do not implement
+// BackendResourceChecker.
+func VerifyTearDown(spec *Spec, cleaner Cleaner) error {
+	if spec.PersistentVolume != nil {
+		return nil
+	}
+	checker, ok := cleaner.(BackendResourceChecker)
+	if !ok {
+		return nil
+	}
+	id := checker.BackendID()
+	exists, err := checker.BackendResourceExists(id)
+	if err != nil {
+		return fmt.Errorf("failed to look up backend resource %q of volume %s: %v", id, cleaner.GetPath(), err)
+	}
+	if exists {
+		return &ErrResourceLeaked{Path: cleaner.GetPath(), BackendID: id}
+	}
+	return nil
+}
+
+// TearDownEphemeralVolume calls TearDownVolume and then VerifyTearDown.
+func TearDownEphemeralVolume(spec *Spec, cleaner Cleaner) error {
+	if err := TearDownVolume(cleaner); err != nil {
+		return err
+	}
+	return VerifyTearDown(spec, cleaner)
+}
+
+// EphemeralVolume is a volume provisioned for a single pod by an
+// EphemeralProvisioner.
+type EphemeralVolume struct {
+	PodUID types.UID
+	// Spec is the spec of the PersistentVolume that was provisioned, named
+	// by EphemeralVolumeName.  It is never saved in the API, so callers
+	// must keep it to delete the volume.
+	Spec    *Spec
+	Builder Builder
+}
+
+// EphemeralProvisioner provisions volumes that live as long as the pod using
+// them, e.g. inline volumes backed by a CSI driver or a secret store.
+type EphemeralProvisioner interface {
+	// SetUpEphemeral provisions the volume volName of pod and sets it up.
+	// A volume that was provisioned but can't be set up is deleted again.
+	SetUpEphemeral(pod *api.Pod, volName string, options VolumeOptions) (*EphemeralVolume, error)
+	// TearDownEphemeral tears the volume down and then deletes it.  The
+	// volume is kept if the teardown fails, so that it can be retried.
+	TearDownEphemeral(ephemeral *EphemeralVolume) error
+}
+
+// EphemeralVolumeName returns the name of the volume provisioned for the
+// volume volName of the pod.  Pod UIDs are unique, so volumes of pods with
+// the same name, or of a pod and its replacement, never collide.
+func EphemeralVolumeName(podUID types.UID, volName string) string {
+	return fmt.Sprintf("ephemeral-%s-%s", podUID, volName)
+}
+
+// NewEphemeralProvisioner returns an EphemeralProvisioner provisioning and
+// deleting volumes with plugin, which must be provisionable and deletable.
+func NewEphemeralProvisioner(plugin VolumePlugin) (EphemeralProvisioner, error) {
+	provisionable, ok := plugin.(ProvisionableVolumePlugin)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s can't provision volumes", plugin.Name())
+	}
+	deletable, ok := plugin.(DeletableVolumePlugin)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s can't delete volumes", plugin.Name())
+	}
+	return &ephemeralProvisioner{provisionable: provisionable, deletable: deletable}, nil
+}
+
+type ephemeralProvisioner struct {
+	provisionable ProvisionableVolumePlugin
+	deletable     DeletableVolumePlugin
+}
+
+var _ EphemeralProvisioner = &ephemeralProvisioner{}
+
+func (p *ephemeralProvisioner) SetUpEphemeral(pod *api.Pod, volName string, options VolumeOptions) (*EphemeralVolume, error) {
+	provisioner, err := p.provisionable.NewProvisioner(options)
+	if err != nil {
+		return nil, err
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		return nil, err
+	}
+	pv.GenerateName = ""
+	pv.Name = EphemeralVolumeName(pod.UID, volName)
+	if err := ProvisionVolume(provisioner, pv); err != nil {
+		return nil, fmt.Errorf("failed to provision ephemeral volume %s: %v", pv.Name, err)
+	}
+
+	spec := NewSpecFromPersistentVolume(pv, false)
+	builder, err := p.provisionable.NewBuilder(spec, pod, options)
+	if err == nil {
+		err = SetUpVolume(builder)
+	}
+	if err != nil {
+		if deleteErr := p.delete(spec); deleteErr != nil {
+			glog.Errorf("Failed to delete ephemeral volume %s after failing to set it up: %v", pv.Name, deleteErr)
+		}
+		return nil, fmt.Errorf("failed to set up ephemeral volume %s: %v", pv.Name, err)
+	}
+	return &EphemeralVolume{PodUID: pod.UID, Spec: spec, Builder: builder}, nil
+}
+
+func (p *ephemeralProvisioner) TearDownEphemeral(ephemeral *EphemeralVolume) error {
+	cleaner, err := p.provisionable.NewCleaner(ephemeral.Spec.Name(), ephemeral.PodUID)
+	if err != nil {
+		return err
+	}
+	if err := TearDownVolume(cleaner); err != nil {
+		return fmt.Errorf("failed to tear down ephemeral volume %s: %v", ephemeral.Spec.Name(), err)
+	}
+	return p.delete(ephemeral.Spec)
+}
+
+func (p *ephemeralProvisioner) delete(spec *Spec) error {
+	deleter, err := p.deletable.NewDeleter(spec)
+	if err != nil {
+		return err
+	}
+	return DeleteVolume(deleter)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// fakeBackedCleaner is a Cleaner whose TearDown is supposed to delete a
+// backend resource.
+type fakeBackedCleaner struct {
+	path            string
+	id              string
+	exists          bool
+	lingers         bool
+	tearDownCalled  bool
+	existenceChecks int
+}
+
+func (c *fakeBackedCleaner) GetPath() string {
+	return c.path
+}
+
+func (c *fakeBackedCleaner) TearDown() error {
+	return c.TearDownAt(c.path)
+}
+
+func (c *fakeBackedCleaner) TearDownAt(dir string) error {
+	c.tearDownCalled = true
+	if !c.lingers {
+		c.exists = false
+	}
+	return nil
+}
+
+func (c *fakeBackedCleaner) BackendID() string {
+	return c.id
+}
+
+func (c *fakeBackedCleaner) BackendResourceExists(id string) (bool, error) {
+	c.existenceChecks++
+	return c.exists && id == c.id, nil
+}
+
+func TestTearDownEphemeralVolumeLeaked(t *testing.T) {
+	spec := NewSpecFromVolume(&api.Volume{Name: "vol1"})
+	cleaner := &fakeBackedCleaner{path: "/vol1", id: "disk-1", exists: true, lingers: true}
+
+	err := TearDownEphemeralVolume(spec, cleaner)
+	leaked, ok := err.(*ErrResourceLeaked)
+	if !ok {
+		t.Fatalf("Expected ErrResourceLeaked, got %v", err)
+	}
+	if leaked.BackendID != "disk-1" || leaked.Path != "/vol1" {
+		t.Errorf("Unexpected leak details: %+v", leaked)
+	}
+}
+
+func TestTearDownEphemeralVolumeFreed(t *testing.T) {
+	spec := NewSpecFromVolume(&api.Volume{Name: "vol1"})
+	cleaner := &fakeBackedCleaner{path: "/vol1", id: "disk-1", exists: true}
+
+	if err := TearDownEphemeralVolume(spec, cleaner); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+	if !cleaner.tearDownCalled {
+		t.Errorf("Expected TearDown to be called")
+	}
+	if cleaner.existenceChecks != 1 {
+		t.Errorf("Expected backend to be checked once, got %d", cleaner.existenceChecks)
+	}
+}
+
+func TestVerifyTearDownSkipsPersistentVolumes(t *testing.T) {
+	spec := NewSpecFromPersistentVolume(&api.PersistentVolume{ObjectMeta: api.ObjectMeta{Name: "pv1"}}, false)
+	cleaner := &fakeBackedCleaner{path: "/pv1", id: "disk-1", exists: true, lingers: true}
+
+	if err := VerifyTearDown(spec, cleaner); err != nil {
+		t.Errorf("Expected persistent volume to skip verification, got %v", err)
+	}
+	if cleaner.existenceChecks != 0 {
+		t.Errorf("Expected no backend lookups for persistent volumes, got %d", cleaner.existenceChecks)
+	}
+}
+
+func newEphemeralProvisioner(t *testing.T, plugin *FakeVolumePlugin) (EphemeralProvisioner, string) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "ephemeralTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	plugin.Init(NewFakeVolumeHost(tmpDir, nil, nil))
+	provisioner, err := NewEphemeralProvisioner(plugin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return provisioner, tmpDir
+}
+
+func TestEphemeralProvisionerLifecycle(t *testing.T) {
+	plugin := &FakeVolumePlugin{PluginName: "fake-ephemeral"}
+	provisioner, tmpDir := newEphemeralProvisioner(t, plugin)
+	defer os.RemoveAll(tmpDir)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "pod1", UID: types.UID("poduid")}}
+
+	ephemeral, err := provisioner.SetUpEphemeral(pod, "scratch", VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if name := ephemeral.Spec.Name(); name != "ephemeral-poduid-scratch" {
+		t.Errorf("Expected the volume to be named after the pod UID, got %s", name)
+	}
+	if len(plugin.Provisioners) != 1 || plugin.Provisioners[0].ProvisionCallCount != 1 {
+		t.Errorf("Expected the volume to be provisioned once")
+	}
+	if ephemeral.Builder.(*FakeVolume).GetSetUpCallCount() != 1 {
+		t.Errorf("Expected the volume to be set up once")
+	}
+	if len(plugin.Deleters) != 0 {
+		t.Errorf("Expected the volume not to be deleted before teardown")
+	}
+
+	if err := provisioner.TearDownEphemeral(ephemeral); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if len(plugin.Cleaners) != 1 || plugin.Cleaners[0].GetTearDownCallCount() != 1 {
+		t.Errorf("Expected the volume to be torn down once")
+	}
+	if len(plugin.Deleters) != 1 || plugin.Deleters[0].GetDeleteCallCount() != 1 {
+		t.Errorf("Expected the volume to be deleted once")
+	}
+}
+
+func TestEphemeralProvisionerDeletesOnSetUpFailure(t *testing.T) {
+	plugin := &FakeVolumePlugin{PluginName: "fake-ephemeral", SetUpErr: fmt.Errorf("mount failed")}
+	provisioner, tmpDir := newEphemeralProvisioner(t, plugin)
+	defer os.RemoveAll(tmpDir)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "pod1", UID: types.UID("poduid")}}
+
+	if _, err := provisioner.SetUpEphemeral(pod, "scratch", VolumeOptions{}); err == nil {
+		t.Fatalf("Expected the setup to fail")
+	}
+	if len(plugin.Deleters) != 1 || plugin.Deleters[0].GetDeleteCallCount() != 1 {
+		t.Errorf("Expected the provisioned volume to be deleted")
+	}
+}
+
+func TestEphemeralProvisionerKeepsVolumeOnTearDownFailure(t *testing.T) {
+	plugin := &FakeVolumePlugin{PluginName: "fake-ephemeral"}
+	provisioner, tmpDir := newEphemeralProvisioner(t, plugin)
+	defer os.RemoveAll(tmpDir)
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "pod1", UID: types.UID("poduid")}}
+
+	ephemeral, err := provisioner.SetUpEphemeral(pod, "scratch", VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	plugin.TearDownErr = fmt.Errorf("device busy")
+	if err := provisioner.TearDownEphemeral(ephemeral); err == nil {
+		t.Fatalf("Expected the teardown to fail")
+	}
+	if len(plugin.Deleters) != 0 {
+		t.Errorf("Expected the volume to be kept while it is set up")
+	}
+}
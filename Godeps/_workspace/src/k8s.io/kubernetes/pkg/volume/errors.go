@@ -0,0 +1,153 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// Plugins return the errors below from Provision, Delete, Recycle and SetUp
+// so that their callers can tell failures worth retrying from those that
+// need a change of configuration.  Errors of other types are permanent.
+
+// transientError is an infrastructure failure that goes away by itself,
+// e.g. a timeout or rate limit of a cloud provider.
+type transientError struct {
+	msg string
+}
+
+func (e *transientError) Error() string {
+	return e.msg
+}
+
+// NewTransientError returns an error with the given message that
+// IsTransient reports as transient.
+func NewTransientError(msg string) error {
+	return &transientError{msg: msg}
+}
+
+// deletedVolumeInUseError is returned by a Deleter when the volume can't be
+// deleted yet because it is still attached or otherwise in use.
+type deletedVolumeInUseError struct {
+	msg string
+}
+
+func (e *deletedVolumeInUseError) Error() string {
+	return e.msg
+}
+
+// NewDeletedVolumeInUseError returns an error with the given message for a
+// volume that is still in use and can be deleted once it is released.
+func NewDeletedVolumeInUseError(msg string) error {
+	return &deletedVolumeInUseError{msg: msg}
+}
+
+// IsDeletedVolumeInUse reports whether err was created by
+// NewDeletedVolumeInUseError.
+func IsDeletedVolumeInUse(err error) bool {
+	_, ok := Cause(err).(*deletedVolumeInUseError)
+	return ok
+}
+
+// IsTransient reports whether the operation that returned err may succeed if
+// retried later without any change, in which case callers should back off
+// and retry instead of failing the volume.  Volumes that are still in use
+// and operations refused because their volume is busy or backing off are
+// transient too.
+func IsTransient(err error) bool {
+	switch Cause(err).(type) {
+	case *transientError, *deletedVolumeInUseError, *ErrOperationPending, *ErrOperationBackoff:
+		return true
+	}
+	return false
+}
+
+// Error is an error of an operation on a volume which says what volume it
+// belongs to, as the error of e.g. SetUpAt alone rarely does.  Fields that
+// don't apply to the operation, like the pod of a Delete, are empty.
+type Error struct {
+	Operation OperationType
+	Plugin    string
+	// VolumeName is the name of the volume in the pod, or of the
+	// PersistentVolume.
+	VolumeName string
+	PodUID     types.UID
+	// Err is the error of the operation.
+	Err error
+}
+
+func (e *Error) Error() string {
+	identity := []string{}
+	if e.Plugin != "" {
+		identity = append(identity, "plugin "+e.Plugin)
+	}
+	if e.PodUID != "" {
+		identity = append(identity, "pod "+string(e.PodUID))
+	}
+	desc := fmt.Sprintf("%s of volume %q", e.Operation, e.VolumeName)
+	if len(identity) > 0 {
+		desc += " (" + strings.Join(identity, ", ") + ")"
+	}
+	return fmt.Sprintf("%s failed: %v", desc, e.Err)
+}
+
+// Unwrap returns the error of the operation.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError wraps err, the error of operation op on a volume, in an Error,
+// or returns nil if err is nil.  If err is an Error already, the result
+// has its fields, with empty ones filled in from the arguments.
+func NewError(op OperationType, plugin, volumeName string, podUID types.UID, err error) error {
+	if err == nil {
+		return nil
+	}
+	wrapped := &Error{Operation: op, Plugin: plugin, VolumeName: volumeName, PodUID: podUID, Err: err}
+	if inner, ok := err.(*Error); ok {
+		merged := *inner
+		if merged.Operation == "" {
+			merged.Operation = op
+		}
+		if merged.Plugin == "" {
+			merged.Plugin = plugin
+		}
+		if merged.VolumeName == "" {
+			merged.VolumeName = volumeName
+		}
+		if merged.PodUID == "" {
+			merged.PodUID = podUID
+		}
+		wrapped = &merged
+	}
+	return wrapped
+}
+
+// Cause returns the error an Error wraps, or err itself if it is not an
+// Error.  Callers inspect the cause to tell kinds of errors apart.
+func Cause(err error) error {
+	for {
+		wrapped, ok := err.(*Error)
+		if !ok {
+			return err
+		}
+		err = wrapped.Err
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+		inUse     bool
+	}{
+		{NewTransientError("timeout"), true, false},
+		{NewDeletedVolumeInUseError("attached"), true, true},
+		{&ErrOperationPending{VolumeName: "vol1"}, true, false},
+		{&ErrOperationBackoff{VolumeName: "vol1", RetryAfter: time.Now()}, true, false},
+		{errors.New("bad configuration"), false, false},
+		{nil, false, false},
+	}
+	for _, test := range tests {
+		if IsTransient(test.err) != test.transient {
+			t.Errorf("Expected IsTransient(%v) to be %v", test.err, test.transient)
+		}
+		if IsDeletedVolumeInUse(test.err) != test.inUse {
+			t.Errorf("Expected IsDeletedVolumeInUse(%v) to be %v", test.err, test.inUse)
+		}
+	}
+	if msg := NewTransientError("timeout").Error(); msg != "timeout" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestError(t *testing.T) {
+	if NewError(OperationSetUp, "kubernetes.io/nfs", "data", "uid1", nil) != nil {
+		t.Errorf("Expected no error for a nil error")
+	}
+
+	cause := NewDeletedVolumeInUseError("attached")
+	err := NewError(OperationDelete, "kubernetes.io/gce-pd", "pv1", "", cause)
+	if expected := `delete of volume "pv1" (plugin kubernetes.io/gce-pd) failed: attached`; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if Cause(err) != cause {
+		t.Errorf("Expected the cause %v, got %v", cause, Cause(err))
+	}
+	if !IsTransient(err) || !IsDeletedVolumeInUse(err) {
+		t.Errorf("Expected the wrapped error to keep the kind of its cause")
+	}
+
+	// Wrapping again fills in what's missing instead of nesting.
+	rewrapped := NewError(OperationSetUp, "other", "data", "uid1", err).(*Error)
+	expected := Error{Operation: OperationDelete, Plugin: "kubernetes.io/gce-pd", VolumeName: "pv1", PodUID: "uid1", Err: cause}
+	if *rewrapped != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *rewrapped)
+	}
+}
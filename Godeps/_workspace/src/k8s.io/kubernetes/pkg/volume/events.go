@@ -0,0 +1,47 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// Reasons of the events VolumeHosts record for failed volume operations.
+const (
+	// ProvisioningFailed is recorded on a PersistentVolumeClaim whose
+	// volume could not be provisioned.
+	ProvisioningFailed = "ProvisioningFailed"
+	// RecyclerPodError is recorded on a PersistentVolume which could not be
+	// recycled, usually because its recycler pod failed.
+	RecyclerPodError = "RecyclerPodError"
+	// VolumeDeleteFailed is recorded on a PersistentVolume whose storage
+	// could not be deleted.
+	VolumeDeleteFailed = "VolumeDeleteFailed"
+)
+
+// RecordOperationFailure records an event with reason and err on obj, the
+// PersistentVolume or PersistentVolumeClaim an operation failed for, if
+// host has an event recorder.
+func RecordOperationFailure(host VolumeHost, obj runtime.Object, reason string, err error) {
+	recorder := host.GetEventRecorder()
+	if recorder == nil {
+		glog.V(4).Infof("No event recorder for %s: %v", reason, err)
+		return
+	}
+	recorder.Eventf(obj, reason, "%v", err)
+}
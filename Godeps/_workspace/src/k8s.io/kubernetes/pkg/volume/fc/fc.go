@@ -19,11 +19,11 @@ package fc
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -69,6 +69,17 @@ func (plugin *fcPlugin) CanSupport(spec *volume.Spec) bool {
 	return false
 }
 
+func (plugin *fcPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.FC == nil {
+		return "", fmt.Errorf("spec does not reference a fibre channel volume")
+	}
+	if source.FC.Lun == nil {
+		return "", fmt.Errorf("fibre channel volume %s has no lun", spec.Name())
+	}
+	return fmt.Sprintf("%s:%d", strings.Join(source.FC.TargetWWNs, ","), *source.FC.Lun), nil
+}
+
 func (plugin *fcPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -78,10 +89,10 @@ func (plugin *fcPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 
 func (plugin *fcPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
 	// Inject real implementations here, test through the internal function.
-	return plugin.newBuilderInternal(spec, pod.UID, &FCUtil{}, plugin.host.GetMounter())
+	return plugin.newBuilderInternal(spec, pod.UID, &FCUtil{}, plugin.host.GetMounter(), exec.New())
 }
 
-func (plugin *fcPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, manager diskManager, mounter mount.Interface) (volume.Builder, error) {
+func (plugin *fcPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, manager diskManager, mounter mount.Interface, exe exec.Interface) (volume.Builder, error) {
 	// fc volumes used directly in a pod have a ReadOnly flag set by the pod author.
 	// fc volumes used as a PersistentVolume gets the ReadOnly flag indirectly through the persistent-claim volume used to mount the PV
 	var readOnly bool
@@ -100,19 +111,21 @@ func (plugin *fcPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID,
 
 	lun := strconv.Itoa(*fc.Lun)
 
-	return &fcDiskBuilder{
+	builder := &fcDiskBuilder{
 		fcDisk: &fcDisk{
 			podUID:  podUID,
 			volName: spec.Name(),
 			wwns:    fc.TargetWWNs,
 			lun:     lun,
 			manager: manager,
-			mounter: &mount.SafeFormatAndMount{mounter, exec.New()},
+			mounter: &volume.SafeFormatAndMount{Interface: mounter, Runner: exe},
 			io:      &osIOHandler{},
 			plugin:  plugin},
 		fsType:   fc.FSType,
 		readOnly: readOnly,
-	}, nil
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
 }
 
 func (plugin *fcPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -153,20 +166,18 @@ type fcDisk struct {
 func (fc *fcDisk) GetPath() string {
 	name := fcPluginName
 	// safe to use PodVolumeDir now: volume teardown occurs before pod is cleaned up
-	return fc.plugin.host.GetPodVolumeDir(fc.podUID, util.EscapeQualifiedNameForDisk(name), fc.volName)
+	return volume.NewDirectoryLayout(fc.plugin.host).PodVolumeDir(fc.podUID, name, fc.volName)
 }
 
 type fcDiskBuilder struct {
 	*fcDisk
+	volume.MetricsProvider
 	readOnly bool
 	fsType   string
 }
 
 var _ volume.Builder = &fcDiskBuilder{}
-
-func (_ *fcDiskBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
+var _ volume.DiskBuilder = &fcDiskBuilder{}
 
 func (b *fcDiskBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
@@ -187,12 +198,17 @@ type fcDiskCleaner struct {
 
 var _ volume.Cleaner = &fcDiskCleaner{}
 
-func (b *fcDiskBuilder) IsReadOnly() bool {
-	return b.readOnly
+func (b *fcDiskBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *fcDiskBuilder) SupportsSELinux() bool {
-	return true
+func (b *fcDiskBuilder) FSType() string {
+	return b.fsType
 }
 
 // Unmounts the bind mount, and detaches the disk only if the disk
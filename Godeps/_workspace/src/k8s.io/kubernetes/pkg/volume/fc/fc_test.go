@@ -24,6 +24,7 @@ import (
 	"k8s.io/kubernetes/pkg/api/testapi"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
@@ -66,6 +67,24 @@ func contains(modes []api.PersistentVolumeAccessMode, mode api.PersistentVolumeA
 	return false
 }
 
+// fakeFormattedExec answers blkid as if the device held a filesystem of
+// type fsType, and lets fsck pass.
+func fakeFormattedExec(fsType string) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for _, output := range []string{"TYPE=" + fsType + "\n", ""} {
+		output := output
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			fakeCmd := &exec.FakeCmd{
+				CombinedOutputScript: []exec.FakeCombinedOutputAction{
+					func() ([]byte, error) { return []byte(output), nil },
+				},
+			}
+			return exec.InitFakeCmd(fakeCmd, cmd, args...)
+		})
+	}
+	return fake
+}
+
 type fakeDiskManager struct {
 	attachCalled bool
 	detachCalled bool
@@ -82,7 +101,9 @@ func (fake *fakeDiskManager) AttachDisk(b fcDiskBuilder) error {
 	}
 	// Simulate the global mount so that the fakeMounter returns the
 	// expected number of mounts for the attached disk.
-	b.mounter.Mount(globalPath, globalPath, b.fsType, nil)
+	if err := b.mounter.Mount(globalPath, globalPath, b.fsType, nil); err != nil {
+		return err
+	}
 
 	fake.attachCalled = true
 	return nil
@@ -108,7 +129,7 @@ func doTestPlugin(t *testing.T, spec *volume.Spec) {
 	}
 	fakeManager := &fakeDiskManager{}
 	fakeMounter := &mount.FakeMounter{}
-	builder, err := plug.(*fcPlugin).newBuilderInternal(spec, types.UID("poduid"), fakeManager, fakeMounter)
+	builder, err := plug.(*fcPlugin).newBuilderInternal(spec, types.UID("poduid"), fakeManager, fakeMounter, fakeFormattedExec("ext4"))
 	if err != nil {
 		t.Errorf("Failed to make a new Builder: %v", err)
 	}
@@ -246,7 +267,44 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestFSTypeMismatch(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/fc")
+	if err != nil {
+		t.Errorf("Can't find the plugin by name")
+	}
+	lun := 0
+	vol := &api.Volume{
+		Name: "vol1",
+		VolumeSource: api.VolumeSource{
+			FC: &api.FCVolumeSource{
+				TargetWWNs: []string{"some_wwn"},
+				FSType:     "xfs",
+				Lun:        &lun,
+			},
+		},
+	}
+	fakeMounter := &mount.FakeMounter{}
+	builder, err := plug.(*fcPlugin).newBuilderInternal(volume.NewSpecFromVolume(vol), types.UID("poduid"), &fakeDiskManager{}, fakeMounter, fakeFormattedExec("ext4"))
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if fsType := builder.(volume.DiskBuilder).FSType(); fsType != "xfs" {
+		t.Errorf("Expected the fsType of the volume source, got %q", fsType)
+	}
+
+	err = builder.SetUp()
+	if _, ok := err.(*volume.ErrFSTypeMismatch); !ok {
+		t.Errorf("Expected an ErrFSTypeMismatch for an ext4 device, got %v", err)
+	}
+	if len(fakeMounter.MountPoints) != 0 {
+		t.Errorf("Expected the device not to be mounted, got %v", fakeMounter.MountPoints)
 	}
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package flexvolume contains the internal representation of volumes set up
+// by driver binaries that storage vendors install on the host, so that they
+// can support their storage without changes to this package.
+//
+// Drivers are looked up in a plugin directory: the driver "vendor/driver"
+// is the executable "driver" in the subdirectory "vendor~driver".  A driver
+// is called with the command as first argument and prints its reply as a
+// JSON object on stdout:
+//
+//	{"status": "Success", "message": "...", "device": "/dev/xvdf"}
+//
+// where status is one of "Success", "Failure" and "Not supported".  The
+// commands are:
+//
+//	init
+//	getvolumename <json options>
+//	attach <json options> <node name>
+//	waitforattach <device> <timeout in seconds>
+//	detach <device> <node name>
+//	mount <mount dir> <device> <json options>
+//	unmount <mount dir>
+//
+// init is called once when the driver is probed; drivers that need their
+// volumes attached to the node before mounting them reply with
+// "capabilities": {"attach": true}.  Only those are called with attach,
+// waitforattach and detach, and mount gets the device attach replied with.
+// The host mounts the device itself if mount is not supported, and unmounts
+// it if unmount is not.  getvolumename replies with the "volumeName" of a
+// volume that is the same whichever pod uses it; the name of the volume in
+// the pod is used for drivers that don't support it.
+//
+// The json options are the options of the volume source and the keys
+// kubernetes.io/fsType and kubernetes.io/readwrite, plus kubernetes.io/pod.*
+// and the base64 encoded data of the secret of the source as
+// kubernetes.io/secret/<key> when mounting.
+package flexvolume
@@ -0,0 +1,121 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+)
+
+// Commands of the driver protocol, see the package doc.
+const (
+	initCmd          = "init"
+	getVolumeNameCmd = "getvolumename"
+	attachCmd        = "attach"
+	waitForAttachCmd = "waitforattach"
+	detachCmd        = "detach"
+	mountCmd         = "mount"
+	unmountCmd       = "unmount"
+)
+
+// Statuses a driver replies with.
+const (
+	statusSuccess      = "Success"
+	statusFailure      = "Failure"
+	statusNotSupported = "Not supported"
+)
+
+// Keys of the options passed to drivers besides those of the volume source.
+const (
+	optionFSType       = "kubernetes.io/fsType"
+	optionReadWrite    = "kubernetes.io/readwrite"
+	optionSecretPrefix = "kubernetes.io/secret/"
+	optionPodName      = "kubernetes.io/pod.name"
+	optionPodNamespace = "kubernetes.io/pod.namespace"
+	optionPodUID       = "kubernetes.io/pod.uid"
+)
+
+// DriverStatus is the reply of a driver to a command.
+type DriverStatus struct {
+	// Status is one of Success, Failure and Not supported.
+	Status string `json:"status"`
+	// Message explains the status, e.g. why the command failed.
+	Message string `json:"message,omitempty"`
+	// Device is the device a volume was attached as, replied to attach.
+	Device string `json:"device,omitempty"`
+	// VolumeName is the name of a volume unique among the volumes of the
+	// driver, replied to getvolumename.
+	VolumeName string `json:"volumeName,omitempty"`
+	// Capabilities are the optional features of the driver, replied to init.
+	Capabilities *DriverCapabilities `json:"capabilities,omitempty"`
+}
+
+// DriverCapabilities are the optional features a driver implements.
+type DriverCapabilities struct {
+	// Attach is whether the volumes of the driver are attached to the node
+	// before they are mounted.
+	Attach bool `json:"attach"`
+}
+
+// notSupportedError is returned for commands a driver doesn't implement.
+type notSupportedError struct {
+	driver  string
+	command string
+}
+
+func (e *notSupportedError) Error() string {
+	return fmt.Sprintf("flexvolume driver %s does not support %s", e.driver, e.command)
+}
+
+func isNotSupported(err error) bool {
+	_, ok := err.(*notSupportedError)
+	return ok
+}
+
+// call runs command of the driver of plugin with args and returns its reply.
+// Replies other than Success are returned as errors.
+func (plugin *flexVolumePlugin) call(command string, args ...string) (*DriverStatus, error) {
+	cmd := plugin.runner.Command(plugin.executable, append([]string{command}, args...)...)
+	output, execErr := cmd.CombinedOutput()
+	status := &DriverStatus{}
+	if err := json.Unmarshal(output, status); err != nil {
+		if execErr != nil {
+			return nil, fmt.Errorf("flexvolume driver %s: %s failed: %v, output: %q", plugin.driverName, command, execErr, string(output))
+		}
+		return nil, fmt.Errorf("flexvolume driver %s: invalid reply to %s: %q: %v", plugin.driverName, command, string(output), err)
+	}
+	glog.V(4).Infof("Flexvolume driver %s replied to %s: %+v", plugin.driverName, command, status)
+	switch status.Status {
+	case statusSuccess:
+		return status, nil
+	case statusNotSupported:
+		return status, &notSupportedError{plugin.driverName, command}
+	default:
+		return status, fmt.Errorf("flexvolume driver %s: %s failed: %s", plugin.driverName, command, status.Message)
+	}
+}
+
+// encodeOptions returns options as the json argument of a command.
+func encodeOptions(options map[string]string) (string, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
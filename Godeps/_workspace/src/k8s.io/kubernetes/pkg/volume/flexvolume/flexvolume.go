@@ -0,0 +1,452 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// attachTimeout is how long SetUp waits for an attached volume to show up.
+const attachTimeout = 2 * time.Minute
+
+// ProbeVolumePlugins returns a plugin for each driver installed in
+// pluginDir.  Drivers that fail to initialize are left out.
+func ProbeVolumePlugins(pluginDir string) []volume.VolumePlugin {
+	return probeVolumePlugins(pluginDir, exec.New())
+}
+
+func probeVolumePlugins(pluginDir string, runner exec.Interface) []volume.VolumePlugin {
+	plugins := []volume.VolumePlugin{}
+	if pluginDir == "" {
+		return plugins
+	}
+	files, err := ioutil.ReadDir(pluginDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			glog.Errorf("Error reading volume plugin directory %s: %v", pluginDir, err)
+		}
+		return plugins
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		plugin, err := newFlexVolumePlugin(pluginDir, f.Name(), runner)
+		if err != nil {
+			glog.Errorf("Error initializing flexvolume driver in %s: %v", path.Join(pluginDir, f.Name()), err)
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// newFlexVolumePlugin initializes the driver in the directory dirName of
+// pluginDir and returns its plugin, which is attachable if the driver is.
+func newFlexVolumePlugin(pluginDir, dirName string, runner exec.Interface) (volume.VolumePlugin, error) {
+	driverName := strings.Replace(dirName, "~", "/", -1)
+	plugin := &flexVolumePlugin{
+		driverName: driverName,
+		executable: path.Join(pluginDir, dirName, path.Base(driverName)),
+		runner:     runner,
+	}
+	status, err := plugin.call(initCmd)
+	if err != nil && !isNotSupported(err) {
+		return nil, err
+	}
+	if status.Capabilities != nil && status.Capabilities.Attach {
+		plugin.attach = true
+		return &flexVolumeAttachablePlugin{plugin}, nil
+	}
+	return plugin, nil
+}
+
+// flexVolumePlugin is the plugin of one driver.
+type flexVolumePlugin struct {
+	host       volume.VolumeHost
+	driverName string
+	executable string
+	runner     exec.Interface
+	// attach is whether the driver attaches its volumes before mounting.
+	attach bool
+}
+
+var _ volume.VolumePlugin = &flexVolumePlugin{}
+var _ volume.PersistentVolumePlugin = &flexVolumePlugin{}
+
+func (plugin *flexVolumePlugin) Init(host volume.VolumeHost) {
+	plugin.host = host
+}
+
+// Name returns the name of the driver, which is namespaced like those of
+// the plugins bundled with kubernetes.
+func (plugin *flexVolumePlugin) Name() string {
+	return plugin.driverName
+}
+
+func (plugin *flexVolumePlugin) CanSupport(spec *volume.Spec) bool {
+	source := plugin.getVolumeSource(spec)
+	return source != nil && source.Driver == plugin.driverName
+}
+
+func (plugin *flexVolumePlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := plugin.getVolumeSource(spec)
+	if source == nil {
+		return "", fmt.Errorf("spec does not reference a flexvolume volume")
+	}
+	options, err := encodeOptions(plugin.driverOptions(source, spec.IsReadOnly()))
+	if err != nil {
+		return "", err
+	}
+	status, err := plugin.call(getVolumeNameCmd, options)
+	if isNotSupported(err) {
+		return spec.Name(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if status.VolumeName == "" {
+		return "", fmt.Errorf("flexvolume driver %s replied no volume name", plugin.driverName)
+	}
+	return status.VolumeName, nil
+}
+
+func (plugin *flexVolumePlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
+	return []api.PersistentVolumeAccessMode{
+		api.ReadWriteOnce,
+		api.ReadOnlyMany,
+	}
+}
+
+func (plugin *flexVolumePlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
+	source := plugin.getVolumeSource(spec)
+	readOnly := spec.IsReadOnly()
+	options := plugin.driverOptions(source, readOnly)
+	options[optionPodName] = pod.Name
+	options[optionPodNamespace] = pod.Namespace
+	options[optionPodUID] = string(pod.UID)
+	if source.SecretRef != nil {
+		kubeClient := plugin.host.GetKubeClient()
+		if kubeClient == nil {
+			return nil, fmt.Errorf("Cannot get kube client")
+		}
+		secret, err := kubeClient.Secrets(pod.Namespace).Get(source.SecretRef.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't get secret %v/%v err: %v", pod.Namespace, source.SecretRef.Name, err)
+		}
+		for name, data := range secret.Data {
+			options[optionSecretPrefix+name] = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	return plugin.newBuilderInternal(spec, pod.UID, plugin.host.GetMounter(), options)
+}
+
+func (plugin *flexVolumePlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, mounter mount.Interface, options map[string]string) (volume.Builder, error) {
+	return &flexVolumeBuilder{
+		flexVolume: &flexVolume{
+			volName: spec.Name(),
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+		spec:     spec,
+		fsType:   plugin.getVolumeSource(spec).FSType,
+		readOnly: spec.IsReadOnly(),
+		options:  options,
+	}, nil
+}
+
+func (plugin *flexVolumePlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
+	return plugin.newCleanerInternal(volName, podUID, plugin.host.GetMounter())
+}
+
+func (plugin *flexVolumePlugin) newCleanerInternal(volName string, podUID types.UID, mounter mount.Interface) (volume.Cleaner, error) {
+	return &flexVolumeCleaner{
+		flexVolume: &flexVolume{
+			volName: volName,
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+	}, nil
+}
+
+func (plugin *flexVolumePlugin) getVolumeSource(spec *volume.Spec) *api.FlexVolumeSource {
+	if source := spec.PersistentVolumeSource(); source != nil {
+		return source.FlexVolume
+	}
+	return nil
+}
+
+// driverOptions returns the options of source passed to every command.
+func (plugin *flexVolumePlugin) driverOptions(source *api.FlexVolumeSource, readOnly bool) map[string]string {
+	options := make(map[string]string, len(source.Options)+2)
+	for key, value := range source.Options {
+		options[key] = value
+	}
+	options[optionFSType] = source.FSType
+	options[optionReadWrite] = "rw"
+	if readOnly {
+		options[optionReadWrite] = "ro"
+	}
+	return options
+}
+
+// flexVolumeAttachablePlugin is the plugin of a driver with the attach
+// capability.
+type flexVolumeAttachablePlugin struct {
+	*flexVolumePlugin
+}
+
+var _ volume.AttachableVolumePlugin = &flexVolumeAttachablePlugin{}
+
+func (plugin *flexVolumeAttachablePlugin) NewAttacher() (volume.Attacher, error) {
+	return &flexVolumeAttacher{plugin.flexVolumePlugin}, nil
+}
+
+func (plugin *flexVolumeAttachablePlugin) NewDetacher() (volume.Detacher, error) {
+	return &flexVolumeDetacher{plugin.flexVolumePlugin}, nil
+}
+
+type flexVolumeAttacher struct {
+	plugin *flexVolumePlugin
+}
+
+var _ volume.Attacher = &flexVolumeAttacher{}
+
+func (a *flexVolumeAttacher) Attach(spec *volume.Spec, nodeName string) (string, error) {
+	source := a.plugin.getVolumeSource(spec)
+	if source == nil {
+		return "", fmt.Errorf("spec does not reference a flexvolume")
+	}
+	options, err := encodeOptions(a.plugin.driverOptions(source, spec.IsReadOnly()))
+	if err != nil {
+		return "", err
+	}
+	status, err := a.plugin.call(attachCmd, options, nodeName)
+	if err != nil {
+		return "", err
+	}
+	return status.Device, nil
+}
+
+func (a *flexVolumeAttacher) WaitForAttach(devicePath string, timeout time.Duration) error {
+	_, err := a.plugin.call(waitForAttachCmd, devicePath, strconv.Itoa(int(timeout.Seconds())))
+	return err
+}
+
+type flexVolumeDetacher struct {
+	plugin *flexVolumePlugin
+}
+
+var _ volume.Detacher = &flexVolumeDetacher{}
+
+func (d *flexVolumeDetacher) Detach(deviceName string, nodeName string) error {
+	_, err := d.plugin.call(detachCmd, deviceName, nodeName)
+	return err
+}
+
+// attachFor attaches the volume of spec to this node for the volume at dir.
+// Like the mount itself, it runs through the registry of the shared
+// lifecycle helpers, so that it is tracked and audited.
+func (plugin *flexVolumePlugin) attachFor(dir string, spec *volume.Spec) (string, error) {
+	device := ""
+	err := volume.RunOperation(volume.Operation{
+		Type:       volume.OperationAttach,
+		VolumePath: dir,
+		Priority:   volume.DefaultPriority(volume.OperationAttach),
+		Func: func() error {
+			var err error
+			device, err = (&flexVolumeAttacher{plugin}).Attach(spec, plugin.host.GetHostName())
+			return err
+		},
+	})
+	return device, err
+}
+
+// detachFor detaches device, which was attached for the volume at dir, from
+// this node.  See attachFor.
+func (plugin *flexVolumePlugin) detachFor(dir, device string) error {
+	return volume.RunOperation(volume.Operation{
+		Type:       volume.OperationDetach,
+		VolumePath: dir,
+		Priority:   volume.DefaultPriority(volume.OperationDetach),
+		Func: func() error {
+			return (&flexVolumeDetacher{plugin}).Detach(device, plugin.host.GetHostName())
+		},
+	})
+}
+
+// flexVolume is a volume set up by a driver.
+type flexVolume struct {
+	volName string
+	podUID  types.UID
+	mounter mount.Interface
+	plugin  *flexVolumePlugin
+}
+
+var _ volume.Volume = &flexVolume{}
+
+func (f *flexVolume) GetPath() string {
+	return volume.NewDirectoryLayout(f.plugin.host).PodVolumeDir(f.podUID, f.plugin.driverName, f.volName)
+}
+
+type flexVolumeBuilder struct {
+	*flexVolume
+	spec     *volume.Spec
+	fsType   string
+	readOnly bool
+	// options are passed to mount, including those of the pod and secret.
+	options map[string]string
+}
+
+var _ volume.Builder = &flexVolumeBuilder{}
+
+// SetUp attaches the volume if the driver attaches volumes and mounts it to
+// the volume path.
+func (b *flexVolumeBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// SetUpAt attaches the volume if the driver attaches volumes and mounts it
+// to dir.
+func (b *flexVolumeBuilder) SetUpAt(dir string) error {
+	notMnt, err := b.mounter.IsLikelyNotMountPoint(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !notMnt {
+		return nil
+	}
+
+	device := ""
+	if b.plugin.attach {
+		if device, err = b.plugin.attachFor(dir, b.spec); err != nil {
+			return err
+		}
+		attacher := &flexVolumeAttacher{b.plugin}
+		if err := attacher.WaitForAttach(device, attachTimeout); err != nil {
+			b.detachLogError(dir, device)
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		b.detachLogError(dir, device)
+		return err
+	}
+	options, err := encodeOptions(b.options)
+	if err == nil {
+		_, err = b.plugin.call(mountCmd, dir, device, options)
+	}
+	if isNotSupported(err) && device != "" {
+		// The driver leaves mounting its devices to the host.
+		mountOptions := []string{}
+		if b.readOnly {
+			mountOptions = append(mountOptions, "ro")
+		}
+		err = b.mounter.Mount(device, dir, b.fsType, volume.JoinMountOptions(b.spec.MountOptions(), mountOptions))
+	}
+	if err != nil {
+		volume.CleanupStagingDir(dir, err)
+		b.detachLogError(dir, device)
+		return err
+	}
+	return nil
+}
+
+// detachLogError detaches device, which SetUpAt attached for dir, after a
+// failed setup.  Nothing is detached if the driver does not attach volumes.
+func (b *flexVolumeBuilder) detachLogError(dir, device string) {
+	if !b.plugin.attach || device == "" {
+		return
+	}
+	if err := b.plugin.detachFor(dir, device); err != nil {
+		glog.Errorf("Failed to detach %s after a failed setup: %v", device, err)
+	}
+}
+
+func (b *flexVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
+}
+
+type flexVolumeCleaner struct {
+	*flexVolume
+}
+
+var _ volume.Cleaner = &flexVolumeCleaner{}
+
+// TearDown unmounts the volume from the volume path and detaches it if the
+// driver attaches volumes and no other mount uses it.
+func (c *flexVolumeCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+// TearDownAt unmounts the volume from dir and detaches it if the driver
+// attaches volumes and no other mount uses it.
+func (c *flexVolumeCleaner) TearDownAt(dir string) error {
+	notMnt, err := volume.IsNotMountPoint(dir, c.mounter)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if notMnt {
+		return os.Remove(dir)
+	}
+
+	device, refCount, err := mount.GetDeviceNameFromMount(c.mounter, dir)
+	if err != nil {
+		return err
+	}
+	if _, err := c.plugin.call(unmountCmd, dir); err != nil {
+		if !isNotSupported(err) {
+			return err
+		}
+		// The driver leaves unmounting its volumes to the host.
+		if err := volume.UnmountPath(dir, c.mounter); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if c.plugin.attach && device != "" && refCount == 1 {
+		return c.plugin.detachFor(dir, device)
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+const testDriverName = "example.com/lvm"
+
+// fakeDriver runs no binaries but logs the calls to drivers and replies
+// to each command with its entry of replies, "Not supported" if it has none.
+type fakeDriver struct {
+	replies map[string]DriverStatus
+	calls   [][]string
+}
+
+func (d *fakeDriver) Command(cmd string, args ...string) exec.Cmd {
+	d.calls = append(d.calls, append([]string{cmd}, args...))
+	reply, ok := d.replies[args[0]]
+	if !ok {
+		reply = DriverStatus{Status: statusNotSupported}
+	}
+	output, _ := json.Marshal(reply)
+	fcmd := &exec.FakeCmd{
+		CombinedOutputScript: []exec.FakeCombinedOutputAction{
+			func() ([]byte, error) { return output, nil },
+		},
+	}
+	return exec.InitFakeCmd(fcmd, cmd, args...)
+}
+
+func (d *fakeDriver) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+// commands returns the commands the driver was called with.
+func (d *fakeDriver) commands() []string {
+	commands := []string{}
+	for _, call := range d.calls {
+		commands = append(commands, call[1])
+	}
+	return commands
+}
+
+func newTestHost(t *testing.T, client client.Interface) (string, volume.VolumeHost) {
+	tempDir, err := ioutil.TempDir("/tmp", "flexvolume_test.")
+	if err != nil {
+		t.Fatalf("can't make a temp rootdir: %v", err)
+	}
+	return tempDir, volume.NewFakeVolumeHost(tempDir, client, nil)
+}
+
+// newTestPlugin probes a plugin directory holding the driver testDriverName.
+func newTestPlugin(t *testing.T, driver *fakeDriver, host volume.VolumeHost) volume.VolumePlugin {
+	pluginDir, err := ioutil.TempDir("/tmp", "flexvolume_plugins.")
+	if err != nil {
+		t.Fatalf("can't make a plugin dir: %v", err)
+	}
+	defer os.RemoveAll(pluginDir)
+	if err := os.Mkdir(path.Join(pluginDir, "example.com~lvm"), 0750); err != nil {
+		t.Fatalf("can't make a driver dir: %v", err)
+	}
+
+	plugins := probeVolumePlugins(pluginDir, driver)
+	if len(plugins) != 1 {
+		t.Fatalf("Expected 1 plugin, got %d", len(plugins))
+	}
+	pluginMgr := volume.VolumePluginMgr{}
+	pluginMgr.InitPlugins(plugins, host)
+	plugin, err := pluginMgr.FindPluginByName(testDriverName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	return plugin
+}
+
+func volumeSpec(source *api.FlexVolumeSource) *volume.Spec {
+	return volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{FlexVolume: source},
+	})
+}
+
+func TestProbeVolumePlugins(t *testing.T) {
+	pluginDir, err := ioutil.TempDir("/tmp", "flexvolume_plugins.")
+	if err != nil {
+		t.Fatalf("can't make a plugin dir: %v", err)
+	}
+	defer os.RemoveAll(pluginDir)
+	if err := os.Mkdir(path.Join(pluginDir, "example.com~lvm"), 0750); err != nil {
+		t.Fatalf("can't make a driver dir: %v", err)
+	}
+	// Files other than directories are no drivers.
+	if err := ioutil.WriteFile(path.Join(pluginDir, "README"), []byte{}, 0640); err != nil {
+		t.Fatalf("can't write a file: %v", err)
+	}
+
+	driver := &fakeDriver{replies: map[string]DriverStatus{initCmd: {Status: statusSuccess}}}
+	plugins := probeVolumePlugins(pluginDir, driver)
+	if len(plugins) != 1 {
+		t.Fatalf("Expected 1 plugin, got %d", len(plugins))
+	}
+	if plugins[0].Name() != testDriverName {
+		t.Errorf("Wrong name: %s", plugins[0].Name())
+	}
+	if _, ok := plugins[0].(volume.AttachableVolumePlugin); ok {
+		t.Errorf("Expected a driver without the attach capability not to be attachable")
+	}
+	expected := []string{path.Join(pluginDir, "example.com~lvm", "lvm"), initCmd}
+	if len(driver.calls) != 1 || !reflect.DeepEqual(driver.calls[0], expected) {
+		t.Errorf("Expected the driver to be called with %v, got %v", expected, driver.calls)
+	}
+
+	driver = &fakeDriver{replies: map[string]DriverStatus{initCmd: {Status: statusSuccess, Capabilities: &DriverCapabilities{Attach: true}}}}
+	plugins = probeVolumePlugins(pluginDir, driver)
+	if len(plugins) != 1 {
+		t.Fatalf("Expected 1 plugin, got %d", len(plugins))
+	}
+	if _, ok := plugins[0].(volume.AttachableVolumePlugin); !ok {
+		t.Errorf("Expected a driver with the attach capability to be attachable")
+	}
+
+	driver = &fakeDriver{replies: map[string]DriverStatus{initCmd: {Status: statusFailure, Message: "broken"}}}
+	if plugins := probeVolumePlugins(pluginDir, driver); len(plugins) != 0 {
+		t.Errorf("Expected drivers failing to initialize to be left out, got %d plugins", len(plugins))
+	}
+
+	if plugins := probeVolumePlugins(path.Join(pluginDir, "missing"), driver); len(plugins) != 0 {
+		t.Errorf("Expected no plugins of a missing directory, got %d", len(plugins))
+	}
+}
+
+func TestCanSupport(t *testing.T) {
+	_, host := newTestHost(t, nil)
+	plugin := newTestPlugin(t, &fakeDriver{}, host)
+
+	if !plugin.CanSupport(volumeSpec(&api.FlexVolumeSource{Driver: testDriverName})) {
+		t.Errorf("Expected true")
+	}
+	if !plugin.CanSupport(&volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{FlexVolume: &api.FlexVolumeSource{Driver: testDriverName}}}}}) {
+		t.Errorf("Expected true")
+	}
+	if plugin.CanSupport(volumeSpec(&api.FlexVolumeSource{Driver: "example.com/other"})) {
+		t.Errorf("Expected false for another driver")
+	}
+	if plugin.CanSupport(&volume.Spec{}) {
+		t.Errorf("Expected false")
+	}
+}
+
+func TestGetVolumeName(t *testing.T) {
+	_, host := newTestHost(t, nil)
+	driver := &fakeDriver{replies: map[string]DriverStatus{getVolumeNameCmd: {Status: statusSuccess, VolumeName: "lvm-vol1"}}}
+	plugin := newTestPlugin(t, driver, host)
+	spec := volumeSpec(&api.FlexVolumeSource{Driver: testDriverName, Options: map[string]string{"volumeID": "vol1"}})
+
+	if name, err := plugin.GetVolumeName(spec); err != nil || name != "lvm-vol1" {
+		t.Errorf("Expected the name replied by the driver, got %q, %v", name, err)
+	}
+	calls := driver.calls[len(driver.calls)-1]
+	if calls[1] != getVolumeNameCmd || !strings.Contains(calls[2], `"volumeID":"vol1"`) {
+		t.Errorf("Expected the driver to be called with the options of the volume, got %v", calls)
+	}
+
+	// The name in the pod is used for drivers without getvolumename.
+	delete(driver.replies, getVolumeNameCmd)
+	if name, err := plugin.GetVolumeName(spec); err != nil || name != "vol1" {
+		t.Errorf("Expected the name of the spec, got %q, %v", name, err)
+	}
+
+	driver.replies[getVolumeNameCmd] = DriverStatus{Status: statusFailure, Message: "broken"}
+	if _, err := plugin.GetVolumeName(spec); err == nil {
+		t.Errorf("Expected an error if the driver fails")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	secret := api.Secret{
+		ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "lvm-secret"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	driver := &fakeDriver{replies: map[string]DriverStatus{
+		initCmd:    {Status: statusSuccess},
+		mountCmd:   {Status: statusSuccess},
+		unmountCmd: {Status: statusSuccess},
+	}}
+	_, host := newTestHost(t, testclient.NewSimpleFake(&secret))
+	plugin := newTestPlugin(t, driver, host)
+
+	spec := volumeSpec(&api.FlexVolumeSource{
+		Driver:    testDriverName,
+		FSType:    "ext4",
+		SecretRef: &api.LocalObjectReference{Name: "lvm-secret"},
+		Options:   map[string]string{"volumeID": "vol1"},
+	})
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "pod", Namespace: "ns", UID: types.UID("poduid")}}
+	builder, err := plugin.NewBuilder(spec, pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	volumePath := builder.GetPath()
+	if path.Base(path.Dir(volumePath)) != "example.com~lvm" {
+		t.Errorf("Got unexpected path: %s", volumePath)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	if _, err := os.Stat(volumePath); err != nil {
+		t.Errorf("SetUp() failed, volume path not created: %s", volumePath)
+	}
+
+	if !reflect.DeepEqual(driver.commands(), []string{initCmd, mountCmd}) {
+		t.Fatalf("Unexpected calls to the driver: %v", driver.calls)
+	}
+	mountCall := driver.calls[1]
+	if mountCall[2] != volumePath || mountCall[3] != "" {
+		t.Errorf("Expected mount of %s without device, got %v", volumePath, mountCall)
+	}
+	options := map[string]string{}
+	if err := json.Unmarshal([]byte(mountCall[4]), &options); err != nil {
+		t.Fatalf("Invalid options %q: %v", mountCall[4], err)
+	}
+	expected := map[string]string{
+		"volumeID":                      "vol1",
+		optionFSType:                    "ext4",
+		optionReadWrite:                 "rw",
+		optionPodName:                   "pod",
+		optionPodNamespace:              "ns",
+		optionPodUID:                    "poduid",
+		optionSecretPrefix + "password": "c2VjcmV0",
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("Expected options %v, got %v", expected, options)
+	}
+
+	// The fake mounter doesn't see the mount of the driver, pretend it does.
+	fakeMounter := host.GetMounter().(*mount.FakeMounter)
+	fakeMounter.MountPoints = []mount.MountPoint{{Device: "vg/vol1", Path: volumePath}}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	if len(driver.calls) != 2 {
+		t.Errorf("Expected a mounted volume not to be mounted again, got %v", driver.calls)
+	}
+
+	cleaner, err := plugin.NewCleaner("vol1", pod.UID)
+	if err != nil {
+		t.Fatalf("Failed to make a new Cleaner: %v", err)
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if !reflect.DeepEqual(driver.commands(), []string{initCmd, mountCmd, unmountCmd}) {
+		t.Errorf("Unexpected calls to the driver: %v", driver.calls)
+	}
+	if _, err := os.Stat(volumePath); !os.IsNotExist(err) {
+		t.Errorf("TearDown() failed, volume path still exists: %s", volumePath)
+	}
+}
+
+func TestAttachablePlugin(t *testing.T) {
+	driver := &fakeDriver{replies: map[string]DriverStatus{
+		initCmd:          {Status: statusSuccess, Capabilities: &DriverCapabilities{Attach: true}},
+		attachCmd:        {Status: statusSuccess, Device: "/dev/sdx"},
+		waitForAttachCmd: {Status: statusSuccess},
+		detachCmd:        {Status: statusSuccess},
+	}}
+	_, host := newTestHost(t, nil)
+	plugin := newTestPlugin(t, driver, host)
+
+	spec := volumeSpec(&api.FlexVolumeSource{Driver: testDriverName, FSType: "xfs", ReadOnly: true})
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+	builder, err := plugin.NewBuilder(spec, pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected a read-only volume")
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	volumePath := builder.GetPath()
+
+	if !reflect.DeepEqual(driver.commands(), []string{initCmd, attachCmd, waitForAttachCmd, mountCmd}) {
+		t.Fatalf("Unexpected calls to the driver: %v", driver.calls)
+	}
+	if node := driver.calls[1][3]; node != "fakeHostName" {
+		t.Errorf("Expected attach to node fakeHostName, got %s", node)
+	}
+	if device := driver.calls[2][2]; device != "/dev/sdx" {
+		t.Errorf("Expected to wait for /dev/sdx, got %s", device)
+	}
+	// The driver doesn't mount, the host mounts the device itself.
+	fakeMounter := host.GetMounter().(*mount.FakeMounter)
+	expectedMount := mount.FakeAction{Action: mount.FakeActionMount, Target: volumePath, Source: "/dev/sdx", FSType: "xfs"}
+	if len(fakeMounter.Log) != 1 || fakeMounter.Log[0] != expectedMount {
+		t.Errorf("Expected %v, got %v", expectedMount, fakeMounter.Log)
+	}
+
+	cleaner, err := plugin.NewCleaner("vol1", pod.UID)
+	if err != nil {
+		t.Fatalf("Failed to make a new Cleaner: %v", err)
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	// The driver doesn't unmount either, the host unmounts and the driver detaches.
+	if len(fakeMounter.Log) != 2 || fakeMounter.Log[1].Action != mount.FakeActionUnmount {
+		t.Errorf("Expected an unmount, got %v", fakeMounter.Log)
+	}
+	if !reflect.DeepEqual(driver.commands(), []string{initCmd, attachCmd, waitForAttachCmd, mountCmd, unmountCmd, detachCmd}) {
+		t.Fatalf("Unexpected calls to the driver: %v", driver.calls)
+	}
+	if detachCall := driver.calls[5]; detachCall[2] != "/dev/sdx" || detachCall[3] != "fakeHostName" {
+		t.Errorf("Expected detach of /dev/sdx from fakeHostName, got %v", detachCall)
+	}
+	if _, err := os.Stat(volumePath); !os.IsNotExist(err) {
+		t.Errorf("TearDown() failed, volume path still exists: %s", volumePath)
+	}
+}
+
+func TestSetUpFailure(t *testing.T) {
+	driver := &fakeDriver{replies: map[string]DriverStatus{
+		initCmd:  {Status: statusSuccess},
+		mountCmd: {Status: statusFailure, Message: "no such volume"},
+	}}
+	_, host := newTestHost(t, nil)
+	plugin := newTestPlugin(t, driver, host)
+
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+	builder, err := plugin.NewBuilder(volumeSpec(&api.FlexVolumeSource{Driver: testDriverName}), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error when the driver fails to mount")
+	}
+	if _, err := os.Stat(builder.GetPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the volume path to be removed after a failed mount, got %v", err)
+	}
+}
+
+func TestSetUpFailureDetaches(t *testing.T) {
+	driver := &fakeDriver{replies: map[string]DriverStatus{
+		initCmd:          {Status: statusSuccess, Capabilities: &DriverCapabilities{Attach: true}},
+		attachCmd:        {Status: statusSuccess, Device: "/dev/sdx"},
+		waitForAttachCmd: {Status: statusSuccess},
+		mountCmd:         {Status: statusFailure, Message: "bad superblock"},
+		detachCmd:        {Status: statusSuccess},
+	}}
+	_, host := newTestHost(t, nil)
+	plugin := newTestPlugin(t, driver, host)
+
+	if err := volume.SetStagingCleanupPolicy(volume.StagingCleanupKeepOnError); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer volume.SetStagingCleanupPolicy(volume.StagingCleanupAlways)
+
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+	builder, err := plugin.NewBuilder(volumeSpec(&api.FlexVolumeSource{Driver: testDriverName}), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error when the driver fails to mount")
+	}
+	if !reflect.DeepEqual(driver.commands(), []string{initCmd, attachCmd, waitForAttachCmd, mountCmd, detachCmd}) {
+		t.Errorf("Expected the device to be detached again, got calls %v", driver.calls)
+	}
+	if _, err := os.Stat(builder.GetPath()); err != nil {
+		t.Errorf("Expected the volume path to be kept for inspection, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// dirWatcher tells whether the plugin directory or the directory of a
+// driver changed.
+type dirWatcher interface {
+	// add watches dir, the directory of a driver, too.
+	add(dir string) error
+	// changed returns whether a watched directory changed since it was
+	// last called, and true when it is called first.
+	changed() bool
+}
+
+// NewDynamicPluginProber returns a prober of the drivers in pluginDir, so
+// that drivers are used as soon as they are installed, updated when their
+// executable is replaced and dropped when they are removed.  Drivers that
+// fail to initialize are tried again once their executable changes.
+func NewDynamicPluginProber(pluginDir string) volume.DynamicPluginProber {
+	return &flexVolumeProber{
+		pluginDir:  pluginDir,
+		runner:     exec.New(),
+		newWatcher: newDirWatcher,
+		drivers:    map[string]time.Time{},
+	}
+}
+
+type flexVolumeProber struct {
+	pluginDir  string
+	runner     exec.Interface
+	newWatcher func(dir string) (dirWatcher, error)
+	watcher    dirWatcher
+	// drivers maps the directory names of the probed drivers to the
+	// modification time of their executable when they were probed.
+	drivers map[string]time.Time
+}
+
+var _ volume.DynamicPluginProber = &flexVolumeProber{}
+
+func (p *flexVolumeProber) Init() error {
+	if err := os.MkdirAll(p.pluginDir, 0755); err != nil {
+		return fmt.Errorf("failed to make the volume plugin directory %s: %v", p.pluginDir, err)
+	}
+	watcher, err := p.newWatcher(p.pluginDir)
+	if err != nil {
+		return fmt.Errorf("failed to watch the volume plugin directory %s: %v", p.pluginDir, err)
+	}
+	p.watcher = watcher
+	return nil
+}
+
+// Probe scans the plugin directory if it changed since the last probe.
+func (p *flexVolumeProber) Probe() ([]volume.ProbeEvent, error) {
+	if !p.watcher.changed() {
+		return nil, nil
+	}
+	files, err := ioutil.ReadDir(p.pluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []volume.ProbeEvent{}
+	allErrs := []error{}
+	found := map[string]bool{}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		dirName := f.Name()
+		driverDir := path.Join(p.pluginDir, dirName)
+		if err := p.watcher.add(driverDir); err != nil {
+			glog.Errorf("Error watching flexvolume driver directory %s: %v", driverDir, err)
+		}
+		driverName := strings.Replace(dirName, "~", "/", -1)
+		info, err := os.Stat(path.Join(driverDir, path.Base(driverName)))
+		if err != nil {
+			// The executable isn't installed (yet).
+			continue
+		}
+		found[dirName] = true
+		if modTime, ok := p.drivers[dirName]; ok && modTime.Equal(info.ModTime()) {
+			continue
+		}
+		plugin, err := newFlexVolumePlugin(p.pluginDir, dirName, p.runner)
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error initializing flexvolume driver in %s: %v", driverDir, err))
+			continue
+		}
+		p.drivers[dirName] = info.ModTime()
+		events = append(events, volume.ProbeEvent{Op: volume.ProbeAddOrUpdate, Plugin: plugin})
+	}
+	for dirName := range p.drivers {
+		if !found[dirName] {
+			delete(p.drivers, dirName)
+			events = append(events, volume.ProbeEvent{Op: volume.ProbeRemove, PluginName: strings.Replace(dirName, "~", "/", -1)})
+		}
+	}
+	return events, errors.NewAggregate(allErrs)
+}
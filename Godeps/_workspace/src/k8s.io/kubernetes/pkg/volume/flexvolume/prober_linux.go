@@ -0,0 +1,77 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"golang.org/x/exp/inotify"
+)
+
+// watchFlags are the changes to a directory that may install, replace or
+// remove a driver.
+const watchFlags = inotify.IN_CREATE | inotify.IN_DELETE | inotify.IN_MOVED_FROM | inotify.IN_MOVED_TO |
+	inotify.IN_CLOSE_WRITE | inotify.IN_ATTRIB | inotify.IN_DELETE_SELF | inotify.IN_MOVE_SELF
+
+// inotifyWatcher is changed whenever inotify reports a watched directory
+// changed, or that events were lost.
+type inotifyWatcher struct {
+	watcher *inotify.Watcher
+	dirty   int32
+}
+
+func newDirWatcher(dir string) (dirWatcher, error) {
+	watcher, err := inotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.AddWatch(dir, watchFlags); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	w := &inotifyWatcher{watcher: watcher, dirty: 1}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Event:
+			if !ok {
+				return
+			}
+			glog.V(5).Infof("Volume plugin directory event: %v", event)
+			atomic.StoreInt32(&w.dirty, 1)
+		case err, ok := <-w.watcher.Error:
+			if !ok {
+				return
+			}
+			glog.Errorf("Error watching the volume plugin directory: %v", err)
+			atomic.StoreInt32(&w.dirty, 1)
+		}
+	}
+}
+
+func (w *inotifyWatcher) add(dir string) error {
+	return w.watcher.AddWatch(dir, watchFlags)
+}
+
+func (w *inotifyWatcher) changed() bool {
+	return atomic.SwapInt32(&w.dirty, 0) == 1
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// fakeWatcher reports the plugin directory changed whenever dirty is set.
+type fakeWatcher struct {
+	dirty bool
+	dirs  []string
+}
+
+func (w *fakeWatcher) add(dir string) error {
+	w.dirs = append(w.dirs, dir)
+	return nil
+}
+
+func (w *fakeWatcher) changed() bool {
+	changed := w.dirty
+	w.dirty = false
+	return changed
+}
+
+func TestDynamicPluginProber(t *testing.T) {
+	pluginDir, err := ioutil.TempDir("/tmp", "flexvolume_plugins.")
+	if err != nil {
+		t.Fatalf("can't make a plugin dir: %v", err)
+	}
+	defer os.RemoveAll(pluginDir)
+
+	watcher := &fakeWatcher{dirty: true}
+	driver := &fakeDriver{replies: map[string]DriverStatus{initCmd: {Status: statusSuccess}}}
+	prober := &flexVolumeProber{
+		pluginDir:  pluginDir,
+		runner:     driver,
+		newWatcher: func(string) (dirWatcher, error) { return watcher, nil },
+		drivers:    map[string]time.Time{},
+	}
+	if err := prober.Init(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	probe := func() []volume.ProbeEvent {
+		events, err := prober.Probe()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return events
+	}
+	if events := probe(); len(events) != 0 {
+		t.Errorf("Expected no events of an empty directory, got %v", events)
+	}
+
+	// A driver directory without its executable is no driver yet.
+	driverDir := path.Join(pluginDir, "example.com~lvm")
+	if err := os.Mkdir(driverDir, 0750); err != nil {
+		t.Fatalf("can't make a driver dir: %v", err)
+	}
+	watcher.dirty = true
+	if events := probe(); len(events) != 0 {
+		t.Errorf("Expected no events of a driver without an executable, got %v", events)
+	}
+	if len(watcher.dirs) != 1 || watcher.dirs[0] != driverDir {
+		t.Errorf("Expected the driver directory to be watched, got %v", watcher.dirs)
+	}
+
+	executable := path.Join(driverDir, "lvm")
+	if err := ioutil.WriteFile(executable, []byte{}, 0750); err != nil {
+		t.Fatalf("can't write the driver: %v", err)
+	}
+	watcher.dirty = true
+	events := probe()
+	if len(events) != 1 || events[0].Op != volume.ProbeAddOrUpdate || events[0].Plugin.Name() != testDriverName {
+		t.Fatalf("Expected the driver to be added, got %v", events)
+	}
+
+	// Without changes the directory isn't scanned again.
+	if events := probe(); len(events) != 0 {
+		t.Errorf("Expected no events without changes, got %v", events)
+	}
+	watcher.dirty = true
+	if events := probe(); len(events) != 0 {
+		t.Errorf("Expected no events for an unchanged driver, got %v", events)
+	}
+
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(executable, modTime, modTime); err != nil {
+		t.Fatalf("can't touch the driver: %v", err)
+	}
+	watcher.dirty = true
+	if events := probe(); len(events) != 1 || events[0].Op != volume.ProbeAddOrUpdate {
+		t.Errorf("Expected the replaced driver to be updated, got %v", events)
+	}
+
+	if err := os.RemoveAll(driverDir); err != nil {
+		t.Fatalf("can't remove the driver: %v", err)
+	}
+	watcher.dirty = true
+	events = probe()
+	if len(events) != 1 || events[0].Op != volume.ProbeRemove || events[0].PluginName != testDriverName {
+		t.Errorf("Expected the driver to be removed, got %v", events)
+	}
+}
+
+func TestDynamicPluginProberInitFailure(t *testing.T) {
+	pluginDir, err := ioutil.TempDir("/tmp", "flexvolume_plugins.")
+	if err != nil {
+		t.Fatalf("can't make a plugin dir: %v", err)
+	}
+	defer os.RemoveAll(pluginDir)
+	driverDir := path.Join(pluginDir, "example.com~lvm")
+	if err := os.Mkdir(driverDir, 0750); err != nil {
+		t.Fatalf("can't make a driver dir: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(driverDir, "lvm"), []byte{}, 0750); err != nil {
+		t.Fatalf("can't write the driver: %v", err)
+	}
+
+	watcher := &fakeWatcher{dirty: true}
+	driver := &fakeDriver{replies: map[string]DriverStatus{initCmd: {Status: statusFailure, Message: "broken"}}}
+	prober := &flexVolumeProber{
+		pluginDir:  pluginDir,
+		runner:     driver,
+		newWatcher: func(string) (dirWatcher, error) { return watcher, nil },
+		drivers:    map[string]time.Time{},
+	}
+	if err := prober.Init(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if events, err := prober.Probe(); err == nil || len(events) != 0 {
+		t.Errorf("Expected the failing driver to be reported and left out, got %v, %v", events, err)
+	}
+
+	// The driver is retried on the next change.
+	driver.replies[initCmd] = DriverStatus{Status: statusSuccess}
+	watcher.dirty = true
+	if events, err := prober.Probe(); err != nil || len(events) != 1 {
+		t.Errorf("Expected the fixed driver to be added, got %v, %v", events, err)
+	}
+}
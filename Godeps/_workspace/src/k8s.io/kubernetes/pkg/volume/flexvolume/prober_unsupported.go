@@ -0,0 +1,35 @@
+// +build !linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flexvolume
+
+// pollingWatcher can't watch directories, so it is always changed and the
+// plugin directory is scanned on every probe.
+type pollingWatcher struct{}
+
+func newDirWatcher(dir string) (dirWatcher, error) {
+	return pollingWatcher{}, nil
+}
+
+func (pollingWatcher) add(dir string) error {
+	return nil
+}
+
+func (pollingWatcher) changed() bool {
+	return true
+}
@@ -25,7 +25,6 @@ import (
 	flockerClient "github.com/ClusterHQ/flocker-go"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -73,6 +72,13 @@ func (p flockerPlugin) CanSupport(spec *volume.Spec) bool {
 	return (spec.PersistentVolume != nil && spec.PersistentVolume.Spec.Flocker != nil) ||
 		(spec.Volume != nil && spec.Volume.Flocker != nil)
 }
+func (p flockerPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.Flocker == nil {
+		return "", fmt.Errorf("spec does not reference a flocker volume")
+	}
+	return source.Flocker.DatasetName, nil
+}
 
 func (p *flockerPlugin) getFlockerVolumeSource(spec *volume.Spec) (*api.FlockerVolumeSource, bool) {
 	// AFAIK this will always be r/w, but perhaps for the future it will be needed
@@ -113,10 +119,6 @@ type flockerBuilder struct {
 	readOnly bool
 }
 
-func (_ *flockerBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
-
 func (b flockerBuilder) GetPath() string {
 	return b.flocker.path
 }
@@ -144,9 +146,7 @@ func (b flockerBuilder) newFlockerClient() (*flockerClient.Client, error) {
 
 func (b *flockerBuilder) getMetaDir() string {
 	return path.Join(
-		b.plugin.host.GetPodPluginDir(
-			b.flocker.pod.UID, util.EscapeQualifiedNameForDisk(flockerPluginName),
-		),
+		volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.flocker.pod.UID, flockerPluginName),
 		b.datasetName,
 	)
 }
@@ -201,12 +201,12 @@ func (b flockerBuilder) SetUpAt(dir string) error {
 	return nil
 }
 
-func (b flockerBuilder) IsReadOnly() bool {
-	return b.readOnly
-}
-
-func (b flockerBuilder) SupportsSELinux() bool {
-	return false
+func (b flockerBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
 }
 
 // updateDatasetPrimary will update the primary in Flocker and wait for it to
@@ -145,9 +145,9 @@ func TestNewCleaner(t *testing.T) {
 	assert.NoError(err)
 }
 
-func TestIsReadOnly(t *testing.T) {
+func TestGetAttributes(t *testing.T) {
 	b := flockerBuilder{readOnly: true}
-	assert.True(t, b.IsReadOnly())
+	assert.True(t, b.GetAttributes().ReadOnly)
 }
 
 func TestGetPath(t *testing.T) {
@@ -0,0 +1,144 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+const (
+	// defaultFSType is formatted onto blank devices when neither the mount
+	// nor the SafeFormatAndMount names a filesystem type.
+	defaultFSType = "ext4"
+
+	// fsckErrorsCorrected is returned by fsck when it repaired the
+	// filesystem.
+	fsckErrorsCorrected = 1
+	// fsckErrorsUncorrected is returned by fsck when errors were left on
+	// the filesystem, which must not be mounted then.
+	fsckErrorsUncorrected = 4
+)
+
+// SafeFormatAndMount mounts block devices, formatting them first if they
+// are blank and checking their filesystem with fsck otherwise.  It is
+// meant for the builders of block-backed plugins such as iSCSI, RBD and
+// cloud disks.  A device is never formatted when it is mounted read-only
+// or when blkid finds a partition table on it.
+type SafeFormatAndMount struct {
+	mount.Interface
+	Runner exec.Interface
+	// DefaultFSType is formatted onto blank devices mounted without a
+	// filesystem type.  It defaults to ext4.
+	DefaultFSType string
+}
+
+// Mount mounts the device source at target.  If fstype is empty, an
+// existing filesystem is mounted as whatever type it is.  A device holding
+// a filesystem other than fstype is not mounted and ErrFSTypeMismatch is
+// returned; a blank device mounted read-only returns ErrDeviceUnformatted.
+// Bind mounts, e.g. of the global mount of a disk into a pod, are passed
+// through, as their source is a directory.
+func (m *SafeFormatAndMount) Mount(source string, target string, fstype string, options []string) error {
+	readOnly := false
+	for _, option := range options {
+		switch option {
+		case "ro":
+			readOnly = true
+		case "bind":
+			return m.Interface.Mount(source, target, fstype, options)
+		}
+	}
+
+	prober := &DeviceProber{Runner: m.Runner}
+	existing, err := prober.GetDiskFormat(source)
+	if err != nil {
+		return err
+	}
+
+	if existing == "" {
+		if fstype == "" {
+			fstype = m.DefaultFSType
+		}
+		if fstype == "" {
+			fstype = defaultFSType
+		}
+		if readOnly {
+			return &ErrDeviceUnformatted{Device: source, Expected: fstype}
+		}
+		if err := m.format(source, fstype); err != nil {
+			return err
+		}
+	} else {
+		if fstype == "" {
+			fstype = existing
+		} else if fstype != existing {
+			return &ErrFSTypeMismatch{Device: source, Expected: fstype, Actual: existing}
+		}
+		glog.Infof("Device %s holds a %s filesystem", source, existing)
+		if !readOnly {
+			if err := m.check(source); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.Interface.Mount(source, target, fstype, append(options, "defaults"))
+}
+
+// format creates a filesystem of type fstype on the blank device source.
+func (m *SafeFormatAndMount) format(source, fstype string) error {
+	args := []string{source}
+	if strings.HasPrefix(fstype, "ext") {
+		args = []string{"-E", "lazy_itable_init=0,lazy_journal_init=0", "-F", source}
+	}
+	glog.Infof("Device %s is blank, formatting it as %s", source, fstype)
+	output, err := m.Runner.Command("mkfs."+fstype, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mkfs.%s failed for %s: %v, output: %q", fstype, source, err, string(output))
+	}
+	return nil
+}
+
+// check runs fsck on the device source.  Errors fsck corrected are logged;
+// errors it left behind fail the mount.
+func (m *SafeFormatAndMount) check(source string) error {
+	output, err := m.Runner.Command("fsck", "-a", source).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	exitErr, ok := err.(exec.ExitError)
+	if !ok {
+		return fmt.Errorf("fsck failed for %s: %v", source, err)
+	}
+	switch exitErr.ExitStatus() {
+	case fsckErrorsCorrected:
+		glog.Infof("fsck corrected errors on %s, output: %q", source, string(output))
+		return nil
+	case fsckErrorsUncorrected:
+		return fmt.Errorf("fsck found errors on %s it could not correct, output: %q", source, string(output))
+	default:
+		// Other statuses, e.g. a missing fsck helper for the filesystem, are
+		// no reason to refuse the mount.
+		glog.Warningf("fsck of %s exited with %d, output: %q", source, exitErr.ExitStatus(), string(output))
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+type fakeCommand struct {
+	output string
+	err    error
+	// stdin, if set, receives what the command read from its stdin.
+	stdin *string
+}
+
+// newFakeRunner returns a FakeExec which answers a call per command in
+// turn and records the commands run in argv.
+func newFakeRunner(commands []fakeCommand, argv *[][]string) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for _, c := range commands {
+		c := c
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			*argv = append(*argv, append([]string{cmd}, args...))
+			fcmd := &exec.FakeCmd{}
+			fcmd.CombinedOutputScript = []exec.FakeCombinedOutputAction{
+				func() ([]byte, error) {
+					if c.stdin != nil && fcmd.Stdin != nil {
+						in, _ := ioutil.ReadAll(fcmd.Stdin)
+						*c.stdin = string(in)
+					}
+					return []byte(c.output), c.err
+				},
+			}
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+	return fake
+}
+
+func TestSafeFormatAndMount(t *testing.T) {
+	blank := fakeCommand{err: &exec.FakeExitError{Status: blkidNotFoundExitCode}}
+	ext4 := fakeCommand{output: "DEVNAME=/dev/foo\nTYPE=ext4\n"}
+	tests := []struct {
+		name          string
+		fstype        string
+		options       []string
+		commands      []fakeCommand
+		expectedArgv  [][]string
+		expectedFS    string
+		expectedError bool
+	}{
+		{
+			name:     "blank device is formatted",
+			commands: []fakeCommand{blank, {}},
+			expectedArgv: [][]string{
+				{"blkid", "-p", "-s", "TYPE", "-s", "PTTYPE", "-o", "export", "/dev/foo"},
+				{"mkfs.ext4", "-E", "lazy_itable_init=0,lazy_journal_init=0", "-F", "/dev/foo"},
+			},
+			expectedFS: "ext4",
+		},
+		{
+			name:       "blank device is formatted as requested",
+			fstype:     "xfs",
+			commands:   []fakeCommand{blank, {}},
+			expectedFS: "xfs",
+		},
+		{
+			name:          "blank device is not formatted read-only",
+			options:       []string{"ro"},
+			commands:      []fakeCommand{blank},
+			expectedError: true,
+		},
+		{
+			name:     "formatted device is checked",
+			commands: []fakeCommand{ext4, {}},
+			expectedArgv: [][]string{
+				{"blkid", "-p", "-s", "TYPE", "-s", "PTTYPE", "-o", "export", "/dev/foo"},
+				{"fsck", "-a", "/dev/foo"},
+			},
+			expectedFS: "ext4",
+		},
+		{
+			name:       "formatted device is not checked read-only",
+			options:    []string{"ro"},
+			commands:   []fakeCommand{ext4},
+			expectedFS: "ext4",
+		},
+		{
+			name:       "corrected errors",
+			commands:   []fakeCommand{ext4, {err: &exec.FakeExitError{Status: fsckErrorsCorrected}}},
+			expectedFS: "ext4",
+		},
+		{
+			name:          "uncorrected errors",
+			commands:      []fakeCommand{ext4, {err: &exec.FakeExitError{Status: fsckErrorsUncorrected}}},
+			expectedError: true,
+		},
+		{
+			name:          "mismatched filesystem",
+			fstype:        "xfs",
+			commands:      []fakeCommand{ext4},
+			expectedError: true,
+		},
+		{
+			name:         "bind mount is passed through",
+			options:      []string{"bind"},
+			expectedArgv: [][]string{},
+		},
+		{
+			name:          "format fails",
+			commands:      []fakeCommand{blank, {err: &exec.FakeExitError{Status: 1}}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		argv := [][]string{}
+		fakeMounter := &mount.FakeMounter{}
+		mounter := &SafeFormatAndMount{Interface: fakeMounter, Runner: newFakeRunner(test.commands, &argv)}
+		err := mounter.Mount("/dev/foo", "/mnt/bar", test.fstype, test.options)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			if len(fakeMounter.Log) != 0 {
+				t.Errorf("%s: expected no mount, got %v", test.name, fakeMounter.Log)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if test.expectedArgv != nil && !reflect.DeepEqual(argv, test.expectedArgv) {
+			t.Errorf("%s: expected commands %v, got %v", test.name, test.expectedArgv, argv)
+		}
+		expectedLog := []mount.FakeAction{{Action: mount.FakeActionMount, Target: "/mnt/bar", Source: "/dev/foo", FSType: test.expectedFS}}
+		if !reflect.DeepEqual(fakeMounter.Log, expectedLog) {
+			t.Errorf("%s: expected %v, got %v", test.name, expectedLog, fakeMounter.Log)
+		}
+	}
+}
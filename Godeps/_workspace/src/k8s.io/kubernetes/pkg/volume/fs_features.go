@@ -0,0 +1,73 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+// Filesystem type numbers as reported by statfs(2).
+const (
+	fsTypeTmpfs int64 = 0x01021994
+	fsTypeExt   int64 = 0xEF53
+	fsTypeXFS   int64 = 0x58465342
+	fsTypeBtrfs int64 = 0x9123683E
+	fsTypeNFS   int64 = 0x6969
+)
+
+// FSFeatures reports optional features of a mounted filesystem.  Features
+// which can not be determined cheaply are reported as unsupported.
+type FSFeatures struct {
+	// SupportsReflink is true if files can share extents (copy-on-write
+	// clones).
+	SupportsReflink bool
+	// SupportsProjectQuota is true if project quotas are enabled.
+	SupportsProjectQuota bool
+	// SupportsXattr is true if user extended attributes can be set.
+	SupportsXattr bool
+	// SupportsSparse is true if files can have holes.
+	SupportsSparse bool
+}
+
+// featuresForFSType returns what is known about a filesystem from its statfs
+// type and superblock mount options alone.
+func featuresForFSType(fsType int64, superOptions []string) *FSFeatures {
+	features := &FSFeatures{}
+	switch fsType {
+	case fsTypeTmpfs:
+		features.SupportsSparse = true
+	case fsTypeExt:
+		features.SupportsSparse = true
+		features.SupportsProjectQuota = hasAnyOption(superOptions, "prjquota")
+	case fsTypeXFS:
+		// Whether XFS was formatted with reflink=1 can't be told without
+		// trying a clone, so it is left unsupported.
+		features.SupportsSparse = true
+		features.SupportsProjectQuota = hasAnyOption(superOptions, "prjquota", "pquota", "pqnoenforce")
+	case fsTypeBtrfs:
+		features.SupportsSparse = true
+		features.SupportsReflink = true
+	}
+	return features
+}
+
+func hasAnyOption(options []string, wanted ...string) bool {
+	for _, opt := range options {
+		for _, w := range wanted {
+			if opt == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/golang/glog"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// xattrProbeName is looked up to find out whether user xattrs are supported.
+// It is never set.
+const xattrProbeName = "user.kubernetes.io-probe"
+
+// FilesystemFeatures reports the optional features of the filesystem
+// mounted at path.
+func FilesystemFeatures(path string) (*FSFeatures, error) {
+	buf := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &buf); err != nil {
+		return nil, fmt.Errorf("statfs(%q): %v", path, err)
+	}
+
+	var superOptions []string
+	if info, err := volumeutil.GetMountInfo(mountInfoPath, path); err != nil {
+		glog.V(4).Infof("Can't find mount options for %s: %v", path, err)
+	} else {
+		superOptions = info.SuperOptions
+	}
+
+	features := featuresForFSType(int64(buf.Type), superOptions)
+	features.SupportsXattr = xattrSupported(path)
+	return features, nil
+}
+
+// xattrSupported looks up an attribute which is never set.  ENODATA means
+// user xattrs are supported, while ENOTSUP means they are not.
+func xattrSupported(path string) bool {
+	_, err := syscall.Getxattr(path, xattrProbeName, nil)
+	return err == nil || err == syscall.ENODATA
+}
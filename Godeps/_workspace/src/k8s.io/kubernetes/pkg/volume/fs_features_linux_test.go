@@ -0,0 +1,59 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestFilesystemFeatures(t *testing.T) {
+	dirs := []string{"/dev/shm"}
+	if dir, err := ioutil.TempDir("", "fs_features_test"); err == nil {
+		defer os.RemoveAll(dir)
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		buf := syscall.Statfs_t{}
+		if err := syscall.Statfs(dir, &buf); err != nil {
+			t.Logf("skipping %s: %v", dir, err)
+			continue
+		}
+		features, err := FilesystemFeatures(dir)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", dir, err)
+			continue
+		}
+		switch int64(buf.Type) {
+		case fsTypeTmpfs, fsTypeExt, fsTypeXFS, fsTypeBtrfs:
+			if !features.SupportsSparse {
+				t.Errorf("%s: expected sparse file support, got %+v", dir, features)
+			}
+		}
+		if int64(buf.Type) == fsTypeTmpfs && (features.SupportsReflink || features.SupportsProjectQuota) {
+			t.Errorf("%s: expected tmpfs to support neither reflink nor project quota, got %+v", dir, features)
+		}
+	}
+
+	if _, err := FilesystemFeatures("/does/not/exist"); err == nil {
+		t.Errorf("Expected error for a missing path")
+	}
+}
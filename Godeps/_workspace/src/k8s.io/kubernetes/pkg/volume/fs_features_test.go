@@ -0,0 +1,45 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeaturesForFSType(t *testing.T) {
+	tests := []struct {
+		name         string
+		fsType       int64
+		superOptions []string
+		expected     FSFeatures
+	}{
+		{"tmpfs", fsTypeTmpfs, []string{"rw", "size=65536k"}, FSFeatures{SupportsSparse: true}},
+		{"ext4", fsTypeExt, []string{"rw", "errors=remount-ro"}, FSFeatures{SupportsSparse: true}},
+		{"ext4 with project quota", fsTypeExt, []string{"rw", "prjquota"}, FSFeatures{SupportsSparse: true, SupportsProjectQuota: true}},
+		{"xfs with project quota", fsTypeXFS, []string{"rw", "pquota"}, FSFeatures{SupportsSparse: true, SupportsProjectQuota: true}},
+		{"btrfs", fsTypeBtrfs, nil, FSFeatures{SupportsSparse: true, SupportsReflink: true}},
+		{"nfs", fsTypeNFS, []string{"rw", "vers=4.1"}, FSFeatures{}},
+		{"unknown", 0x1234, nil, FSFeatures{}},
+	}
+	for _, test := range tests {
+		features := featuresForFSType(test.fsType, test.superOptions)
+		if !reflect.DeepEqual(*features, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, *features)
+		}
+	}
+}
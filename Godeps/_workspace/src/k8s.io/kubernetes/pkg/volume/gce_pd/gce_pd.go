@@ -26,7 +26,6 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -45,9 +44,16 @@ var _ volume.VolumePlugin = &gcePersistentDiskPlugin{}
 var _ volume.PersistentVolumePlugin = &gcePersistentDiskPlugin{}
 var _ volume.DeletableVolumePlugin = &gcePersistentDiskPlugin{}
 var _ volume.ProvisionableVolumePlugin = &gcePersistentDiskPlugin{}
+var _ volume.DeviceMountablePlugin = &gcePersistentDiskPlugin{}
+var _ volume.ReconstructableVolumePlugin = &gcePersistentDiskPlugin{}
+var _ volume.VolumePluginWithAttachLimits = &gcePersistentDiskPlugin{}
 
 const (
-	gcePersistentDiskPluginName = "kubernetes.io/gce-pd"
+	gcePersistentDiskPluginName     = "kubernetes.io/gce-pd"
+	gcePersistentDiskVolumeLimitKey = volume.VolumeLimitKeyPrefix + "gce-pd"
+
+	// A GCE instance can attach 16 persistent disks, more only with some machine types.
+	maxGCEPersistentDisks = 16
 )
 
 func (plugin *gcePersistentDiskPlugin) Init(host volume.VolumeHost) {
@@ -63,6 +69,14 @@ func (plugin *gcePersistentDiskPlugin) CanSupport(spec *volume.Spec) bool {
 		(spec.Volume != nil && spec.Volume.GCEPersistentDisk != nil)
 }
 
+func (plugin *gcePersistentDiskPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.GCEPersistentDisk == nil {
+		return "", fmt.Errorf("spec does not reference a GCE persistent disk volume")
+	}
+	return source.GCEPersistentDisk.PDName, nil
+}
+
 func (plugin *gcePersistentDiskPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -96,7 +110,7 @@ func (plugin *gcePersistentDiskPlugin) newBuilderInternal(spec *volume.Spec, pod
 		partition = strconv.Itoa(gce.Partition)
 	}
 
-	return &gcePersistentDiskBuilder{
+	builder := &gcePersistentDiskBuilder{
 		gcePersistentDisk: &gcePersistentDisk{
 			podUID:    podUID,
 			volName:   spec.Name(),
@@ -108,7 +122,40 @@ func (plugin *gcePersistentDiskPlugin) newBuilderInternal(spec *volume.Spec, pod
 		},
 		fsType:      fsType,
 		readOnly:    readOnly,
-		diskMounter: &mount.SafeFormatAndMount{mounter, exec.New()}}, nil
+		diskMounter: &volume.SafeFormatAndMount{Interface: mounter, Runner: exec.New()},
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
+}
+
+func (plugin *gcePersistentDiskPlugin) GetDeviceMountPath(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.GCEPersistentDisk == nil {
+		return "", fmt.Errorf("spec does not reference a GCE persistent disk")
+	}
+	return makeGlobalPDName(plugin.host, source.GCEPersistentDisk.PDName), nil
+}
+
+func (plugin *gcePersistentDiskPlugin) ConstructVolumeSpec(volumeName, mountPath string) (*volume.Spec, error) {
+	globalPath, err := volume.GetGlobalMountRef(plugin.host, gcePersistentDiskPluginName, mountPath)
+	if err != nil {
+		return nil, err
+	}
+	// The global mount is named after the PD, see makeGlobalPDName.
+	return volume.NewSpecFromVolume(&api.Volume{
+		Name: volumeName,
+		VolumeSource: api.VolumeSource{
+			GCEPersistentDisk: &api.GCEPersistentDiskVolumeSource{PDName: path.Base(globalPath)},
+		},
+	}), nil
+}
+
+func (plugin *gcePersistentDiskPlugin) GetVolumeLimits() (map[string]int64, error) {
+	return map[string]int64{gcePersistentDiskVolumeLimitKey: maxGCEPersistentDisks}, nil
+}
+
+func (plugin *gcePersistentDiskPlugin) VolumeLimitKey(spec *volume.Spec) string {
+	return gcePersistentDiskVolumeLimitKey
 }
 
 func (plugin *gcePersistentDiskPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -140,7 +187,13 @@ func (plugin *gcePersistentDiskPlugin) newDeleterInternal(spec *volume.Spec, man
 			pdName:  spec.PersistentVolume.Spec.GCEPersistentDisk.PDName,
 			manager: manager,
 			plugin:  plugin,
-		}}, nil
+		},
+		zone: spec.PersistentVolume.Labels[volume.ZoneAnnotation],
+	}, nil
+}
+
+func (plugin *gcePersistentDiskPlugin) SupportsCloning() bool {
+	return false
 }
 
 func (plugin *gcePersistentDiskPlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
@@ -151,13 +204,32 @@ func (plugin *gcePersistentDiskPlugin) NewProvisioner(options volume.VolumeOptio
 }
 
 func (plugin *gcePersistentDiskPlugin) newProvisionerInternal(options volume.VolumeOptions, manager pdManager) (volume.Provisioner, error) {
-	return &gcePersistentDiskProvisioner{
+	provisioner := &gcePersistentDiskProvisioner{
 		gcePersistentDisk: &gcePersistentDisk{
 			manager: manager,
 			plugin:  plugin,
 		},
 		options: options,
-	}, nil
+	}
+	for k, v := range options.Parameters {
+		switch k {
+		case "type":
+			provisioner.diskType = v
+		case "zone":
+			// The zone requested by the claim wins.
+			if provisioner.options.Zone == "" {
+				provisioner.options.Zone = v
+			}
+		default:
+			return nil, fmt.Errorf("invalid option %q for volume plugin %s", k, gcePersistentDiskPluginName)
+		}
+	}
+	zone, err := volume.ChooseZone(provisioner.options)
+	if err != nil {
+		return nil, err
+	}
+	provisioner.options.Zone = zone
+	return provisioner, nil
 }
 
 // Abstract interface to PD operations.
@@ -170,6 +242,10 @@ type pdManager interface {
 	CreateVolume(provisioner *gcePersistentDiskProvisioner) (volumeID string, volumeSizeGB int, err error)
 	// Deletes a volume
 	DeleteVolume(deleter *gcePersistentDiskDeleter) error
+	// Checks that CreateVolume would succeed, without creating anything
+	ValidateCreateVolume(provisioner *gcePersistentDiskProvisioner) error
+	// Checks that DeleteVolume would succeed, without deleting anything
+	ValidateDeleteVolume(deleter *gcePersistentDiskDeleter) error
 }
 
 // gcePersistentDisk volumes are disk resources provided by Google Compute Engine
@@ -197,6 +273,7 @@ func detachDiskLogError(pd *gcePersistentDisk) {
 
 type gcePersistentDiskBuilder struct {
 	*gcePersistentDisk
+	volume.MetricsProvider
 	// Filesystem type, optional.
 	fsType string
 	// Specifies whether the disk will be attached as read-only.
@@ -206,10 +283,7 @@ type gcePersistentDiskBuilder struct {
 }
 
 var _ volume.Builder = &gcePersistentDiskBuilder{}
-
-func (_ *gcePersistentDiskBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
+var _ volume.DiskBuilder = &gcePersistentDiskBuilder{}
 
 // SetUp attaches the disk and bind mounts to the volume path.
 func (b *gcePersistentDiskBuilder) SetUp() error {
@@ -267,7 +341,7 @@ func (b *gcePersistentDiskBuilder) SetUpAt(dir string) error {
 				return err
 			}
 		}
-		os.Remove(dir)
+		volume.CleanupStagingDir(dir, err)
 		// TODO: we should really eject the attach/detach out into its own control loop.
 		detachDiskLogError(b.gcePersistentDisk)
 		return err
@@ -276,12 +350,17 @@ func (b *gcePersistentDiskBuilder) SetUpAt(dir string) error {
 	return nil
 }
 
-func (b *gcePersistentDiskBuilder) IsReadOnly() bool {
-	return b.readOnly
+func (b *gcePersistentDiskBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *gcePersistentDiskBuilder) SupportsSELinux() bool {
-	return true
+func (b *gcePersistentDiskBuilder) FSType() string {
+	return b.fsType
 }
 
 func makeGlobalPDName(host volume.VolumeHost, devName string) string {
@@ -290,7 +369,7 @@ func makeGlobalPDName(host volume.VolumeHost, devName string) string {
 
 func (pd *gcePersistentDisk) GetPath() string {
 	name := gcePersistentDiskPluginName
-	return pd.plugin.host.GetPodVolumeDir(pd.podUID, util.EscapeQualifiedNameForDisk(name), pd.volName)
+	return volume.NewDirectoryLayout(pd.plugin.host).PodVolumeDir(pd.podUID, name, pd.volName)
 }
 
 type gcePersistentDiskCleaner struct {
@@ -316,7 +395,7 @@ func (c *gcePersistentDiskCleaner) TearDownAt(dir string) error {
 		return os.Remove(dir)
 	}
 
-	refs, err := mount.GetMountRefs(c.mounter, dir)
+	_, refs, err := mount.PathExistsWithRefs(c.mounter, dir)
 	if err != nil {
 		return err
 	}
@@ -324,11 +403,11 @@ func (c *gcePersistentDiskCleaner) TearDownAt(dir string) error {
 	if err := c.mounter.Unmount(dir); err != nil {
 		return err
 	}
-	// If len(refs) is 1, then all bind mounts have been removed, and the
-	// remaining reference is the global mount. It is safe to detach.
-	if len(refs) == 1 {
+	// If the global mount is the last reference, then no other pod uses
+	// the disk anymore. It is safe to detach.
+	if globalPath, ok := volume.LastGlobalMountRef(c.plugin.host, refs); ok {
 		// c.pdName is not initially set for volume-cleaners, so set it here.
-		c.pdName = path.Base(refs[0])
+		c.pdName = path.Base(globalPath)
 		if err := c.manager.DetachDisk(c); err != nil {
 			return err
 		}
@@ -348,27 +427,40 @@ func (c *gcePersistentDiskCleaner) TearDownAt(dir string) error {
 
 type gcePersistentDiskDeleter struct {
 	*gcePersistentDisk
+	// zone the disk was provisioned in, empty for the zone of the controller.
+	zone string
 }
 
 var _ volume.Deleter = &gcePersistentDiskDeleter{}
+var _ volume.DryRunDeleter = &gcePersistentDiskDeleter{}
 
 func (d *gcePersistentDiskDeleter) GetPath() string {
 	name := gcePersistentDiskPluginName
-	return d.plugin.host.GetPodVolumeDir(d.podUID, util.EscapeQualifiedNameForDisk(name), d.volName)
+	return volume.NewDirectoryLayout(d.plugin.host).PodVolumeDir(d.podUID, name, d.volName)
 }
 
 func (d *gcePersistentDiskDeleter) Delete() error {
 	return d.manager.DeleteVolume(d)
 }
 
+func (d *gcePersistentDiskDeleter) ValidateDelete() error {
+	return d.manager.ValidateDeleteVolume(d)
+}
+
 type gcePersistentDiskProvisioner struct {
 	*gcePersistentDisk
 	options volume.VolumeOptions
+	// diskType is the type of the disk to create, e.g. "pd-ssd", or empty for the default of GCE.
+	diskType string
 }
 
 var _ volume.Provisioner = &gcePersistentDiskProvisioner{}
+var _ volume.DryRunProvisioner = &gcePersistentDiskProvisioner{}
 
 func (c *gcePersistentDiskProvisioner) Provision(pv *api.PersistentVolume) error {
+	if err := c.validateOptions(); err != nil {
+		return err
+	}
 	volumeID, sizeGB, err := c.manager.CreateVolume(c)
 	if err != nil {
 		return err
@@ -380,13 +472,38 @@ func (c *gcePersistentDiskProvisioner) Provision(pv *api.PersistentVolume) error
 	return nil
 }
 
+func (c *gcePersistentDiskProvisioner) ValidateProvision(pv *api.PersistentVolume) error {
+	if err := c.validateOptions(); err != nil {
+		return err
+	}
+	return c.manager.ValidateCreateVolume(c)
+}
+
+// validateOptions checks the options which can be rejected without asking GCE.
+func (c *gcePersistentDiskProvisioner) validateOptions() error {
+	if c.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: gcePersistentDiskPluginName, SnapshotID: c.options.SnapshotSource}
+	}
+	if c.options.CloneSource != nil {
+		return &volume.ErrCloneSourceNotSupported{Plugin: gcePersistentDiskPluginName, Source: c.options.CloneSource.Name}
+	}
+	if c.options.Capacity.Value() <= 0 {
+		return fmt.Errorf("invalid capacity %s for volume plugin %s", c.options.Capacity.String(), gcePersistentDiskPluginName)
+	}
+	return nil
+}
+
 func (c *gcePersistentDiskProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
+	labels := map[string]string{}
+	if c.options.Zone != "" {
+		labels[volume.ZoneAnnotation] = c.options.Zone
+	}
 	// Provide dummy api.PersistentVolume.Spec, it will be filled in
 	// gcePersistentDiskProvisioner.Provision()
-	return &api.PersistentVolume{
+	pv := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
 			GenerateName: "pv-gce-",
-			Labels:       map[string]string{},
+			Labels:       labels,
 			Annotations: map[string]string{
 				"kubernetes.io/createdby": "gce-pd-dynamic-provisioner",
 			},
@@ -406,5 +523,11 @@ func (c *gcePersistentDiskProvisioner) NewPersistentVolumeTemplate() (*api.Persi
 				},
 			},
 		},
-	}, nil
+	}
+	if c.options.Zone != "" {
+		if err := volume.SetNodeAffinity(pv, volume.ZoneNodeAffinity(c.options.Zone)); err != nil {
+			return nil, err
+		}
+	}
+	return pv, nil
 }
@@ -74,6 +74,8 @@ func contains(modes []api.PersistentVolumeAccessMode, mode api.PersistentVolumeA
 type fakePDManager struct {
 	attachCalled bool
 	detachCalled bool
+	// validateErr is returned by ValidateCreateVolume and ValidateDeleteVolume.
+	validateErr error
 }
 
 // TODO(jonesdl) To fully test this, we could create a loopback device
@@ -112,6 +114,14 @@ func (fake *fakePDManager) DeleteVolume(cd *gcePersistentDiskDeleter) error {
 	return nil
 }
 
+func (fake *fakePDManager) ValidateCreateVolume(c *gcePersistentDiskProvisioner) error {
+	return fake.validateErr
+}
+
+func (fake *fakePDManager) ValidateDeleteVolume(cd *gcePersistentDiskDeleter) error {
+	return fake.validateErr
+}
+
 func TestPlugin(t *testing.T) {
 	plugMgr := volume.VolumePluginMgr{}
 	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
@@ -228,6 +238,170 @@ func TestPlugin(t *testing.T) {
 	}
 }
 
+type fakeZonePDManager struct {
+	fakePDManager
+	createZone string
+	deleteZone string
+}
+
+func (fake *fakeZonePDManager) CreateVolume(c *gcePersistentDiskProvisioner) (volumeID string, volumeSizeGB int, err error) {
+	fake.createZone = c.options.Zone
+	return "test-gce-volume-name", 100, nil
+}
+
+func (fake *fakeZonePDManager) DeleteVolume(cd *gcePersistentDiskDeleter) error {
+	fake.deleteZone = cd.zone
+	return nil
+}
+
+func TestProvisionInZone(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/gce-pd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	options := volume.VolumeOptions{
+		Capacity:                      resource.MustParse("1Gi"),
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+		Zone:                          "us-central1-b",
+	}
+	manager := &fakeZonePDManager{}
+	provisioner, err := plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("NewPersistentVolumeTemplate() failed: %v", err)
+	}
+	if pv.Labels[volume.ZoneAnnotation] != "us-central1-b" {
+		t.Errorf("Expected the volume to be labeled with its zone, got %v", pv.Labels)
+	}
+	if affinity, err := volume.GetNodeAffinity(pv); err != nil || !affinity.Matches(map[string]string{volume.ZoneLabel: "us-central1-b"}) {
+		t.Errorf("Expected the volume to be usable from nodes in us-central1-b, got %v, %v", affinity, err)
+	}
+	if err := provisioner.Provision(pv); err != nil {
+		t.Fatalf("Provision() failed: %v", err)
+	}
+	if manager.createZone != "us-central1-b" {
+		t.Errorf("Expected the disk to be created in us-central1-b, got %q", manager.createZone)
+	}
+
+	deleter, err := plug.(*gcePersistentDiskPlugin).newDeleterInternal(&volume.Spec{PersistentVolume: pv}, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Deleter: %v", err)
+	}
+	if err := deleter.Delete(); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if manager.deleteZone != "us-central1-b" {
+		t.Errorf("Expected the disk to be deleted in us-central1-b, got %q", manager.deleteZone)
+	}
+}
+
+func TestProvisionParameters(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/gce-pd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	options := volume.VolumeOptions{
+		Capacity:                      resource.MustParse("1Gi"),
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+		Parameters:                    map[string]string{"type": "pd-ssd", "zone": "us-central1-c"},
+	}
+	provisioner, err := plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, &fakeZonePDManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	if p := provisioner.(*gcePersistentDiskProvisioner); p.diskType != "pd-ssd" || p.options.Zone != "us-central1-c" {
+		t.Errorf("Expected a pd-ssd disk in us-central1-c, got %q in %q", p.diskType, p.options.Zone)
+	}
+
+	// The zone of the claim wins over the one of the class.
+	options.Zone = "us-central1-b"
+	provisioner, err = plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, &fakeZonePDManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	if zone := provisioner.(*gcePersistentDiskProvisioner).options.Zone; zone != "us-central1-b" {
+		t.Errorf("Expected the zone of the claim, got %q", zone)
+	}
+
+	options.Parameters = map[string]string{"iopsPerGB": "10"}
+	if _, err := plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, &fakeZonePDManager{}); err == nil {
+		t.Errorf("Expected an error for an unknown parameter")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/gce-pd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	options := volume.VolumeOptions{
+		Capacity:                      resource.MustParse("1Gi"),
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+	}
+	manager := &fakeZonePDManager{}
+	provisioner, err := plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("NewPersistentVolumeTemplate() failed: %v", err)
+	}
+	if err := volume.DryRunProvision(provisioner, pv); err != nil {
+		t.Errorf("DryRunProvision() failed: %v", err)
+	}
+	if manager.createZone != "" || pv.Spec.GCEPersistentDisk.PDName != "dummy" {
+		t.Errorf("Expected a dry run not to create a disk")
+	}
+
+	manager.validateErr = fmt.Errorf("quota exceeded")
+	if err := volume.DryRunProvision(provisioner, pv); err != manager.validateErr {
+		t.Errorf("Expected the error of the backend, got %v", err)
+	}
+	deleter, err := plug.(*gcePersistentDiskPlugin).newDeleterInternal(&volume.Spec{PersistentVolume: pv}, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Deleter: %v", err)
+	}
+	if err := volume.DryRunDelete(deleter); err != manager.validateErr {
+		t.Errorf("Expected the error of the backend, got %v", err)
+	}
+	if manager.deleteZone != "" {
+		t.Errorf("Expected a dry run not to delete the disk")
+	}
+
+	// Options which GCE would reject are caught without asking it.
+	manager.validateErr = nil
+	options.SnapshotSource = "snap-1"
+	provisioner, err = plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	if err := volume.DryRunProvision(provisioner, pv); err == nil {
+		t.Errorf("Expected an error for a snapshot source")
+	}
+	options.SnapshotSource = ""
+	options.Capacity = resource.MustParse("0")
+	provisioner, err = plug.(*gcePersistentDiskPlugin).newProvisionerInternal(options, manager)
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	if err := volume.DryRunProvision(provisioner, pv); err == nil {
+		t.Errorf("Expected an error for an empty capacity")
+	}
+}
+
 func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pv := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
@@ -271,7 +445,80 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestGetDeviceMountPath(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{GCEPersistentDisk: &api.GCEPersistentDiskVolumeSource{PDName: "pd"}},
+	})
+	plug, err := plugMgr.FindDeviceMountablePluginBySpec(spec)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by spec: %v", err)
+	}
+	mountPath, err := plug.GetDeviceMountPath(spec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mountPath != "/tmp/fake/plugins/kubernetes.io/gce-pd/mounts/pd" {
+		t.Errorf("Unexpected device mount path: %s", mountPath)
+	}
+}
+
+func TestConstructVolumeSpec(t *testing.T) {
+	host := volume.NewFakeVolumeHost("/tmp/fake", nil, nil)
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), host)
+
+	plug, err := plugMgr.FindReconstructablePluginByName(gcePersistentDiskPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name: %v", err)
+	}
+	mountPath := host.GetPodVolumeDir("poduid", "kubernetes.io~gce-pd", "vol1")
+	host.GetMounter().(*mount.FakeMounter).MountPoints = []mount.MountPoint{
+		{Device: "/dev/sdb", Path: "/tmp/fake/plugins/kubernetes.io/gce-pd/mounts/pd"},
+		{Device: "/dev/sdb", Path: mountPath},
+	}
+	spec, err := plug.ConstructVolumeSpec("vol1", mountPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spec.Name() != "vol1" || spec.Volume.GCEPersistentDisk == nil || spec.Volume.GCEPersistentDisk.PDName != "pd" {
+		t.Errorf("Unexpected spec: %+v", spec.Volume)
+	}
+
+	if _, err := plug.ConstructVolumeSpec("vol2", host.GetPodVolumeDir("poduid", "kubernetes.io~gce-pd", "vol2")); err == nil {
+		t.Errorf("Expected an error for a volume without a global mount")
+	}
+}
+
+func TestGetVolumeLimits(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{GCEPersistentDisk: &api.GCEPersistentDiskVolumeSource{PDName: "pd"}},
+	})
+	plug, err := plugMgr.FindVolumePluginWithLimitsBySpec(spec)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by spec: %v", err)
+	}
+	limits, err := plug.GetVolumeLimits()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	key := plug.VolumeLimitKey(spec)
+	if key != "attachable-volumes-gce-pd" || limits[key] != 16 {
+		t.Errorf("Unexpected limit %s: %v", key, limits)
+	}
+	if plugins := plugMgr.ListVolumePluginWithLimits(); len(plugins) != 1 {
+		t.Errorf("Expected one plugin with limits, got %v", plugins)
 	}
 }
@@ -90,7 +90,7 @@ func (diskUtil *GCEDiskUtil) AttachAndMountDisk(b *gcePersistentDiskBuilder, glo
 	if notMnt {
 		err = b.diskMounter.Mount(devicePath, globalPDPath, b.fsType, options)
 		if err != nil {
-			os.Remove(globalPDPath)
+			volume.CleanupStagingDir(globalPDPath, err)
 			return err
 		}
 	}
@@ -127,7 +127,7 @@ func (util *GCEDiskUtil) DeleteVolume(d *gcePersistentDiskDeleter) error {
 		return err
 	}
 
-	if err = cloud.DeleteDisk(d.pdName); err != nil {
+	if err = cloud.DeleteDisk(d.pdName, d.zone); err != nil {
 		glog.V(2).Infof("Error deleting GCE PD volume %s: %v", d.pdName, err)
 		return err
 	}
@@ -142,10 +142,8 @@ func (gceutil *GCEDiskUtil) CreateVolume(c *gcePersistentDiskProvisioner) (volum
 	}
 
 	name := fmt.Sprintf("kube-dynamic-%s", util.NewUUID())
-	requestBytes := c.options.Capacity.Value()
-	// GCE works with gigabytes, convert to GiB with rounding up
-	requestGB := volume.RoundUpSize(requestBytes, 1024*1024*1024)
-	err = cloud.CreateDisk(name, int64(requestGB))
+	requestGB := requestedGB(c)
+	err = cloud.CreateDisk(name, c.diskType, c.options.Zone, int64(requestGB))
 	if err != nil {
 		glog.V(2).Infof("Error creating GCE PD volume: %v", err)
 		return "", 0, err
@@ -154,6 +152,28 @@ func (gceutil *GCEDiskUtil) CreateVolume(c *gcePersistentDiskProvisioner) (volum
 	return name, int(requestGB), nil
 }
 
+func (gceutil *GCEDiskUtil) ValidateCreateVolume(c *gcePersistentDiskProvisioner) error {
+	cloud, err := getCloudProvider()
+	if err != nil {
+		return err
+	}
+	return cloud.ValidateDisk(c.diskType, c.options.Zone, int64(requestedGB(c)))
+}
+
+func (util *GCEDiskUtil) ValidateDeleteVolume(d *gcePersistentDiskDeleter) error {
+	cloud, err := getCloudProvider()
+	if err != nil {
+		return err
+	}
+	return cloud.ValidateDiskDeletion(d.pdName, d.zone)
+}
+
+// requestedGB returns the size of the disk to create for c.  GCE works with
+// gigabytes, so the requested capacity is converted to GiB, rounding up.
+func requestedGB(c *gcePersistentDiskProvisioner) int64 {
+	return volume.RoundUpToGiB(c.options.Capacity)
+}
+
 // Attaches the specified persistent disk device to node, verifies that it is attached, and retries if it fails.
 func attachDiskAndVerify(b *gcePersistentDiskBuilder, sdBeforeSet sets.String) (string, error) {
 	devicePaths := getDiskByIdPaths(b.gcePersistentDisk)
@@ -23,7 +23,6 @@ import (
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/volume"
 	volumeutil "k8s.io/kubernetes/pkg/volume/util"
@@ -56,6 +55,13 @@ func (plugin *gitRepoPlugin) CanSupport(spec *volume.Spec) bool {
 	return spec.Volume != nil && spec.Volume.GitRepo != nil
 }
 
+func (plugin *gitRepoPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.GitRepo == nil {
+		return "", fmt.Errorf("spec does not reference a gitRepo volume")
+	}
+	return spec.Name(), nil
+}
+
 func (plugin *gitRepoPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	return &gitRepoVolumeBuilder{
 		gitRepoVolume: &gitRepoVolume{
@@ -93,7 +99,7 @@ var _ volume.Volume = &gitRepoVolume{}
 
 func (gr *gitRepoVolume) GetPath() string {
 	name := gitRepoPluginName
-	return gr.plugin.host.GetPodVolumeDir(gr.podUID, util.EscapeQualifiedNameForDisk(name), gr.volName)
+	return volume.NewDirectoryLayout(gr.plugin.host).PodVolumeDir(gr.podUID, name, gr.volName)
 }
 
 // gitRepoVolumeBuilder builds git repo volumes.
@@ -109,21 +115,18 @@ type gitRepoVolumeBuilder struct {
 
 var _ volume.Builder = &gitRepoVolumeBuilder{}
 
-func (_ *gitRepoVolumeBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
-
 // SetUp creates new directory and clones a git repo.
 func (b *gitRepoVolumeBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
 }
 
-func (b *gitRepoVolumeBuilder) IsReadOnly() bool {
-	return false
-}
-
-func (b *gitRepoVolumeBuilder) SupportsSELinux() bool {
-	return true
+func (b *gitRepoVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            false,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeAlways,
+		SupportsSELinux:     true,
+	}
 }
 
 // This is the spec for the volume that this plugin wraps.
@@ -176,7 +179,7 @@ func (b *gitRepoVolumeBuilder) SetUpAt(dir string) error {
 }
 
 func (b *gitRepoVolumeBuilder) getMetaDir() string {
-	return path.Join(b.plugin.host.GetPodPluginDir(b.podUID, util.EscapeQualifiedNameForDisk(gitRepoPluginName)), b.volName)
+	return path.Join(volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.podUID, gitRepoPluginName), b.volName)
 }
 
 func (b *gitRepoVolumeBuilder) execCommand(command string, args []string, dir string) ([]byte, error) {
@@ -17,14 +17,16 @@ limitations under the License.
 package glusterfs
 
 import (
+	"bufio"
 	"fmt"
+	"math/rand"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -72,6 +74,14 @@ func (plugin *glusterfsPlugin) CanSupport(spec *volume.Spec) bool {
 
 }
 
+func (plugin *glusterfsPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.Glusterfs == nil {
+		return "", fmt.Errorf("spec does not reference a glusterfs volume")
+	}
+	return fmt.Sprintf("%s:%s", source.Glusterfs.EndpointsName, source.Glusterfs.Path), nil
+}
+
 func (plugin *glusterfsPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -94,13 +104,7 @@ func (plugin *glusterfsPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ vol
 }
 
 func (plugin *glusterfsPlugin) getGlusterVolumeSource(spec *volume.Spec) (*api.GlusterfsVolumeSource, bool) {
-	// Glusterfs volumes used directly in a pod have a ReadOnly flag set by the pod author.
-	// Glusterfs volumes used as a PersistentVolume gets the ReadOnly flag indirectly through the persistent-claim volume used to mount the PV
-	if spec.Volume != nil && spec.Volume.Glusterfs != nil {
-		return spec.Volume.Glusterfs, spec.Volume.Glusterfs.ReadOnly
-	} else {
-		return spec.PersistentVolume.Spec.Glusterfs, spec.ReadOnly
-	}
+	return spec.PersistentVolumeSource().Glusterfs, spec.IsReadOnly()
 }
 
 func (plugin *glusterfsPlugin) newBuilderInternal(spec *volume.Spec, ep *api.Endpoints, pod *api.Pod, mounter mount.Interface, exe exec.Interface) (volume.Builder, error) {
@@ -112,10 +116,11 @@ func (plugin *glusterfsPlugin) newBuilderInternal(spec *volume.Spec, ep *api.End
 			pod:     pod,
 			plugin:  plugin,
 		},
-		hosts:    ep,
-		path:     source.Path,
-		readOnly: readOnly,
-		exe:      exe}, nil
+		hosts:        ep,
+		path:         source.Path,
+		readOnly:     readOnly,
+		mountOptions: spec.MountOptions(),
+		exe:          exe}, nil
 }
 
 func (plugin *glusterfsPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -146,18 +151,15 @@ type glusterfs struct {
 
 type glusterfsBuilder struct {
 	*glusterfs
-	hosts    *api.Endpoints
-	path     string
-	readOnly bool
-	exe      exec.Interface
+	hosts        *api.Endpoints
+	path         string
+	readOnly     bool
+	mountOptions []string
+	exe          exec.Interface
 }
 
 var _ volume.Builder = &glusterfsBuilder{}
 
-func (_ *glusterfsBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
-
 // SetUp attaches the disk and bind mounts to the volume path.
 func (b *glusterfsBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
@@ -180,22 +182,31 @@ func (b *glusterfsBuilder) SetUpAt(dir string) error {
 	}
 
 	// Cleanup upon failure.
-	c := &glusterfsCleaner{b.glusterfs}
-	c.cleanup(dir)
+	if mntErr := volume.UnmountMountPoint(dir, b.mounter); mntErr != nil {
+		glog.Errorf("glusterfs: failed to clean up %s after a failed mount: %v", dir, mntErr)
+		return err
+	}
+	volume.CleanupStagingDir(dir, err)
 	return err
 }
 
-func (b *glusterfsBuilder) IsReadOnly() bool {
-	return b.readOnly
-}
-
-func (b *glusterfsBuilder) SupportsSELinux() bool {
-	return false
+func (b *glusterfsBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
 }
 
 func (glusterfsVolume *glusterfs) GetPath() string {
 	name := glusterfsPluginName
-	return glusterfsVolume.plugin.host.GetPodVolumeDir(glusterfsVolume.pod.UID, util.EscapeQualifiedNameForDisk(name), glusterfsVolume.volName)
+	return volume.NewDirectoryLayout(glusterfsVolume.plugin.host).PodVolumeDir(glusterfsVolume.pod.UID, name, glusterfsVolume.volName)
+}
+
+// GetMetrics reports the usage of the mounted share.  Walking the share over
+// the network would be too slow, so its whole filesystem is reported.
+func (glusterfsVolume *glusterfs) GetMetrics() (*volume.Metrics, error) {
+	return volume.NewMetricsStatFS(glusterfsVolume.GetPath()).GetMetrics()
 }
 
 type glusterfsCleaner struct {
@@ -213,27 +224,9 @@ func (c *glusterfsCleaner) TearDownAt(dir string) error {
 }
 
 func (c *glusterfsCleaner) cleanup(dir string) error {
-	notMnt, err := c.mounter.IsLikelyNotMountPoint(dir)
-	if err != nil {
-		return fmt.Errorf("glusterfs: Error checking IsLikelyNotMountPoint: %v", err)
-	}
-	if notMnt {
-		return os.RemoveAll(dir)
-	}
-
-	if err := c.mounter.Unmount(dir); err != nil {
-		return fmt.Errorf("glusterfs: Unmounting failed: %v", err)
-	}
-	notMnt, mntErr := c.mounter.IsLikelyNotMountPoint(dir)
-	if mntErr != nil {
-		return fmt.Errorf("glusterfs: IsLikelyNotMountPoint check failed: %v", mntErr)
+	if err := volume.UnmountPath(dir, c.mounter); err != nil {
+		return fmt.Errorf("glusterfs: %v", err)
 	}
-	if notMnt {
-		if err := os.RemoveAll(dir); err != nil {
-			return fmt.Errorf("glusterfs: RemoveAll failed: %v", err)
-		}
-	}
-
 	return nil
 }
 
@@ -249,23 +242,63 @@ func (b *glusterfsBuilder) setUpAtInternal(dir string) error {
 	if err := os.MkdirAll(p, 0750); err != nil {
 		return fmt.Errorf("glusterfs: mkdir failed: %v", err)
 	}
-	log := path.Join(p, "glusterfs.log")
+	log := path.Join(p, b.pod.Name+"-glusterfs.log")
 	options = append(options, "log-file="+log)
 
-	addr := make(map[string]struct{})
+	addrs := []string{}
+	seen := make(map[string]bool)
 	for _, s := range b.hosts.Subsets {
 		for _, a := range s.Addresses {
-			addr[a.IP] = struct{}{}
+			if !seen[a.IP] {
+				seen[a.IP] = true
+				addrs = append(addrs, a.IP)
+			}
 		}
 	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("glusterfs: endpoints %s has no addresses", b.hosts.Name)
+	}
 
 	// Avoid mount storm, pick a host randomly.
-	// Iterate all hosts until mount succeeds.
-	for hostIP := range addr {
-		errs = b.mounter.Mount(hostIP+":"+b.path, dir, "glusterfs", options)
-		if errs == nil {
-			return nil
-		}
+	// The mount helper falls back to the other hosts if it can't fetch
+	// the volume file from the first.
+	start := rand.Int() % len(addrs)
+	hosts := append(append([]string{}, addrs[start:]...), addrs[:start]...)
+	if len(hosts) > 1 {
+		options = append(options, "backup-volfile-servers="+strings.Join(hosts[1:], ":"))
+	}
+	options = volume.JoinMountOptions(b.mountOptions, options)
+
+	errs = b.mounter.Mount(hosts[0]+":"+b.path, dir, "glusterfs", options)
+	if errs == nil {
+		return nil
+	}
+	// The mount helper doesn't say why the mount failed, it writes
+	// that to the log file.
+	if tail, err := readLogTail(log, 2); err == nil && tail != "" {
+		return fmt.Errorf("glusterfs: mount failed: %v, the following error information was pulled from %s: %s", errs, log, tail)
 	}
 	return fmt.Errorf("glusterfs: mount failed: %v", errs)
 }
+
+// readLogTail returns the last n lines of the file at path.
+func readLogTail(path string, n int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
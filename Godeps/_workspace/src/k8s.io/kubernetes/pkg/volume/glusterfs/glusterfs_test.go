@@ -17,7 +17,12 @@ limitations under the License.
 package glusterfs
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path"
+	"sort"
+	"strings"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
@@ -209,7 +214,106 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+// recordingMounter records the options of each mount and fails them with err.
+type recordingMounter struct {
+	mount.FakeMounter
+	options [][]string
+	err     error
+}
+
+func (m *recordingMounter) Mount(source string, target string, fstype string, options []string) error {
+	m.options = append(m.options, options)
+	if m.err != nil {
+		return m.err
+	}
+	return m.FakeMounter.Mount(source, target, fstype, options)
+}
+
+func newTestBuilder(t *testing.T, basePath string, mounter mount.Interface, ips ...string) volume.Builder {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost(basePath, nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/glusterfs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	addresses := []api.EndpointAddress{}
+	for _, ip := range ips {
+		addresses = append(addresses, api.EndpointAddress{IP: ip})
+	}
+	ep := &api.Endpoints{ObjectMeta: api.ObjectMeta{Name: "foo"}, Subsets: []api.EndpointSubset{{Addresses: addresses}}}
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{Glusterfs: &api.GlusterfsVolumeSource{EndpointsName: "foo", Path: "bar"}},
+	})
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "mypod", UID: types.UID("poduid")}}
+	builder, err := plug.(*glusterfsPlugin).newBuilderInternal(spec, ep, pod, mounter, &exec.FakeExec{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	return builder
+}
+
+func TestBackupVolfileServers(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "glusterfs_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	mounter := &recordingMounter{}
+	builder := newTestBuilder(t, tmpDir, mounter, "127.0.0.1", "127.0.0.2", "127.0.0.1", "127.0.0.3")
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	if len(mounter.Log) != 1 {
+		t.Fatalf("Expected a single mount, got %#v", mounter.Log)
+	}
+	// Every host is used exactly once, either to mount or as a backup.
+	hosts := []string{strings.Split(mounter.Log[0].Source, ":")[0]}
+	for _, option := range mounter.options[0] {
+		if strings.HasPrefix(option, "backup-volfile-servers=") {
+			hosts = append(hosts, strings.Split(strings.TrimPrefix(option, "backup-volfile-servers="), ":")...)
+		}
+	}
+	sort.Strings(hosts)
+	if strings.Join(hosts, ",") != "127.0.0.1,127.0.0.2,127.0.0.3" {
+		t.Errorf("Expected each host to be used once, got %v from %v", hosts, mounter.options[0])
+	}
+}
+
+func TestMountFailureIncludesLog(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "glusterfs_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logDir := path.Join(tmpDir, "plugins", "kubernetes.io/glusterfs", "vol1")
+	if err := os.MkdirAll(logDir, 0750); err != nil {
+		t.Fatalf("Can't make the log dir: %v", err)
+	}
+	logContents := "[1] starting\n[2] connecting\n[3] failed to fetch volume file\n"
+	if err := ioutil.WriteFile(path.Join(logDir, "mypod-glusterfs.log"), []byte(logContents), 0640); err != nil {
+		t.Fatalf("Can't write the log: %v", err)
+	}
+
+	mounter := &recordingMounter{err: errors.New("exit status 1")}
+	builder := newTestBuilder(t, tmpDir, mounter, "127.0.0.1")
+	err = builder.SetUp()
+	if err == nil {
+		t.Fatalf("Expected the mount to fail")
+	}
+	if !strings.Contains(err.Error(), "[2] connecting\n[3] failed to fetch volume file") || strings.Contains(err.Error(), "[1] starting") {
+		t.Errorf("Expected the last two log lines in the error, got: %v", err)
+	}
+	for _, option := range mounter.options[0] {
+		if strings.HasPrefix(option, "backup-volfile-servers=") {
+			t.Errorf("Unexpected backup servers with a single host: %v", mounter.options[0])
+		}
 	}
 }
@@ -0,0 +1,272 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// healthCheckTimeout bounds a single volume's health check, so that a hung
+// backend (e.g. a stale NFS mount) does not hold up its caller.
+var healthCheckTimeout = 10 * time.Second
+
+// Reasons of the abnormal VolumeConditions reported by HealthCheckers.
+// Checkers may use reasons of their own.
+const (
+	// VolumeConditionReadOnly means the filesystem of a writable volume
+	// was remounted read-only, e.g. after I/O errors.
+	VolumeConditionReadOnly = "ReadOnlyFilesystem"
+	// VolumeConditionUnreachable means the target of the volume, e.g. its
+	// server or portal, can't be reached.
+	VolumeConditionUnreachable = "TargetUnreachable"
+	// VolumeConditionOutOfSpace means the volume has no space or inodes
+	// left.
+	VolumeConditionOutOfSpace = "OutOfSpace"
+)
+
+// VolumeAbnormal is the reason of the events recorded on pods whose volume
+// became abnormal.
+const VolumeAbnormal = "VolumeAbnormal"
+
+// VolumeCondition is the condition of a mounted volume.
+type VolumeCondition struct {
+	// Abnormal is true if the volume can't be used as expected.
+	Abnormal bool
+	// Reason is a short CamelCase reason of an abnormal condition, e.g.
+	// VolumeConditionOutOfSpace.
+	Reason string
+	// Message explains the condition to humans.
+	Message string
+}
+
+// HealthChecker is implemented by volumes which can check the health of
+// their backend.  Volumes which do not implement it are checked by
+// stat-ing their path.
+type HealthChecker interface {
+	// CheckVolumeHealth returns the condition of the volume.  An error
+	// means the condition could not be checked, not that the volume is
+	// abnormal.
+	CheckVolumeHealth() (*VolumeCondition, error)
+}
+
+// HealthResult is the outcome of checking a single volume.
+type HealthResult struct {
+	// Path identifies the volume checked.
+	Path string
+	// Healthy is true if the check passed and the volume is not abnormal;
+	// otherwise Err or Condition says why not.
+	Healthy bool
+	Err     error
+	// Condition is the condition reported by the volume's HealthChecker,
+	// if any.
+	Condition *VolumeCondition
+}
+
+// ErrHealthCheckTimeout is returned when a volume's health check did not
+// complete within the timeout.
+type ErrHealthCheckTimeout struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *ErrHealthCheckTimeout) Error() string {
+	return fmt.Sprintf("health check of volume %s did not complete within %s", e.Path, e.Timeout)
+}
+
+func checkHealth(v Volume) (*VolumeCondition, error) {
+	if checker, ok := UnwrapVolume(v).(HealthChecker); ok {
+		return checker.CheckVolumeHealth()
+	}
+	_, err := os.Stat(v.GetPath())
+	return nil, err
+}
+
+// checkHealthWithTimeout checks v, giving up after healthCheckTimeout or
+// when ctx is cancelled.  A check that is given up on keeps running in the
+// background, as there is no way to interrupt a hung filesystem call; if
+// finished is not nil, it is called once the check has returned.
+func checkHealthWithTimeout(ctx context.Context, v Volume, finished func()) HealthResult {
+	path := v.GetPath()
+	done := make(chan HealthResult, 1)
+	go func() {
+		if finished != nil {
+			defer finished()
+		}
+		condition, err := checkHealth(v)
+		done <- HealthResult{Path: path, Err: err, Condition: condition}
+	}()
+	var result HealthResult
+	select {
+	case result = <-done:
+	case <-time.After(healthCheckTimeout):
+		result = HealthResult{Path: path, Err: &ErrHealthCheckTimeout{Path: path, Timeout: healthCheckTimeout}}
+	case <-ctx.Done():
+		result = HealthResult{Path: path, Err: ctx.Err()}
+	}
+	result.Healthy = result.Err == nil && (result.Condition == nil || !result.Condition.Abnormal)
+	return result
+}
+
+// CheckHealthBatch checks the health of volumes with at most parallelism
+// checks in flight, and streams each result as soon as it is known.  The
+// returned channel is closed once every volume has been checked, or once
+// ctx is cancelled, after which no further checks are started.
+func CheckHealthBatch(ctx context.Context, volumes []Volume, parallelism int) <-chan HealthResult {
+	return checkHealthBatch(ctx, volumes, parallelism, nil)
+}
+
+// checkHealthBatch is CheckHealthBatch calling finished, if not nil, with
+// each volume whose check has returned, including checks given up on.
+func checkHealthBatch(ctx context.Context, volumes []Volume, parallelism int, finished func(Volume)) <-chan HealthResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	results := make(chan HealthResult, parallelism)
+	go func() {
+		defer close(results)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		tokens := make(chan struct{}, parallelism)
+		for _, v := range volumes {
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(v Volume) {
+				defer wg.Done()
+				defer func() { <-tokens }()
+				var done func()
+				if finished != nil {
+					done = func() { finished(v) }
+				}
+				result := checkHealthWithTimeout(ctx, v, done)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+			}(v)
+		}
+	}()
+	return results
+}
+
+// PeriodicHealthChecker checks the health of volumes every period and
+// reports the volumes whose health changed, e.g. so that the kubelet
+// records an event with reason VolumeAbnormal on their pods.  Only changes
+// are reported, so a volume that stays abnormal is not reported on every
+// check.  A volume whose previous check is still running, e.g. because its
+// mount hung, is not checked again until that check returns, so that hung
+// checks do not pile up.
+type PeriodicHealthChecker struct {
+	period      time.Duration
+	parallelism int
+	// volumes returns the volumes to check, typically the mounted ones.
+	volumes func() []Volume
+	report  func(HealthResult)
+
+	// last is the last result of each volume by path.  Only Run uses it.
+	last map[string]HealthResult
+
+	// lock protects inFlight, the paths of the volumes whose check has not
+	// returned yet.
+	lock     sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewPeriodicHealthChecker returns a PeriodicHealthChecker checking the
+// volumes returned by volumes with at most parallelism checks in flight,
+// see CheckHealthBatch, and passing changed results to report.
+func NewPeriodicHealthChecker(period time.Duration, parallelism int, volumes func() []Volume, report func(HealthResult)) *PeriodicHealthChecker {
+	return &PeriodicHealthChecker{
+		period:      period,
+		parallelism: parallelism,
+		volumes:     volumes,
+		report:      report,
+		last:        map[string]HealthResult{},
+		inFlight:    map[string]bool{},
+	}
+}
+
+// Run checks the volumes every period until stopCh is closed.
+func (c *PeriodicHealthChecker) Run(stopCh <-chan struct{}) {
+	util.Until(c.checkOnce, c.period, stopCh)
+}
+
+// checkOnce checks all volumes once and reports the changed ones.  The
+// first result of a volume is only reported if it is unhealthy.  Volumes
+// that are gone are forgotten.  Volumes whose previous check is still
+// running are skipped and keep their last result.
+func (c *PeriodicHealthChecker) checkOnce() {
+	seen := map[string]bool{}
+	volumes := []Volume{}
+	c.lock.Lock()
+	for _, v := range c.volumes() {
+		path := v.GetPath()
+		if c.inFlight[path] {
+			seen[path] = true
+			continue
+		}
+		c.inFlight[path] = true
+		volumes = append(volumes, v)
+	}
+	c.lock.Unlock()
+	for result := range checkHealthBatch(context.Background(), volumes, c.parallelism, c.finished) {
+		seen[result.Path] = true
+		last, found := c.last[result.Path]
+		c.last[result.Path] = result
+		if found && !healthChanged(last, result) {
+			continue
+		}
+		if !found && result.Healthy {
+			continue
+		}
+		c.report(result)
+	}
+	for path := range c.last {
+		if !seen[path] {
+			delete(c.last, path)
+		}
+	}
+}
+
+func (c *PeriodicHealthChecker) finished(v Volume) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.inFlight, v.GetPath())
+}
+
+func healthChanged(last, current HealthResult) bool {
+	if last.Healthy != current.Healthy {
+		return true
+	}
+	lastReason, currentReason := "", ""
+	if last.Condition != nil {
+		lastReason = last.Condition.Reason
+	}
+	if current.Condition != nil {
+		currentReason = current.Condition.Reason
+	}
+	return lastReason != currentReason
+}
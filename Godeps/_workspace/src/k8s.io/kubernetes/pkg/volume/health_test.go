@@ -0,0 +1,247 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type fakeHealthVolume struct {
+	path  string
+	delay time.Duration
+	// hang blocks the check until the channel is closed.
+	hang      chan struct{}
+	err       error
+	condition *VolumeCondition
+	tracker   *concurrencyTracker
+}
+
+func (v *fakeHealthVolume) GetPath() string {
+	return v.path
+}
+
+func (v *fakeHealthVolume) CheckVolumeHealth() (*VolumeCondition, error) {
+	if v.tracker != nil {
+		v.tracker.enter()
+		defer v.tracker.exit()
+	}
+	if v.hang != nil {
+		<-v.hang
+	}
+	time.Sleep(v.delay)
+	return v.condition, v.err
+}
+
+type concurrencyTracker struct {
+	lock    sync.Mutex
+	current int
+	max     int
+}
+
+func (c *concurrencyTracker) enter() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
+}
+
+func (c *concurrencyTracker) exit() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current--
+}
+
+func TestCheckHealthBatch(t *testing.T) {
+	defer func(timeout time.Duration) { healthCheckTimeout = timeout }(healthCheckTimeout)
+	healthCheckTimeout = 100 * time.Millisecond
+
+	hang := make(chan struct{})
+	defer close(hang)
+	tracker := &concurrencyTracker{}
+	volumes := []Volume{
+		&fakeHealthVolume{path: "/stale", hang: hang},
+		&fakeHealthVolume{path: "/slow", delay: 50 * time.Millisecond, tracker: tracker},
+		&fakeHealthVolume{path: "/broken", err: fmt.Errorf("backend unreachable"), tracker: tracker},
+	}
+	for i := 0; i < 6; i++ {
+		volumes = append(volumes, &fakeHealthVolume{path: fmt.Sprintf("/fast/%d", i), delay: 10 * time.Millisecond, tracker: tracker})
+	}
+
+	results := map[string]HealthResult{}
+	var order []string
+	for result := range CheckHealthBatch(context.Background(), volumes, 2) {
+		results[result.Path] = result
+		order = append(order, result.Path)
+	}
+
+	if len(results) != len(volumes) {
+		t.Fatalf("Expected %d results, got %v", len(volumes), order)
+	}
+	if _, ok := results["/stale"].Err.(*ErrHealthCheckTimeout); !ok || results["/stale"].Healthy {
+		t.Errorf("Expected stale volume to time out, got %+v", results["/stale"])
+	}
+	if r := results["/broken"]; r.Healthy || r.Err == nil {
+		t.Errorf("Expected broken volume to be unhealthy, got %+v", r)
+	}
+	for path, r := range results {
+		if path != "/stale" && path != "/broken" && (!r.Healthy || r.Err != nil) {
+			t.Errorf("Expected %s to be healthy, got %+v", path, r)
+		}
+	}
+	if tracker.max > 2 {
+		t.Errorf("Expected at most 2 concurrent checks, saw %d", tracker.max)
+	}
+}
+
+func TestCheckHealthBatchCancel(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	var volumes []Volume
+	for i := 0; i < 4; i++ {
+		volumes = append(volumes, &fakeHealthVolume{path: fmt.Sprintf("/hung/%d", i), hang: hang})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := CheckHealthBatch(ctx, volumes, 2)
+	cancel()
+	select {
+	case <-drain(results):
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the result channel to be closed after cancellation")
+	}
+}
+
+// drain reads results until the channel is closed.
+func drain(results <-chan HealthResult) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range results {
+		}
+		close(done)
+	}()
+	return done
+}
+
+func TestCheckHealthBatchAbnormalCondition(t *testing.T) {
+	volumes := []Volume{
+		&fakeHealthVolume{path: "/full", condition: &VolumeCondition{Abnormal: true, Reason: VolumeConditionOutOfSpace}},
+		&fakeHealthVolume{path: "/fine", condition: &VolumeCondition{Message: "all good"}},
+	}
+	results := map[string]HealthResult{}
+	for result := range CheckHealthBatch(context.Background(), volumes, 2) {
+		results[result.Path] = result
+	}
+	if r := results["/full"]; r.Healthy || r.Err != nil || r.Condition == nil || r.Condition.Reason != VolumeConditionOutOfSpace {
+		t.Errorf("Expected full volume to be abnormal, got %+v", r)
+	}
+	if r := results["/fine"]; !r.Healthy {
+		t.Errorf("Expected fine volume to be healthy, got %+v", r)
+	}
+}
+
+func TestPeriodicHealthCheckerReportsChanges(t *testing.T) {
+	volume := &fakeHealthVolume{path: "/vol"}
+	volumes := []Volume{volume}
+	var reported []HealthResult
+	checker := NewPeriodicHealthChecker(time.Second, 1, func() []Volume { return volumes }, func(result HealthResult) {
+		reported = append(reported, result)
+	})
+
+	checker.checkOnce()
+	if len(reported) != 0 {
+		t.Fatalf("Expected a healthy volume not to be reported, got %+v", reported)
+	}
+
+	volume.condition = &VolumeCondition{Abnormal: true, Reason: VolumeConditionReadOnly}
+	checker.checkOnce()
+	checker.checkOnce()
+	if len(reported) != 1 || reported[0].Condition.Reason != VolumeConditionReadOnly {
+		t.Fatalf("Expected the volume to be reported once when it became abnormal, got %+v", reported)
+	}
+
+	volume.condition = &VolumeCondition{Abnormal: true, Reason: VolumeConditionOutOfSpace}
+	checker.checkOnce()
+	if len(reported) != 2 || reported[1].Condition.Reason != VolumeConditionOutOfSpace {
+		t.Fatalf("Expected the volume to be reported when its reason changed, got %+v", reported)
+	}
+
+	volume.condition = nil
+	checker.checkOnce()
+	if len(reported) != 3 || !reported[2].Healthy {
+		t.Fatalf("Expected the volume to be reported when it recovered, got %+v", reported)
+	}
+
+	volumes = nil
+	checker.checkOnce()
+	if len(checker.last) != 0 {
+		t.Errorf("Expected volumes that are gone to be forgotten, got %+v", checker.last)
+	}
+}
+
+func TestPeriodicHealthCheckerSkipsHungVolumes(t *testing.T) {
+	defer func(timeout time.Duration) { healthCheckTimeout = timeout }(healthCheckTimeout)
+	healthCheckTimeout = 10 * time.Millisecond
+
+	tracker := &concurrencyTracker{}
+	volume := &fakeHealthVolume{path: "/vol", hang: make(chan struct{}), tracker: tracker}
+	var reported []HealthResult
+	checker := NewPeriodicHealthChecker(time.Second, 1, func() []Volume { return []Volume{volume} }, func(result HealthResult) {
+		reported = append(reported, result)
+	})
+
+	for i := 0; i < 3; i++ {
+		checker.checkOnce()
+	}
+	if len(reported) != 1 {
+		t.Fatalf("Expected the hung volume to be reported once, got %+v", reported)
+	}
+	if _, ok := reported[0].Err.(*ErrHealthCheckTimeout); !ok {
+		t.Errorf("Expected a timeout, got %+v", reported[0])
+	}
+	tracker.lock.Lock()
+	checks := tracker.max
+	tracker.lock.Unlock()
+	if checks != 1 {
+		t.Errorf("Expected the hung volume to be checked once, got %d checks in flight", checks)
+	}
+	if _, found := checker.last["/vol"]; !found {
+		t.Errorf("Expected the hung volume not to be forgotten")
+	}
+
+	// The volume is checked again once the hung check returns.
+	close(volume.hang)
+	for i := 0; i < 100; i++ {
+		checker.lock.Lock()
+		inFlight := len(checker.inFlight)
+		checker.lock.Unlock()
+		if inFlight == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	checker.checkOnce()
+	if len(reported) != 2 || !reported[1].Healthy {
+		t.Errorf("Expected the volume to be reported when its check returned, got %+v", reported)
+	}
+}
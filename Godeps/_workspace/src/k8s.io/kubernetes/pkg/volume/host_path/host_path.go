@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
 
@@ -86,6 +89,14 @@ func (plugin *hostPathPlugin) CanSupport(spec *volume.Spec) bool {
 		(spec.Volume != nil && spec.Volume.HostPath != nil)
 }
 
+func (plugin *hostPathPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.HostPath == nil {
+		return "", fmt.Errorf("spec does not reference a host path volume")
+	}
+	return source.HostPath.Path, nil
+}
+
 func (plugin *hostPathPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -96,11 +107,13 @@ func (plugin *hostPathPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volu
 	if spec.Volume != nil && spec.Volume.HostPath != nil {
 		return &hostPathBuilder{
 			hostPath: &hostPath{path: spec.Volume.HostPath.Path},
+			pathType: spec.Volume.HostPath.Type,
 			readOnly: false,
 		}, nil
 	} else {
 		return &hostPathBuilder{
 			hostPath: &hostPath{path: spec.PersistentVolume.Spec.HostPath.Path},
+			pathType: spec.PersistentVolume.Spec.HostPath.Type,
 			readOnly: spec.ReadOnly,
 		}, nil
 	}
@@ -118,6 +131,10 @@ func (plugin *hostPathPlugin) NewDeleter(spec *volume.Spec) (volume.Deleter, err
 	return plugin.newDeleterFunc(spec, plugin.host)
 }
 
+func (plugin *hostPathPlugin) SupportsCloning() bool {
+	return false
+}
+
 func (plugin *hostPathPlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
 	if len(options.AccessModes) == 0 {
 		options.AccessModes = plugin.GetAccessModes()
@@ -125,16 +142,25 @@ func (plugin *hostPathPlugin) NewProvisioner(options volume.VolumeOptions) (volu
 	return plugin.newProvisionerFunc(options, plugin.host)
 }
 
+// scrubberFactory makes the scrubbers of recyclers.  The files of a volume
+// can be deleted or shredded, but it is not a block device to reformat.
+var scrubberFactory = volume.NewScrubberFactory(volume.ScrubStrategyDelete, volume.ScrubStrategyShred)
+
 func newRecycler(spec *volume.Spec, host volume.VolumeHost, config volume.VolumeConfig) (volume.Recycler, error) {
 	if spec.PersistentVolume == nil || spec.PersistentVolume.Spec.HostPath == nil {
 		return nil, fmt.Errorf("spec.PersistentVolumeSource.HostPath is nil")
 	}
+	scrubber, err := scrubberFactory.NewScrubber(spec.PersistentVolume)
+	if err != nil {
+		return nil, err
+	}
 	return &hostPathRecycler{
-		name:    spec.Name(),
-		path:    spec.PersistentVolume.Spec.HostPath.Path,
-		host:    host,
-		config:  config,
-		timeout: volume.CalculateTimeoutForVolume(config.RecyclerMinimumTimeout, config.RecyclerTimeoutIncrement, spec.PersistentVolume),
+		name:     spec.Name(),
+		path:     spec.PersistentVolume.Spec.HostPath.Path,
+		host:     host,
+		config:   config,
+		timeout:  volume.CalculateTimeoutForVolume(config.RecyclerMinimumTimeout, config.RecyclerTimeoutIncrement, spec.PersistentVolume),
+		scrubber: scrubber,
 	}, nil
 }
 
@@ -161,18 +187,16 @@ func (hp *hostPath) GetPath() string {
 
 type hostPathBuilder struct {
 	*hostPath
+	pathType api.HostPathType
 	readOnly bool
 }
 
 var _ volume.Builder = &hostPathBuilder{}
 
-func (_ *hostPathBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
-
-// SetUp does nothing.
+// SetUp checks that the path has the requested type, creating it if the
+// type asks for that.  It does nothing if no type was requested.
 func (b *hostPathBuilder) SetUp() error {
-	return nil
+	return checkType(b.path, b.pathType)
 }
 
 // SetUpAt does not make sense for host paths - probably programmer error.
@@ -180,12 +204,12 @@ func (b *hostPathBuilder) SetUpAt(dir string) error {
 	return fmt.Errorf("SetUpAt() does not make sense for host paths")
 }
 
-func (b *hostPathBuilder) IsReadOnly() bool {
-	return b.readOnly
-}
-
-func (b *hostPathBuilder) SupportsSELinux() bool {
-	return false
+func (b *hostPathBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
 }
 
 func (b *hostPathBuilder) GetPath() string {
@@ -208,6 +232,52 @@ func (c *hostPathCleaner) TearDownAt(dir string) error {
 	return fmt.Errorf("TearDownAt() does not make sense for host paths")
 }
 
+// checkType returns an error unless path has type pathType.  A missing
+// directory or file is made for the *OrCreate types.
+func checkType(path string, pathType api.HostPathType) error {
+	if pathType == api.HostPathUnset {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		switch pathType {
+		case api.HostPathDirectoryOrCreate:
+			return os.MkdirAll(path, 0755)
+		case api.HostPathFileOrCreate:
+			f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+			if err != nil {
+				return err
+			}
+			return f.Close()
+		}
+		return fmt.Errorf("hostPath %s of type %s does not exist", path, pathType)
+	}
+	if err != nil {
+		return err
+	}
+
+	mode := info.Mode()
+	var matches bool
+	switch pathType {
+	case api.HostPathDirectoryOrCreate, api.HostPathDirectory:
+		matches = mode.IsDir()
+	case api.HostPathFileOrCreate, api.HostPathFile:
+		matches = mode.IsRegular()
+	case api.HostPathSocket:
+		matches = mode&os.ModeSocket != 0
+	case api.HostPathCharDev:
+		matches = mode&os.ModeDevice != 0 && mode&os.ModeCharDevice != 0
+	case api.HostPathBlockDev:
+		matches = mode&os.ModeDevice != 0 && mode&os.ModeCharDevice == 0
+	default:
+		return fmt.Errorf("hostPath %s has unsupported type %s", path, pathType)
+	}
+	if !matches {
+		return fmt.Errorf("hostPath %s is not of type %s", path, pathType)
+	}
+	return nil
+}
+
 // hostPathRecycler implements a Recycler for the HostPath plugin
 // This implementation is meant for testing only and only works in a single node cluster
 type hostPathRecycler struct {
@@ -216,6 +286,8 @@ type hostPathRecycler struct {
 	host    volume.VolumeHost
 	config  volume.VolumeConfig
 	timeout int64
+	// scrubber sets up the recycler pod to scrub the volume.
+	scrubber volume.Scrubber
 }
 
 func (r *hostPathRecycler) GetPath() string {
@@ -226,16 +298,39 @@ func (r *hostPathRecycler) GetPath() string {
 // Recycle blocks until the pod has completed or any error occurs.
 // HostPath recycling only works in single node clusters and is meant for testing purposes only.
 func (r *hostPathRecycler) Recycle() error {
-	pod := r.config.RecyclerPodTemplate
-	// overrides
-	pod.Spec.ActiveDeadlineSeconds = &r.timeout
-	pod.GenerateName = "pv-recycler-hostpath-"
-	pod.Spec.Volumes[0].VolumeSource = api.VolumeSource{
+	return r.RecycleWithTimeout(0, nil)
+}
+
+// RecycleWithTimeout is like Recycle, but gives up after timeout and
+// reports the phases of the recycler pod to progress.
+func (r *hostPathRecycler) RecycleWithTimeout(timeout time.Duration, progress volume.RecycleProgressFunc) error {
+	executor, err := r.newScrubExecutor()
+	if err != nil {
+		return err
+	}
+	return executor.Scrub(timeout, progress)
+}
+
+// newScrubExecutor returns the executor of the scrub, which runs on the host
+// if the config asks for it and in a recycler pod otherwise.
+func (r *hostPathRecycler) newScrubExecutor() (volume.ScrubExecutor, error) {
+	if r.config.RecyclerScrubOnHost {
+		mounter := r.host.GetMounter()
+		if mounter == nil {
+			mounter = mount.New()
+		}
+		return volume.NewHostScrubExecutor(r.scrubber.Strategy(), r.path, mounter, exec.New())
+	}
+	pod, err := volume.NewRecyclerPod(r.config, "pv-recycler-hostpath-", r.timeout, api.VolumeSource{
 		HostPath: &api.HostPathVolumeSource{
 			Path: r.path,
 		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	return volume.RecycleVolumeByWatchingPodUntilCompletion(pod, r.host.GetKubeClient())
+	r.scrubber.SetUpRecyclerPod(pod)
+	return volume.NewPodScrubExecutor(pod, r.host.GetKubeClient()), nil
 }
 
 // hostPathProvisioner implements a Provisioner for the HostPath plugin
@@ -248,6 +343,12 @@ type hostPathProvisioner struct {
 // Create for hostPath simply creates a local /tmp/hostpath_pv/%s directory as a new PersistentVolume.
 // This Provisioner is meant for development and testing only and WILL NOT WORK in a multi-node cluster.
 func (r *hostPathProvisioner) Provision(pv *api.PersistentVolume) error {
+	if r.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: hostPathPluginName, SnapshotID: r.options.SnapshotSource}
+	}
+	if r.options.CloneSource != nil {
+		return &volume.ErrCloneSourceNotSupported{Plugin: hostPathPluginName, Source: r.options.CloneSource.Name}
+	}
 	if pv.Spec.HostPath == nil {
 		return fmt.Errorf("pv.Spec.HostPath cannot be nil")
 	}
@@ -18,7 +18,10 @@ package host_path
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
 	"os"
+	"path"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
@@ -87,6 +90,33 @@ func TestRecycler(t *testing.T) {
 	}
 }
 
+func TestRecyclerScrubsOnHost(t *testing.T) {
+	tempPath, err := ioutil.TempDir("", "hostpath_pv")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempPath)
+	if err := ioutil.WriteFile(path.Join(tempPath, "file"), []byte("data"), 0640); err != nil {
+		t.Fatalf("can't write a file: %v", err)
+	}
+
+	spec := &volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{HostPath: &api.HostPathVolumeSource{Path: tempPath}}}}}
+	recycler, err := newRecycler(spec, volume.NewFakeVolumeHost("/tmp/fake", nil, nil), volume.VolumeConfig{RecyclerScrubOnHost: true})
+	if err != nil {
+		t.Fatalf("Failed to make a new Recycler: %v", err)
+	}
+	// Without a client, a recycler pod could not be created.
+	if err := recycler.Recycle(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path.Join(tempPath, "file")); !os.IsNotExist(err) {
+		t.Errorf("Expected the file to be scrubbed, got: %v", err)
+	}
+	if _, err := os.Stat(tempPath); err != nil {
+		t.Errorf("Expected the volume to be kept: %v", err)
+	}
+}
+
 func TestDeleter(t *testing.T) {
 	tempPath := fmt.Sprintf("/tmp/hostpath/%s", util.NewUUID())
 	defer os.RemoveAll(tempPath)
@@ -268,7 +298,105 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestProvisionerRejectsSnapshotSource(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	spec := &volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{HostPath: &api.HostPathVolumeSource{Path: "/tmp/hostpath/"}}}}}
+	plug, err := plugMgr.FindCreatablePluginBySpec(spec)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	creater, err := plug.NewProvisioner(volume.VolumeOptions{Capacity: resource.MustParse("1Gi"), SnapshotSource: "snap-1"})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := creater.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("Unexpected error creating volume: %v", err)
+	}
+	err = creater.Provision(pv)
+	if _, ok := err.(*volume.ErrSnapshotSourceNotSupported); !ok {
+		t.Errorf("Expected ErrSnapshotSourceNotSupported, got %v", err)
+	}
+	if _, err := os.Stat(pv.Spec.HostPath.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected no directory to be created, got %v", err)
+	}
+}
+
+func TestSetUpChecksPathType(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "hostpath_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	file := path.Join(tmpDir, "file")
+	if err := ioutil.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatalf("Can't write %s: %v", file, err)
+	}
+	socket := path.Join(tmpDir, "socket")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Can't listen on %s: %v", socket, err)
+	}
+	defer listener.Close()
+
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/host-path")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+
+	tests := []struct {
+		path     string
+		pathType api.HostPathType
+		success  bool
+	}{
+		{path.Join(tmpDir, "missing"), api.HostPathUnset, true},
+		{path.Join(tmpDir, "newdir"), api.HostPathDirectoryOrCreate, true},
+		{path.Join(tmpDir, "newfile"), api.HostPathFileOrCreate, true},
+		{tmpDir, api.HostPathDirectory, true},
+		{file, api.HostPathDirectory, false},
+		{file, api.HostPathDirectoryOrCreate, false},
+		{file, api.HostPathFile, true},
+		{path.Join(tmpDir, "missing"), api.HostPathFile, false},
+		{tmpDir, api.HostPathFileOrCreate, false},
+		{socket, api.HostPathSocket, true},
+		{file, api.HostPathSocket, false},
+		{tmpDir, api.HostPathBlockDev, false},
+		{socket, api.HostPathCharDev, false},
+	}
+	for i, test := range tests {
+		spec := &api.Volume{
+			Name:         "vol1",
+			VolumeSource: api.VolumeSource{HostPath: &api.HostPathVolumeSource{Path: test.path, Type: test.pathType}},
+		}
+		builder, err := plug.NewBuilder(volume.NewSpecFromVolume(spec), pod, volume.VolumeOptions{})
+		if err != nil {
+			t.Fatalf("Failed to make a new Builder: %v", err)
+		}
+		err = builder.SetUp()
+		if test.success && err != nil {
+			t.Errorf("%d: expected %s of type %q to be accepted, got %v", i, test.path, test.pathType, err)
+		}
+		if !test.success && err == nil {
+			t.Errorf("%d: expected %s of type %q to be refused", i, test.path, test.pathType)
+		}
+	}
+
+	if info, err := os.Stat(path.Join(tmpDir, "newdir")); err != nil || !info.IsDir() {
+		t.Errorf("Expected DirectoryOrCreate to make a directory, got %v", err)
+	}
+	if info, err := os.Stat(path.Join(tmpDir, "newfile")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("Expected FileOrCreate to make a file, got %v", err)
+	}
+	if _, err := os.Stat(path.Join(tmpDir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected an untyped path not to be created, got %v", err)
 	}
 }
@@ -0,0 +1,131 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+const (
+	// MinIOWeight and MaxIOWeight bound the weights accepted by the cgroup
+	// v2 io controller.
+	MinIOWeight = 1
+	MaxIOWeight = 10000
+)
+
+// ErrIOWeightOutOfRange is returned for weights outside
+// [MinIOWeight, MaxIOWeight].
+type ErrIOWeightOutOfRange struct {
+	Weight int
+}
+
+func (e *ErrIOWeightOutOfRange) Error() string {
+	return fmt.Sprintf("io weight %d is out of range [%d, %d]", e.Weight, MinIOWeight, MaxIOWeight)
+}
+
+// ErrIOControllerUnsupported is returned when the cgroup v2 io controller is
+// not available for a cgroup.
+type ErrIOControllerUnsupported struct {
+	CgroupDir string
+}
+
+func (e *ErrIOControllerUnsupported) Error() string {
+	return fmt.Sprintf("cgroup v2 io controller is not available for %s", e.CgroupDir)
+}
+
+// CgroupWriter reads and writes cgroup interface files.
+type CgroupWriter interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+}
+
+// NewCgroupWriter returns a CgroupWriter operating on the real cgroup
+// filesystem.
+func NewCgroupWriter() CgroupWriter {
+	return &osCgroupWriter{}
+}
+
+type osCgroupWriter struct{}
+
+func (*osCgroupWriter) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (*osCgroupWriter) WriteFile(name string, data []byte) error {
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+// IOWeighter sets proportional I/O weights for block devices in a cgroup v2
+// cgroup, so that under contention the cgroup gets a share of the device's
+// I/O proportional to its weight.
+type IOWeighter struct {
+	// CgroupDir is the cgroup v2 directory, e.g. the pod's cgroup.
+	CgroupDir string
+	Writer    CgroupWriter
+}
+
+// getDeviceNumber resolves a block device path to its major and minor
+// numbers.  It is a variable so tests can fake devices.
+var getDeviceNumber = deviceNumber
+
+// SetIOWeight sets the io.weight of the device at devicePath to weight.
+func (w *IOWeighter) SetIOWeight(devicePath string, weight int) error {
+	if weight < MinIOWeight || weight > MaxIOWeight {
+		return &ErrIOWeightOutOfRange{Weight: weight}
+	}
+	return w.writeIOWeight(devicePath, fmt.Sprintf("%d", weight))
+}
+
+// ClearIOWeight resets the io.weight of the device at devicePath to the
+// cgroup's default weight.
+func (w *IOWeighter) ClearIOWeight(devicePath string) error {
+	return w.writeIOWeight(devicePath, "default")
+}
+
+func (w *IOWeighter) writeIOWeight(devicePath, value string) error {
+	if err := w.checkIOController(); err != nil {
+		return err
+	}
+	major, minor, err := getDeviceNumber(devicePath)
+	if err != nil {
+		return err
+	}
+	entry := fmt.Sprintf("%d:%d %s", major, minor, value)
+	if err := w.Writer.WriteFile(path.Join(w.CgroupDir, "io.weight"), []byte(entry)); err != nil {
+		return fmt.Errorf("failed to set io.weight %q in %s: %v", entry, w.CgroupDir, err)
+	}
+	return nil
+}
+
+// checkIOController verifies that the io controller is enabled for the
+// cgroup, which is the case when it is listed in cgroup.controllers.  On
+// cgroup v1 the file does not exist.
+func (w *IOWeighter) checkIOController() error {
+	data, err := w.Writer.ReadFile(path.Join(w.CgroupDir, "cgroup.controllers"))
+	if err != nil {
+		return &ErrIOControllerUnsupported{CgroupDir: w.CgroupDir}
+	}
+	for _, controller := range strings.Fields(string(data)) {
+		if controller == "io" {
+			return nil
+		}
+	}
+	return &ErrIOControllerUnsupported{CgroupDir: w.CgroupDir}
+}
@@ -0,0 +1,39 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// deviceNumber returns the major and minor numbers of a block device.
+func deviceNumber(devicePath string) (uint32, uint32, error) {
+	st := syscall.Stat_t{}
+	if err := syscall.Stat(devicePath, &st); err != nil {
+		return 0, 0, fmt.Errorf("stat(%q): %v", devicePath, err)
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFBLK {
+		return 0, 0, fmt.Errorf("%s is not a block device", devicePath)
+	}
+	rdev := uint64(st.Rdev)
+	major := uint32((rdev>>8)&0xfff | (rdev>>32)&^0xfff)
+	minor := uint32(rdev&0xff | (rdev>>12)&^0xff)
+	return major, minor, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+type fakeCgroupWriter struct {
+	files map[string]string
+}
+
+func (f *fakeCgroupWriter) ReadFile(name string) ([]byte, error) {
+	data, found := f.files[name]
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func (f *fakeCgroupWriter) WriteFile(name string, data []byte) error {
+	f.files[name] = string(data)
+	return nil
+}
+
+func withFakeDevice(major, minor uint32) func() {
+	old := getDeviceNumber
+	getDeviceNumber = func(devicePath string) (uint32, uint32, error) {
+		if devicePath != "/dev/sdb" {
+			return 0, 0, fmt.Errorf("no such device %s", devicePath)
+		}
+		return major, minor, nil
+	}
+	return func() { getDeviceNumber = old }
+}
+
+func TestSetIOWeight(t *testing.T) {
+	defer withFakeDevice(8, 16)()
+	writer := &fakeCgroupWriter{files: map[string]string{
+		"/sys/fs/cgroup/pod1/cgroup.controllers": "cpuset cpu io memory pids\n",
+	}}
+	weighter := &IOWeighter{CgroupDir: "/sys/fs/cgroup/pod1", Writer: writer}
+
+	if err := weighter.SetIOWeight("/dev/sdb", 500); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := writer.files["/sys/fs/cgroup/pod1/io.weight"]; got != "8:16 500" {
+		t.Errorf("Expected io.weight entry %q, got %q", "8:16 500", got)
+	}
+
+	if err := weighter.ClearIOWeight("/dev/sdb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := writer.files["/sys/fs/cgroup/pod1/io.weight"]; got != "8:16 default" {
+		t.Errorf("Expected io.weight entry %q, got %q", "8:16 default", got)
+	}
+}
+
+func TestSetIOWeightRange(t *testing.T) {
+	defer withFakeDevice(8, 16)()
+	writer := &fakeCgroupWriter{files: map[string]string{
+		"/cg/cgroup.controllers": "io",
+	}}
+	weighter := &IOWeighter{CgroupDir: "/cg", Writer: writer}
+
+	for _, weight := range []int{MinIOWeight, MaxIOWeight} {
+		if err := weighter.SetIOWeight("/dev/sdb", weight); err != nil {
+			t.Errorf("Unexpected error for weight %d: %v", weight, err)
+		}
+	}
+	for _, weight := range []int{0, -1, MaxIOWeight + 1} {
+		err := weighter.SetIOWeight("/dev/sdb", weight)
+		if _, ok := err.(*ErrIOWeightOutOfRange); !ok {
+			t.Errorf("Expected ErrIOWeightOutOfRange for weight %d, got %v", weight, err)
+		}
+	}
+}
+
+func TestSetIOWeightUnsupported(t *testing.T) {
+	defer withFakeDevice(8, 16)()
+	tests := map[string]map[string]string{
+		"cgroup v1":        {},
+		"io not delegated": {"/cg/cgroup.controllers": "cpu memory pids"},
+	}
+	for name, files := range tests {
+		writer := &fakeCgroupWriter{files: files}
+		weighter := &IOWeighter{CgroupDir: "/cg", Writer: writer}
+		err := weighter.SetIOWeight("/dev/sdb", 100)
+		if _, ok := err.(*ErrIOControllerUnsupported); !ok {
+			t.Errorf("%s: expected ErrIOControllerUnsupported, got %v", name, err)
+		}
+		if _, written := writer.files["/cg/io.weight"]; written {
+			t.Errorf("%s: expected io.weight not to be written", name)
+		}
+	}
+}
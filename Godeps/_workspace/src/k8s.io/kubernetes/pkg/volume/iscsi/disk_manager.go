@@ -21,6 +21,7 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
 )
 
 // Abstract interface to disk operations.
@@ -62,6 +63,7 @@ func diskSetUp(manager diskManager, b iscsiDiskBuilder, volPath string, mounter
 	err = mounter.Mount(globalPDPath, volPath, "", options)
 	if err != nil {
 		glog.Errorf("failed to bind mount:%s", globalPDPath)
+		volume.CleanupStagingDir(volPath, err)
 		return err
 	}
 	return nil
@@ -78,7 +80,7 @@ func diskTearDown(manager diskManager, c iscsiDiskCleaner, volPath string, mount
 		return os.Remove(volPath)
 	}
 
-	refs, err := mount.GetMountRefs(mounter, volPath)
+	_, refs, err := mount.PathExistsWithRefs(mounter, volPath)
 	if err != nil {
 		glog.Errorf("failed to get reference count %s", volPath)
 		return err
@@ -87,10 +89,9 @@ func diskTearDown(manager diskManager, c iscsiDiskCleaner, volPath string, mount
 		glog.Errorf("failed to unmount %s", volPath)
 		return err
 	}
-	// If len(refs) is 1, then all bind mounts have been removed, and the
-	// remaining reference is the global mount. It is safe to detach.
-	if len(refs) == 1 {
-		mntPath := refs[0]
+	// If the global mount is the last reference, then no other pod uses
+	// the disk anymore. It is safe to detach.
+	if mntPath, ok := volume.LastGlobalMountRef(c.plugin.host, refs); ok {
 		if err := manager.DetachDisk(c, mntPath); err != nil {
 			glog.Errorf("failed to detach disk from %s", mntPath)
 			return err
@@ -17,13 +17,13 @@ limitations under the License.
 package iscsi
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -69,6 +69,14 @@ func (plugin *iscsiPlugin) CanSupport(spec *volume.Spec) bool {
 	return false
 }
 
+func (plugin *iscsiPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.ISCSI == nil {
+		return "", fmt.Errorf("spec does not reference a iscsi volume")
+	}
+	return fmt.Sprintf("%s:%s:%d", source.ISCSI.TargetPortal, source.ISCSI.IQN, source.ISCSI.Lun), nil
+}
+
 func (plugin *iscsiPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -78,10 +86,10 @@ func (plugin *iscsiPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 
 func (plugin *iscsiPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
 	// Inject real implementations here, test through the internal function.
-	return plugin.newBuilderInternal(spec, pod.UID, &ISCSIUtil{}, plugin.host.GetMounter())
+	return plugin.newBuilderInternal(spec, pod.UID, &ISCSIUtil{}, plugin.host.GetMounter(), exec.New())
 }
 
-func (plugin *iscsiPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, manager diskManager, mounter mount.Interface) (volume.Builder, error) {
+func (plugin *iscsiPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, manager diskManager, mounter mount.Interface, exe exec.Interface) (volume.Builder, error) {
 	// iscsi volumes used directly in a pod have a ReadOnly flag set by the pod author.
 	// iscsi volumes used as a PersistentVolume gets the ReadOnly flag indirectly through the persistent-claim volume used to mount the PV
 	var readOnly bool
@@ -96,20 +104,27 @@ func (plugin *iscsiPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UI
 
 	lun := strconv.Itoa(iscsi.Lun)
 	portal := portalBuilder(iscsi.TargetPortal)
+	portals := []string{portal}
+	for _, p := range iscsi.Portals {
+		portals = append(portals, portalBuilder(p))
+	}
 
-	return &iscsiDiskBuilder{
+	builder := &iscsiDiskBuilder{
 		iscsiDisk: &iscsiDisk{
 			podUID:  podUID,
 			volName: spec.Name(),
 			portal:  portal,
+			portals: portals,
 			iqn:     iscsi.IQN,
 			lun:     lun,
 			manager: manager,
-			mounter: &mount.SafeFormatAndMount{mounter, exec.New()},
+			mounter: &volume.SafeFormatAndMount{Interface: mounter, Runner: exe},
 			plugin:  plugin},
 		fsType:   iscsi.FSType,
 		readOnly: readOnly,
-	}, nil
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
 }
 
 func (plugin *iscsiPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
@@ -136,6 +151,8 @@ type iscsiDisk struct {
 	volName string
 	podUID  types.UID
 	portal  string
+	// All portals of the target, starting with portal; more than one means multipath.
+	portals []string
 	iqn     string
 	lun     string
 	plugin  *iscsiPlugin
@@ -147,20 +164,18 @@ type iscsiDisk struct {
 func (iscsi *iscsiDisk) GetPath() string {
 	name := iscsiPluginName
 	// safe to use PodVolumeDir now: volume teardown occurs before pod is cleaned up
-	return iscsi.plugin.host.GetPodVolumeDir(iscsi.podUID, util.EscapeQualifiedNameForDisk(name), iscsi.volName)
+	return volume.NewDirectoryLayout(iscsi.plugin.host).PodVolumeDir(iscsi.podUID, name, iscsi.volName)
 }
 
 type iscsiDiskBuilder struct {
 	*iscsiDisk
+	volume.MetricsProvider
 	readOnly bool
 	fsType   string
 }
 
 var _ volume.Builder = &iscsiDiskBuilder{}
-
-func (_ *iscsiDiskBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
+var _ volume.DiskBuilder = &iscsiDiskBuilder{}
 
 func (b *iscsiDiskBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
@@ -181,12 +196,17 @@ type iscsiDiskCleaner struct {
 
 var _ volume.Cleaner = &iscsiDiskCleaner{}
 
-func (b *iscsiDiskBuilder) IsReadOnly() bool {
-	return b.readOnly
+func (b *iscsiDiskBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *iscsiDiskBuilder) SupportsSELinux() bool {
-	return true
+func (b *iscsiDiskBuilder) FSType() string {
+	return b.fsType
 }
 
 // Unmounts the bind mount, and detaches the disk only if the disk
@@ -18,12 +18,14 @@ package iscsi
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
@@ -66,6 +68,24 @@ func contains(modes []api.PersistentVolumeAccessMode, mode api.PersistentVolumeA
 	return false
 }
 
+// fakeFormattedExec answers blkid as if the device held a filesystem of
+// type fsType, and lets fsck pass.
+func fakeFormattedExec(fsType string) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for _, output := range []string{"TYPE=" + fsType + "\n", ""} {
+		output := output
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			fakeCmd := &exec.FakeCmd{
+				CombinedOutputScript: []exec.FakeCombinedOutputAction{
+					func() ([]byte, error) { return []byte(output), nil },
+				},
+			}
+			return exec.InitFakeCmd(fakeCmd, cmd, args...)
+		})
+	}
+	return fake
+}
+
 type fakeDiskManager struct {
 	attachCalled bool
 	detachCalled bool
@@ -108,7 +128,7 @@ func doTestPlugin(t *testing.T, spec *volume.Spec) {
 	}
 	fakeManager := &fakeDiskManager{}
 	fakeMounter := &mount.FakeMounter{}
-	builder, err := plug.(*iscsiPlugin).newBuilderInternal(spec, types.UID("poduid"), fakeManager, fakeMounter)
+	builder, err := plug.(*iscsiPlugin).newBuilderInternal(spec, types.UID("poduid"), fakeManager, fakeMounter, fakeFormattedExec("ext4"))
 	if err != nil {
 		t.Errorf("Failed to make a new Builder: %v", err)
 	}
@@ -179,6 +199,35 @@ func TestPluginVolume(t *testing.T) {
 	doTestPlugin(t, volume.NewSpecFromVolume(vol))
 }
 
+func TestBuilderMultipathPortals(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/iscsi")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	vol := &api.Volume{
+		Name: "vol1",
+		VolumeSource: api.VolumeSource{
+			ISCSI: &api.ISCSIVolumeSource{
+				TargetPortal: "127.0.0.1:3260",
+				Portals:      []string{"127.0.0.2", "127.0.0.3:860"},
+				IQN:          "iqn.2014-12.server:storage.target01",
+				FSType:       "ext4",
+				Lun:          0,
+			},
+		},
+	}
+	builder, err := plug.(*iscsiPlugin).newBuilderInternal(volume.NewSpecFromVolume(vol), types.UID("poduid"), &fakeDiskManager{}, &mount.FakeMounter{}, fakeFormattedExec("ext4"))
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	expected := []string{"127.0.0.1:3260", "127.0.0.2:3260", "127.0.0.3:860"}
+	if portals := builder.(*iscsiDiskBuilder).portals; !reflect.DeepEqual(portals, expected) {
+		t.Errorf("Expected portals %v, got %v", expected, portals)
+	}
+}
+
 func TestPluginPersistentVolume(t *testing.T) {
 	vol := &api.PersistentVolume{
 		ObjectMeta: api.ObjectMeta{
@@ -246,8 +295,8 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
 	}
 }
 
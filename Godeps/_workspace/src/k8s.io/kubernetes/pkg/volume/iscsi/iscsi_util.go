@@ -19,8 +19,10 @@ package iscsi
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -29,6 +31,27 @@ import (
 	"k8s.io/kubernetes/pkg/volume"
 )
 
+// ioHandler abstracts the filesystem lookups used to find multipath devices.
+type ioHandler interface {
+	ReadDir(dirname string) ([]os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	EvalSymlinks(path string) (string, error)
+}
+
+type osIOHandler struct{}
+
+func (handler *osIOHandler) ReadDir(dirname string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(dirname)
+}
+
+func (handler *osIOHandler) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (handler *osIOHandler) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
 // stat a path, if not exists, retry maxRetries times
 func waitForPathToExist(devicePath string, maxRetries int) bool {
 	for i := 0; i < maxRetries; i++ {
@@ -79,25 +102,78 @@ func (util *ISCSIUtil) MakeGlobalPDName(iscsi iscsiDisk) string {
 	return makePDNameInternal(iscsi.plugin.host, iscsi.portal, iscsi.iqn, iscsi.lun)
 }
 
-func (util *ISCSIUtil) AttachDisk(b iscsiDiskBuilder) error {
-	devicePath := strings.Join([]string{"/dev/disk/by-path/ip", b.portal, "iscsi", b.iqn, "lun", b.lun}, "-")
-	exist := waitForPathToExist(devicePath, 1)
-	if exist == false {
-		// discover iscsi target
-		out, err := b.plugin.execCommand("iscsiadm", []string{"-m", "discovery", "-t", "sendtargets", "-p", b.portal})
-		if err != nil {
-			glog.Errorf("iscsi: failed to sendtargets to portal %s error: %s", b.portal, string(out))
-			return err
+// findMultipathDeviceForDevice returns the device mapper device (e.g.
+// /dev/dm-0) that has the given disk as one of its paths, or "" if the disk
+// is not part of a multipath device.
+func findMultipathDeviceForDevice(device string, io ioHandler) string {
+	disk, err := io.EvalSymlinks(device)
+	if err != nil {
+		return ""
+	}
+	disk = path.Base(disk)
+	sysPath := "/sys/block/"
+	dirs, err := io.ReadDir(sysPath)
+	if err != nil {
+		return ""
+	}
+	for _, f := range dirs {
+		name := f.Name()
+		if !strings.HasPrefix(name, "dm-") {
+			continue
+		}
+		if _, err := io.Lstat(path.Join(sysPath, name, "slaves", disk)); err == nil {
+			return "/dev/" + name
 		}
-		// login to iscsi target
-		out, err = b.plugin.execCommand("iscsiadm", []string{"-m", "node", "-p", b.portal, "-T", b.iqn, "--login"})
+	}
+	return ""
+}
+
+// loginToPortal makes the lun of b visible through portal and returns the
+// resulting device path.
+func loginToPortal(b iscsiDiskBuilder, portal string) (string, error) {
+	devicePath := strings.Join([]string{"/dev/disk/by-path/ip", portal, "iscsi", b.iqn, "lun", b.lun}, "-")
+	if waitForPathToExist(devicePath, 1) {
+		return devicePath, nil
+	}
+	// discover iscsi target
+	out, err := b.plugin.execCommand("iscsiadm", []string{"-m", "discovery", "-t", "sendtargets", "-p", portal})
+	if err != nil {
+		glog.Errorf("iscsi: failed to sendtargets to portal %s error: %s", portal, string(out))
+		return "", err
+	}
+	// login to iscsi target
+	out, err = b.plugin.execCommand("iscsiadm", []string{"-m", "node", "-p", portal, "-T", b.iqn, "--login"})
+	if err != nil {
+		glog.Errorf("iscsi: failed to attach disk:Error: %s (%v)", string(out), err)
+		return "", err
+	}
+	if !waitForPathToExist(devicePath, 10) {
+		return "", errors.New("Could not attach disk: Timeout after 10s")
+	}
+	return devicePath, nil
+}
+
+func (util *ISCSIUtil) AttachDisk(b iscsiDiskBuilder) error {
+	// Log into every portal; with multipath the disk is usable as long as
+	// at least one path comes up.
+	var devicePaths []string
+	var lastErr error
+	for _, portal := range b.portals {
+		devicePath, err := loginToPortal(b, portal)
 		if err != nil {
-			glog.Errorf("iscsi: failed to attach disk:Error: %s (%v)", string(out), err)
-			return err
+			lastErr = err
+			continue
 		}
-		exist = waitForPathToExist(devicePath, 10)
-		if !exist {
-			return errors.New("Could not attach disk: Timeout after 10s")
+		devicePaths = append(devicePaths, devicePath)
+	}
+	if len(devicePaths) == 0 {
+		return lastErr
+	}
+	devicePath := devicePaths[0]
+	if len(b.portals) > 1 {
+		if mpath := findMultipathDeviceForDevice(devicePath, &osIOHandler{}); mpath != "" {
+			glog.V(4).Infof("iscsi: using multipath device %s for %s", mpath, devicePath)
+			devicePath = mpath
 		}
 	}
 	// mount it
@@ -122,7 +198,7 @@ func (util *ISCSIUtil) AttachDisk(b iscsiDiskBuilder) error {
 }
 
 func (util *ISCSIUtil) DetachDisk(c iscsiDiskCleaner, mntPath string) error {
-	_, cnt, err := mount.GetDeviceNameFromMount(c.mounter, mntPath)
+	device, cnt, err := mount.GetDeviceNameFromMount(c.mounter, mntPath)
 	if err != nil {
 		glog.Errorf("iscsi detach disk: failed to get device from mnt: %s\nError: %v", mntPath, err)
 		return err
@@ -134,7 +210,7 @@ func (util *ISCSIUtil) DetachDisk(c iscsiDiskCleaner, mntPath string) error {
 	cnt--
 	// if device is no longer used, see if need to logout the target
 	if cnt == 0 {
-		device, prefix, err := extractDeviceAndPrefix(mntPath)
+		pdName, prefix, err := extractDeviceAndPrefix(mntPath)
 		if err != nil {
 			return err
 		}
@@ -143,12 +219,18 @@ func (util *ISCSIUtil) DetachDisk(c iscsiDiskCleaner, mntPath string) error {
 		if err == nil && refCount == 0 {
 			// this portal/iqn are no longer referenced, log out
 			// extract portal and iqn from device path
-			portal, iqn, err := extractPortalAndIqn(device)
+			portal, iqn, err := extractPortalAndIqn(pdName)
 			if err != nil {
 				return err
 			}
+			args := []string{"-m", "node", "-p", portal, "-T", iqn, "--logout"}
+			if strings.HasPrefix(path.Base(device), "dm-") {
+				// A multipath device was mounted; log out of every
+				// portal of the target, not just the first.
+				args = []string{"-m", "node", "-T", iqn, "--logout"}
+			}
 			glog.Infof("iscsi: log out target %s iqn %s", portal, iqn)
-			out, err := c.plugin.execCommand("iscsiadm", []string{"-m", "node", "-p", portal, "-T", iqn, "--logout"})
+			out, err := c.plugin.execCommand("iscsiadm", args)
 			if err != nil {
 				glog.Errorf("iscsi: failed to detach disk Error: %s", string(out))
 			}
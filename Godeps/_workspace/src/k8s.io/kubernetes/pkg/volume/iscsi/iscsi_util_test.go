@@ -17,7 +17,10 @@ limitations under the License.
 package iscsi
 
 import (
+	"errors"
+	"os"
 	"testing"
+	"time"
 
 	"k8s.io/kubernetes/pkg/util/mount"
 )
@@ -69,3 +72,57 @@ func TestExtractPortalAndIqn(t *testing.T) {
 		t.Errorf("extractPortalAndIqn: got %v %s %s", err, portal, iqn)
 	}
 }
+
+type fakeFileInfo struct {
+	name string
+}
+
+func (fi *fakeFileInfo) Name() string       { return fi.name }
+func (fi *fakeFileInfo) Size() int64        { return 0 }
+func (fi *fakeFileInfo) Mode() os.FileMode  { return 0777 }
+func (fi *fakeFileInfo) ModTime() time.Time { return time.Now() }
+func (fi *fakeFileInfo) IsDir() bool        { return false }
+func (fi *fakeFileInfo) Sys() interface{}   { return nil }
+
+// fakeIOHandler presents /dev/sdb as the only path of the multipath device dm-1.
+type fakeIOHandler struct{}
+
+func (handler *fakeIOHandler) ReadDir(dirname string) ([]os.FileInfo, error) {
+	if dirname != "/sys/block/" {
+		return nil, errors.New("not a directory")
+	}
+	return []os.FileInfo{&fakeFileInfo{"sda"}, &fakeFileInfo{"dm-0"}, &fakeFileInfo{"dm-1"}}, nil
+}
+
+func (handler *fakeIOHandler) Lstat(name string) (os.FileInfo, error) {
+	if name == "/sys/block/dm-1/slaves/sdb" {
+		return &fakeFileInfo{"sdb"}, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (handler *fakeIOHandler) EvalSymlinks(path string) (string, error) {
+	switch path {
+	case "/dev/disk/by-path/ip-127.0.0.1:3260-iscsi-iqn.2014-12.com.example:test.tgt00-lun-0":
+		return "/dev/sdb", nil
+	case "/dev/disk/by-path/ip-127.0.0.2:3260-iscsi-iqn.2014-12.com.example:test.tgt00-lun-0":
+		return "/dev/sdc", nil
+	}
+	return "", os.ErrNotExist
+}
+
+func TestFindMultipathDeviceForDevice(t *testing.T) {
+	tests := []struct {
+		device   string
+		expected string
+	}{
+		{"/dev/disk/by-path/ip-127.0.0.1:3260-iscsi-iqn.2014-12.com.example:test.tgt00-lun-0", "/dev/dm-1"},
+		{"/dev/disk/by-path/ip-127.0.0.2:3260-iscsi-iqn.2014-12.com.example:test.tgt00-lun-0", ""},
+		{"/dev/disk/by-path/missing", ""},
+	}
+	for i, test := range tests {
+		if mpath := findMultipathDeviceForDevice(test.device, &fakeIOHandler{}); mpath != test.expected {
+			t.Errorf("%d. findMultipathDeviceForDevice(%s) = %q; expected %q", i, test.device, mpath, test.expected)
+		}
+	}
+}
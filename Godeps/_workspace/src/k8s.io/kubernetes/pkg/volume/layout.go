@@ -0,0 +1,145 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// volumeDevicesDir is the directory beneath the data directories of a plugin
+// which the devices of its block volumes are linked in.
+const volumeDevicesDir = "volumeDevices"
+
+// DirectoryLayout computes the directories which plugins keep volumes and
+// data in beneath the directories of a VolumeHost.  Plugin names are escaped
+// with EscapePluginName, so that every plugin lays out its directories the
+// same way and the layout can only change here.
+//
+// The plugins which mount devices globally in GetPluginDir of their unescaped
+// name keep doing so: moving those mounts would lose the devices already
+// mounted on existing nodes.
+type DirectoryLayout struct {
+	host VolumeHost
+}
+
+// NewDirectoryLayout returns the layout of the directories of host.
+func NewDirectoryLayout(host VolumeHost) DirectoryLayout {
+	return DirectoryLayout{host: host}
+}
+
+// EscapePluginName returns pluginName, a qualified name like
+// "kubernetes.io/nfs", as a single path component.
+func EscapePluginName(pluginName string) string {
+	return util.EscapeQualifiedNameForDisk(pluginName)
+}
+
+// UnescapePluginName returns the name of the plugin whose directories are
+// named dirName.
+func UnescapePluginName(dirName string) string {
+	return util.UnescapeQualifiedNameForDisk(dirName)
+}
+
+// PodVolumeDir returns the directory which the named plugin sets up the
+// named volume of the pod in.  The volume name is passed through the
+// configured PathSanitizer; SanitizeVolumeName keeps the DNS labels that
+// validation allows as volume names, so their directories don't change.  If
+// the sanitizer fails, SanitizeVolumeName is used instead, and if that fails
+// too the directory is named after a hash of the volume name.
+func (l DirectoryLayout) PodVolumeDir(podUID types.UID, pluginName, volumeName string) string {
+	dir, err := l.sanitizedPodVolumeDir(podUID, pluginName, volumeName, getPathSanitizer())
+	if err == nil {
+		return dir
+	}
+	glog.Errorf("Falling back to the default sanitizer for volume %q of pod %s: %v", volumeName, podUID, err)
+	if dir, err = l.sanitizedPodVolumeDir(podUID, pluginName, volumeName, SanitizeVolumeName); err == nil {
+		return dir
+	}
+	// Only names validation rejects, e.g. empty ones, get here.
+	glog.Errorf("Hashing volume name %q of pod %s: %v", volumeName, podUID, err)
+	return l.host.GetPodVolumeDir(podUID, EscapePluginName(pluginName), hashVolumeName(volumeName))
+}
+
+// sanitizedPodVolumeDir is PodVolumeDir with the volume name passed through
+// sanitize, which must produce a single path component.
+func (l DirectoryLayout) sanitizedPodVolumeDir(podUID types.UID, pluginName, volumeName string, sanitize PathSanitizer) (string, error) {
+	name, err := sanitize(volumeName)
+	if err != nil {
+		return "", err
+	}
+	dir := l.host.GetPodVolumeDir(podUID, EscapePluginName(pluginName), name)
+	if path.Base(dir) != name {
+		return "", fmt.Errorf("sanitized volume name %q is not a single path component", name)
+	}
+	return dir, nil
+}
+
+// PodVolumesDir returns the directory holding the volume directories of all
+// plugins for the pod.
+func (l DirectoryLayout) PodVolumesDir(podUID types.UID) string {
+	// GetPodVolumeDir is <pods dir>/<pod UID>/volumes/<plugin>/<volume>.
+	return path.Dir(path.Dir(l.host.GetPodVolumeDir(podUID, "plugin", "volume")))
+}
+
+// PodsDir returns the directory holding the directories of all pods.
+func (l DirectoryLayout) PodsDir() string {
+	return path.Dir(path.Dir(l.PodVolumesDir("uid")))
+}
+
+// PodPluginDir returns the directory which the named plugin keeps data
+// about the volumes of the pod in.
+func (l DirectoryLayout) PodPluginDir(podUID types.UID, pluginName string) string {
+	return l.host.GetPodPluginDir(podUID, EscapePluginName(pluginName))
+}
+
+// PodVolumeDeviceDir returns the directory which the devices of the block
+// volumes of the named plugin are linked in for the pod, each as the name of
+// its volume.
+func (l DirectoryLayout) PodVolumeDeviceDir(podUID types.UID, pluginName string) string {
+	return path.Join(l.PodPluginDir(podUID, pluginName), volumeDevicesDir)
+}
+
+// PluginDataDir returns the directory which the named plugin keeps data in
+// that is not about a single pod.
+func (l DirectoryLayout) PluginDataDir(pluginName string) string {
+	return l.host.GetPluginDir(EscapePluginName(pluginName))
+}
+
+// PluginDeviceDir returns the directory which the devices of all block
+// volumes of the named plugin on the node are linked in, each as the name
+// of its volume.
+func (l DirectoryLayout) PluginDeviceDir(pluginName string) string {
+	return path.Join(l.PluginDataDir(pluginName), volumeDevicesDir)
+}
+
+// podUIDFromVolumePath returns the UID of the pod whose directory holds the
+// volume at path, i.e. .../pods/<uid>/volumes/..., or "" if path is not in a
+// pod directory.
+func podUIDFromVolumePath(path string) types.UID {
+	parts := strings.Split(path, "/")
+	for i := len(parts) - 3; i >= 0; i-- {
+		if parts[i] == "pods" && parts[i+1] != "" && parts[i+2] == "volumes" {
+			return types.UID(parts[i+1])
+		}
+	}
+	return ""
+}
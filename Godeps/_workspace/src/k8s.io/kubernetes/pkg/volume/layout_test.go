@@ -0,0 +1,97 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+func TestDirectoryLayout(t *testing.T) {
+	layout := NewDirectoryLayout(NewFakeVolumeHost("/var/lib/kubelet", nil, nil))
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"PodVolumeDir", layout.PodVolumeDir("poduid", "kubernetes.io/nfs", "vol1"), "/var/lib/kubelet/pods/poduid/volumes/kubernetes.io~nfs/vol1"},
+		{"PodVolumesDir", layout.PodVolumesDir("poduid"), "/var/lib/kubelet/pods/poduid/volumes"},
+		{"PodsDir", layout.PodsDir(), "/var/lib/kubelet/pods"},
+		{"PodPluginDir", layout.PodPluginDir("poduid", "kubernetes.io/nfs"), "/var/lib/kubelet/pods/poduid/plugins/kubernetes.io~nfs"},
+		{"PodVolumeDeviceDir", layout.PodVolumeDeviceDir("poduid", "kubernetes.io/local-volume"), "/var/lib/kubelet/pods/poduid/plugins/kubernetes.io~local-volume/volumeDevices"},
+		{"PluginDataDir", layout.PluginDataDir("kubernetes.io/csi"), "/var/lib/kubelet/plugins/kubernetes.io~csi"},
+		{"PluginDeviceDir", layout.PluginDeviceDir("kubernetes.io/local-volume"), "/var/lib/kubelet/plugins/kubernetes.io~local-volume/volumeDevices"},
+	}
+	for _, test := range tests {
+		if test.path != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, test.path)
+		}
+	}
+}
+
+func TestPodVolumeDirSanitizesName(t *testing.T) {
+	layout := NewDirectoryLayout(NewFakeVolumeHost("/var/lib/kubelet", nil, nil))
+	expected := "/var/lib/kubelet/pods/poduid/volumes/kubernetes.io~nfs/%2E.%2Fevil"
+	if dir := layout.PodVolumeDir("poduid", "kubernetes.io/nfs", "../evil"); dir != expected {
+		t.Errorf("Expected %s, got %s", expected, dir)
+	}
+
+	// A sanitizer producing a nested path is not trusted.
+	SetPathSanitizer(func(name string) (string, error) { return "x/y", nil })
+	defer SetPathSanitizer(nil)
+	if dir := layout.PodVolumeDir("poduid", "kubernetes.io/nfs", "../evil"); dir != expected {
+		t.Errorf("Expected the default sanitizer to be used, got %s", dir)
+	}
+
+	// Names no sanitizer accepts are hashed rather than used as they are.
+	SetPathSanitizer(func(name string) (string, error) { return "", fmt.Errorf("rejected") })
+	name := "../" + strings.Repeat("x", maxSanitizedNameLength)
+	dir := layout.PodVolumeDir("poduid", "kubernetes.io/nfs", name)
+	if path.Dir(dir) != "/var/lib/kubelet/pods/poduid/volumes/kubernetes.io~nfs" || !strings.HasPrefix(path.Base(dir), "~sha256-") {
+		t.Errorf("Expected a hashed name in the volume directory of the plugin, got %s", dir)
+	}
+}
+
+func TestEscapePluginName(t *testing.T) {
+	for _, name := range []string{"kubernetes.io/nfs", "example.com/driver", "plain"} {
+		escaped := EscapePluginName(name)
+		if escaped == "" || path.Base(escaped) != escaped {
+			t.Errorf("%s: expected a single path component, got %q", name, escaped)
+		}
+		if unescaped := UnescapePluginName(escaped); unescaped != name {
+			t.Errorf("%s: expected the name back, got %q", name, unescaped)
+		}
+	}
+}
+
+func TestPodUIDFromVolumePath(t *testing.T) {
+	tests := map[string]types.UID{
+		"/var/lib/kubelet/pods/poduid/volumes/kubernetes.io~nfs/vol1": "poduid",
+		"/var/lib/kubelet/pods/poduid/plugins/kubernetes.io~nfs":      "",
+		"/mnt/disks/vol1": "",
+		"pv1":             "",
+	}
+	for path, expected := range tests {
+		if uid := podUIDFromVolumePath(path); uid != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, uid)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package local contains the internal representation of local volumes,
+// disks or directories on a node which an administrator made available as
+// persistent volumes.
+//
+// A local volume can only be used on its node, so its PersistentVolume
+// carries the node affinity of the node, see NewPersistentVolumeTemplate,
+// and the scheduler only puts pods claiming it there.  Builders bind mount
+// the directory into the pod, and block volume builders link the device,
+// which must be a raw block device.  The plugin can't delete the data of a
+// volume once released; its deleters fail, so that an administrator cleans
+// up the disk.
+package local
@@ -0,0 +1,333 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// ProbeVolumePlugins is the entry point for plugin detection in a package.
+func ProbeVolumePlugins() []volume.VolumePlugin {
+	return []volume.VolumePlugin{&localVolumePlugin{}}
+}
+
+const localVolumePluginName = "kubernetes.io/local-volume"
+
+type localVolumePlugin struct {
+	host volume.VolumeHost
+}
+
+var _ volume.VolumePlugin = &localVolumePlugin{}
+var _ volume.PersistentVolumePlugin = &localVolumePlugin{}
+var _ volume.DeletableVolumePlugin = &localVolumePlugin{}
+var _ volume.BlockVolumePlugin = &localVolumePlugin{}
+
+func (plugin *localVolumePlugin) Init(host volume.VolumeHost) {
+	plugin.host = host
+}
+
+func (plugin *localVolumePlugin) Name() string {
+	return localVolumePluginName
+}
+
+func (plugin *localVolumePlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.PersistentVolume != nil && spec.PersistentVolume.Spec.Local != nil
+}
+
+func (plugin *localVolumePlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if !plugin.CanSupport(spec) {
+		return "", fmt.Errorf("spec does not reference a local volume")
+	}
+	// The same path may be a different volume on every node, but a
+	// PersistentVolume is only ever used on one.
+	return spec.Name(), nil
+}
+
+func (plugin *localVolumePlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
+	return []api.PersistentVolumeAccessMode{
+		api.ReadWriteOnce,
+	}
+}
+
+func (plugin *localVolumePlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.Builder, error) {
+	return plugin.newBuilderInternal(spec, pod.UID, plugin.host.GetMounter())
+}
+
+func (plugin *localVolumePlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, mounter mount.Interface) (volume.Builder, error) {
+	if !plugin.CanSupport(spec) {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.Local is nil")
+	}
+	return &localVolumeBuilder{
+		localVolume: &localVolume{
+			volName: spec.Name(),
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+		localPath: spec.PersistentVolume.Spec.Local.Path,
+		readOnly:  spec.ReadOnly,
+	}, nil
+}
+
+func (plugin *localVolumePlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
+	return plugin.newCleanerInternal(volName, podUID, plugin.host.GetMounter())
+}
+
+func (plugin *localVolumePlugin) newCleanerInternal(volName string, podUID types.UID, mounter mount.Interface) (volume.Cleaner, error) {
+	return &localVolumeCleaner{
+		localVolume: &localVolume{
+			volName: volName,
+			podUID:  podUID,
+			mounter: mounter,
+			plugin:  plugin,
+		},
+	}, nil
+}
+
+func (plugin *localVolumePlugin) NewDeleter(spec *volume.Spec) (volume.Deleter, error) {
+	if !plugin.CanSupport(spec) {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.Local is nil")
+	}
+	return &localVolumeDeleter{name: spec.Name(), localPath: spec.PersistentVolume.Spec.Local.Path}, nil
+}
+
+func (plugin *localVolumePlugin) NewBlockVolumeBuilder(spec *volume.Spec, pod *api.Pod, _ volume.VolumeOptions) (volume.BlockVolumeBuilder, error) {
+	if !plugin.CanSupport(spec) {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.Local is nil")
+	}
+	return &localBlockVolumeBuilder{
+		localBlockVolume: &localBlockVolume{
+			volName: spec.Name(),
+			podUID:  pod.UID,
+			plugin:  plugin,
+		},
+		devicePath: spec.PersistentVolume.Spec.Local.Path,
+	}, nil
+}
+
+func (plugin *localVolumePlugin) NewBlockVolumeCleaner(volName string, podUID types.UID) (volume.BlockVolumeCleaner, error) {
+	return &localBlockVolumeCleaner{
+		localBlockVolume: &localBlockVolume{
+			volName: volName,
+			podUID:  podUID,
+			plugin:  plugin,
+		},
+	}, nil
+}
+
+// NewPersistentVolumeTemplate returns a PersistentVolume with the given
+// capacity for the local volume at localPath on the node labeled with
+// hostname.  Its node affinity confines the pods claiming it to the node,
+// and it is retained once released, as the plugin can't delete its data.
+func NewPersistentVolumeTemplate(hostname, localPath string, capacity resource.Quantity) (*api.PersistentVolume, error) {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			GenerateName: "pv-local-",
+		},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimRetain,
+			AccessModes:                   []api.PersistentVolumeAccessMode{api.ReadWriteOnce},
+			Capacity: api.ResourceList{
+				api.ResourceName(api.ResourceStorage): capacity,
+			},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				Local: &api.LocalVolumeSource{
+					Path: localPath,
+				},
+			},
+		},
+	}
+	if err := volume.SetNodeAffinity(pv, volume.HostnameNodeAffinity(hostname)); err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+// localVolume is a local directory bind mounted into a pod.
+type localVolume struct {
+	volName string
+	podUID  types.UID
+	mounter mount.Interface
+	plugin  *localVolumePlugin
+}
+
+var _ volume.MetricsProvider = &localVolume{}
+
+func (l *localVolume) GetPath() string {
+	return volume.NewDirectoryLayout(l.plugin.host).PodVolumeDir(l.podUID, localVolumePluginName, l.volName)
+}
+
+// GetMetrics reports the usage of the filesystem of the volume, which is
+// typically a disk of its own.
+func (l *localVolume) GetMetrics() (*volume.Metrics, error) {
+	return (&volume.MetricsStatFS{Path: l.GetPath()}).GetMetrics()
+}
+
+type localVolumeBuilder struct {
+	*localVolume
+	// localPath is the directory on the node that is mounted.
+	localPath string
+	readOnly  bool
+}
+
+var _ volume.Builder = &localVolumeBuilder{}
+
+// SetUp bind mounts the local directory at the volume path.
+func (b *localVolumeBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// SetUpAt bind mounts the local directory at dir.
+func (b *localVolumeBuilder) SetUpAt(dir string) error {
+	// Bind mounts of a directory on the same filesystem as dir can't be
+	// told apart from dir by its device.
+	notMnt, err := volume.IsNotMountPoint(dir, b.mounter)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && !notMnt {
+		return nil
+	}
+
+	info, err := os.Stat(b.localPath)
+	if err != nil {
+		return fmt.Errorf("local volume %s is not available at %s: %v", b.volName, b.localPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("local volume %s at %s is not a directory", b.volName, b.localPath)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	options := []string{"bind"}
+	if b.readOnly {
+		options = append(options, "ro")
+	}
+	glog.V(4).Infof("Mounting local volume %s at %s on %s", b.volName, b.localPath, dir)
+	if err := b.mounter.Mount(b.localPath, dir, "", options); err != nil {
+		if cleanupErr := volume.UnmountMountPoint(dir, b.mounter); cleanupErr != nil {
+			glog.Errorf("Failed to clean up %s after failing to mount local volume %s: %v", dir, b.volName, cleanupErr)
+			return err
+		}
+		volume.CleanupStagingDir(dir, err)
+		return err
+	}
+	return nil
+}
+
+// GetAttributes lets the Kubelet manage the ownership of writable volumes
+// only, as a read-only volume can't be changed anyway.
+func (b *localVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.readOnly,
+		Managed:             !b.readOnly,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
+}
+
+type localVolumeCleaner struct {
+	*localVolume
+}
+
+var _ volume.Cleaner = &localVolumeCleaner{}
+
+// TearDown unmounts the local directory from the volume path.
+func (c *localVolumeCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+// TearDownAt unmounts the local directory from dir and removes dir.  The
+// contents of the directory are kept.
+func (c *localVolumeCleaner) TearDownAt(dir string) error {
+	return volume.UnmountPath(dir, c.mounter)
+}
+
+// localVolumeDeleter refuses to delete local volumes: the plugin runs in
+// the controller manager, away from the disk, and the data of a disk that
+// an administrator made available should be removed by an administrator.
+type localVolumeDeleter struct {
+	name      string
+	localPath string
+}
+
+var _ volume.Deleter = &localVolumeDeleter{}
+
+func (d *localVolumeDeleter) GetPath() string {
+	return d.localPath
+}
+
+func (d *localVolumeDeleter) Delete() error {
+	return fmt.Errorf("local volume %s at %s can't be deleted, its data must be removed and the PersistentVolume deleted by an administrator", d.name, d.localPath)
+}
+
+// localBlockVolume is a raw local block device linked into a pod.
+type localBlockVolume struct {
+	volName string
+	podUID  types.UID
+	plugin  *localVolumePlugin
+}
+
+// GetGlobalMapPath returns the directory the devices of all local block
+// volumes of the node are linked in, each as the name of its volume.
+func (v *localBlockVolume) GetGlobalMapPath(spec *volume.Spec) (string, error) {
+	return volume.NewDirectoryLayout(v.plugin.host).PluginDeviceDir(localVolumePluginName), nil
+}
+
+func (v *localBlockVolume) GetPodDeviceMapPath() (string, string) {
+	return volume.NewDirectoryLayout(v.plugin.host).PodVolumeDeviceDir(v.podUID, localVolumePluginName), v.volName
+}
+
+type localBlockVolumeBuilder struct {
+	*localBlockVolume
+	devicePath string
+}
+
+var _ volume.BlockVolumeBuilder = &localBlockVolumeBuilder{}
+
+// SetUpDevice checks that the volume is a block device and returns its
+// path.  Local devices are attached to the node already.
+func (b *localBlockVolumeBuilder) SetUpDevice() (string, error) {
+	info, err := os.Stat(b.devicePath)
+	if err != nil {
+		return "", fmt.Errorf("local volume %s is not available at %s: %v", b.volName, b.devicePath, err)
+	}
+	if mode := info.Mode(); mode&os.ModeDevice == 0 || mode&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("local volume %s at %s is not a block device", b.volName, b.devicePath)
+	}
+	return b.devicePath, nil
+}
+
+type localBlockVolumeCleaner struct {
+	*localBlockVolume
+}
+
+var _ volume.BlockVolumeCleaner = &localBlockVolumeCleaner{}
+
+// TearDownDevice does nothing, the device stays attached to the node.
+func (c *localBlockVolumeCleaner) TearDownDevice(mapPath, devicePath string) error {
+	return nil
+}
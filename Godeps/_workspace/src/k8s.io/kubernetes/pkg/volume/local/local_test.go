@@ -0,0 +1,263 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+func newPlugin(t *testing.T, tmpDir string) volume.VolumePlugin {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost(tmpDir, nil, nil))
+
+	plug, err := plugMgr.FindPluginByName(localVolumePluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	return plug
+}
+
+func localSpec(localPath string, readOnly bool) *volume.Spec {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv-local"},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				Local: &api.LocalVolumeSource{Path: localPath},
+			},
+		},
+	}
+	return volume.NewSpecFromPersistentVolume(pv, readOnly)
+}
+
+func TestCanSupport(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plug := newPlugin(t, tmpDir)
+
+	if plug.Name() != localVolumePluginName {
+		t.Errorf("Wrong name: %s", plug.Name())
+	}
+	if !plug.CanSupport(localSpec("/mnt/disks/vol1", false)) {
+		t.Errorf("Expected true")
+	}
+	if plug.CanSupport(&volume.Spec{PersistentVolume: &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{}}}}) {
+		t.Errorf("Expected false")
+	}
+	if plug.CanSupport(&volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{}}}) {
+		t.Errorf("Expected false")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	localPath := path.Join(tmpDir, "disks/vol1")
+	if err := os.MkdirAll(localPath, 0750); err != nil {
+		t.Fatalf("can't make the local volume: %v", err)
+	}
+	plug := newPlugin(t, tmpDir)
+
+	fakeMounter := &mount.FakeMounter{}
+	builder, err := plug.(*localVolumePlugin).newBuilderInternal(localSpec(localPath, false), types.UID("poduid"), fakeMounter)
+	if err != nil {
+		t.Errorf("Failed to make a new Builder: %v", err)
+	}
+	if builder == nil {
+		t.Fatalf("Got a nil Builder")
+	}
+
+	volPath := path.Join(tmpDir, "pods/poduid/volumes/kubernetes.io~local-volume/pv-local")
+	if builder.GetPath() != volPath {
+		t.Errorf("Got unexpected path: %s", builder.GetPath())
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if _, err := os.Stat(volPath); err != nil {
+		t.Errorf("SetUp() failed to create the volume path: %v", err)
+	}
+	if len(fakeMounter.Log) != 1 || fakeMounter.Log[0].Action != mount.FakeActionMount || fakeMounter.Log[0].Source != localPath {
+		t.Errorf("Expected a mount of %s, got: %#v", localPath, fakeMounter.Log)
+	}
+
+	// Setting up a mounted volume again is a no-op.
+	fakeMounter.ResetLog()
+	if err := builder.SetUp(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if len(fakeMounter.Log) != 0 {
+		t.Errorf("Expected no mount, got: %#v", fakeMounter.Log)
+	}
+
+	cleaner, err := plug.(*localVolumePlugin).newCleanerInternal("pv-local", types.UID("poduid"), fakeMounter)
+	if err != nil {
+		t.Errorf("Failed to make a new Cleaner: %v", err)
+	}
+	if cleaner == nil {
+		t.Fatalf("Got a nil Cleaner")
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if _, err := os.Stat(volPath); !os.IsNotExist(err) {
+		t.Errorf("TearDown() failed to remove the volume path: %v", err)
+	}
+	if _, err := os.Stat(localPath); err != nil {
+		t.Errorf("TearDown() removed the local volume: %v", err)
+	}
+}
+
+func TestSetUpMissingPath(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plug := newPlugin(t, tmpDir)
+
+	fakeMounter := &mount.FakeMounter{}
+	builder, err := plug.(*localVolumePlugin).newBuilderInternal(localSpec(path.Join(tmpDir, "missing"), false), types.UID("poduid"), fakeMounter)
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error for a missing local path")
+	}
+	if len(fakeMounter.Log) != 0 {
+		t.Errorf("Expected no mount, got: %#v", fakeMounter.Log)
+	}
+}
+
+func TestGetAttributes(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plug := newPlugin(t, tmpDir)
+
+	tests := []struct {
+		readOnly bool
+		expected volume.Attributes
+	}{
+		{
+			readOnly: false,
+			expected: volume.Attributes{Managed: true, FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch, SupportsSELinux: true},
+		},
+		{
+			readOnly: true,
+			expected: volume.Attributes{ReadOnly: true, FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch, SupportsSELinux: true},
+		},
+	}
+	for _, test := range tests {
+		builder, err := plug.(*localVolumePlugin).newBuilderInternal(localSpec("/mnt/disks/vol1", test.readOnly), types.UID("poduid"), &mount.FakeMounter{})
+		if err != nil {
+			t.Fatalf("Failed to make a new Builder: %v", err)
+		}
+		if attrs := builder.GetAttributes(); attrs != test.expected {
+			t.Errorf("readOnly %v: expected %#v, got %#v", test.readOnly, test.expected, attrs)
+		}
+	}
+}
+
+func TestDeleter(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plug := newPlugin(t, tmpDir)
+
+	deleter, err := plug.(volume.DeletableVolumePlugin).NewDeleter(localSpec(tmpDir, false))
+	if err != nil {
+		t.Fatalf("Failed to make a new Deleter: %v", err)
+	}
+	if deleter.GetPath() != tmpDir {
+		t.Errorf("Expected %s but got %s", tmpDir, deleter.GetPath())
+	}
+	if err := deleter.Delete(); err == nil {
+		t.Errorf("Expected local volumes to refuse deletion")
+	}
+	if _, err := os.Stat(tmpDir); err != nil {
+		t.Errorf("Delete() removed the local volume: %v", err)
+	}
+}
+
+func TestBlockVolumeRequiresBlockDevice(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "localTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plug := newPlugin(t, tmpDir)
+
+	devicePath := path.Join(tmpDir, "device")
+	if err := ioutil.WriteFile(devicePath, []byte{}, 0640); err != nil {
+		t.Fatalf("can't make a fake device: %v", err)
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+	builder, err := plug.(volume.BlockVolumePlugin).NewBlockVolumeBuilder(localSpec(devicePath, false), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new BlockVolumeBuilder: %v", err)
+	}
+	if _, err := builder.SetUpDevice(); err == nil {
+		t.Errorf("Expected an error for a regular file")
+	}
+
+	mapDir, mapName := builder.GetPodDeviceMapPath()
+	if mapDir != path.Join(tmpDir, "pods/poduid/plugins/kubernetes.io~local-volume/volumeDevices") || mapName != "pv-local" {
+		t.Errorf("Got unexpected pod device map path: %s, %s", mapDir, mapName)
+	}
+}
+
+func TestNewPersistentVolumeTemplate(t *testing.T) {
+	pv, err := NewPersistentVolumeTemplate("node1", "/mnt/disks/vol1", resource.MustParse("10Gi"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pv.Spec.Local == nil || pv.Spec.Local.Path != "/mnt/disks/vol1" {
+		t.Errorf("Expected a local volume at /mnt/disks/vol1, got %#v", pv.Spec.PersistentVolumeSource)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != api.PersistentVolumeReclaimRetain {
+		t.Errorf("Expected the Retain reclaim policy, got %s", pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+	affinity, err := volume.GetNodeAffinity(pv)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !affinity.Matches(map[string]string{volume.HostnameLabel: "node1"}) {
+		t.Errorf("Expected the volume to be usable on node1, got %#v", affinity)
+	}
+	if affinity.Matches(map[string]string{volume.HostnameLabel: "node2"}) {
+		t.Errorf("Expected the volume not to be usable on node2, got %#v", affinity)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"time"
+)
+
+// Metrics describes the space and inode usage of a volume.  Sizes are in
+// bytes.
+type Metrics struct {
+	Capacity  int64
+	Used      int64
+	Available int64
+
+	Inodes     int64
+	InodesUsed int64
+	InodesFree int64
+
+	// Time is when the usage was measured.  It is only set by providers
+	// which don't measure on every call, see CachingMetricsProvider.
+	Time time.Time
+	// Stale is true if the usage could not be measured again when it was
+	// due, so that it is older than usual.
+	Stale bool
+}
+
+// MetricsProvider is implemented by volumes which can report their usage.
+type MetricsProvider interface {
+	GetMetrics() (*Metrics, error)
+}
+
+// MetricsStatFS reports the usage of the filesystem mounted at Path.  It can
+// be embedded by volumes which own their whole filesystem.  A single statfs
+// call measures the filesystem, however many files it has.
+type MetricsStatFS struct {
+	Path string
+}
+
+var _ MetricsProvider = &MetricsStatFS{}
+
+// NewMetricsStatFS returns a MetricsStatFS for the filesystem at path.
+func NewMetricsStatFS(path string) *MetricsStatFS {
+	return &MetricsStatFS{Path: path}
+}
+
+// NewMetricsProvider returns the MetricsProvider for the volume at path.
+// Volumes which are a dedicated filesystem, e.g. a disk, are measured with
+// statfs.  Other volumes share their filesystem, which says nothing about
+// their own usage, so they are walked with du instead.
+func NewMetricsProvider(path string, dedicatedFS bool) MetricsProvider {
+	if dedicatedFS {
+		return NewMetricsStatFS(path)
+	}
+	return NewMetricsDu(path)
+}
+
+// GetMetrics returns the usage of the filesystem containing m.Path.
+func (m *MetricsStatFS) GetMetrics() (*Metrics, error) {
+	return statfsMetrics(m.Path)
+}
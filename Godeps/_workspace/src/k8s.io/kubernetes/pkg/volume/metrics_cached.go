@@ -0,0 +1,105 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// CachingMetricsProvider measures the usage of a volume with another
+// MetricsProvider in the background, every period, and returns the last
+// usage measured without waiting.  It is meant for slow providers such as
+// MetricsDu, which walk the whole volume.  Measuring is a background job of
+// the volume, so it is skipped while SuspendBackgroundJobs holds the volume.
+type CachingMetricsProvider struct {
+	path     string
+	provider MetricsProvider
+	period   time.Duration
+	clock    util.Clock
+	jobs     *backgroundJobs
+
+	lock    sync.RWMutex
+	metrics *Metrics
+	// err is the error of the last measurement, if it failed.
+	err error
+}
+
+var _ MetricsProvider = &CachingMetricsProvider{}
+
+// NewCachingMetricsProvider returns a CachingMetricsProvider measuring the
+// volume at path with provider every period once it is Run.
+func NewCachingMetricsProvider(path string, provider MetricsProvider, period time.Duration) *CachingMetricsProvider {
+	return &CachingMetricsProvider{
+		path:     path,
+		provider: provider,
+		period:   period,
+		clock:    util.RealClock{},
+		jobs:     defaultBackgroundJobs,
+	}
+}
+
+// Run measures the usage every period until stopCh is closed.  The first
+// measurement is made right away.
+func (c *CachingMetricsProvider) Run(stopCh <-chan struct{}) {
+	util.Until(c.refresh, c.period, stopCh)
+}
+
+// refresh measures the usage once.  A failed measurement keeps the last
+// usage, which becomes stale, and so does a measurement skipped because the
+// background jobs of the volume are suspended.
+func (c *CachingMetricsProvider) refresh() {
+	if !c.jobs.start(c.path) {
+		glog.V(4).Infof("Background jobs for %s are suspended, not measuring its usage", c.path)
+		return
+	}
+	metrics, err := c.provider.GetMetrics()
+	c.jobs.finish(c.path)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.err = err
+	if err != nil {
+		glog.V(4).Infof("Failed to measure volume usage, keeping the last usage: %v", err)
+		return
+	}
+	if metrics.Time.IsZero() {
+		metrics.Time = c.clock.Now()
+	}
+	c.metrics = metrics
+}
+
+// GetMetrics returns a copy of the last usage measured.  It is Stale if the
+// last measurement failed, or if it is more than two periods old, e.g.
+// because measuring takes longer than the period.  The error of the last
+// measurement is only returned while no usage was measured yet; a volume
+// that was never measured is a transient error.
+func (c *CachingMetricsProvider) GetMetrics() (*Metrics, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.metrics == nil {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, NewTransientError("volume usage has not been measured yet")
+	}
+	metrics := *c.metrics
+	metrics.Stale = c.err != nil || c.clock.Since(metrics.Time) > 2*c.period
+	return &metrics, nil
+}
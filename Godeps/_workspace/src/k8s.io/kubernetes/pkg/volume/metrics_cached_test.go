@@ -0,0 +1,117 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+type fakeMetricsProvider struct {
+	used  int64
+	err   error
+	calls int
+}
+
+func (p *fakeMetricsProvider) GetMetrics() (*Metrics, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Metrics{Used: p.used}, nil
+}
+
+func TestCachingMetricsProvider(t *testing.T) {
+	provider := &fakeMetricsProvider{used: 100}
+	clock := &util.FakeClock{Time: time.Now()}
+	cache := NewCachingMetricsProvider("/vol", provider, time.Minute)
+	cache.clock = clock
+	cache.jobs = newBackgroundJobs()
+
+	if _, err := cache.GetMetrics(); !IsTransient(err) {
+		t.Errorf("Expected a transient error before the first measurement, got %v", err)
+	}
+
+	cache.refresh()
+	metrics, err := cache.GetMetrics()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.Used != 100 || metrics.Stale || !metrics.Time.Equal(clock.Now()) {
+		t.Errorf("Expected fresh usage of 100 measured now, got %+v", metrics)
+	}
+	if _, err := cache.GetMetrics(); err != nil || provider.calls != 1 {
+		t.Errorf("Expected cached usage to be returned without measuring, got %d measurements", provider.calls)
+	}
+
+	// A failed measurement keeps the last usage, marked stale.
+	measuredAt := clock.Now()
+	clock.Step(time.Minute)
+	provider.err = fmt.Errorf("du timed out")
+	cache.refresh()
+	metrics, err = cache.GetMetrics()
+	if err != nil {
+		t.Fatalf("Expected the last usage after a failed measurement, got %v", err)
+	}
+	if metrics.Used != 100 || !metrics.Stale || !metrics.Time.Equal(measuredAt) {
+		t.Errorf("Expected stale usage of 100 measured a minute ago, got %+v", metrics)
+	}
+
+	provider.err = nil
+	provider.used = 200
+	cache.refresh()
+	if metrics, _ := cache.GetMetrics(); metrics.Used != 200 || metrics.Stale {
+		t.Errorf("Expected fresh usage of 200, got %+v", metrics)
+	}
+
+	// Usage that was not refreshed for over two periods is stale.
+	clock.Step(3 * time.Minute)
+	if metrics, _ := cache.GetMetrics(); !metrics.Stale {
+		t.Errorf("Expected old usage to be stale, got %+v", metrics)
+	}
+}
+
+func TestCachingMetricsProviderFirstMeasurementFails(t *testing.T) {
+	provider := &fakeMetricsProvider{err: fmt.Errorf("no such directory")}
+	cache := NewCachingMetricsProvider("/vol", provider, time.Minute)
+	cache.jobs = newBackgroundJobs()
+
+	cache.refresh()
+	if _, err := cache.GetMetrics(); err == nil || IsTransient(err) {
+		t.Errorf("Expected the error of the measurement, got %v", err)
+	}
+}
+
+func TestCachingMetricsProviderSuspended(t *testing.T) {
+	provider := &fakeMetricsProvider{used: 100}
+	cache := NewCachingMetricsProvider("/vol", provider, time.Minute)
+	cache.jobs = newBackgroundJobs()
+
+	cache.jobs.suspend("/vol")
+	cache.refresh()
+	if provider.calls != 0 {
+		t.Errorf("Expected no measurement while the background jobs are suspended, got %d", provider.calls)
+	}
+	cache.jobs.resume("/vol")
+	cache.refresh()
+	if metrics, err := cache.GetMetrics(); err != nil || metrics.Used != 100 {
+		t.Errorf("Expected usage of 100 once resumed, got %+v, %v", metrics, err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+// MetricsDu reports the usage of a directory backed volume which shares its
+// filesystem with other data, such as an emptyDir or hostPath.  Space and
+// inodes used are measured by walking the directory with du and find, while
+// capacity and available space are those of the underlying filesystem.
+type MetricsDu struct {
+	Path   string
+	Runner exec.Interface
+}
+
+var _ MetricsProvider = &MetricsDu{}
+
+// NewMetricsDu returns a MetricsDu for the directory at path.
+func NewMetricsDu(path string) *MetricsDu {
+	return &MetricsDu{Path: path, Runner: exec.New()}
+}
+
+// GetMetrics walks m.Path to find its usage.  This can be slow for large
+// directories, so callers should cache the result.
+func (m *MetricsDu) GetMetrics() (*Metrics, error) {
+	metrics, err := statfsMetrics(m.Path)
+	if err != nil {
+		return nil, err
+	}
+	if metrics.Used, err = m.runDu(); err != nil {
+		return nil, err
+	}
+	if metrics.InodesUsed, err = m.runFind(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// runDu returns the number of bytes used by files under m.Path.
+func (m *MetricsDu) runDu() (int64, error) {
+	output, err := m.Runner.Command("du", "-s", "-B", "1", m.Path).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("du failed for %s: %v, output: %q", m.Path, err, string(output))
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("no output from du for %s", m.Path)
+	}
+	used, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output from du for %s: %q", m.Path, string(output))
+	}
+	return used, nil
+}
+
+// runFind returns the number of inodes used by m.Path, counting the
+// directory itself.
+func (m *MetricsDu) runFind() (int64, error) {
+	output, err := m.Runner.Command("find", m.Path, "-xdev").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("find failed for %s: %v, output: %q", m.Path, err, string(output))
+	}
+	return int64(bytes.Count(output, []byte("\n"))), nil
+}
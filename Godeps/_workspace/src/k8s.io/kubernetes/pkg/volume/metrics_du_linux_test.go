@@ -0,0 +1,85 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+func TestMetricsDu(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics_du_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outputs := []string{"4096\t" + dir + "\n", dir + "\n" + dir + "/a\n" + dir + "/b\n"}
+	var commands [][]string
+	fake := &exec.FakeExec{}
+	for i := range outputs {
+		output := outputs[i]
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			commands = append(commands, append([]string{cmd}, args...))
+			fcmd := &exec.FakeCmd{
+				CombinedOutputScript: []exec.FakeCombinedOutputAction{
+					func() ([]byte, error) { return []byte(output), nil },
+				},
+			}
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+
+	metrics, err := (&MetricsDu{Path: dir, Runner: fake}).GetMetrics()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"du", "-s", "-B", "1", dir}, {"find", dir, "-xdev"}}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected commands %v, got %v", expected, commands)
+	}
+	if metrics.Used != 4096 || metrics.InodesUsed != 3 {
+		t.Errorf("Expected 4096 bytes and 3 inodes used, got %+v", metrics)
+	}
+	if metrics.Capacity <= 0 || metrics.Available <= 0 || metrics.Inodes <= 0 {
+		t.Errorf("Expected capacity from statfs, got %+v", metrics)
+	}
+}
+
+func TestMetricsDuError(t *testing.T) {
+	fake := &exec.FakeExec{
+		CommandScript: []exec.FakeCommandAction{
+			func(cmd string, args ...string) exec.Cmd {
+				fcmd := &exec.FakeCmd{
+					CombinedOutputScript: []exec.FakeCombinedOutputAction{
+						func() ([]byte, error) { return []byte("du: cannot access"), &exec.FakeExitError{Status: 1} },
+					},
+				}
+				return exec.InitFakeCmd(fcmd, cmd, args...)
+			},
+		},
+	}
+	if _, err := (&MetricsDu{Path: os.TempDir(), Runner: fake}).GetMetrics(); err == nil {
+		t.Errorf("Expected du failure to be returned")
+	}
+}
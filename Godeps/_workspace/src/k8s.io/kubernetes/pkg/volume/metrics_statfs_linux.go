@@ -0,0 +1,41 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// statfsMetrics returns the usage of the filesystem containing path.
+func statfsMetrics(path string) (*Metrics, error) {
+	buf := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &buf); err != nil {
+		return nil, fmt.Errorf("statfs(%q): %v", path, err)
+	}
+	bsize := int64(buf.Bsize)
+	return &Metrics{
+		Capacity:   int64(buf.Blocks) * bsize,
+		Used:       int64(buf.Blocks-buf.Bfree) * bsize,
+		Available:  int64(buf.Bavail) * bsize,
+		Inodes:     int64(buf.Files),
+		InodesUsed: int64(buf.Files - buf.Ffree),
+		InodesFree: int64(buf.Ffree),
+	}, nil
+}
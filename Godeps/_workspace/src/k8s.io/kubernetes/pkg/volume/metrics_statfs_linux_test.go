@@ -0,0 +1,57 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMetricsStatFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics_statfs_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	metrics, err := NewMetricsStatFS(dir).GetMetrics()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.Capacity <= 0 || metrics.Available > metrics.Capacity || metrics.Used > metrics.Capacity {
+		t.Errorf("Unexpected space usage: %+v", metrics)
+	}
+	if metrics.Inodes > 0 && metrics.InodesUsed+metrics.InodesFree != metrics.Inodes {
+		t.Errorf("Unexpected inode usage: %+v", metrics)
+	}
+
+	if _, err := NewMetricsStatFS(dir + "/missing").GetMetrics(); err == nil {
+		t.Errorf("Expected an error for a missing path")
+	}
+}
+
+func TestNewMetricsProvider(t *testing.T) {
+	if _, ok := NewMetricsProvider("/vol", true).(*MetricsStatFS); !ok {
+		t.Errorf("Expected dedicated filesystems to be measured with statfs")
+	}
+	if _, ok := NewMetricsProvider("/vol", false).(*MetricsDu); !ok {
+		t.Errorf("Expected shared filesystems to be measured with du")
+	}
+}
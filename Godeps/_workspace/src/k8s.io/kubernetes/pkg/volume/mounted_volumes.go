@@ -0,0 +1,161 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// MountedVolume is a volume found set up in the volume directory of a pod.
+type MountedVolume struct {
+	// PluginName is the name of the plugin which set up the volume.
+	PluginName string
+	// VolumeName is the name of the volume in the pod.
+	VolumeName string
+	// Path is the directory the volume was set up in.
+	Path string
+	// Mounted is whether Path is a mount point.  Volumes like emptyDir
+	// are set up without mounting anything.
+	Mounted bool
+}
+
+// GetMountedVolumesForPod returns the volumes which are set up on this node
+// for the pod with the given UID, as recorded by the directories in its
+// volume directory rather than by the pod's spec, so that volumes left
+// behind by a deleted pod or by a restarted kubelet can be found.  A pod
+// without a volume directory has no volumes.
+func GetMountedVolumesForPod(host VolumeHost, podUID types.UID) ([]MountedVolume, error) {
+	podVolumesDir := NewDirectoryLayout(host).PodVolumesDir(podUID)
+	pluginDirs, err := ioutil.ReadDir(podVolumesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mounter := host.GetMounter()
+	volumes := []MountedVolume{}
+	for _, pluginDir := range pluginDirs {
+		if !pluginDir.IsDir() {
+			continue
+		}
+		pluginName := UnescapePluginName(pluginDir.Name())
+		volumeDirs, err := ioutil.ReadDir(path.Join(podVolumesDir, pluginDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, volumeDir := range volumeDirs {
+			if isPoisonMarker(volumeDir.Name()) {
+				continue
+			}
+			volumePath := host.GetPodVolumeDir(podUID, pluginDir.Name(), volumeDir.Name())
+			notMnt, err := mounter.IsLikelyNotMountPoint(volumePath)
+			if err != nil {
+				glog.Errorf("Could not check whether %s is a mount point: %v", volumePath, err)
+			}
+			volumes = append(volumes, MountedVolume{
+				PluginName: pluginName,
+				VolumeName: volumeDir.Name(),
+				Path:       volumePath,
+				Mounted:    err == nil && !notMnt,
+			})
+		}
+	}
+	return volumes, nil
+}
+
+// ReconstructedVolume is a volume found set up on the node together with the
+// spec rebuilt for it by its plugin.
+type ReconstructedVolume struct {
+	MountedVolume
+	// Spec is nil if the plugin of the volume is not a Reconstructor.
+	Spec *Spec
+}
+
+// ReconstructVolumesForPod returns the volumes set up on this node for the
+// pod with the given UID, with their specs rebuilt by their plugins, so that
+// a restarted kubelet can track volumes it did not set up itself and tear
+// down the volumes of pods which no longer exist.  Volumes whose plugin
+// cannot rebuild their spec are returned without one; volumes of unknown
+// plugins are skipped.
+func ReconstructVolumesForPod(host VolumeHost, pm *VolumePluginMgr, podUID types.UID) ([]ReconstructedVolume, error) {
+	mounted, err := GetMountedVolumesForPod(host, podUID)
+	if err != nil {
+		return nil, err
+	}
+	volumes := []ReconstructedVolume{}
+	for _, vol := range mounted {
+		plugin, err := pm.FindPluginByName(vol.PluginName)
+		if err != nil {
+			glog.Errorf("Could not find the plugin of volume %s: %v", vol.Path, err)
+			continue
+		}
+		reconstructed := ReconstructedVolume{MountedVolume: vol}
+		if reconstructor, ok := plugin.(Reconstructor); ok {
+			spec, err := reconstructor.ConstructVolumeSpec(vol.VolumeName, vol.Path)
+			if err != nil {
+				return nil, fmt.Errorf("could not reconstruct volume %s: %v", vol.Path, err)
+			}
+			reconstructed.Spec = spec
+		}
+		volumes = append(volumes, reconstructed)
+	}
+	return volumes, nil
+}
+
+// GetGlobalMountRef returns the mount of the device mounted at mountPath
+// which is in the directory of the named plugin, for Reconstructors of
+// plugins which mount devices globally to find which device a pod's mount
+// belongs to.
+func GetGlobalMountRef(host VolumeHost, pluginName, mountPath string) (string, error) {
+	refs, err := mount.GetMountRefs(host.GetMounter(), mountPath)
+	if err != nil {
+		return "", err
+	}
+	pluginDir := path.Clean(host.GetPluginDir(pluginName))
+	for _, ref := range refs {
+		if strings.HasPrefix(path.Clean(ref), pluginDir+"/") {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("no mount of %s in %s", mountPath, pluginDir)
+}
+
+// LastGlobalMountRef returns the global mount of a device given refs, the
+// other mounts of the device found with mount.PathExistsWithRefs before
+// unmounting a pod's mount of it.  The device may be detached only if its
+// global mount is its last reference: a mount left in the directory of a pod
+// means that another pod still uses the device, and anything else that it is
+// mounted by someone else.
+func LastGlobalMountRef(host VolumeHost, refs []string) (string, bool) {
+	if len(refs) != 1 {
+		return "", false
+	}
+	podsDir := NewDirectoryLayout(host).PodsDir()
+	if strings.HasPrefix(path.Clean(refs[0]), podsDir+"/") {
+		return "", false
+	}
+	return refs[0], true
+}
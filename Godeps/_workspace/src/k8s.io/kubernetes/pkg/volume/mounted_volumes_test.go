@@ -0,0 +1,116 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+func TestGetMountedVolumesForPod(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "mounted_volumes_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	host := NewFakeVolumeHost(tmpDir, nil, nil)
+
+	volumes, err := GetMountedVolumesForPod(host, "poduid")
+	if err != nil || len(volumes) != 0 {
+		t.Errorf("Expected no volumes for an unknown pod, got %v, %v", volumes, err)
+	}
+
+	emptyDirPath := host.GetPodVolumeDir("poduid", "kubernetes.io~empty-dir", "cache")
+	pdPath := host.GetPodVolumeDir("poduid", "kubernetes.io~gce-pd", "data")
+	for _, dir := range []string{emptyDirPath, pdPath} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatalf("Can't make %s: %v", dir, err)
+		}
+	}
+	// Markers of poisoned volume directories are no volumes.
+	if err := ioutil.WriteFile(poisonMarker(pdPath), []byte{}, 0640); err != nil {
+		t.Fatalf("Can't poison %s: %v", pdPath, err)
+	}
+	host.mounter = &mount.FakeMounter{MountPoints: []mount.MountPoint{{Device: "/dev/sdb", Path: pdPath}}}
+
+	volumes, err = GetMountedVolumesForPod(host, "poduid")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []MountedVolume{
+		{PluginName: "kubernetes.io/empty-dir", VolumeName: "cache", Path: emptyDirPath, Mounted: false},
+		{PluginName: "kubernetes.io/gce-pd", VolumeName: "data", Path: pdPath, Mounted: true},
+	}
+	if !reflect.DeepEqual(volumes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, volumes)
+	}
+}
+
+func TestLastGlobalMountRef(t *testing.T) {
+	host := NewFakeVolumeHost("/var/lib/kubelet", nil, nil)
+	globalPath := "/var/lib/kubelet/plugins/kubernetes.io/gce-pd/mounts/data"
+	otherPodPath := host.GetPodVolumeDir("otherpod", "kubernetes.io~gce-pd", "data")
+
+	tests := []struct {
+		refs     []string
+		expected string
+		detach   bool
+	}{
+		{refs: nil, detach: false},
+		{refs: []string{globalPath}, expected: globalPath, detach: true},
+		{refs: []string{otherPodPath}, detach: false},
+		{refs: []string{globalPath, otherPodPath}, detach: false},
+	}
+	for i, test := range tests {
+		path, detach := LastGlobalMountRef(host, test.refs)
+		if path != test.expected || detach != test.detach {
+			t.Errorf("%d: expected %q, %v, got %q, %v", i, test.expected, test.detach, path, detach)
+		}
+	}
+}
+
+func TestReconstructVolumesForPod(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "mounted_volumes_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	host := NewFakeVolumeHost(tmpDir, nil, nil)
+	plugMgr := VolumePluginMgr{}
+	plugMgr.InitPlugins([]VolumePlugin{&FakeVolumePlugin{PluginName: "kubernetes.io/fake"}}, host)
+
+	for _, plugin := range []string{"kubernetes.io~fake", "kubernetes.io~unknown"} {
+		if err := os.MkdirAll(host.GetPodVolumeDir("poduid", plugin, "data"), 0750); err != nil {
+			t.Fatalf("Can't make the volume dir: %v", err)
+		}
+	}
+
+	volumes, err := ReconstructVolumesForPod(host, &plugMgr, "poduid")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(volumes) != 1 {
+		t.Fatalf("Expected only the volume of the known plugin, got %+v", volumes)
+	}
+	if volumes[0].PluginName != "kubernetes.io/fake" || volumes[0].Spec == nil || volumes[0].Spec.Name() != "data" {
+		t.Errorf("Unexpected volume: %+v", volumes[0])
+	}
+}
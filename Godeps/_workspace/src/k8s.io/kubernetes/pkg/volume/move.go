@@ -0,0 +1,163 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// moveBusyRetries is how many times a rename failing with EBUSY, e.g. while
+// an unmount beneath the directory is settling, is retried.
+const moveBusyRetries = 5
+
+var (
+	// Replaced in tests.
+	moveRename        = renameDirectory
+	moveRetryInterval = 100 * time.Millisecond
+)
+
+// MoveDirectory moves oldPath to a new temporary directory next to it whose
+// name starts with newNamePrefix and returns its path.  Renames failing with
+// EBUSY are retried, and a directory which can't be renamed because the new
+// path is on another device, e.g. an emptyDir backed by tmpfs, is copied and
+// then deleted.  If the move fails, the temporary directory is removed and
+// oldPath is left as it was; only if oldPath can't be deleted once copied is
+// the copy kept and its path returned along with the error.
+//
+// Callers that only move in order to delete should use RenameAndDelete,
+// which also makes sure nothing is mounted beneath the directory.
+func MoveDirectory(oldPath, newNamePrefix string) (string, error) {
+	newPath, err := ioutil.TempDir(path.Dir(oldPath), newNamePrefix)
+	if err != nil {
+		return "", err
+	}
+
+	err = renameRetryingBusy(oldPath, newPath)
+	if isCrossDevice(err) {
+		glog.V(4).Infof("Can't rename %s to %s across devices, copying it", oldPath, newPath)
+		if err = copyDirectory(oldPath, newPath); err == nil {
+			if err := os.RemoveAll(oldPath); err != nil {
+				return newPath, fmt.Errorf("copied %s to %s, but failed to delete it: %v", oldPath, newPath, err)
+			}
+			return newPath, nil
+		}
+	}
+	if err != nil {
+		if rmErr := os.RemoveAll(newPath); rmErr != nil {
+			glog.Errorf("Failed to remove %s after a failed move of %s: %v", newPath, oldPath, rmErr)
+		}
+		return "", err
+	}
+	return newPath, nil
+}
+
+func renameRetryingBusy(oldPath, newPath string) error {
+	var err error
+	for i := 0; i <= moveBusyRetries; i++ {
+		if i > 0 {
+			glog.V(4).Infof("Renaming %s is busy, retrying: %v", oldPath, err)
+			time.Sleep(moveRetryInterval)
+		}
+		if err = moveRename(oldPath, newPath); !isErrno(err, syscall.EBUSY) {
+			return err
+		}
+	}
+	return err
+}
+
+func isCrossDevice(err error) bool {
+	return isErrno(err, syscall.EXDEV)
+}
+
+func isErrno(err error, errno syscall.Errno) bool {
+	if linkErr, ok := err.(*os.LinkError); ok {
+		err = linkErr.Err
+	}
+	return err == errno
+}
+
+// copyDirectory copies the tree at src into dst, an existing directory,
+// keeping the modes, including the setgid bits of fsGroup, and the owners of
+// the files.  Symlinks are copied as they are, and files other than
+// directories, regular files and symlinks are an error.
+func copyDirectory(src, dst string) error {
+	return filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		mode := info.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			link, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+			return copyOwnership(target, info)
+		case mode.IsRegular():
+			if err := copyFile(file, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("can't copy %s of mode %v", file, mode)
+		}
+		// A chown clears the setuid and setgid bits, so the mode comes last.
+		if err := copyOwnership(target, info); err != nil {
+			return err
+		}
+		return os.Chmod(target, mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
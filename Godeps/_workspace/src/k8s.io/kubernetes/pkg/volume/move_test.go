@@ -0,0 +1,173 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// withMoveRename replaces the rename of MoveDirectory with rename for the
+// duration of a test.
+func withMoveRename(rename func(oldPath, newPath string) error) func() {
+	origRename, origInterval := moveRename, moveRetryInterval
+	moveRename = rename
+	moveRetryInterval = 0
+	return func() {
+		moveRename, moveRetryInterval = origRename, origInterval
+	}
+}
+
+func makeMoveSource(t *testing.T) (string, string) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "moveTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	src := path.Join(tmpDir, "volume")
+	if err := os.MkdirAll(path.Join(src, "sub"), 0750); err != nil {
+		t.Fatalf("can't make the source: %v", err)
+	}
+	if err := os.Chmod(path.Join(src, "sub"), os.ModeSetgid|0770); err != nil {
+		t.Fatalf("can't chmod the source: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(src, "sub/file"), []byte("data"), 0640); err != nil {
+		t.Fatalf("can't write the source: %v", err)
+	}
+	if err := os.Symlink("sub/file", path.Join(src, "link")); err != nil {
+		t.Fatalf("can't link the source: %v", err)
+	}
+	return tmpDir, src
+}
+
+func checkMoved(t *testing.T, src, newPath string) {
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be gone, got: %v", src, err)
+	}
+	if !strings.HasPrefix(path.Base(newPath), "volume.deleting~") {
+		t.Errorf("Unexpected new path %s", newPath)
+	}
+	if data, err := ioutil.ReadFile(path.Join(newPath, "link")); err != nil || string(data) != "data" {
+		t.Errorf("Expected the file through the link, got %q: %v", string(data), err)
+	}
+	info, err := os.Stat(path.Join(newPath, "sub"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Mode()&os.ModeSetgid == 0 || info.Mode().Perm() != 0770 {
+		t.Errorf("Expected the mode of the directory to be kept, got %v", info.Mode())
+	}
+	info, err = os.Stat(path.Join(newPath, "sub/file"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Expected the mode of the file to be kept, got %v", info.Mode())
+	}
+}
+
+func checkOnlySource(t *testing.T, tmpDir string) {
+	entries, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "volume" {
+		t.Errorf("Expected only the source to be left, got %v", entries)
+	}
+}
+
+func TestMoveDirectory(t *testing.T) {
+	tmpDir, src := makeMoveSource(t)
+	defer os.RemoveAll(tmpDir)
+
+	newPath, err := MoveDirectory(src, "volume.deleting~")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkMoved(t, src, newPath)
+}
+
+func TestMoveDirectoryAcrossDevices(t *testing.T) {
+	defer withMoveRename(func(oldPath, newPath string) error {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EXDEV}
+	})()
+	tmpDir, src := makeMoveSource(t)
+	defer os.RemoveAll(tmpDir)
+
+	newPath, err := MoveDirectory(src, "volume.deleting~")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkMoved(t, src, newPath)
+}
+
+func TestMoveDirectoryRetriesBusy(t *testing.T) {
+	calls := 0
+	defer withMoveRename(func(oldPath, newPath string) error {
+		calls++
+		if calls < 3 {
+			return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EBUSY}
+		}
+		return renameDirectory(oldPath, newPath)
+	})()
+	tmpDir, src := makeMoveSource(t)
+	defer os.RemoveAll(tmpDir)
+
+	newPath, err := MoveDirectory(src, "volume.deleting~")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 renames, got %d", calls)
+	}
+	checkMoved(t, src, newPath)
+}
+
+func TestMoveDirectoryFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		errno         syscall.Errno
+		expectedCalls int
+	}{
+		{name: "permission denied", errno: syscall.EACCES, expectedCalls: 1},
+		{name: "busy", errno: syscall.EBUSY, expectedCalls: moveBusyRetries + 1},
+	}
+	for _, test := range tests {
+		calls := 0
+		restore := withMoveRename(func(oldPath, newPath string) error {
+			calls++
+			return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: test.errno}
+		})
+		tmpDir, src := makeMoveSource(t)
+
+		newPath, err := MoveDirectory(src, "volume.deleting~")
+		if err == nil || newPath != "" {
+			t.Errorf("%s: expected an error, got %q", test.name, newPath)
+		}
+		if calls != test.expectedCalls {
+			t.Errorf("%s: expected %d renames, got %d", test.name, test.expectedCalls, calls)
+		}
+		// The temporary directory must not leak.
+		checkOnlySource(t, tmpDir)
+
+		restore()
+		os.RemoveAll(tmpDir)
+	}
+}
@@ -19,10 +19,11 @@ package nfs
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 
@@ -71,6 +72,14 @@ func (plugin *nfsPlugin) CanSupport(spec *volume.Spec) bool {
 		(spec.Volume != nil && spec.Volume.NFS != nil)
 }
 
+func (plugin *nfsPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.NFS == nil {
+		return "", fmt.Errorf("spec does not reference a NFS volume")
+	}
+	return fmt.Sprintf("%s:%s", source.NFS.Server, source.NFS.Path), nil
+}
+
 func (plugin *nfsPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -84,15 +93,8 @@ func (plugin *nfsPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.Vo
 }
 
 func (plugin *nfsPlugin) newBuilderInternal(spec *volume.Spec, pod *api.Pod, mounter mount.Interface) (volume.Builder, error) {
-	var source *api.NFSVolumeSource
-	var readOnly bool
-	if spec.Volume != nil && spec.Volume.NFS != nil {
-		source = spec.Volume.NFS
-		readOnly = spec.Volume.NFS.ReadOnly
-	} else {
-		source = spec.PersistentVolume.Spec.NFS
-		readOnly = spec.ReadOnly
-	}
+	source := spec.PersistentVolumeSource().NFS
+	readOnly := spec.IsReadOnly()
 	return &nfsBuilder{
 		nfs: &nfs{
 			volName: spec.Name(),
@@ -100,9 +102,10 @@ func (plugin *nfsPlugin) newBuilderInternal(spec *volume.Spec, pod *api.Pod, mou
 			pod:     pod,
 			plugin:  plugin,
 		},
-		server:     source.Server,
-		exportPath: source.Path,
-		readOnly:   readOnly,
+		server:       source.Server,
+		exportPath:   source.Path,
+		readOnly:     readOnly,
+		mountOptions: spec.MountOptions(),
 	}, nil
 }
 
@@ -111,12 +114,15 @@ func (plugin *nfsPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cl
 }
 
 func (plugin *nfsPlugin) newCleanerInternal(volName string, podUID types.UID, mounter mount.Interface) (volume.Cleaner, error) {
-	return &nfsCleaner{&nfs{
-		volName: volName,
-		mounter: mounter,
-		pod:     &api.Pod{ObjectMeta: api.ObjectMeta{UID: podUID}},
-		plugin:  plugin,
-	}}, nil
+	return &nfsCleaner{
+		nfs: &nfs{
+			volName: volName,
+			mounter: mounter,
+			pod:     &api.Pod{ObjectMeta: api.ObjectMeta{UID: podUID}},
+			plugin:  plugin,
+		},
+		exe: exec.New(),
+	}, nil
 }
 
 func (plugin *nfsPlugin) NewRecycler(spec *volume.Spec) (volume.Recycler, error) {
@@ -135,22 +141,25 @@ type nfs struct {
 
 func (nfsVolume *nfs) GetPath() string {
 	name := nfsPluginName
-	return nfsVolume.plugin.host.GetPodVolumeDir(nfsVolume.pod.UID, util.EscapeQualifiedNameForDisk(name), nfsVolume.volName)
+	return volume.NewDirectoryLayout(nfsVolume.plugin.host).PodVolumeDir(nfsVolume.pod.UID, name, nfsVolume.volName)
+}
+
+// GetMetrics reports the usage of the mounted share.  Walking the share over
+// the network would be too slow, so its whole filesystem is reported.
+func (nfsVolume *nfs) GetMetrics() (*volume.Metrics, error) {
+	return volume.NewMetricsStatFS(nfsVolume.GetPath()).GetMetrics()
 }
 
 type nfsBuilder struct {
 	*nfs
-	server     string
-	exportPath string
-	readOnly   bool
+	server       string
+	exportPath   string
+	readOnly     bool
+	mountOptions []string
 }
 
 var _ volume.Builder = &nfsBuilder{}
 
-func (_ *nfsBuilder) SupportsOwnershipManagement() bool {
-	return false
-}
-
 // SetUp attaches the disk and bind mounts to the volume path.
 func (b *nfsBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
@@ -171,7 +180,7 @@ func (b *nfsBuilder) SetUpAt(dir string) error {
 	if b.readOnly {
 		options = append(options, "ro")
 	}
-	err = b.mounter.Mount(source, dir, "nfs", options)
+	err = b.mounter.Mount(source, dir, "nfs", volume.JoinMountOptions(b.mountOptions, options))
 	if err != nil {
 		notMnt, mntErr := b.mounter.IsLikelyNotMountPoint(dir)
 		if mntErr != nil {
@@ -194,79 +203,74 @@ func (b *nfsBuilder) SetUpAt(dir string) error {
 				return err
 			}
 		}
-		os.Remove(dir)
+		volume.CleanupStagingDir(dir, err)
 		return err
 	}
 	return nil
 }
 
-func (b *nfsBuilder) IsReadOnly() bool {
-	return b.readOnly
-}
+var _ volume.Verifier = &nfsBuilder{}
 
-func (b *nfsBuilder) SupportsSELinux() bool {
-	return false
+// Verify checks that the export is readable, and writable unless the volume
+// is read-only.
+func (b *nfsBuilder) Verify() error {
+	return volume.VerifyPath(b.GetPath(), b.readOnly)
 }
 
-//
-//func (c *nfsCleaner) GetPath() string {
-//	name := nfsPluginName
-//	return c.plugin.host.GetPodVolumeDir(c.pod.UID, util.EscapeQualifiedNameForDisk(name), c.volName)
-//}
+func (b *nfsBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:        b.readOnly,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
+}
 
 var _ volume.Cleaner = &nfsCleaner{}
 
 type nfsCleaner struct {
 	*nfs
+	exe exec.Interface
 }
 
 func (c *nfsCleaner) TearDown() error {
 	return c.TearDownAt(c.GetPath())
 }
 
+// TearDownAt unmounts dir, escalating to a forced and then a lazy unmount
+// if UnmountGracePeriod is configured, since the unmount of an export whose
+// server is gone hangs.
 func (c *nfsCleaner) TearDownAt(dir string) error {
-	notMnt, err := c.mounter.IsLikelyNotMountPoint(dir)
-	if err != nil {
-		glog.Errorf("Error checking IsLikelyNotMountPoint: %v", err)
-		return err
-	}
-	if notMnt {
-		return os.Remove(dir)
+	mounter := c.mounter
+	if gracePeriod := c.plugin.config.UnmountGracePeriod; gracePeriod > 0 {
+		mounter = volume.NewEscalatingMounter(mounter, c.exe, gracePeriod)
 	}
-
-	if err := c.mounter.Unmount(dir); err != nil {
-		glog.Errorf("Unmounting failed: %v", err)
-		return err
-	}
-	notMnt, mntErr := c.mounter.IsLikelyNotMountPoint(dir)
-	if mntErr != nil {
-		glog.Errorf("IsLikelyNotMountPoint check failed: %v", mntErr)
-		return mntErr
-	}
-	if notMnt {
-		if err := os.Remove(dir); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return volume.UnmountPath(dir, mounter)
 }
 
+// scrubberFactory makes the scrubbers of recyclers.  The files of a volume
+// can be deleted or shredded, but it is not a block device to reformat.
+var scrubberFactory = volume.NewScrubberFactory(volume.ScrubStrategyDelete, volume.ScrubStrategyShred)
+
 func newRecycler(spec *volume.Spec, host volume.VolumeHost, volumeConfig volume.VolumeConfig) (volume.Recycler, error) {
 	if spec.PersistentVolume == nil || spec.PersistentVolume.Spec.NFS == nil {
 		return nil, fmt.Errorf("spec.PersistentVolumeSource.NFS is nil")
 	}
+	scrubber, err := scrubberFactory.NewScrubber(spec.PersistentVolume)
+	if err != nil {
+		return nil, err
+	}
 	return &nfsRecycler{
-		name:    spec.Name(),
-		server:  spec.PersistentVolume.Spec.NFS.Server,
-		path:    spec.PersistentVolume.Spec.NFS.Path,
-		host:    host,
-		config:  volumeConfig,
-		timeout: volume.CalculateTimeoutForVolume(volumeConfig.RecyclerMinimumTimeout, volumeConfig.RecyclerTimeoutIncrement, spec.PersistentVolume),
+		name:     spec.Name(),
+		server:   spec.PersistentVolume.Spec.NFS.Server,
+		path:     spec.PersistentVolume.Spec.NFS.Path,
+		host:     host,
+		config:   volumeConfig,
+		timeout:  volume.CalculateTimeoutForVolume(volumeConfig.RecyclerMinimumTimeout, volumeConfig.RecyclerTimeoutIncrement, spec.PersistentVolume),
+		scrubber: scrubber,
 	}, nil
 }
 
-// nfsRecycler scrubs an NFS volume by running "rm -rf" on the volume in a pod.
+// nfsRecycler scrubs an NFS volume in a pod set up by its scrubber.
 type nfsRecycler struct {
 	name    string
 	server  string
@@ -274,6 +278,8 @@ type nfsRecycler struct {
 	host    volume.VolumeHost
 	config  volume.VolumeConfig
 	timeout int64
+	// scrubber sets up the recycler pod to scrub the volume.
+	scrubber volume.Scrubber
 }
 
 func (r *nfsRecycler) GetPath() string {
@@ -283,15 +289,39 @@ func (r *nfsRecycler) GetPath() string {
 // Recycle recycles/scrubs clean an NFS volume.
 // Recycle blocks until the pod has completed or any error occurs.
 func (r *nfsRecycler) Recycle() error {
-	pod := r.config.RecyclerPodTemplate
-	// overrides
-	pod.Spec.ActiveDeadlineSeconds = &r.timeout
-	pod.GenerateName = "pv-recycler-nfs-"
-	pod.Spec.Volumes[0].VolumeSource = api.VolumeSource{
+	return r.RecycleWithTimeout(0, nil)
+}
+
+// RecycleWithTimeout is like Recycle, but gives up after timeout and
+// reports the phases of the recycler pod to progress.
+func (r *nfsRecycler) RecycleWithTimeout(timeout time.Duration, progress volume.RecycleProgressFunc) error {
+	executor, err := r.newScrubExecutor()
+	if err != nil {
+		return err
+	}
+	return executor.Scrub(timeout, progress)
+}
+
+// newScrubExecutor returns the executor of the scrub, which mounts the export
+// on the host if the config asks for it and runs a recycler pod otherwise.
+func (r *nfsRecycler) newScrubExecutor() (volume.ScrubExecutor, error) {
+	if r.config.RecyclerScrubOnHost {
+		mounter := r.host.GetMounter()
+		if mounter == nil {
+			mounter = mount.New()
+		}
+		source := volume.HostScrubMount{Source: fmt.Sprintf("%s:%s", r.server, r.path), FSType: "nfs"}
+		return volume.NewMountingHostScrubExecutor(r.scrubber.Strategy(), source, mounter, exec.New())
+	}
+	pod, err := volume.NewRecyclerPod(r.config, "pv-recycler-nfs-", r.timeout, api.VolumeSource{
 		NFS: &api.NFSVolumeSource{
 			Server: r.server,
 			Path:   r.path,
 		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	return volume.RecycleVolumeByWatchingPodUntilCompletion(pod, r.host.GetKubeClient())
+	r.scrubber.SetUpRecyclerPod(pod)
+	return volume.NewPodScrubExecutor(pod, r.host.GetKubeClient()), nil
 }
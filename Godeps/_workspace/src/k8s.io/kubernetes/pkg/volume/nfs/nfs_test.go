@@ -17,6 +17,7 @@ limitations under the License.
 package nfs
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -26,6 +27,7 @@ import (
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/volumetest"
 )
 
 func TestCanSupport(t *testing.T) {
@@ -49,6 +51,19 @@ func TestCanSupport(t *testing.T) {
 	}
 }
 
+func TestGetVolumeName(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/nfs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	spec := &volume.Spec{Volume: &api.Volume{Name: "vol1", VolumeSource: api.VolumeSource{NFS: &api.NFSVolumeSource{Server: "localhost", Path: "/export/data"}}}}
+	if name, err := plug.GetVolumeName(spec); err != nil || name != "localhost:/export/data" {
+		t.Errorf("Expected the server and path, got %q, %v", name, err)
+	}
+}
+
 func TestGetAccessModes(t *testing.T) {
 	plugMgr := volume.VolumePluginMgr{}
 	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
@@ -83,6 +98,24 @@ func TestRecycler(t *testing.T) {
 	}
 }
 
+func TestRecyclerScrubStrategy(t *testing.T) {
+	pv := &api.PersistentVolume{Spec: api.PersistentVolumeSpec{PersistentVolumeSource: api.PersistentVolumeSource{NFS: &api.NFSVolumeSource{Path: "/foo"}}}}
+	pv.Annotations = map[string]string{volume.RecycleScrubberAnnotation: string(volume.ScrubStrategyShred)}
+	recycler, err := newRecycler(volume.NewSpecFromPersistentVolume(pv, false), nil, volume.VolumeConfig{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Recycler: %v", err)
+	}
+	if strategy := recycler.(*nfsRecycler).scrubber.Strategy(); strategy != volume.ScrubStrategyShred {
+		t.Errorf("Expected the shred scrubber, got %s", strategy)
+	}
+
+	// An NFS volume is not a block device to reformat.
+	pv.Annotations[volume.RecycleScrubberAnnotation] = string(volume.ScrubStrategyReformat)
+	if _, err := newRecycler(volume.NewSpecFromPersistentVolume(pv, false), nil, volume.VolumeConfig{}); err == nil {
+		t.Errorf("Expected reformatting an NFS volume to be refused")
+	}
+}
+
 func newMockRecycler(spec *volume.Spec, host volume.VolumeHost, config volume.VolumeConfig) (volume.Recycler, error) {
 	return &mockRecycler{
 		path: spec.PersistentVolume.Spec.NFS.Path,
@@ -152,6 +185,9 @@ func doTestPlugin(t *testing.T, spec *volume.Spec) {
 		if fake.Log[0].Action != mount.FakeActionMount {
 			t.Errorf("Unexpected mounter action: %#v", fake.Log[0])
 		}
+		if fake.Log[0].Source != "localhost:/tmp" || fake.Log[0].FSType != "nfs" {
+			t.Errorf("Expected an nfs mount of localhost:/tmp, got %#v", fake.Log[0])
+		}
 	}
 	fake.ResetLog()
 
@@ -247,7 +283,36 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestBuilderIdempotency(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "nfs_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost(tmpDir, nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/nfs")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
 	}
+	spec := volume.NewSpecFromVolume(&api.Volume{
+		Name:         "vol1",
+		VolumeSource: api.VolumeSource{NFS: &api.NFSVolumeSource{Server: "localhost", Path: "/somepath"}},
+	})
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
+	fake := &mount.FakeMounter{}
+
+	volumetest.RunBuilderIdempotencyTests(t, func() (volume.Builder, volume.Cleaner, error) {
+		builder, err := plug.(*nfsPlugin).newBuilderInternal(spec, pod, fake)
+		if err != nil {
+			return nil, nil, err
+		}
+		cleaner, err := plug.(*nfsPlugin).newCleanerInternal("vol1", pod.UID, fake)
+		return builder, cleaner, err
+	})
 }
@@ -0,0 +1,84 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// openMetricsFamily is a gauge family exported for every volume.
+type openMetricsFamily struct {
+	name  string
+	unit  string
+	help  string
+	value func(*Metrics) int64
+}
+
+var openMetricsFamilies = []openMetricsFamily{
+	{"volume_capacity_bytes", "bytes", "Capacity of the volume.", func(m *Metrics) int64 { return m.Capacity }},
+	{"volume_used_bytes", "bytes", "Space used on the volume.", func(m *Metrics) int64 { return m.Used }},
+	{"volume_available_bytes", "bytes", "Space available to unprivileged users of the volume.", func(m *Metrics) int64 { return m.Available }},
+	{"volume_inodes", "", "Number of inodes on the volume.", func(m *Metrics) int64 { return m.Inodes }},
+	{"volume_inodes_used", "", "Number of inodes in use on the volume.", func(m *Metrics) int64 { return m.InodesUsed }},
+	{"volume_inodes_free", "", "Number of free inodes on the volume.", func(m *Metrics) int64 { return m.InodesFree }},
+}
+
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteMetricsOpenMetrics collects the metrics of every volume which
+// implements MetricsProvider and writes them to w in the OpenMetrics text
+// format, labelled with the volume's path.  Volumes whose metrics can not
+// be collected are logged and left out.
+func WriteMetricsOpenMetrics(w io.Writer, volumes []Volume) error {
+	type sample struct {
+		path    string
+		metrics *Metrics
+	}
+	var samples []sample
+	for _, v := range volumes {
+		provider, ok := UnwrapVolume(v).(MetricsProvider)
+		if !ok {
+			continue
+		}
+		metrics, err := provider.GetMetrics()
+		if err != nil {
+			glog.Warningf("Failed to get metrics of volume %s: %v", v.GetPath(), err)
+			continue
+		}
+		samples = append(samples, sample{v.GetPath(), metrics})
+	}
+
+	// Every sample of a family has to follow its metadata.
+	bw := bufio.NewWriter(w)
+	for _, family := range openMetricsFamilies {
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", family.name)
+		if family.unit != "" {
+			fmt.Fprintf(bw, "# UNIT %s %s\n", family.name, family.unit)
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s{volume_path=\"%s\"} %d\n", family.name, openMetricsLabelEscaper.Replace(s.path), family.value(s.metrics))
+		}
+	}
+	fmt.Fprint(bw, "# EOF\n")
+	return bw.Flush()
+}
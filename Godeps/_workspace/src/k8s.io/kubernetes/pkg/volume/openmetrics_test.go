@@ -0,0 +1,126 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type fakeMetricsVolume struct {
+	path    string
+	metrics *Metrics
+	err     error
+}
+
+func (v *fakeMetricsVolume) GetPath() string {
+	return v.path
+}
+
+func (v *fakeMetricsVolume) GetMetrics() (*Metrics, error) {
+	return v.metrics, v.err
+}
+
+var (
+	openMetricsSampleRE   = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)\{volume_path="((?:[^"\\\n]|\\["\\n])*)"\} (-?[0-9]+)$`)
+	openMetricsMetadataRE = regexp.MustCompile(`^# (TYPE|UNIT|HELP) ([a-zA-Z_:][a-zA-Z0-9_:]*) (.*)$`)
+)
+
+// parseOpenMetrics checks that text is valid OpenMetrics as produced by
+// WriteMetricsOpenMetrics and returns the samples keyed by family then by
+// unescaped volume path.
+func parseOpenMetrics(text string) (map[string]map[string]int64, error) {
+	if !strings.HasSuffix(text, "# EOF\n") {
+		return nil, fmt.Errorf("missing # EOF terminator")
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "# EOF\n"), "\n")
+	samples := map[string]map[string]int64{}
+	var family string
+	for _, line := range lines[:len(lines)-1] {
+		if m := openMetricsMetadataRE.FindStringSubmatch(line); m != nil {
+			if m[1] == "TYPE" {
+				if _, seen := samples[m[2]]; seen {
+					return nil, fmt.Errorf("family %s is not contiguous", m[2])
+				}
+				family = m[2]
+				samples[family] = map[string]int64{}
+			} else if m[2] != family {
+				return nil, fmt.Errorf("metadata %q outside its family", line)
+			}
+			continue
+		}
+		m := openMetricsSampleRE.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		if m[1] != family {
+			return nil, fmt.Errorf("sample %q outside its family", line)
+		}
+		path, err := strconv.Unquote(`"` + m[2] + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("bad label value in %q: %v", line, err)
+		}
+		value, _ := strconv.ParseInt(m[3], 10, 64)
+		samples[family][path] = value
+	}
+	return samples, nil
+}
+
+func TestWriteMetricsOpenMetrics(t *testing.T) {
+	odd := "/vol/\"quoted\"\\back\nslash"
+	volumes := []Volume{
+		&fakeMetricsVolume{path: "/vol/a", metrics: &Metrics{Capacity: 100, Used: 40, Available: 60, Inodes: 10, InodesUsed: 3, InodesFree: 7}},
+		&fakeMetricsVolume{path: odd, metrics: &Metrics{Capacity: 200, Used: 20, Available: 180, Inodes: 20, InodesUsed: 1, InodesFree: 19}},
+		&fakeMetricsVolume{path: "/vol/broken", err: fmt.Errorf("statfs failed")},
+		&FakeVolume{},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMetricsOpenMetrics(&buf, volumes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	samples, err := parseOpenMetrics(buf.String())
+	if err != nil {
+		t.Fatalf("Invalid OpenMetrics output: %v\n%s", err, buf.String())
+	}
+
+	expected := map[string]map[string]int64{
+		"volume_capacity_bytes":  {"/vol/a": 100, odd: 200},
+		"volume_used_bytes":      {"/vol/a": 40, odd: 20},
+		"volume_available_bytes": {"/vol/a": 60, odd: 180},
+		"volume_inodes":          {"/vol/a": 10, odd: 20},
+		"volume_inodes_used":     {"/vol/a": 3, odd: 1},
+		"volume_inodes_free":     {"/vol/a": 7, odd: 19},
+	}
+	if len(samples) != len(expected) {
+		t.Errorf("Expected %d families, got %d: %v", len(expected), len(samples), samples)
+	}
+	for family, values := range expected {
+		if len(samples[family]) != len(values) {
+			t.Errorf("%s: expected %v, got %v", family, values, samples[family])
+		}
+		for path, value := range values {
+			if got, found := samples[family][path]; !found || got != value {
+				t.Errorf("%s{%q}: expected %d, got %d (found %v)", family, path, value, got, found)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	operationStatusSuccess = "success"
+	operationStatusFailure = "failure"
+)
+
+var (
+	operationTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "volume_operation_total",
+			Help: "Cumulative number of volume operations. Broken down by plugin, operation and status.",
+		},
+		[]string{"plugin", "operation", "status"},
+	)
+	operationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "volume_operation_duration_seconds",
+			Help: "Duration in seconds of volume operations. Broken down by plugin and operation.",
+			// From 100ms up to about 7 minutes, as attaching a cloud disk or
+			// recycling a large volume easily takes minutes.
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 13),
+		},
+		[]string{"plugin", "operation"},
+	)
+)
+
+var registerOperationMetrics sync.Once
+
+// RegisterOperationMetrics registers the volume operation metrics with the
+// default prometheus registry.  Kubelets and controllers which measure
+// operations with MeasureOperation call it once at startup.
+func RegisterOperationMetrics() {
+	registerOperationMetrics.Do(func() {
+		prometheus.MustRegister(operationTotal)
+		prometheus.MustRegister(operationDuration)
+	})
+}
+
+// MeasureOperation runs fn, the operation op on a volume of plugin, and
+// counts it in volume_operation_total by its outcome and its duration in
+// volume_operation_duration_seconds.  It returns the error of fn.
+func MeasureOperation(plugin string, op OperationType, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	operationDuration.WithLabelValues(plugin, string(op)).Observe(time.Since(start).Seconds())
+	status := operationStatusSuccess
+	if err != nil {
+		status = operationStatusFailure
+	}
+	operationTotal.WithLabelValues(plugin, string(op), status).Inc()
+	return err
+}
+
+// InstrumentCleaner returns a Cleaner which measures the TearDown and
+// TearDownAt of c, a Cleaner of plugin, with MeasureOperation.  The result
+// implements none of the optional interfaces c may implement, so it is for
+// callers which only tear down.  Builders implement too many optional
+// interfaces to be wrapped; measure their SetUp with MeasureOperation.
+func InstrumentCleaner(plugin string, c Cleaner) Cleaner {
+	return &instrumentedCleaner{Cleaner: c, plugin: plugin}
+}
+
+type instrumentedCleaner struct {
+	Cleaner
+	plugin string
+}
+
+func (c *instrumentedCleaner) TearDown() error {
+	return MeasureOperation(c.plugin, OperationTearDown, c.Cleaner.TearDown)
+}
+
+func (c *instrumentedCleaner) TearDownAt(dir string) error {
+	return MeasureOperation(c.plugin, OperationTearDown, func() error { return c.Cleaner.TearDownAt(dir) })
+}
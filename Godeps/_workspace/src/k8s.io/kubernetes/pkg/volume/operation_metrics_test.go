@@ -0,0 +1,92 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	if err := c.Write(m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func sampleCount(t *testing.T, h prometheus.Histogram) uint64 {
+	m := &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMeasureOperation(t *testing.T) {
+	plugin := "kubernetes.io/measure-test"
+	failure := errors.New("failed")
+	succeeded := operationTotal.WithLabelValues(plugin, string(OperationDelete), operationStatusSuccess)
+	failed := operationTotal.WithLabelValues(plugin, string(OperationDelete), operationStatusFailure)
+	duration := operationDuration.WithLabelValues(plugin, string(OperationDelete)).(prometheus.Histogram)
+
+	if err := MeasureOperation(plugin, OperationDelete, func() error { return nil }); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := MeasureOperation(plugin, OperationDelete, func() error { return failure }); err != failure {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if err := MeasureOperation(plugin, OperationDelete, func() error { return failure }); err != failure {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+
+	if v := counterValue(t, succeeded); v != 1 {
+		t.Errorf("Expected 1 successful operation, got %v", v)
+	}
+	if v := counterValue(t, failed); v != 2 {
+		t.Errorf("Expected 2 failed operations, got %v", v)
+	}
+	if n := sampleCount(t, duration); n != 3 {
+		t.Errorf("Expected 3 observed durations, got %d", n)
+	}
+}
+
+func TestInstrumentCleaner(t *testing.T) {
+	plugin := "kubernetes.io/instrument-test"
+	succeeded := operationTotal.WithLabelValues(plugin, string(OperationTearDown), operationStatusSuccess)
+	tmpDir, err := ioutil.TempDir("", "operation_metrics_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	fake := &FakeVolume{}
+	cleaner := InstrumentCleaner(plugin, fake)
+	if err := cleaner.TearDownAt(tmpDir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fake.GetTearDownCallCount() != 1 {
+		t.Errorf("Expected TearDown to be called once, got %d", fake.GetTearDownCallCount())
+	}
+	if v := counterValue(t, succeeded); v != 1 {
+		t.Errorf("Expected 1 successful teardown, got %v", v)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// OperationQueue runs operations on a fixed number of workers.  Whenever a
+// worker becomes free it picks the highest priority operation queued,
+// oldest first among equal priorities, so that e.g. a SetUp a pod is waiting
+// on does not sit behind a backlog of recycling.  Operations that already
+// started are never interrupted.
+type OperationQueue struct {
+	registry *OperationRegistry
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	pending operationHeap
+	nextSeq uint64
+	stopped bool
+}
+
+// NewOperationQueue creates a queue which runs operations through registry
+// on the given number of workers.
+func NewOperationQueue(registry *OperationRegistry, workers int) *OperationQueue {
+	q := &OperationQueue{registry: registry}
+	q.cond = sync.NewCond(&q.lock)
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+// NewSharedOperationQueue creates a queue which runs operations through the
+// registry of the shared lifecycle helpers, so that they are drained by
+// ShutdownOperations.
+func NewSharedOperationQueue(workers int) *OperationQueue {
+	return NewOperationQueue(defaultOperationRegistry, workers)
+}
+
+// Enqueue queues op.  The returned channel receives the result of the
+// operation once it ran, or an ErrShuttingDown if the queue was stopped
+// before it could run.
+func (q *OperationQueue) Enqueue(op Operation) <-chan error {
+	result := make(chan error, 1)
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.stopped {
+		result <- &ErrShuttingDown{Type: op.Type, VolumePath: op.VolumePath}
+		return result
+	}
+	heap.Push(&q.pending, &queuedOperation{op: op, seq: q.nextSeq, result: result})
+	q.nextSeq++
+	q.cond.Signal()
+	return result
+}
+
+// Stop makes the workers exit once the operations they are running finish.
+// Operations still queued are not run; their result is an ErrShuttingDown.
+func (q *OperationQueue) Stop() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.stopped = true
+	for q.pending.Len() > 0 {
+		next := heap.Pop(&q.pending).(*queuedOperation)
+		next.result <- &ErrShuttingDown{Type: next.op.Type, VolumePath: next.op.VolumePath}
+	}
+	q.cond.Broadcast()
+}
+
+func (q *OperationQueue) worker() {
+	for {
+		q.lock.Lock()
+		for !q.stopped && q.pending.Len() == 0 {
+			q.cond.Wait()
+		}
+		if q.stopped {
+			q.lock.Unlock()
+			return
+		}
+		next := heap.Pop(&q.pending).(*queuedOperation)
+		q.lock.Unlock()
+
+		next.result <- q.registry.RunOperation(next.op)
+	}
+}
+
+type queuedOperation struct {
+	op     Operation
+	seq    uint64
+	result chan error
+}
+
+// operationHeap implements heap.Interface, with the highest priority and then
+// lowest sequence number at the top.
+type operationHeap []*queuedOperation
+
+func (h operationHeap) Len() int { return len(h) }
+
+func (h operationHeap) Less(i, j int) bool {
+	if h[i].op.Priority != h[j].op.Priority {
+		return h[i].op.Priority > h[j].op.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h operationHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *operationHeap) Push(x interface{}) {
+	*h = append(*h, x.(*queuedOperation))
+}
+
+func (h *operationHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
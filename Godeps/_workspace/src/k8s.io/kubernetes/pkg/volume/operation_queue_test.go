@@ -0,0 +1,113 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+func TestOperationQueuePriority(t *testing.T) {
+	registry := NewOperationRegistry(util.RealClock{})
+	q := NewOperationQueue(registry, 1)
+	defer q.Stop()
+
+	var lock sync.Mutex
+	order := []string{}
+	record := func(name string) func() error {
+		return func() error {
+			lock.Lock()
+			defer lock.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	// Occupy the only worker so that everything else queues up.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocker := q.Enqueue(Operation{
+		Type:       OperationRecycle,
+		VolumePath: "/blocker",
+		Priority:   DefaultPriority(OperationRecycle),
+		Func: func() error {
+			close(started)
+			<-release
+			return record("blocker")()
+		},
+	})
+	<-started
+
+	results := []<-chan error{blocker}
+	for _, name := range []string{"gc-1", "gc-2", "gc-3"} {
+		results = append(results, q.Enqueue(Operation{Type: OperationDelete, VolumePath: name, Priority: DefaultPriority(OperationDelete), Func: record(name)}))
+	}
+	results = append(results, q.Enqueue(Operation{Type: OperationSetUp, VolumePath: "setup", Priority: DefaultPriority(OperationSetUp), Func: record("setup")}))
+
+	if ops := registry.InProgressOperations(); len(ops) != 1 || ops[0].VolumePath != "/blocker" {
+		t.Errorf("Expected only the in-flight operation to be running, got %+v", ops)
+	}
+
+	close(release)
+	for _, result := range results {
+		if err := <-result; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"blocker", "setup", "gc-1", "gc-2", "gc-3"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+}
+
+func TestOperationQueueStop(t *testing.T) {
+	q := NewOperationQueue(NewOperationRegistry(util.RealClock{}), 1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	running := q.Enqueue(Operation{
+		Type:       OperationSetUp,
+		VolumePath: "/running",
+		Func: func() error {
+			close(started)
+			<-release
+			return nil
+		},
+	})
+	<-started
+	pending := q.Enqueue(Operation{Type: OperationSetUp, VolumePath: "/pending", Func: func() error {
+		t.Errorf("Expected the queued operation not to run after Stop")
+		return nil
+	}})
+
+	q.Stop()
+	if _, ok := (<-pending).(*ErrShuttingDown); !ok {
+		t.Errorf("Expected the queued operation to fail with ErrShuttingDown")
+	}
+	if _, ok := (<-q.Enqueue(Operation{Type: OperationTearDown, VolumePath: "/late"})).(*ErrShuttingDown); !ok {
+		t.Errorf("Expected an operation enqueued after Stop to fail with ErrShuttingDown")
+	}
+
+	close(release)
+	if err := <-running; err != nil {
+		t.Errorf("Expected the running operation to finish, got %v", err)
+	}
+}
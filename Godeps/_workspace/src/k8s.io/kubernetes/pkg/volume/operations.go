@@ -0,0 +1,392 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// OperationType identifies the kind of work a volume operation performs.
+type OperationType string
+
+const (
+	OperationSetUp     OperationType = "setup"
+	OperationTearDown  OperationType = "teardown"
+	OperationRecycle   OperationType = "recycle"
+	OperationDelete    OperationType = "delete"
+	OperationProvision OperationType = "provision"
+	OperationAttach    OperationType = "attach"
+	OperationDetach    OperationType = "detach"
+)
+
+// OperationPriority orders queued operations; higher priorities run first.
+type OperationPriority int
+
+const (
+	// PriorityBackground is for bulk work that no pod is waiting on, such
+	// as recycling or deleting released volumes.
+	PriorityBackground OperationPriority = 0
+	// PriorityNormal is the default priority.
+	PriorityNormal OperationPriority = 50
+	// PriorityCritical is for work that blocks a pod from starting.
+	PriorityCritical OperationPriority = 100
+)
+
+// DefaultPriority returns the priority operations of the given type run at
+// unless they ask for another one.
+func DefaultPriority(opType OperationType) OperationPriority {
+	switch opType {
+	case OperationSetUp, OperationAttach:
+		return PriorityCritical
+	case OperationRecycle, OperationDelete:
+		return PriorityBackground
+	default:
+		return PriorityNormal
+	}
+}
+
+// Operation is a unit of volume work.
+type Operation struct {
+	Type OperationType
+	// VolumePath identifies the volume, see OperationInfo.VolumePath.
+	VolumePath string
+	// PodUID is the pod the operation is for, if any.  Operations on the
+	// volume directories of pods are attributed to the pod of the directory
+	// without it.
+	PodUID types.UID
+	// VolumeName, if set, keeps the operation from running at the same time
+	// as the other operations for the volume and PodUID, and backs it off
+	// after failures; see pendingOperations.
+	VolumeName string
+	Priority   OperationPriority
+	// Func performs the operation.
+	Func func() error
+	// Cancel, if set, is called to abort Func when the registry shuts down
+	// before Func returned.
+	Cancel func()
+}
+
+// OperationInfo describes a volume operation that is currently running.
+type OperationInfo struct {
+	// Type is the kind of operation.
+	Type OperationType
+	// VolumePath identifies the volume the operation acts on.  This is the
+	// result of GetPath() for the volume, or the PV name for operations
+	// that do not have a path yet (e.g. provisioning).
+	VolumePath string
+	// Priority is the priority the operation was run with.
+	Priority OperationPriority
+	// StartTime is when the operation was registered.
+	StartTime time.Time
+	// Elapsed is how long the operation has been running.
+	Elapsed time.Duration
+}
+
+// OperationRegistry keeps track of the volume operations in flight.  It is
+// safe for concurrent use.
+type OperationRegistry struct {
+	mutex      sync.Mutex
+	clock      util.Clock
+	nextID     uint64
+	operations map[uint64]*registeredOperation
+	// shuttingDown is set once Shutdown is called; drained is closed when
+	// the last operation finishes after that.
+	shuttingDown bool
+	drained      chan struct{}
+	// audit, if set, records every operation once it returned.
+	audit AuditSink
+	// pending serializes the operations that have a VolumeName.
+	pending *pendingOperations
+}
+
+type registeredOperation struct {
+	info   OperationInfo
+	cancel func()
+}
+
+// NewOperationRegistry creates an empty OperationRegistry which uses the
+// given clock to timestamp operations.
+func NewOperationRegistry(clock util.Clock) *OperationRegistry {
+	return &OperationRegistry{
+		clock:      clock,
+		operations: map[uint64]*registeredOperation{},
+		drained:    make(chan struct{}),
+		pending:    newPendingOperations(clock),
+	}
+}
+
+// defaultOperationRegistry is the registry used by the shared lifecycle
+// helpers below.
+var defaultOperationRegistry = NewOperationRegistry(util.RealClock{})
+
+// InProgressOperations returns the operations currently running through the
+// shared lifecycle helpers, oldest first.
+func InProgressOperations() []OperationInfo {
+	return defaultOperationRegistry.InProgressOperations()
+}
+
+// ShutdownOperations shuts down the registry used by the shared lifecycle
+// helpers.  See OperationRegistry.Shutdown.
+func ShutdownOperations(ctx context.Context) error {
+	return defaultOperationRegistry.Shutdown(ctx)
+}
+
+// ErrShuttingDown is returned for operations started after Shutdown was
+// called.
+type ErrShuttingDown struct {
+	Type       OperationType
+	VolumePath string
+}
+
+func (e *ErrShuttingDown) Error() string {
+	return fmt.Sprintf("not starting %s of %s: volume operations are shutting down", e.Type, e.VolumePath)
+}
+
+// ErrShutdownIncomplete is returned by Shutdown when operations were still
+// running when its context expired.
+type ErrShutdownIncomplete struct {
+	// Unfinished lists the operations that had not finished.
+	Unfinished []OperationInfo
+}
+
+func (e *ErrShutdownIncomplete) Error() string {
+	descs := make([]string, 0, len(e.Unfinished))
+	for _, op := range e.Unfinished {
+		descs = append(descs, fmt.Sprintf("%s of %s (running for %s)", op.Type, op.VolumePath, op.Elapsed))
+	}
+	return fmt.Sprintf("%d volume operations did not finish before shutdown: %s", len(descs), strings.Join(descs, ", "))
+}
+
+// Run registers an operation, calls fn and removes the operation once fn
+// returns.  The error returned by fn is passed through unchanged.
+func (r *OperationRegistry) Run(opType OperationType, volumePath string, fn func() error) error {
+	return r.RunOperation(Operation{
+		Type:       opType,
+		VolumePath: volumePath,
+		Priority:   DefaultPriority(opType),
+		Func:       fn,
+	})
+}
+
+// RunOperation is like Run, but takes a fully specified Operation.  An
+// operation with a VolumeName that conflicts with one in flight or is
+// backing off is not run, and an ErrOperationPending or ErrOperationBackoff
+// is returned instead.
+func (r *OperationRegistry) RunOperation(op Operation) (err error) {
+	// Register first, so that an operation rejected at shutdown does not
+	// count as a failure and back off the next one.
+	id, err := r.register(op)
+	if err != nil {
+		return err
+	}
+	defer r.deregister(id)
+
+	if op.VolumeName != "" {
+		if err := r.pending.start(op.VolumeName, op.PodUID); err != nil {
+			return err
+		}
+		defer func() { r.pending.finish(op.VolumeName, op.PodUID, err) }()
+	}
+
+	sink := r.auditSink()
+	if sink == nil {
+		return op.Func()
+	}
+	start := r.clock.Now()
+	err = op.Func()
+	if auditErr := sink.Record(newAuditRecord(op, start, r.clock.Since(start), err)); auditErr != nil {
+		glog.Errorf("Failed to audit the %s of %s: %v", op.Type, op.VolumePath, auditErr)
+	}
+	return err
+}
+
+// SetAuditSink makes the registry record every operation that runs through
+// it to sink once it returned.  A nil sink turns auditing off.
+func (r *OperationRegistry) SetAuditSink(sink AuditSink) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.audit = sink
+}
+
+func (r *OperationRegistry) auditSink() AuditSink {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.audit
+}
+
+func (r *OperationRegistry) register(op Operation) (uint64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.shuttingDown {
+		return 0, &ErrShuttingDown{Type: op.Type, VolumePath: op.VolumePath}
+	}
+	id := r.nextID
+	r.nextID++
+	r.operations[id] = &registeredOperation{
+		info: OperationInfo{
+			Type:       op.Type,
+			VolumePath: op.VolumePath,
+			Priority:   op.Priority,
+			StartTime:  r.clock.Now(),
+		},
+		cancel: op.Cancel,
+	}
+	return id, nil
+}
+
+func (r *OperationRegistry) deregister(id uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.operations, id)
+	if r.shuttingDown && len(r.operations) == 0 {
+		r.closeDrained()
+	}
+}
+
+// closeDrained must be called with the mutex held.
+func (r *OperationRegistry) closeDrained() {
+	select {
+	case <-r.drained:
+	default:
+		close(r.drained)
+	}
+}
+
+// InProgressOperations returns a snapshot of the registered operations,
+// oldest first, with Elapsed computed against the registry's clock.
+func (r *OperationRegistry) InProgressOperations() []OperationInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.snapshot()
+}
+
+// IsOperationPending reports whether an operation that would conflict with
+// one for volumeName and podUID is running.
+func (r *OperationRegistry) IsOperationPending(volumeName string, podUID types.UID) bool {
+	return r.pending.isPending(volumeName, podUID)
+}
+
+// snapshot must be called with the mutex held.
+func (r *OperationRegistry) snapshot() []OperationInfo {
+	ids := make([]uint64, 0, len(r.operations))
+	for id := range r.operations {
+		ids = append(ids, id)
+	}
+	sort.Sort(uint64Slice(ids))
+
+	infos := make([]OperationInfo, 0, len(ids))
+	for _, id := range ids {
+		info := r.operations[id].info
+		info.Elapsed = r.clock.Since(info.StartTime)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Shutdown stops the registry from accepting new operations and waits for
+// the ones in flight to finish.  If ctx is done first, the Cancel hook of
+// every unfinished operation is called and an ErrShutdownIncomplete listing
+// them is returned.
+func (r *OperationRegistry) Shutdown(ctx context.Context) error {
+	r.mutex.Lock()
+	r.shuttingDown = true
+	if len(r.operations) == 0 {
+		r.closeDrained()
+	}
+	r.mutex.Unlock()
+
+	select {
+	case <-r.drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	r.mutex.Lock()
+	unfinished := r.snapshot()
+	cancels := []func(){}
+	for _, op := range r.operations {
+		if op.cancel != nil {
+			cancels = append(cancels, op.cancel)
+		}
+	}
+	r.mutex.Unlock()
+
+	if len(unfinished) == 0 {
+		return nil
+	}
+	// The hooks are called without holding the mutex, since the operations
+	// they abort deregister themselves.
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return &ErrShutdownIncomplete{Unfinished: unfinished}
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// RunOperation runs op through the registry used by the shared lifecycle
+// helpers, for callers that need more than the helpers below take, e.g. the
+// pod the operation is for.
+func RunOperation(op Operation) error {
+	return defaultOperationRegistry.RunOperation(op)
+}
+
+// SetUpVolume calls builder.SetUp(), tracking it as an in-progress operation.
+func SetUpVolume(builder Builder) error {
+	return defaultOperationRegistry.Run(OperationSetUp, builder.GetPath(), builder.SetUp)
+}
+
+// TearDownVolume calls cleaner.TearDown(), tracking it as an in-progress operation.
+func TearDownVolume(cleaner Cleaner) error {
+	return defaultOperationRegistry.Run(OperationTearDown, cleaner.GetPath(), cleaner.TearDown)
+}
+
+// RecycleVolume calls recycler.Recycle(), tracking it as an in-progress operation.
+func RecycleVolume(recycler Recycler) error {
+	return defaultOperationRegistry.Run(OperationRecycle, recycler.GetPath(), recycler.Recycle)
+}
+
+// DeleteVolume calls deleter.Delete(), tracking it as an in-progress operation.
+func DeleteVolume(deleter Deleter) error {
+	return defaultOperationRegistry.Run(OperationDelete, deleter.GetPath(), deleter.Delete)
+}
+
+// ProvisionVolume calls provisioner.Provision(pv), tracking it as an
+// in-progress operation keyed by the PV name.
+func ProvisionVolume(provisioner Provisioner, pv *api.PersistentVolume) error {
+	return defaultOperationRegistry.Run(OperationProvision, pv.Name, func() error {
+		return provisioner.Provision(pv)
+	})
+}
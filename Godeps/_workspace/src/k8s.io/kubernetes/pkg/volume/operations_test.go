@@ -0,0 +1,224 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+func TestOperationRegistryInProgressOperations(t *testing.T) {
+	clock := &util.FakeClock{Time: time.Now()}
+	registry := NewOperationRegistry(clock)
+
+	const numOps = 5
+	release := make([]chan struct{}, numOps)
+	var wg sync.WaitGroup
+	for i := 0; i < numOps; i++ {
+		release[i] = make(chan struct{})
+		<-runAsync(&wg, registry, fmt.Sprintf("/vol/%d", i), release[i])
+		clock.Step(time.Second)
+	}
+
+	ops := registry.InProgressOperations()
+	if len(ops) != numOps {
+		t.Fatalf("Expected %d operations, got %d: %+v", numOps, len(ops), ops)
+	}
+	for i, op := range ops {
+		if op.Type != OperationSetUp {
+			t.Errorf("Expected type %q, got %q", OperationSetUp, op.Type)
+		}
+		if expected := fmt.Sprintf("/vol/%d", i); op.VolumePath != expected {
+			t.Errorf("Expected volume %q, got %q", expected, op.VolumePath)
+		}
+		if expected := time.Duration(numOps-i) * time.Second; op.Elapsed != expected {
+			t.Errorf("Expected %s elapsed for %s, got %s", expected, op.VolumePath, op.Elapsed)
+		}
+	}
+
+	// Complete every other operation and make sure only those disappear.
+	for i := 0; i < numOps; i += 2 {
+		close(release[i])
+	}
+	waitForOperationCount(t, registry, numOps/2)
+	for _, op := range registry.InProgressOperations() {
+		var i int
+		fmt.Sscanf(op.VolumePath, "/vol/%d", &i)
+		if i%2 == 0 {
+			t.Errorf("Completed operation %s still listed", op.VolumePath)
+		}
+	}
+
+	for i := 1; i < numOps; i += 2 {
+		close(release[i])
+	}
+	wg.Wait()
+	if ops := registry.InProgressOperations(); len(ops) != 0 {
+		t.Errorf("Expected no operations after completion, got %+v", ops)
+	}
+}
+
+func TestOperationRegistryRemovesFailedOperations(t *testing.T) {
+	registry := NewOperationRegistry(util.RealClock{})
+	err := registry.Run(OperationTearDown, "/vol/failed", func() error {
+		if ops := registry.InProgressOperations(); len(ops) != 1 {
+			t.Errorf("Expected 1 operation while running, got %+v", ops)
+		}
+		return fmt.Errorf("teardown failed")
+	})
+	if err == nil || err.Error() != "teardown failed" {
+		t.Errorf("Expected error from the operation to be returned, got %v", err)
+	}
+	if ops := registry.InProgressOperations(); len(ops) != 0 {
+		t.Errorf("Expected failed operation to be removed, got %+v", ops)
+	}
+}
+
+// runAsync starts a SetUp operation that blocks until release is closed.  The
+// returned channel is signalled once the operation is registered.
+func runAsync(wg *sync.WaitGroup, registry *OperationRegistry, path string, release chan struct{}) chan struct{} {
+	ready := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		registry.Run(OperationSetUp, path, func() error {
+			close(ready)
+			<-release
+			return nil
+		})
+	}()
+	return ready
+}
+
+func waitForOperationCount(t *testing.T, registry *OperationRegistry, count int) {
+	for i := 0; i < 100; i++ {
+		if len(registry.InProgressOperations()) == count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d operations, have %+v", count, registry.InProgressOperations())
+}
+
+func TestOperationRegistryShutdownWaits(t *testing.T) {
+	registry := NewOperationRegistry(util.RealClock{})
+
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	<-runAsync(&wg, registry, "/vol/slow", release)
+
+	shutdownDone := make(chan error)
+	go func() {
+		shutdownDone <- registry.Shutdown(context.Background())
+	}()
+
+	// New work is rejected as soon as shutdown begins.
+	waitForShutdown(t, registry)
+	err := registry.Run(OperationSetUp, "/vol/new", func() error {
+		t.Errorf("Operation started after shutdown")
+		return nil
+	})
+	if rejected, ok := err.(*ErrShuttingDown); !ok || rejected.VolumePath != "/vol/new" {
+		t.Errorf("Expected ErrShuttingDown for /vol/new, got %v", err)
+	}
+
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("Shutdown returned before the in-flight operation finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+	wg.Wait()
+}
+
+func TestOperationRegistryShutdownDeadline(t *testing.T) {
+	registry := NewOperationRegistry(util.RealClock{})
+
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+	started := make(chan struct{})
+	go registry.RunOperation(Operation{
+		Type:       OperationTearDown,
+		VolumePath: "/vol/hung",
+		Func: func() error {
+			close(started)
+			<-release
+			return nil
+		},
+		Cancel: func() {
+			close(cancelled)
+			close(release)
+		},
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := registry.Shutdown(ctx)
+	incomplete, ok := err.(*ErrShutdownIncomplete)
+	if !ok {
+		t.Fatalf("Expected ErrShutdownIncomplete, got %v", err)
+	}
+	if len(incomplete.Unfinished) != 1 || incomplete.Unfinished[0].VolumePath != "/vol/hung" {
+		t.Errorf("Expected /vol/hung to be reported unfinished, got %+v", incomplete.Unfinished)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the unfinished operation to be cancelled")
+	}
+}
+
+func waitForShutdown(t *testing.T, registry *OperationRegistry) {
+	for i := 0; i < 100; i++ {
+		registry.mutex.Lock()
+		shuttingDown := registry.shuttingDown
+		registry.mutex.Unlock()
+		if shuttingDown {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for shutdown to begin")
+}
+
+func TestOperationRegistryShutdownDoesNotBackOff(t *testing.T) {
+	registry := NewOperationRegistry(util.RealClock{})
+	if err := registry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	err := registry.RunOperation(Operation{Type: OperationSetUp, VolumePath: "/vol", VolumeName: "vol", Func: func() error {
+		t.Errorf("Operation started after shutdown")
+		return nil
+	}})
+	if _, ok := err.(*ErrShuttingDown); !ok {
+		t.Fatalf("Expected ErrShuttingDown, got %v", err)
+	}
+	if err := registry.pending.start("vol", ""); err != nil {
+		t.Errorf("Expected the rejected operation not to back off the volume, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// CleanupOrphanedPodDirs tears down the volumes left in the directories of
+// pods which are not in activePods, e.g. because the pods were deleted while
+// the kubelet was down.  podVolumeRoot holds a directory per pod, named after
+// its UID, whose volumes are in volumes/<escaped plugin name>/<volume name>.
+// Each volume is torn down by the Cleaner of its plugin, and a pod directory
+// is only removed once no volume and no mount is left beneath it.
+//
+// The directory of a volume whose plugin is unknown is only removed if it is
+// not a mount point and empty, and a directory left by a failed teardown
+// only if it is empty as well, so that the data of a volume is never deleted
+// without its plugin.  Volumes which can't be cleaned up are kept and make
+// up the returned error, but don't keep the other pods from being cleaned up.
+func CleanupOrphanedPodDirs(activePods []types.UID, podVolumeRoot string, pluginMgr *VolumePluginMgr) error {
+	var mounter mount.Interface
+	if pluginMgr.host != nil {
+		mounter = pluginMgr.host.GetMounter()
+	}
+	if mounter == nil {
+		// Without the mounter of the host, e.g. before any plugin was
+		// initialized, the mount table must still be checked before
+		// anything is deleted.
+		mounter = mount.New()
+	}
+
+	active := map[types.UID]bool{}
+	for _, uid := range activePods {
+		active[uid] = true
+	}
+	podDirs, err := ioutil.ReadDir(podVolumeRoot)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, podDir := range podDirs {
+		uid := types.UID(podDir.Name())
+		if !podDir.IsDir() || active[uid] {
+			continue
+		}
+		if err := cleanupOrphanedPodDir(uid, path.Join(podVolumeRoot, podDir.Name()), pluginMgr, mounter); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clean up orphaned pod %s: %v", uid, err))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+func cleanupOrphanedPodDir(podUID types.UID, podDir string, pluginMgr *VolumePluginMgr, mounter mount.Interface) error {
+	volumesDir := path.Join(podDir, "volumes")
+	pluginDirs, err := ioutil.ReadDir(volumesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	errs := []error{}
+	for _, pluginDir := range pluginDirs {
+		if !pluginDir.IsDir() {
+			continue
+		}
+		pluginPath := path.Join(volumesDir, pluginDir.Name())
+		volumeDirs, err := ioutil.ReadDir(pluginPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, volumeDir := range volumeDirs {
+			if !volumeDir.IsDir() || isPoisonMarker(volumeDir.Name()) {
+				continue
+			}
+			volumePath := path.Join(pluginPath, volumeDir.Name())
+			if err := cleanupOrphanedVolume(podUID, pluginDir.Name(), volumeDir.Name(), volumePath, pluginMgr, mounter); err != nil {
+				errs = append(errs, fmt.Errorf("volume %s: %v", volumePath, err))
+			}
+		}
+		// The plugin directory is left if any of its volumes is.
+		os.Remove(pluginPath)
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	if err := os.Remove(volumesDir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("volumes are left in %s: %v", volumesDir, err)
+	}
+	// Removing a directory with a mount beneath it would delete the data of
+	// the mounted volume.
+	if err := verifyNoMounts(mounter, podDir); err != nil {
+		return err
+	}
+	return os.RemoveAll(podDir)
+}
+
+func cleanupOrphanedVolume(podUID types.UID, escapedPluginName, volName, dir string, pluginMgr *VolumePluginMgr, mounter mount.Interface) error {
+	notMnt, err := IsNotMountPoint(dir, mounter)
+	if err != nil {
+		return err
+	}
+
+	plugin, err := pluginMgr.FindPluginByName(util.UnescapeQualifiedNameForDisk(escapedPluginName))
+	if err != nil {
+		// Without its plugin nothing knows how to unmount the volume or
+		// whether its content may go.
+		if notMnt {
+			return removeEmptyDir(dir)
+		}
+		return fmt.Errorf("volume is mounted, but its plugin is unknown: %v", err)
+	}
+	cleaner, err := plugin.NewCleaner(volName, podUID)
+	if err != nil {
+		return err
+	}
+	if cleaner.GetPath() != dir {
+		return fmt.Errorf("the cleaner of plugin %s is at %s", plugin.Name(), cleaner.GetPath())
+	}
+
+	glog.Infof("Tearing down orphaned volume %s", dir)
+	err = defaultOperationRegistry.RunOperation(Operation{
+		Type:       OperationTearDown,
+		VolumePath: dir,
+		PodUID:     podUID,
+		VolumeName: dir,
+		Priority:   DefaultPriority(OperationTearDown),
+		Func:       func() error { return SafeTearDownAt(cleaner, dir) },
+	})
+	if err != nil {
+		// A volume whose unmount succeeded before the pod directory was
+		// left behind may fail to tear down again, e.g. because it can't be
+		// detached twice; an empty directory is all that is left of it.
+		if notMnt && removeEmptyDir(dir) == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// removeEmptyDir removes dir only if it is empty.  A missing dir is not an
+// error.
+func removeEmptyDir(dir string) error {
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
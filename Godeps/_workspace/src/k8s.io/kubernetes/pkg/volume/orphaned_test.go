@@ -0,0 +1,196 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+func newOrphanedTestMgr(t *testing.T) (string, *VolumePluginMgr, *FakeVolumePlugin, *mount.FakeMounter) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "orphanedTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	host := NewFakeVolumeHost(tmpDir, nil, nil)
+	mounter := &mount.FakeMounter{}
+	host.mounter = mounter
+	plugin := &FakeVolumePlugin{PluginName: "kubernetes.io/fake"}
+	mgr := &VolumePluginMgr{}
+	if err := mgr.InitPlugins([]VolumePlugin{plugin}, host); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return tmpDir, mgr, plugin, mounter
+}
+
+func makeVolumeDir(t *testing.T, rootDir, podUID, escapedPluginName, volName string, files ...string) string {
+	dir := path.Join(rootDir, "pods", podUID, "volumes", escapedPluginName, volName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("can't make the volume dir: %v", err)
+	}
+	for _, f := range files {
+		if err := ioutil.WriteFile(path.Join(dir, f), []byte("data"), 0640); err != nil {
+			t.Fatalf("can't write %s: %v", f, err)
+		}
+	}
+	return dir
+}
+
+func TestCleanupOrphanedPodDirs(t *testing.T) {
+	tmpDir, mgr, plugin, _ := newOrphanedTestMgr(t)
+	defer os.RemoveAll(tmpDir)
+
+	activeDir := makeVolumeDir(t, tmpDir, "active", "kubernetes.io~fake", "vol1", "file")
+	makeVolumeDir(t, tmpDir, "orphaned", "kubernetes.io~fake", "vol1", "file")
+	makeVolumeDir(t, tmpDir, "orphaned", "kubernetes.io~fake", "vol2")
+	makeVolumeDir(t, tmpDir, "orphaned", "kubernetes.io~unknown", "vol3")
+
+	if err := CleanupOrphanedPodDirs([]types.UID{"active"}, path.Join(tmpDir, "pods"), mgr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plugin.Cleaners) != 2 {
+		t.Fatalf("Expected 2 cleaners, got %d", len(plugin.Cleaners))
+	}
+	for _, cleaner := range plugin.Cleaners {
+		if cleaner.PodUID != "orphaned" || cleaner.GetTearDownCallCount() != 1 {
+			t.Errorf("Expected one teardown of the volume %s of the orphaned pod, got %d of pod %s", cleaner.VolName, cleaner.GetTearDownCallCount(), cleaner.PodUID)
+		}
+	}
+	if _, err := os.Stat(path.Join(tmpDir, "pods/orphaned")); !os.IsNotExist(err) {
+		t.Errorf("Expected the orphaned pod dir to be removed, got: %v", err)
+	}
+	if _, err := os.Stat(path.Join(activeDir, "file")); err != nil {
+		t.Errorf("Expected the volume of the active pod to be kept: %v", err)
+	}
+}
+
+func TestCleanupOrphanedPodDirsKeepsData(t *testing.T) {
+	tests := []struct {
+		name        string
+		plugin      string
+		mounted     bool
+		files       []string
+		tearDownErr error
+		removed     bool
+	}{
+		{
+			name:    "unknown plugin, empty dir",
+			plugin:  "kubernetes.io~unknown",
+			removed: true,
+		},
+		{
+			name:   "unknown plugin, data",
+			plugin: "kubernetes.io~unknown",
+			files:  []string{"file"},
+		},
+		{
+			name:    "unknown plugin, mounted",
+			plugin:  "kubernetes.io~unknown",
+			mounted: true,
+		},
+		{
+			name:        "teardown failed, empty dir",
+			plugin:      "kubernetes.io~fake",
+			tearDownErr: fmt.Errorf("detach failed"),
+			removed:     true,
+		},
+		{
+			name:        "teardown failed, data",
+			plugin:      "kubernetes.io~fake",
+			files:       []string{"file"},
+			tearDownErr: fmt.Errorf("detach failed"),
+		},
+		{
+			name:        "teardown failed, mounted",
+			plugin:      "kubernetes.io~fake",
+			mounted:     true,
+			tearDownErr: fmt.Errorf("unmount failed"),
+		},
+	}
+	for _, test := range tests {
+		tmpDir, mgr, plugin, mounter := newOrphanedTestMgr(t)
+		plugin.TearDownErr = test.tearDownErr
+		dir := makeVolumeDir(t, tmpDir, "orphaned", test.plugin, "vol", test.files...)
+		if test.mounted {
+			mounter.MountPoints = []mount.MountPoint{{Device: "/dev/sdb", Path: dir}}
+		}
+
+		err := CleanupOrphanedPodDirs(nil, path.Join(tmpDir, "pods"), mgr)
+		_, statErr := os.Stat(path.Join(tmpDir, "pods/orphaned"))
+		if test.removed {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			if !os.IsNotExist(statErr) {
+				t.Errorf("%s: expected the pod dir to be removed, got: %v", test.name, statErr)
+			}
+		} else {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			if _, err := os.Stat(dir); err != nil {
+				t.Errorf("%s: expected the volume dir to be kept: %v", test.name, err)
+			}
+			for _, f := range test.files {
+				if _, err := os.Stat(path.Join(dir, f)); err != nil {
+					t.Errorf("%s: expected %s to be kept: %v", test.name, f, err)
+				}
+			}
+		}
+		os.RemoveAll(tmpDir)
+	}
+}
+
+func TestCleanupOrphanedPodDirsMissingRoot(t *testing.T) {
+	tmpDir, mgr, _, _ := newOrphanedTestMgr(t)
+	defer os.RemoveAll(tmpDir)
+
+	if err := CleanupOrphanedPodDirs(nil, path.Join(tmpDir, "pods"), mgr); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCleanupOrphanedPodDirsWithoutPlugins(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "orphanedTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	podsDir := path.Join(tmpDir, "pods")
+	if err := os.MkdirAll(path.Join(podsDir, "orphaned"), 0750); err != nil {
+		t.Fatalf("can't make the pod dir: %v", err)
+	}
+	makeVolumeDir(t, tmpDir, "withvolume", "kubernetes.io~fake", "vol1", "data")
+
+	// A pod directory without volumes needs no plugin to be removed, one
+	// with the data of a volume is kept.
+	if err := CleanupOrphanedPodDirs(nil, podsDir, &VolumePluginMgr{}); err == nil {
+		t.Errorf("Expected an error for the volume without a plugin")
+	}
+	if _, err := os.Stat(path.Join(podsDir, "orphaned")); !os.IsNotExist(err) {
+		t.Errorf("Expected the orphaned pod directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(path.Join(podsDir, "withvolume", "volumes", "kubernetes.io~fake", "vol1", "data")); err != nil {
+		t.Errorf("Expected the data of the volume to be kept, got %v", err)
+	}
+}
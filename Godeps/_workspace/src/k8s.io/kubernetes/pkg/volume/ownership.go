@@ -0,0 +1,245 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// ownershipMarkerName is the file in the root of a volume recording the
+	// FSGroup last applied to it, and what it replaced.
+	ownershipMarkerName = ".kubernetes-fsgroup"
+	// managedOwnershipBitmask is ORed with the mode of every file of a
+	// volume whose ownership is managed.
+	managedOwnershipBitmask = os.FileMode(0660)
+	// ownershipModeMask is the part of a file's mode that is recorded so it
+	// can be restored.
+	ownershipModeMask = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+)
+
+// ownershipMarker is the content of the ownership marker.
+type ownershipMarker struct {
+	FSGroup int64 `json:"fsGroup"`
+	// Original holds the group and mode every entry had before an FSGroup
+	// was first applied, keyed by path relative to the volume root.
+	Original map[string]originalOwnership `json:"original,omitempty"`
+}
+
+type originalOwnership struct {
+	GID  int         `json:"gid"`
+	Mode os.FileMode `json:"mode"`
+}
+
+// SetVolumeOwnership makes every entry of the builder's volume owned by the
+// group fsGroup, sets the setgid bit so that new files inherit the group,
+// and ORs in rw-rw---- permissions.  Owners are left alone and symlinks are
+// not followed.  It does nothing if fsGroup is nil, or if the builder's
+// policy is FSGroupChangeOnRootMismatch and the root of the volume already
+// matches.  On Windows, where files have no group, the users of the node
+// are granted modify access to the volume through its ACL instead.
+func SetVolumeOwnership(builder Builder, fsGroup *int64) error {
+	if fsGroup == nil {
+		return nil
+	}
+	path := builder.GetPath()
+	if aclOwnership {
+		return grantVolumeAccess(path)
+	}
+	if builder.GetAttributes().FSGroupChangePolicy == FSGroupChangeOnRootMismatch {
+		matches, err := rootOwnershipMatches(path, *fsGroup)
+		if err != nil {
+			return err
+		}
+		if matches {
+			glog.V(4).Infof("Root of volume %s is already owned by FSGroup %d, not changing ownership", path, *fsGroup)
+			return nil
+		}
+	}
+	return applyFSGroup(path, *fsGroup, nil)
+}
+
+// ReconcileFSGroup makes the volume at path owned by desiredFSGroup.  The
+// FSGroup recorded by the previous reconcile is compared first, so that a
+// pod restarting with an unchanged FSGroup does not walk the volume again.
+// If the root of the volume already has the desired group and mode, the
+// walk is skipped as well.  A nil desiredFSGroup reverts the ownership to
+// what it was before any FSGroup was applied.
+func ReconcileFSGroup(path string, desiredFSGroup *int64) error {
+	if desiredFSGroup == nil {
+		return RevertOwnership(path)
+	}
+	if aclOwnership {
+		return grantVolumeAccess(path)
+	}
+	fsGroup := *desiredFSGroup
+	marker, err := readOwnershipMarker(path)
+	if err != nil {
+		return err
+	}
+	if marker != nil && marker.FSGroup == fsGroup {
+		glog.V(4).Infof("FSGroup %d of volume %s is unchanged", fsGroup, path)
+		return nil
+	}
+	if marker == nil {
+		marker = &ownershipMarker{Original: map[string]originalOwnership{}}
+	} else if marker.Original == nil {
+		marker.Original = map[string]originalOwnership{}
+	}
+
+	matches, err := rootOwnershipMatches(path, fsGroup)
+	if err != nil {
+		return err
+	}
+	if matches {
+		glog.V(4).Infof("Root of volume %s is already owned by FSGroup %d, not changing ownership", path, fsGroup)
+	} else if err := applyFSGroup(path, fsGroup, marker.Original); err != nil {
+		return err
+	}
+	marker.FSGroup = fsGroup
+	return writeOwnershipMarker(path, marker)
+}
+
+// RevertOwnership restores the group and mode every entry of the volume at
+// path had before ReconcileFSGroup first changed them, and removes the
+// ownership marker.  Entries which have since been removed are skipped.  It
+// does nothing if ReconcileFSGroup was never called on the volume.
+func RevertOwnership(path string) error {
+	if aclOwnership {
+		return revokeVolumeAccess(path)
+	}
+	marker, err := readOwnershipMarker(path)
+	if err != nil || marker == nil {
+		return err
+	}
+	for rel, orig := range marker.Original {
+		file := filepath.Join(path, rel)
+		info, err := os.Lstat(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := os.Lchown(file, -1, orig.GID); err != nil {
+			return fmt.Errorf("failed to restore group of %s: %v", file, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if err := os.Chmod(file, orig.Mode); err != nil {
+			return fmt.Errorf("failed to restore mode of %s: %v", file, err)
+		}
+	}
+	return os.Remove(filepath.Join(path, ownershipMarkerName))
+}
+
+// rootOwnershipMatches reports whether the root of the volume already has
+// the group and mode bits applyFSGroup would give it.
+func rootOwnershipMatches(path string, fsGroup int64) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	gid, err := fileGID(info)
+	if err != nil {
+		return false, err
+	}
+	wanted := managedOwnershipBitmask | os.ModeSetgid
+	return int64(gid) == fsGroup && info.Mode()&wanted == wanted, nil
+}
+
+// applyFSGroup gives every entry of the volume the group fsGroup and makes
+// it group readable and writable.  If original is not nil, the previous
+// group and mode of entries not yet in it are recorded.
+func applyFSGroup(root string, fsGroup int64, original map[string]originalOwnership) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == ownershipMarkerName {
+			return nil
+		}
+		gid, err := fileGID(info)
+		if err != nil {
+			return err
+		}
+		if _, found := original[rel]; !found && original != nil {
+			original[rel] = originalOwnership{GID: gid, Mode: info.Mode() & ownershipModeMask}
+		}
+		if err := os.Lchown(path, -1, int(fsGroup)); err != nil {
+			return fmt.Errorf("failed to change group of %s: %v", path, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if err := os.Chmod(path, info.Mode()&ownershipModeMask|managedOwnershipBitmask|os.ModeSetgid); err != nil {
+			return fmt.Errorf("failed to change mode of %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
+func readOwnershipMarker(path string) (*ownershipMarker, error) {
+	data, err := ioutil.ReadFile(filepath.Join(path, ownershipMarkerName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	marker := &ownershipMarker{}
+	if err := json.Unmarshal(data, marker); err != nil {
+		return nil, fmt.Errorf("corrupt ownership marker in %s: %v", path, err)
+	}
+	return marker, nil
+}
+
+// writeOwnershipMarker replaces the marker atomically, so a crash never
+// leaves a partially written one behind.
+func writeOwnershipMarker(path string, marker *ownershipMarker) error {
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(path, ownershipMarkerName+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(path, ownershipMarkerName)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func fileGID(info os.FileInfo) (int, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat == nil {
+		return 0, fmt.Errorf("no ownership information for %s", info.Name())
+	}
+	return int(stat.Gid), nil
+}
+
+// aclOwnership is false as files have groups which FSGroups are applied
+// with.
+const aclOwnership = false
+
+func grantVolumeAccess(path string) error {
+	return fmt.Errorf("ACL based ownership management is not supported on this platform")
+}
+
+func revokeVolumeAccess(path string) error {
+	return fmt.Errorf("ACL based ownership management is not supported on this platform")
+}
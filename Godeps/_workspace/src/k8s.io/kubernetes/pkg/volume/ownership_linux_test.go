@@ -0,0 +1,203 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// makeOwnershipTree creates a volume with a file, a subdirectory and a
+// symlink, all owned by the current group.
+func makeOwnershipTree(t *testing.T) string {
+	if os.Getuid() != 0 {
+		t.Skip("Changing file groups requires root")
+	}
+	dir, err := ioutil.TempDir("", "ownership_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatalf("Can't chmod %s: %v", dir, err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatalf("Can't make a subdirectory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "file"), []byte("data"), 0600); err != nil {
+		t.Fatalf("Can't write a file: %v", err)
+	}
+	if err := os.Symlink("sub/file", filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Can't make a symlink: %v", err)
+	}
+	return dir
+}
+
+func expectOwnership(t *testing.T, path string, gid int, mode os.FileMode) {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Can't stat %s: %v", path, err)
+	}
+	stat := info.Sys().(*syscall.Stat_t)
+	if int(stat.Gid) != gid || info.Mode()&ownershipModeMask != mode {
+		t.Errorf("%s: expected group %d and mode %v, got %d and %v", path, gid, mode, stat.Gid, info.Mode()&ownershipModeMask)
+	}
+}
+
+func TestReconcileFSGroupTransitions(t *testing.T) {
+	dir := makeOwnershipTree(t)
+	defer os.RemoveAll(dir)
+	gid := os.Getgid()
+	file := filepath.Join(dir, "sub", "file")
+
+	fsGroup := int64(1234)
+	if err := ReconcileFSGroup(dir, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, dir, 1234, 0775|os.ModeSetgid)
+	expectOwnership(t, file, 1234, 0660|os.ModeSetgid)
+
+	// Unchanged: nothing is touched, even files that drifted since.
+	if err := os.Chmod(file, 0600); err != nil {
+		t.Fatalf("Can't chmod %s: %v", file, err)
+	}
+	if err := ReconcileFSGroup(dir, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, file, 1234, 0600)
+
+	// Changed: ownership is applied again.
+	fsGroup = 5678
+	if err := ReconcileFSGroup(dir, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, dir, 5678, 0775|os.ModeSetgid)
+	expectOwnership(t, file, 5678, 0660|os.ModeSetgid)
+	if marker, err := readOwnershipMarker(dir); err != nil || marker == nil || marker.FSGroup != 5678 {
+		t.Errorf("Expected marker to record FSGroup 5678, got %+v, %v", marker, err)
+	}
+
+	// Unset: the original ownership comes back.
+	if err := ReconcileFSGroup(dir, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, dir, gid, 0755)
+	expectOwnership(t, filepath.Join(dir, "sub"), gid, 0700)
+	expectOwnership(t, file, gid, 0600)
+	if _, err := os.Stat(filepath.Join(dir, ownershipMarkerName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the marker to be removed, got %v", err)
+	}
+}
+
+func TestReconcileFSGroupRootMatches(t *testing.T) {
+	dir := makeOwnershipTree(t)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "sub", "file")
+
+	if err := os.Chown(dir, -1, 1234); err != nil {
+		t.Fatalf("Can't chown %s: %v", dir, err)
+	}
+	if err := os.Chmod(dir, 0770|os.ModeSetgid); err != nil {
+		t.Fatalf("Can't chmod %s: %v", dir, err)
+	}
+	fsGroup := int64(1234)
+	if err := ReconcileFSGroup(dir, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The root already matched, so the volume was not walked.
+	expectOwnership(t, file, os.Getgid(), 0600)
+	if marker, err := readOwnershipMarker(dir); err != nil || marker == nil || marker.FSGroup != 1234 {
+		t.Errorf("Expected marker to record FSGroup 1234, got %+v, %v", marker, err)
+	}
+}
+
+type fakeOwnershipBuilder struct {
+	FakeVolume
+	path   string
+	policy FSGroupChangePolicy
+}
+
+func (b *fakeOwnershipBuilder) GetPath() string {
+	return b.path
+}
+
+func (b *fakeOwnershipBuilder) GetAttributes() Attributes {
+	return Attributes{Managed: true, FSGroupChangePolicy: b.policy}
+}
+
+func TestSetVolumeOwnership(t *testing.T) {
+	dir := makeOwnershipTree(t)
+	defer os.RemoveAll(dir)
+	builder := &fakeOwnershipBuilder{path: dir, policy: FSGroupChangeAlways}
+
+	if err := SetVolumeOwnership(builder, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, dir, os.Getgid(), 0755)
+
+	fsGroup := int64(1234)
+	if err := SetVolumeOwnership(builder, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, dir, 1234, 0775|os.ModeSetgid)
+	expectOwnership(t, filepath.Join(dir, "sub"), 1234, 0760|os.ModeSetgid)
+	expectOwnership(t, filepath.Join(dir, "sub", "file"), 1234, 0660|os.ModeSetgid)
+
+	info, err := os.Lstat(filepath.Join(dir, "link"))
+	if err != nil {
+		t.Fatalf("Can't stat link: %v", err)
+	}
+	if gid := info.Sys().(*syscall.Stat_t).Gid; gid != 1234 {
+		t.Errorf("Expected the symlink itself to get group 1234, got %d", gid)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ownershipMarkerName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no ownership marker to be written, got %v", err)
+	}
+}
+
+func TestSetVolumeOwnershipOnRootMismatch(t *testing.T) {
+	dir := makeOwnershipTree(t)
+	defer os.RemoveAll(dir)
+	builder := &fakeOwnershipBuilder{path: dir, policy: FSGroupChangeOnRootMismatch}
+
+	fsGroup := int64(1234)
+	if err := SetVolumeOwnership(builder, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, filepath.Join(dir, "sub", "file"), 1234, 0660|os.ModeSetgid)
+
+	// The root matches, so a file changed since must be left alone.
+	file := filepath.Join(dir, "sub", "file")
+	if err := os.Chmod(file, 0600); err != nil {
+		t.Fatalf("Can't chmod %s: %v", file, err)
+	}
+	if err := SetVolumeOwnership(builder, &fsGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, file, 1234, 0600)
+
+	// A different group doesn't match the root and walks the volume again.
+	otherGroup := int64(4321)
+	if err := SetVolumeOwnership(builder, &otherGroup); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, file, 4321, 0660|os.ModeSetgid)
+}
@@ -0,0 +1,61 @@
+// +build windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+// aclOwnership is true as files have no groups on Windows: the access an
+// FSGroup gives is granted through the ACL of the volume instead.
+const aclOwnership = true
+
+// usersSID is the well known SID of the BUILTIN\Users group, which all
+// accounts containers run as on the node belong to.
+const usersSID = "*S-1-5-32-545"
+
+// aclRunner runs icacls, a variable so that it can be faked.
+var aclRunner exec.Interface = exec.New()
+
+// fileGID fails, as files have no group on Windows.
+func fileGID(info os.FileInfo) (int, error) {
+	return 0, fmt.Errorf("files have no group on Windows")
+}
+
+// grantVolumeAccess grants the users of the node modify access to the
+// volume at path, inherited by everything beneath it.
+func grantVolumeAccess(path string) error {
+	return runICACLS(path, "/grant", usersSID+":(OI)(CI)M")
+}
+
+// revokeVolumeAccess removes the access granted by grantVolumeAccess.
+func revokeVolumeAccess(path string) error {
+	return runICACLS(path, "/remove:g", usersSID)
+}
+
+func runICACLS(path string, args ...string) error {
+	output, err := aclRunner.Command("icacls", append([]string{path}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("icacls %s %v failed: %v: %s", path, args, err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+const (
+	// initialOperationBackoff is how long a volume whose operation failed
+	// waits before the next operation may start.
+	initialOperationBackoff = 500 * time.Millisecond
+	// maxOperationBackoff caps the backoff, which doubles on every failure.
+	maxOperationBackoff = 2*time.Minute + 2*time.Second
+)
+
+// pendingOperations keeps track of the operations of an OperationRegistry
+// that have a VolumeName, so that at most one operation runs for a volume
+// at a time, while operations on different volumes run in parallel.
+//
+// Operations are keyed by volume name and, optionally, pod.  An operation
+// without a pod conflicts with every operation on the volume, e.g.
+// detaching a disk; one with a pod only conflicts with operations for the
+// same pod and with those without a pod, so that several pods can set up
+// the same shared volume at once.
+//
+// A key whose last operation failed is not run again until its backoff
+// expired.  The backoff starts at initialOperationBackoff and doubles with
+// every consecutive failure up to maxOperationBackoff.  The backoff of the
+// key without a pod holds back the operations of every pod too.
+type pendingOperations struct {
+	lock       sync.Mutex
+	clock      util.Clock
+	operations []pendingOperation
+}
+
+type pendingOperation struct {
+	volumeName string
+	podUID     types.UID
+	running    bool
+	// lastFailure and backoff are set once an operation failed.
+	lastFailure time.Time
+	backoff     time.Duration
+}
+
+func newPendingOperations(clock util.Clock) *pendingOperations {
+	return &pendingOperations{clock: clock}
+}
+
+// ErrOperationPending is returned for an operation if a conflicting one is
+// still running.
+type ErrOperationPending struct {
+	VolumeName string
+	PodUID     types.UID
+}
+
+func (e *ErrOperationPending) Error() string {
+	return fmt.Sprintf("an operation for volume %q (pod %q) is already running", e.VolumeName, e.PodUID)
+}
+
+// ErrOperationBackoff is returned for an operation if the last operation for
+// its key failed and the backoff didn't expire yet.
+type ErrOperationBackoff struct {
+	VolumeName string
+	PodUID     types.UID
+	// RetryAfter is when the next operation may start.
+	RetryAfter time.Time
+}
+
+func (e *ErrOperationBackoff) Error() string {
+	return fmt.Sprintf("not running an operation for volume %q (pod %q) before %v, the last one failed", e.VolumeName, e.PodUID, e.RetryAfter)
+}
+
+// start marks the operation for the key as running, unless a conflicting
+// operation is running or the key is backing off, in which case it returns
+// an ErrOperationPending or ErrOperationBackoff.  Every successful start must
+// be followed by a finish.
+func (p *pendingOperations) start(volumeName string, podUID types.UID) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	index := -1
+	for i, op := range p.operations {
+		if op.volumeName != volumeName {
+			continue
+		}
+		if op.running && (op.podUID == "" || podUID == "" || op.podUID == podUID) {
+			return &ErrOperationPending{VolumeName: volumeName, PodUID: podUID}
+		}
+		if op.podUID == podUID {
+			index = i
+		} else if op.podUID == "" {
+			if retryAfter, ok := op.retryAfter(now); ok {
+				return &ErrOperationBackoff{VolumeName: volumeName, PodUID: podUID, RetryAfter: retryAfter}
+			}
+		}
+	}
+
+	if index == -1 {
+		p.operations = append(p.operations, pendingOperation{volumeName: volumeName, podUID: podUID})
+		index = len(p.operations) - 1
+	}
+	op := &p.operations[index]
+	if retryAfter, ok := op.retryAfter(now); ok {
+		return &ErrOperationBackoff{VolumeName: volumeName, PodUID: podUID, RetryAfter: retryAfter}
+	}
+	op.running = true
+	return nil
+}
+
+// retryAfter returns when the key of op may run again, and whether that is
+// after now.
+func (op *pendingOperation) retryAfter(now time.Time) (time.Time, bool) {
+	if op.backoff == 0 {
+		return time.Time{}, false
+	}
+	retryAfter := op.lastFailure.Add(op.backoff)
+	return retryAfter, now.Before(retryAfter)
+}
+
+// finish marks the operation for the key as done.  err decides the backoff
+// of the next operation for the key.
+func (p *pendingOperations) finish(volumeName string, podUID types.UID, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i := range p.operations {
+		op := &p.operations[i]
+		if op.volumeName != volumeName || op.podUID != podUID {
+			continue
+		}
+		if err == nil {
+			// Forget the key, there is no backoff to remember.
+			p.operations = append(p.operations[:i], p.operations[i+1:]...)
+			return
+		}
+		op.running = false
+		op.lastFailure = p.clock.Now()
+		switch {
+		case op.backoff == 0:
+			op.backoff = initialOperationBackoff
+		case op.backoff < maxOperationBackoff:
+			op.backoff *= 2
+			if op.backoff > maxOperationBackoff {
+				op.backoff = maxOperationBackoff
+			}
+		}
+		return
+	}
+}
+
+// isPending reports whether an operation that would conflict with one for
+// volumeName and podUID is running.
+func (p *pendingOperations) isPending(volumeName string, podUID types.UID) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, op := range p.operations {
+		if op.volumeName == volumeName && op.running && (op.podUID == "" || podUID == "" || op.podUID == podUID) {
+			return true
+		}
+	}
+	return false
+}
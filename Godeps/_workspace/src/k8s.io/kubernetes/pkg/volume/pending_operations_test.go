@@ -0,0 +1,151 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+func TestPendingOperationsSerializeVolume(t *testing.T) {
+	registry := NewOperationRegistry(&util.FakeClock{Time: time.Now()})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- registry.RunOperation(Operation{Type: OperationSetUp, VolumeName: "vol1", PodUID: "pod1", Func: func() error {
+			close(started)
+			<-release
+			return nil
+		}})
+	}()
+	<-started
+
+	run := func(volumeName string, podUID types.UID) error {
+		return registry.RunOperation(Operation{Type: OperationSetUp, VolumeName: volumeName, PodUID: podUID, Func: func() error { return nil }})
+	}
+	// Same key, and the whole volume, conflict.
+	for _, podUID := range []types.UID{"pod1", ""} {
+		if err := run("vol1", podUID); err == nil {
+			t.Errorf("Expected an operation for vol1 (pod %q) to be refused", podUID)
+		} else if _, ok := err.(*ErrOperationPending); !ok {
+			t.Errorf("Expected ErrOperationPending, got %v", err)
+		}
+	}
+	// Another pod of the same volume and another volume run in parallel.
+	if err := run("vol1", "pod2"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := run("vol2", ""); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !registry.IsOperationPending("vol1", "") {
+		t.Errorf("Expected an operation for vol1 to be pending")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if registry.IsOperationPending("vol1", "") || registry.IsOperationPending("vol2", "") {
+		t.Errorf("Expected no operation to be pending")
+	}
+	if err := run("vol1", ""); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPendingOperationsBackoff(t *testing.T) {
+	clock := &util.FakeClock{Time: time.Now()}
+	registry := NewOperationRegistry(clock)
+	failing := func() error { return errors.New("failed") }
+	calls := 0
+	succeeding := func() error {
+		calls++
+		return nil
+	}
+	run := func(volumeName string, fn func() error) error {
+		return registry.RunOperation(Operation{Type: OperationDelete, VolumeName: volumeName, Func: fn})
+	}
+
+	for _, backoff := range []time.Duration{initialOperationBackoff, 2 * initialOperationBackoff} {
+		if err := run("vol1", failing); err == nil {
+			t.Fatalf("Expected the failing operation to fail")
+		}
+
+		err := run("vol1", succeeding)
+		if backoffErr, ok := err.(*ErrOperationBackoff); !ok {
+			t.Fatalf("Expected ErrOperationBackoff, got %v", err)
+		} else if expected := clock.Now().Add(backoff); !backoffErr.RetryAfter.Equal(expected) {
+			t.Errorf("Expected to retry at %v, got %v", expected, backoffErr.RetryAfter)
+		}
+		// Other volumes are not affected.
+		if err := run("vol2", succeeding); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		clock.Step(backoff)
+	}
+
+	if err := run("vol1", succeeding); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A success resets the backoff.
+	if err := run("vol1", failing); err == nil {
+		t.Fatalf("Expected the failing operation to fail")
+	}
+	clock.Step(initialOperationBackoff)
+	if err := run("vol1", succeeding); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 successful operations, got %d", calls)
+	}
+}
+
+func TestPendingOperationsVolumeBackoffHoldsBackPods(t *testing.T) {
+	clock := &util.FakeClock{Time: time.Now()}
+	registry := NewOperationRegistry(clock)
+	if err := registry.RunOperation(Operation{Type: OperationTearDown, VolumeName: "vol1", Func: func() error { return errors.New("failed") }}); err == nil {
+		t.Fatalf("Expected the failing operation to fail")
+	}
+
+	called := false
+	setUp := Operation{Type: OperationSetUp, VolumeName: "vol1", PodUID: "pod1", Func: func() error {
+		called = true
+		return nil
+	}}
+	if err := registry.RunOperation(setUp); err == nil {
+		t.Errorf("Expected the operation of the pod to back off with the volume")
+	} else if _, ok := err.(*ErrOperationBackoff); !ok {
+		t.Errorf("Expected ErrOperationBackoff, got %v", err)
+	}
+	if called {
+		t.Errorf("Expected the operation of the pod not to run while the volume backs off")
+	}
+
+	clock.Step(initialOperationBackoff)
+	if err := registry.RunOperation(setUp); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !called {
+		t.Errorf("Expected the operation of the pod to run once the backoff expired")
+	}
+}
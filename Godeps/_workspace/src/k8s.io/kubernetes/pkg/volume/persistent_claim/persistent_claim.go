@@ -18,6 +18,7 @@ package persistent_claim
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
@@ -26,12 +27,15 @@ import (
 )
 
 func ProbeVolumePlugins() []volume.VolumePlugin {
-	return []volume.VolumePlugin{&persistentClaimPlugin{host: nil}}
+	return []volume.VolumePlugin{&persistentClaimPlugin{host: nil, state: volume.NewActualStateOfWorld()}}
 }
 
 type persistentClaimPlugin struct {
 	host     volume.VolumeHost
 	readOnly bool
+	// state records the pods the volumes of ReadWriteOncePod claims are set
+	// up for.
+	state volume.ActualStateOfWorld
 }
 
 var _ volume.VolumePlugin = &persistentClaimPlugin{}
@@ -52,6 +56,13 @@ func (plugin *persistentClaimPlugin) CanSupport(spec *volume.Spec) bool {
 	return spec.Volume != nil && spec.Volume.PersistentVolumeClaim != nil
 }
 
+func (plugin *persistentClaimPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.PersistentVolumeClaim == nil {
+		return "", fmt.Errorf("spec does not reference a persistent volume claim")
+	}
+	return spec.Volume.PersistentVolumeClaim.ClaimName, nil
+}
+
 func (plugin *persistentClaimPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	claim, err := plugin.host.GetKubeClient().PersistentVolumeClaims(pod.Namespace).Get(spec.Volume.PersistentVolumeClaim.ClaimName)
 	if err != nil {
@@ -85,9 +96,26 @@ func (plugin *persistentClaimPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod,
 		return nil, err
 	}
 
+	if volume.IsReadWriteOncePod(claim.Spec.AccessModes) {
+		builder = plugin.newReadWriteOncePodBuilder(builder, pv, pod)
+	}
 	return builder, nil
 }
 
+// newReadWriteOncePodBuilder returns a builder which refuses to set up pv for
+// pod while another pod of the node uses it.  A pod uses pv until its
+// directory is cleaned up, which only happens once all its volumes are torn
+// down.
+func (plugin *persistentClaimPlugin) newReadWriteOncePodBuilder(builder volume.Builder, pv *api.PersistentVolume, pod *api.Pod) volume.Builder {
+	layout := volume.NewDirectoryLayout(plugin.host)
+	inUse := func(podUID types.UID) bool {
+		_, err := os.Stat(layout.PodVolumesDir(podUID))
+		return !os.IsNotExist(err)
+	}
+	volumeName := volume.GetUniqueVolumeName(persistentClaimPluginName, pv.Name)
+	return volume.NewReadWriteOncePodBuilder(builder, plugin.state, volumeName, volume.NewSpecFromPersistentVolume(pv, false), pod.UID, inUse)
+}
+
 func (plugin *persistentClaimPlugin) IsReadOnly() bool {
 	return plugin.readOnly
 }
@@ -19,6 +19,8 @@ package persistent_claim
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
 	"strings"
 	"testing"
 
@@ -310,6 +312,78 @@ func TestNewBuilderClaimNotBound(t *testing.T) {
 	}
 }
 
+func TestNewBuilderReadWriteOncePod(t *testing.T) {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			Name: "pvD",
+		},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				HostPath: &api.HostPathVolumeSource{Path: "/tmp"},
+			},
+			AccessModes: []api.PersistentVolumeAccessMode{api.ReadWriteOncePod},
+			ClaimRef: &api.ObjectReference{
+				Name: "claimD",
+			},
+		},
+	}
+	claim := &api.PersistentVolumeClaim{
+		ObjectMeta: api.ObjectMeta{
+			Name:      "claimD",
+			Namespace: "nsA",
+		},
+		Spec: api.PersistentVolumeClaimSpec{
+			VolumeName:  "pvD",
+			AccessModes: []api.PersistentVolumeAccessMode{api.ReadWriteOncePod},
+		},
+	}
+	o := testclient.NewObjects(api.Scheme, api.Scheme)
+	o.Add(pv)
+	o.Add(claim)
+	client := &testclient.Fake{}
+	client.AddReactor("*", "*", testclient.ObjectReaction(o, api.RESTMapper))
+
+	host := newTestHost(t, client)
+	layout := volume.NewDirectoryLayout(host)
+	defer os.RemoveAll(path.Dir(layout.PodsDir()))
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(testProbeVolumePlugins(), host)
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/persistent-claim")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	spec := &volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{
+		PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: "claimD"},
+	}}}
+	setUp := func(podUID types.UID) error {
+		builder, err := plug.NewBuilder(spec, &api.Pod{ObjectMeta: api.ObjectMeta{UID: podUID, Namespace: "nsA"}}, volume.VolumeOptions{})
+		if err != nil {
+			t.Fatalf("Failed to make a new Builder: %v", err)
+		}
+		return builder.SetUp()
+	}
+
+	if err := os.MkdirAll(layout.PodVolumesDir("pod1"), 0750); err != nil {
+		t.Fatalf("can't make the volumes dir: %v", err)
+	}
+	if err := setUp("pod1"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := setUp("pod2"); err == nil {
+		t.Errorf("Expected the volume to be refused to pod2")
+	} else if _, ok := err.(*volume.ErrVolumeConflict); !ok {
+		t.Errorf("Expected an ErrVolumeConflict, got %v", err)
+	}
+
+	// Once pod1 is cleaned up the volume is free.
+	if err := os.RemoveAll(path.Dir(layout.PodVolumesDir("pod1"))); err != nil {
+		t.Fatalf("can't remove the pod dir: %v", err)
+	}
+	if err := setUp("pod2"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func testProbeVolumePlugins() []volume.VolumePlugin {
 	allPlugins := []volume.VolumePlugin{}
 	allPlugins = append(allPlugins, gce_pd.ProbeVolumePlugins()...)
@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/client/record"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	"k8s.io/kubernetes/pkg/types"
@@ -51,6 +53,29 @@ type VolumeOptions struct {
 	PersistentVolumeReclaimPolicy api.PersistentVolumeReclaimPolicy
 	// Tags to attach to the real volume in the cloud provider - e.g. AWS EBS
 	CloudTags *map[string]string
+	// SnapshotSource is the ID of a snapshot, as returned by Snapshotter.CreateSnapshot, to populate the new
+	// volume from.  If empty, the volume is provisioned empty.  Provisioners which do not support snapshots
+	// must fail when it is set.
+	SnapshotSource string
+	// CloneSource is the volume bound to an existing claim to populate the new volume with a copy of.  If
+	// nil, the volume is provisioned empty.  Provisioners of plugins whose SupportsCloning returns false must
+	// fail when it is set.
+	CloneSource *api.PersistentVolume
+	// Zone is the zone, or availability zone, of the cloud provider to create the volume in.  If empty, the
+	// volume is created in the zone the provisioner runs in.
+	Zone string
+	// Parameters are the provisioner specific parameters of the storage class the claim asked for, e.g. the
+	// type of disk.  Provisioners must fail on parameters they do not know, so that typos are noticed.
+	Parameters map[string]string
+	// PVC is the claim the volume is provisioned for, if any.
+	PVC *api.PersistentVolumeClaim
+	// AllowedTopologies restricts where the volume may be created, e.g. to some zones, if not empty.  The
+	// volume must be usable from nodes matching any of the selectors.
+	AllowedTopologies []TopologySelector
+	// SelectedNode is the node the pod consuming the volume was scheduled to, if known.  Provisioners of
+	// volumes that can't be used from every node should create the volume where this node can use it, and
+	// record where it can be used with SetNodeAffinity.
+	SelectedNode *api.Node
 }
 
 // VolumePlugin is an interface to volume plugins that can be used on a
@@ -71,6 +96,14 @@ type VolumePlugin interface {
 	// const.
 	CanSupport(spec *Spec) bool
 
+	// GetVolumeName returns the name of the volume described by spec,
+	// unique among the volumes of the plugin, e.g. the ID of a cloud disk,
+	// so that a volume is recognized whichever pod or spec refers to it.
+	// Plugins whose volumes belong to a single pod return the name of the
+	// volume in the spec.  See GetUniqueVolumeName to key volumes of all
+	// plugins.
+	GetVolumeName(spec *Spec) (string, error)
+
 	// NewBuilder creates a new volume.Builder from an API specification.
 	// Ownership of the spec pointer in *not* transferred.
 	// - spec: The api.Volume spec
@@ -115,6 +148,100 @@ type ProvisionableVolumePlugin interface {
 	// NewProvisioner creates a new volume.Provisioner which knows how to create PersistentVolumes in accordance with
 	// the plugin's underlying storage provider
 	NewProvisioner(options VolumeOptions) (Provisioner, error)
+	// SupportsCloning returns whether the plugin's provisioners can populate new volumes from
+	// VolumeOptions.CloneSource with a copy made by the underlying storage provider.
+	SupportsCloning() bool
+}
+
+// ErrSnapshotSourceNotSupported is returned by provisioners which can't populate a new volume from a snapshot.
+type ErrSnapshotSourceNotSupported struct {
+	Plugin     string
+	SnapshotID string
+}
+
+func (e *ErrSnapshotSourceNotSupported) Error() string {
+	return fmt.Sprintf("plugin %s can't provision volumes from snapshot %q", e.Plugin, e.SnapshotID)
+}
+
+// ErrCloneSourceNotSupported is returned by provisioners which can't populate a new volume from CloneSource.
+type ErrCloneSourceNotSupported struct {
+	Plugin string
+	// Source is the name of the persistent volume to clone.
+	Source string
+}
+
+func (e *ErrCloneSourceNotSupported) Error() string {
+	return fmt.Sprintf("plugin %s can't provision volumes cloned from volume %q", e.Plugin, e.Source)
+}
+
+// CloneSourceAnnotation is the annotation on a PersistentVolumeClaim with the name of another claim, in the
+// same namespace, whose volume the volume provisioned for the claim is cloned from.
+const CloneSourceAnnotation = "volume.alpha.kubernetes.io/clone-source"
+
+// SnapshottableVolumePlugin is an extended interface of VolumePlugin and is used by persistent volumes whose
+// storage provider can take snapshots.
+type SnapshottableVolumePlugin interface {
+	VolumePlugin
+	// NewSnapshotter creates a new volume.Snapshotter which knows how to snapshot the volume described by spec.
+	NewSnapshotter(spec *Spec) (Snapshotter, error)
+}
+
+// ExpandableVolumePlugin is an extended interface of VolumePlugin and is used by persistent volumes that can be
+// grown in place.
+type ExpandableVolumePlugin interface {
+	VolumePlugin
+	Expander
+}
+
+// ReconstructableVolumePlugin is an extended interface of VolumePlugin and is used for volumes whose spec can be
+// rebuilt from their mount path after a kubelet restart.
+type ReconstructableVolumePlugin interface {
+	VolumePlugin
+	Reconstructor
+}
+
+// AttachableVolumePlugin is an extended interface of VolumePlugin and is used for volumes that require attachment
+// to a node before mounting.
+type AttachableVolumePlugin interface {
+	VolumePlugin
+	// NewAttacher creates a new volume.Attacher which knows how to attach volumes of this plugin to a node.
+	NewAttacher() (Attacher, error)
+	// NewDetacher creates a new volume.Detacher which knows how to detach volumes of this plugin from a node.
+	NewDetacher() (Detacher, error)
+}
+
+// DeviceMountablePlugin is an extended interface of VolumePlugin and is used for volumes whose device is mounted
+// once per node in a global directory, which is then bind mounted into each pod using it.
+type DeviceMountablePlugin interface {
+	VolumePlugin
+	// GetDeviceMountPath returns the global directory the device of the volume is mounted in.
+	GetDeviceMountPath(spec *Spec) (string, error)
+}
+
+// BlockVolumePlugin is an extended interface of VolumePlugin and is used for volumes that can be exposed to pods
+// as raw block devices.
+type BlockVolumePlugin interface {
+	VolumePlugin
+	// NewBlockVolumeBuilder creates a new volume.BlockVolumeBuilder from an API specification.
+	// Ownership of the spec pointer in *not* transferred.
+	NewBlockVolumeBuilder(spec *Spec, podRef *api.Pod, opts VolumeOptions) (BlockVolumeBuilder, error)
+	// NewBlockVolumeCleaner creates a new volume.BlockVolumeCleaner from recoverable state.
+	NewBlockVolumeCleaner(name string, podUID types.UID) (BlockVolumeCleaner, error)
+}
+
+// VolumeLimitKeyPrefix starts the keys of the limits returned by
+// VolumePluginWithAttachLimits.GetVolumeLimits.
+const VolumeLimitKeyPrefix = "attachable-volumes-"
+
+// VolumePluginWithAttachLimits is an extended interface of VolumePlugin and is used for volumes of which a node
+// can only attach a bounded number, so that pods are not scheduled to nodes that are out of attachment slots.
+type VolumePluginWithAttachLimits interface {
+	VolumePlugin
+	// GetVolumeLimits returns the maximum number of volumes of this plugin that can be attached to the local
+	// node, keyed by limit key.
+	GetVolumeLimits() (map[string]int64, error)
+	// VolumeLimitKey returns the key of the limit the volume described by spec counts against.
+	VolumeLimitKey(spec *Spec) string
 }
 
 // VolumeHost is an interface that plugins can use to access the kubelet.
@@ -159,14 +286,59 @@ type VolumeHost interface {
 
 	// Get writer interface for writing data to disk.
 	GetWriter() io.Writer
+
+	// GetHostName returns the name of the node the host runs on, or "" if
+	// the host is not tied to a node, e.g. in a controller.
+	GetHostName() string
+
+	// GetEventRecorder returns the recorder for events about the objects
+	// the host manages volumes for, or nil if the host records none.
+	GetEventRecorder() record.EventRecorder
 }
 
 // VolumePluginMgr tracks registered plugins.
 type VolumePluginMgr struct {
 	mutex   sync.Mutex
 	plugins map[string]VolumePlugin
+	// prober registers and unregisters plugins at runtime, whose names are
+	// kept in probedPlugins.  Plugins it registers are initialized with host.
+	prober        DynamicPluginProber
+	probedPlugins map[string]bool
+	host          VolumeHost
+}
+
+// DynamicPluginProber finds plugins which are installed and removed while
+// the host runs, e.g. drivers in a plugin directory, so that they are used
+// without restarting the host.
+type DynamicPluginProber interface {
+	// Init prepares probing, e.g. starts watching for changes.
+	Init() error
+	// Probe returns the changes to the plugins since it was last called,
+	// all the plugins there are when it is called first.  Events are
+	// returned along with an error if only some plugins failed to probe.
+	Probe() ([]ProbeEvent, error)
 }
 
+// ProbeEvent is a change to the plugins found by a DynamicPluginProber.
+type ProbeEvent struct {
+	Op ProbeOperation
+	// Plugin is the plugin to register for ProbeAddOrUpdate.
+	Plugin VolumePlugin
+	// PluginName is the name of the plugin to unregister for ProbeRemove.
+	PluginName string
+}
+
+// ProbeOperation is what to do with the plugin of a ProbeEvent.
+type ProbeOperation string
+
+const (
+	// ProbeAddOrUpdate registers the plugin, replacing a probed plugin of
+	// the same name.
+	ProbeAddOrUpdate ProbeOperation = "AddOrUpdate"
+	// ProbeRemove unregisters the plugin of the name.
+	ProbeRemove ProbeOperation = "Remove"
+)
+
 // Spec is an internal representation of a volume.  All API volume types translate to Spec.
 type Spec struct {
 	Volume           *api.Volume
@@ -186,6 +358,109 @@ func (spec *Spec) Name() string {
 	}
 }
 
+// PersistentVolumeSource returns the source of the volume in the form used by PersistentVolumes, so that plugins
+// can look up their source the same way whether the volume is inline or persistent.  For inline volumes, sources
+// that can't back a PersistentVolume (e.g. emptyDir) are left out.  Returns nil if the spec is empty.
+func (spec *Spec) PersistentVolumeSource() *api.PersistentVolumeSource {
+	switch {
+	case spec.Volume != nil:
+		vs := &spec.Volume.VolumeSource
+		return &api.PersistentVolumeSource{
+			GCEPersistentDisk:    vs.GCEPersistentDisk,
+			AWSElasticBlockStore: vs.AWSElasticBlockStore,
+			HostPath:             vs.HostPath,
+			Glusterfs:            vs.Glusterfs,
+			NFS:                  vs.NFS,
+			RBD:                  vs.RBD,
+			ISCSI:                vs.ISCSI,
+			Cinder:               vs.Cinder,
+			CephFS:               vs.CephFS,
+			FC:                   vs.FC,
+			Flocker:              vs.Flocker,
+			FlexVolume:           vs.FlexVolume,
+		}
+	case spec.PersistentVolume != nil:
+		return &spec.PersistentVolume.Spec.PersistentVolumeSource
+	default:
+		return nil
+	}
+}
+
+// MountOptionAnnotation is the annotation on a PersistentVolume with the comma separated options, e.g.
+// "noatime,nfsvers=4.1", that an administrator wants the volume mounted with.
+const MountOptionAnnotation = "volume.beta.kubernetes.io/mount-options"
+
+// ZoneAnnotation is the annotation on a PersistentVolumeClaim with the zone its volume should be provisioned
+// in.  Provisioned PersistentVolumes carry a label with the same key and the zone they were created in.
+const ZoneAnnotation = "volume.alpha.kubernetes.io/zone"
+
+// MountOptions returns the mount options requested through MountOptionAnnotation on the PersistentVolume, or nil
+// for volumes used directly in a pod.  Plugins that mount a filesystem pass them on, see JoinMountOptions.
+func (spec *Spec) MountOptions() []string {
+	if spec.PersistentVolume == nil {
+		return nil
+	}
+	value, found := spec.PersistentVolume.Annotations[MountOptionAnnotation]
+	if !found {
+		return nil
+	}
+	options := []string{}
+	for _, option := range strings.Split(value, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// JoinMountOptions returns the options a plugin needs to mount a volume followed by those of userOptions which
+// aren't among them.
+func JoinMountOptions(userOptions []string, systemOptions []string) []string {
+	seen := make(map[string]bool, len(systemOptions))
+	options := make([]string, 0, len(systemOptions)+len(userOptions))
+	for _, option := range append(append([]string{}, systemOptions...), userOptions...) {
+		if !seen[option] {
+			seen[option] = true
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// IsReadOnly returns whether the volume is to be mounted read-only.  Volumes used directly in a pod have a ReadOnly
+// flag set by the pod author in their source, while PersistentVolumes get it from the claim through Spec.ReadOnly.
+func (spec *Spec) IsReadOnly() bool {
+	if spec.Volume == nil {
+		return spec.ReadOnly
+	}
+	vs := &spec.Volume.VolumeSource
+	switch {
+	case vs.GCEPersistentDisk != nil:
+		return vs.GCEPersistentDisk.ReadOnly
+	case vs.AWSElasticBlockStore != nil:
+		return vs.AWSElasticBlockStore.ReadOnly
+	case vs.Glusterfs != nil:
+		return vs.Glusterfs.ReadOnly
+	case vs.NFS != nil:
+		return vs.NFS.ReadOnly
+	case vs.RBD != nil:
+		return vs.RBD.ReadOnly
+	case vs.ISCSI != nil:
+		return vs.ISCSI.ReadOnly
+	case vs.Cinder != nil:
+		return vs.Cinder.ReadOnly
+	case vs.CephFS != nil:
+		return vs.CephFS.ReadOnly
+	case vs.FC != nil:
+		return vs.FC.ReadOnly
+	case vs.FlexVolume != nil:
+		return vs.FlexVolume.ReadOnly
+	case vs.PersistentVolumeClaim != nil:
+		return vs.PersistentVolumeClaim.ReadOnly
+	}
+	return spec.ReadOnly
+}
+
 // VolumeConfig is how volume plugins receive configuration.  An instance specific to the plugin will be passed to
 // the plugin's ProbeVolumePlugins(config) func.  Reasonable defaults will be provided by the binary hosting
 // the plugins while allowing override of those default values.  Those config values are then set to an instance of
@@ -217,6 +492,14 @@ type VolumeConfig struct {
 	// Example: 5Gi volume x 30s increment = 150s + 30s minimum = 180s ActiveDeadlineSeconds for recycler pod
 	RecyclerTimeoutIncrement int
 
+	// RecyclerScrubOnHost makes plugins whose volumes the host can reach directly, e.g. hostPath and NFS, scrub
+	// them on the host instead of in a recycler pod.  See NewHostScrubExecutor.
+	RecyclerScrubOnHost bool
+
+	// UnmountGracePeriod bounds each attempt of plugins which escalate unmounts that fail or hang, e.g. of NFS
+	// volumes whose server went away, see UnmountWithEscalation.  0 unmounts without escalating.
+	UnmountGracePeriod time.Duration
+
 	// OtherAttributes stores config as strings.  These strings are opaque to the system and only understood by the binary
 	// hosting the plugin and the plugin itself.
 	OtherAttributes map[string]string
@@ -247,6 +530,7 @@ func (pm *VolumePluginMgr) InitPlugins(plugins []VolumePlugin, host VolumeHost)
 	if pm.plugins == nil {
 		pm.plugins = map[string]VolumePlugin{}
 	}
+	pm.host = host
 
 	allErrs := []error{}
 	for _, plugin := range plugins {
@@ -267,12 +551,70 @@ func (pm *VolumePluginMgr) InitPlugins(plugins []VolumePlugin, host VolumeHost)
 	return errors.NewAggregate(allErrs)
 }
 
+// InitDynamicPlugins registers the plugins prober finds, then keeps them up
+// to date by probing whenever plugins are looked up.  Plugins it finds are
+// initialized with host, and never replace the plugins of InitPlugins.
+func (pm *VolumePluginMgr) InitDynamicPlugins(prober DynamicPluginProber, host VolumeHost) error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if pm.plugins == nil {
+		pm.plugins = map[string]VolumePlugin{}
+	}
+	if err := prober.Init(); err != nil {
+		return err
+	}
+	pm.prober = prober
+	pm.probedPlugins = map[string]bool{}
+	pm.host = host
+	pm.refreshProbedPlugins()
+	return nil
+}
+
+// refreshProbedPlugins applies the changes the prober found since it was
+// last called.  It must be called with pm.mutex held.
+func (pm *VolumePluginMgr) refreshProbedPlugins() {
+	if pm.prober == nil {
+		return
+	}
+	events, err := pm.prober.Probe()
+	if err != nil {
+		glog.Errorf("Error probing dynamic volume plugins: %v", err)
+	}
+	for _, event := range events {
+		switch event.Op {
+		case ProbeAddOrUpdate:
+			name := event.Plugin.Name()
+			if !validation.IsQualifiedName(name) {
+				glog.Errorf("Probed volume plugin has invalid name: %#v", event.Plugin)
+				continue
+			}
+			if _, found := pm.plugins[name]; found && !pm.probedPlugins[name] {
+				glog.Errorf("Probed volume plugin %q has the name of a registered plugin, ignoring it", name)
+				continue
+			}
+			event.Plugin.Init(pm.host)
+			pm.plugins[name] = event.Plugin
+			pm.probedPlugins[name] = true
+			glog.V(1).Infof("Loaded probed volume plugin %q", name)
+		case ProbeRemove:
+			if !pm.probedPlugins[event.PluginName] {
+				continue
+			}
+			delete(pm.plugins, event.PluginName)
+			delete(pm.probedPlugins, event.PluginName)
+			glog.V(1).Infof("Unloaded probed volume plugin %q", event.PluginName)
+		}
+	}
+}
+
 // FindPluginBySpec looks for a plugin that can support a given volume
 // specification.  If no plugins can support or more than one plugin can
 // support it, return error.
 func (pm *VolumePluginMgr) FindPluginBySpec(spec *Spec) (VolumePlugin, error) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
+	pm.refreshProbedPlugins()
 
 	matches := []string{}
 	for k, v := range pm.plugins {
@@ -294,6 +636,7 @@ func (pm *VolumePluginMgr) FindPluginBySpec(spec *Spec) (VolumePlugin, error) {
 func (pm *VolumePluginMgr) FindPluginByName(name string) (VolumePlugin, error) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
+	pm.refreshProbedPlugins()
 
 	// Once we can get rid of legacy names we can reduce this to a map lookup.
 	matches := []string{}
@@ -421,3 +764,109 @@ func NewPersistentVolumeRecyclerPodTemplate() *api.Pod {
 	}
 	return pod
 }
+
+// FindSnapshottablePluginBySpec fetches a snapshottable volume plugin by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindSnapshottablePluginBySpec(spec *Spec) (SnapshottableVolumePlugin, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if snapshottableVolumePlugin, ok := volumePlugin.(SnapshottableVolumePlugin); ok {
+		return snapshottableVolumePlugin, nil
+	}
+	return nil, fmt.Errorf("no snapshottable volume plugin matched")
+}
+
+// FindExpandablePluginBySpec fetches an expandable volume plugin by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindExpandablePluginBySpec(spec *Spec) (ExpandableVolumePlugin, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if expandableVolumePlugin, ok := volumePlugin.(ExpandableVolumePlugin); ok {
+		return expandableVolumePlugin, nil
+	}
+	return nil, fmt.Errorf("no expandable volume plugin matched")
+}
+
+// FindReconstructablePluginByName fetches a reconstructable volume plugin by name.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindReconstructablePluginByName(name string) (ReconstructableVolumePlugin, error) {
+	volumePlugin, err := pm.FindPluginByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if reconstructableVolumePlugin, ok := volumePlugin.(ReconstructableVolumePlugin); ok {
+		return reconstructableVolumePlugin, nil
+	}
+	return nil, fmt.Errorf("no reconstructable volume plugin matched")
+}
+
+// FindAttachablePluginBySpec fetches an attachable volume plugin by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindAttachablePluginBySpec(spec *Spec) (AttachableVolumePlugin, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if attachableVolumePlugin, ok := volumePlugin.(AttachableVolumePlugin); ok {
+		return attachableVolumePlugin, nil
+	}
+	return nil, fmt.Errorf("no attachable volume plugin matched")
+}
+
+// FindVolumePluginWithLimitsBySpec fetches a volume plugin with attach limits by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindVolumePluginWithLimitsBySpec(spec *Spec) (VolumePluginWithAttachLimits, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if limitsPlugin, ok := volumePlugin.(VolumePluginWithAttachLimits); ok {
+		return limitsPlugin, nil
+	}
+	return nil, fmt.Errorf("no volume plugin with attach limits matched")
+}
+
+// ListVolumePluginWithLimits returns all plugins with attach limits, e.g. to collect the limits of a node.
+func (pm *VolumePluginMgr) ListVolumePluginWithLimits() []VolumePluginWithAttachLimits {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.refreshProbedPlugins()
+
+	plugins := []VolumePluginWithAttachLimits{}
+	for _, plugin := range pm.plugins {
+		if limitsPlugin, ok := plugin.(VolumePluginWithAttachLimits); ok {
+			plugins = append(plugins, limitsPlugin)
+		}
+	}
+	return plugins
+}
+
+// FindBlockVolumePluginBySpec fetches a block volume plugin by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindBlockVolumePluginBySpec(spec *Spec) (BlockVolumePlugin, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if blockVolumePlugin, ok := volumePlugin.(BlockVolumePlugin); ok {
+		return blockVolumePlugin, nil
+	}
+	return nil, fmt.Errorf("no block volume plugin matched")
+}
+
+// FindDeviceMountablePluginBySpec fetches a device mountable volume plugin by spec.  If no plugin
+// is found, returns error.
+func (pm *VolumePluginMgr) FindDeviceMountablePluginBySpec(spec *Spec) (DeviceMountablePlugin, error) {
+	volumePlugin, err := pm.FindPluginBySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if deviceMountablePlugin, ok := volumePlugin.(DeviceMountablePlugin); ok {
+		return deviceMountablePlugin, nil
+	}
+	return nil, fmt.Errorf("no device mountable volume plugin matched")
+}
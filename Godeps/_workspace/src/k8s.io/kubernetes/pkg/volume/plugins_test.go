@@ -17,6 +17,7 @@ limitations under the License.
 package volume
 
 import (
+	"reflect"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
@@ -51,3 +52,155 @@ func TestSpecSourceConverters(t *testing.T) {
 		t.Errorf("Expected %v but got %v", pv.Name, converted.Name())
 	}
 }
+
+func TestVolumePluginMgr(t *testing.T) {
+	plugMgr := VolumePluginMgr{}
+	err := plugMgr.InitPlugins([]VolumePlugin{
+		&FakeVolumePlugin{PluginName: "kubernetes.io/fake-a"},
+		&FakeVolumePlugin{PluginName: "kubernetes.io/fake-a"},
+		&FakeVolumePlugin{PluginName: "not a/valid name!"},
+	}, NewFakeVolumeHost("/tmp/fake", nil, nil))
+	if err == nil {
+		t.Errorf("Expected duplicate and invalid plugin names to be reported")
+	}
+
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/fake-a")
+	if err != nil || plug.Name() != "kubernetes.io/fake-a" {
+		t.Errorf("Expected to find kubernetes.io/fake-a, got %v, %v", plug, err)
+	}
+	if _, err := plugMgr.FindPluginByName("kubernetes.io/missing"); err == nil {
+		t.Errorf("Expected an error for an unregistered plugin")
+	}
+
+	spec := NewSpecFromVolume(&api.Volume{Name: "vol1"})
+	if plug, err := plugMgr.FindPluginBySpec(spec); err != nil || plug.Name() != "kubernetes.io/fake-a" {
+		t.Errorf("Expected kubernetes.io/fake-a to support the spec, got %v, %v", plug, err)
+	}
+
+	// FakeVolumePlugin supports every spec, so a second one is ambiguous.
+	if err := plugMgr.InitPlugins([]VolumePlugin{&FakeVolumePlugin{PluginName: "kubernetes.io/fake-b"}}, NewFakeVolumeHost("/tmp/fake", nil, nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := plugMgr.FindPluginBySpec(spec); err == nil {
+		t.Errorf("Expected an error when several plugins match")
+	}
+}
+
+// fakeProber returns its queued events on the next Probe.
+type fakeProber struct {
+	events []ProbeEvent
+}
+
+func (p *fakeProber) Init() error {
+	return nil
+}
+
+func (p *fakeProber) Probe() ([]ProbeEvent, error) {
+	events := p.events
+	p.events = nil
+	return events, nil
+}
+
+func TestVolumePluginMgrDynamicPlugins(t *testing.T) {
+	plugMgr := VolumePluginMgr{}
+	registered := &FakeVolumePlugin{PluginName: "kubernetes.io/fake-a"}
+	if err := plugMgr.InitPlugins([]VolumePlugin{registered}, NewFakeVolumeHost("/tmp/fake", nil, nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	prober := &fakeProber{events: []ProbeEvent{
+		{Op: ProbeAddOrUpdate, Plugin: &FakeVolumePlugin{PluginName: "example.com/probed"}},
+		{Op: ProbeAddOrUpdate, Plugin: &FakeVolumePlugin{PluginName: "kubernetes.io/fake-a"}},
+	}}
+	if err := plugMgr.InitDynamicPlugins(prober, NewFakeVolumeHost("/tmp/fake", nil, nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	plug, err := plugMgr.FindPluginByName("example.com/probed")
+	if err != nil {
+		t.Fatalf("Expected to find the probed plugin, got %v", err)
+	}
+	if plug.(*FakeVolumePlugin).Host == nil {
+		t.Errorf("Expected the probed plugin to be initialized")
+	}
+	if plug, _ := plugMgr.FindPluginByName("kubernetes.io/fake-a"); plug != registered {
+		t.Errorf("Expected the registered plugin not to be replaced by a probed one")
+	}
+
+	updated := &FakeVolumePlugin{PluginName: "example.com/probed"}
+	prober.events = []ProbeEvent{{Op: ProbeAddOrUpdate, Plugin: updated}}
+	if plug, _ := plugMgr.FindPluginByName("example.com/probed"); plug != updated {
+		t.Errorf("Expected the updated plugin, got %v", plug)
+	}
+
+	prober.events = []ProbeEvent{
+		{Op: ProbeRemove, PluginName: "example.com/probed"},
+		{Op: ProbeRemove, PluginName: "kubernetes.io/fake-a"},
+	}
+	if _, err := plugMgr.FindPluginByName("example.com/probed"); err == nil {
+		t.Errorf("Expected the removed plugin to be unregistered")
+	}
+	if _, err := plugMgr.FindPluginByName("kubernetes.io/fake-a"); err != nil {
+		t.Errorf("Expected the registered plugin not to be removed by the prober, got %v", err)
+	}
+}
+
+func TestSpecPersistentVolumeSource(t *testing.T) {
+	rbd := &api.RBDVolumeSource{RBDImage: "image", ReadOnly: true}
+	inline := NewSpecFromVolume(&api.Volume{Name: "foo", VolumeSource: api.VolumeSource{RBD: rbd}})
+	if source := inline.PersistentVolumeSource(); source == nil || source.RBD != rbd {
+		t.Errorf("Expected the inline RBD source, got %+v", source)
+	}
+	if !inline.IsReadOnly() {
+		t.Errorf("Expected the inline source's ReadOnly flag to be used")
+	}
+
+	emptyDir := NewSpecFromVolume(&api.Volume{Name: "foo", VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{}}})
+	if source := emptyDir.PersistentVolumeSource(); source == nil || *source != (api.PersistentVolumeSource{}) {
+		t.Errorf("Expected an empty source for emptyDir, got %+v", source)
+	}
+
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "bar"},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{RBD: rbd},
+		},
+	}
+	// The PV's own flag is ignored in favor of the claim's.
+	if NewSpecFromPersistentVolume(pv, false).IsReadOnly() {
+		t.Errorf("Expected the claim's ReadOnly flag to be used")
+	}
+	persistent := NewSpecFromPersistentVolume(pv, true)
+	if source := persistent.PersistentVolumeSource(); source == nil || source.RBD != rbd {
+		t.Errorf("Expected the PersistentVolume's RBD source, got %+v", source)
+	}
+	if !persistent.IsReadOnly() {
+		t.Errorf("Expected the claim's ReadOnly flag to be used")
+	}
+
+	if source := (&Spec{}).PersistentVolumeSource(); source != nil {
+		t.Errorf("Expected nil source for an empty spec, got %+v", source)
+	}
+}
+
+func TestSpecMountOptions(t *testing.T) {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			Name:        "bar",
+			Annotations: map[string]string{MountOptionAnnotation: "noatime, nfsvers=4.1,,ro"},
+		},
+	}
+	options := NewSpecFromPersistentVolume(pv, false).MountOptions()
+	if !reflect.DeepEqual(options, []string{"noatime", "nfsvers=4.1", "ro"}) {
+		t.Errorf("Unexpected mount options: %v", options)
+	}
+
+	inline := NewSpecFromVolume(&api.Volume{Name: "foo", VolumeSource: api.VolumeSource{NFS: &api.NFSVolumeSource{}}})
+	if options := inline.MountOptions(); options != nil {
+		t.Errorf("Expected no mount options for an inline volume, got %v", options)
+	}
+
+	joined := JoinMountOptions([]string{"noatime", "ro"}, []string{"ro", "log-file=/tmp/log"})
+	if !reflect.DeepEqual(joined, []string{"ro", "log-file=/tmp/log", "noatime"}) {
+		t.Errorf("Unexpected joined mount options: %v", joined)
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projected
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/configmap"
+	"k8s.io/kubernetes/pkg/volume/downwardapi"
+	"k8s.io/kubernetes/pkg/volume/secret"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// ProbeVolumePlugins is the entry point for plugin detection in a package.
+func ProbeVolumePlugins() []volume.VolumePlugin {
+	return []volume.VolumePlugin{&projectedPlugin{}}
+}
+
+const (
+	projectedPluginName = "kubernetes.io/projected"
+)
+
+// projectedPlugin implements the VolumePlugin interface.
+type projectedPlugin struct {
+	host volume.VolumeHost
+}
+
+var _ volume.VolumePlugin = &projectedPlugin{}
+
+func (plugin *projectedPlugin) Init(host volume.VolumeHost) {
+	plugin.host = host
+}
+
+func (plugin *projectedPlugin) Name() string {
+	return projectedPluginName
+}
+
+func (plugin *projectedPlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.Volume != nil && spec.Volume.Projected != nil
+}
+
+func (plugin *projectedPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.Projected == nil {
+		return "", fmt.Errorf("spec does not reference a projected volume")
+	}
+	return spec.Name(), nil
+}
+
+func (plugin *projectedPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
+	return &projectedVolumeBuilder{
+		projectedVolume: &projectedVolume{spec.Name(), pod.UID, plugin, plugin.host.GetMounter()},
+		source:          *spec.Volume.Projected,
+		pod:             *pod,
+		opts:            &opts}, nil
+}
+
+func (plugin *projectedPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
+	return &projectedVolumeCleaner{&projectedVolume{volName, podUID, plugin, plugin.host.GetMounter()}}, nil
+}
+
+type projectedVolume struct {
+	volName string
+	podUID  types.UID
+	plugin  *projectedPlugin
+	mounter mount.Interface
+}
+
+var _ volume.Volume = &projectedVolume{}
+
+func (pv *projectedVolume) GetPath() string {
+	return volume.NewDirectoryLayout(pv.plugin.host).PodVolumeDir(pv.podUID, projectedPluginName, pv.volName)
+}
+
+// projectedVolumeBuilder collects the files of all the sources of a
+// projected volume and writes them into the volume on the host at once.
+type projectedVolumeBuilder struct {
+	*projectedVolume
+
+	source api.ProjectedVolumeSource
+	pod    api.Pod
+	opts   *volume.VolumeOptions
+}
+
+var _ volume.Builder = &projectedVolumeBuilder{}
+var _ volume.ContentRefresher = &projectedVolumeBuilder{}
+
+// SetUp refreshes the files of the volume from its sources every time it is
+// called, like the secret and downward API volumes it replaces.
+func (b *projectedVolumeBuilder) SetUp() error {
+	return b.SetUpAt(b.GetPath())
+}
+
+// This is the spec for the volume that this plugin wraps.
+var wrappedVolumeSpec = &volume.Spec{
+	Volume: &api.Volume{VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{Medium: api.StorageMediumMemory}}},
+}
+
+func (b *projectedVolumeBuilder) getMetaDir() string {
+	return path.Join(volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.podUID, projectedPluginName), b.volName)
+}
+
+func (b *projectedVolumeBuilder) SetUpAt(dir string) error {
+	notMnt, err := b.mounter.IsLikelyNotMountPoint(dir)
+	// Getting an os.IsNotExist err from is a contingency; the directory
+	// may not exist yet, in which case, setup should run.
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// If the plugin readiness file is present for this volume and
+	// the setup dir is a mountpoint, the tmpfs is already set up and
+	// only the projected data is refreshed.
+	ready := volumeutil.IsReady(b.getMetaDir()) && !notMnt
+	if !ready {
+		glog.V(3).Infof("Setting up volume %v for pod %v at %v", b.volName, b.pod.UID, dir)
+
+		// Wrap EmptyDir, let it do the setup.
+		wrapped, err := b.plugin.host.NewWrapperBuilder(wrappedVolumeSpec, &b.pod, *b.opts)
+		if err != nil {
+			return err
+		}
+		if err := wrapped.SetUpAt(dir); err != nil {
+			return err
+		}
+	}
+
+	payload, err := b.collectData()
+	if err != nil {
+		glog.Errorf("Error preparing data for projected volume %v for pod %v/%v: %v", b.volName, b.pod.Namespace, b.pod.Name, err)
+		if ready {
+			// Keep serving the data already in the volume.
+			return nil
+		}
+		return err
+	}
+	writer, err := volume.NewAtomicWriter(dir, fmt.Sprintf("projected volume %v for pod %v", b.volName, b.pod.UID))
+	if err != nil {
+		return err
+	}
+	// The writer leaves the volume alone if no data changed.
+	if err := writer.Write(payload); err != nil {
+		glog.Errorf("Error writing projected data to %v: %v", dir, err)
+		return err
+	}
+
+	volumeutil.SetReady(b.getMetaDir())
+
+	return nil
+}
+
+// collectData returns the files of all the sources of the volume, each below
+// the path of its source.  Two sources projecting the same file is an error.
+func (b *projectedVolumeBuilder) collectData() (map[string]volume.FileProjection, error) {
+	payload := make(map[string]volume.FileProjection)
+	for i, source := range b.source.Sources {
+		var (
+			files map[string]volume.FileProjection
+			err   error
+		)
+		switch {
+		case source.Secret != nil:
+			files, err = b.collectSecretData(source.Secret)
+		case source.ConfigMap != nil:
+			files, err = b.collectConfigMapData(source.ConfigMap)
+		case source.DownwardAPI != nil:
+			files, err = downwardapi.CollectData(&b.pod, source.DownwardAPI.Items, b.source.DefaultMode)
+		default:
+			err = fmt.Errorf("source %d has no data source", i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for name, file := range files {
+			name = path.Join(source.Path, name)
+			if _, ok := payload[name]; ok {
+				return nil, fmt.Errorf("source %d projects %q, which is already projected by another source", i, name)
+			}
+			payload[name] = file
+		}
+	}
+	return payload, nil
+}
+
+func (b *projectedVolumeBuilder) collectSecretData(source *api.SecretVolumeSource) (map[string]volume.FileProjection, error) {
+	kubeClient := b.plugin.host.GetKubeClient()
+	if kubeClient == nil {
+		return nil, fmt.Errorf("Cannot setup projected volume %v because kube client is not configured", b.volName)
+	}
+	s, err := kubeClient.Secrets(b.pod.Namespace).Get(source.SecretName)
+	if err != nil {
+		glog.Errorf("Couldn't get secret %v/%v", b.pod.Namespace, source.SecretName)
+		return nil, err
+	}
+	return secret.MakePayload(source.Items, s, b.source.DefaultMode)
+}
+
+// collectConfigMapData projects the configMap of source with the default mode
+// of the volume, like the other sources of the volume.
+func (b *projectedVolumeBuilder) collectConfigMapData(source *api.ConfigMapVolumeSource) (map[string]volume.FileProjection, error) {
+	kubeClient := b.plugin.host.GetKubeClient()
+	if kubeClient == nil {
+		return nil, fmt.Errorf("Cannot setup projected volume %v because kube client is not configured", b.volName)
+	}
+	projection := *source
+	projection.DefaultMode = b.source.DefaultMode
+	return configmap.CollectData(kubeClient, b.pod.Namespace, &projection)
+}
+
+func (b *projectedVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            false,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeAlways,
+		SupportsSELinux:     true,
+	}
+}
+
+// RefreshesContent tells volume.EnforceReadOnly that SetUp rewrites the
+// files of the volume, which must stay writable therefore.
+func (pv *projectedVolume) RefreshesContent() bool {
+	return true
+}
+
+// projectedVolumeCleaner handles cleaning up projected volumes.
+type projectedVolumeCleaner struct {
+	*projectedVolume
+}
+
+var _ volume.Cleaner = &projectedVolumeCleaner{}
+
+func (c *projectedVolumeCleaner) TearDown() error {
+	return c.TearDownAt(c.GetPath())
+}
+
+func (c *projectedVolumeCleaner) TearDownAt(dir string) error {
+	glog.V(3).Infof("Tearing down volume %v for pod %v at %v", c.volName, c.podUID, dir)
+
+	// Wrap EmptyDir, let it do the teardown.
+	wrapped, err := c.plugin.host.NewWrapperCleaner(wrappedVolumeSpec, c.podUID)
+	if err != nil {
+		return err
+	}
+	return wrapped.TearDownAt(dir)
+}
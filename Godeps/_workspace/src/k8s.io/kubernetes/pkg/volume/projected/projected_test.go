@@ -0,0 +1,384 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projected
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/empty_dir"
+)
+
+func newTestHost(t *testing.T, client client.Interface) (string, volume.VolumeHost) {
+	tempDir, err := ioutil.TempDir("/tmp", "projected_volume_test.")
+	if err != nil {
+		t.Fatalf("can't make a temp rootdir: %v", err)
+	}
+
+	return tempDir, volume.NewFakeVolumeHost(tempDir, client, empty_dir.ProbeVolumePlugins())
+}
+
+func TestCanSupport(t *testing.T) {
+	pluginMgr := volume.VolumePluginMgr{}
+	_, host := newTestHost(t, nil)
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Errorf("Can't find the plugin by name")
+	}
+	if plugin.Name() != projectedPluginName {
+		t.Errorf("Wrong name: %s", plugin.Name())
+	}
+	if !plugin.CanSupport(&volume.Spec{Volume: &api.Volume{VolumeSource: api.VolumeSource{Projected: &api.ProjectedVolumeSource{}}}}) {
+		t.Errorf("Expected true")
+	}
+	if plugin.CanSupport(&volume.Spec{}) {
+		t.Errorf("Expected false")
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_projected_namespace"
+		testName       = "test_secret_name"
+
+		secret    = testSecret(testNamespace, testName)
+		client    = testclient.NewSimpleFake(&secret)
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+		mode      = int32(0400)
+	)
+	volumeSpec := volumeSpec(testVolumeName,
+		api.VolumeProjection{Secret: &api.SecretVolumeSource{SecretName: testName, Items: []api.KeyToPath{
+			{Key: "data-1", Path: "one"},
+			{Key: "data-2", Path: "dir/two", Mode: &mode},
+		}}},
+		api.VolumeProjection{Path: "podinfo", DownwardAPI: &api.DownwardAPIVolumeSource{Items: []api.DownwardAPIVolumeFile{
+			{Path: "namespace", FieldRef: api.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+			{Path: "name", FieldRef: api.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}}},
+	)
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{
+		UID:       testPodUID,
+		Name:      "test_pod_name",
+		Namespace: testNamespace,
+	}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+
+	volumePath := builder.GetPath()
+	if !strings.HasSuffix(volumePath, fmt.Sprintf("pods/test_pod_uid/volumes/kubernetes.io~projected/test_volume_name")) {
+		t.Errorf("Got unexpected path: %s", volumePath)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	doTestFilesInVolume(volumePath, map[string]expectedFile{
+		"one":               {"value-1", 0444},
+		"dir/two":           {"value-2", 0400},
+		"podinfo/namespace": {"test_projected_namespace", 0644},
+		"podinfo/name":      {"test_pod_name", 0644},
+		"data-3":            {},
+		"podinfo/one":       {},
+	}, t)
+	doTestCleanAndTeardown(plugin, testPodUID, testVolumeName, volumePath, t)
+}
+
+func TestPluginDefaultMode(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid2")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_projected_namespace"
+		testName       = "test_secret_name"
+
+		secret    = testSecret(testNamespace, testName)
+		client    = testclient.NewSimpleFake(&secret)
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+		mode      = int32(0440)
+	)
+	volumeSpec := volumeSpec(testVolumeName,
+		api.VolumeProjection{Path: "secret", Secret: &api.SecretVolumeSource{SecretName: testName}},
+		api.VolumeProjection{DownwardAPI: &api.DownwardAPIVolumeSource{Items: []api.DownwardAPIVolumeFile{
+			{Path: "name", FieldRef: api.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}}},
+	)
+	volumeSpec.Projected.DefaultMode = &mode
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Name: "test_pod_name", Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	doTestFilesInVolume(builder.GetPath(), map[string]expectedFile{
+		"secret/data-1": {"value-1", 0440},
+		"secret/data-3": {"value-3", 0440},
+		"name":          {"test_pod_name", 0440},
+	}, t)
+}
+
+func TestPluginConflict(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid3")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_projected_namespace"
+		testName       = "test_secret_name"
+
+		secret    = testSecret(testNamespace, testName)
+		client    = testclient.NewSimpleFake(&secret)
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+	)
+	volumeSpec := volumeSpec(testVolumeName,
+		api.VolumeProjection{Secret: &api.SecretVolumeSource{SecretName: testName}},
+		api.VolumeProjection{DownwardAPI: &api.DownwardAPIVolumeSource{Items: []api.DownwardAPIVolumeFile{
+			{Path: "data-2", FieldRef: api.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}}},
+	)
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Name: "test_pod_name", Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error for sources projecting the same file")
+	}
+}
+
+func TestPluginConfigMap(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid4")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_projected_namespace"
+		testName       = "test_configmap_name"
+
+		configMap = api.ConfigMap{
+			ObjectMeta: api.ObjectMeta{Namespace: testNamespace, Name: testName},
+			Data:       map[string]string{"key-1": "value-1", "key-2": "value-2"},
+		}
+		client    = testclient.NewSimpleFake(&configMap)
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+		optional  = true
+	)
+	volumeSpec := volumeSpec(testVolumeName,
+		api.VolumeProjection{Path: "config", ConfigMap: &api.ConfigMapVolumeSource{
+			LocalObjectReference: api.LocalObjectReference{Name: testName},
+			Items:                []api.KeyToPath{{Key: "key-1", Path: "one"}},
+		}},
+		api.VolumeProjection{Path: "missing", ConfigMap: &api.ConfigMapVolumeSource{
+			LocalObjectReference: api.LocalObjectReference{Name: "missing"},
+			Optional:             &optional,
+		}},
+	)
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Name: "test_pod_name", Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	doTestFilesInVolume(builder.GetPath(), map[string]expectedFile{
+		"config/one":   {"value-1", 0644},
+		"config/key-2": {},
+	}, t)
+}
+
+func TestPluginRefresh(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid4")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_projected_namespace"
+		testName       = "test_secret_name"
+
+		secret    = testSecret(testNamespace, testName)
+		client    = &testclient.Fake{}
+		pluginMgr = volume.VolumePluginMgr{}
+		_, host   = newTestHost(t, client)
+		getErr    error
+	)
+	client.AddReactor("get", "secrets", func(action testclient.Action) (bool, runtime.Object, error) {
+		if getErr != nil {
+			return true, nil, getErr
+		}
+		return true, &secret, nil
+	})
+	volumeSpec := volumeSpec(testVolumeName,
+		api.VolumeProjection{Secret: &api.SecretVolumeSource{SecretName: testName}},
+		api.VolumeProjection{DownwardAPI: &api.DownwardAPIVolumeSource{Items: []api.DownwardAPIVolumeFile{
+			{Path: "name", FieldRef: api.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}}},
+	)
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(projectedPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID, Name: "test_pod_name", Namespace: testNamespace}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	volumePath := builder.GetPath()
+
+	// The fake mounter doesn't remember the mount, pretend it does.
+	host.GetMounter().(*mount.FakeMounter).MountPoints = []mount.MountPoint{{Path: volumePath}}
+	secret.Data = map[string][]byte{"data-1": []byte("new-value-1")}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	doTestFilesInVolume(volumePath, map[string]expectedFile{
+		"data-1": {"new-value-1", 0444},
+		"data-2": {},
+		"name":   {"test_pod_name", 0644},
+	}, t)
+
+	// A volume already set up keeps its data if the secret can't be read.
+	getErr = errors.New("unavailable")
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Expected the volume to keep its data, got %v", err)
+	}
+	doTestFilesInVolume(volumePath, map[string]expectedFile{
+		"data-1": {"new-value-1", 0444},
+		"name":   {"test_pod_name", 0644},
+	}, t)
+}
+
+func volumeSpec(volumeName string, sources ...api.VolumeProjection) *api.Volume {
+	return &api.Volume{
+		Name: volumeName,
+		VolumeSource: api.VolumeSource{
+			Projected: &api.ProjectedVolumeSource{
+				Sources: sources,
+			},
+		},
+	}
+}
+
+func testSecret(namespace, name string) api.Secret {
+	return api.Secret{
+		ObjectMeta: api.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			"data-1": []byte("value-1"),
+			"data-2": []byte("value-2"),
+			"data-3": []byte("value-3"),
+		},
+	}
+}
+
+// expectedFile is the content and mode of a file expected in a volume; the
+// zero value expects the file not to exist.
+type expectedFile struct {
+	value string
+	mode  os.FileMode
+}
+
+func doTestFilesInVolume(volumePath string, files map[string]expectedFile, t *testing.T) {
+	for file, expected := range files {
+		filePath := path.Join(volumePath, file)
+		if expected.mode == 0 {
+			if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+				t.Errorf("Expected %v not to be projected, got %v", file, err)
+			}
+			continue
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Errorf("Couldn't read %v: %v", filePath, err)
+			continue
+		}
+		if string(data) != expected.value {
+			t.Errorf("Unexpected value of %v; expected %q, got %q", file, expected.value, data)
+		}
+		if info, err := os.Stat(filePath); err != nil || info.Mode().Perm() != expected.mode {
+			t.Errorf("Expected %v to have mode %v, got %v, %v", file, expected.mode, info, err)
+		}
+	}
+}
+
+func doTestCleanAndTeardown(plugin volume.VolumePlugin, podUID types.UID, testVolumeName, volumePath string, t *testing.T) {
+	cleaner, err := plugin.NewCleaner(testVolumeName, podUID)
+	if err != nil {
+		t.Errorf("Failed to make a new Cleaner: %v", err)
+	}
+	if cleaner == nil {
+		t.Errorf("Got a nil Cleaner")
+	}
+
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if _, err := os.Stat(volumePath); err == nil {
+		t.Errorf("TearDown() failed, volume path still exists: %s", volumePath)
+	} else if !os.IsNotExist(err) {
+		t.Errorf("SetUp() failed: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// MountPropagationMode is how mounts made inside a volume propagate between
+// the host and the containers using it.
+type MountPropagationMode string
+
+const (
+	// MountPropagationNone keeps mounts private to the host or the
+	// container that made them.
+	MountPropagationNone MountPropagationMode = "None"
+	// MountPropagationHostToContainer passes mounts made on the host
+	// inside the volume on to the containers, but not the other way.
+	MountPropagationHostToContainer MountPropagationMode = "HostToContainer"
+	// MountPropagationBidirectional also passes mounts made by containers
+	// inside the volume on to the host, e.g. for plugins whose container
+	// runtime mounts devices in the volume.
+	MountPropagationBidirectional MountPropagationMode = "Bidirectional"
+)
+
+// makePropagation marks the mount at a path and the mounts below it shared
+// or slave; replaced in tests.
+var makePropagation = makePropagationInPlace
+
+// SetUpMountPropagation marks the mount at the path of builder, which must
+// have been set up, as the MountPropagation attribute of the builder asks:
+// rslave for HostToContainer and rshared for Bidirectional.  Volumes with
+// no propagation are left private.  The volume must be mounted at its path,
+// as the mode of the mount containing it would apply to other volumes too.
+func SetUpMountPropagation(builder Builder) error {
+	mode := builder.GetAttributes().MountPropagation
+	var shared bool
+	switch mode {
+	case "", MountPropagationNone:
+		return nil
+	case MountPropagationHostToContainer:
+		shared = false
+	case MountPropagationBidirectional:
+		shared = true
+	default:
+		return fmt.Errorf("unknown mount propagation mode %q", mode)
+	}
+
+	path := filepath.Clean(builder.GetPath())
+	info, err := volumeutil.GetMountInfo(mountInfoPath, path)
+	if err != nil {
+		return err
+	}
+	if info.MountPoint != path {
+		return fmt.Errorf("volume %s asks for %s mount propagation but is not mounted at its path", path, mode)
+	}
+	glog.V(3).Infof("Setting mount propagation of volume %s to %s", path, mode)
+	if err := makePropagation(path, shared); err != nil {
+		return fmt.Errorf("failed to set mount propagation of volume %s to %s: %v", path, mode, err)
+	}
+	return nil
+}
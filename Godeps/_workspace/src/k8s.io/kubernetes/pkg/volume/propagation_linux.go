@@ -0,0 +1,33 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import "syscall"
+
+// makePropagationInPlace marks the mount at path and the mounts below it
+// rshared, or rslave if shared is false.  Only the propagation type of the
+// mounts changes, so an rslave mount keeps receiving the mounts of its
+// master.
+func makePropagationInPlace(path string, shared bool) error {
+	flags := uintptr(syscall.MS_REC | syscall.MS_SLAVE)
+	if shared {
+		flags = syscall.MS_REC | syscall.MS_SHARED
+	}
+	return syscall.Mount("", path, "", flags, "")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakePropagationBuilder struct {
+	FakeVolume
+	path string
+	mode MountPropagationMode
+}
+
+func (b *fakePropagationBuilder) GetPath() string {
+	return b.path
+}
+
+func (b *fakePropagationBuilder) GetAttributes() Attributes {
+	return Attributes{MountPropagation: b.mode}
+}
+
+type propagationCall struct {
+	path   string
+	shared bool
+}
+
+func TestSetUpMountPropagation(t *testing.T) {
+	defer withFakeMountInfo(t, fakeMountInfo)()
+	defer func(make func(string, bool) error) { makePropagation = make }(makePropagation)
+	calls := []propagationCall{}
+	makePropagation = func(path string, shared bool) error {
+		calls = append(calls, propagationCall{path, shared})
+		return nil
+	}
+
+	vol := "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~nfs/rw-vol"
+	tests := []struct {
+		builder     Builder
+		calls       []propagationCall
+		expectError bool
+	}{
+		{&fakePropagationBuilder{path: vol}, []propagationCall{}, false},
+		{&fakePropagationBuilder{path: vol, mode: MountPropagationNone}, []propagationCall{}, false},
+		{&fakePropagationBuilder{path: vol, mode: MountPropagationHostToContainer}, []propagationCall{{vol, false}}, false},
+		{&fakePropagationBuilder{path: vol + "/", mode: MountPropagationBidirectional}, []propagationCall{{vol, true}}, false},
+		{&fakePropagationBuilder{path: vol, mode: "Sideways"}, []propagationCall{}, true},
+		// Not a mount point of its own.
+		{&fakePropagationBuilder{path: "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~empty-dir/disk", mode: MountPropagationBidirectional}, []propagationCall{}, true},
+	}
+	for _, test := range tests {
+		calls = []propagationCall{}
+		err := SetUpMountPropagation(test.builder)
+		if (err != nil) != test.expectError {
+			t.Errorf("%s %q: expected error=%t, got %v", test.builder.GetPath(), test.builder.GetAttributes().MountPropagation, test.expectError, err)
+		}
+		if !reflect.DeepEqual(calls, test.calls) {
+			t.Errorf("%s %q: expected %v, got %v", test.builder.GetPath(), test.builder.GetAttributes().MountPropagation, test.calls, calls)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"sync"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// ProvisionRateLimit limits the calls made to a storage backend.  A QPS of 0
+// means no limit.
+type ProvisionRateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// ProvisionControllerConfig configures a ProvisionController.
+type ProvisionControllerConfig struct {
+	// Workers is how many provisions run at once, across all backends.  0
+	// means no limit.
+	Workers int
+	// DefaultLimit limits the provisions of each backend without a limit in
+	// BackendLimits.
+	DefaultLimit ProvisionRateLimit
+	// BackendLimits are the limits of backends, keyed by the name of their
+	// plugin.
+	BackendLimits map[string]ProvisionRateLimit
+}
+
+// ProvisionController limits the provisions of volumes, so that a burst of
+// claims does not trip the throttling of cloud provider APIs.  Provisions run
+// on a bounded number of workers and at a bounded rate per backend, and a
+// provision for a claim whose provision is already running is not run again.
+// It is safe for concurrent use, and shared by every caller provisioning
+// through the same backends.
+type ProvisionController struct {
+	config  ProvisionControllerConfig
+	workers chan struct{}
+
+	lock     sync.Mutex
+	limiters map[string]util.RateLimiter
+	inFlight map[string]*provisionCall
+}
+
+// provisionCall is a provision that is running, whose result every caller
+// provisioning the same claim gets.
+type provisionCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewProvisionController creates a ProvisionController limited by config.
+func NewProvisionController(config ProvisionControllerConfig) *ProvisionController {
+	c := &ProvisionController{
+		config:   config,
+		limiters: map[string]util.RateLimiter{},
+		inFlight: map[string]*provisionCall{},
+	}
+	if config.Workers > 0 {
+		c.workers = make(chan struct{}, config.Workers)
+	}
+	return c
+}
+
+// Provision calls provision, which provisions the volume of the claim with
+// key claimKey, e.g. namespace/name, through the plugin named backend, once
+// a worker is free and the rate limit of backend allows it, and returns its
+// result.  If a provision for claimKey is already running, provision is not
+// called; the result of the running provision is returned instead.
+func (c *ProvisionController) Provision(backend, claimKey string, provision func() error) error {
+	c.lock.Lock()
+	if call, found := c.inFlight[claimKey]; found {
+		c.lock.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &provisionCall{done: make(chan struct{})}
+	c.inFlight[claimKey] = call
+	limiter := c.limiterLocked(backend)
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.inFlight, claimKey)
+		c.lock.Unlock()
+		close(call.done)
+	}()
+
+	// A worker is only taken once the backend allows the call, so that a
+	// throttled backend does not keep the provisions of others waiting.
+	limiter.Accept()
+	if c.workers != nil {
+		c.workers <- struct{}{}
+		defer func() { <-c.workers }()
+	}
+	call.err = provision()
+	return call.err
+}
+
+// limiterLocked returns the rate limiter of backend, creating it on first
+// use.  c.lock must be held.
+func (c *ProvisionController) limiterLocked(backend string) util.RateLimiter {
+	if limiter, found := c.limiters[backend]; found {
+		return limiter
+	}
+	limit, found := c.config.BackendLimits[backend]
+	if !found {
+		limit = c.config.DefaultLimit
+	}
+	var limiter util.RateLimiter
+	if limit.QPS > 0 {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = util.NewTokenBucketRateLimiter(limit.QPS, burst)
+	} else {
+		limiter = util.NewFakeRateLimiter()
+	}
+	c.limiters[backend] = limiter
+	return limiter
+}
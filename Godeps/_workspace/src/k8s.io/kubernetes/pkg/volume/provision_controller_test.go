@@ -0,0 +1,118 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProvisionControllerDeduplicates(t *testing.T) {
+	c := NewProvisionController(ProvisionControllerConfig{Workers: 2})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	calls := 0
+	provision := func() error {
+		calls++
+		close(started)
+		<-release
+		return fmt.Errorf("quota exceeded")
+	}
+
+	results := make(chan error, 2)
+	go func() { results <- c.Provision("kubernetes.io/fake", "default/claim", provision) }()
+	<-started
+	go func() { results <- c.Provision("kubernetes.io/fake", "default/claim", provision) }()
+	// Let the second call find the first before it finishes.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-results; err == nil || err.Error() != "quota exceeded" {
+			t.Errorf("Expected the error of the first provision, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one provision, got %d", calls)
+	}
+
+	// Once the first provision returned, the claim is provisioned again.
+	if err := c.Provision("kubernetes.io/fake", "default/claim", func() error { return nil }); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestProvisionControllerLimitsWorkers(t *testing.T) {
+	c := NewProvisionController(ProvisionControllerConfig{Workers: 2})
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	provision := func() error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := c.Provision("kubernetes.io/fake", fmt.Sprintf("default/claim%d", i), provision); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if maxRunning != 2 {
+		t.Errorf("Expected at most 2 provisions at once, got %d", maxRunning)
+	}
+}
+
+func TestProvisionControllerBackendLimits(t *testing.T) {
+	c := NewProvisionController(ProvisionControllerConfig{
+		BackendLimits: map[string]ProvisionRateLimit{
+			"kubernetes.io/slow": {QPS: 0.001, Burst: 1},
+		},
+	})
+	provision := func() error { return nil }
+
+	for _, backend := range []string{"kubernetes.io/slow", "kubernetes.io/fast"} {
+		if err := c.Provision(backend, "default/"+backend, provision); err != nil {
+			t.Errorf("%s: unexpected error: %v", backend, err)
+		}
+	}
+	// The only token of the slow backend was taken, while the default of
+	// the fast backend is not to limit it.
+	if c.limiters["kubernetes.io/slow"].CanAccept() {
+		t.Errorf("Expected the slow backend to be throttled")
+	}
+	if !c.limiters["kubernetes.io/fast"].CanAccept() {
+		t.Errorf("Expected the fast backend not to be throttled")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+const (
+	// Project IDs of volume directories are taken from [minProjectID, minProjectID+projectIDRange), leaving
+	// lower IDs to the administrator of the node.
+	minProjectID   = 1 << 20
+	projectIDRange = 1 << 30
+)
+
+// filesystemFeatures is swapped in tests.
+var filesystemFeatures = FilesystemFeatures
+
+// QuotaApplier limits and accounts for the space used by directory backed volumes, such as emptyDir,
+// which share the filesystem of the node and could otherwise fill it.
+type QuotaApplier interface {
+	// SupportsQuotas returns whether quotas can be assigned to directories at path.
+	SupportsQuotas(path string) (bool, error)
+	// AssignQuota limits the space used by the files under the directory at path to bytes.
+	AssignQuota(path string, bytes int64) error
+	// GetQuotaUsage returns the space and inodes used by the files under the directory at path, as
+	// accounted by its quota.  This is cheap compared to walking the directory.
+	GetQuotaUsage(path string) (used int64, inodesUsed int64, err error)
+	// ClearQuota removes the quota of the directory at path, if any.
+	ClearQuota(path string) error
+}
+
+// ProjectQuota is a QuotaApplier using the project quotas of XFS, or of ext4, both managed with
+// xfs_quota.  The filesystem must be mounted with project quotas enabled, e.g. with prjquota.  Each
+// directory is given the project ID hashed from its path, so that the ID is found again after a restart
+// of the kubelet without keeping any state.
+type ProjectQuota struct {
+	Runner exec.Interface
+}
+
+var _ QuotaApplier = &ProjectQuota{}
+
+// NewProjectQuota returns a ProjectQuota running the real xfs_quota.
+func NewProjectQuota() *ProjectQuota {
+	return &ProjectQuota{Runner: exec.New()}
+}
+
+func (q *ProjectQuota) SupportsQuotas(path string) (bool, error) {
+	features, err := filesystemFeatures(path)
+	if err != nil {
+		return false, err
+	}
+	return features.SupportsProjectQuota, nil
+}
+
+func (q *ProjectQuota) AssignQuota(path string, bytes int64) error {
+	id := projectID(path)
+	if _, err := q.xfsQuota(path, fmt.Sprintf("project -s -p %s %d", path, id)); err != nil {
+		return err
+	}
+	_, err := q.xfsQuota(path, fmt.Sprintf("limit -p bhard=%d %d", bytes, id))
+	return err
+}
+
+func (q *ProjectQuota) GetQuotaUsage(path string) (int64, int64, error) {
+	id := projectID(path)
+	// Blocks are reported in KiB.
+	blocks, err := q.quotaReport(path, fmt.Sprintf("quota -p -N -n -b %d", id))
+	if err != nil {
+		return 0, 0, err
+	}
+	inodes, err := q.quotaReport(path, fmt.Sprintf("quota -p -N -n -i %d", id))
+	if err != nil {
+		return 0, 0, err
+	}
+	return blocks * 1024, inodes, nil
+}
+
+func (q *ProjectQuota) ClearQuota(path string) error {
+	supported, err := q.SupportsQuotas(path)
+	if err != nil || !supported {
+		return err
+	}
+	id := projectID(path)
+	if _, err := q.xfsQuota(path, fmt.Sprintf("limit -p bhard=0 %d", id)); err != nil {
+		return err
+	}
+	_, err = q.xfsQuota(path, fmt.Sprintf("project -C -p %s %d", path, id))
+	return err
+}
+
+// quotaReport runs an xfs_quota quota command with a single line of output, and returns the number in
+// its second column, the first being the device.
+func (q *ProjectQuota) quotaReport(path, command string) (int64, error) {
+	output, err := q.xfsQuota(path, command)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected output of xfs_quota %q for %s: %q", command, path, string(output))
+	}
+	n, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output of xfs_quota %q for %s: %q", command, path, string(output))
+	}
+	return n, nil
+}
+
+// xfsQuota runs an expert xfs_quota command on the filesystem containing path.
+func (q *ProjectQuota) xfsQuota(path, command string) ([]byte, error) {
+	info, err := volumeutil.GetMountInfo(mountInfoPath, path)
+	if err != nil {
+		return nil, fmt.Errorf("can't find the filesystem of %s: %v", path, err)
+	}
+	output, err := q.Runner.Command("xfs_quota", "-x", "-c", command, info.MountPoint).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("xfs_quota %q failed for %s: %v, output: %q", command, path, err, string(output))
+	}
+	return output, nil
+}
+
+// projectID returns the project ID of the directory at path.
+func projectID(path string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return minProjectID + h.Sum32()%projectIDRange
+}
+
+// MetricsQuota reports the usage of a directory backed volume with a quota, reading the space and inodes
+// used from the quota instead of walking the directory like MetricsDu does.  Capacity is the quota, unless
+// the underlying filesystem is smaller.
+type MetricsQuota struct {
+	Path  string
+	Quota QuotaApplier
+	// Limit is the size of the quota of Path in bytes.
+	Limit int64
+}
+
+var _ MetricsProvider = &MetricsQuota{}
+
+func (m *MetricsQuota) GetMetrics() (*Metrics, error) {
+	metrics, err := statfsMetrics(m.Path)
+	if err != nil {
+		return nil, err
+	}
+	if metrics.Used, metrics.InodesUsed, err = m.Quota.GetQuotaUsage(m.Path); err != nil {
+		return nil, err
+	}
+	if m.Limit > 0 && m.Limit < metrics.Capacity {
+		metrics.Capacity = m.Limit
+		if available := m.Limit - metrics.Used; available < metrics.Available {
+			metrics.Available = available
+		}
+		if metrics.Available < 0 {
+			metrics.Available = 0
+		}
+	}
+	return metrics, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestProjectQuota(t *testing.T) {
+	f, err := ioutil.TempFile("", "mountinfo")
+	if err != nil {
+		t.Fatalf("Can't make a temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	fmt.Fprintln(f, "36 35 8:16 / /var/lib/kubelet rw,relatime shared:1 - xfs /dev/sdb rw,prjquota")
+	f.Close()
+	oldMountInfo, oldFeatures := mountInfoPath, filesystemFeatures
+	defer func() { mountInfoPath, filesystemFeatures = oldMountInfo, oldFeatures }()
+	mountInfoPath = f.Name()
+	filesystemFeatures = func(path string) (*FSFeatures, error) {
+		return &FSFeatures{SupportsProjectQuota: true}, nil
+	}
+
+	dir := "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~empty-dir/vol"
+	id := projectID(dir)
+	if id < minProjectID || id != projectID(dir) {
+		t.Errorf("Expected a stable project ID out of the administrator's range, got %d", id)
+	}
+	var argv [][]string
+	q := &ProjectQuota{Runner: newFakeRunner([]fakeCommand{
+		{}, {},
+		{output: "/dev/sdb 2048 0 1048576 00 [--------] /var/lib/kubelet\n"},
+		{output: "/dev/sdb 12 0 0 00 [--------] /var/lib/kubelet\n"},
+		{}, {},
+	}, &argv)}
+
+	if supported, err := q.SupportsQuotas(dir); err != nil || !supported {
+		t.Errorf("Expected quotas to be supported, got %v, %v", supported, err)
+	}
+	if err := q.AssignQuota(dir, 1<<30); err != nil {
+		t.Fatalf("AssignQuota() failed: %v", err)
+	}
+	used, inodes, err := q.GetQuotaUsage(dir)
+	if err != nil || used != 2048*1024 || inodes != 12 {
+		t.Errorf("Unexpected usage %d bytes, %d inodes, %v", used, inodes, err)
+	}
+	if err := q.ClearQuota(dir); err != nil {
+		t.Fatalf("ClearQuota() failed: %v", err)
+	}
+	expected := [][]string{
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("project -s -p %s %d", dir, id), "/var/lib/kubelet"},
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=1073741824 %d", id), "/var/lib/kubelet"},
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("quota -p -N -n -b %d", id), "/var/lib/kubelet"},
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("quota -p -N -n -i %d", id), "/var/lib/kubelet"},
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=0 %d", id), "/var/lib/kubelet"},
+		{"xfs_quota", "-x", "-c", fmt.Sprintf("project -C -p %s %d", dir, id), "/var/lib/kubelet"},
+	}
+	if !reflect.DeepEqual(argv, expected) {
+		t.Errorf("Unexpected commands:\n%v\nexpected:\n%v", argv, expected)
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/util/mount"
+	"k8s.io/kubernetes/pkg/volume"
 )
 
 // Abstract interface to disk operations.
@@ -36,6 +37,12 @@ type diskManager interface {
 	AttachDisk(disk rbdBuilder) error
 	// Detaches the disk from the kubelet's host machine.
 	DetachDisk(disk rbdCleaner, mntPath string) error
+	// Creates the provisioner's rbd image.
+	CreateImage(provisioner *rbdVolumeProvisioner) (sizeMB int64, err error)
+	// Creates the provisioner's rbd image as a copy of its clone source.
+	CloneImage(provisioner *rbdVolumeProvisioner) (sizeMB int64, err error)
+	// Deletes a rbd image.
+	DeleteImage(deleter *rbdVolumeDeleter) error
 }
 
 // utility to mount a disk based filesystem
@@ -62,12 +69,13 @@ func diskSetUp(manager diskManager, b rbdBuilder, volPath string, mounter mount.
 	}
 	// Perform a bind mount to the full path to allow duplicate mounts of the same disk.
 	options := []string{"bind"}
-	if b.IsReadOnly() {
+	if b.ReadOnly {
 		options = append(options, "ro")
 	}
 	err = mounter.Mount(globalPDPath, volPath, "", options)
 	if err != nil {
 		glog.Errorf("failed to bind mount:%s", globalPDPath)
+		volume.CleanupStagingDir(volPath, err)
 		return err
 	}
 	return nil
@@ -84,7 +92,7 @@ func diskTearDown(manager diskManager, c rbdCleaner, volPath string, mounter mou
 		return os.Remove(volPath)
 	}
 
-	refs, err := mount.GetMountRefs(mounter, volPath)
+	_, refs, err := mount.PathExistsWithRefs(mounter, volPath)
 	if err != nil {
 		glog.Errorf("failed to get reference count %s", volPath)
 		return err
@@ -93,10 +101,9 @@ func diskTearDown(manager diskManager, c rbdCleaner, volPath string, mounter mou
 		glog.Errorf("failed to umount %s", volPath)
 		return err
 	}
-	// If len(refs) is 1, then all bind mounts have been removed, and the
-	// remaining reference is the global mount. It is safe to detach.
-	if len(refs) == 1 {
-		mntPath := refs[0]
+	// If the global mount is the last reference, then no other pod uses
+	// the disk anymore. It is safe to detach.
+	if mntPath, ok := volume.LastGlobalMountRef(c.plugin.host, refs); ok {
 		if err := manager.DetachDisk(c, mntPath); err != nil {
 			glog.Errorf("failed to detach disk from %s", mntPath)
 			return err
@@ -18,9 +18,11 @@ package rbd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/exec"
@@ -29,22 +31,40 @@ import (
 )
 
 // This is the primary entrypoint for volume plugins.
-func ProbeVolumePlugins() []volume.VolumePlugin {
-	return []volume.VolumePlugin{&rbdPlugin{nil, exec.New()}}
+// The volumeConfig arg configures where and as whom new images are provisioned, see the
+// RBDProvisioner* keys of its OtherAttributes.  It is unused by kubelets, which only mount images.
+func ProbeVolumePlugins(volumeConfig volume.VolumeConfig) []volume.VolumePlugin {
+	return []volume.VolumePlugin{&rbdPlugin{nil, exec.New(), volumeConfig}}
 }
 
 type rbdPlugin struct {
-	host volume.VolumeHost
-	exe  exec.Interface
+	host   volume.VolumeHost
+	exe    exec.Interface
+	config volume.VolumeConfig
 }
 
 var _ volume.VolumePlugin = &rbdPlugin{}
 var _ volume.PersistentVolumePlugin = &rbdPlugin{}
+var _ volume.DeletableVolumePlugin = &rbdPlugin{}
+var _ volume.ProvisionableVolumePlugin = &rbdPlugin{}
 
 const (
 	rbdPluginName = "kubernetes.io/rbd"
 )
 
+// Keys of VolumeConfig.OtherAttributes that configure provisioning.  Provisioned
+// volumes are mounted with the same user and keyring that created them.
+const (
+	// RBDProvisionerMonitors is a comma separated list of ceph monitors.
+	RBDProvisionerMonitors = "rbd.monitors"
+	// RBDProvisionerPool is the pool new images are created in, default is rbd.
+	RBDProvisionerPool = "rbd.pool"
+	// RBDProvisionerUser is the rados user that creates images, default is admin.
+	RBDProvisionerUser = "rbd.user"
+	// RBDProvisionerKeyring is the path to the key ring for RBDProvisionerUser, default is /etc/ceph/keyring.
+	RBDProvisionerKeyring = "rbd.keyring"
+)
+
 func (plugin *rbdPlugin) Init(host volume.VolumeHost) {
 	plugin.host = host
 }
@@ -66,6 +86,14 @@ func (plugin *rbdPlugin) CanSupport(spec *volume.Spec) bool {
 	return false
 }
 
+func (plugin *rbdPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	source := spec.PersistentVolumeSource()
+	if source == nil || source.RBD == nil {
+		return "", fmt.Errorf("spec does not reference a rbd volume")
+	}
+	return fmt.Sprintf("%s:%s", source.RBD.RBDPool, source.RBD.RBDImage), nil
+}
+
 func (plugin *rbdPlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{
 		api.ReadWriteOnce,
@@ -99,13 +127,7 @@ func (plugin *rbdPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, _ volume.Vo
 }
 
 func (plugin *rbdPlugin) getRBDVolumeSource(spec *volume.Spec) (*api.RBDVolumeSource, bool) {
-	// rbd volumes used directly in a pod have a ReadOnly flag set by the pod author.
-	// rbd volumes used as a PersistentVolume gets the ReadOnly flag indirectly through the persistent-claim volume used to mount the PV
-	if spec.Volume != nil && spec.Volume.RBD != nil {
-		return spec.Volume.RBD, spec.Volume.RBD.ReadOnly
-	} else {
-		return spec.PersistentVolume.Spec.RBD, spec.ReadOnly
-	}
+	return spec.PersistentVolumeSource().RBD, spec.IsReadOnly()
 }
 
 func (plugin *rbdPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID, manager diskManager, mounter mount.Interface, secret string) (volume.Builder, error) {
@@ -123,7 +145,7 @@ func (plugin *rbdPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID,
 		keyring = "/etc/ceph/keyring"
 	}
 
-	return &rbdBuilder{
+	builder := &rbdBuilder{
 		rbd: &rbd{
 			podUID:   podUID,
 			volName:  spec.Name(),
@@ -131,7 +153,7 @@ func (plugin *rbdPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID,
 			Pool:     pool,
 			ReadOnly: readOnly,
 			manager:  manager,
-			mounter:  &mount.SafeFormatAndMount{mounter, exec.New()},
+			mounter:  &volume.SafeFormatAndMount{Interface: mounter, Runner: exec.New()},
 			plugin:   plugin,
 		},
 		Mon:     source.CephMonitors,
@@ -139,27 +161,93 @@ func (plugin *rbdPlugin) newBuilderInternal(spec *volume.Spec, podUID types.UID,
 		Keyring: keyring,
 		Secret:  secret,
 		fsType:  source.FSType,
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return builder, nil
+}
+
+func (plugin *rbdPlugin) NewDeleter(spec *volume.Spec) (volume.Deleter, error) {
+	return plugin.newDeleterInternal(spec, &RBDUtil{})
+}
+
+func (plugin *rbdPlugin) newDeleterInternal(spec *volume.Spec, manager diskManager) (volume.Deleter, error) {
+	if spec.PersistentVolume == nil || spec.PersistentVolume.Spec.RBD == nil {
+		return nil, fmt.Errorf("spec.PersistentVolumeSource.RBD is nil")
+	}
+	builder, err := plugin.newBuilderInternal(spec, "", manager, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return &rbdVolumeDeleter{builder.(*rbdBuilder)}, nil
+}
+
+func (plugin *rbdPlugin) SupportsCloning() bool {
+	return true
+}
+
+func (plugin *rbdPlugin) NewProvisioner(options volume.VolumeOptions) (volume.Provisioner, error) {
+	if len(options.AccessModes) == 0 {
+		options.AccessModes = plugin.GetAccessModes()
+	}
+	return plugin.newProvisionerInternal(options, &RBDUtil{})
+}
+
+func (plugin *rbdPlugin) newProvisionerInternal(options volume.VolumeOptions, manager diskManager) (volume.Provisioner, error) {
+	source := plugin.getProvisionerSource()
+	if len(source.CephMonitors) == 0 {
+		return nil, fmt.Errorf("rbd: %s must be configured to provision images", RBDProvisionerMonitors)
+	}
+	spec := volume.NewSpecFromPersistentVolume(&api.PersistentVolume{
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{RBD: source},
+		},
+	}, false)
+	builder, err := plugin.newBuilderInternal(spec, "", manager, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return &rbdVolumeProvisioner{
+		rbdBuilder: builder.(*rbdBuilder),
+		options:    options,
 	}, nil
 }
 
+// getProvisionerSource returns the volume source that provisioned images
+// are reached through, less the image name.
+func (plugin *rbdPlugin) getProvisionerSource() *api.RBDVolumeSource {
+	attrs := plugin.config.OtherAttributes
+	source := &api.RBDVolumeSource{
+		RBDPool:   attrs[RBDProvisionerPool],
+		RadosUser: attrs[RBDProvisionerUser],
+		Keyring:   attrs[RBDProvisionerKeyring],
+		FSType:    "ext4",
+	}
+	for _, mon := range strings.Split(attrs[RBDProvisionerMonitors], ",") {
+		if mon = strings.TrimSpace(mon); mon != "" {
+			source.CephMonitors = append(source.CephMonitors, mon)
+		}
+	}
+	return source
+}
+
 func (plugin *rbdPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
 	// Inject real implementations here, test through the internal function.
 	return plugin.newCleanerInternal(volName, podUID, &RBDUtil{}, plugin.host.GetMounter())
 }
 
 func (plugin *rbdPlugin) newCleanerInternal(volName string, podUID types.UID, manager diskManager, mounter mount.Interface) (volume.Cleaner, error) {
-	return &rbdCleaner{
-		rbdBuilder: &rbdBuilder{
-			rbd: &rbd{
-				podUID:  podUID,
-				volName: volName,
-				manager: manager,
-				mounter: mounter,
-				plugin:  plugin,
-			},
-			Mon: make([]string, 0),
+	builder := &rbdBuilder{
+		rbd: &rbd{
+			podUID:  podUID,
+			volName: volName,
+			manager: manager,
+			mounter: mounter,
+			plugin:  plugin,
 		},
-	}, nil
+		Mon: make([]string, 0),
+	}
+	builder.MetricsProvider = volume.NewMetricsStatFS(builder.GetPath())
+	return &rbdCleaner{rbdBuilder: builder}, nil
 }
 
 type rbd struct {
@@ -177,11 +265,12 @@ type rbd struct {
 func (rbd *rbd) GetPath() string {
 	name := rbdPluginName
 	// safe to use PodVolumeDir now: volume teardown occurs before pod is cleaned up
-	return rbd.plugin.host.GetPodVolumeDir(rbd.podUID, util.EscapeQualifiedNameForDisk(name), rbd.volName)
+	return volume.NewDirectoryLayout(rbd.plugin.host).PodVolumeDir(rbd.podUID, name, rbd.volName)
 }
 
 type rbdBuilder struct {
 	*rbd
+	volume.MetricsProvider
 	// capitalized so they can be exported in persistRBD()
 	Mon     []string
 	Id      string
@@ -191,10 +280,8 @@ type rbdBuilder struct {
 }
 
 var _ volume.Builder = &rbdBuilder{}
-
-func (_ *rbdBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
+var _ volume.DiskBuilder = &rbdBuilder{}
+var _ volume.ReplicationReporter = &rbdBuilder{}
 
 func (b *rbdBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
@@ -209,18 +296,28 @@ func (b *rbdBuilder) SetUpAt(dir string) error {
 	return err
 }
 
+// ReplicationStatus reports the replication health of the image's pool.
+func (b *rbdBuilder) ReplicationStatus() (*volume.ReplicationInfo, error) {
+	return getReplicationStatus(b)
+}
+
 type rbdCleaner struct {
 	*rbdBuilder
 }
 
 var _ volume.Cleaner = &rbdCleaner{}
 
-func (b *rbd) IsReadOnly() bool {
-	return b.ReadOnly
+func (b *rbdBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            b.ReadOnly,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeOnRootMismatch,
+		SupportsSELinux:     true,
+	}
 }
 
-func (b *rbd) SupportsSELinux() bool {
-	return true
+func (b *rbdBuilder) FSType() string {
+	return b.fsType
 }
 
 // Unmounts the bind mount, and detaches the disk only if the disk
@@ -237,3 +334,76 @@ func (plugin *rbdPlugin) execCommand(command string, args []string) ([]byte, err
 	cmd := plugin.exe.Command(command, args...)
 	return cmd.CombinedOutput()
 }
+
+type rbdVolumeDeleter struct {
+	*rbdBuilder
+}
+
+var _ volume.Deleter = &rbdVolumeDeleter{}
+
+func (d *rbdVolumeDeleter) Delete() error {
+	return d.manager.DeleteImage(d)
+}
+
+type rbdVolumeProvisioner struct {
+	*rbdBuilder
+	options volume.VolumeOptions
+}
+
+var _ volume.Provisioner = &rbdVolumeProvisioner{}
+
+func (p *rbdVolumeProvisioner) Provision(pv *api.PersistentVolume) error {
+	if p.options.SnapshotSource != "" {
+		return &volume.ErrSnapshotSourceNotSupported{Plugin: rbdPluginName, SnapshotID: p.options.SnapshotSource}
+	}
+	p.Image = "kubernetes-dynamic-pv-" + string(util.NewUUID())
+	var sizeMB int64
+	var err error
+	if source := p.options.CloneSource; source != nil {
+		if source.Spec.RBD == nil {
+			return fmt.Errorf("rbd: can't clone volume %s, it is not an rbd image", source.Name)
+		}
+		sizeMB, err = p.manager.CloneImage(p)
+	} else {
+		sizeMB, err = p.manager.CreateImage(p)
+	}
+	if err != nil {
+		return err
+	}
+	pv.Spec.PersistentVolumeSource.RBD.RBDImage = p.Image
+	pv.Spec.Capacity = api.ResourceList{
+		api.ResourceName(api.ResourceStorage): resource.MustParse(fmt.Sprintf("%dMi", sizeMB)),
+	}
+	return nil
+}
+
+func (p *rbdVolumeProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
+	// Provide dummy api.PersistentVolume.Spec, the image name will be
+	// filled in rbdVolumeProvisioner.Provision()
+	return &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{
+			GenerateName: "pv-rbd-",
+			Labels:       map[string]string{},
+			Annotations: map[string]string{
+				"kubernetes.io/createdby": "rbd-dynamic-provisioner",
+			},
+		},
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: p.options.PersistentVolumeReclaimPolicy,
+			AccessModes:                   p.options.AccessModes,
+			Capacity: api.ResourceList{
+				api.ResourceName(api.ResourceStorage): p.options.Capacity,
+			},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				RBD: &api.RBDVolumeSource{
+					CephMonitors: p.Mon,
+					RBDImage:     "dummy",
+					RBDPool:      p.Pool,
+					RadosUser:    p.Id,
+					Keyring:      p.Keyring,
+					FSType:       p.fsType,
+				},
+			},
+		},
+	}, nil
+}
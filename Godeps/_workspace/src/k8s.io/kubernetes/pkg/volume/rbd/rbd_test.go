@@ -17,20 +17,24 @@ limitations under the License.
 package rbd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 )
 
 func TestCanSupport(t *testing.T) {
 	plugMgr := volume.VolumePluginMgr{}
-	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
 
 	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
 	if err != nil {
@@ -67,9 +71,24 @@ func (fake *fakeDiskManager) DetachDisk(c rbdCleaner, mntPath string) error {
 	return nil
 }
 
+func (fake *fakeDiskManager) CreateImage(p *rbdVolumeProvisioner) (int64, error) {
+	return 1024, nil
+}
+
+func (fake *fakeDiskManager) CloneImage(p *rbdVolumeProvisioner) (int64, error) {
+	return 2048, nil
+}
+
+func (fake *fakeDiskManager) DeleteImage(d *rbdVolumeDeleter) error {
+	if d.Image != "bar" {
+		return fmt.Errorf("unexpected image %q", d.Image)
+	}
+	return nil
+}
+
 func doTestPlugin(t *testing.T, spec *volume.Spec) {
 	plugMgr := volume.VolumePluginMgr{}
-	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
 
 	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
 	if err != nil {
@@ -195,7 +214,7 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	client.AddReactor("*", "*", testclient.ObjectReaction(o, testapi.Default.RESTMapper()))
 
 	plugMgr := volume.VolumePluginMgr{}
-	plugMgr.InitPlugins(ProbeVolumePlugins(), volume.NewFakeVolumeHost("/tmp/fake", client, nil))
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", client, nil))
 	plug, _ := plugMgr.FindPluginByName(rbdPluginName)
 
 	// readOnly bool is supplied by persistent-claim volume source when its builder creates other volumes
@@ -203,7 +222,356 @@ func TestPersistentClaimReadOnlyFlag(t *testing.T) {
 	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: types.UID("poduid")}}
 	builder, _ := plug.NewBuilder(spec, pod, volume.VolumeOptions{})
 
-	if !builder.IsReadOnly() {
-		t.Errorf("Expected true for builder.IsReadOnly")
+	if !builder.GetAttributes().ReadOnly {
+		t.Errorf("Expected the builder to be ReadOnly")
+	}
+}
+
+func TestReplicationStatusDegraded(t *testing.T) {
+	tests := []struct {
+		name     string
+		pgs      string
+		inSync   int
+		degraded bool
+	}{
+		{
+			name:     "undersized",
+			pgs:      `[{"pgid":"1.0","state":"active+clean","acting":[0,1,2]},{"pgid":"1.1","state":"active+undersized+degraded","acting":[0,2]}]`,
+			inSync:   2,
+			degraded: true,
+		},
+		{
+			name:     "recovering",
+			pgs:      `{"pg_ready":true,"pg_stats":[{"pgid":"1.0","state":"active+clean","acting":[0,1,2]},{"pgid":"1.1","state":"active+recovering+degraded","acting":[0,1,2]}]}`,
+			inSync:   2,
+			degraded: true,
+		},
+		{
+			name:     "clean",
+			pgs:      `{"pg_ready":true,"pg_stats":[{"pgid":"1.0","state":"active+clean","acting":[0,1,2]},{"pgid":"1.1","state":"active+clean+scrubbing","acting":[2,0,1]}]}`,
+			inSync:   3,
+			degraded: false,
+		},
+	}
+	for _, test := range tests {
+		outputs := []string{
+			`{"pool":"kube","pool_id":1,"size":3}`,
+			test.pgs,
+		}
+		fake := &exec.FakeExec{}
+		for i := range outputs {
+			output := outputs[i]
+			fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+				fcmd := &exec.FakeCmd{
+					CombinedOutputScript: []exec.FakeCombinedOutputAction{
+						func() ([]byte, error) { return []byte(output), nil },
+					},
+				}
+				return exec.InitFakeCmd(fcmd, cmd, args...)
+			})
+		}
+
+		plugMgr := volume.VolumePluginMgr{}
+		plugMgr.InitPlugins([]volume.VolumePlugin{&rbdPlugin{nil, fake, volume.VolumeConfig{}}}, volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+		plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+		if err != nil {
+			t.Fatalf("Can't find the plugin by name")
+		}
+		spec := volume.NewSpecFromVolume(&api.Volume{
+			Name: "vol1",
+			VolumeSource: api.VolumeSource{
+				RBD: &api.RBDVolumeSource{
+					CephMonitors: []string{"a"},
+					RBDImage:     "bar",
+					RBDPool:      "kube",
+				},
+			},
+		})
+		builder, err := plug.(*rbdPlugin).newBuilderInternal(spec, types.UID("poduid"), &fakeDiskManager{}, &mount.FakeMounter{}, "secrets")
+		if err != nil {
+			t.Fatalf("Failed to make a new Builder: %v", err)
+		}
+
+		info, err := volume.GetReplicationStatus(builder)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if info.Replicas != 3 || info.InSync != test.inSync || info.Degraded != test.degraded {
+			t.Errorf("%s: expected %d of 3 replicas in sync and degraded %v, got %+v", test.name, test.inSync, test.degraded, info)
+		}
+		if fake.CommandCalls != 2 {
+			t.Errorf("%s: expected 2 ceph calls, got %d", test.name, fake.CommandCalls)
+		}
+	}
+}
+
+func TestProvisioner(t *testing.T) {
+	config := volume.VolumeConfig{
+		OtherAttributes: map[string]string{
+			RBDProvisionerMonitors: "a:6789, b:6789",
+			RBDProvisionerPool:     "kube",
+		},
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(config), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+
+	options := volume.VolumeOptions{
+		Capacity:                      resource.MustParse("1Gi"),
+		AccessModes:                   []api.PersistentVolumeAccessMode{api.ReadWriteOnce},
+		PersistentVolumeReclaimPolicy: api.PersistentVolumeReclaimDelete,
+	}
+	provisioner, err := plug.(*rbdPlugin).newProvisionerInternal(options, &fakeDiskManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("Failed to make a new PV template: %v", err)
+	}
+	if err := provisioner.Provision(pv); err != nil {
+		t.Fatalf("Provision() failed: %v", err)
+	}
+
+	source := pv.Spec.RBD
+	if !strings.HasPrefix(source.RBDImage, "kubernetes-dynamic-pv-") {
+		t.Errorf("Unexpected image name: %s", source.RBDImage)
+	}
+	if len(source.CephMonitors) != 2 || source.CephMonitors[0] != "a:6789" || source.CephMonitors[1] != "b:6789" {
+		t.Errorf("Unexpected monitors: %v", source.CephMonitors)
+	}
+	if source.RBDPool != "kube" || source.RadosUser != "admin" || source.Keyring != "/etc/ceph/keyring" {
+		t.Errorf("Unexpected pool or credentials: %+v", source)
+	}
+	capacity := pv.Spec.Capacity[api.ResourceStorage]
+	if capacity.Value() != 1024*1024*1024 {
+		t.Errorf("Expected capacity of 1Gi, got %v", capacity.String())
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != api.PersistentVolumeReclaimDelete {
+		t.Errorf("Expected reclaim policy %v, got %v", api.PersistentVolumeReclaimDelete, pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+}
+
+func TestProvisionerNeedsMonitors(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	if _, err := plug.(*rbdPlugin).newProvisionerInternal(volume.VolumeOptions{}, &fakeDiskManager{}); err == nil {
+		t.Errorf("Expected an error without configured monitors")
+	}
+}
+
+func TestDeleter(t *testing.T) {
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins(ProbeVolumePlugins(volume.VolumeConfig{}), volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	spec := volume.NewSpecFromPersistentVolume(&api.PersistentVolume{
+		Spec: api.PersistentVolumeSpec{
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				RBD: &api.RBDVolumeSource{CephMonitors: []string{"a"}, RBDImage: "bar"},
+			},
+		},
+	}, false)
+	deleter, err := plug.(*rbdPlugin).newDeleterInternal(spec, &fakeDiskManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Deleter: %v", err)
+	}
+	if err := deleter.Delete(); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+
+	if _, err := plug.(*rbdPlugin).newDeleterInternal(volume.NewSpecFromVolume(&api.Volume{}), &fakeDiskManager{}); err == nil {
+		t.Errorf("Expected an error for a spec without a persistent volume")
+	}
+}
+
+func TestCreateImage(t *testing.T) {
+	fake := &exec.FakeExec{}
+	fcmd := &exec.FakeCmd{
+		CombinedOutputScript: []exec.FakeCombinedOutputAction{
+			func() ([]byte, error) { return nil, &exec.FakeExitError{Status: 1} },
+			func() ([]byte, error) { return nil, nil },
+		},
+	}
+	for range fcmd.CombinedOutputScript {
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+	config := volume.VolumeConfig{
+		OtherAttributes: map[string]string{RBDProvisionerMonitors: "a,b"},
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins([]volume.VolumePlugin{&rbdPlugin{nil, fake, config}}, volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	provisioner, err := plug.(*rbdPlugin).newProvisionerInternal(volume.VolumeOptions{Capacity: resource.MustParse("1500Ki")}, &RBDUtil{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	p := provisioner.(*rbdVolumeProvisioner)
+	p.Image = "foo"
+
+	sizeMB, err := (&RBDUtil{}).CreateImage(p)
+	if err != nil {
+		t.Fatalf("CreateImage() failed: %v", err)
+	}
+	if sizeMB != 4 {
+		t.Errorf("Expected size rounded up to a 4MB object, got %d", sizeMB)
+	}
+	// The first monitor fails, so the image is created through the second.
+	expected := []string{"rbd", "create", "foo", "--size", "4", "--pool", "rbd", "--id", "admin", "-m", "b", "-k", "/etc/ceph/keyring"}
+	if len(fcmd.CombinedOutputLog) != 2 || strings.Join(fcmd.CombinedOutputLog[1], " ") != strings.Join(expected, " ") {
+		t.Errorf("Unexpected rbd commands: %v", fcmd.CombinedOutputLog)
+	}
+}
+
+func TestCloneImage(t *testing.T) {
+	fake := &exec.FakeExec{}
+	fcmd := &exec.FakeCmd{}
+	for i := 0; i < 7; i++ {
+		fcmd.CombinedOutputScript = append(fcmd.CombinedOutputScript, func() ([]byte, error) { return nil, nil })
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+	config := volume.VolumeConfig{
+		OtherAttributes: map[string]string{RBDProvisionerMonitors: "a", RBDProvisionerPool: "kube"},
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins([]volume.VolumePlugin{&rbdPlugin{nil, fake, config}}, volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	source := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv-source"},
+		Spec: api.PersistentVolumeSpec{
+			Capacity: api.ResourceList{api.ResourceStorage: resource.MustParse("1Gi")},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				RBD: &api.RBDVolumeSource{CephMonitors: []string{"a"}, RBDImage: "src"},
+			},
+		},
+	}
+	options := volume.VolumeOptions{Capacity: resource.MustParse("2Gi"), CloneSource: source}
+	provisioner, err := plug.(*rbdPlugin).newProvisionerInternal(options, &RBDUtil{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	p := provisioner.(*rbdVolumeProvisioner)
+	p.Image = "foo"
+
+	sizeMB, err := (&RBDUtil{}).CloneImage(p)
+	if err != nil {
+		t.Fatalf("CloneImage() failed: %v", err)
+	}
+	if sizeMB != 2048 {
+		t.Errorf("Expected the clone to be grown to 2048MB, got %d", sizeMB)
+	}
+	expected := []string{
+		"snap create rbd/src@foo",
+		"snap protect rbd/src@foo",
+		"clone rbd/src@foo foo",
+		"flatten foo",
+		"snap unprotect rbd/src@foo",
+		"snap rm rbd/src@foo",
+		"resize foo --size 2048",
+	}
+	if len(fcmd.CombinedOutputLog) != len(expected) {
+		t.Fatalf("Unexpected rbd commands: %v", fcmd.CombinedOutputLog)
+	}
+	for i, args := range expected {
+		if command := strings.Join(fcmd.CombinedOutputLog[i], " "); !strings.HasPrefix(command, "rbd "+args+" --pool kube ") {
+			t.Errorf("Expected command %d to be rbd %s, got %s", i, args, command)
+		}
+	}
+
+	// Only rbd images can be cloned.
+	source.Spec.RBD = nil
+	source.Spec.HostPath = &api.HostPathVolumeSource{Path: "/tmp"}
+	provisioner, err = plug.(*rbdPlugin).newProvisionerInternal(options, &fakeDiskManager{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	pv, err := provisioner.NewPersistentVolumeTemplate()
+	if err != nil {
+		t.Fatalf("Failed to make a new PV template: %v", err)
+	}
+	if err := provisioner.Provision(pv); err == nil {
+		t.Errorf("Expected an error cloning a host path volume")
+	}
+}
+
+func TestCloneImageRollsBack(t *testing.T) {
+	fake := &exec.FakeExec{}
+	fcmd := &exec.FakeCmd{}
+	// The flatten fails, so the clone, the protection and the snapshot are
+	// undone in that order.
+	for i := 0; i < 7; i++ {
+		output := func() ([]byte, error) { return nil, nil }
+		if i == 3 {
+			output = func() ([]byte, error) { return nil, &exec.FakeExitError{Status: 1} }
+		}
+		fcmd.CombinedOutputScript = append(fcmd.CombinedOutputScript, output)
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+	config := volume.VolumeConfig{
+		OtherAttributes: map[string]string{RBDProvisionerMonitors: "a", RBDProvisionerPool: "kube"},
+	}
+	plugMgr := volume.VolumePluginMgr{}
+	plugMgr.InitPlugins([]volume.VolumePlugin{&rbdPlugin{nil, fake, config}}, volume.NewFakeVolumeHost("/tmp/fake", nil, nil))
+	plug, err := plugMgr.FindPluginByName("kubernetes.io/rbd")
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	source := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv-source"},
+		Spec: api.PersistentVolumeSpec{
+			Capacity: api.ResourceList{api.ResourceStorage: resource.MustParse("1Gi")},
+			PersistentVolumeSource: api.PersistentVolumeSource{
+				RBD: &api.RBDVolumeSource{CephMonitors: []string{"a"}, RBDImage: "src"},
+			},
+		},
+	}
+	options := volume.VolumeOptions{Capacity: resource.MustParse("1Gi"), CloneSource: source}
+	provisioner, err := plug.(*rbdPlugin).newProvisionerInternal(options, &RBDUtil{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Provisioner: %v", err)
+	}
+	p := provisioner.(*rbdVolumeProvisioner)
+	p.Image = "foo"
+
+	if _, err := (&RBDUtil{}).CloneImage(p); err == nil {
+		t.Fatalf("Expected CloneImage() to fail")
+	}
+	expected := []string{
+		"snap create rbd/src@foo",
+		"snap protect rbd/src@foo",
+		"clone rbd/src@foo foo",
+		"flatten foo",
+		"rm foo",
+		"snap unprotect rbd/src@foo",
+		"snap rm rbd/src@foo",
+	}
+	if len(fcmd.CombinedOutputLog) != len(expected) {
+		t.Fatalf("Unexpected rbd commands: %v", fcmd.CombinedOutputLog)
+	}
+	for i, args := range expected {
+		if command := strings.Join(fcmd.CombinedOutputLog[i], " "); !strings.HasPrefix(command, "rbd "+args+" --pool kube ") {
+			t.Errorf("Expected command %d to be rbd %s, got %s", i, args, command)
+		}
 	}
 }
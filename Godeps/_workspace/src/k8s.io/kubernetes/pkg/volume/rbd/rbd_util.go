@@ -30,13 +30,15 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/mount"
-	"k8s.io/kubernetes/pkg/util/node"
 	"k8s.io/kubernetes/pkg/volume"
 )
 
@@ -107,7 +109,7 @@ func (util *RBDUtil) rbdLock(b rbdBuilder, lock bool) error {
 		secret_opt = []string{"-k", b.Keyring}
 	}
 	// construct lock id using host name and a magic prefix
-	lock_id := "kubelet_lock_magic_" + node.GetHostname("")
+	lock_id := "kubelet_lock_magic_" + b.plugin.host.GetHostName()
 
 	l := len(b.Mon)
 	// avoid mount storm, pick a host randomly
@@ -193,7 +195,7 @@ func (util *RBDUtil) loadRBD(builder *rbdBuilder, mnt string) error {
 
 func (util *RBDUtil) fencing(b rbdBuilder) error {
 	// no need to fence readOnly
-	if b.IsReadOnly() {
+	if b.ReadOnly {
 		return nil
 	}
 	return util.rbdLock(b, true)
@@ -305,3 +307,237 @@ func (util *RBDUtil) DetachDisk(c rbdCleaner, mntPath string) error {
 	}
 	return nil
 }
+
+// rbdCommand runs an rbd command on the builder's pool against the first
+// monitor that answers.
+func rbdCommand(b *rbdBuilder, args ...string) ([]byte, error) {
+	var secret_opt []string
+	if b.Secret != "" {
+		secret_opt = []string{"--key=" + b.Secret}
+	} else {
+		secret_opt = []string{"-k", b.Keyring}
+	}
+	var output []byte
+	err := fmt.Errorf("rbd: no monitors configured")
+	for _, mon := range b.Mon {
+		output, err = b.plugin.execCommand("rbd",
+			append(append(args, "--pool", b.Pool, "--id", b.Id, "-m", mon), secret_opt...))
+		if err == nil {
+			return output, nil
+		}
+	}
+	return output, err
+}
+
+// rbdAllocationUnit is the size of the objects, 4MiB by default, an rbd image
+// is striped over.  Images are sized in whole objects, so that the capacity
+// of a volume is what its image can actually hold.
+const rbdAllocationUnit = 4 * volume.MiB
+
+// rbdSizeMB returns the size in megabytes, as rbd sizes images, of the
+// smallest image holding size.
+func rbdSizeMB(size resource.Quantity) int64 {
+	rounded := volume.RoundUpQuantity(size, rbdAllocationUnit)
+	return rounded.Value() / volume.MiB
+}
+
+func (util *RBDUtil) CreateImage(p *rbdVolumeProvisioner) (int64, error) {
+	sizeMB := rbdSizeMB(p.options.Capacity)
+	output, err := rbdCommand(p.rbdBuilder, "create", p.Image, "--size", strconv.FormatInt(sizeMB, 10))
+	if err != nil {
+		return 0, fmt.Errorf("rbd: failed to create image %s in pool %s: %v, output: %q", p.Image, p.Pool, err, string(output))
+	}
+	glog.V(2).Infof("rbd: successfully created image %s in pool %s", p.Image, p.Pool)
+	return sizeMB, nil
+}
+
+// CloneImage copies the image of the provisioner's clone source into a new image: the source is
+// snapshotted and the snapshot cloned, which requires a format 2 source image, then the clone is
+// flattened so that the snapshot can be removed and the source deleted independently of the clone.
+// The clone is grown to the requested capacity if that is larger than the source.  If any step
+// fails, the steps done so far are undone in reverse, so that neither a protected snapshot, which
+// would keep the source from being deleted, nor a partial clone is left behind.
+func (util *RBDUtil) CloneImage(p *rbdVolumeProvisioner) (int64, error) {
+	source := p.options.CloneSource
+	pool := source.Spec.RBD.RBDPool
+	if pool == "" {
+		pool = "rbd"
+	}
+	snapshot := fmt.Sprintf("%s/%s@%s", pool, source.Spec.RBD.RBDImage, p.Image)
+	removeSnapshot := []string{"snap", "rm", snapshot}
+	unprotectSnapshot := []string{"snap", "unprotect", snapshot}
+	removeClone := []string{"rm", p.Image}
+
+	// undo holds the commands undoing the steps done so far, the last one
+	// first.
+	undo := [][]string{}
+	fail := func(err error) (int64, error) {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if output, undoErr := rbdCommand(p.rbdBuilder, undo[i]...); undoErr != nil {
+				glog.Errorf("rbd: failed to roll back the clone of image %s/%s into image %s in pool %s, rbd %s failed: %v, output: %q", pool, source.Spec.RBD.RBDImage, p.Image, p.Pool, strings.Join(undo[i], " "), undoErr, string(output))
+			}
+		}
+		return 0, err
+	}
+	for _, step := range []struct {
+		args []string
+		// undo is the state of the undo stack once the step is done.
+		undo [][]string
+	}{
+		{[]string{"snap", "create", snapshot}, [][]string{removeSnapshot}},
+		{[]string{"snap", "protect", snapshot}, [][]string{removeSnapshot, unprotectSnapshot}},
+		{[]string{"clone", snapshot, p.Image}, [][]string{removeSnapshot, unprotectSnapshot, removeClone}},
+		{[]string{"flatten", p.Image}, [][]string{removeSnapshot, unprotectSnapshot, removeClone}},
+		{unprotectSnapshot, [][]string{removeSnapshot, removeClone}},
+		{removeSnapshot, [][]string{removeClone}},
+	} {
+		output, err := rbdCommand(p.rbdBuilder, step.args...)
+		if err != nil {
+			return fail(fmt.Errorf("rbd: failed to clone image %s/%s into image %s in pool %s: %v, output: %q", pool, source.Spec.RBD.RBDImage, p.Image, p.Pool, err, string(output)))
+		}
+		undo = step.undo
+	}
+
+	sizeMB := rbdSizeMB(p.options.Capacity)
+	sourceMB := rbdSizeMB(source.Spec.Capacity[api.ResourceStorage])
+	if sizeMB > sourceMB {
+		output, err := rbdCommand(p.rbdBuilder, "resize", p.Image, "--size", strconv.FormatInt(sizeMB, 10))
+		if err != nil {
+			return fail(fmt.Errorf("rbd: failed to resize image %s in pool %s: %v, output: %q", p.Image, p.Pool, err, string(output)))
+		}
+	} else {
+		sizeMB = sourceMB
+	}
+	glog.V(2).Infof("rbd: successfully cloned image %s/%s into image %s in pool %s", pool, source.Spec.RBD.RBDImage, p.Image, p.Pool)
+	return sizeMB, nil
+}
+
+func (util *RBDUtil) DeleteImage(d *rbdVolumeDeleter) error {
+	output, err := rbdCommand(d.rbdBuilder, "rm", d.Image)
+	if err != nil {
+		return fmt.Errorf("rbd: failed to delete image %s in pool %s: %v, output: %q", d.Image, d.Pool, err, string(output))
+	}
+	glog.V(2).Infof("rbd: successfully deleted image %s in pool %s", d.Image, d.Pool)
+	return nil
+}
+
+// cephPoolSize is the output of "ceph osd pool get <pool> size -f json".
+type cephPoolSize struct {
+	Size int `json:"size"`
+}
+
+// cephPlacementGroup is an entry in the output of
+// "ceph pg ls-by-pool <pool> -f json".
+type cephPlacementGroup struct {
+	PGID   string `json:"pgid"`
+	State  string `json:"state"`
+	Acting []int  `json:"acting"`
+}
+
+// cephPlacementGroups is the output of "ceph pg ls-by-pool <pool> -f json"
+// of Ceph releases since Nautilus, which wrap the list of placement groups.
+// Older releases print the bare list.
+type cephPlacementGroups struct {
+	PGStats []cephPlacementGroup `json:"pg_stats"`
+}
+
+// parsePlacementGroups parses the output of "ceph pg ls-by-pool <pool> -f
+// json" of any Ceph release.
+func parsePlacementGroups(output []byte) ([]cephPlacementGroup, error) {
+	var pgs []cephPlacementGroup
+	if err := json.Unmarshal(output, &pgs); err == nil {
+		return pgs, nil
+	}
+	var wrapped cephPlacementGroups
+	if err := json.Unmarshal(output, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.PGStats, nil
+}
+
+// isClean returns whether the state of a placement group, e.g.
+// "active+clean" or "active+undersized+degraded", says that it serves I/O
+// and every replica of it is up to date.
+func (pg *cephPlacementGroup) isClean() bool {
+	active, clean := false, false
+	for _, s := range strings.Split(pg.State, "+") {
+		switch s {
+		case "active":
+			active = true
+		case "clean":
+			clean = true
+		}
+	}
+	return active && clean
+}
+
+// inSync returns how many of the size replicas of the placement group are
+// up to date.  The acting set of a placement group which is not clean may
+// be complete while it recovers or backfills, but at least one replica in
+// it lags behind.
+func (pg *cephPlacementGroup) inSync(size int) int {
+	if pg.isClean() {
+		return size
+	}
+	inSync := size - 1
+	if len(pg.Acting) < inSync {
+		inSync = len(pg.Acting)
+	}
+	if inSync < 0 {
+		inSync = 0
+	}
+	return inSync
+}
+
+// cephCommand runs a ceph command against the first monitor that answers.
+func cephCommand(b *rbdBuilder, args ...string) ([]byte, error) {
+	var secret_opt []string
+	if b.Secret != "" {
+		secret_opt = []string{"--key=" + b.Secret}
+	} else {
+		secret_opt = []string{"-k", b.Keyring}
+	}
+	var output []byte
+	err := fmt.Errorf("rbd: no monitors configured")
+	for _, mon := range b.Mon {
+		output, err = b.plugin.execCommand("ceph",
+			append(append(args, "--format", "json", "--id", b.Id, "-m", mon), secret_opt...))
+		if err == nil {
+			return output, nil
+		}
+	}
+	return output, err
+}
+
+// getReplicationStatus reports how many replicas the image's pool keeps and
+// how many are in sync.  Ceph replicates per placement group, so the number
+// in sync is the smallest of any placement group in the pool, all replicas
+// of an active+clean one and fewer of any other.
+func getReplicationStatus(b *rbdBuilder) (*volume.ReplicationInfo, error) {
+	output, err := cephCommand(b, "osd", "pool", "get", b.Pool, "size")
+	if err != nil {
+		return nil, fmt.Errorf("rbd: failed to get replica count of pool %s: %v, output: %q", b.Pool, err, string(output))
+	}
+	var size cephPoolSize
+	if err := json.Unmarshal(output, &size); err != nil {
+		return nil, fmt.Errorf("rbd: failed to parse size of pool %s: %v", b.Pool, err)
+	}
+
+	output, err = cephCommand(b, "pg", "ls-by-pool", b.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("rbd: failed to list placement groups of pool %s: %v, output: %q", b.Pool, err, string(output))
+	}
+	pgs, err := parsePlacementGroups(output)
+	if err != nil {
+		return nil, fmt.Errorf("rbd: failed to parse placement groups of pool %s: %v", b.Pool, err)
+	}
+
+	inSync := size.Size
+	for i := range pgs {
+		if n := pgs[i].inSync(size.Size); n < inSync {
+			glog.V(4).Infof("rbd: placement group %s is %s with %d of %d replicas in sync", pgs[i].PGID, pgs[i].State, n, size.Size)
+			inSync = n
+		}
+	}
+	return volume.NewReplicationInfo(size.Size, inSync), nil
+}
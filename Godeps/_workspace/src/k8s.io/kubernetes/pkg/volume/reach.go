@@ -0,0 +1,74 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// reachabilityStat is replaced in tests.
+var reachabilityStat = os.Stat
+
+// CanReach stats the path of the volume behind c, giving up after timeout,
+// and reports whether a normal TearDown is likely to succeed.  Callers can
+// use it to choose a lazy or forced unmount upfront for a volume whose
+// backend is gone instead of hanging in TearDown.  A path that does not
+// exist is reachable, as there is nothing left to tear down.  CanReach
+// itself never blocks for longer than timeout; a hung stat is left behind
+// in the background.
+func CanReach(c Cleaner, timeout time.Duration) (bool, error) {
+	path := c.GetPath()
+	stat := reachabilityStat
+	done := make(chan error, 1)
+	go func() {
+		_, err := stat(path)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		switch {
+		case err == nil, os.IsNotExist(err):
+			return true, nil
+		case isUnreachableError(err):
+			glog.V(2).Infof("Volume %s is unreachable: %v", path, err)
+			return false, nil
+		default:
+			return false, err
+		}
+	case <-time.After(timeout):
+		glog.V(2).Infof("Volume %s did not respond within %s", path, timeout)
+		return false, nil
+	}
+}
+
+// isUnreachableError reports whether a stat failed because the backend of a
+// mount could not be reached.
+func isUnreachableError(err error) bool {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	switch err {
+	case syscall.ENOTCONN, syscall.ESTALE, syscall.EIO, syscall.EHOSTDOWN, syscall.ETIMEDOUT:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type fakePathCleaner struct {
+	path string
+}
+
+func (c *fakePathCleaner) GetPath() string {
+	return c.path
+}
+
+func (c *fakePathCleaner) TearDown() error {
+	return nil
+}
+
+func (c *fakePathCleaner) TearDownAt(dir string) error {
+	return nil
+}
+
+func TestCanReach(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	defer func(stat func(string) (os.FileInfo, error)) { reachabilityStat = stat }(reachabilityStat)
+	reachabilityStat = func(path string) (os.FileInfo, error) {
+		switch path {
+		case "/hung":
+			<-hang
+		case "/stale":
+			return nil, &os.PathError{Op: "stat", Path: path, Err: syscall.ESTALE}
+		case "/gone":
+			return nil, &os.PathError{Op: "stat", Path: path, Err: syscall.ENOENT}
+		case "/denied":
+			return nil, &os.PathError{Op: "stat", Path: path, Err: syscall.EACCES}
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		path      string
+		reachable bool
+		err       bool
+	}{
+		{"/ok", true, false},
+		{"/gone", true, false},
+		{"/hung", false, false},
+		{"/stale", false, false},
+		{"/denied", false, true},
+	}
+	const timeout = 50 * time.Millisecond
+	for _, test := range tests {
+		start := time.Now()
+		reachable, err := CanReach(&fakePathCleaner{test.path}, timeout)
+		if elapsed := time.Since(start); elapsed > 10*timeout {
+			t.Errorf("%s: CanReach took %s, longer than the %s timeout", test.path, elapsed, timeout)
+		}
+		if reachable != test.reachable || (err != nil) != test.err {
+			t.Errorf("%s: expected reachable=%v error=%v, got %v, %v", test.path, test.reachable, test.err, reachable, err)
+		}
+	}
+}
+
+func TestCanReachRealPath(t *testing.T) {
+	reachable, err := CanReach(&fakePathCleaner{os.TempDir()}, time.Second)
+	if !reachable || err != nil {
+		t.Errorf("Expected %s to be reachable, got %v, %v", os.TempDir(), reachable, err)
+	}
+	if _, err := CanReach(&fakePathCleaner{fmt.Sprintf("%s/does-not-exist-%d", os.TempDir(), time.Now().UnixNano())}, time.Second); err != nil {
+		t.Errorf("Expected missing path to be reachable, got %v", err)
+	}
+}
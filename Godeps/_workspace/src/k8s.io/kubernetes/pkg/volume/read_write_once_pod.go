@@ -0,0 +1,107 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// ErrVolumeConflict is returned by the SetUp of a ReadWriteOncePod volume
+// that is already set up for another pod of the node.
+type ErrVolumeConflict struct {
+	VolumeName string
+	PodUID     types.UID
+	// Pods are the other pods the volume is set up for.
+	Pods []types.UID
+}
+
+func (e *ErrVolumeConflict) Error() string {
+	pods := []string{}
+	for _, uid := range e.Pods {
+		pods = append(pods, string(uid))
+	}
+	return fmt.Sprintf("volume %s can only be used by one pod and is in use by %s, refusing to set it up for pod %s", e.VolumeName, strings.Join(pods, ", "), e.PodUID)
+}
+
+// IsReadWriteOncePod returns whether modes restrict a volume to a single
+// pod.
+func IsReadWriteOncePod(modes []api.PersistentVolumeAccessMode) bool {
+	return AccessModesContains(modes, api.ReadWriteOncePod)
+}
+
+// NewReadWriteOncePodBuilder returns a Builder which sets up the volume with
+// builder only if state records no other pod using it, and records the pod
+// as its user in state otherwise, so that two pods of a node can't share a
+// ReadWriteOncePod volume.  volumeName is the unique name of the volume, see
+// GetUniqueVolumeName, and inUse reports whether the volume is still set up
+// for another pod, see MarkVolumeAsMountedExclusively.  The other interfaces
+// of builder, e.g. Verifier, are reached through Unwrap.
+func NewReadWriteOncePodBuilder(builder Builder, state ActualStateOfWorld, volumeName string, spec *Spec, podUID types.UID, inUse func(podUID types.UID) bool) Builder {
+	return &readWriteOncePodBuilder{
+		Builder:    builder,
+		state:      state,
+		volumeName: volumeName,
+		spec:       spec,
+		podUID:     podUID,
+		inUse:      inUse,
+	}
+}
+
+type readWriteOncePodBuilder struct {
+	Builder
+	state      ActualStateOfWorld
+	volumeName string
+	spec       *Spec
+	podUID     types.UID
+	inUse      func(podUID types.UID) bool
+}
+
+var _ Unwrapper = &readWriteOncePodBuilder{}
+
+func (b *readWriteOncePodBuilder) Unwrap() Volume {
+	return b.Builder
+}
+
+func (b *readWriteOncePodBuilder) SetUp() error {
+	return b.setUp(b.Builder.SetUp)
+}
+
+func (b *readWriteOncePodBuilder) SetUpAt(dir string) error {
+	return b.setUp(func() error {
+		return b.Builder.SetUpAt(dir)
+	})
+}
+
+func (b *readWriteOncePodBuilder) setUp(setUp func() error) error {
+	// SetUp is repeated for pods the volume is set up for already, whose
+	// failure must not release the volume.
+	settingUp := !b.state.PodExistsInVolume(b.podUID, b.volumeName)
+	if err := b.state.MarkVolumeAsMountedExclusively(b.podUID, b.volumeName, b.spec, b.inUse); err != nil {
+		return err
+	}
+	if err := setUp(); err != nil {
+		if settingUp {
+			b.state.MarkVolumeAsUnmounted(b.podUID, b.volumeName)
+		}
+		return err
+	}
+	return nil
+}
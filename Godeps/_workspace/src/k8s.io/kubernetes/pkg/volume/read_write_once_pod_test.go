@@ -0,0 +1,110 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+func TestReadWriteOncePodBuilder(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "readWriteOncePodTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plugin := &FakeVolumePlugin{PluginName: "kubernetes.io/fake", Host: NewFakeVolumeHost(tmpDir, nil, nil)}
+	spec := &Spec{Volume: &api.Volume{Name: "vol"}}
+	state := NewActualStateOfWorld()
+	inUse := map[types.UID]bool{}
+	newBuilder := func(podUID types.UID) (Builder, *FakeVolume) {
+		fake := plugin.newFakeVolume(podUID, "vol")
+		return NewReadWriteOncePodBuilder(fake, state, "kubernetes.io/fake/vol", spec, podUID, func(uid types.UID) bool { return inUse[uid] }), fake
+	}
+
+	builder1, _ := newBuilder("pod1")
+	if err := builder1.SetUp(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	inUse["pod1"] = true
+	// The pod the volume is set up for may set it up again.
+	if err := builder1.SetUp(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	builder2, fake2 := newBuilder("pod2")
+	err = builder2.SetUp()
+	conflict, ok := err.(*ErrVolumeConflict)
+	if !ok {
+		t.Fatalf("Expected an ErrVolumeConflict, got %v", err)
+	}
+	if conflict.PodUID != "pod2" || !reflect.DeepEqual(conflict.Pods, []types.UID{"pod1"}) {
+		t.Errorf("Unexpected conflict: %#v", conflict)
+	}
+	if fake2.GetSetUpCallCount() != 0 {
+		t.Errorf("Expected the volume not to be set up for pod2")
+	}
+
+	// pod1 went away without the state being told.
+	inUse["pod1"] = false
+	if err := builder2.SetUp(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if state.PodExistsInVolume("pod1", "kubernetes.io/fake/vol") || !state.PodExistsInVolume("pod2", "kubernetes.io/fake/vol") {
+		t.Errorf("Expected the volume to be recorded for pod2 only, got %+v", state.GetMountedVolumes())
+	}
+}
+
+func TestReadWriteOncePodBuilderReleasesFailedSetUp(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "readWriteOncePodTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plugin := &FakeVolumePlugin{PluginName: "kubernetes.io/fake", Host: NewFakeVolumeHost(tmpDir, nil, nil)}
+	state := NewActualStateOfWorld()
+	fake := plugin.newFakeVolume("pod1", "vol")
+	fake.SetUpErr = fmt.Errorf("mount failed")
+	builder := NewReadWriteOncePodBuilder(fake, state, "kubernetes.io/fake/vol", &Spec{Volume: &api.Volume{Name: "vol"}}, "pod1", func(types.UID) bool { return true })
+
+	if err := builder.SetUp(); err == nil {
+		t.Errorf("Expected an error")
+	}
+	if state.VolumeExists("kubernetes.io/fake/vol") {
+		t.Errorf("Expected the volume to be released, got %+v", state.GetMountedVolumes())
+	}
+}
+
+func TestReadWriteOncePodBuilderUnwraps(t *testing.T) {
+	failure := fmt.Errorf("wrong UUID")
+	inner := &fakeVerifyingBuilder{err: failure}
+	builder := NewReadWriteOncePodBuilder(inner, NewActualStateOfWorld(), "kubernetes.io/fake/vol", &Spec{Volume: &api.Volume{Name: "vol"}}, "pod1", func(types.UID) bool { return false })
+
+	if UnwrapVolume(builder) != inner {
+		t.Errorf("Expected the wrapped builder, got %#v", UnwrapVolume(builder))
+	}
+	if err := VerifyWithTimeout(builder, time.Second); err != failure {
+		t.Errorf("Expected the wrapped builder to be verified with %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util/mount"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// mountInfoPath is the mount table consulted for the actual state of mounts.
+var mountInfoPath = "/proc/self/mountinfo"
+
+// remountReadOnly makes the mount at a path read-only; replaced in tests.
+var remountReadOnly = remountReadOnlyInPlace
+
+// ContentRefresher is implemented by Builders with the ReadOnly attribute
+// because containers must not write to them, but which rewrite the contents
+// of the volume themselves on every SetUp, e.g. the downward API.
+// EnforceReadOnly leaves them writable.
+type ContentRefresher interface {
+	RefreshesContent() bool
+}
+
+// ErrReadOnlyMismatch is returned when the read-only state a volume is
+// mounted with differs from the ReadOnly attribute of its Builder.
+type ErrReadOnlyMismatch struct {
+	Path string
+	// Requested is the ReadOnly attribute of the Builder.
+	Requested bool
+	// Effective is the read-only state of the mount containing Path.
+	Effective bool
+}
+
+func (e *ErrReadOnlyMismatch) Error() string {
+	return fmt.Sprintf("volume %s was requested with readOnly=%t but is mounted with readOnly=%t", e.Path, e.Requested, e.Effective)
+}
+
+// EffectiveReadOnly reports whether the mount containing path is read-only,
+// either because the mount itself or its superblock is read-only.
+func EffectiveReadOnly(path string) (bool, error) {
+	info, err := volumeutil.GetMountInfo(mountInfoPath, path)
+	if err != nil {
+		return false, err
+	}
+	return info.ReadOnly(), nil
+}
+
+// CheckReadOnly compares the effective read-only state of the builder's path
+// with the ReadOnly attribute of the builder.  A difference is logged and
+// returned as an ErrReadOnlyMismatch.
+func CheckReadOnly(builder Builder) error {
+	path := builder.GetPath()
+	effective, err := EffectiveReadOnly(path)
+	if err != nil {
+		return err
+	}
+	if requested := builder.GetAttributes().ReadOnly; requested != effective {
+		mismatch := &ErrReadOnlyMismatch{Path: path, Requested: requested, Effective: effective}
+		glog.Warningf("%v", mismatch)
+		return mismatch
+	}
+	return nil
+}
+
+// EnforceReadOnly makes the volume of builder read-only at the VFS layer if
+// the builder has the ReadOnly attribute, so that the attribute is a
+// guarantee instead of advice to the plugin.  It runs after SetUp, and remounts the mount at
+// the builder's path read-only without affecting other mounts of the same
+// filesystem.  A volume which is not mounted at its path can't be remounted
+// on its own; its ErrReadOnlyMismatch is returned instead.
+func EnforceReadOnly(builder Builder) error {
+	if !builder.GetAttributes().ReadOnly {
+		return nil
+	}
+	if refresher, ok := UnwrapVolume(builder).(ContentRefresher); ok && refresher.RefreshesContent() {
+		return nil
+	}
+	path := filepath.Clean(builder.GetPath())
+	info, err := volumeutil.GetMountInfo(mountInfoPath, path)
+	if err != nil {
+		return err
+	}
+	if info.ReadOnly() {
+		return nil
+	}
+	if info.MountPoint != path {
+		mismatch := &ErrReadOnlyMismatch{Path: path, Requested: true, Effective: false}
+		glog.Warningf("%v, and it has no mount of its own to remount read-only", mismatch)
+		return mismatch
+	}
+	glog.V(3).Infof("Remounting volume %s read-only", path)
+	if err := remountReadOnly(path); err != nil {
+		return fmt.Errorf("failed to remount volume %s read-only: %v", path, err)
+	}
+	return nil
+}
+
+// BindMountReadOnly bind mounts source on target read-only.  A bind mount
+// ignores "ro" unless it is remounted, which mounter may not do, so the new
+// mount is remounted read-only explicitly.  If that fails, target is
+// unmounted again.
+func BindMountReadOnly(mounter mount.Interface, source, target string) error {
+	if err := mounter.Mount(source, target, "", []string{"bind", "ro"}); err != nil {
+		return err
+	}
+	if err := remountReadOnly(target); err != nil {
+		if unmountErr := mounter.Unmount(target); unmountErr != nil {
+			glog.Errorf("Failed to unmount %s: %v", target, unmountErr)
+		}
+		return fmt.Errorf("failed to remount %s read-only: %v", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import "syscall"
+
+// The per-mount flags statfs reports in f_flags, see statfs(2).
+const (
+	stNoSuid     = 0x2
+	stNoDev      = 0x4
+	stNoExec     = 0x8
+	stNoAtime    = 0x400
+	stNoDirAtime = 0x800
+	stRelAtime   = 0x1000
+)
+
+// statfsMountFlags maps the statfs flags of a mount to the flags which keep
+// them when the mount is remounted.
+var statfsMountFlags = []struct {
+	statfs int64
+	mount  uintptr
+}{
+	{stNoSuid, syscall.MS_NOSUID},
+	{stNoDev, syscall.MS_NODEV},
+	{stNoExec, syscall.MS_NOEXEC},
+	{stNoAtime, syscall.MS_NOATIME},
+	{stNoDirAtime, syscall.MS_NODIRATIME},
+	{stRelAtime, syscall.MS_RELATIME},
+}
+
+// remountReadOnlyInPlace sets the read-only flag of the mount at path.
+// MS_BIND limits the change to this mount, where a plain remount would
+// make the filesystem read-only everywhere it is mounted.  A bind remount
+// replaces all per-mount flags, so the ones the mount has, e.g. nosuid, are
+// passed along.
+func remountReadOnlyInPlace(path string) error {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return err
+	}
+	return syscall.Mount("", path, "", syscall.MS_REMOUNT|syscall.MS_BIND|syscall.MS_RDONLY|mountFlags(int64(st.Flags)), "")
+}
+
+// mountFlags returns the mount flags keeping the per-mount flags in flags,
+// the f_flags of statfs.
+func mountFlags(flags int64) uintptr {
+	var kept uintptr
+	for _, f := range statfsMountFlags {
+		if flags&f.statfs != 0 {
+			kept |= f.mount
+		}
+	}
+	return kept
+}
@@ -0,0 +1,36 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestMountFlagsKeepHardening(t *testing.T) {
+	// ST_RDONLY (0x1) is set by the remount itself.
+	flags := mountFlags(0x1 | stNoSuid | stNoDev | stNoExec | stRelAtime)
+	expected := uintptr(syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC | syscall.MS_RELATIME)
+	if flags != expected {
+		t.Errorf("Expected mount flags %#x, got %#x", expected, flags)
+	}
+	if flags := mountFlags(0); flags != 0 {
+		t.Errorf("Expected no mount flags, got %#x", flags)
+	}
+}
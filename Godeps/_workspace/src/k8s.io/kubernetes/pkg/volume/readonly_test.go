@@ -0,0 +1,168 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+const fakeMountInfo = `17 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,errors=remount-ro
+25 17 0:22 / /var/lib/kubelet/pods/uid1/volumes/kubernetes.io~nfs/rw-vol rw,relatime shared:5 - nfs4 server:/export rw,vers=4.1
+26 17 8:16 / /var/lib/kubelet/pods/uid1/volumes/kubernetes.io~gce-pd/forced-ro ro,relatime shared:6 - ext4 /dev/sdb rw
+27 17 8:32 / /var/lib/kubelet/pods/uid1/volumes/kubernetes.io~aws-ebs/errors rw,relatime shared:7 - ext4 /dev/sdc ro,errors=remount-ro
+`
+
+type fakeReadOnlyBuilder struct {
+	FakeVolume
+	path     string
+	readOnly bool
+}
+
+func (b *fakeReadOnlyBuilder) GetPath() string {
+	return b.path
+}
+
+func (b *fakeReadOnlyBuilder) GetAttributes() Attributes {
+	return Attributes{ReadOnly: b.readOnly}
+}
+
+func withFakeMountInfo(t *testing.T, contents string) func() {
+	f, err := ioutil.TempFile("", "mountinfo")
+	if err != nil {
+		t.Fatalf("can't create temp file: %v", err)
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("can't write temp file: %v", err)
+	}
+	f.Close()
+	old := mountInfoPath
+	mountInfoPath = f.Name()
+	return func() {
+		mountInfoPath = old
+		os.Remove(f.Name())
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	defer withFakeMountInfo(t, fakeMountInfo)()
+
+	tests := []struct {
+		path      string
+		requested bool
+		effective bool
+		mismatch  bool
+	}{
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~nfs/rw-vol", false, false, false},
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~nfs/rw-vol", true, false, true},
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~gce-pd/forced-ro", false, true, true},
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~gce-pd/forced-ro", true, true, false},
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~aws-ebs/errors", false, true, true},
+		{"/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~empty-dir/disk", false, false, false},
+	}
+	for _, test := range tests {
+		effective, err := EffectiveReadOnly(test.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.path, err)
+			continue
+		}
+		if effective != test.effective {
+			t.Errorf("%s: expected effective readOnly=%t, got %t", test.path, test.effective, effective)
+		}
+
+		err = CheckReadOnly(&fakeReadOnlyBuilder{path: test.path, readOnly: test.requested})
+		mismatch, ok := err.(*ErrReadOnlyMismatch)
+		if test.mismatch != ok {
+			t.Errorf("%s: expected mismatch=%t, got %v", test.path, test.mismatch, err)
+			continue
+		}
+		if ok && (mismatch.Requested != test.requested || mismatch.Effective != test.effective) {
+			t.Errorf("%s: unexpected mismatch details: %+v", test.path, mismatch)
+		}
+	}
+}
+
+type fakeRefreshingBuilder struct {
+	fakeReadOnlyBuilder
+}
+
+func (b *fakeRefreshingBuilder) RefreshesContent() bool {
+	return true
+}
+
+func TestEnforceReadOnly(t *testing.T) {
+	defer withFakeMountInfo(t, fakeMountInfo)()
+	defer func(remount func(string) error) { remountReadOnly = remount }(remountReadOnly)
+	remounted := []string{}
+	remountReadOnly = func(path string) error {
+		remounted = append(remounted, path)
+		return nil
+	}
+
+	rwVol := "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~nfs/rw-vol"
+	tests := []struct {
+		builder   Builder
+		remounted []string
+		mismatch  bool
+	}{
+		{&fakeReadOnlyBuilder{path: rwVol, readOnly: false}, []string{}, false},
+		{&fakeReadOnlyBuilder{path: rwVol, readOnly: true}, []string{rwVol}, false},
+		{&fakeReadOnlyBuilder{path: rwVol + "/", readOnly: true}, []string{rwVol}, false},
+		{&fakeReadOnlyBuilder{path: "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~gce-pd/forced-ro", readOnly: true}, []string{}, false},
+		{&fakeRefreshingBuilder{fakeReadOnlyBuilder{path: rwVol, readOnly: true}}, []string{}, false},
+		// Not a mount point of its own.
+		{&fakeReadOnlyBuilder{path: "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~empty-dir/disk", readOnly: true}, []string{}, true},
+	}
+	for _, test := range tests {
+		remounted = []string{}
+		err := EnforceReadOnly(test.builder)
+		if _, ok := err.(*ErrReadOnlyMismatch); ok != test.mismatch {
+			t.Errorf("%s: expected mismatch=%t, got %v", test.builder.GetPath(), test.mismatch, err)
+		} else if !ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.builder.GetPath(), err)
+		}
+		if !reflect.DeepEqual(remounted, test.remounted) {
+			t.Errorf("%s: expected %v to be remounted, got %v", test.builder.GetPath(), test.remounted, remounted)
+		}
+	}
+}
+
+func TestBindMountReadOnly(t *testing.T) {
+	defer func(remount func(string) error) { remountReadOnly = remount }(remountReadOnly)
+	remountErr := errors.New("remount failed")
+	for _, err := range []error{nil, remountErr} {
+		err := err
+		remountReadOnly = func(string) error { return err }
+		mounter := &mount.FakeMounter{}
+		result := BindMountReadOnly(mounter, "/src", "/dst")
+		if (result == nil) != (err == nil) {
+			t.Errorf("remount error %v: unexpected result %v", err, result)
+		}
+		expectedLog := []mount.FakeAction{{Action: mount.FakeActionMount, Target: "/dst", Source: "/src"}}
+		if err != nil {
+			expectedLog = append(expectedLog, mount.FakeAction{Action: mount.FakeActionUnmount, Target: "/dst"})
+		}
+		if !reflect.DeepEqual(mounter.Log, expectedLog) {
+			t.Errorf("remount error %v: expected %v, got %v", err, expectedLog, mounter.Log)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ErrRecycleTimeout is returned when a recycle is not complete within its
+// timeout.
+type ErrRecycleTimeout struct {
+	// Volume is the path of the volume, or the name of the pod scrubbing it.
+	Volume  string
+	Timeout time.Duration
+}
+
+func (e *ErrRecycleTimeout) Error() string {
+	return fmt.Sprintf("recycling %s did not complete within %v", e.Volume, e.Timeout)
+}
+
+// RecycleWithTimeout recycles the volume of recycler, giving up with an
+// ErrRecycleTimeout after timeout unless it is 0.  Recyclers implementing
+// ProgressRecycler report their progress to progress and are asked to abort,
+// while others only report that they started and the call to Recycle is left
+// to finish in the background, like in RecycleWithContext.
+func RecycleWithTimeout(recycler Recycler, timeout time.Duration, progress RecycleProgressFunc) error {
+	if r, ok := recycler.(ProgressRecycler); ok {
+		return r.RecycleWithTimeout(timeout, progress)
+	}
+	if progress != nil {
+		progress(fmt.Sprintf("recycling %s", recycler.GetPath()))
+	}
+	if timeout == 0 {
+		return recycler.Recycle()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := RecycleWithContext(ctx, recycler)
+	if err == context.DeadlineExceeded {
+		return &ErrRecycleTimeout{Volume: recycler.GetPath(), Timeout: timeout}
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+	"time"
+)
+
+// blockingRecycler is a Recycler without progress support whose Recycle
+// blocks until release is closed.
+type blockingRecycler struct {
+	fakeRecycler
+	release chan struct{}
+}
+
+func (r *blockingRecycler) Recycle() error {
+	<-r.release
+	return nil
+}
+
+func TestRecycleWithTimeout(t *testing.T) {
+	recycler := &blockingRecycler{fakeRecycler: fakeRecycler{path: "/recycled"}, release: make(chan struct{})}
+	defer close(recycler.release)
+
+	messages := []string{}
+	err := RecycleWithTimeout(recycler, 50*time.Millisecond, func(message string) {
+		messages = append(messages, message)
+	})
+	if timeoutErr, ok := err.(*ErrRecycleTimeout); !ok || timeoutErr.Volume != "/recycled" {
+		t.Errorf("Expected ErrRecycleTimeout for /recycled, got %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected the start of the recycle to be reported, got %v", messages)
+	}
+
+	if err := RecycleWithTimeout(&fakeRecycler{}, 0, nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+// +build windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import "os"
+
+// renameDirectory moves oldPath to newPath, an empty directory.  Unlike on
+// Unix, a rename does not replace an existing directory on Windows, so it
+// is removed first, unless a previous attempt did.
+func renameDirectory(oldPath, newPath string) error {
+	if err := os.Remove(newPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// copyOwnership does nothing, as files have no Unix owner on Windows.
+func copyOwnership(path string, info os.FileInfo) error {
+	return nil
+}
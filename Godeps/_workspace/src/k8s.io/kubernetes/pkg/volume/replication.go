@@ -0,0 +1,65 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+)
+
+// ErrNotReplicated is returned by GetReplicationStatus for volumes whose
+// backend does not replicate data.
+var ErrNotReplicated = errors.New("volume is not replicated")
+
+// ReplicationInfo describes the health of a volume's backend replica set.
+type ReplicationInfo struct {
+	// Replicas is the number of replicas the backend is configured to keep.
+	Replicas int
+	// InSync is the number of replicas which are currently up to date.
+	InSync int
+	// Degraded is true if fewer than Replicas replicas are in sync.  A
+	// degraded volume is still usable but has reduced durability.
+	Degraded bool
+}
+
+// NewReplicationInfo returns a ReplicationInfo for the given counts, marking
+// it degraded if any replica is out of sync.
+func NewReplicationInfo(replicas, inSync int) *ReplicationInfo {
+	return &ReplicationInfo{
+		Replicas: replicas,
+		InSync:   inSync,
+		Degraded: inSync < replicas,
+	}
+}
+
+// ReplicationReporter is implemented by volumes whose backend replicates
+// data, which so far is only Ceph RBD; there is no DRBD plugin to report
+// the state of its resources yet.  An error means the backend could not be
+// queried; a reachable but degraded replica set is reported through
+// ReplicationInfo.Degraded instead.
+type ReplicationReporter interface {
+	ReplicationStatus() (*ReplicationInfo, error)
+}
+
+// GetReplicationStatus returns the replication status of v, or
+// ErrNotReplicated if v does not implement ReplicationReporter.
+func GetReplicationStatus(v Volume) (*ReplicationInfo, error) {
+	reporter, ok := UnwrapVolume(v).(ReplicationReporter)
+	if !ok {
+		return nil, ErrNotReplicated
+	}
+	return reporter.ReplicationStatus()
+}
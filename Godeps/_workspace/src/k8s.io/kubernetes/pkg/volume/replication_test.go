@@ -0,0 +1,59 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeReplicatedVolume struct {
+	FakeVolume
+	info *ReplicationInfo
+	err  error
+}
+
+func (v *fakeReplicatedVolume) ReplicationStatus() (*ReplicationInfo, error) {
+	return v.info, v.err
+}
+
+func TestGetReplicationStatusDegraded(t *testing.T) {
+	v := &fakeReplicatedVolume{info: NewReplicationInfo(3, 2)}
+	info, err := GetReplicationStatus(v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Replicas != 3 || info.InSync != 2 || !info.Degraded {
+		t.Errorf("Expected 2 of 3 replicas in sync and degraded, got %+v", info)
+	}
+
+	if info := NewReplicationInfo(3, 3); info.Degraded {
+		t.Errorf("Expected fully synced replica set not to be degraded: %+v", info)
+	}
+}
+
+func TestGetReplicationStatusErrors(t *testing.T) {
+	if _, err := GetReplicationStatus(&FakeVolume{}); err != ErrNotReplicated {
+		t.Errorf("Expected ErrNotReplicated, got %v", err)
+	}
+
+	backendErr := fmt.Errorf("monitor unreachable")
+	v := &fakeReplicatedVolume{err: backendErr}
+	if _, err := GetReplicationStatus(v); err != backendErr {
+		t.Errorf("Expected backend error, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// maxSanitizedNameLength is the longest name a filesystem is guaranteed to
+// accept for a single path component.
+const maxSanitizedNameLength = 255
+
+// PathSanitizer turns a user supplied volume name into a single path
+// component that cannot escape or collide with another volume's directory.
+// Distinct names must never produce the same output.
+type PathSanitizer func(name string) (string, error)
+
+var (
+	pathSanitizerLock sync.RWMutex
+	pathSanitizer     PathSanitizer = SanitizeVolumeName
+)
+
+// SetPathSanitizer replaces the sanitizer used by PathForSpec and
+// DirectoryLayout.PodVolumeDir.  Passing nil
+// restores SanitizeVolumeName.
+func SetPathSanitizer(sanitizer PathSanitizer) {
+	if sanitizer == nil {
+		sanitizer = SanitizeVolumeName
+	}
+	pathSanitizerLock.Lock()
+	defer pathSanitizerLock.Unlock()
+	pathSanitizer = sanitizer
+}
+
+func getPathSanitizer() PathSanitizer {
+	pathSanitizerLock.RLock()
+	defer pathSanitizerLock.RUnlock()
+	return pathSanitizer
+}
+
+// SanitizeVolumeName returns a filesystem safe encoding of name.  Letters,
+// digits, '-', '_' and non-leading '.' are kept and every other byte is
+// written as %XX, which makes the encoding reversible and therefore
+// collision free.  Empty names, names containing NUL and names whose
+// encoding is too long for a path component are rejected.
+func SanitizeVolumeName(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("volume name must not be empty")
+	}
+	if strings.IndexByte(name, 0) >= 0 {
+		return "", fmt.Errorf("volume name %q must not contain NUL", name)
+	}
+	out := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if isSafeNameByte(c) && !(i == 0 && c == '.') {
+			out = append(out, c)
+		} else {
+			out = append(out, fmt.Sprintf("%%%02X", c)...)
+		}
+	}
+	if len(out) > maxSanitizedNameLength {
+		return "", fmt.Errorf("volume name %q is too long: %d bytes after sanitizing, the limit is %d", name, len(out), maxSanitizedNameLength)
+	}
+	return string(out), nil
+}
+
+// hashVolumeName returns a path component for name that is safe whatever
+// name is, for the names the sanitizers reject.  SanitizeVolumeName never
+// produces a '~', so a hashed name cannot collide with a sanitized one.
+func hashVolumeName(name string) string {
+	return fmt.Sprintf("~sha256-%x", sha256.Sum256([]byte(name)))
+}
+
+func isSafeNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.'
+}
+
+// PathForSpec returns the directory in which the named plugin sets up the
+// volume described by spec for the given pod, with the volume name passed
+// through the configured PathSanitizer.  Unlike PodVolumeDir, it fails
+// instead of falling back to SanitizeVolumeName.
+func PathForSpec(host VolumeHost, podUID types.UID, pluginName string, spec *Spec) (string, error) {
+	return NewDirectoryLayout(host).sanitizedPodVolumeDir(podUID, pluginName, spec.Name(), getPathSanitizer())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+func TestSanitizeVolumeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"data", "data"},
+		{"my-vol_1.backup", "my-vol_1.backup"},
+		{"..", "%2E."},
+		{".hidden", "%2Ehidden"},
+		{"../../etc", "%2E.%2F..%2Fetc"},
+		{"a/b", "a%2Fb"},
+		{"a b", "a%20b"},
+		{"100%", "100%25"},
+		{"\xff", "%FF"},
+	}
+	for _, test := range tests {
+		out, err := SanitizeVolumeName(test.name)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if out != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.name, test.expected, out)
+		}
+		if strings.Contains(out, "/") || strings.HasPrefix(out, ".") {
+			t.Errorf("%q: sanitized name %q is not a safe path component", test.name, out)
+		}
+	}
+}
+
+func TestSanitizeVolumeNameRejects(t *testing.T) {
+	for _, name := range []string{"", "a\x00b", strings.Repeat("a", maxSanitizedNameLength+1), strings.Repeat("/", 100)} {
+		if out, err := SanitizeVolumeName(name); err == nil {
+			t.Errorf("%q: expected an error, got %q", name, out)
+		}
+	}
+	if _, err := SanitizeVolumeName(strings.Repeat("a", maxSanitizedNameLength)); err != nil {
+		t.Errorf("Expected a name at the length limit to be accepted, got %v", err)
+	}
+}
+
+func TestSanitizeVolumeNameCollisions(t *testing.T) {
+	names := []string{
+		"a/b", "a%2Fb", "a%b", "a_b", "a-b", "a.b", "a b", "a\\b",
+		".a", "%2Ea", "a", "A", "..", ".", "%2E", "%", "%25",
+	}
+	seen := map[string]string{}
+	for _, name := range names {
+		out, err := SanitizeVolumeName(name)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", name, err)
+		}
+		if other, found := seen[out]; found {
+			t.Errorf("%q and %q both sanitize to %q", name, other, out)
+		}
+		seen[out] = name
+	}
+}
+
+func TestPathForSpec(t *testing.T) {
+	host := NewFakeVolumeHost("/tmp/fake", nil, nil)
+	spec := NewSpecFromVolume(&api.Volume{Name: "../evil"})
+	dir, err := PathForSpec(host, types.UID("poduid"), "kubernetes.io/fake", spec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "/tmp/fake/pods/poduid/volumes/kubernetes.io~fake/%2E.%2Fevil"; dir != expected {
+		t.Errorf("Expected %s, got %s", expected, dir)
+	}
+
+	SetPathSanitizer(func(name string) (string, error) { return "x/y", nil })
+	defer SetPathSanitizer(nil)
+	if dir, err := PathForSpec(host, types.UID("poduid"), "kubernetes.io/fake", spec); err == nil {
+		t.Errorf("Expected a sanitizer producing a nested path to be rejected, got %s", dir)
+	}
+}
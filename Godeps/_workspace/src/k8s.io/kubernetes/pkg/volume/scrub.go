@@ -0,0 +1,181 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// ScrubStage is a step of ScrubPath or RenameAndDelete.
+type ScrubStage string
+
+const (
+	// ScrubStageRenamed is reported once the directory was moved out of the
+	// way; Path is its new location.
+	ScrubStageRenamed ScrubStage = "Renamed"
+	// ScrubStageVerified is reported once no mount was found beneath Path.
+	ScrubStageVerified ScrubStage = "Verified"
+	// ScrubStageRemoved is reported for every entry that was removed.
+	ScrubStageRemoved ScrubStage = "Removed"
+)
+
+// ScrubProgress describes a step of ScrubPath or RenameAndDelete.
+type ScrubProgress struct {
+	Stage ScrubStage
+	Path  string
+}
+
+// ScrubProgressFunc is called with every step of ScrubPath and
+// RenameAndDelete.  It may be nil.
+type ScrubProgressFunc func(ScrubProgress)
+
+// ErrMountsRemain is returned by ScrubPath and RenameAndDelete when
+// something is still mounted beneath the directory, which would be
+// deleted through the mount otherwise.
+type ErrMountsRemain struct {
+	Path        string
+	MountPoints []string
+}
+
+func (e *ErrMountsRemain) Error() string {
+	return fmt.Sprintf("refusing to delete %s, mounts remain beneath it: %s", e.Path, strings.Join(e.MountPoints, ", "))
+}
+
+// ScrubPath removes everything in dir, but not dir itself, after verifying
+// that neither dir nor anything beneath it is a mount point.
+func ScrubPath(mounter mount.Interface, dir string, progress ScrubProgressFunc) error {
+	if err := verifyNoMounts(mounter, dir); err != nil {
+		return err
+	}
+	reportScrub(progress, ScrubStageVerified, dir)
+	return removeContents(dir, progress)
+}
+
+// removeContents removes everything in dir, but not dir itself, without
+// checking for mounts.
+func removeContents(dir string, progress ScrubProgressFunc) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := path.Join(dir, entry.Name())
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+		reportScrub(progress, ScrubStageRemoved, p)
+	}
+	return nil
+}
+
+// RenameAndDelete moves dir with MoveDirectory to a sibling named after it
+// with a ".deleting~" suffix, so that dir can be reused right away and a
+// partial deletion is recognizable, then verifies that nothing is mounted beneath it and
+// removes it.  A missing dir is not an error.
+func RenameAndDelete(mounter mount.Interface, dir string, progress ScrubProgressFunc) error {
+	if _, err := os.Lstat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	// Moving a mount point would fail, but check first for a clear error.
+	if err := verifyNoMounts(mounter, dir); err != nil {
+		return err
+	}
+	newPath, err := MoveDirectory(dir, path.Base(path.Clean(dir))+".deleting~")
+	if err != nil {
+		return err
+	}
+	reportScrub(progress, ScrubStageRenamed, newPath)
+
+	// Mounts beneath dir moved along with it.
+	if err := ScrubPath(mounter, newPath, progress); err != nil {
+		return err
+	}
+	if err := os.Remove(newPath); err != nil {
+		return err
+	}
+	reportScrub(progress, ScrubStageRemoved, newPath)
+	return nil
+}
+
+// verifyNoMounts returns an ErrMountsRemain if dir or anything beneath it is
+// a mount point.
+func verifyNoMounts(mounter mount.Interface, dir string) error {
+	remaining, err := mountsBeneath(mounter, dir)
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return &ErrMountsRemain{Path: path.Clean(dir), MountPoints: remaining}
+	}
+	return nil
+}
+
+// UnmountBeneath unmounts everything the mounter lists at or beneath dir,
+// the deepest mount points first, so that dir can be deleted with
+// RenameAndDelete.
+func UnmountBeneath(mounter mount.Interface, dir string) error {
+	mountPoints, err := mountsBeneath(mounter, dir)
+	if err != nil {
+		return err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(mountPoints)))
+	for _, mp := range mountPoints {
+		if err := mounter.Unmount(mp); err != nil {
+			return fmt.Errorf("failed to unmount %s: %v", mp, err)
+		}
+	}
+	return nil
+}
+
+// mountsBeneath returns the mount points at or beneath dir.
+func mountsBeneath(mounter mount.Interface, dir string) ([]string, error) {
+	mountPoints, err := mounter.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mount points: %v", err)
+	}
+	dir = path.Clean(dir)
+	// The mount table holds resolved paths.
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		resolved = dir
+	}
+
+	found := []string{}
+	for _, mp := range mountPoints {
+		for _, d := range []string{dir, resolved} {
+			if mp.Path == d || strings.HasPrefix(mp.Path, d+"/") {
+				found = append(found, mp.Path)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func reportScrub(progress ScrubProgressFunc, stage ScrubStage, path string) {
+	if progress != nil {
+		progress(ScrubProgress{Stage: stage, Path: path})
+	}
+}
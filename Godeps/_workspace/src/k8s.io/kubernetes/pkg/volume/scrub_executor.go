@@ -0,0 +1,173 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// hostShredScript overwrites and removes every file beneath $1.
+const hostShredScript = "find \"$1\" -mindepth 1 -xdev -type f -exec shred -f -u -z -n 1 {} +"
+
+// ScrubExecutor runs the scrub of a recycled volume.  Recyclers pick the
+// executor of their volume, e.g. to scrub volumes the host can reach without
+// creating a recycler pod.
+type ScrubExecutor interface {
+	// Scrub scrubs the volume, giving up with an ErrRecycleTimeout after
+	// timeout unless it is 0, and reports its progress to progress, which
+	// may be nil.
+	Scrub(timeout time.Duration, progress RecycleProgressFunc) error
+}
+
+// NewPodScrubExecutor returns a ScrubExecutor which runs pod, a recycler pod
+// set up by a Scrubber, and watches it until it completes.
+func NewPodScrubExecutor(pod *api.Pod, kubeClient client.Interface) ScrubExecutor {
+	return &podScrubExecutor{pod: pod, kubeClient: kubeClient}
+}
+
+type podScrubExecutor struct {
+	pod        *api.Pod
+	kubeClient client.Interface
+}
+
+func (e *podScrubExecutor) Scrub(timeout time.Duration, progress RecycleProgressFunc) error {
+	return RecycleVolumeByWatchingPodWithProgress(e.pod, e.kubeClient, timeout, progress)
+}
+
+// HostScrubMount is a filesystem a host ScrubExecutor mounts in order to
+// scrub it, e.g. an NFS export.
+type HostScrubMount struct {
+	Source  string
+	FSType  string
+	Options []string
+}
+
+// hostScrubStrategies are the strategies host ScrubExecutors support.  A
+// reformat needs the block device of a volume, which hostPath and NFS
+// volumes have none of.
+var hostScrubStrategies = []ScrubStrategy{ScrubStrategyDelete, ScrubStrategyShred}
+
+// NewHostScrubExecutor returns a ScrubExecutor which scrubs dir, a directory
+// of the host, with strategy, refusing to delete anything if a mount is
+// beneath dir.  It is meant for volumes the host reaches directly, e.g.
+// hostPath volumes.
+func NewHostScrubExecutor(strategy ScrubStrategy, dir string, mounter mount.Interface, exe exec.Interface) (ScrubExecutor, error) {
+	if err := checkHostScrubStrategy(strategy); err != nil {
+		return nil, err
+	}
+	return &hostScrubExecutor{strategy: strategy, dir: dir, mounter: mounter, exe: exe}, nil
+}
+
+// NewMountingHostScrubExecutor returns a ScrubExecutor which mounts source
+// at a temporary directory of the host, scrubs it with strategy and
+// unmounts it again.  It is meant for network volumes the host can mount,
+// e.g. NFS volumes.
+func NewMountingHostScrubExecutor(strategy ScrubStrategy, source HostScrubMount, mounter mount.Interface, exe exec.Interface) (ScrubExecutor, error) {
+	if err := checkHostScrubStrategy(strategy); err != nil {
+		return nil, err
+	}
+	return &hostScrubExecutor{strategy: strategy, source: &source, mounter: mounter, exe: exe}, nil
+}
+
+func checkHostScrubStrategy(strategy ScrubStrategy) error {
+	for _, s := range hostScrubStrategies {
+		if s == strategy {
+			return nil
+		}
+	}
+	return &ErrScrubStrategyNotSupported{Strategy: strategy, Supported: hostScrubStrategies}
+}
+
+type hostScrubExecutor struct {
+	strategy ScrubStrategy
+	// dir is scrubbed unless source is set.
+	dir     string
+	source  *HostScrubMount
+	mounter mount.Interface
+	exe     exec.Interface
+}
+
+// Scrub scrubs the volume.  A scrub that times out keeps running in the
+// background, and unmounts the volume once it is done.
+func (e *hostScrubExecutor) Scrub(timeout time.Duration, progress RecycleProgressFunc) error {
+	if timeout == 0 {
+		return e.scrub(progress)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- e.scrub(progress)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return &ErrRecycleTimeout{Volume: e.volume(), Timeout: timeout}
+	}
+}
+
+func (e *hostScrubExecutor) volume() string {
+	if e.source != nil {
+		return e.source.Source
+	}
+	return e.dir
+}
+
+func (e *hostScrubExecutor) scrub(progress RecycleProgressFunc) error {
+	dir := e.dir
+	if e.source != nil {
+		var err error
+		if dir, err = ioutil.TempDir("", "volume-scrub-"); err != nil {
+			return err
+		}
+		reportRecycle(progress, fmt.Sprintf("mounting %s at %s", e.source.Source, dir))
+		if err := e.mounter.Mount(e.source.Source, dir, e.source.FSType, e.source.Options); err != nil {
+			os.Remove(dir)
+			return fmt.Errorf("failed to mount %s: %v", e.source.Source, err)
+		}
+		defer func() {
+			if err := UnmountPath(dir, e.mounter); err != nil {
+				glog.Errorf("Failed to unmount %s after scrubbing %s: %v", dir, e.source.Source, err)
+			}
+		}()
+	} else if err := verifyNoMounts(e.mounter, dir); err != nil {
+		// The delete would reach the volumes mounted beneath dir.
+		return err
+	}
+
+	reportRecycle(progress, fmt.Sprintf("scrubbing %s with strategy %s", e.volume(), e.strategy))
+	if e.strategy == ScrubStrategyShred {
+		if output, err := e.exe.Command("/bin/sh", "-c", hostShredScript, "scrub", dir).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to shred %s: %v, output: %q", e.volume(), err, string(output))
+		}
+	}
+	return removeContents(dir, nil)
+}
+
+func reportRecycle(progress RecycleProgressFunc, message string) {
+	if progress != nil {
+		progress(message)
+	}
+}
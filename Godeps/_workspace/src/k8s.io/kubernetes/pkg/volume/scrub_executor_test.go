@@ -0,0 +1,176 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+func makeScrubDir(t *testing.T) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "scrubExecutorTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	if err := os.MkdirAll(path.Join(dir, "sub"), 0750); err != nil {
+		t.Fatalf("can't make a dir: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "sub/file"), []byte("data"), 0640); err != nil {
+		t.Fatalf("can't write a file: %v", err)
+	}
+	return dir
+}
+
+// recordingExec returns a FakeExec which records the argv of its commands in
+// argvs and runs them with run.
+func recordingExec(argvs *[][]string, run func() ([]byte, error)) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for i := 0; i < 5; i++ {
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			*argvs = append(*argvs, append([]string{cmd}, args...))
+			return &exec.FakeCmd{CombinedOutputScript: []exec.FakeCombinedOutputAction{run}}
+		})
+	}
+	return fake
+}
+
+func TestHostScrubExecutorDelete(t *testing.T) {
+	dir := makeScrubDir(t)
+	defer os.RemoveAll(dir)
+
+	argvs := [][]string{}
+	executor, err := NewHostScrubExecutor(ScrubStrategyDelete, dir, &mount.FakeMounter{}, recordingExec(&argvs, nil))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	messages := []string{}
+	if err := executor.Scrub(0, func(message string) { messages = append(messages, message) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected %s to be empty, got %v: %v", dir, entries, err)
+	}
+	if len(argvs) != 0 {
+		t.Errorf("Expected no command, got %v", argvs)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected the scrub to be reported, got %v", messages)
+	}
+}
+
+func TestHostScrubExecutorShred(t *testing.T) {
+	dir := makeScrubDir(t)
+	defer os.RemoveAll(dir)
+
+	argvs := [][]string{}
+	fakeExec := recordingExec(&argvs, func() ([]byte, error) { return nil, nil })
+	executor, err := NewHostScrubExecutor(ScrubStrategyShred, dir, &mount.FakeMounter{}, fakeExec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := executor.Scrub(0, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"/bin/sh", "-c", hostShredScript, "scrub", dir}}
+	if !reflect.DeepEqual(argvs, expected) {
+		t.Errorf("Expected %v, got %v", expected, argvs)
+	}
+	if _, err := os.Stat(path.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Errorf("Expected the contents to be removed after the shred, got: %v", err)
+	}
+}
+
+func TestHostScrubExecutorRefusesMounts(t *testing.T) {
+	dir := makeScrubDir(t)
+	defer os.RemoveAll(dir)
+
+	mounter := &mount.FakeMounter{MountPoints: []mount.MountPoint{{Device: "/dev/sdb", Path: path.Join(dir, "sub")}}}
+	executor, err := NewHostScrubExecutor(ScrubStrategyDelete, dir, mounter, &exec.FakeExec{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := executor.Scrub(0, nil); err == nil {
+		t.Errorf("Expected an error")
+	} else if _, ok := err.(*ErrMountsRemain); !ok {
+		t.Errorf("Expected an ErrMountsRemain, got %v", err)
+	}
+	if _, err := os.Stat(path.Join(dir, "sub/file")); err != nil {
+		t.Errorf("Expected the file to be kept: %v", err)
+	}
+}
+
+func TestHostScrubExecutorRejectsReformat(t *testing.T) {
+	if _, err := NewHostScrubExecutor(ScrubStrategyReformat, "/vol", &mount.FakeMounter{}, &exec.FakeExec{}); err == nil {
+		t.Errorf("Expected an error")
+	} else if _, ok := err.(*ErrScrubStrategyNotSupported); !ok {
+		t.Errorf("Expected an ErrScrubStrategyNotSupported, got %v", err)
+	}
+}
+
+func TestMountingHostScrubExecutor(t *testing.T) {
+	mounter := &mount.FakeMounter{}
+	source := HostScrubMount{Source: "nfs.example.com:/exports/vol1", FSType: "nfs"}
+	executor, err := NewMountingHostScrubExecutor(ScrubStrategyDelete, source, mounter, &exec.FakeExec{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := executor.Scrub(0, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(mounter.Log) != 2 || mounter.Log[0].Action != mount.FakeActionMount || mounter.Log[1].Action != mount.FakeActionUnmount {
+		t.Fatalf("Expected a mount and an unmount, got %#v", mounter.Log)
+	}
+	if mounter.Log[0].Source != source.Source || mounter.Log[0].FSType != "nfs" {
+		t.Errorf("Expected %s to be mounted, got %#v", source.Source, mounter.Log[0])
+	}
+	if mounter.Log[1].Target != mounter.Log[0].Target {
+		t.Errorf("Expected %s to be unmounted, got %s", mounter.Log[0].Target, mounter.Log[1].Target)
+	}
+	if _, err := os.Stat(mounter.Log[0].Target); !os.IsNotExist(err) {
+		t.Errorf("Expected the mount point to be removed, got: %v", err)
+	}
+}
+
+func TestHostScrubExecutorTimeout(t *testing.T) {
+	dir := makeScrubDir(t)
+	defer os.RemoveAll(dir)
+
+	release := make(chan struct{})
+	defer close(release)
+	argvs := [][]string{}
+	fakeExec := recordingExec(&argvs, func() ([]byte, error) {
+		<-release
+		return nil, nil
+	})
+	executor, err := NewHostScrubExecutor(ScrubStrategyShred, dir, &mount.FakeMounter{}, fakeExec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err = executor.Scrub(10*time.Millisecond, nil)
+	if _, ok := err.(*ErrRecycleTimeout); !ok {
+		t.Errorf("Expected an ErrRecycleTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+func TestRenameAndDelete(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "scrub_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dir := path.Join(tmpDir, "vol")
+	if err := os.MkdirAll(path.Join(dir, "sub"), 0750); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "file"), []byte("data"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stages := map[ScrubStage]int{}
+	progress := func(p ScrubProgress) {
+		stages[p.Stage]++
+		if !strings.HasPrefix(p.Path, dir+".deleting~") {
+			t.Errorf("Unexpected path %s", p.Path)
+		}
+	}
+	if err := RenameAndDelete(&mount.FakeMounter{}, dir, progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entries, _ := ioutil.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("Expected %s to be empty, got %v", tmpDir, entries)
+	}
+	if stages[ScrubStageRenamed] != 1 || stages[ScrubStageVerified] != 1 || stages[ScrubStageRemoved] != 3 {
+		t.Errorf("Unexpected progress: %v", stages)
+	}
+
+	// A missing directory is already deleted.
+	if err := RenameAndDelete(&mount.FakeMounter{}, dir, nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestScrubPathRefusesMounts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "scrub_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	mountPath := path.Join(tmpDir, "sub")
+	if err := os.Mkdir(mountPath, 0750); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mounter := &mount.FakeMounter{MountPoints: []mount.MountPoint{{Device: "/dev/sdb", Path: mountPath}}}
+
+	for _, fn := range []func() error{
+		func() error { return ScrubPath(mounter, tmpDir, nil) },
+		func() error { return RenameAndDelete(mounter, tmpDir, nil) },
+	} {
+		err := fn()
+		if mountsErr, ok := err.(*ErrMountsRemain); !ok {
+			t.Errorf("Expected ErrMountsRemain, got %v", err)
+		} else if len(mountsErr.MountPoints) != 1 || mountsErr.MountPoints[0] != mountPath {
+			t.Errorf("Unexpected mount points %v", mountsErr.MountPoints)
+		}
+		if _, err := os.Stat(mountPath); err != nil {
+			t.Errorf("Expected %s to be kept: %v", mountPath, err)
+		}
+	}
+}
+
+func TestUnmountBeneath(t *testing.T) {
+	mounter := &mount.FakeMounter{MountPoints: []mount.MountPoint{
+		{Device: "tmpfs", Path: "/pods/uid/vol"},
+		{Device: "/dev/sdb", Path: "/pods/uid/vol/sub"},
+		{Device: "/dev/sdc", Path: "/pods/uid/vol2"},
+	}}
+	if err := UnmountBeneath(mounter, "/pods/uid/vol"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []mount.FakeAction{
+		{Action: mount.FakeActionUnmount, Target: "/pods/uid/vol/sub"},
+		{Action: mount.FakeActionUnmount, Target: "/pods/uid/vol"},
+	}
+	if !reflect.DeepEqual(mounter.Log, expected) {
+		t.Errorf("Expected %v, got %v", expected, mounter.Log)
+	}
+	if len(mounter.MountPoints) != 1 || mounter.MountPoints[0].Path != "/pods/uid/vol2" {
+		t.Errorf("Expected only the other volume to stay mounted, got %v", mounter.MountPoints)
+	}
+}
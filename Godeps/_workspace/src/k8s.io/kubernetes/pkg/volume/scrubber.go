@@ -0,0 +1,149 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// RecycleScrubberAnnotation selects the ScrubStrategy used to recycle a
+// PersistentVolume.  Volumes without it are scrubbed with the default
+// strategy of their plugin.
+const RecycleScrubberAnnotation = "volume.alpha.kubernetes.io/recycle-scrubber"
+
+// ScrubStrategy is a way of destroying the data of a recycled volume.
+type ScrubStrategy string
+
+const (
+	// ScrubStrategyDelete deletes the files of the volume with the command
+	// of the recycler pod template.  Their data may remain on the storage.
+	ScrubStrategyDelete ScrubStrategy = "delete"
+	// ScrubStrategyShred overwrites the files of the volume before
+	// deleting them, so that their data cannot be read back from the
+	// storage.  The recycler pod image has to provide shred.
+	ScrubStrategyShred ScrubStrategy = "shred"
+	// ScrubStrategyReformat makes a new filesystem on the block device of
+	// the volume, which is only possible for volumes backed by one and
+	// requires a privileged recycler pod.
+	ScrubStrategyReformat ScrubStrategy = "reformat"
+)
+
+// shredScript overwrites and removes every file beneath /scrub, then
+// removes what is left like the default recycler pod template does.
+const shredScript = "test -e /scrub && find /scrub -mindepth 1 -type f -exec shred -f -u -z -n 1 {} + && find /scrub -mindepth 1 -maxdepth 1 -exec rm -rf {} + && test -z \"$(ls -A /scrub)\" || exit 1"
+
+// reformatScript unmounts the device mounted at /scrub and makes a new
+// filesystem of the same type on it.
+const reformatScript = "dev=$(awk '$2 == \"/scrub\" {print $1}' /proc/mounts) && fstype=$(awk '$2 == \"/scrub\" {print $3}' /proc/mounts) && test -b \"$dev\" && umount /scrub && mkfs -t \"$fstype\" \"$dev\" || exit 1"
+
+// Scrubber sets up recycler pods to scrub their volume with a ScrubStrategy.
+type Scrubber interface {
+	// Strategy returns the strategy of the scrubber.
+	Strategy() ScrubStrategy
+	// SetUpRecyclerPod makes pod, as returned by NewRecyclerPod, scrub its
+	// first volume with the strategy of the scrubber.
+	SetUpRecyclerPod(pod *api.Pod)
+}
+
+// ScrubberFactory makes the Scrubber selected for a PersistentVolume by its
+// RecycleScrubberAnnotation.
+type ScrubberFactory interface {
+	NewScrubber(pv *api.PersistentVolume) (Scrubber, error)
+}
+
+// ErrScrubStrategyNotSupported is returned by ScrubberFactories for
+// volumes which select a strategy their plugin does not support.
+type ErrScrubStrategyNotSupported struct {
+	Strategy  ScrubStrategy
+	Supported []ScrubStrategy
+}
+
+func (e *ErrScrubStrategyNotSupported) Error() string {
+	return fmt.Sprintf("scrub strategy %q is not supported, supported strategies are %v", e.Strategy, e.Supported)
+}
+
+// NewScrubberFactory returns a ScrubberFactory which supports strategies,
+// the first of which is the default.  Plugins should only list strategies
+// their volumes can be scrubbed with, e.g. not ScrubStrategyReformat for
+// volumes which are not block devices.
+func NewScrubberFactory(strategies ...ScrubStrategy) ScrubberFactory {
+	return &scrubberFactory{strategies: strategies}
+}
+
+type scrubberFactory struct {
+	strategies []ScrubStrategy
+}
+
+func (f *scrubberFactory) NewScrubber(pv *api.PersistentVolume) (Scrubber, error) {
+	if len(f.strategies) == 0 {
+		return nil, fmt.Errorf("no scrub strategy is supported")
+	}
+	strategy := f.strategies[0]
+	if pv != nil {
+		if s, ok := pv.Annotations[RecycleScrubberAnnotation]; ok {
+			strategy = ScrubStrategy(s)
+		}
+	}
+	for _, s := range f.strategies {
+		if s == strategy {
+			return newScrubber(strategy)
+		}
+	}
+	return nil, &ErrScrubStrategyNotSupported{Strategy: strategy, Supported: f.strategies}
+}
+
+func newScrubber(strategy ScrubStrategy) (Scrubber, error) {
+	switch strategy {
+	case ScrubStrategyDelete:
+		return &commandScrubber{strategy: strategy}, nil
+	case ScrubStrategyShred:
+		return &commandScrubber{strategy: strategy, script: shredScript}, nil
+	case ScrubStrategyReformat:
+		return &commandScrubber{strategy: strategy, script: reformatScript, privileged: true}, nil
+	}
+	return nil, fmt.Errorf("unknown scrub strategy %q", strategy)
+}
+
+// commandScrubber replaces the command of the recycler pod with script, or
+// keeps the command of the template if script is empty.
+type commandScrubber struct {
+	strategy   ScrubStrategy
+	script     string
+	privileged bool
+}
+
+func (s *commandScrubber) Strategy() ScrubStrategy {
+	return s.strategy
+}
+
+func (s *commandScrubber) SetUpRecyclerPod(pod *api.Pod) {
+	if s.script == "" || len(pod.Spec.Containers) == 0 {
+		return
+	}
+	container := &pod.Spec.Containers[0]
+	container.Command = []string{"/bin/sh"}
+	container.Args = []string{"-c", s.script}
+	if s.privileged {
+		privileged := true
+		if container.SecurityContext == nil {
+			container.SecurityContext = &api.SecurityContext{}
+		}
+		container.SecurityContext.Privileged = &privileged
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestScrubberFactory(t *testing.T) {
+	factory := NewScrubberFactory(ScrubStrategyDelete, ScrubStrategyShred)
+	newPV := func(strategy string) *api.PersistentVolume {
+		pv := &api.PersistentVolume{}
+		if strategy != "" {
+			pv.Annotations = map[string]string{RecycleScrubberAnnotation: strategy}
+		}
+		return pv
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		expected ScrubStrategy
+		err      bool
+	}{
+		{name: "default", expected: ScrubStrategyDelete},
+		{name: "shred", strategy: "shred", expected: ScrubStrategyShred},
+		{name: "unsupported", strategy: "reformat", err: true},
+		{name: "unknown", strategy: "burn", err: true},
+	}
+	for _, test := range tests {
+		scrubber, err := factory.NewScrubber(newPV(test.strategy))
+		if test.err {
+			if _, ok := err.(*ErrScrubStrategyNotSupported); !ok {
+				t.Errorf("%s: expected ErrScrubStrategyNotSupported, got %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if scrubber.Strategy() != test.expected {
+			t.Errorf("%s: expected strategy %s, got %s", test.name, test.expected, scrubber.Strategy())
+		}
+	}
+}
+
+func TestScrubberSetUpRecyclerPod(t *testing.T) {
+	template := NewPersistentVolumeRecyclerPodTemplate()
+	config := VolumeConfig{RecyclerPodTemplate: template}
+	factory := NewScrubberFactory(ScrubStrategyDelete, ScrubStrategyShred, ScrubStrategyReformat)
+
+	for _, strategy := range []ScrubStrategy{ScrubStrategyDelete, ScrubStrategyShred, ScrubStrategyReformat} {
+		pod, err := NewRecyclerPod(config, "pv-recycler-test-", 60, api.VolumeSource{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		scrubber, err := factory.NewScrubber(&api.PersistentVolume{ObjectMeta: api.ObjectMeta{
+			Annotations: map[string]string{RecycleScrubberAnnotation: string(strategy)},
+		}})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", strategy, err)
+		}
+		scrubber.SetUpRecyclerPod(pod)
+
+		container := pod.Spec.Containers[0]
+		script := container.Args[len(container.Args)-1]
+		switch strategy {
+		case ScrubStrategyDelete:
+			if script != template.Spec.Containers[0].Args[1] {
+				t.Errorf("Expected the delete scrubber to keep the command of the template, got %q", script)
+			}
+		case ScrubStrategyShred:
+			if script != shredScript {
+				t.Errorf("Expected the shred script, got %q", script)
+			}
+		case ScrubStrategyReformat:
+			if script != reformatScript {
+				t.Errorf("Expected the reformat script, got %q", script)
+			}
+			if container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+				t.Errorf("Expected the reformat scrubber to make the pod privileged")
+			}
+		}
+	}
+	if template.Spec.Containers[0].SecurityContext != nil {
+		t.Errorf("Expected the template to be left alone")
+	}
+}
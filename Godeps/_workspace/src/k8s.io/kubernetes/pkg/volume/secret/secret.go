@@ -24,8 +24,6 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util"
-	ioutil "k8s.io/kubernetes/pkg/util/io"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
 	volumeutil "k8s.io/kubernetes/pkg/volume/util"
@@ -38,6 +36,8 @@ func ProbeVolumePlugins() []volume.VolumePlugin {
 
 const (
 	secretPluginName = "kubernetes.io/secret"
+	// defaultFileMode is the mode of secret files whose mode isn't set.
+	defaultFileMode = 0444
 )
 
 // secretPlugin implements the VolumePlugin interface.
@@ -59,16 +59,24 @@ func (plugin *secretPlugin) CanSupport(spec *volume.Spec) bool {
 	return spec.Volume != nil && spec.Volume.Secret != nil
 }
 
+func (plugin *secretPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.Secret == nil {
+		return "", fmt.Errorf("spec does not reference a secret volume")
+	}
+	return spec.Name(), nil
+}
+
 func (plugin *secretPlugin) NewBuilder(spec *volume.Spec, pod *api.Pod, opts volume.VolumeOptions) (volume.Builder, error) {
 	return &secretVolumeBuilder{
-		secretVolume: &secretVolume{spec.Name(), pod.UID, plugin, plugin.host.GetMounter(), plugin.host.GetWriter()},
+		secretVolume: &secretVolume{spec.Name(), pod.UID, plugin, plugin.host.GetMounter()},
 		secretName:   spec.Volume.Secret.SecretName,
+		items:        spec.Volume.Secret.Items,
 		pod:          *pod,
 		opts:         &opts}, nil
 }
 
 func (plugin *secretPlugin) NewCleaner(volName string, podUID types.UID) (volume.Cleaner, error) {
-	return &secretVolumeCleaner{&secretVolume{volName, podUID, plugin, plugin.host.GetMounter(), plugin.host.GetWriter()}}, nil
+	return &secretVolumeCleaner{&secretVolume{volName, podUID, plugin, plugin.host.GetMounter()}}, nil
 }
 
 type secretVolume struct {
@@ -76,13 +84,12 @@ type secretVolume struct {
 	podUID  types.UID
 	plugin  *secretPlugin
 	mounter mount.Interface
-	writer  ioutil.Writer
 }
 
 var _ volume.Volume = &secretVolume{}
 
 func (sv *secretVolume) GetPath() string {
-	return sv.plugin.host.GetPodVolumeDir(sv.podUID, util.EscapeQualifiedNameForDisk(secretPluginName), sv.volName)
+	return volume.NewDirectoryLayout(sv.plugin.host).PodVolumeDir(sv.podUID, secretPluginName, sv.volName)
 }
 
 // secretVolumeBuilder handles retrieving secrets from the API server
@@ -91,16 +98,13 @@ type secretVolumeBuilder struct {
 	*secretVolume
 
 	secretName string
+	items      []api.KeyToPath
 	pod        api.Pod
 	opts       *volume.VolumeOptions
 }
 
 var _ volume.Builder = &secretVolumeBuilder{}
 
-func (_ *secretVolumeBuilder) SupportsOwnershipManagement() bool {
-	return true
-}
-
 func (b *secretVolumeBuilder) SetUp() error {
 	return b.SetUpAt(b.GetPath())
 }
@@ -111,7 +115,7 @@ var wrappedVolumeSpec = &volume.Spec{
 }
 
 func (b *secretVolumeBuilder) getMetaDir() string {
-	return path.Join(b.plugin.host.GetPodPluginDir(b.podUID, util.EscapeQualifiedNameForDisk(secretPluginName)), b.volName)
+	return path.Join(volume.NewDirectoryLayout(b.plugin.host).PodPluginDir(b.podUID, secretPluginName), b.volName)
 }
 
 func (b *secretVolumeBuilder) SetUpAt(dir string) error {
@@ -123,20 +127,20 @@ func (b *secretVolumeBuilder) SetUpAt(dir string) error {
 	}
 
 	// If the plugin readiness file is present for this volume and
-	// the setup dir is a mountpoint, this volume is already ready.
-	if volumeutil.IsReady(b.getMetaDir()) && !notMnt {
-		return nil
-	}
-
-	glog.V(3).Infof("Setting up volume %v for pod %v at %v", b.volName, b.pod.UID, dir)
-
-	// Wrap EmptyDir, let it do the setup.
-	wrapped, err := b.plugin.host.NewWrapperBuilder(wrappedVolumeSpec, &b.pod, *b.opts)
-	if err != nil {
-		return err
-	}
-	if err := wrapped.SetUpAt(dir); err != nil {
-		return err
+	// the setup dir is a mountpoint, the tmpfs is already set up and
+	// only the secret data is refreshed.
+	ready := volumeutil.IsReady(b.getMetaDir()) && !notMnt
+	if !ready {
+		glog.V(3).Infof("Setting up volume %v for pod %v at %v", b.volName, b.pod.UID, dir)
+
+		// Wrap EmptyDir, let it do the setup.
+		wrapped, err := b.plugin.host.NewWrapperBuilder(wrappedVolumeSpec, &b.pod, *b.opts)
+		if err != nil {
+			return err
+		}
+		if err := wrapped.SetUpAt(dir); err != nil {
+			return err
+		}
 	}
 
 	kubeClient := b.plugin.host.GetKubeClient()
@@ -147,6 +151,10 @@ func (b *secretVolumeBuilder) SetUpAt(dir string) error {
 	secret, err := kubeClient.Secrets(b.pod.Namespace).Get(b.secretName)
 	if err != nil {
 		glog.Errorf("Couldn't get secret %v/%v", b.pod.Namespace, b.secretName)
+		if ready {
+			// Keep serving the data already in the volume.
+			return nil
+		}
 		return err
 	} else {
 		totalBytes := totalSecretBytes(secret)
@@ -157,14 +165,17 @@ func (b *secretVolumeBuilder) SetUpAt(dir string) error {
 			totalBytes)
 	}
 
-	for name, data := range secret.Data {
-		hostFilePath := path.Join(dir, name)
-		glog.V(3).Infof("Writing secret data %v/%v/%v (%v bytes) to host file %v", b.pod.Namespace, b.secretName, name, len(data), hostFilePath)
-		err := b.writer.WriteFile(hostFilePath, data, 0444)
-		if err != nil {
-			glog.Errorf("Error writing secret data to host path: %v, %v", hostFilePath, err)
-			return err
-		}
+	payload, err := MakePayload(b.items, secret, nil)
+	if err != nil {
+		return err
+	}
+	writer, err := volume.NewAtomicWriter(dir, fmt.Sprintf("secret volume %v for pod %v", b.volName, b.pod.UID))
+	if err != nil {
+		return err
+	}
+	if err := writer.Write(payload); err != nil {
+		glog.Errorf("Error writing secret data %v/%v to %v: %v", b.pod.Namespace, b.secretName, dir, err)
+		return err
 	}
 
 	volumeutil.SetReady(b.getMetaDir())
@@ -172,12 +183,42 @@ func (b *secretVolumeBuilder) SetUpAt(dir string) error {
 	return nil
 }
 
-func (sv *secretVolume) IsReadOnly() bool {
-	return false
+func (b *secretVolumeBuilder) GetAttributes() volume.Attributes {
+	return volume.Attributes{
+		ReadOnly:            false,
+		Managed:             true,
+		FSGroupChangePolicy: volume.FSGroupChangeAlways,
+		SupportsSELinux:     true,
+	}
 }
 
-func (sv *secretVolume) SupportsSELinux() bool {
-	return true
+// MakePayload returns the files to project secret into: the keys selected by
+// items at their paths, or every key under its own name if items is empty.
+// Files whose mode isn't set by their item get defaultMode, 0444 if nil.
+func MakePayload(items []api.KeyToPath, secret *api.Secret, defaultMode *int32) (map[string]volume.FileProjection, error) {
+	fileMode := int32(defaultFileMode)
+	if defaultMode != nil {
+		fileMode = *defaultMode
+	}
+	payload := make(map[string]volume.FileProjection, len(secret.Data))
+	if len(items) == 0 {
+		for name, data := range secret.Data {
+			payload[name] = volume.FileProjection{Data: data, Mode: fileMode}
+		}
+		return payload, nil
+	}
+	for _, item := range items {
+		data, ok := secret.Data[item.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %v/%v has no key %q", secret.Namespace, secret.Name, item.Key)
+		}
+		mode := fileMode
+		if item.Mode != nil {
+			mode = *item.Mode
+		}
+		payload[item.Path] = volume.FileProjection{Data: data, Mode: mode}
+	}
+	return payload, nil
 }
 
 func totalSecretBytes(secret *api.Secret) int {
@@ -27,6 +27,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/volume"
@@ -115,7 +116,7 @@ func TestPlugin(t *testing.T) {
 }
 
 // Test the case where the 'ready' file has been created and the pod volume dir
-// is a mountpoint.  Mount should not be called.
+// is a mountpoint.  Mount should not be called, but the secret data is refreshed.
 func TestPluginIdempotent(t *testing.T) {
 	var (
 		testPodUID     = types.UID("test_pod_uid2")
@@ -147,6 +148,9 @@ func TestPluginIdempotent(t *testing.T) {
 		},
 	}
 	util.SetReady(podMetadataDir)
+	if err := os.MkdirAll(podVolumeDir, 0750); err != nil {
+		t.Fatalf("Can't make the volume dir: %v", err)
+	}
 	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
 	if err != nil {
 		t.Errorf("Failed to make a new Builder: %v", err)
@@ -165,13 +169,7 @@ func TestPluginIdempotent(t *testing.T) {
 		t.Errorf("Unexpected calls made to mounter: %v", mounter.Log)
 	}
 
-	if _, err := os.Stat(volumePath); err != nil {
-		if !os.IsNotExist(err) {
-			t.Errorf("SetUp() failed unexpectedly: %v", err)
-		}
-	} else {
-		t.Errorf("volume path should not exist: %v", volumePath)
-	}
+	doTestSecretDataInVolume(volumePath, secret, t)
 }
 
 // Test the case where the plugin's ready file exists, but the volume dir is not a
@@ -230,6 +228,110 @@ func TestPluginReboot(t *testing.T) {
 	doTestCleanAndTeardown(plugin, testPodUID, testVolumeName, volumePath, t)
 }
 
+func TestPluginItems(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid4")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_secret_namespace"
+		testName       = "test_secret_name"
+
+		volumeSpec = volumeSpec(testVolumeName, testName)
+		secret     = secret(testNamespace, testName)
+		client     = testclient.NewSimpleFake(&secret)
+		pluginMgr  = volume.VolumePluginMgr{}
+		_, host    = newTestHost(t, client)
+		mode       = int32(0400)
+	)
+	volumeSpec.Secret.Items = []api.KeyToPath{
+		{Key: "data-1", Path: "one"},
+		{Key: "data-2", Path: "dir/two", Mode: &mode},
+	}
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(secretPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+
+	volumePath := builder.GetPath()
+	for file, expected := range map[string]struct {
+		value string
+		mode  os.FileMode
+	}{
+		"one":     {"value-1", 0444},
+		"dir/two": {"value-2", 0400},
+	} {
+		filePath := path.Join(volumePath, file)
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Errorf("Couldn't read %v: %v", filePath, err)
+			continue
+		}
+		if string(data) != expected.value {
+			t.Errorf("Unexpected value of %v; expected %q, got %q", file, expected.value, data)
+		}
+		if info, err := os.Stat(filePath); err != nil || info.Mode().Perm() != expected.mode {
+			t.Errorf("Expected %v to have mode %v, got %v, %v", file, expected.mode, info, err)
+		}
+	}
+	if _, err := os.Stat(path.Join(volumePath, "data-3")); !os.IsNotExist(err) {
+		t.Errorf("Expected data-3 not to be projected, got %v", err)
+	}
+}
+
+func TestPluginRefresh(t *testing.T) {
+	var (
+		testPodUID     = types.UID("test_pod_uid5")
+		testVolumeName = "test_volume_name"
+		testNamespace  = "test_secret_namespace"
+		testName       = "test_secret_name"
+
+		volumeSpec = volumeSpec(testVolumeName, testName)
+		secret     = secret(testNamespace, testName)
+		client     = &testclient.Fake{}
+		pluginMgr  = volume.VolumePluginMgr{}
+		_, host    = newTestHost(t, client)
+	)
+	client.AddReactor("get", "secrets", func(action testclient.Action) (bool, runtime.Object, error) {
+		return true, &secret, nil
+	})
+
+	pluginMgr.InitPlugins(ProbeVolumePlugins(), host)
+	plugin, err := pluginMgr.FindPluginByName(secretPluginName)
+	if err != nil {
+		t.Fatalf("Can't find the plugin by name")
+	}
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: testPodUID}}
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(volumeSpec), pod, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a new Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	volumePath := builder.GetPath()
+	doTestSecretDataInVolume(volumePath, secret, t)
+
+	// The fake mounter doesn't remember the mount, pretend it does.
+	host.GetMounter().(*mount.FakeMounter).MountPoints = []mount.MountPoint{{Path: volumePath}}
+	secret.Data = map[string][]byte{"data-1": []byte("new-value-1")}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Failed to setup volume: %v", err)
+	}
+	doTestSecretDataInVolume(volumePath, secret, t)
+	if _, err := os.Stat(path.Join(volumePath, "data-2")); !os.IsNotExist(err) {
+		t.Errorf("Expected data-2 to be removed, got %v", err)
+	}
+}
+
 func volumeSpec(volumeName, secretName string) *api.Volume {
 	return &api.Volume{
 		Name: volumeName,
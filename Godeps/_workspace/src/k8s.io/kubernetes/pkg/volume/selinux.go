@@ -0,0 +1,65 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"os"
+	"path/filepath"
+
+	"k8s.io/kubernetes/pkg/util/selinux"
+)
+
+// SELinuxLabeler sets the SELinux context of volumes, for builders whose
+// SupportsSELinux returns true.
+type SELinuxLabeler interface {
+	// SetFileLabel sets the SELinux context of the single file or
+	// directory at path to label.
+	SetFileLabel(path, label string) error
+	// Relabel sets the SELinux context of the builder's volume to
+	// context.  If recursive is true everything within the volume is
+	// relabeled as well, otherwise only its root.
+	Relabel(builder Builder, context string, recursive bool) error
+}
+
+// NewSELinuxLabeler returns an SELinuxLabeler that sets contexts the way
+// chcon does.  It does nothing if SELinux is not enabled.
+func NewSELinuxLabeler() SELinuxLabeler {
+	return &chconLabeler{runner: selinux.NewChconRunner()}
+}
+
+type chconLabeler struct {
+	runner selinux.ChconRunner
+}
+
+var _ SELinuxLabeler = &chconLabeler{}
+
+func (l *chconLabeler) SetFileLabel(path, label string) error {
+	return l.runner.SetContext(path, label)
+}
+
+func (l *chconLabeler) Relabel(builder Builder, context string, recursive bool) error {
+	root := builder.GetPath()
+	if !recursive {
+		return l.SetFileLabel(root, context)
+	}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return l.SetFileLabel(path, context)
+	})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type fakeChconRunner struct {
+	labels map[string]string
+}
+
+func (f *fakeChconRunner) SetContext(dir, context string) error {
+	f.labels[dir] = context
+	return nil
+}
+
+func TestRelabel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "selinux_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	plugin := &FakeVolumePlugin{PluginName: "fake-plugin"}
+	NewFakeVolumeHost(dir, nil, []VolumePlugin{plugin})
+	builder := &FakeVolume{PodUID: "poduid", VolName: "vol", Plugin: plugin}
+	if err := builder.SetUp(); err != nil {
+		t.Fatalf("Can't set up volume: %v", err)
+	}
+	root := builder.GetPath()
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0750); err != nil {
+		t.Fatalf("Can't make a subdirectory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "sub", "file"), []byte("data"), 0640); err != nil {
+		t.Fatalf("Can't write a file: %v", err)
+	}
+	context := "system_u:object_r:svirt_sandbox_file_t:s0:c1,c2"
+
+	runner := &fakeChconRunner{labels: map[string]string{}}
+	labeler := &chconLabeler{runner: runner}
+	if err := labeler.Relabel(builder, context, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(runner.labels, map[string]string{root: context}) {
+		t.Errorf("Expected only the root to be relabeled, got %v", runner.labels)
+	}
+
+	runner.labels = map[string]string{}
+	if err := labeler.Relabel(builder, context, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	paths := []string{}
+	for path, label := range runner.labels {
+		if label != context {
+			t.Errorf("%s: expected context %q, got %q", path, context, label)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	expected := []string{root, filepath.Join(root, "sub"), filepath.Join(root, "sub", "file")}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v to be relabeled, got %v", expected, paths)
+	}
+}
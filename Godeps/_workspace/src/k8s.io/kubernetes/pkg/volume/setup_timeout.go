@@ -0,0 +1,174 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// poisonedSuffix is appended to the path of a volume directory for the
+// marker file of a poisoned directory.  The marker is kept next to the
+// directory as anything inside it may hang like the mount did.
+const poisonedSuffix = ".poisoned"
+
+// ErrSetUpTimeout is returned by SetUpWithTimeout when SetUpAt did not
+// return within its timeout.
+type ErrSetUpTimeout struct {
+	Dir     string
+	Timeout time.Duration
+}
+
+func (e *ErrSetUpTimeout) Error() string {
+	return fmt.Sprintf("setting up %s did not complete within %v", e.Dir, e.Timeout)
+}
+
+// abandonedSetUps are the directories whose SetUpAt timed out and did not
+// return yet.
+var abandonedSetUps = struct {
+	sync.Mutex
+	dirs map[string]bool
+}{dirs: map[string]bool{}}
+
+func isSetUpAbandoned(dir string) bool {
+	abandonedSetUps.Lock()
+	defer abandonedSetUps.Unlock()
+	return abandonedSetUps.dirs[dir]
+}
+
+func setSetUpAbandoned(dir string, abandoned bool) {
+	abandonedSetUps.Lock()
+	defer abandonedSetUps.Unlock()
+	if abandoned {
+		abandonedSetUps.dirs[dir] = true
+	} else {
+		delete(abandonedSetUps.dirs, dir)
+	}
+}
+
+func poisonMarker(dir string) string {
+	return path.Clean(dir) + poisonedSuffix
+}
+
+// isPoisonMarker returns whether name is the marker of a poisoned directory,
+// which is no volume of its own.
+func isPoisonMarker(name string) bool {
+	return strings.HasSuffix(name, poisonedSuffix)
+}
+
+// IsPoisoned returns whether dir is a volume directory whose setup timed out
+// and that was not torn down since.
+func IsPoisoned(dir string) bool {
+	_, err := os.Stat(poisonMarker(dir))
+	return err == nil
+}
+
+// SetUpWithTimeout runs builder.SetUpAt(dir) and gives up with an
+// ErrSetUpTimeout if it does not return within timeout, unless it is 0,
+// e.g. because the mount of a volume whose NFS server is dead hangs.
+//
+// Mounts can't be interrupted, so the abandoned SetUpAt is left running and
+// dir is marked poisoned.  Once SetUpAt returns, the setup is rolled back
+// with cleaner.TearDownAt(dir), as the caller was told that it failed, and
+// the poison is cleared.  Until then, SetUpWithTimeout and SafeTearDownAt of
+// dir fail with a transient error instead of racing the hung mount.  If the
+// rollback fails, dir is torn down before it is set up again.
+func SetUpWithTimeout(builder Builder, cleaner Cleaner, dir string, timeout time.Duration) error {
+	dir = path.Clean(dir)
+	if isSetUpAbandoned(dir) {
+		return NewTransientError(fmt.Sprintf("an earlier setup of %s that timed out is still running", dir))
+	}
+	if IsPoisoned(dir) {
+		if err := SafeTearDownAt(cleaner, dir); err != nil {
+			return fmt.Errorf("failed to roll back the setup of %s that timed out: %v", dir, err)
+		}
+	}
+	if timeout == 0 {
+		return builder.SetUpAt(dir)
+	}
+
+	result := make(chan error)
+	abandoned := make(chan struct{})
+	go func() {
+		err := builder.SetUpAt(dir)
+		select {
+		case result <- err:
+		case <-abandoned:
+			rollBackSetUp(cleaner, dir, err)
+		}
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		poison(dir)
+		close(abandoned)
+		return &ErrSetUpTimeout{Dir: dir, Timeout: timeout}
+	}
+}
+
+// poison marks dir as poisoned and its setup as abandoned.
+func poison(dir string) {
+	setSetUpAbandoned(dir, true)
+	marker := poisonMarker(dir)
+	err := os.MkdirAll(path.Dir(marker), 0750)
+	if err == nil {
+		err = ioutil.WriteFile(marker, []byte(time.Now().Format(time.RFC3339)+"\n"), 0640)
+	}
+	if err != nil {
+		glog.Errorf("Failed to mark %s as poisoned: %v", dir, err)
+	}
+}
+
+// rollBackSetUp tears down dir after its abandoned SetUpAt returned setUpErr.
+// Even a failed setup may have left a mount or files behind.
+func rollBackSetUp(cleaner Cleaner, dir string, setUpErr error) {
+	defer setSetUpAbandoned(dir, false)
+	glog.Infof("Setup of %s that timed out returned %v, rolling it back", dir, setUpErr)
+	if err := cleaner.TearDownAt(dir); err != nil {
+		glog.Errorf("Failed to roll back the setup of %s that timed out: %v", dir, err)
+		return
+	}
+	if err := os.Remove(poisonMarker(dir)); err != nil && !os.IsNotExist(err) {
+		glog.Errorf("Failed to clear the poison of %s: %v", dir, err)
+	}
+}
+
+// SafeTearDownAt runs cleaner.TearDownAt(dir) and clears the poison of dir,
+// see SetUpWithTimeout.  It fails with a transient error while a setup of dir
+// that timed out is still running, as the mount may complete after the
+// teardown otherwise.
+func SafeTearDownAt(cleaner Cleaner, dir string) error {
+	dir = path.Clean(dir)
+	if isSetUpAbandoned(dir) {
+		return NewTransientError(fmt.Sprintf("a setup of %s that timed out is still running", dir))
+	}
+	if err := cleaner.TearDownAt(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(poisonMarker(dir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
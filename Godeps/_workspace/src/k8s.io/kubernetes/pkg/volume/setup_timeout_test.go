@@ -0,0 +1,147 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hangingBuilder's SetUpAt blocks until release is closed.
+type hangingBuilder struct {
+	FakeVolume
+	release chan struct{}
+}
+
+func (b *hangingBuilder) SetUpAt(dir string) error {
+	<-b.release
+	return os.MkdirAll(dir, 0750)
+}
+
+// recordingCleaner logs the directories it tore down.
+type recordingCleaner struct {
+	FakeVolume
+	lock     sync.Mutex
+	torndown []string
+	err      error
+}
+
+func (c *recordingCleaner) TearDownAt(dir string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.torndown = append(c.torndown, dir)
+	return c.err
+}
+
+func (c *recordingCleaner) teardowns() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.torndown)
+}
+
+func TestSetUpWithTimeout(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "setup_timeout_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dir := path.Join(tmpDir, "plugin", "vol1")
+
+	// Setups returning in time are not affected.
+	done := make(chan struct{})
+	close(done)
+	cleaner := &recordingCleaner{}
+	if err := SetUpWithTimeout(&hangingBuilder{release: done}, cleaner, dir, time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if IsPoisoned(dir) || cleaner.teardowns() != 0 {
+		t.Errorf("Expected a setup in time to be kept")
+	}
+
+	hung := &hangingBuilder{release: make(chan struct{})}
+	err = SetUpWithTimeout(hung, cleaner, dir, 10*time.Millisecond)
+	if _, ok := err.(*ErrSetUpTimeout); !ok {
+		t.Fatalf("Expected an ErrSetUpTimeout, got %v", err)
+	}
+	if !IsPoisoned(dir) {
+		t.Errorf("Expected %s to be poisoned", dir)
+	}
+	if err := SafeTearDownAt(cleaner, dir); !IsTransient(err) {
+		t.Errorf("Expected a transient error tearing down while the setup hangs, got %v", err)
+	}
+	if err := SetUpWithTimeout(hung, cleaner, dir, time.Minute); !IsTransient(err) {
+		t.Errorf("Expected a transient error setting up while the setup hangs, got %v", err)
+	}
+	if cleaner.teardowns() != 0 {
+		t.Errorf("Expected no teardown while the setup hangs")
+	}
+
+	// The setup is rolled back once it returns.
+	close(hung.release)
+	for i := 0; i < 100 && (IsPoisoned(dir) || isSetUpAbandoned(dir)); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if IsPoisoned(dir) || isSetUpAbandoned(dir) {
+		t.Fatalf("Expected the poison to be cleared after the rollback")
+	}
+	if cleaner.teardowns() != 1 || cleaner.torndown[0] != dir {
+		t.Errorf("Expected %s to be rolled back, got %v", dir, cleaner.torndown)
+	}
+	volumes, err := ioutil.ReadDir(path.Dir(dir))
+	if err != nil || len(volumes) != 1 {
+		t.Errorf("Expected only the volume directory, got %v, %v", volumes, err)
+	}
+}
+
+func TestSetUpWithTimeoutFailedRollback(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "setup_timeout_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dir := path.Join(tmpDir, "vol1")
+
+	hung := &hangingBuilder{release: make(chan struct{})}
+	cleaner := &recordingCleaner{err: errors.New("device busy")}
+	if err := SetUpWithTimeout(hung, cleaner, dir, 10*time.Millisecond); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	close(hung.release)
+	for i := 0; i < 100 && isSetUpAbandoned(dir); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !IsPoisoned(dir) || cleaner.teardowns() != 1 {
+		t.Fatalf("Expected %s to stay poisoned after the rollback failed", dir)
+	}
+
+	// The next setup tears the directory down first.
+	if err := SetUpWithTimeout(hung, cleaner, dir, time.Minute); err == nil {
+		t.Errorf("Expected an error while the teardown fails")
+	}
+	cleaner.err = nil
+	if err := SetUpWithTimeout(hung, cleaner, dir, time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if IsPoisoned(dir) || cleaner.teardowns() != 3 {
+		t.Errorf("Expected %s to be torn down and set up again, got %v", dir, cleaner.torndown)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// SnapshotID identifies a snapshot within its backend.
+type SnapshotID string
+
+// SnapshotMounter is implemented by volumes whose snapshots can be mounted
+// read-only for inspection, without restoring them and without touching
+// the live volume.
+type SnapshotMounter interface {
+	// MountSnapshot exposes the filesystem of the snapshot read-only at
+	// target.  The returned Cleaner unmounts it.
+	MountSnapshot(snapshotID SnapshotID, target string) (Cleaner, error)
+}
+
+// MountSnapshotReadOnly mounts the snapshot at source on target and then
+// remounts it with MS_RDONLY, so that writes are refused by the kernel even
+// when source is a directory, for which a plain bind mount ignores "ro".
+// If fsType is empty source is bind mounted, otherwise it is a device
+// holding a filesystem of that type.  It is a building block for
+// implementations of SnapshotMounter.
+func MountSnapshotReadOnly(mounter mount.Interface, source, fsType, target string) (Cleaner, error) {
+	if err := os.MkdirAll(target, 0750); err != nil {
+		return nil, err
+	}
+	options := []string{"ro"}
+	remountOptions := []string{"remount", "ro"}
+	if fsType == "" {
+		options = []string{"bind", "ro"}
+		remountOptions = []string{"bind", "remount", "ro"}
+	} else if fsType == "ext3" || fsType == "ext4" {
+		// Don't replay the journal, which would write to the snapshot.
+		options = append(options, "noload")
+	}
+
+	if err := mounter.Mount(source, target, fsType, options); err != nil {
+		os.Remove(target)
+		return nil, fmt.Errorf("failed to mount snapshot %s on %s: %v", source, target, err)
+	}
+	if err := mounter.Mount(source, target, fsType, remountOptions); err != nil {
+		if unmountErr := mounter.Unmount(target); unmountErr != nil {
+			glog.Errorf("Failed to unmount snapshot %s from %s: %v", source, target, unmountErr)
+		} else {
+			os.Remove(target)
+		}
+		return nil, fmt.Errorf("failed to remount snapshot %s on %s read-only: %v", source, target, err)
+	}
+	return &snapshotCleaner{mounter: mounter, path: target}, nil
+}
+
+// snapshotCleaner unmounts a snapshot mounted by MountSnapshotReadOnly.
+type snapshotCleaner struct {
+	mounter mount.Interface
+	path    string
+}
+
+var _ Cleaner = &snapshotCleaner{}
+
+func (c *snapshotCleaner) GetPath() string {
+	return c.path
+}
+
+func (c *snapshotCleaner) TearDown() error {
+	return c.TearDownAt(c.path)
+}
+
+func (c *snapshotCleaner) TearDownAt(dir string) error {
+	notMnt, err := c.mounter.IsLikelyNotMountPoint(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && !notMnt {
+		if err := c.mounter.Unmount(dir); err != nil {
+			return fmt.Errorf("failed to unmount snapshot from %s: %v", dir, err)
+		}
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// optionsMounter records the options of every mount, which FakeMounter
+// does not.
+type optionsMounter struct {
+	mount.FakeMounter
+	options [][]string
+}
+
+func (m *optionsMounter) Mount(source, target, fstype string, options []string) error {
+	m.options = append(m.options, options)
+	return m.FakeMounter.Mount(source, target, fstype, options)
+}
+
+// fakeSnapshotVolume keeps its snapshots as directories next to the live
+// data.
+type fakeSnapshotVolume struct {
+	dir     string
+	mounter mount.Interface
+}
+
+func (v *fakeSnapshotVolume) MountSnapshot(snapshotID SnapshotID, target string) (Cleaner, error) {
+	return MountSnapshotReadOnly(v.mounter, path.Join(v.dir, "snapshots", string(snapshotID)), "", target)
+}
+
+func TestMountSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot_mount_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mounter := &optionsMounter{}
+	var snapshotter SnapshotMounter = &fakeSnapshotVolume{dir: dir, mounter: mounter}
+	target := path.Join(dir, "inspect")
+	cleaner, err := snapshotter.MountSnapshot("snap-1", target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	source := path.Join(dir, "snapshots", "snap-1")
+	if len(mounter.Log) != 2 || mounter.Log[0].Source != source || mounter.Log[1].Target != target {
+		t.Fatalf("Expected a bind mount of %s followed by a remount, got %+v", source, mounter.Log)
+	}
+	expected := [][]string{{"bind", "ro"}, {"bind", "remount", "ro"}}
+	if !reflect.DeepEqual(mounter.options, expected) {
+		t.Errorf("Expected mount options %v, got %v", expected, mounter.options)
+	}
+	for _, mp := range mounter.MountPoints {
+		if mp.Path != target {
+			t.Errorf("Unexpected mount of %s, the live volume must not be touched", mp.Path)
+		}
+	}
+
+	if cleaner.GetPath() != target {
+		t.Errorf("Expected cleaner for %s, got %s", target, cleaner.GetPath())
+	}
+	if err := cleaner.TearDown(); err != nil {
+		t.Fatalf("Unexpected error tearing down: %v", err)
+	}
+	if len(mounter.MountPoints) != 0 {
+		t.Errorf("Expected the snapshot to be unmounted, got %+v", mounter.MountPoints)
+	}
+	if last := mounter.Log[len(mounter.Log)-1]; last.Action != mount.FakeActionUnmount || last.Target != target {
+		t.Errorf("Expected an unmount of %s, got %+v", target, last)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", target, err)
+	}
+}
+
+func TestMountSnapshotDevice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot_mount_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mounter := &optionsMounter{}
+	if _, err := MountSnapshotReadOnly(mounter, "/dev/snap0", "ext4", path.Join(dir, "inspect")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"ro", "noload"}, {"remount", "ro"}}
+	if !reflect.DeepEqual(mounter.options, expected) {
+		t.Errorf("Expected mount options %v, got %v", expected, mounter.options)
+	}
+}
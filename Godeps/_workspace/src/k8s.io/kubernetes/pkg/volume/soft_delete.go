@@ -0,0 +1,136 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// SoftDeleter is implemented by Deleters whose backend can keep a deleted
+// volume recoverable for a while before destroying it.
+type SoftDeleter interface {
+	// SoftDelete marks the volume for deletion once graceWindow has passed,
+	// without destroying any data.
+	SoftDelete(graceWindow time.Duration) error
+	// Restore unmarks a soft deleted volume whose grace window has not yet
+	// passed.
+	Restore() error
+	// PurgeExpired destroys every volume of the backend whose grace window
+	// has passed and returns their IDs.
+	PurgeExpired() ([]string, error)
+}
+
+// SoftDeleteBackend is the storage side of soft deletion.  Backends usually
+// record the deadline by renaming or tagging the volume.
+type SoftDeleteBackend interface {
+	// MarkForDeletion records that the volume id may be destroyed after
+	// deadline.
+	MarkForDeletion(id string, deadline time.Time) error
+	// UnmarkForDeletion removes the mark set by MarkForDeletion.
+	UnmarkForDeletion(id string) error
+	// ListMarkedForDeletion returns the deadline of every marked volume.
+	ListMarkedForDeletion() (map[string]time.Time, error)
+	// Destroy physically deletes the volume id.
+	Destroy(id string) error
+}
+
+// ErrNotSoftDeleted is returned by Restore for a volume that is not marked
+// for deletion.
+type ErrNotSoftDeleted struct {
+	ID string
+}
+
+func (e *ErrNotSoftDeleted) Error() string {
+	return fmt.Sprintf("volume %q is not soft deleted", e.ID)
+}
+
+// ErrGraceWindowExpired is returned by Restore for a volume whose grace
+// window has passed, even if it has not been purged yet.
+type ErrGraceWindowExpired struct {
+	ID       string
+	Deadline time.Time
+}
+
+func (e *ErrGraceWindowExpired) Error() string {
+	return fmt.Sprintf("grace window of volume %q expired at %s", e.ID, e.Deadline)
+}
+
+// NewSoftDeleter returns a SoftDeleter for the volume id stored in backend.
+// Grace windows are measured with clock.
+func NewSoftDeleter(backend SoftDeleteBackend, id string, clock util.Clock) SoftDeleter {
+	return &softDeleter{backend: backend, id: id, clock: clock}
+}
+
+type softDeleter struct {
+	backend SoftDeleteBackend
+	id      string
+	clock   util.Clock
+}
+
+func (d *softDeleter) SoftDelete(graceWindow time.Duration) error {
+	if graceWindow < 0 {
+		return fmt.Errorf("invalid grace window %s for volume %q", graceWindow, d.id)
+	}
+	deadline := d.clock.Now().Add(graceWindow)
+	glog.V(2).Infof("Soft deleting volume %q, it will be purged after %s", d.id, deadline)
+	return d.backend.MarkForDeletion(d.id, deadline)
+}
+
+func (d *softDeleter) Restore() error {
+	marked, err := d.backend.ListMarkedForDeletion()
+	if err != nil {
+		return err
+	}
+	deadline, found := marked[d.id]
+	if !found {
+		return &ErrNotSoftDeleted{ID: d.id}
+	}
+	if !d.clock.Now().Before(deadline) {
+		return &ErrGraceWindowExpired{ID: d.id, Deadline: deadline}
+	}
+	return d.backend.UnmarkForDeletion(d.id)
+}
+
+func (d *softDeleter) PurgeExpired() ([]string, error) {
+	marked, err := d.backend.ListMarkedForDeletion()
+	if err != nil {
+		return nil, err
+	}
+	now := d.clock.Now()
+	var expired []string
+	for id, deadline := range marked {
+		if !now.Before(deadline) {
+			expired = append(expired, id)
+		}
+	}
+	sort.Strings(expired)
+
+	purged := []string{}
+	for _, id := range expired {
+		if err := d.backend.Destroy(id); err != nil {
+			return purged, fmt.Errorf("failed to purge volume %q: %v", id, err)
+		}
+		glog.V(2).Infof("Purged soft deleted volume %q", id)
+		purged = append(purged, id)
+	}
+	return purged, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+)
+
+type fakeSoftDeleteBackend struct {
+	volumes map[string]bool
+	marked  map[string]time.Time
+}
+
+func newFakeSoftDeleteBackend(ids ...string) *fakeSoftDeleteBackend {
+	b := &fakeSoftDeleteBackend{volumes: map[string]bool{}, marked: map[string]time.Time{}}
+	for _, id := range ids {
+		b.volumes[id] = true
+	}
+	return b
+}
+
+func (b *fakeSoftDeleteBackend) MarkForDeletion(id string, deadline time.Time) error {
+	b.marked[id] = deadline
+	return nil
+}
+
+func (b *fakeSoftDeleteBackend) UnmarkForDeletion(id string) error {
+	delete(b.marked, id)
+	return nil
+}
+
+func (b *fakeSoftDeleteBackend) ListMarkedForDeletion() (map[string]time.Time, error) {
+	marked := map[string]time.Time{}
+	for id, deadline := range b.marked {
+		marked[id] = deadline
+	}
+	return marked, nil
+}
+
+func (b *fakeSoftDeleteBackend) Destroy(id string) error {
+	delete(b.volumes, id)
+	delete(b.marked, id)
+	return nil
+}
+
+func TestSoftDeleteRestore(t *testing.T) {
+	clock := &util.FakeClock{Time: time.Now()}
+	backend := newFakeSoftDeleteBackend("vol1")
+	deleter := NewSoftDeleter(backend, "vol1", clock)
+
+	if err := deleter.Restore(); err == nil {
+		t.Errorf("Expected restoring a live volume to fail")
+	} else if _, ok := err.(*ErrNotSoftDeleted); !ok {
+		t.Errorf("Expected ErrNotSoftDeleted, got %v", err)
+	}
+
+	if err := deleter.SoftDelete(time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock.Step(59 * time.Minute)
+	if purged, err := deleter.PurgeExpired(); err != nil || len(purged) != 0 {
+		t.Errorf("Expected nothing to be purged within the grace window, got %v, %v", purged, err)
+	}
+	if err := deleter.Restore(); err != nil {
+		t.Fatalf("Unexpected error restoring: %v", err)
+	}
+	if _, marked := backend.marked["vol1"]; marked || !backend.volumes["vol1"] {
+		t.Errorf("Expected vol1 to be restored, got %+v", backend)
+	}
+
+	// A restored volume survives past the original deadline.
+	clock.Step(time.Hour)
+	if purged, err := deleter.PurgeExpired(); err != nil || len(purged) != 0 {
+		t.Errorf("Expected restored volume not to be purged, got %v, %v", purged, err)
+	}
+}
+
+func TestSoftDeletePurgeExpired(t *testing.T) {
+	clock := &util.FakeClock{Time: time.Now()}
+	backend := newFakeSoftDeleteBackend("vol1", "vol2", "vol3")
+	if err := NewSoftDeleter(backend, "vol2", clock).SoftDelete(time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := NewSoftDeleter(backend, "vol1", clock).SoftDelete(2 * time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := NewSoftDeleter(backend, "vol3", clock).SoftDelete(time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clock.Step(time.Hour)
+	deleter := NewSoftDeleter(backend, "vol2", clock)
+	if err := deleter.Restore(); err == nil {
+		t.Errorf("Expected restoring after the grace window to fail")
+	} else if _, ok := err.(*ErrGraceWindowExpired); !ok {
+		t.Errorf("Expected ErrGraceWindowExpired, got %v", err)
+	}
+
+	purged, err := deleter.PurgeExpired()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"vol2", "vol3"}; !reflect.DeepEqual(purged, expected) {
+		t.Errorf("Expected %v to be purged, got %v", expected, purged)
+	}
+	if !reflect.DeepEqual(backend.volumes, map[string]bool{"vol1": true}) {
+		t.Errorf("Expected only vol1 to remain, got %v", backend.volumes)
+	}
+}
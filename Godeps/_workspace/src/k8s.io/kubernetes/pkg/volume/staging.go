@@ -0,0 +1,103 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// StagingCleanupPolicy controls what happens to the directories a Builder
+// creates while setting up a volume (mount points, global device mounts,
+// temporary data directories) when a setup step using them has finished.
+type StagingCleanupPolicy string
+
+const (
+	// StagingCleanupAlways removes staging directories whether or not the
+	// step using them failed.  This is the default.
+	StagingCleanupAlways StagingCleanupPolicy = "AlwaysClean"
+	// StagingCleanupKeepOnError keeps the staging directories of a failed
+	// step so that they can be inspected, and removes them otherwise.
+	StagingCleanupKeepOnError StagingCleanupPolicy = "KeepOnError"
+	// StagingCleanupNever never removes staging directories.
+	StagingCleanupNever StagingCleanupPolicy = "Never"
+)
+
+var (
+	stagingCleanupPolicyLock sync.RWMutex
+	stagingCleanupPolicy     = StagingCleanupAlways
+)
+
+// SetStagingCleanupPolicy sets the policy used by all builders.
+func SetStagingCleanupPolicy(policy StagingCleanupPolicy) error {
+	switch policy {
+	case StagingCleanupAlways, StagingCleanupKeepOnError, StagingCleanupNever:
+	default:
+		return fmt.Errorf("unknown staging cleanup policy %q", policy)
+	}
+	stagingCleanupPolicyLock.Lock()
+	defer stagingCleanupPolicyLock.Unlock()
+	stagingCleanupPolicy = policy
+	return nil
+}
+
+// GetStagingCleanupPolicy returns the policy used by all builders.
+func GetStagingCleanupPolicy() StagingCleanupPolicy {
+	stagingCleanupPolicyLock.RLock()
+	defer stagingCleanupPolicyLock.RUnlock()
+	return stagingCleanupPolicy
+}
+
+// shouldCleanupStaging returns whether the staging directory of a step that
+// returned stepErr should be removed under the current policy.
+func shouldCleanupStaging(dir string, stepErr error) bool {
+	switch GetStagingCleanupPolicy() {
+	case StagingCleanupNever:
+		glog.V(4).Infof("Keeping staging directory %s: cleanup policy is %s", dir, StagingCleanupNever)
+		return false
+	case StagingCleanupKeepOnError:
+		if stepErr != nil {
+			glog.Infof("Keeping staging directory %s for inspection after failure: %v", dir, stepErr)
+			return false
+		}
+	}
+	return true
+}
+
+// CleanupStagingDir removes the empty staging directory dir, subject to the
+// staging cleanup policy.  stepErr is the error returned by the setup step
+// which used dir, or nil if it succeeded.  Use this for mount points, which
+// must never be removed recursively.
+func CleanupStagingDir(dir string, stepErr error) error {
+	if !shouldCleanupStaging(dir, stepErr) {
+		return nil
+	}
+	return os.Remove(dir)
+}
+
+// CleanupStagingTree is like CleanupStagingDir, but removes dir and
+// everything below it.  Use this only for directories which hold data
+// written by the plugin itself.
+func CleanupStagingTree(dir string, stepErr error) error {
+	if !shouldCleanupStaging(dir, stepErr) {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// failingSetUp mimics a multi-step builder: it creates a mount point and a
+// data directory, fails the mount and cleans up after itself.
+func failingSetUp(root string) error {
+	mountPoint := path.Join(root, "mnt")
+	dataDir := path.Join(root, "data")
+	if err := os.MkdirAll(mountPoint, 0750); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0750); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(dataDir, "file"), []byte("data"), 0640); err != nil {
+		return err
+	}
+	err := fmt.Errorf("mount failed")
+	CleanupStagingDir(mountPoint, err)
+	CleanupStagingTree(dataDir, err)
+	return err
+}
+
+func TestStagingCleanupPolicy(t *testing.T) {
+	defer SetStagingCleanupPolicy(GetStagingCleanupPolicy())
+
+	tests := []struct {
+		policy StagingCleanupPolicy
+		kept   bool
+	}{
+		{StagingCleanupAlways, false},
+		{StagingCleanupKeepOnError, true},
+		{StagingCleanupNever, true},
+	}
+	for _, test := range tests {
+		root, err := ioutil.TempDir("", "staging_test")
+		if err != nil {
+			t.Fatalf("can't make a temp dir: %v", err)
+		}
+		defer os.RemoveAll(root)
+
+		if err := SetStagingCleanupPolicy(test.policy); err != nil {
+			t.Fatalf("Unexpected error setting policy %s: %v", test.policy, err)
+		}
+		if err := failingSetUp(root); err == nil {
+			t.Fatalf("Expected simulated SetUp to fail")
+		}
+		for _, dir := range []string{"mnt", "data"} {
+			_, err := os.Stat(path.Join(root, dir))
+			if kept := err == nil; kept != test.kept {
+				t.Errorf("%s: expected %s kept=%t, got %t (%v)", test.policy, dir, test.kept, kept, err)
+			}
+		}
+	}
+}
+
+func TestStagingCleanupKeepOnErrorRemovesOnSuccess(t *testing.T) {
+	defer SetStagingCleanupPolicy(GetStagingCleanupPolicy())
+
+	dir, err := ioutil.TempDir("", "staging_test")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetStagingCleanupPolicy(StagingCleanupKeepOnError)
+	if err := CleanupStagingDir(dir, nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after a successful step, got %v", dir, err)
+	}
+}
+
+func TestSetStagingCleanupPolicyInvalid(t *testing.T) {
+	if err := SetStagingCleanupPolicy("Sometimes"); err == nil {
+		t.Errorf("Expected error for an unknown policy")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SubPather is implemented by Builders that expose a directory inside the
+// volume in a way of their own, e.g. because it lives on a different
+// filesystem than GetPath().  Other Builders get the generic resolution of
+// GetPathForSubPath.
+type SubPather interface {
+	// GetPathForSubPath returns the path of subPath inside the set up
+	// volume.  See GetPathForSubPath for the meaning of the arguments.
+	GetPathForSubPath(subPath string, create bool, fsGroup *int64) (string, error)
+}
+
+// ErrSubPathEscapes is returned when a subpath leaves the root of its
+// volume, either lexically or through a symlink inside the volume.
+type ErrSubPathEscapes struct {
+	Root    string
+	SubPath string
+}
+
+func (e *ErrSubPathEscapes) Error() string {
+	return fmt.Sprintf("subpath %q escapes the volume at %s", e.SubPath, e.Root)
+}
+
+// GetPathForSubPath returns the path of the directory subPath inside the
+// volume of builder, which must be set up.  Every component of subPath is
+// opened without following symlinks, so that a pod can't point its subpath
+// at a file outside the volume, e.g. on the host, by planting a symlink in
+// the volume.  If create is set, missing directories are created and, if
+// fsGroup is not nil, given the group fsGroup with the same mode
+// SetVolumeOwnership applies.  An empty subPath is the volume itself.
+func GetPathForSubPath(builder Builder, subPath string, create bool, fsGroup *int64) (string, error) {
+	if subPather, ok := UnwrapVolume(builder).(SubPather); ok {
+		return subPather.GetPathForSubPath(subPath, create, fsGroup)
+	}
+	root := builder.GetPath()
+	components, err := splitSubPath(root, subPath)
+	if err != nil {
+		return "", err
+	}
+	if err := walkSubPath(root, components, create, fsGroup); err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{root}, components...)...), nil
+}
+
+// splitSubPath returns the components of subPath, which must be relative
+// and may not contain "..".
+func splitSubPath(root, subPath string) ([]string, error) {
+	if path.IsAbs(subPath) {
+		return nil, &ErrSubPathEscapes{Root: root, SubPath: subPath}
+	}
+	components := []string{}
+	for _, c := range strings.Split(subPath, "/") {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			return nil, &ErrSubPathEscapes{Root: root, SubPath: subPath}
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
@@ -0,0 +1,72 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// walkSubPath opens every component of the subpath relative to the one
+// before it with O_NOFOLLOW, so that neither a symlink nor a concurrent
+// rename of a parent can lead the walk out of root.
+func walkSubPath(root string, components []string, create bool, fsGroup *int64) error {
+	fd, err := syscall.Open(root, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open volume %s: %v", root, err)
+	}
+	defer func() { syscall.Close(fd) }()
+
+	for i, c := range components {
+		p := filepath.Join(append([]string{root}, components[:i+1]...)...)
+		childFD, err := openSubPathDir(fd, c)
+		if err == syscall.ENOENT && create {
+			if err := syscall.Mkdirat(fd, c, 0750); err != nil && err != syscall.EEXIST {
+				return fmt.Errorf("failed to create %s: %v", p, err)
+			}
+			childFD, err = openSubPathDir(fd, c)
+			if err == nil && fsGroup != nil {
+				err = chownSubPathDir(childFD, *fsGroup)
+			}
+		}
+		switch {
+		case err == syscall.ELOOP || err == syscall.ENOTDIR:
+			// The component is a symlink or a file.
+			return &ErrSubPathEscapes{Root: root, SubPath: strings.Join(components, "/")}
+		case err != nil:
+			return fmt.Errorf("failed to open %s: %v", p, err)
+		}
+		syscall.Close(fd)
+		fd = childFD
+	}
+	return nil
+}
+
+func openSubPathDir(dirFD int, name string) (int, error) {
+	return syscall.Openat(dirFD, name, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+}
+
+func chownSubPathDir(fd int, fsGroup int64) error {
+	if err := syscall.Fchown(fd, -1, int(fsGroup)); err != nil {
+		return err
+	}
+	return syscall.Fchmod(fd, uint32(0750|managedOwnershipBitmask)|syscall.S_ISGID)
+}
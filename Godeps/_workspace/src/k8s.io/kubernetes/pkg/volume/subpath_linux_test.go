@@ -0,0 +1,102 @@
+// +build linux
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetPathForSubPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subpath_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "volume")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{filepath.Join(root, "data"), outside} {
+		if err := os.MkdirAll(d, 0750); err != nil {
+			t.Fatalf("Can't make %s: %v", d, err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("Can't make a symlink: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "file"), []byte("data"), 0644); err != nil {
+		t.Fatalf("Can't write a file: %v", err)
+	}
+	builder := &fakeOwnershipBuilder{path: root}
+
+	tests := []struct {
+		subPath  string
+		create   bool
+		expected string
+		escapes  bool
+	}{
+		{"", false, root, false},
+		{"data", false, filepath.Join(root, "data"), false},
+		{"./data/", false, filepath.Join(root, "data"), false},
+		{"data/new/dir", true, filepath.Join(root, "data/new/dir"), false},
+		{"escape", false, "", true},
+		{"escape/new", true, "", true},
+		{"file", false, "", true},
+		{"../outside", false, "", true},
+		{"data/../../outside", false, "", true},
+		{outside, false, "", true},
+	}
+	for _, test := range tests {
+		p, err := GetPathForSubPath(builder, test.subPath, test.create, nil)
+		if test.escapes {
+			if _, ok := err.(*ErrSubPathEscapes); !ok {
+				t.Errorf("Expected subpath %q to escape, got %q, %v", test.subPath, p, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for subpath %q: %v", test.subPath, err)
+		} else if p != test.expected {
+			t.Errorf("Expected subpath %q at %s, got %s", test.subPath, test.expected, p)
+		}
+		if info, err := os.Lstat(p); err != nil || !info.IsDir() {
+			t.Errorf("Expected a directory at %s: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be created outside the volume: %v", err)
+	}
+	if _, err := GetPathForSubPath(builder, "missing", false, nil); err == nil {
+		t.Errorf("Expected an error for a missing subpath")
+	}
+}
+
+func TestGetPathForSubPathFSGroup(t *testing.T) {
+	dir := makeOwnershipTree(t)
+	defer os.RemoveAll(dir)
+	builder := &fakeOwnershipBuilder{path: dir}
+
+	fsGroup := int64(1234)
+	p, err := GetPathForSubPath(builder, "sub/new", true, &fsGroup)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectOwnership(t, p, 1234, 0770|os.ModeSetgid)
+}
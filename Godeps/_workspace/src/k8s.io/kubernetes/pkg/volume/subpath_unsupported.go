@@ -0,0 +1,57 @@
+// +build !linux,!windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walkSubPath checks every component of the subpath with Lstat.  Unlike on
+// linux this can't protect against a component being replaced by a symlink
+// during the walk.
+func walkSubPath(root string, components []string, create bool, fsGroup *int64) error {
+	for i := range components {
+		p := filepath.Join(append([]string{root}, components[:i+1]...)...)
+		info, err := os.Lstat(p)
+		if os.IsNotExist(err) && create {
+			if err := os.Mkdir(p, 0750); err != nil {
+				return fmt.Errorf("failed to create %s: %v", p, err)
+			}
+			if fsGroup != nil {
+				if err := os.Chown(p, -1, int(*fsGroup)); err != nil {
+					return err
+				}
+				if err := os.Chmod(p, 0750|managedOwnershipBitmask|os.ModeSetgid); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %v", p, err)
+		}
+		if !info.IsDir() {
+			return &ErrSubPathEscapes{Root: root, SubPath: strings.Join(components, "/")}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// +build windows
+
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// walkSubPath checks every component of the subpath with Lstat, refusing
+// symlinks, junctions and other reparse points, which could lead out of
+// root, as well as components that filepath would read as separators or
+// drives.  Like on other platforms without openat this can't protect
+// against a component being replaced during the walk.  Directories created
+// inherit the ACL of the volume, so fsGroup needs no further handling.
+func walkSubPath(root string, components []string, create bool, fsGroup *int64) error {
+	escapes := &ErrSubPathEscapes{Root: root, SubPath: strings.Join(components, "/")}
+	for i, c := range components {
+		if strings.ContainsAny(c, `\:`) {
+			return escapes
+		}
+		p := filepath.Join(append([]string{root}, components[:i+1]...)...)
+		info, err := os.Lstat(p)
+		if os.IsNotExist(err) && create {
+			if err := os.Mkdir(p, 0750); err != nil {
+				return fmt.Errorf("failed to create %s: %v", p, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %v", p, err)
+		}
+		if !info.IsDir() || isReparsePoint(info) {
+			return escapes
+		}
+	}
+	return nil
+}
+
+// isReparsePoint reports whether info is of a symlink, a junction or
+// another reparse point, whichever mode bits Lstat reported for it.
+func isReparsePoint(info os.FileInfo) bool {
+	if info.Mode()&(os.ModeSymlink|os.ModeIrregular) != 0 {
+		return true
+	}
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && data.FileAttributes&syscall.FILE_ATTRIBUTE_REPARSE_POINT != 0
+}
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/record"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	"k8s.io/kubernetes/pkg/types"
@@ -38,12 +41,14 @@ type fakeVolumeHost struct {
 	cloud      cloudprovider.Interface
 	mounter    mount.Interface
 	writer     io.Writer
+	recorder   *record.FakeRecorder
 }
 
 func NewFakeVolumeHost(rootDir string, kubeClient client.Interface, plugins []VolumePlugin) *fakeVolumeHost {
 	host := &fakeVolumeHost{rootDir: rootDir, kubeClient: kubeClient, cloud: nil}
 	host.mounter = &mount.FakeMounter{}
 	host.writer = &io.StdWriter{}
+	host.recorder = &record.FakeRecorder{}
 	host.pluginMgr.InitPlugins(plugins, host)
 	return host
 }
@@ -76,6 +81,14 @@ func (f *fakeVolumeHost) GetWriter() io.Writer {
 	return f.writer
 }
 
+func (f *fakeVolumeHost) GetHostName() string {
+	return "fakeHostName"
+}
+
+func (f *fakeVolumeHost) GetEventRecorder() record.EventRecorder {
+	return f.recorder
+}
+
 func (f *fakeVolumeHost) NewWrapperBuilder(spec *Spec, pod *api.Pod, opts VolumeOptions) (Builder, error) {
 	plug, err := f.pluginMgr.FindPluginBySpec(spec)
 	if err != nil {
@@ -106,19 +119,37 @@ func ProbeVolumePlugins(config VolumeConfig) []VolumePlugin {
 }
 
 // FakeVolumePlugin is useful for testing.  It tries to be a fully compliant
-// plugin, but all it does is make empty directories.
+// plugin, but all it does is make empty directories.  It keeps the volumes,
+// provisioners and deleters it makes so that tests can check how they were
+// used, and the errors set on it are returned by them.
 // Use as:
 //   volume.RegisterPlugin(&FakePlugin{"fake-name"})
 type FakeVolumePlugin struct {
+	sync.RWMutex
 	PluginName string
 	Host       VolumeHost
 	Config     VolumeConfig
+
+	// Errors returned by SetUp, TearDown, Provision and Delete.
+	SetUpErr     error
+	TearDownErr  error
+	ProvisionErr error
+	DeleteErr    error
+	// Cloning is returned by SupportsCloning.
+	Cloning bool
+
+	Builders     []*FakeVolume
+	Cleaners     []*FakeVolume
+	Provisioners []*FakeProvisioner
+	Deleters     []*FakeDeleter
 }
 
 var _ VolumePlugin = &FakeVolumePlugin{}
 var _ RecyclableVolumePlugin = &FakeVolumePlugin{}
 var _ DeletableVolumePlugin = &FakeVolumePlugin{}
 var _ ProvisionableVolumePlugin = &FakeVolumePlugin{}
+var _ AttachableVolumePlugin = &FakeVolumePlugin{}
+var _ ReconstructableVolumePlugin = &FakeVolumePlugin{}
 
 func (plugin *FakeVolumePlugin) Init(host VolumeHost) {
 	plugin.Host = host
@@ -133,12 +164,34 @@ func (plugin *FakeVolumePlugin) CanSupport(spec *Spec) bool {
 	return true
 }
 
+func (plugin *FakeVolumePlugin) GetVolumeName(spec *Spec) (string, error) {
+	return spec.Name(), nil
+}
+
 func (plugin *FakeVolumePlugin) NewBuilder(spec *Spec, pod *api.Pod, opts VolumeOptions) (Builder, error) {
-	return &FakeVolume{pod.UID, spec.Name(), plugin}, nil
+	plugin.Lock()
+	defer plugin.Unlock()
+	volume := plugin.newFakeVolume(pod.UID, spec.Name())
+	plugin.Builders = append(plugin.Builders, volume)
+	return volume, nil
 }
 
 func (plugin *FakeVolumePlugin) NewCleaner(volName string, podUID types.UID) (Cleaner, error) {
-	return &FakeVolume{podUID, volName, plugin}, nil
+	plugin.Lock()
+	defer plugin.Unlock()
+	volume := plugin.newFakeVolume(podUID, volName)
+	plugin.Cleaners = append(plugin.Cleaners, volume)
+	return volume, nil
+}
+
+func (plugin *FakeVolumePlugin) newFakeVolume(podUID types.UID, volName string) *FakeVolume {
+	return &FakeVolume{
+		PodUID:      podUID,
+		VolName:     volName,
+		Plugin:      plugin,
+		SetUpErr:    plugin.SetUpErr,
+		TearDownErr: plugin.TearDownErr,
+	}
 }
 
 func (plugin *FakeVolumePlugin) NewRecycler(spec *Spec) (Recycler, error) {
@@ -146,25 +199,55 @@ func (plugin *FakeVolumePlugin) NewRecycler(spec *Spec) (Recycler, error) {
 }
 
 func (plugin *FakeVolumePlugin) NewDeleter(spec *Spec) (Deleter, error) {
-	return &FakeDeleter{"/attributesTransferredFromSpec"}, nil
+	plugin.Lock()
+	defer plugin.Unlock()
+	deleter := &FakeDeleter{path: "/attributesTransferredFromSpec", DeleteErr: plugin.DeleteErr}
+	plugin.Deleters = append(plugin.Deleters, deleter)
+	return deleter, nil
 }
 
 func (plugin *FakeVolumePlugin) NewProvisioner(options VolumeOptions) (Provisioner, error) {
-	return &FakeProvisioner{options, plugin.Host}, nil
+	plugin.Lock()
+	defer plugin.Unlock()
+	provisioner := &FakeProvisioner{Options: options, Host: plugin.Host, ProvisionErr: plugin.ProvisionErr}
+	plugin.Provisioners = append(plugin.Provisioners, provisioner)
+	return provisioner, nil
+}
+
+func (plugin *FakeVolumePlugin) SupportsCloning() bool {
+	return plugin.Cloning
+}
+
+func (plugin *FakeVolumePlugin) NewAttacher() (Attacher, error) {
+	return &FakeAttacher{}, nil
+}
+
+func (plugin *FakeVolumePlugin) NewDetacher() (Detacher, error) {
+	return &FakeAttacher{}, nil
+}
+
+func (plugin *FakeVolumePlugin) ConstructVolumeSpec(volumeName, mountPath string) (*Spec, error) {
+	return NewSpecFromVolume(&api.Volume{Name: volumeName}), nil
 }
 
 func (plugin *FakeVolumePlugin) GetAccessModes() []api.PersistentVolumeAccessMode {
 	return []api.PersistentVolumeAccessMode{}
 }
 
+// FakeVolume is both the Builder and the Cleaner of FakeVolumePlugin.  It
+// counts the calls to SetUp and TearDown, and fails them with SetUpErr and
+// TearDownErr if they are set.
 type FakeVolume struct {
+	sync.RWMutex
 	PodUID  types.UID
 	VolName string
 	Plugin  *FakeVolumePlugin
-}
 
-func (_ *FakeVolume) SupportsOwnershipManagement() bool {
-	return false
+	SetUpErr    error
+	TearDownErr error
+
+	SetUpCallCount    int
+	TearDownCallCount int
 }
 
 func (fv *FakeVolume) SetUp() error {
@@ -172,19 +255,32 @@ func (fv *FakeVolume) SetUp() error {
 }
 
 func (fv *FakeVolume) SetUpAt(dir string) error {
+	fv.Lock()
+	defer fv.Unlock()
+	fv.SetUpCallCount++
+	if fv.SetUpErr != nil {
+		return fv.SetUpErr
+	}
 	return os.MkdirAll(dir, 0750)
 }
 
-func (fv *FakeVolume) IsReadOnly() bool {
-	return false
+// GetSetUpCallCount returns how many times the volume was set up.
+func (fv *FakeVolume) GetSetUpCallCount() int {
+	fv.RLock()
+	defer fv.RUnlock()
+	return fv.SetUpCallCount
 }
 
-func (fv *FakeVolume) SupportsSELinux() bool {
-	return false
+func (fv *FakeVolume) GetAttributes() Attributes {
+	return Attributes{
+		ReadOnly:        false,
+		Managed:         false,
+		SupportsSELinux: false,
+	}
 }
 
 func (fv *FakeVolume) GetPath() string {
-	return path.Join(fv.Plugin.Host.GetPodVolumeDir(fv.PodUID, util.EscapeQualifiedNameForDisk(fv.Plugin.PluginName), fv.VolName))
+	return path.Join(NewDirectoryLayout(fv.Plugin.Host).PodVolumeDir(fv.PodUID, fv.Plugin.PluginName, fv.VolName))
 }
 
 func (fv *FakeVolume) TearDown() error {
@@ -192,9 +288,22 @@ func (fv *FakeVolume) TearDown() error {
 }
 
 func (fv *FakeVolume) TearDownAt(dir string) error {
+	fv.Lock()
+	defer fv.Unlock()
+	fv.TearDownCallCount++
+	if fv.TearDownErr != nil {
+		return fv.TearDownErr
+	}
 	return os.RemoveAll(dir)
 }
 
+// GetTearDownCallCount returns how many times the volume was torn down.
+func (fv *FakeVolume) GetTearDownCallCount() int {
+	fv.RLock()
+	defer fv.RUnlock()
+	return fv.TearDownCallCount
+}
+
 type fakeRecycler struct {
 	path string
 }
@@ -217,22 +326,67 @@ func NewFakeRecycler(spec *Spec, host VolumeHost, config VolumeConfig) (Recycler
 	}, nil
 }
 
+// FakeAttacher records the volumes it attaches and detaches.
+type FakeAttacher struct {
+	Attached []string
+	Detached []string
+}
+
+var _ Attacher = &FakeAttacher{}
+var _ Detacher = &FakeAttacher{}
+
+func (fa *FakeAttacher) Attach(spec *Spec, nodeName string) (string, error) {
+	fa.Attached = append(fa.Attached, spec.Name())
+	return "/dev/fake/" + spec.Name(), nil
+}
+
+func (fa *FakeAttacher) WaitForAttach(devicePath string, timeout time.Duration) error {
+	return nil
+}
+
+func (fa *FakeAttacher) Detach(deviceName string, nodeName string) error {
+	fa.Detached = append(fa.Detached, deviceName)
+	return nil
+}
+
+// FakeDeleter counts the calls to Delete, and fails them with DeleteErr if
+// it is set.
 type FakeDeleter struct {
+	sync.RWMutex
 	path string
+
+	DeleteErr       error
+	DeleteCallCount int
 }
 
 func (fd *FakeDeleter) Delete() error {
+	fd.Lock()
+	defer fd.Unlock()
+	fd.DeleteCallCount++
 	// nil is success, else error
-	return nil
+	return fd.DeleteErr
+}
+
+// GetDeleteCallCount returns how many times Delete was called.
+func (fd *FakeDeleter) GetDeleteCallCount() int {
+	fd.RLock()
+	defer fd.RUnlock()
+	return fd.DeleteCallCount
 }
 
 func (fd *FakeDeleter) GetPath() string {
 	return fd.path
 }
 
+// FakeProvisioner counts the calls to Provision, and fails them with
+// ProvisionErr if it is set.
 type FakeProvisioner struct {
+	sync.RWMutex
 	Options VolumeOptions
 	Host    VolumeHost
+
+	ProvisionErr       error
+	ProvisionCallCount int
 }
 
 func (fc *FakeProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume, error) {
@@ -260,5 +414,15 @@ func (fc *FakeProvisioner) NewPersistentVolumeTemplate() (*api.PersistentVolume,
 }
 
 func (fc *FakeProvisioner) Provision(pv *api.PersistentVolume) error {
-	return nil
+	fc.Lock()
+	defer fc.Unlock()
+	fc.ProvisionCallCount++
+	return fc.ProvisionErr
+}
+
+// GetProvisionCallCount returns how many times Provision was called.
+func (fc *FakeProvisioner) GetProvisionCallCount() int {
+	fc.RLock()
+	defer fc.RUnlock()
+	return fc.ProvisionCallCount
 }
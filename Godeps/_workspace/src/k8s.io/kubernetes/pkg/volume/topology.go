@@ -0,0 +1,157 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+const (
+	// ZoneLabel is the label of nodes with the zone they run in.  It is the topology key provisioners
+	// of zonal volumes understand.
+	ZoneLabel = "failure-domain.alpha.kubernetes.io/zone"
+
+	// HostnameLabel is the label of nodes with their hostname.  It is the topology key of volumes that are
+	// attached to a single node.
+	HostnameLabel = "kubernetes.io/hostname"
+
+	// NodeAffinityAnnotation is the annotation on a PersistentVolume with the NodeAffinity, encoded as
+	// JSON, of the nodes that can use the volume.
+	NodeAffinityAnnotation = "volume.alpha.kubernetes.io/node-affinity"
+
+	// SelectedNodeAnnotation is the annotation on a PersistentVolumeClaim with the name of the node the
+	// pod consuming the claim is going to run on, so that its volume is provisioned where the node can
+	// attach it.
+	SelectedNodeAnnotation = "volume.alpha.kubernetes.io/selected-node"
+)
+
+// TopologySelector selects the nodes whose label of every key has one of the listed values.
+type TopologySelector map[string][]string
+
+// Matches returns true if labels satisfies the selector.
+func (s TopologySelector) Matches(labels map[string]string) bool {
+	for key, values := range s {
+		value, found := labels[key]
+		if !found || !contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeAffinity constrains the nodes a PersistentVolume can be used on to those matching any of its terms.
+// An empty NodeAffinity allows all nodes.
+type NodeAffinity []TopologySelector
+
+// Matches returns true if a node with the given labels can use the volume.
+func (a NodeAffinity) Matches(labels map[string]string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, term := range a {
+		if term.Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNodeAffinity records affinity in NodeAffinityAnnotation of pv.  Provisioners call it on the templates
+// of PersistentVolumes that can't be used from every node.
+func SetNodeAffinity(pv *api.PersistentVolume, affinity NodeAffinity) error {
+	data, err := json.Marshal(affinity)
+	if err != nil {
+		return err
+	}
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[NodeAffinityAnnotation] = string(data)
+	return nil
+}
+
+// GetNodeAffinity returns the NodeAffinity recorded in pv, which is empty if pv has none.
+func GetNodeAffinity(pv *api.PersistentVolume) (NodeAffinity, error) {
+	value, found := pv.Annotations[NodeAffinityAnnotation]
+	if !found {
+		return nil, nil
+	}
+	affinity := NodeAffinity{}
+	if err := json.Unmarshal([]byte(value), &affinity); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on PersistentVolume %s: %v", NodeAffinityAnnotation, pv.Name, err)
+	}
+	return affinity, nil
+}
+
+// ChooseZone returns the zone a provisioner of zonal volumes should create the volume described by options in:
+// options.Zone if set, else the zone of options.SelectedNode, else the first zone of options.AllowedTopologies.
+// It returns an error if the chosen zone is not allowed, and an empty zone if options leave the choice to the
+// provisioner.
+func ChooseZone(options VolumeOptions) (string, error) {
+	zone := options.Zone
+	if zone == "" && options.SelectedNode != nil {
+		zone = options.SelectedNode.Labels[ZoneLabel]
+	}
+	if zone == "" {
+		for _, term := range options.AllowedTopologies {
+			if zones := term[ZoneLabel]; len(zones) > 0 {
+				return zones[0], nil
+			}
+		}
+		return "", nil
+	}
+	if !zoneAllowed(options.AllowedTopologies, zone) {
+		return "", fmt.Errorf("zone %s is not allowed by %v", zone, options.AllowedTopologies)
+	}
+	return zone, nil
+}
+
+// zoneAllowed returns true if a term of allowed does not constrain the zone or lists zone.  Keys other than
+// ZoneLabel are left to the scheduler.
+func zoneAllowed(allowed []TopologySelector, zone string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, term := range allowed {
+		if zones, found := term[ZoneLabel]; !found || contains(zones, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// ZoneNodeAffinity returns the NodeAffinity of a volume in zone.
+func ZoneNodeAffinity(zone string) NodeAffinity {
+	return NodeAffinity{{ZoneLabel: {zone}}}
+}
+
+// HostnameNodeAffinity returns the NodeAffinity of a volume attached to the node with hostname.
+func HostnameNodeAffinity(hostname string) NodeAffinity {
+	return NodeAffinity{{HostnameLabel: {hostname}}}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestChooseZone(t *testing.T) {
+	node := &api.Node{ObjectMeta: api.ObjectMeta{Name: "node1", Labels: map[string]string{ZoneLabel: "zone-b"}}}
+	allowed := []TopologySelector{{ZoneLabel: {"zone-a", "zone-b"}}}
+	tests := []struct {
+		options  VolumeOptions
+		expected string
+		err      bool
+	}{
+		{VolumeOptions{}, "", false},
+		{VolumeOptions{Zone: "zone-c"}, "zone-c", false},
+		{VolumeOptions{SelectedNode: node}, "zone-b", false},
+		{VolumeOptions{Zone: "zone-a", SelectedNode: node}, "zone-a", false},
+		{VolumeOptions{AllowedTopologies: allowed}, "zone-a", false},
+		{VolumeOptions{AllowedTopologies: allowed, SelectedNode: node}, "zone-b", false},
+		{VolumeOptions{AllowedTopologies: allowed, Zone: "zone-c"}, "", true},
+		{VolumeOptions{AllowedTopologies: []TopologySelector{{"rack": {"r1"}}}, Zone: "zone-c"}, "zone-c", false},
+	}
+	for i, test := range tests {
+		zone, err := ChooseZone(test.options)
+		if test.err != (err != nil) {
+			t.Errorf("%d: unexpected error %v", i, err)
+		} else if zone != test.expected {
+			t.Errorf("%d: expected zone %q, got %q", i, test.expected, zone)
+		}
+	}
+}
+
+func TestNodeAffinity(t *testing.T) {
+	pv := &api.PersistentVolume{}
+	if affinity, err := GetNodeAffinity(pv); err != nil || len(affinity) != 0 {
+		t.Errorf("Expected no affinity, got %v, %v", affinity, err)
+	}
+	if err := SetNodeAffinity(pv, ZoneNodeAffinity("zone-a")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	affinity, err := GetNodeAffinity(pv)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !affinity.Matches(map[string]string{ZoneLabel: "zone-a", "rack": "r1"}) {
+		t.Errorf("Expected a node in zone-a to match %v", affinity)
+	}
+	if affinity.Matches(map[string]string{ZoneLabel: "zone-b"}) || affinity.Matches(nil) {
+		t.Errorf("Expected only nodes in zone-a to match %v", affinity)
+	}
+
+	pv.Annotations[NodeAffinityAnnotation] = "zone-a"
+	if _, err := GetNodeAffinity(pv); err == nil {
+		t.Errorf("Expected an error for an invalid annotation")
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+const (
+	// trimQPS limits how often a TrimScheduler issues fstrim, so that a
+	// large set of volumes does not keep the devices busy discarding.
+	trimQPS = 0.1
+	// maxTrimPollPeriod is the longest a TrimScheduler waits before
+	// checking whether any volume is due to be trimmed.
+	maxTrimPollPeriod = time.Minute
+	// trimLimiterPollPeriod is how often a TrimScheduler waiting for the
+	// rate limiter checks whether it was stopped.
+	trimLimiterPollPeriod = 100 * time.Millisecond
+)
+
+// ErrTrimNotSupported is returned when the filesystem mounted at Path does
+// not support discard.
+type ErrTrimNotSupported struct {
+	Path string
+}
+
+func (e *ErrTrimNotSupported) Error() string {
+	return fmt.Sprintf("filesystem at %s does not support discard", e.Path)
+}
+
+// Trimmer discards unused blocks of mounted filesystems, which lets SSD
+// backed volumes reclaim space and keep write performance up.
+type Trimmer struct {
+	Runner exec.Interface
+}
+
+// TrimVolume runs fstrim on the filesystem mounted at path.
+func (t *Trimmer) TrimVolume(path string) error {
+	glog.V(4).Infof("Trimming volume at %s", path)
+	output, err := t.Runner.Command("fstrim", path).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "not supported") {
+			return &ErrTrimNotSupported{Path: path}
+		}
+		return fmt.Errorf("fstrim failed for %s: %v, output: %q", path, err, string(output))
+	}
+	return nil
+}
+
+// ScheduleTrim starts trimming each of the given paths once per interval in
+// the background.  Trims are issued one at a time and rate limited.  Call
+// Stop on the returned TrimScheduler to stop trimming.
+func (t *Trimmer) ScheduleTrim(paths []string, interval time.Duration) *TrimScheduler {
+	s := newTrimScheduler(t, paths, interval, util.RealClock{}, util.NewTokenBucketRateLimiter(trimQPS, 1))
+	go s.run()
+	return s
+}
+
+// TrimScheduler periodically trims a set of volumes.
+type TrimScheduler struct {
+	trimmer  *Trimmer
+	paths    []string
+	interval time.Duration
+	clock    util.Clock
+	limiter  util.RateLimiter
+	lastTrim map[string]time.Time
+	jobs     *backgroundJobs
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func newTrimScheduler(trimmer *Trimmer, paths []string, interval time.Duration, clock util.Clock, limiter util.RateLimiter) *TrimScheduler {
+	return &TrimScheduler{
+		trimmer:  trimmer,
+		paths:    paths,
+		interval: interval,
+		clock:    clock,
+		limiter:  limiter,
+		lastTrim: map[string]time.Time{},
+		jobs:     defaultBackgroundJobs,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Stop stops the scheduler, also interrupting a wait for the rate limiter.
+// A trim already in progress is allowed to finish.  It is safe to call Stop more than once.
+func (s *TrimScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.limiter.Stop()
+	})
+}
+
+func (s *TrimScheduler) run() {
+	period := s.interval
+	if period > maxTrimPollPeriod {
+		period = maxTrimPollPeriod
+	}
+	for {
+		s.trimDue()
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(period):
+		}
+	}
+}
+
+// trimDue trims every path which has not been trimmed within the interval.
+// Volumes whose background jobs are suspended are left until the next poll.
+func (s *TrimScheduler) trimDue() {
+	for _, path := range s.paths {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		if last, found := s.lastTrim[path]; found && s.clock.Since(last) < s.interval {
+			continue
+		}
+		if s.jobs.isSuspended(path) {
+			glog.V(4).Infof("Background jobs for %s are suspended, not trimming", path)
+			continue
+		}
+		if !s.waitForToken() {
+			return
+		}
+		// The jobs may have been suspended while waiting for the limiter.
+		if !s.jobs.start(path) {
+			glog.V(4).Infof("Background jobs for %s are suspended, not trimming", path)
+			continue
+		}
+		s.trim(path)
+		s.jobs.finish(path)
+		s.lastTrim[path] = s.clock.Now()
+	}
+}
+
+// waitForToken waits for the rate limiter to accept a trim, and returns
+// false if the scheduler was stopped first.  The limiter is polled rather
+// than waited on with Accept, as its Stop does not interrupt Accept.
+func (s *TrimScheduler) waitForToken() bool {
+	for !s.limiter.CanAccept() {
+		select {
+		case <-s.stopCh:
+			return false
+		case <-time.After(trimLimiterPollPeriod):
+		}
+	}
+	return true
+}
+
+func (s *TrimScheduler) trim(path string) {
+	if err := s.trimmer.TrimVolume(path); err != nil {
+		if _, ok := err.(*ErrTrimNotSupported); ok {
+			glog.V(4).Infof("Skipping trim: %v", err)
+		} else {
+			glog.Errorf("Failed to trim volume: %v", err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/exec"
+)
+
+// newFakeTrimRunner returns a FakeExec which answers every fstrim call with
+// the given output and error, and records the trimmed paths.
+func newFakeTrimRunner(calls int, output string, err error, trimmed *[]string) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for i := 0; i < calls; i++ {
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			*trimmed = append(*trimmed, args...)
+			fcmd := &exec.FakeCmd{
+				CombinedOutputScript: []exec.FakeCombinedOutputAction{
+					func() ([]byte, error) { return []byte(output), err },
+				},
+			}
+			return exec.InitFakeCmd(fcmd, cmd, args...)
+		})
+	}
+	return fake
+}
+
+func TestTrimVolume(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(1, "", nil, &trimmed)
+	trimmer := &Trimmer{Runner: runner}
+
+	if err := trimmer.TrimVolume("/mnt/ssd"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runner.CommandCalls != 1 || len(trimmed) != 1 || trimmed[0] != "/mnt/ssd" {
+		t.Errorf("Expected fstrim to be run on /mnt/ssd, got %v", trimmed)
+	}
+}
+
+func TestTrimVolumeNotSupported(t *testing.T) {
+	var trimmed []string
+	output := "fstrim: /mnt/hdd: the discard operation is not supported"
+	trimmer := &Trimmer{Runner: newFakeTrimRunner(1, output, &exec.FakeExitError{Status: 1}, &trimmed)}
+
+	err := trimmer.TrimVolume("/mnt/hdd")
+	if _, ok := err.(*ErrTrimNotSupported); !ok {
+		t.Errorf("Expected ErrTrimNotSupported, got %v", err)
+	}
+}
+
+func TestTrimSchedulerRespectsInterval(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(4, "", nil, &trimmed)
+	clock := &util.FakeClock{Time: time.Now()}
+	s := newTrimScheduler(&Trimmer{Runner: runner}, []string{"/a", "/b"}, time.Hour, clock, util.NewFakeRateLimiter())
+
+	s.trimDue()
+	if runner.CommandCalls != 2 {
+		t.Fatalf("Expected both volumes to be trimmed initially, got %v", trimmed)
+	}
+
+	clock.Step(30 * time.Minute)
+	s.trimDue()
+	if runner.CommandCalls != 2 {
+		t.Fatalf("Expected no trims before the interval elapsed, got %v", trimmed)
+	}
+
+	clock.Step(30 * time.Minute)
+	s.trimDue()
+	if runner.CommandCalls != 4 {
+		t.Fatalf("Expected both volumes to be trimmed again after the interval, got %v", trimmed)
+	}
+}
+
+func TestTrimSchedulerStop(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(1, "", nil, &trimmed)
+	s := newTrimScheduler(&Trimmer{Runner: runner}, []string{"/a"}, time.Hour, util.RealClock{}, util.NewFakeRateLimiter())
+
+	done := make(chan struct{})
+	go func() {
+		s.run()
+		close(done)
+	}()
+	s.Stop()
+	s.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Scheduler did not stop")
+	}
+}
+
+// countingRateLimiter accepts right away, counting the tokens taken.
+type countingRateLimiter struct {
+	accepted int
+}
+
+func (l *countingRateLimiter) CanAccept() bool { l.accepted++; return true }
+func (l *countingRateLimiter) Accept()         { l.accepted++ }
+func (l *countingRateLimiter) Stop()           {}
+
+func TestTrimSchedulerSkipsSuspendedVolumes(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(1, "", nil, &trimmed)
+	limiter := &countingRateLimiter{}
+	s := newTrimScheduler(&Trimmer{Runner: runner}, []string{"/a", "/b"}, time.Hour, util.RealClock{}, limiter)
+	s.jobs = newBackgroundJobs()
+
+	s.jobs.suspend("/a")
+	s.trimDue()
+	if len(trimmed) != 1 || trimmed[0] != "/b" {
+		t.Errorf("Expected only /b to be trimmed, got %v", trimmed)
+	}
+	if limiter.accepted != 1 {
+		t.Errorf("Expected the suspended volume not to wait for the limiter, got %d tokens taken", limiter.accepted)
+	}
+}
+
+// closedRateLimiter never accepts.
+type closedRateLimiter struct{}
+
+func (l *closedRateLimiter) CanAccept() bool { return false }
+func (l *closedRateLimiter) Accept()         { select {} }
+func (l *closedRateLimiter) Stop()           {}
+
+func TestTrimSchedulerStopWhileRateLimited(t *testing.T) {
+	var trimmed []string
+	runner := newFakeTrimRunner(1, "", nil, &trimmed)
+	s := newTrimScheduler(&Trimmer{Runner: runner}, []string{"/a"}, time.Hour, util.RealClock{}, &closedRateLimiter{})
+	s.jobs = newBackgroundJobs()
+
+	done := make(chan struct{})
+	go func() {
+		s.trimDue()
+		close(done)
+	}()
+	s.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Scheduler waiting for the rate limiter did not stop")
+	}
+	if len(trimmed) != 0 {
+		t.Errorf("Expected nothing to be trimmed, got %v", trimmed)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// UnmountLevel is how forcefully UnmountWithEscalation unmounts a path.
+type UnmountLevel int
+
+const (
+	// UnmountNormal unmounts with the mounter, like any other unmount.
+	UnmountNormal UnmountLevel = iota
+	// UnmountForce runs umount -f, which aborts the pending requests of a
+	// network filesystem whose server went away.
+	UnmountForce
+	// UnmountLazy runs umount -l, which detaches the filesystem at once and
+	// cleans it up once it is no longer busy.
+	UnmountLazy
+)
+
+func (l UnmountLevel) String() string {
+	switch l {
+	case UnmountNormal:
+		return "normal"
+	case UnmountForce:
+		return "force"
+	case UnmountLazy:
+		return "lazy"
+	}
+	return fmt.Sprintf("UnmountLevel(%d)", int(l))
+}
+
+// escalatedUnmountFlags are the flags of umount for the levels beyond
+// UnmountNormal.
+var escalatedUnmountFlags = map[UnmountLevel]string{
+	UnmountForce: "-f",
+	UnmountLazy:  "-l",
+}
+
+// ErrUnmountFailed is the error of UnmountWithEscalation when every level
+// failed.
+type ErrUnmountFailed struct {
+	Path string
+	// Level is the last level tried.
+	Level UnmountLevel
+	// Errs are the errors of the levels tried, in order.
+	Errs []error
+}
+
+func (e *ErrUnmountFailed) Error() string {
+	msgs := []string{}
+	for i, err := range e.Errs {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", UnmountLevel(i), err))
+	}
+	return fmt.Sprintf("failed to unmount %s: %s", e.Path, strings.Join(msgs, "; "))
+}
+
+// UnmountWithEscalation unmounts path with mounter, and escalates to
+// umount -f and then umount -l run with exe if an attempt fails or has not
+// returned within gracePeriod, so that the mounts of dead NFS servers do
+// not hang their teardown forever.  It returns the level which unmounted
+// path, or an *ErrUnmountFailed.  An attempt that timed out keeps running in the
+// background.  The escalated attempts run umount directly rather than
+// through mounter.
+func UnmountWithEscalation(path string, gracePeriod time.Duration, mounter mount.Interface, exe exec.Interface) (UnmountLevel, error) {
+	unmountErr := &ErrUnmountFailed{Path: path}
+	for level := UnmountNormal; level <= UnmountLazy; level++ {
+		level := level
+		unmountErr.Level = level
+		err := runWithGracePeriod(gracePeriod, func() error {
+			return unmountAtLevel(path, level, mounter, exe)
+		})
+		if err == nil {
+			if level != UnmountNormal {
+				glog.Warningf("Unmounted %s with a %s unmount", path, level)
+			}
+			return level, nil
+		}
+		glog.Warningf("Failed a %s unmount of %s: %v", level, path, err)
+		unmountErr.Errs = append(unmountErr.Errs, err)
+	}
+	return unmountErr.Level, unmountErr
+}
+
+func unmountAtLevel(path string, level UnmountLevel, mounter mount.Interface, exe exec.Interface) error {
+	if level == UnmountNormal {
+		return mounter.Unmount(path)
+	}
+	output, err := exe.Command("umount", escalatedUnmountFlags[level], path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v, output: %q", err, string(output))
+	}
+	return nil
+}
+
+// runWithGracePeriod returns the error of f, or an error if f has not
+// returned within gracePeriod.
+func runWithGracePeriod(gracePeriod time.Duration, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(gracePeriod):
+		return fmt.Errorf("timed out after %v", gracePeriod)
+	}
+}
+
+// NewEscalatingMounter returns a mounter which unmounts with
+// UnmountWithEscalation, for passing to UnmountPath.
+func NewEscalatingMounter(mounter mount.Interface, exe exec.Interface, gracePeriod time.Duration) mount.Interface {
+	return &escalatingMounter{Interface: mounter, exe: exe, gracePeriod: gracePeriod}
+}
+
+type escalatingMounter struct {
+	mount.Interface
+	exe         exec.Interface
+	gracePeriod time.Duration
+}
+
+func (m *escalatingMounter) Unmount(target string) error {
+	_, err := UnmountWithEscalation(target, m.gracePeriod, m.Interface, m.exe)
+	return err
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/exec"
+	"k8s.io/kubernetes/pkg/util/mount"
+)
+
+// stuckMounter is a mounter whose unmounts fail with err, or hang until
+// release is closed if err is nil.
+type stuckMounter struct {
+	mount.FakeMounter
+	err     error
+	release chan struct{}
+}
+
+func (m *stuckMounter) Unmount(target string) error {
+	if m.err != nil {
+		return m.err
+	}
+	<-m.release
+	return nil
+}
+
+// scriptedUmount returns a FakeExec whose commands return errs in order
+// and records their argv in argvs.
+func scriptedUmount(argvs *[][]string, errs ...error) *exec.FakeExec {
+	fake := &exec.FakeExec{}
+	for _, err := range errs {
+		err := err
+		fake.CommandScript = append(fake.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			*argvs = append(*argvs, append([]string{cmd}, args...))
+			return &exec.FakeCmd{CombinedOutputScript: []exec.FakeCombinedOutputAction{
+				func() ([]byte, error) { return nil, err },
+			}}
+		})
+	}
+	return fake
+}
+
+func TestUnmountWithEscalation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	busy := fmt.Errorf("device is busy")
+	tests := []struct {
+		name          string
+		mounter       mount.Interface
+		umountErrs    []error
+		expectedLevel UnmountLevel
+		expectedArgvs [][]string
+		expectErr     bool
+	}{
+		{
+			name:          "normal",
+			mounter:       &mount.FakeMounter{},
+			expectedLevel: UnmountNormal,
+		},
+		{
+			name:          "hung unmount",
+			mounter:       &stuckMounter{release: release},
+			umountErrs:    []error{nil},
+			expectedLevel: UnmountForce,
+			expectedArgvs: [][]string{{"umount", "-f", "/mnt/vol"}},
+		},
+		{
+			name:          "failed force",
+			mounter:       &stuckMounter{err: busy},
+			umountErrs:    []error{busy, nil},
+			expectedLevel: UnmountLazy,
+			expectedArgvs: [][]string{{"umount", "-f", "/mnt/vol"}, {"umount", "-l", "/mnt/vol"}},
+		},
+		{
+			name:          "all failed",
+			mounter:       &stuckMounter{err: busy},
+			umountErrs:    []error{busy, busy},
+			expectedLevel: UnmountLazy,
+			expectedArgvs: [][]string{{"umount", "-f", "/mnt/vol"}, {"umount", "-l", "/mnt/vol"}},
+			expectErr:     true,
+		},
+	}
+	for _, test := range tests {
+		argvs := [][]string{}
+		level, err := UnmountWithEscalation("/mnt/vol", 10*time.Millisecond, test.mounter, scriptedUmount(&argvs, test.umountErrs...))
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if level != test.expectedLevel {
+			t.Errorf("%s: expected a %s unmount, got %s", test.name, test.expectedLevel, level)
+		}
+		if len(argvs) != 0 || len(test.expectedArgvs) != 0 {
+			if !reflect.DeepEqual(argvs, test.expectedArgvs) {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expectedArgvs, argvs)
+			}
+		}
+		if test.expectErr {
+			unmountErr, ok := err.(*ErrUnmountFailed)
+			if !ok {
+				t.Errorf("%s: expected an *ErrUnmountFailed, got %v", test.name, err)
+			} else if unmountErr.Level != UnmountLazy || len(unmountErr.Errs) != 3 {
+				t.Errorf("%s: expected the errors of all levels, got %#v", test.name, unmountErr)
+			}
+		}
+	}
+}
+
+func TestUnmountPathEscalates(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "unmountEscalationTest")
+	if err != nil {
+		t.Fatalf("can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The forced unmount is what removes the mount point.
+	mounter := &stuckMounter{err: fmt.Errorf("stale file handle")}
+	mounter.MountPoints = []mount.MountPoint{{Device: "server:/export", Path: dir, Type: "nfs"}}
+	fakeExec := &exec.FakeExec{CommandScript: []exec.FakeCommandAction{
+		func(cmd string, args ...string) exec.Cmd {
+			return &exec.FakeCmd{CombinedOutputScript: []exec.FakeCombinedOutputAction{
+				func() ([]byte, error) {
+					mounter.FakeMounter.Unmount(dir)
+					return nil, nil
+				},
+			}}
+		},
+	}}
+	if err := UnmountPath(dir, NewEscalatingMounter(mounter, fakeExec, time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got: %v", dir, err)
+	}
+}
@@ -18,6 +18,9 @@ package volume
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"k8s.io/kubernetes/pkg/api"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/mount"
 	"k8s.io/kubernetes/pkg/watch"
 
 	"github.com/golang/glog"
@@ -38,11 +42,18 @@ import (
 // 	pod - the pod designed by a volume plugin to recycle the volume
 //	client - kube client for API operations.
 func RecycleVolumeByWatchingPodUntilCompletion(pod *api.Pod, kubeClient client.Interface) error {
-	return internalRecycleVolumeByWatchingPodUntilCompletion(pod, newRecyclerClient(kubeClient))
+	return internalRecycleVolumeByWatchingPodUntilCompletion(pod, newRecyclerClient(kubeClient), 0, nil)
+}
+
+// RecycleVolumeByWatchingPodWithProgress is like RecycleVolumeByWatchingPodUntilCompletion, for ProgressRecyclers:
+// it gives up with an ErrRecycleTimeout if the pod has not completed within timeout, unless it is 0, and calls
+// progress, if not nil, each time the pod enters a new phase.
+func RecycleVolumeByWatchingPodWithProgress(pod *api.Pod, kubeClient client.Interface, timeout time.Duration, progress RecycleProgressFunc) error {
+	return internalRecycleVolumeByWatchingPodUntilCompletion(pod, newRecyclerClient(kubeClient), timeout, progress)
 }
 
 // same as above func comments, except 'recyclerClient' is a narrower pod API interface to ease testing
-func internalRecycleVolumeByWatchingPodUntilCompletion(pod *api.Pod, recyclerClient recyclerClient) error {
+func internalRecycleVolumeByWatchingPodUntilCompletion(pod *api.Pod, recyclerClient recyclerClient, timeout time.Duration, progress RecycleProgressFunc) error {
 	glog.V(5).Infof("Creating recycler pod for volume %s\n", pod.Name)
 	pod, err := recyclerClient.CreatePod(pod)
 	if err != nil {
@@ -55,8 +66,38 @@ func internalRecycleVolumeByWatchingPodUntilCompletion(pod *api.Pod, recyclerCli
 	defer close(stopChannel)
 	nextPod := recyclerClient.WatchPod(pod.Name, pod.Namespace, pod.ResourceVersion, stopChannel)
 
+	// Pods are read in the background so that waiting for the next one can be
+	// given up on when the timeout expires.
+	pods := make(chan *api.Pod)
+	go func() {
+		for {
+			watchedPod := nextPod()
+			select {
+			case pods <- watchedPod:
+			case <-stopChannel:
+				return
+			}
+		}
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var lastPhase api.PodPhase
 	for {
-		watchedPod := nextPod()
+		var watchedPod *api.Pod
+		select {
+		case watchedPod = <-pods:
+		case <-expired:
+			return &ErrRecycleTimeout{Volume: pod.Name, Timeout: timeout}
+		}
+		if progress != nil && watchedPod.Status.Phase != lastPhase {
+			progress(fmt.Sprintf("recycler pod %s is %s", pod.Name, watchedPod.Status.Phase))
+		}
+		lastPhase = watchedPod.Status.Phase
 		if watchedPod.Status.Phase == api.PodSucceeded {
 			// volume.Recycle() returns nil on success, else error
 			return nil
@@ -64,7 +105,7 @@ func internalRecycleVolumeByWatchingPodUntilCompletion(pod *api.Pod, recyclerCli
 		if watchedPod.Status.Phase == api.PodFailed {
 			// volume.Recycle() returns nil on success, else error
 			if watchedPod.Status.Message != "" {
-				return fmt.Errorf(watchedPod.Status.Message)
+				return fmt.Errorf("%s", watchedPod.Status.Message)
 			} else {
 				return fmt.Errorf("Pod failed, pod.Status.Message unknown.")
 			}
@@ -117,6 +158,12 @@ func (c *realRecyclerClient) WatchPod(name, namespace, resourceVersion string, s
 	}
 	queue := cache.NewFIFO(cache.MetaNamespaceKeyFunc)
 	cache.NewReflector(podLW, &api.Pod{}, queue, 1*time.Minute).RunUntil(stopChannel)
+	// Wake up a caller still waiting for the pod with an empty one once the
+	// watch is stopped, as nothing will be added to the queue anymore.
+	go func() {
+		<-stopChannel
+		queue.Add(&api.Pod{ObjectMeta: api.ObjectMeta{Name: name, Namespace: namespace}})
+	}()
 
 	return func() *api.Pod {
 		obj := queue.Pop()
@@ -124,6 +171,27 @@ func (c *realRecyclerClient) WatchPod(name, namespace, resourceVersion string, s
 	}
 }
 
+// NewRecyclerPod returns a copy of config.RecyclerPodTemplate set up to scrub a volume: the first volume of the pod
+// gets source, and the pod gets timeout as its ActiveDeadlineSeconds and generateName as its GenerateName.  The
+// template itself is not modified, so it can be shared by concurrent recycles.
+func NewRecyclerPod(config VolumeConfig, generateName string, timeout int64, source api.VolumeSource) (*api.Pod, error) {
+	if config.RecyclerPodTemplate == nil {
+		return nil, fmt.Errorf("no recycler pod template configured")
+	}
+	if len(config.RecyclerPodTemplate.Spec.Volumes) == 0 {
+		return nil, fmt.Errorf("recycler pod template has no volume to scrub")
+	}
+	obj, err := api.Scheme.Copy(config.RecyclerPodTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy recycler pod template: %v", err)
+	}
+	pod := obj.(*api.Pod)
+	pod.GenerateName = generateName
+	pod.Spec.ActiveDeadlineSeconds = &timeout
+	pod.Spec.Volumes[0].VolumeSource = source
+	return pod, nil
+}
+
 // CalculateTimeoutForVolume calculates time for a Recycler pod to complete a recycle operation.
 // The calculation and return value is either the minimumTimeout or the timeoutIncrement per Gi of storage size, whichever is greater.
 func CalculateTimeoutForVolume(minimumTimeout, timeoutIncrement int, pv *api.PersistentVolume) int64 {
@@ -139,6 +207,13 @@ func CalculateTimeoutForVolume(minimumTimeout, timeoutIncrement int, pv *api.Per
 	}
 }
 
+const (
+	// MiB is the number of bytes in a mebibyte.
+	MiB int64 = 1024 * 1024
+	// GiB is the number of bytes in a gibibyte.
+	GiB int64 = 1024 * MiB
+)
+
 // RoundUpSize calculates how many allocation units are needed to accomodate
 // a volume of given size. E.g. when user wants 1500MiB volume, while AWS EBS
 // allocates volumes in gibibyte-sized chunks,
@@ -147,3 +222,187 @@ func CalculateTimeoutForVolume(minimumTimeout, timeoutIncrement int, pv *api.Per
 func RoundUpSize(volumeSizeBytes int64, allocationUnitBytes int64) int64 {
 	return (volumeSizeBytes + allocationUnitBytes - 1) / allocationUnitBytes
 }
+
+// RoundUpToGiB returns the number of gibibytes needed to hold size.
+func RoundUpToGiB(size resource.Quantity) int64 {
+	return RoundUpSize(size.Value(), GiB)
+}
+
+// RoundUpToMiB returns the number of mebibytes needed to hold size.
+func RoundUpToMiB(size resource.Quantity) int64 {
+	return RoundUpSize(size.Value(), MiB)
+}
+
+// RoundUpQuantity returns size rounded up to a whole number of allocation
+// units, i.e. the capacity of the smallest volume holding size a backend
+// allocating in units of allocationUnitBytes can create.
+func RoundUpQuantity(size resource.Quantity, allocationUnitBytes int64) resource.Quantity {
+	return *resource.NewQuantity(RoundUpSize(size.Value(), allocationUnitBytes)*allocationUnitBytes, resource.BinarySI)
+}
+
+// ExpandPersistentVolume grows pv to newSize using expander and, on success,
+// records the new capacity in pv.  Shrinking a volume is refused, and asking
+// for the current size is a no-op.  The caller is responsible for saving pv
+// and, if expander.RequiresFSResize(), for resizing the filesystem on the
+// node.
+func ExpandPersistentVolume(expander Expander, pv *api.PersistentVolume, newSize resource.Quantity) error {
+	current, found := pv.Spec.Capacity[api.ResourceStorage]
+	if found {
+		switch current.Cmp(newSize) {
+		case 0:
+			return nil
+		case 1:
+			return fmt.Errorf("can't shrink volume %s from %s to %s", pv.Name, current.String(), newSize.String())
+		}
+	}
+	if err := expander.ExpandVolume(pv, newSize); err != nil {
+		return err
+	}
+	if pv.Spec.Capacity == nil {
+		pv.Spec.Capacity = api.ResourceList{}
+	}
+	pv.Spec.Capacity[api.ResourceStorage] = newSize
+	return nil
+}
+
+// IsNotMountPoint returns true if dir is not a mount point.  Unlike
+// mounter.IsLikelyNotMountPoint, which only compares devices, it also
+// detects bind mounts of the same filesystem by looking dir up in the
+// mount table.  The error of a missing dir satisfies os.IsNotExist.
+func IsNotMountPoint(dir string, mounter mount.Interface) (bool, error) {
+	notMnt, err := mounter.IsLikelyNotMountPoint(dir)
+	if err != nil || !notMnt {
+		return notMnt, err
+	}
+	mountPoints, err := mounter.List()
+	if err != nil {
+		return true, err
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		resolved = filepath.Clean(dir)
+	}
+	for _, mp := range mountPoints {
+		if mp.Path == resolved || mp.Path == filepath.Clean(dir) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// UnmountPath unmounts dir if it is a mount point and then removes it.  The
+// directory is only removed with os.Remove, so a dir which still has content
+// after the unmount, e.g. because the mount check was wrong, is kept instead
+// of deleting the data of the volume.  A missing dir is not an error.
+// Cleaners of volumes mounted at their path must tear down through it.
+func UnmountPath(dir string, mounter mount.Interface) error {
+	if err := UnmountMountPoint(dir, mounter); err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// UnmountMountPoint unmounts dir if it is a mount point, like UnmountPath,
+// but keeps dir.  Builders use it to undo a failed mount before leaving dir
+// to CleanupStagingDir.  A missing dir is not an error.
+func UnmountMountPoint(dir string, mounter mount.Interface) error {
+	notMnt, err := IsNotMountPoint(dir, mounter)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking whether %s is a mount point: %v", dir, err)
+	}
+	if notMnt {
+		return nil
+	}
+	if err := mounter.Unmount(dir); err != nil {
+		return fmt.Errorf("failed to unmount %s: %v", dir, err)
+	}
+	notMnt, err = IsNotMountPoint(dir, mounter)
+	if err != nil {
+		return fmt.Errorf("error checking whether %s is a mount point: %v", dir, err)
+	}
+	if !notMnt {
+		return fmt.Errorf("%s is still mounted after unmounting it", dir)
+	}
+	return nil
+}
+
+// AccessModesContains returns whether modes contains mode.
+func AccessModesContains(modes []api.PersistentVolumeAccessMode, mode api.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessModesContainedInAll returns whether indexedModes contains all of
+// requestedModes.
+func AccessModesContainedInAll(indexedModes []api.PersistentVolumeAccessMode, requestedModes []api.PersistentVolumeAccessMode) bool {
+	for _, mode := range requestedModes {
+		if !AccessModesContains(indexedModes, mode) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckAccessMode returns an error if the volume of spec can't be accessed
+// in all of the requested modes: a PersistentVolume must list each of them,
+// and a read-only spec can't be written to.  Inline volumes have no access
+// modes of their own and are only checked for being read-only.
+func CheckAccessMode(spec *Spec, requested []api.PersistentVolumeAccessMode) error {
+	if spec.IsReadOnly() {
+		for _, mode := range requested {
+			if mode != api.ReadOnlyMany {
+				return fmt.Errorf("volume %s is read-only and can't be accessed as %s", spec.Name(), mode)
+			}
+		}
+	}
+	if spec.PersistentVolume == nil {
+		return nil
+	}
+	supported := spec.PersistentVolume.Spec.AccessModes
+	for _, mode := range requested {
+		if !AccessModesContains(supported, mode) {
+			return fmt.Errorf("persistent volume %s supports access modes %s, not %s", spec.Name(), api.GetAccessModesAsString(supported), mode)
+		}
+	}
+	return nil
+}
+
+// GetUniqueVolumeName returns the name of the volume volumeName of the
+// plugin pluginName that is unique among the volumes of all plugins, e.g.
+// "kubernetes.io/aws-ebs/vol-1234", so that attach/detach controllers and
+// the caches of the volumes of a node key them consistently.
+func GetUniqueVolumeName(pluginName, volumeName string) string {
+	return pluginName + "/" + volumeName
+}
+
+// GetUniqueVolumeNameFromSpec returns the unique name, see
+// GetUniqueVolumeName, of the volume of spec, which plugin supports.
+func GetUniqueVolumeNameFromSpec(plugin VolumePlugin, spec *Spec) (string, error) {
+	volumeName, err := plugin.GetVolumeName(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the name of volume %s of plugin %s: %v", spec.Name(), plugin.Name(), err)
+	}
+	return GetUniqueVolumeName(plugin.Name(), volumeName), nil
+}
+
+// SplitUniqueName returns the names of the plugin and of the volume a name
+// returned by GetUniqueVolumeName was made of.  Only the names of plugins
+// with namespaced names, like "kubernetes.io/aws-ebs", can be split, while
+// the name of the volume may contain slashes.
+func SplitUniqueName(uniqueName string) (string, string, error) {
+	components := strings.SplitN(uniqueName, "/", 3)
+	if len(components) != 3 || components[0] == "" || components[1] == "" || components[2] == "" {
+		return "", "", fmt.Errorf("invalid unique volume name %q, expected <plugin namespace>/<plugin name>/<volume name>", uniqueName)
+	}
+	return components[0] + "/" + components[1], components[2], nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MountInfo is a single line of /proc/self/mountinfo.  See proc(5).
+type MountInfo struct {
+	MountID  int
+	ParentID int
+	// MajorMinor is the st_dev of files on this filesystem, e.g. "8:1".
+	MajorMinor string
+	// Root is the root of the mount within the filesystem.
+	Root string
+	// MountPoint is the mount point relative to the process's root.
+	MountPoint string
+	// MountOptions are the per-mount options.
+	MountOptions []string
+	FSType       string
+	Source       string
+	// SuperOptions are the per-superblock options.
+	SuperOptions []string
+}
+
+// ReadOnly returns true if either the mount or the superblock is read-only.
+func (m *MountInfo) ReadOnly() bool {
+	for _, opts := range [][]string{m.MountOptions, m.SuperOptions} {
+		for _, opt := range opts {
+			if opt == "ro" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseMountInfo parses the contents of a mountinfo file.
+func ParseMountInfo(r io.Reader) ([]MountInfo, error) {
+	infos := []MountInfo{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		info, err := parseMountInfoLine(line)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+func parseMountInfoLine(line string) (MountInfo, error) {
+	fields := strings.Fields(line)
+	// The optional fields are terminated by a single "-".
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
+		}
+	}
+	if len(fields) < 6 || sep < 0 || len(fields) < sep+3 {
+		return MountInfo{}, fmt.Errorf("malformed mountinfo line: %q", line)
+	}
+
+	mountID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("malformed mount id in mountinfo line %q: %v", line, err)
+	}
+	parentID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("malformed parent id in mountinfo line %q: %v", line, err)
+	}
+	info := MountInfo{
+		MountID:      mountID,
+		ParentID:     parentID,
+		MajorMinor:   fields[2],
+		Root:         unescapeMountInfo(fields[3]),
+		MountPoint:   unescapeMountInfo(fields[4]),
+		MountOptions: strings.Split(fields[5], ","),
+		FSType:       fields[sep+1],
+		Source:       unescapeMountInfo(fields[sep+2]),
+	}
+	if len(fields) > sep+3 {
+		info.SuperOptions = strings.Split(fields[sep+3], ",")
+	}
+	return info, nil
+}
+
+// unescapeMountInfo replaces the octal escapes the kernel uses for spaces,
+// tabs, newlines and backslashes.
+func unescapeMountInfo(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				out = append(out, byte(v))
+				i += 3
+				continue
+			}
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
+
+// FindMountInfo returns the entry for the mount that contains file, which is
+// the entry with the longest mount point that is a prefix of file.  Later
+// entries win over earlier ones with the same mount point, since they are
+// mounted on top.
+func FindMountInfo(infos []MountInfo, file string) (*MountInfo, error) {
+	file = path.Clean(file)
+	var best *MountInfo
+	for i := range infos {
+		mp := path.Clean(infos[i].MountPoint)
+		if file != mp && !strings.HasPrefix(file, strings.TrimSuffix(mp, "/")+"/") {
+			continue
+		}
+		if best == nil || len(mp) >= len(path.Clean(best.MountPoint)) {
+			best = &infos[i]
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no mount found for %s", file)
+	}
+	return best, nil
+}
+
+// GetMountInfo reads the mountinfo file at mountInfoPath and returns the
+// entry for the mount containing file.
+func GetMountInfo(mountInfoPath, file string) (*MountInfo, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	infos, err := ParseMountInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	return FindMountInfo(infos, file)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	contents := `17 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,errors=remount-ro
+36 17 98:0 /mnt1 /mnt/with\040space ro,noatime master:1 shared:2 - ext3 /dev/root rw,errors=continue
+40 17 0:35 / /mnt/tmp rw - tmpfs tmpfs rw`
+	infos, err := ParseMountInfo(strings.NewReader(contents))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(infos))
+	}
+	expected := MountInfo{
+		MountID:      36,
+		ParentID:     17,
+		MajorMinor:   "98:0",
+		Root:         "/mnt1",
+		MountPoint:   "/mnt/with space",
+		MountOptions: []string{"ro", "noatime"},
+		FSType:       "ext3",
+		Source:       "/dev/root",
+		SuperOptions: []string{"rw", "errors=continue"},
+	}
+	if !reflect.DeepEqual(infos[1], expected) {
+		t.Errorf("expected %+v, got %+v", expected, infos[1])
+	}
+	if !infos[1].ReadOnly() || infos[2].ReadOnly() {
+		t.Errorf("unexpected read-only state: %t, %t", infos[1].ReadOnly(), infos[2].ReadOnly())
+	}
+
+	mi, err := FindMountInfo(infos, "/mnt/tmp/a/b")
+	if err != nil || mi.MountPoint != "/mnt/tmp" {
+		t.Errorf("expected /mnt/tmp, got %+v, %v", mi, err)
+	}
+	mi, err = FindMountInfo(infos, "/mnt/tmpfoo")
+	if err != nil || mi.MountPoint != "/" {
+		t.Errorf("expected /, got %+v, %v", mi, err)
+	}
+}
+
+func TestParseMountInfoMalformed(t *testing.T) {
+	if _, err := ParseMountInfo(strings.NewReader("17 1 8:1 / / rw")); err == nil {
+		t.Errorf("expected error for line without separator")
+	}
+}
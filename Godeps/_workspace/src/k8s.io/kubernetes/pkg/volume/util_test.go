@@ -18,11 +18,17 @@ package volume
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/util/mount"
 )
 
 func TestRecyclerSuccess(t *testing.T) {
@@ -37,7 +43,7 @@ func TestRecyclerSuccess(t *testing.T) {
 		},
 	}
 
-	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client)
+	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client, 0, nil)
 	if err != nil {
 		t.Errorf("Unexpected error watching recycler pod: %+v", err)
 	}
@@ -59,7 +65,7 @@ func TestRecyclerFailure(t *testing.T) {
 		},
 	}
 
-	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client)
+	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client, 0, nil)
 	if err == nil {
 		t.Fatalf("Expected pod failure but got nil error returned")
 	}
@@ -73,6 +79,60 @@ func TestRecyclerFailure(t *testing.T) {
 	}
 }
 
+func TestRecyclerProgress(t *testing.T) {
+	client := &phasedRecyclerClient{phases: []api.PodPhase{api.PodPending, api.PodPending, api.PodRunning, api.PodSucceeded}}
+	recycler := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "recycler-test", Namespace: api.NamespaceDefault}}
+
+	messages := []string{}
+	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client, time.Minute, func(message string) {
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Errorf("Unexpected error watching recycler pod: %+v", err)
+	}
+	expected := []string{
+		"recycler pod recycler-test is Pending",
+		"recycler pod recycler-test is Running",
+		"recycler pod recycler-test is Succeeded",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected progress %v, got %v", expected, messages)
+	}
+}
+
+func TestRecyclerTimeout(t *testing.T) {
+	client := &phasedRecyclerClient{phases: []api.PodPhase{api.PodRunning}}
+	recycler := &api.Pod{ObjectMeta: api.ObjectMeta{Name: "recycler-test", Namespace: api.NamespaceDefault}}
+
+	err := internalRecycleVolumeByWatchingPodUntilCompletion(recycler, client, 50*time.Millisecond, nil)
+	if _, ok := err.(*ErrRecycleTimeout); !ok {
+		t.Errorf("Expected ErrRecycleTimeout, got %v", err)
+	}
+	if !client.deletedCalled {
+		t.Errorf("Expected deferred client.Delete to be called on recycler pod")
+	}
+}
+
+// phasedRecyclerClient is a mockRecyclerClient whose pod goes through
+// phases, and then stays in the last one, which is reported once.
+type phasedRecyclerClient struct {
+	mockRecyclerClient
+	phases []api.PodPhase
+}
+
+func (c *phasedRecyclerClient) WatchPod(name, namespace, resourceVersion string, stopChannel chan struct{}) func() *api.Pod {
+	return func() *api.Pod {
+		if len(c.phases) == 0 {
+			<-stopChannel
+			return c.pod
+		}
+		pod := *c.pod
+		pod.Status.Phase = c.phases[0]
+		c.phases = c.phases[1:]
+		return &pod
+	}
+}
+
 type mockRecyclerClient struct {
 	pod           *api.Pod
 	deletedCalled bool
@@ -128,3 +188,232 @@ func TestCalculateTimeoutForVolume(t *testing.T) {
 		t.Errorf("Expected 4500 for timeout but got %v", timeout)
 	}
 }
+
+func TestRoundUpSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		unit     int64
+		units    int64
+		quantity string
+	}{
+		{"1500Mi", GiB, 2, "2Gi"},
+		{"1Gi", GiB, 1, "1Gi"},
+		{"1500Ki", 4 * MiB, 1, "4Mi"},
+		{"9Mi", 4 * MiB, 3, "12Mi"},
+		{"1", MiB, 1, "1Mi"},
+	}
+	for _, test := range tests {
+		size := resource.MustParse(test.size)
+		if units := RoundUpSize(size.Value(), test.unit); units != test.units {
+			t.Errorf("%s in units of %d: expected %d units, got %d", test.size, test.unit, test.units, units)
+		}
+		rounded := RoundUpQuantity(size, test.unit)
+		if rounded.String() != test.quantity {
+			t.Errorf("%s in units of %d: expected %s, got %s", test.size, test.unit, test.quantity, rounded.String())
+		}
+	}
+	if gb := RoundUpToGiB(resource.MustParse("1500Mi")); gb != 2 {
+		t.Errorf("Expected 2GiB, got %d", gb)
+	}
+	if mb := RoundUpToMiB(resource.MustParse("1500Ki")); mb != 2 {
+		t.Errorf("Expected 2MiB, got %d", mb)
+	}
+}
+
+func TestUniqueVolumeName(t *testing.T) {
+	uniqueName := GetUniqueVolumeName("kubernetes.io/nfs", "localhost:/export/data")
+	if uniqueName != "kubernetes.io/nfs/localhost:/export/data" {
+		t.Errorf("Unexpected unique name %q", uniqueName)
+	}
+	pluginName, volumeName, err := SplitUniqueName(uniqueName)
+	if err != nil || pluginName != "kubernetes.io/nfs" || volumeName != "localhost:/export/data" {
+		t.Errorf("Expected the names the unique name was made of, got %q, %q, %v", pluginName, volumeName, err)
+	}
+	for _, invalid := range []string{"", "vol1", "nfs/vol1", "kubernetes.io/nfs/", "/nfs/vol1"} {
+		if _, _, err := SplitUniqueName(invalid); err == nil {
+			t.Errorf("Expected an error splitting %q", invalid)
+		}
+	}
+
+	plugin := &FakeVolumePlugin{PluginName: "kubernetes.io/fake"}
+	spec := NewSpecFromVolume(&api.Volume{Name: "vol1"})
+	if uniqueName, err := GetUniqueVolumeNameFromSpec(plugin, spec); err != nil || uniqueName != "kubernetes.io/fake/vol1" {
+		t.Errorf("Unexpected unique name %q, %v", uniqueName, err)
+	}
+}
+
+type fakeExpander struct {
+	expanded []string
+}
+
+func (e *fakeExpander) ExpandVolume(spec *api.PersistentVolume, newSize resource.Quantity) error {
+	e.expanded = append(e.expanded, newSize.String())
+	return nil
+}
+
+func (e *fakeExpander) RequiresFSResize() bool {
+	return true
+}
+
+func TestExpandPersistentVolume(t *testing.T) {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv1"},
+		Spec: api.PersistentVolumeSpec{
+			Capacity: api.ResourceList{api.ResourceStorage: resource.MustParse("1Gi")},
+		},
+	}
+	expander := &fakeExpander{}
+
+	if err := ExpandPersistentVolume(expander, pv, resource.MustParse("500Mi")); err == nil {
+		t.Errorf("Expected shrinking to be refused")
+	}
+	if err := ExpandPersistentVolume(expander, pv, resource.MustParse("1Gi")); err != nil {
+		t.Errorf("Expected expanding to the current size to succeed, got %v", err)
+	}
+	if len(expander.expanded) != 0 {
+		t.Errorf("Expected no expansion yet, got %v", expander.expanded)
+	}
+
+	if err := ExpandPersistentVolume(expander, pv, resource.MustParse("2Gi")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(expander.expanded) != 1 || expander.expanded[0] != "2Gi" {
+		t.Errorf("Expected one expansion to 2Gi, got %v", expander.expanded)
+	}
+	if size := pv.Spec.Capacity[api.ResourceStorage]; size.String() != "2Gi" {
+		t.Errorf("Expected capacity to be updated to 2Gi, got %s", size.String())
+	}
+}
+
+func TestNewRecyclerPod(t *testing.T) {
+	template := NewPersistentVolumeRecyclerPodTemplate()
+	config := VolumeConfig{RecyclerPodTemplate: template}
+	source := api.VolumeSource{NFS: &api.NFSVolumeSource{Server: "server", Path: "/export"}}
+
+	pod, err := NewRecyclerPod(config, "pv-recycler-nfs-", 120, source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pod.GenerateName != "pv-recycler-nfs-" || *pod.Spec.ActiveDeadlineSeconds != 120 {
+		t.Errorf("Expected name and timeout to be set, got %q and %d", pod.GenerateName, *pod.Spec.ActiveDeadlineSeconds)
+	}
+	if pod.Spec.Volumes[0].NFS == nil || pod.Spec.Volumes[0].NFS.Path != "/export" {
+		t.Errorf("Expected the NFS source to be set, got %+v", pod.Spec.Volumes[0])
+	}
+	if pod.Spec.Containers[0].Image != template.Spec.Containers[0].Image {
+		t.Errorf("Expected the template's scrubber image, got %s", pod.Spec.Containers[0].Image)
+	}
+
+	// The shared template must not be changed.
+	if template.GenerateName != "pv-recycler-" || *template.Spec.ActiveDeadlineSeconds != 60 || template.Spec.Volumes[0].NFS != nil {
+		t.Errorf("Expected the template to be left alone, got %+v", template)
+	}
+
+	if _, err := NewRecyclerPod(VolumeConfig{}, "pv-recycler-nfs-", 120, source); err == nil {
+		t.Errorf("Expected an error without a template")
+	}
+	if _, err := NewRecyclerPod(VolumeConfig{RecyclerPodTemplate: &api.Pod{}}, "pv-recycler-nfs-", 120, source); err == nil {
+		t.Errorf("Expected an error for a template without volumes")
+	}
+}
+
+// bindMounter misses bind mounts in IsLikelyNotMountPoint like the real
+// mounter does, since they are on the same device as their parent.
+type bindMounter struct {
+	*mount.FakeMounter
+}
+
+func (m *bindMounter) IsLikelyNotMountPoint(file string) (bool, error) {
+	return true, nil
+}
+
+func TestUnmountPath(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "util_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dir := path.Join(tmpDir, "vol")
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mounter := &bindMounter{&mount.FakeMounter{MountPoints: []mount.MountPoint{{Device: "/data", Path: dir}}}}
+	if notMnt, err := IsNotMountPoint(dir, mounter); err != nil || notMnt {
+		t.Errorf("Expected the bind mount %s to be detected, got %v, %v", dir, notMnt, err)
+	}
+	if err := UnmountPath(dir, mounter); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mounter.Log) != 1 || mounter.Log[0].Action != mount.FakeActionUnmount {
+		t.Errorf("Expected one unmount, got %v", mounter.Log)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed: %v", dir, err)
+	}
+	// Already torn down.
+	if err := UnmountPath(dir, mounter); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Content of a directory that is not mounted is kept.
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "data"), []byte("data"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := UnmountPath(dir, mounter); err == nil {
+		t.Errorf("Expected an error removing a non-empty directory")
+	}
+	if _, err := os.Stat(path.Join(dir, "data")); err != nil {
+		t.Errorf("Expected the data to be kept: %v", err)
+	}
+}
+
+func TestAccessModes(t *testing.T) {
+	modes := []api.PersistentVolumeAccessMode{api.ReadWriteOnce, api.ReadOnlyMany}
+	if !AccessModesContains(modes, api.ReadOnlyMany) || AccessModesContains(modes, api.ReadWriteMany) {
+		t.Errorf("Unexpected result of AccessModesContains for %v", modes)
+	}
+	if !AccessModesContainedInAll(modes, []api.PersistentVolumeAccessMode{api.ReadOnlyMany, api.ReadWriteOnce}) {
+		t.Errorf("Expected all requested modes to be contained in %v", modes)
+	}
+	if AccessModesContainedInAll(modes, []api.PersistentVolumeAccessMode{api.ReadWriteOnce, api.ReadWriteMany}) {
+		t.Errorf("Expected ReadWriteMany not to be contained in %v", modes)
+	}
+	if !AccessModesContainedInAll(modes, nil) {
+		t.Errorf("Expected no requested modes to be contained in %v", modes)
+	}
+}
+
+func TestCheckAccessMode(t *testing.T) {
+	pv := &api.PersistentVolume{
+		ObjectMeta: api.ObjectMeta{Name: "pv"},
+		Spec: api.PersistentVolumeSpec{
+			AccessModes: []api.PersistentVolumeAccessMode{api.ReadWriteOnce, api.ReadOnlyMany},
+		},
+	}
+	tests := []struct {
+		name      string
+		spec      *Spec
+		requested []api.PersistentVolumeAccessMode
+		expectErr bool
+	}{
+		{"pv-supported", NewSpecFromPersistentVolume(pv, false), []api.PersistentVolumeAccessMode{api.ReadWriteOnce}, false},
+		{"pv-unsupported", NewSpecFromPersistentVolume(pv, false), []api.PersistentVolumeAccessMode{api.ReadWriteMany}, true},
+		{"pv-read-only", NewSpecFromPersistentVolume(pv, true), []api.PersistentVolumeAccessMode{api.ReadOnlyMany}, false},
+		{"pv-read-only-write", NewSpecFromPersistentVolume(pv, true), []api.PersistentVolumeAccessMode{api.ReadWriteOnce}, true},
+		{"inline", NewSpecFromVolume(&api.Volume{Name: "vol", VolumeSource: api.VolumeSource{NFS: &api.NFSVolumeSource{}}}), []api.PersistentVolumeAccessMode{api.ReadWriteMany}, false},
+		{"inline-read-only", NewSpecFromVolume(&api.Volume{Name: "vol", VolumeSource: api.VolumeSource{NFS: &api.NFSVolumeSource{ReadOnly: true}}}), []api.PersistentVolumeAccessMode{api.ReadWriteMany}, true},
+	}
+	for _, test := range tests {
+		err := CheckAccessMode(test.spec, test.requested)
+		if test.expectErr && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if !test.expectErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// DefaultVerifyTimeout is how long callers of VerifyWithTimeout should give
+// a volume to verify, long enough for a backend under load but short enough
+// that a hung mount does not hold up the pod.
+const DefaultVerifyTimeout = 10 * time.Second
+
+// Verifier is implemented by Builders which can check their volume once it
+// is set up, e.g. that the filesystem is readable, carries the expected
+// UUID and is writable unless the volume is read-only, so that a bad mount
+// is caught before containers start on it.
+type Verifier interface {
+	Verify() error
+}
+
+// ErrVerifyTimeout is returned when the verification of a volume did not
+// complete within the timeout.
+type ErrVerifyTimeout struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *ErrVerifyTimeout) Error() string {
+	return fmt.Sprintf("verification of volume %s did not complete within %s", e.Path, e.Timeout)
+}
+
+// VerifyWithTimeout runs builder.Verify() if builder is a Verifier, giving
+// up after timeout.  Builders which are not Verifiers pass.  A verification
+// that is given up on keeps running in the background, as there is no way
+// to interrupt a hung filesystem call.
+func VerifyWithTimeout(builder Builder, timeout time.Duration) error {
+	verifier, ok := UnwrapVolume(builder).(Verifier)
+	if !ok {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- verifier.Verify()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return &ErrVerifyTimeout{Path: builder.GetPath(), Timeout: timeout}
+	}
+}
+
+// VerifyPath is a Verify() for volumes mounted at path: it checks that the
+// directory can be listed and, unless readOnly, that a file can be created
+// in it.
+func VerifyPath(path string, readOnly bool) error {
+	if _, err := ioutil.ReadDir(path); err != nil {
+		return fmt.Errorf("volume %s is not readable: %v", path, err)
+	}
+	if readOnly {
+		return nil
+	}
+	f, err := ioutil.TempFile(path, ".verify")
+	if err != nil {
+		return fmt.Errorf("volume %s is not writable: %v", path, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+type fakeVerifyingBuilder struct {
+	FakeVolume
+	hang chan struct{}
+	err  error
+}
+
+func (b *fakeVerifyingBuilder) Verify() error {
+	if b.hang != nil {
+		<-b.hang
+	}
+	return b.err
+}
+
+func (b *fakeVerifyingBuilder) GetPath() string {
+	return "/fake"
+}
+
+func TestVerifyWithTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	failure := errors.New("wrong UUID")
+
+	if err := VerifyWithTimeout(&FakeVolume{}, time.Millisecond); err != nil {
+		t.Errorf("Expected a builder without Verify to pass, got %v", err)
+	}
+	if err := VerifyWithTimeout(&fakeVerifyingBuilder{}, time.Second); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := VerifyWithTimeout(&fakeVerifyingBuilder{err: failure}, time.Second); err != failure {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	err := VerifyWithTimeout(&fakeVerifyingBuilder{hang: hang}, 10*time.Millisecond)
+	if _, ok := err.(*ErrVerifyTimeout); !ok {
+		t.Errorf("Expected ErrVerifyTimeout, got %v", err)
+	}
+}
+
+func TestVerifyPath(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "verify_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := VerifyPath(tmpDir, false); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	entries, err := ioutil.ReadDir(tmpDir)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected the probe file to be removed, got %v, %v", entries, err)
+	}
+	if err := VerifyPath(path.Join(tmpDir, "missing"), true); err == nil {
+		t.Errorf("Expected a missing volume to fail")
+	}
+}
@@ -17,10 +17,10 @@ limitations under the License.
 package volume
 
 import (
-	"io/ioutil"
+	"time"
+
 	"k8s.io/kubernetes/pkg/api"
-	"os"
-	"path"
+	"k8s.io/kubernetes/pkg/api/resource"
 )
 
 // Volume represents a directory used by pods or hosts on a node.
@@ -30,6 +30,27 @@ type Volume interface {
 	GetPath() string
 }
 
+// Unwrapper is implemented by volumes which wrap another volume to change
+// how it is set up or torn down, e.g. the Builder of NewReadWriteOncePodBuilder.
+// The optional interfaces of a wrapped volume, like Verifier or
+// HealthChecker, are looked up on the volume it wraps, see UnwrapVolume.
+type Unwrapper interface {
+	// Unwrap returns the wrapped volume.
+	Unwrap() Volume
+}
+
+// UnwrapVolume returns the volume v wraps, however deeply, or v if it wraps
+// none.
+func UnwrapVolume(v Volume) Volume {
+	for {
+		unwrapper, ok := v.(Unwrapper)
+		if !ok {
+			return v
+		}
+		v = unwrapper.Unwrap()
+	}
+}
+
 // Builder interface provides methods to set up/mount the volume.
 type Builder interface {
 	// Uses Interface to provide the path for Docker binds.
@@ -42,24 +63,70 @@ type Builder interface {
 	// directory path, which may or may not exist yet.  This may be called
 	// more than once, so implementations must be idempotent.
 	SetUpAt(dir string) error
-	// IsReadOnly is a flag that gives the builder's ReadOnly attribute.
-	// All persistent volumes have a private readOnly flag in their builders.
-	IsReadOnly() bool
-	// SupportsOwnershipManagement returns whether this builder wants
-	// ownership management for the volume.  If this method returns true,
-	// the Kubelet will:
+	// GetAttributes returns the attributes of the builder.  They are
+	// computed from the spec the builder was made for, so volumes of the
+	// same plugin may differ.
+	GetAttributes() Attributes
+}
+
+// Attributes represents the attributes of a builder that the Kubelet
+// considers when setting up a volume.
+type Attributes struct {
+	// ReadOnly is whether the volume is mounted read-only.
+	ReadOnly bool
+	// Managed is whether the builder wants ownership management for the
+	// volume.  If it is true and the volume is not ReadOnly, the Kubelet
+	// will:
 	//
 	// 1. Make the volume owned by group FSGroup
 	// 2. Set the setgid bit is set (new files created in the volume will be owned by FSGroup)
 	// 3. Logical OR the permission bits with rw-rw----
-	SupportsOwnershipManagement() bool
-	// SupportsSELinux reports whether the given builder supports
-	// SELinux and would like the kubelet to relabel the volume to
-	// match the pod to which it will be attached.
-	SupportsSELinux() bool
+	Managed bool
+	// FSGroupChangePolicy is how the Kubelet changes the ownership of a
+	// Managed volume.  The empty policy is FSGroupChangeAlways.
+	FSGroupChangePolicy FSGroupChangePolicy
+	// SupportsSELinux is whether the builder supports SELinux and would
+	// like the Kubelet to relabel the volume to match the pod to which it
+	// will be attached.
+	SupportsSELinux bool
+	// MountPropagation is how mounts made inside the volume propagate
+	// between the host and the containers.  The empty mode is
+	// MountPropagationNone.  See SetUpMountPropagation.
+	MountPropagation MountPropagationMode
+}
+
+// FSGroupChangePolicy controls when the ownership of a volume is changed to
+// match the FSGroup of the pod using it.
+type FSGroupChangePolicy string
+
+const (
+	// FSGroupChangeAlways changes the ownership of every file in the volume
+	// each time it is set up.
+	FSGroupChangeAlways FSGroupChangePolicy = "Always"
+	// FSGroupChangeOnRootMismatch changes the ownership of the volume only
+	// if its root directory is not already owned by FSGroup with the
+	// setgid and rw-rw---- bits set, which saves a recursive walk of large
+	// volumes that were set up before.
+	FSGroupChangeOnRootMismatch FSGroupChangePolicy = "OnRootMismatch"
+)
+
+// DiskBuilder is implemented by the Builders of block-backed volumes, such
+// as cloud disks, iSCSI and RBD, which mount a filesystem on their device
+// with a SafeFormatAndMount.
+type DiskBuilder interface {
+	Builder
+	// FSType returns the filesystem type from the fsType of the volume
+	// source.  Blank devices are formatted with it, and devices holding
+	// another filesystem are not mounted.  If it is empty, blank devices
+	// are formatted with the default type and existing filesystems are
+	// mounted as whatever type they are.
+	FSType() string
 }
 
 // Cleaner interface provides methods to cleanup/unmount the volumes.
+// Cleaners of volumes that are mounted at their path should use
+// UnmountPath, which never removes the directory while it is still a
+// mount point.
 type Cleaner interface {
 	Volume
 	// TearDown unmounts the volume from a self-determined directory and
@@ -70,6 +137,36 @@ type Cleaner interface {
 	TearDownAt(dir string) error
 }
 
+// BlockVolume is a raw block device exposed to pods without a filesystem.
+// It is kept apart from Volume, whose GetPath is a directory.  The device
+// is linked once per node under the global map path and once per pod under
+// the pod device map path.
+type BlockVolume interface {
+	// GetGlobalMapPath returns the node wide directory in which the device
+	// of the volume described by spec is linked.
+	GetGlobalMapPath(spec *Spec) (string, error)
+	// GetPodDeviceMapPath returns the directory and the file name of the
+	// link to the device for the pod.
+	GetPodDeviceMapPath() (string, string)
+}
+
+// BlockVolumeBuilder interface provides methods to set up a raw block device.
+type BlockVolumeBuilder interface {
+	BlockVolume
+	// SetUpDevice prepares the volume's device on the node, e.g. by
+	// attaching it, and returns the device path.  This may be called more
+	// than once, so implementations must be idempotent.
+	SetUpDevice() (string, error)
+}
+
+// BlockVolumeCleaner interface provides methods to clean up a raw block device.
+type BlockVolumeCleaner interface {
+	BlockVolume
+	// TearDownDevice removes traces of SetUpDevice for the device at
+	// devicePath, whose links are under mapPath.
+	TearDownDevice(mapPath, devicePath string) error
+}
+
 // Recycler provides methods to reclaim the volume resource.
 type Recycler interface {
 	Volume
@@ -78,6 +175,20 @@ type Recycler interface {
 	Recycle() error
 }
 
+// RecycleProgressFunc is called by ProgressRecyclers with a description of
+// what the recycle is doing, e.g. the phase the pod scrubbing the volume
+// entered.
+type RecycleProgressFunc func(message string)
+
+// ProgressRecycler is implemented by Recyclers which can give up on a
+// recycle after a timeout and report its progress.
+type ProgressRecycler interface {
+	// RecycleWithTimeout is like Recycle, but returns an ErrRecycleTimeout
+	// if the recycle is not complete within timeout, which is unlimited
+	// if 0, and calls progress, if not nil, as the recycle advances.
+	RecycleWithTimeout(timeout time.Duration, progress RecycleProgressFunc) error
+}
+
 // Provisioner is an interface that creates templates for PersistentVolumes and can create the volume
 // as a new resource in the infrastructure provider.
 type Provisioner interface {
@@ -99,14 +210,71 @@ type Deleter interface {
 	Delete() error
 }
 
-func RenameDirectory(oldPath, newName string) (string, error) {
-	newPath, err := ioutil.TempDir(path.Dir(oldPath), newName)
-	if err != nil {
-		return "", err
-	}
-	err = os.Rename(oldPath, newPath)
-	if err != nil {
-		return "", err
-	}
-	return newPath, nil
+// DryRunProvisioner is implemented by Provisioners which can check, against
+// their storage backend, whether Provision would succeed without creating
+// anything, e.g. to preflight StorageClass parameters from admission.
+type DryRunProvisioner interface {
+	// ValidateProvision returns the error Provision(pv) is expected to fail
+	// with, or nil.  It must not allocate any resources.
+	ValidateProvision(pv *api.PersistentVolume) error
+}
+
+// DryRunDeleter is implemented by Deleters which can check whether Delete
+// would succeed without deleting anything.
+type DryRunDeleter interface {
+	ValidateDelete() error
+}
+
+// Snapshotter takes point in time copies of a volume in its storage
+// provider.  Volumes can be provisioned from a snapshot by setting
+// VolumeOptions.SnapshotSource.
+type Snapshotter interface {
+	// CreateSnapshot snapshots the volume, naming the snapshot name if the
+	// provider supports it, and returns the ID of the new snapshot.  This
+	// method should block until the snapshot is complete.
+	CreateSnapshot(name string) (snapshotID string, err error)
+	// DeleteSnapshot deletes the snapshot with the given ID.
+	DeleteSnapshot(id string) error
+}
+
+// Expander grows volumes in place, so that users do not have to recreate a
+// PersistentVolume to get more space.
+type Expander interface {
+	// ExpandVolume grows the storage backing spec to newSize.  This method
+	// should block until completion.
+	ExpandVolume(spec *api.PersistentVolume, newSize resource.Quantity) error
+	// RequiresFSResize returns whether the filesystem on the volume has to
+	// be resized on the node after ExpandVolume before the space is usable.
+	RequiresFSResize() bool
+}
+
+// Reconstructor rebuilds the spec of a volume from what is left of it on
+// the node, so that volumes set up before a kubelet restart can be tracked
+// and torn down even though the pods using them are gone.
+type Reconstructor interface {
+	// ConstructVolumeSpec returns a spec for the volume named volumeName
+	// which is set up at mountPath, as found by GetMountedVolumesForPod.
+	// The spec only has to be complete enough to tear the volume down and
+	// to find its device.
+	ConstructVolumeSpec(volumeName, mountPath string) (*Spec, error)
+}
+
+// Attacher can attach a volume to a node, for volumes such as network block
+// devices which have to be attached before they can be mounted.  Attaching
+// is separate from the Builder so that it can be done by a controller
+// instead of the kubelet.
+type Attacher interface {
+	// Attach attaches the volume described by spec to the named node and
+	// returns the path of the device on that node.
+	Attach(spec *Spec, nodeName string) (string, error)
+	// WaitForAttach waits until the device at devicePath shows up on the
+	// local node, or returns an error once timeout has passed.
+	WaitForAttach(devicePath string, timeout time.Duration) error
+}
+
+// Detacher can detach a volume from a node.
+type Detacher interface {
+	// Detach detaches the named device from the named node.  It should
+	// only be called once the volume is no longer mounted on the node.
+	Detach(deviceName string, nodeName string) error
 }
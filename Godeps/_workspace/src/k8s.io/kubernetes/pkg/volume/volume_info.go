@@ -0,0 +1,181 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// MountTimeReporter is implemented by volumes which remember when they were
+// mounted.
+type MountTimeReporter interface {
+	MountTime() time.Time
+}
+
+// Info is everything known about a volume, for diagnostics.  Fields of
+// capabilities the volume does not have are omitted, and fields which could
+// not be collected are omitted with the reason recorded in Errors.
+type Info struct {
+	Path string `json:"path"`
+
+	Device       string     `json:"device,omitempty"`
+	FSType       string     `json:"fsType,omitempty"`
+	MountOptions []string   `json:"mountOptions,omitempty"`
+	SuperOptions []string   `json:"superOptions,omitempty"`
+	ReadOnly     *bool      `json:"readOnly,omitempty"`
+	MountTime    *time.Time `json:"mountTime,omitempty"`
+	MountAge     string     `json:"mountAge,omitempty"`
+
+	Metrics     *Metrics         `json:"metrics,omitempty"`
+	Features    *FSFeatures      `json:"features,omitempty"`
+	Replication *ReplicationInfo `json:"replication,omitempty"`
+	Healthy     *bool            `json:"healthy,omitempty"`
+
+	// Capabilities lists the optional interfaces the volume implements.
+	Capabilities []string `json:"capabilities"`
+	// Errors maps the name of each field that could not be collected to
+	// the reason.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// volumeCapabilities are the optional interfaces reported in
+// Info.Capabilities.
+var volumeCapabilities = []struct {
+	name string
+	has  func(Volume) bool
+}{
+	{"Builder", func(v Volume) bool { _, ok := v.(Builder); return ok }},
+	{"Cleaner", func(v Volume) bool { _, ok := v.(Cleaner); return ok }},
+	{"Recycler", func(v Volume) bool { _, ok := v.(Recycler); return ok }},
+	{"Deleter", func(v Volume) bool { _, ok := v.(Deleter); return ok }},
+	{"MetricsProvider", func(v Volume) bool { _, ok := v.(MetricsProvider); return ok }},
+	{"HealthChecker", func(v Volume) bool { _, ok := v.(HealthChecker); return ok }},
+	{"ReplicationReporter", func(v Volume) bool { _, ok := v.(ReplicationReporter); return ok }},
+	{"SnapshotMounter", func(v Volume) bool { _, ok := v.(SnapshotMounter); return ok }},
+	{"SoftDeleter", func(v Volume) bool { _, ok := v.(SoftDeleter); return ok }},
+	{"BackendResourceChecker", func(v Volume) bool { _, ok := v.(BackendResourceChecker); return ok }},
+	{"MountTimeReporter", func(v Volume) bool { _, ok := v.(MountTimeReporter); return ok }},
+}
+
+// VolumeInfo collects everything known about v into an Info.  Failing to
+// collect one field does not stop the others from being collected; only a
+// volume without a path is an error.
+func VolumeInfo(v Volume) (*Info, error) {
+	path := v.GetPath()
+	if path == "" {
+		return nil, fmt.Errorf("volume has no path")
+	}
+	info := &Info{Path: path, Capabilities: []string{}, Errors: map[string]string{}}
+	inner := UnwrapVolume(v)
+	for _, c := range volumeCapabilities {
+		if c.has(v) || c.has(inner) {
+			info.Capabilities = append(info.Capabilities, c.name)
+		}
+	}
+
+	var mnt *volumeutil.MountInfo
+	if err := probeWithTimeout("mount", func() (err error) {
+		mnt, err = volumeutil.GetMountInfo(mountInfoPath, path)
+		return err
+	}); err != nil {
+		info.Errors["mount"] = err.Error()
+	} else {
+		readOnly := mnt.ReadOnly()
+		info.Device = mnt.Source
+		info.FSType = mnt.FSType
+		info.MountOptions = mnt.MountOptions
+		info.SuperOptions = mnt.SuperOptions
+		info.ReadOnly = &readOnly
+	}
+	if reporter, ok := inner.(MountTimeReporter); ok {
+		mountTime := reporter.MountTime()
+		info.MountTime = &mountTime
+		info.MountAge = time.Since(mountTime).String()
+	}
+
+	if provider, ok := inner.(MetricsProvider); ok {
+		var metrics *Metrics
+		if err := probeWithTimeout("metrics", func() (err error) {
+			metrics, err = provider.GetMetrics()
+			return err
+		}); err != nil {
+			info.Errors["metrics"] = err.Error()
+		} else {
+			info.Metrics = metrics
+		}
+	}
+	var features *FSFeatures
+	if err := probeWithTimeout("features", func() (err error) {
+		features, err = FilesystemFeatures(path)
+		return err
+	}); err != nil {
+		info.Errors["features"] = err.Error()
+	} else {
+		info.Features = features
+	}
+	if _, ok := inner.(ReplicationReporter); ok {
+		var replication *ReplicationInfo
+		if err := probeWithTimeout("replication", func() (err error) {
+			replication, err = GetReplicationStatus(v)
+			return err
+		}); err != nil {
+			info.Errors["replication"] = err.Error()
+		} else {
+			info.Replication = replication
+		}
+	}
+
+	health := checkHealthWithTimeout(context.Background(), v, nil)
+	info.Healthy = &health.Healthy
+	if health.Err != nil {
+		info.Errors["health"] = health.Err.Error()
+	}
+	return info, nil
+}
+
+// ErrInfoTimeout is recorded for a field of an Info whose probe did not
+// return within healthCheckTimeout.
+type ErrInfoTimeout struct {
+	Field   string
+	Timeout time.Duration
+}
+
+func (e *ErrInfoTimeout) Error() string {
+	return fmt.Sprintf("collecting %s did not complete within %s", e.Field, e.Timeout)
+}
+
+// probeWithTimeout calls probe, giving up after healthCheckTimeout so that a
+// volume on a dead server, e.g. NFS, can't hang VolumeInfo.  Like a health
+// check, a probe that is given up on keeps running in the background; the
+// results it sets must not be read once it timed out.
+func probeWithTimeout(field string, probe func() error) error {
+	timeout := healthCheckTimeout
+	done := make(chan error, 1)
+	go func() {
+		done <- probe()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return &ErrInfoTimeout{Field: field, Timeout: timeout}
+	}
+}
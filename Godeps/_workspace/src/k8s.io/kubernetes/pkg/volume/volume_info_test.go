@@ -0,0 +1,153 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeInfoVolume implements MetricsProvider, failing, and MountTimeReporter.
+type fakeInfoVolume struct {
+	fakeMetricsVolume
+	mountTime time.Time
+}
+
+func (v *fakeInfoVolume) MountTime() time.Time {
+	return v.mountTime
+}
+
+func TestVolumeInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volume_info_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	defer withFakeMountInfo(t, fmt.Sprintf("17 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n30 17 8:16 / %s ro,relatime - xfs /dev/sdb rw,noquota\n", dir))()
+
+	mountTime := time.Now().Add(-time.Hour)
+	v := &fakeInfoVolume{
+		fakeMetricsVolume: fakeMetricsVolume{path: dir, err: fmt.Errorf("statfs failed")},
+		mountTime:         mountTime,
+	}
+	info, err := VolumeInfo(v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if info.Path != dir || info.Device != "/dev/sdb" || info.FSType != "xfs" {
+		t.Errorf("Unexpected mount details: %+v", info)
+	}
+	if info.ReadOnly == nil || !*info.ReadOnly {
+		t.Errorf("Expected the volume to be reported read-only, got %v", info.ReadOnly)
+	}
+	if info.MountTime == nil || !info.MountTime.Equal(mountTime) || info.MountAge == "" {
+		t.Errorf("Expected mount time %s with an age, got %v %q", mountTime, info.MountTime, info.MountAge)
+	}
+	if info.Healthy == nil || !*info.Healthy {
+		t.Errorf("Expected the volume to be healthy, got %v", info.Healthy)
+	}
+	if expected := []string{"MetricsProvider", "MountTimeReporter"}; !reflect.DeepEqual(info.Capabilities, expected) {
+		t.Errorf("Expected capabilities %v, got %v", expected, info.Capabilities)
+	}
+
+	// Metrics failed and replication is not supported.
+	if info.Metrics != nil || info.Replication != nil {
+		t.Errorf("Expected metrics and replication to be omitted, got %+v", info)
+	}
+	if info.Errors["metrics"] != "statfs failed" {
+		t.Errorf("Expected the metrics error to be recorded, got %v", info.Errors)
+	}
+	if _, found := info.Errors["replication"]; found {
+		t.Errorf("Expected no replication error for a volume without replication, got %v", info.Errors)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Can't marshal info: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Can't unmarshal info: %v", err)
+	}
+	for _, omitted := range []string{"metrics", "replication"} {
+		if _, found := fields[omitted]; found {
+			t.Errorf("Expected %s to be omitted from %s", omitted, data)
+		}
+	}
+}
+
+func TestVolumeInfoMissingMount(t *testing.T) {
+	defer withFakeMountInfo(t, "")()
+
+	info, err := VolumeInfo(&FakeVolume{Plugin: &FakeVolumePlugin{Host: NewFakeVolumeHost("/tmp/fake", nil, nil)}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, found := info.Errors["mount"]; !found || info.FSType != "" {
+		t.Errorf("Expected the mount lookup error to be recorded, got %+v", info)
+	}
+	if _, found := info.Errors["health"]; !found || *info.Healthy {
+		t.Errorf("Expected a missing path to be unhealthy, got %+v", info)
+	}
+}
+
+// hungMetricsVolume is a MetricsProvider whose GetMetrics hangs until
+// released, like a statfs of a dead NFS server.
+type hungMetricsVolume struct {
+	path    string
+	release chan struct{}
+}
+
+func (v *hungMetricsVolume) GetPath() string {
+	return v.path
+}
+
+func (v *hungMetricsVolume) GetMetrics() (*Metrics, error) {
+	<-v.release
+	return &Metrics{}, nil
+}
+
+func TestVolumeInfoProbeTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volume_info_test")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	defer withFakeMountInfo(t, "")()
+	defer func(timeout time.Duration) { healthCheckTimeout = timeout }(healthCheckTimeout)
+	healthCheckTimeout = 100 * time.Millisecond
+
+	v := &hungMetricsVolume{path: dir, release: make(chan struct{})}
+	defer close(v.release)
+	info, err := VolumeInfo(v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := (&ErrInfoTimeout{Field: "metrics", Timeout: healthCheckTimeout}).Error()
+	if info.Errors["metrics"] != expected || info.Metrics != nil {
+		t.Errorf("Expected the metrics to time out with %q, got %+v", expected, info)
+	}
+	if _, found := info.Errors["features"]; found {
+		t.Errorf("Expected the other fields to be collected, got %+v", info)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package volumetest contains conformance tests that volume plugins can run
+// against their Builder and Cleaner implementations.
+package volumetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+// BuilderFactory returns a new Builder and a new Cleaner for the same
+// volume each time it is called, as the kubelet would make them after a
+// restart.
+type BuilderFactory func() (volume.Builder, volume.Cleaner, error)
+
+// RunBuilderIdempotencyTests checks that the volumes made by factory honor
+// the idempotency the Builder and Cleaner interfaces require:
+//
+//  1. SetUp may be called more than once, by the same or by a new Builder,
+//     without losing data written to the volume.
+//  2. SetUpAt on the volume path behaves like SetUp.
+//  3. TearDown may be called more than once, and on a volume which was
+//     never set up or whose directory was already removed.
+//  4. SetUp recovers from a previous SetUp that left an empty directory
+//     behind, as happens when it fails or the kubelet dies half way.
+func RunBuilderIdempotencyTests(t *testing.T, factory BuilderFactory) {
+	newBuilder := func() (volume.Builder, volume.Cleaner) {
+		builder, cleaner, err := factory()
+		if err != nil {
+			t.Fatalf("Failed to make a Builder and Cleaner: %v", err)
+		}
+		if builder == nil || cleaner == nil {
+			t.Fatalf("Got a nil Builder or Cleaner")
+		}
+		return builder, cleaner
+	}
+
+	// A Cleaner must cope with a volume that was never set up.
+	_, cleaner := newBuilder()
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("TearDown of a volume which was never set up failed: %v", err)
+	}
+
+	builder, cleaner := newBuilder()
+	volumePath := builder.GetPath()
+	if cleaner.GetPath() != volumePath {
+		t.Errorf("Builder and Cleaner disagree on the volume path: %q and %q", volumePath, cleaner.GetPath())
+	}
+	for i := 0; i < 2; i++ {
+		if err := builder.SetUp(); err != nil {
+			t.Fatalf("SetUp #%d failed: %v", i+1, err)
+		}
+		expectDir(t, volumePath)
+	}
+
+	canary := path.Join(volumePath, "volumetest-canary")
+	if !builder.GetAttributes().ReadOnly {
+		if err := ioutil.WriteFile(canary, []byte("canary"), 0644); err != nil {
+			t.Fatalf("Can't write to the volume: %v", err)
+		}
+	}
+	again, _ := newBuilder()
+	if err := again.SetUp(); err != nil {
+		t.Errorf("SetUp by a new Builder failed: %v", err)
+	}
+	if err := again.SetUpAt(volumePath); err != nil {
+		t.Errorf("SetUpAt on the volume path failed: %v", err)
+	}
+	if !builder.GetAttributes().ReadOnly {
+		if data, err := ioutil.ReadFile(canary); err != nil || string(data) != "canary" {
+			t.Errorf("Setting up the volume again lost its data: %q, %v", data, err)
+		}
+		os.Remove(canary)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := cleaner.TearDown(); err != nil {
+			t.Errorf("TearDown #%d failed: %v", i+1, err)
+		}
+		expectNoDir(t, volumePath)
+	}
+
+	// Simulate a SetUp which only got as far as making the directory.
+	if err := os.MkdirAll(volumePath, 0750); err != nil {
+		t.Fatalf("Can't make %s: %v", volumePath, err)
+	}
+	builder, cleaner = newBuilder()
+	if err := builder.SetUp(); err != nil {
+		t.Errorf("SetUp after a partial SetUp failed: %v", err)
+	}
+	expectDir(t, volumePath)
+	if err := cleaner.TearDown(); err != nil {
+		t.Errorf("TearDown after a partial SetUp failed: %v", err)
+	}
+	expectNoDir(t, volumePath)
+}
+
+func expectDir(t *testing.T, dir string) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Errorf("Volume path %s was not set up: %v", dir, err)
+	} else if !info.IsDir() {
+		t.Errorf("Volume path %s is not a directory", dir)
+	}
+}
+
+func expectNoDir(t *testing.T, dir string) {
+	if _, err := os.Stat(dir); err == nil {
+		t.Errorf("Volume path %s still exists after TearDown", dir)
+	} else if !os.IsNotExist(err) {
+		t.Errorf("Can't stat volume path %s: %v", dir, err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumetest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+func TestFakeVolumeIdempotency(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "volumetest")
+	if err != nil {
+		t.Fatalf("Can't make a temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	plugin := &volume.FakeVolumePlugin{PluginName: "fake-plugin"}
+	volume.NewFakeVolumeHost(tmpDir, nil, []volume.VolumePlugin{plugin})
+	spec := volume.NewSpecFromVolume(&api.Volume{Name: "vol1"})
+	pod := &api.Pod{ObjectMeta: api.ObjectMeta{UID: "poduid"}}
+
+	RunBuilderIdempotencyTests(t, func() (volume.Builder, volume.Cleaner, error) {
+		builder, err := plugin.NewBuilder(spec, pod, volume.VolumeOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		cleaner, err := plugin.NewCleaner("vol1", pod.UID)
+		return builder, cleaner, err
+	})
+	// The first Builder and Cleaner are for the TearDown of a volume never
+	// set up, the second ones set up and tear down the volume twice.
+	if len(plugin.Builders) < 2 || plugin.Builders[1].GetSetUpCallCount() != 2 {
+		t.Errorf("Expected the second Builder to be set up twice")
+	}
+	if len(plugin.Cleaners) < 2 || plugin.Cleaners[1].GetTearDownCallCount() != 2 {
+		t.Errorf("Expected the second Cleaner to be torn down twice")
+	}
+}
+
+func TestFakeVolumeInjectedErrors(t *testing.T) {
+	setUpErr := errors.New("set up failed")
+	plugin := &volume.FakeVolumePlugin{PluginName: "fake-plugin", SetUpErr: setUpErr}
+	volume.NewFakeVolumeHost("/tmp/fake", nil, []volume.VolumePlugin{plugin})
+	builder, err := plugin.NewBuilder(volume.NewSpecFromVolume(&api.Volume{Name: "vol1"}), &api.Pod{}, volume.VolumeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to make a Builder: %v", err)
+	}
+	if err := builder.SetUp(); err != setUpErr {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+	if count := plugin.Builders[0].GetSetUpCallCount(); count != 1 {
+		t.Errorf("Expected one SetUp call, got %d", count)
+	}
+}
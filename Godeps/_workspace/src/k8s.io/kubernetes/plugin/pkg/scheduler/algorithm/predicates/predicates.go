@@ -22,6 +22,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/volume"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 
 	"github.com/golang/glog"
@@ -52,6 +53,14 @@ func (nodes ClientNodeInfo) GetNodeInfo(nodeID string) (*api.Node, error) {
 	return nodes.Nodes().Get(nodeID)
 }
 
+type PersistentVolumeInfo interface {
+	GetPersistentVolumeInfo(pvID string) (*api.PersistentVolume, error)
+}
+
+type PersistentVolumeClaimInfo interface {
+	GetPersistentVolumeClaimInfo(namespace string, pvcID string) (*api.PersistentVolumeClaim, error)
+}
+
 func isVolumeConflict(volume api.Volume, pod *api.Pod) bool {
 	if volume.GCEPersistentDisk != nil {
 		disk := volume.GCEPersistentDisk
@@ -96,6 +105,62 @@ func NoDiskConflict(pod *api.Pod, existingPods []*api.Pod, node string) (bool, e
 	return true, nil
 }
 
+type VolumeNodeChecker struct {
+	pvInfo  PersistentVolumeInfo
+	pvcInfo PersistentVolumeClaimInfo
+	info    NodeInfo
+}
+
+func NewVolumeNodePredicate(pvInfo PersistentVolumeInfo, pvcInfo PersistentVolumeClaimInfo, info NodeInfo) algorithm.FitPredicate {
+	c := &VolumeNodeChecker{
+		pvInfo:  pvInfo,
+		pvcInfo: pvcInfo,
+		info:    info,
+	}
+	return c.predicate
+}
+
+// predicate checks that the node can use the persistent volumes the pod
+// claims, i.e. that its labels match the node affinity of every bound
+// volume.  Volumes without node affinity, e.g. NFS shares, can be used from
+// every node.  Claims which are not bound yet are left to the binder.
+func (c *VolumeNodeChecker) predicate(pod *api.Pod, existingPods []*api.Pod, nodeID string) (bool, error) {
+	var node *api.Node
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		claim, err := c.pvcInfo.GetPersistentVolumeClaimInfo(pod.Namespace, vol.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			return false, err
+		}
+		if claim.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := c.pvInfo.GetPersistentVolumeInfo(claim.Spec.VolumeName)
+		if err != nil {
+			return false, err
+		}
+		affinity, err := volume.GetNodeAffinity(pv)
+		if err != nil {
+			return false, err
+		}
+		if len(affinity) == 0 {
+			continue
+		}
+		if node == nil {
+			if node, err = c.info.GetNodeInfo(nodeID); err != nil {
+				return false, err
+			}
+		}
+		if !affinity.Matches(node.Labels) {
+			glog.V(4).Infof("Won't schedule pod %q onto node %q due to the node affinity of volume %q", pod.Name, nodeID, pv.Name)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 type ResourceFit struct {
 	info NodeInfo
 }
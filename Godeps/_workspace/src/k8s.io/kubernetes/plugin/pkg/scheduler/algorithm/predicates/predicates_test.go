@@ -23,6 +23,7 @@ import (
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/volume"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 )
 
@@ -44,6 +45,28 @@ func (nodes FakeNodeListInfo) GetNodeInfo(nodeName string) (*api.Node, error) {
 	return nil, fmt.Errorf("Unable to find node: %s", nodeName)
 }
 
+type FakePersistentVolumeInfo []api.PersistentVolume
+
+func (pvs FakePersistentVolumeInfo) GetPersistentVolumeInfo(pvID string) (*api.PersistentVolume, error) {
+	for _, pv := range pvs {
+		if pv.Name == pvID {
+			return &pv, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find persistent volume: %s", pvID)
+}
+
+type FakePersistentVolumeClaimInfo []api.PersistentVolumeClaim
+
+func (pvcs FakePersistentVolumeClaimInfo) GetPersistentVolumeClaimInfo(namespace string, pvcID string) (*api.PersistentVolumeClaim, error) {
+	for _, pvc := range pvcs {
+		if pvc.Namespace == namespace && pvc.Name == pvcID {
+			return &pvc, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find persistent volume claim: %s/%s", namespace, pvcID)
+}
+
 func makeResources(milliCPU int64, memory int64, pods int64) api.NodeResources {
 	return api.NodeResources{
 		Capacity: api.ResourceList{
@@ -716,3 +739,63 @@ func TestServiceAffinity(t *testing.T) {
 		}
 	}
 }
+
+func TestVolumeNodePredicate(t *testing.T) {
+	localPV := api.PersistentVolume{ObjectMeta: api.ObjectMeta{Name: "local"}}
+	if err := volume.SetNodeAffinity(&localPV, volume.HostnameNodeAffinity("node-a")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pvInfo := FakePersistentVolumeInfo{
+		localPV,
+		{ObjectMeta: api.ObjectMeta{Name: "nfs"}},
+	}
+	pvcInfo := FakePersistentVolumeClaimInfo{
+		{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "local-claim"}, Spec: api.PersistentVolumeClaimSpec{VolumeName: "local"}},
+		{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "nfs-claim"}, Spec: api.PersistentVolumeClaimSpec{VolumeName: "nfs"}},
+		{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "unbound-claim"}},
+	}
+	nodeInfo := FakeNodeListInfo{
+		{ObjectMeta: api.ObjectMeta{Name: "node-a", Labels: map[string]string{volume.HostnameLabel: "node-a"}}},
+		{ObjectMeta: api.ObjectMeta{Name: "node-b", Labels: map[string]string{volume.HostnameLabel: "node-b"}}},
+	}
+	podWithClaims := func(claims ...string) *api.Pod {
+		pod := &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "ns", Name: "pod"}}
+		for _, claim := range claims {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, api.Volume{
+				Name: claim,
+				VolumeSource: api.VolumeSource{
+					PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			})
+		}
+		return pod
+	}
+
+	tests := []struct {
+		pod  *api.Pod
+		node string
+		fits bool
+		test string
+	}{
+		{podWithClaims(), "node-b", true, "no claims"},
+		{podWithClaims("local-claim"), "node-a", true, "local volume on its node"},
+		{podWithClaims("local-claim"), "node-b", false, "local volume on another node"},
+		{podWithClaims("nfs-claim"), "node-b", true, "volume without node affinity"},
+		{podWithClaims("unbound-claim"), "node-b", true, "unbound claim"},
+		{podWithClaims("nfs-claim", "local-claim"), "node-b", false, "one volume on another node"},
+	}
+	predicate := NewVolumeNodePredicate(pvInfo, pvcInfo, nodeInfo)
+	for _, test := range tests {
+		fits, err := predicate(test.pod, nil, test.node)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.test, err)
+		}
+		if fits != test.fits {
+			t.Errorf("%s: expected %v, got %v", test.test, test.fits, fits)
+		}
+	}
+
+	if _, err := predicate(podWithClaims("missing-claim"), nil, "node-a"); err == nil {
+		t.Errorf("Expected an error for a missing claim")
+	}
+}